@@ -0,0 +1,233 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Audiences of the filtered specifications served at /docs/{audience}
+const (
+	AudiencePublic     = "public"
+	AudienceClient     = "client"
+	AudienceAdmin      = "admin"
+	AudienceSuperAdmin = "super-admin"
+)
+
+// Audiences lists every audience, most restricted first.
+var Audiences = []string{AudiencePublic, AudienceClient, AudienceAdmin, AudienceSuperAdmin}
+
+// audienceRoles is the user role each audience documents the API for.
+var audienceRoles = map[string]string{
+	AudienceClient:     "CLIENT",
+	AudienceAdmin:      "ADMIN",
+	AudienceSuperAdmin: "SUPER_ADMIN",
+}
+
+const schemaRef = "#/components/schemas/"
+
+// SpecFor returns OpenAPISpec reduced to the operations audience can call: those without
+// authentication for the public, and for a role also those any signed-in user can call and
+// those whose x-roles include it. Schemas and tags no remaining operation uses are dropped.
+//
+// It relies on the layout openapi-gen writes, so it does not need a YAML parser.
+func SpecFor(audience string) ([]byte, error) {
+	role, ok := audienceRoles[audience]
+	if !ok && audience != AudiencePublic {
+		return nil, fmt.Errorf("unknown audience %q", audience)
+	}
+
+	lines := strings.SplitAfter(string(OpenAPISpec), "\n")
+	pathsAt, componentsAt := -1, -1
+	for i, line := range lines {
+		switch strings.TrimRight(line, "\n") {
+		case "paths:":
+			pathsAt = i
+		case "components:":
+			componentsAt = i
+		}
+	}
+	if pathsAt < 0 || componentsAt < pathsAt {
+		return nil, fmt.Errorf("openapi.yaml has no paths or components section")
+	}
+
+	// Operations are the 4-space blocks of the 2-space path blocks
+	var paths []string
+	refs, tags := map[string]bool{}, map[string]bool{}
+	for _, path := range blocks(lines[pathsAt+1:componentsAt], 2) {
+		var kept []string
+		for _, op := range blocks(path[1:], 4) {
+			if !visible(op, role, audience == AudiencePublic) {
+				continue
+			}
+			kept = append(kept, op...)
+			collectRefs(op, refs)
+			for _, tag := range listValues(op, "      tags:") {
+				tags[tag] = true
+			}
+		}
+		if len(kept) > 0 {
+			paths = append(paths, path[0])
+			paths = append(paths, kept...)
+		}
+	}
+
+	// Schemas are kept when an operation or a kept schema refers to them
+	var components []string
+	for _, section := range blocks(lines[componentsAt+1:], 2) {
+		if strings.TrimSpace(section[0]) != "schemas:" {
+			components = append(components, section...)
+			continue
+		}
+		schemas := map[string][]string{}
+		var names []string
+		for _, schema := range blocks(section[1:], 4) {
+			name := unquote(strings.TrimSuffix(strings.TrimSpace(schema[0]), ":"))
+			schemas[name] = schema
+			names = append(names, name)
+		}
+		for pending := sortedKeys(refs); len(pending) > 0; {
+			name := pending[0]
+			pending = pending[1:]
+			found := map[string]bool{}
+			collectRefs(schemas[name], found)
+			for ref := range found {
+				if !refs[ref] {
+					refs[ref] = true
+					pending = append(pending, ref)
+				}
+			}
+		}
+		components = append(components, section[0])
+		for _, name := range names {
+			if refs[name] {
+				components = append(components, schemas[name]...)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(filterTags(lines[:pathsAt], tags), ""))
+	if len(paths) == 0 {
+		// "paths:" followed by nothing would read as null
+		b.WriteString("paths: {}\n")
+	} else {
+		b.WriteString(lines[pathsAt])
+		b.WriteString(strings.Join(paths, ""))
+	}
+	b.WriteString(lines[componentsAt])
+	b.WriteString(strings.Join(components, ""))
+	return []byte(b.String()), nil
+}
+
+// visible reports whether the operation op is documented for role, or for the public.
+func visible(op []string, role string, public bool) bool {
+	secured := false
+	for _, line := range op {
+		if strings.TrimRight(line, "\n") == "      security:" {
+			secured = true
+		}
+	}
+	if public || !secured {
+		return !secured
+	}
+	roles := listValues(op, "      x-roles:")
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// blocks splits lines into the blocks starting at each line indented by exactly indent spaces.
+// Lines before the first such line are dropped.
+func blocks(lines []string, indent int) [][]string {
+	pad := strings.Repeat(" ", indent)
+	var out [][]string
+	for _, line := range lines {
+		if strings.HasPrefix(line, pad) && len(line) > indent && line[indent] != ' ' && line[indent] != '-' {
+			out = append(out, []string{line})
+			continue
+		}
+		if len(out) > 0 {
+			out[len(out)-1] = append(out[len(out)-1], line)
+		}
+	}
+	return out
+}
+
+// listValues returns the items of the list under key, e.g. the roles of "      x-roles:".
+func listValues(lines []string, key string) []string {
+	var values []string
+	for i, line := range lines {
+		if strings.TrimRight(line, "\n") != key {
+			continue
+		}
+		itemPad := strings.Repeat(" ", len(key)-len(strings.TrimLeft(key, " "))+2) + "- "
+		for _, item := range lines[i+1:] {
+			if !strings.HasPrefix(item, itemPad) {
+				break
+			}
+			values = append(values, unquote(strings.TrimSpace(strings.TrimPrefix(item, itemPad))))
+		}
+	}
+	return values
+}
+
+// collectRefs adds the schemas lines refer to.
+func collectRefs(lines []string, refs map[string]bool) {
+	for _, line := range lines {
+		at := strings.Index(line, schemaRef)
+		if at < 0 {
+			continue
+		}
+		name := strings.TrimRight(line[at+len(schemaRef):], "\"\n")
+		refs[name] = true
+	}
+}
+
+// filterTags drops the entries of the header's tags list that name no kept operation's tag.
+func filterTags(header []string, used map[string]bool) []string {
+	var out []string
+	inTags, skipping := false, false
+	for _, line := range header {
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case trimmed == "tags:":
+			inTags = true
+		case inTags && strings.HasPrefix(trimmed, "  - name: "):
+			skipping = !used[unquote(strings.TrimPrefix(trimmed, "  - name: "))]
+		case inTags && strings.HasPrefix(trimmed, "    "):
+			// a field of the current tag
+		default:
+			if trimmed != "" {
+				inTags, skipping = false, false
+			}
+		}
+		if !skipping {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func unquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+// Package api embeds the OpenAPI specification, the Swagger UI assets served at /docs and
+// the error catalog, so the docs work without the source tree or internet access.
+package api
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// OpenAPISpec is generated from the route registrations; see cmd/openapi-gen.
+//
+//go:generate go run ../cmd/openapi-gen -root ..
+//go:embed openapi.yaml
+var OpenAPISpec []byte
+
+// ErrorCatalog lists the error codes of the API as JSON []ErrorCode; see cmd/errors-gen.
+//
+//go:generate go run ../cmd/errors-gen -root ..
+//go:embed errors.json
+var ErrorCatalog []byte
+
+// ErrorCode is an entry of the ErrorCatalog: a code returned with an HTTP status, and the
+// messages sent with it.
+type ErrorCode struct {
+	Code     string   `json:"code"`
+	Status   int      `json:"status"`
+	Messages []string `json:"messages"`
+}
+
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+// SwaggerUIVersion is the swagger-ui-dist release fetched by `make swagger-ui`.
+const SwaggerUIVersion = "3.25.0"
+
+// SwaggerUI returns the embedded Swagger UI assets, or nil when the binary was built
+// without running `make swagger-ui` first.
+func SwaggerUI() fs.FS {
+	assets, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		return nil
+	}
+	if _, err := fs.Stat(assets, "swagger-ui-bundle.js"); err != nil {
+		return nil
+	}
+	return assets
+}
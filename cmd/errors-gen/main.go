@@ -0,0 +1,278 @@
+// Command errors-gen derives api/errors.json, the catalog served at /api/errors, from the
+// errors.New calls of finsolvz-backend/internal/utils/errors below internal and cmd, so
+// client teams can map every code the API returns to their own messages.
+//
+// Codes must be string literals and statuses int literals or http.StatusXxx constants;
+// parts of messages built at runtime are shown as "…".
+//
+// Usage:
+//
+//	go run ./cmd/errors-gen          # rewrite api/errors.json
+//	go run ./cmd/errors-gen -check   # fail if api/errors.json is out of date
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"finsolvz-backend/api"
+)
+
+const errorsPkg = "/internal/utils/errors"
+
+func main() {
+	root := flag.String("root", ".", "module root")
+	out := flag.String("out", "api/errors.json", "generated catalog")
+	check := flag.Bool("check", false, "fail if the generated catalog differs from -out instead of writing it")
+	flag.Parse()
+
+	if err := run(*root, *out, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "errors-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(root, outPath string, check bool) error {
+	modPath, err := modulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return err
+	}
+
+	c := catalog{}
+	for _, dir := range []string{"internal", "cmd"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(p string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+				return err
+			}
+			return c.scanFile(p, modPath+errorsPkg, filepath.Dir(p) == filepath.Join(root, errorsPkg))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	body, err := json.MarshalIndent(c.codes(), "", "  ")
+	if err != nil {
+		return err
+	}
+	body = append(body, '\n')
+
+	target := filepath.Join(root, outPath)
+	if check {
+		current, err := os.ReadFile(target)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, body) {
+			return fmt.Errorf("%s is out of date; run `make errors`", outPath)
+		}
+		return nil
+	}
+	return os.WriteFile(target, body, 0o644)
+}
+
+// catalog collects the messages of each code and status.
+type catalog map[string]map[int]map[string]bool
+
+// scanFile records the errors.New calls of a file. Inside the errors package itself the
+// calls are unqualified.
+func (c catalog) scanFile(path, importPath string, inErrorsPkg bool) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return err
+	}
+
+	qualifier := ""
+	if !inErrorsPkg {
+		qualifier = importName(file, importPath)
+		if qualifier == "" {
+			return nil
+		}
+	}
+
+	var scanErr error
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || scanErr != nil || !isNewCall(call.Fun, qualifier) || len(call.Args) < 3 {
+			return true
+		}
+
+		code, codeOK := stringLit(call.Args[0])
+		status, statusOK := statusCode(call.Args[2])
+		if !codeOK || !statusOK {
+			scanErr = fmt.Errorf("%s: errors.New needs a literal code and a known status", fset.Position(call.Pos()))
+			return false
+		}
+		message := messageText(call.Args[1])
+
+		if c[code] == nil {
+			c[code] = map[int]map[string]bool{}
+		}
+		if c[code][status] == nil {
+			c[code][status] = map[string]bool{}
+		}
+		if message != "" {
+			c[code][status][message] = true
+		}
+		return true
+	})
+	return scanErr
+}
+
+// codes lists the catalog sorted by code, then status.
+func (c catalog) codes() []api.ErrorCode {
+	var codes []api.ErrorCode
+	for code, statuses := range c {
+		for status, messages := range statuses {
+			entry := api.ErrorCode{Code: code, Status: status}
+			for message := range messages {
+				entry.Messages = append(entry.Messages, message)
+			}
+			sort.Strings(entry.Messages)
+			codes = append(codes, entry)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].Code != codes[j].Code {
+			return codes[i].Code < codes[j].Code
+		}
+		return codes[i].Status < codes[j].Status
+	})
+	return codes
+}
+
+func isNewCall(fun ast.Expr, qualifier string) bool {
+	if qualifier == "" {
+		ident, ok := fun.(*ast.Ident)
+		return ok && ident.Name == "New"
+	}
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "New" {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == qualifier
+}
+
+// importName is the name a file uses for an import, or "" when it does not import it.
+func importName(file *ast.File, importPath string) string {
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == importPath {
+			if spec.Name != nil {
+				return spec.Name.Name
+			}
+			return importPath[strings.LastIndex(importPath, "/")+1:]
+		}
+	}
+	return ""
+}
+
+// messageText renders a message with "…" for the parts built at runtime, or returns ""
+// when nothing of it is known.
+func messageText(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		s, _ := stringLit(e)
+		return s
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			left, right := messageText(e.X), messageText(e.Y)
+			if left == "" {
+				left = "…"
+			}
+			if right == "" {
+				right = "…"
+			}
+			return strings.ReplaceAll(left+right, "……", "…")
+		}
+	case *ast.CallExpr:
+		// fmt.Sprintf("format", ...)
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Sprintf" && len(e.Args) > 0 {
+			if format, ok := stringLit(e.Args[0]); ok {
+				return formatVerb.ReplaceAllString(format, "…")
+			}
+		}
+	}
+	return ""
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func statusCode(expr ast.Expr) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		code, err := strconv.Atoi(e.Value)
+		return code, err == nil && e.Kind == token.INT
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "http" {
+			code, ok := statusCodes[e.Sel.Name]
+			return code, ok
+		}
+	}
+	return 0, false
+}
+
+var statusCodes = map[string]int{
+	"StatusBadRequest":                 http.StatusBadRequest,
+	"StatusUnauthorized":               http.StatusUnauthorized,
+	"StatusPaymentRequired":            http.StatusPaymentRequired,
+	"StatusForbidden":                  http.StatusForbidden,
+	"StatusNotFound":                   http.StatusNotFound,
+	"StatusMethodNotAllowed":           http.StatusMethodNotAllowed,
+	"StatusNotAcceptable":              http.StatusNotAcceptable,
+	"StatusConflict":                   http.StatusConflict,
+	"StatusGone":                       http.StatusGone,
+	"StatusPreconditionFailed":         http.StatusPreconditionFailed,
+	"StatusRequestEntityTooLarge":      http.StatusRequestEntityTooLarge,
+	"StatusUnsupportedMediaType":       http.StatusUnsupportedMediaType,
+	"StatusUnprocessableEntity":        http.StatusUnprocessableEntity,
+	"StatusLocked":                     http.StatusLocked,
+	"StatusPreconditionRequired":       http.StatusPreconditionRequired,
+	"StatusUpgradeRequired":            http.StatusUpgradeRequired,
+	"StatusTooManyRequests":            http.StatusTooManyRequests,
+	"StatusUnavailableForLegalReasons": http.StatusUnavailableForLegalReasons,
+	"StatusInternalServerError":        http.StatusInternalServerError,
+	"StatusNotImplemented":             http.StatusNotImplemented,
+	"StatusBadGateway":                 http.StatusBadGateway,
+	"StatusServiceUnavailable":         http.StatusServiceUnavailable,
+	"StatusGatewayTimeout":             http.StatusGatewayTimeout,
+}
+
+func modulePath(goMod string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[0] == "module" {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("%s has no module directive", goMod)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/app/backup"
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/repository"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const usage = `finsolvzctl - Finsolvz operations tool
+
+Usage:
+  finsolvzctl <command> [flags]
+
+Commands:
+  create-superadmin -email <email> [-name <name>] [-password <password>]
+                        Create a SUPER_ADMIN user (password generated when omitted)
+  reset-password -email <email> [-password <password>]
+                        Set a user's password (generated when omitted)
+  reindex               Create missing MongoDB indexes
+  run-migrations        Apply pending PostgreSQL migrations
+  purge-trash [-older-than 720h] [-collections users,companies,...]
+                        Permanently delete documents soft-deleted before the cutoff
+  export-collection -collection <name> -out <file> [-gzip]
+                        Export a collection (including soft-deleted documents) as Extended JSON lines
+  restore -key <key>    Restore a backup from the object store
+
+Configuration is read from the environment and .env like the server.
+`
+
+type command func(ctx context.Context, cfg *config.Config, args []string)
+
+var commands = map[string]command{
+	"create-superadmin": runCreateSuperAdmin,
+	"reset-password":    runResetPassword,
+	"reindex":           runReindex,
+	"run-migrations":    runMigrations,
+	"purge-trash":       runPurgeTrash,
+	"export-collection": runExportCollection,
+	"restore":           runRestore,
+}
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Warnf(context.Background(), "No .env file found: %v", err)
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.Load(ctx)
+	if err != nil {
+		log.Fatalf(ctx, "%v", err)
+	}
+	log.SetLevel(cfg.LogLevel)
+
+	run(ctx, cfg, os.Args[2:])
+}
+
+func runCreateSuperAdmin(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("create-superadmin", flag.ExitOnError)
+	email := fs.String("email", "", "email address of the new user")
+	name := fs.String("name", "Super Admin", "display name")
+	password := fs.String("password", "", "initial password; generated and printed when empty")
+	fs.Parse(args)
+
+	if *email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	userRepo := connectUsers(ctx, cfg)
+
+	if existing, err := userRepo.GetByEmail(ctx, *email); err == nil && existing != nil {
+		log.Fatalf(ctx, "A user with email %s already exists (role %s)", *email, existing.Role)
+	}
+
+	plain, generated := passwordOrGenerate(ctx, *password)
+	hashed, err := utils.HashPassword(plain)
+	if err != nil {
+		log.Fatalf(ctx, "Failed to hash password: %v", err)
+	}
+
+	user := &domain.User{
+		Name:     *name,
+		Email:    *email,
+		Password: hashed,
+		Role:     domain.RoleSuperAdmin,
+		Company:  []primitive.ObjectID{},
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		log.Fatalf(ctx, "Failed to create user: %v", err)
+	}
+
+	log.Infof(ctx, "Created SUPER_ADMIN %s (%s)", user.Email, user.ID.Hex())
+	if generated {
+		fmt.Printf("Password: %s\n", plain)
+	}
+}
+
+func runResetPassword(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	email := fs.String("email", "", "email address of the user")
+	password := fs.String("password", "", "new password; generated and printed when empty")
+	fs.Parse(args)
+
+	if *email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	userRepo := connectUsers(ctx, cfg)
+
+	user, err := userRepo.GetByEmail(ctx, *email)
+	if err != nil {
+		log.Fatalf(ctx, "Failed to find user: %v", err)
+	}
+
+	plain, generated := passwordOrGenerate(ctx, *password)
+	if user.Password, err = utils.HashPassword(plain); err != nil {
+		log.Fatalf(ctx, "Failed to hash password: %v", err)
+	}
+	if err := userRepo.Update(ctx, user.ID, user); err != nil {
+		log.Fatalf(ctx, "Failed to update user: %v", err)
+	}
+
+	log.Infof(ctx, "Password reset for %s", user.Email)
+	if generated {
+		fmt.Printf("Password: %s\n", plain)
+	}
+}
+
+func runReindex(ctx context.Context, cfg *config.Config, args []string) {
+	db := connectMongo(ctx, cfg, "reindex")
+
+	if err := config.CreateIndexes(db); err != nil {
+		log.Fatalf(ctx, "Reindex failed: %v", err)
+	}
+	log.Infof(ctx, "Indexes are up to date")
+}
+
+func runMigrations(ctx context.Context, cfg *config.Config, args []string) {
+	if cfg.Database.Driver != config.DriverPostgres {
+		log.Fatalf(ctx, "run-migrations requires DB_DRIVER=postgres; MongoDB has no schema migrations (use reindex)")
+	}
+
+	pg, err := config.ConnectPostgres(ctx, cfg.Database.PostgresDSN)
+	if err != nil {
+		log.Fatalf(ctx, "Failed to connect to database: %v", err)
+	}
+	defer pg.Close()
+
+	if err := repository.MigratePostgres(ctx, pg); err != nil {
+		log.Fatalf(ctx, "Migration failed: %v", err)
+	}
+	log.Infof(ctx, "Migrations are up to date")
+}
+
+func runPurgeTrash(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("purge-trash", flag.ExitOnError)
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "only purge documents deleted longer ago than this")
+	collections := fs.String("collections", strings.Join(domain.SoftDeleteCollections, ","), "comma-separated collections to purge")
+	fs.Parse(args)
+
+	var trashRepo domain.TrashRepository
+	if cfg.Database.Driver == config.DriverPostgres {
+		pg, err := config.ConnectPostgres(ctx, cfg.Database.PostgresDSN)
+		if err != nil {
+			log.Fatalf(ctx, "Failed to connect to database: %v", err)
+		}
+		defer pg.Close()
+		trashRepo = repository.NewTrashPostgresRepository(pg)
+	} else {
+		trashRepo = repository.NewTrashMongoRepository(connectMongo(ctx, cfg, "purge-trash"))
+	}
+
+	cutoff := time.Now().Add(-*olderThan)
+	for _, collection := range strings.Split(*collections, ",") {
+		collection = strings.TrimSpace(collection)
+		if !isSoftDeleteCollection(collection) {
+			log.Fatalf(ctx, "%q does not support soft delete (expected one of %s)", collection, strings.Join(domain.SoftDeleteCollections, ", "))
+		}
+
+		purged, err := trashRepo.Purge(ctx, collection, cutoff)
+		if err != nil {
+			log.Fatalf(ctx, "Purge failed: %v", err)
+		}
+		fmt.Printf("%-12s %d purged\n", collection, purged)
+	}
+	log.Infof(ctx, "Purged documents deleted before %s", cutoff.Format(time.RFC3339))
+}
+
+func runExportCollection(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("export-collection", flag.ExitOnError)
+	collection := fs.String("collection", "", "collection to export, e.g. reports")
+	out := fs.String("out", "", "output file, e.g. reports.jsonl")
+	compress := fs.Bool("gzip", false, "gzip the output")
+	fs.Parse(args)
+
+	if *collection == "" || *out == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	backupRepo := repository.NewBackupMongoRepository(connectMongo(ctx, cfg, "export-collection"))
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf(ctx, "Failed to create %s: %v", *out, err)
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+
+	var gz *gzip.Writer
+	if *compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	counts, err := backupRepo.Export(ctx, []string{*collection}, w)
+	if err != nil {
+		log.Fatalf(ctx, "Export failed: %v", err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			log.Fatalf(ctx, "Export failed: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf(ctx, "Export failed: %v", err)
+	}
+
+	log.Infof(ctx, "Exported %d documents from %s to %s", counts[*collection], *collection, *out)
+}
+
+func runRestore(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	key := fs.String("key", "", "object store key of the backup, e.g. backups/finsolvz-20240101T000000Z.jsonl.gz")
+	fs.Parse(args)
+
+	if *key == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf(ctx, "Failed to configure storage: %v", err)
+	}
+
+	db := connectMongo(ctx, cfg, "restore")
+	backupService := backup.NewService(repository.NewBackupMongoRepository(db), store, nil, cfg.Backups)
+
+	result, err := backupService.RestoreBackup(ctx, *key)
+	if err != nil {
+		log.Fatalf(ctx, "Restore failed: %v", err)
+	}
+
+	for collection, count := range result.Collections {
+		fmt.Printf("%-12s %d documents\n", collection, count)
+	}
+	log.Infof(ctx, "Restored backup %s", result.Key)
+}
+
+// connectMongo exits unless the configured driver is MongoDB, for commands that only apply to it.
+func connectMongo(ctx context.Context, cfg *config.Config, command string) *mongo.Database {
+	if cfg.Database.Driver != config.DriverMongo {
+		log.Fatalf(ctx, "%s requires DB_DRIVER=mongo", command)
+	}
+
+	db, err := config.ConnectMongoDB(ctx, cfg.Database.MongoURI)
+	if err != nil {
+		log.Fatalf(ctx, "Failed to connect to database: %v", err)
+	}
+	return db
+}
+
+func connectUsers(ctx context.Context, cfg *config.Config) domain.UserRepository {
+	if cfg.Database.Driver == config.DriverPostgres {
+		pg, err := config.ConnectPostgres(ctx, cfg.Database.PostgresDSN)
+		if err != nil {
+			log.Fatalf(ctx, "Failed to connect to database: %v", err)
+		}
+		return repository.NewUserPostgresRepository(pg)
+	}
+	return repository.NewUserMongoRepository(connectMongo(ctx, cfg, "this command"))
+}
+
+func passwordOrGenerate(ctx context.Context, password string) (string, bool) {
+	if password != "" {
+		return password, false
+	}
+	generated, err := utils.GenerateRandomPassword()
+	if err != nil {
+		log.Fatalf(ctx, "Failed to generate password: %v", err)
+	}
+	return generated, true
+}
+
+func isSoftDeleteCollection(name string) bool {
+	for _, c := range domain.SoftDeleteCollections {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
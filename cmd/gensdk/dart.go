@@ -0,0 +1,455 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dart renders lib/models.dart, with an enum or class per schema that has one in Dart, and
+// lib/client.dart, with a package:http based client method per operation. Schemas without
+// a Dart counterpart, such as the oneOf of report data, become typedefs.
+func dart(a *api, header func(comment string) string) map[string]string {
+	var models strings.Builder
+	models.WriteString(header("//"))
+	for _, t := range a.types {
+		models.WriteString("\n" + docLines("///", t.schema.str("description")))
+		switch {
+		case t.isEnum():
+			models.WriteString(dartEnum(t))
+		case t.isClass():
+			models.WriteString(dartClass(a, t))
+		default:
+			models.WriteString("typedef " + t.name + " = " + dartType(a, t.schema, false) + ";\n")
+		}
+	}
+
+	var client strings.Builder
+	client.WriteString(header("//") + "\n")
+	client.WriteString(dartClientPrelude)
+	for _, op := range a.operations {
+		client.WriteString("\n" + dartOperation(a, op))
+	}
+	client.WriteString("}\n")
+
+	return map[string]string{
+		"lib/models.dart": models.String(),
+		"lib/client.dart": client.String(),
+	}
+}
+
+var dartKeywords = map[string]bool{
+	"abstract": true, "as": true, "assert": true, "async": true, "await": true, "break": true,
+	"case": true, "catch": true, "class": true, "const": true, "continue": true, "default": true,
+	"do": true, "dynamic": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "final": true, "finally": true, "for": true, "if": true, "import": true,
+	"in": true, "is": true, "new": true, "null": true, "return": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true, "var": true,
+	"void": true, "while": true, "with": true, "values": true, "index": true, "hashCode": true,
+	"toString": true, "runtimeType": true, "toJson": true,
+}
+
+// dartName is the Dart identifier for the JSON name or enum value s.
+func dartName(s string) string {
+	name := camel(s)
+	if dartKeywords[name] {
+		name += "Value"
+	}
+	return name
+}
+
+func dartEnum(t *typeDef) string {
+	var b strings.Builder
+	b.WriteString("enum " + t.name + " {\n")
+	values := t.schema.list("enum")
+	for i, v := range values {
+		end := ","
+		if i == len(values)-1 {
+			end = ";"
+		}
+		b.WriteString(fmt.Sprintf("  %s(%s)%s\n", dartName(fmt.Sprint(v)), dartString(fmt.Sprint(v)), end))
+	}
+	b.WriteString(fmt.Sprintf(`
+  const %[1]s(this.value);
+
+  final String value;
+
+  static %[1]s fromJson(String json) =>
+      values.firstWhere((v) => v.value == json, orElse: () => throw ArgumentError.value(json, '%[1]s'));
+
+  String toJson() => value;
+}
+`, t.name))
+	return b.String()
+}
+
+func dartClass(a *api, t *typeDef) string {
+	props := t.schema.obj("properties")
+	required := stringSet(t.schema.list("required"))
+
+	var ctor, fromJSON, fields, toJSON strings.Builder
+	for _, key := range props.keys {
+		prop := props.obj(key)
+		name := dartName(key)
+		nullable := !required[key] || prop.flag("nullable")
+		typ := dartType(a, prop, nullable)
+		access := "json[" + dartString(key) + "]"
+
+		if nullable || dartDynamic(a, prop) {
+			ctor.WriteString("    this." + name + ",\n")
+		} else {
+			ctor.WriteString("    required this." + name + ",\n")
+		}
+		fromJSON.WriteString(fmt.Sprintf("        %s: %s,\n", name, dartFromJSON(a, prop, access, nullable, 0)))
+		fields.WriteString(docLines("  ///", prop.str("description")))
+		fields.WriteString(fmt.Sprintf("  final %s %s;\n", typ, name))
+
+		value := dartToJSON(a, prop, name+"!", 0)
+		switch {
+		case value == name+"!" && !required[key] && !dartDynamic(a, prop):
+			toJSON.WriteString(fmt.Sprintf("        if (%s != null) %s: %s,\n", name, dartString(key), name))
+		case value == name+"!" && nullable:
+			toJSON.WriteString(fmt.Sprintf("        %s: %s,\n", dartString(key), name))
+		case !required[key] && !dartDynamic(a, prop):
+			toJSON.WriteString(fmt.Sprintf("        if (%s != null) %s: %s,\n", name, dartString(key), value))
+		case nullable:
+			toJSON.WriteString(fmt.Sprintf("        %s: %s == null ? null : %s,\n", dartString(key), name, value))
+		default:
+			toJSON.WriteString(fmt.Sprintf("        %s: %s,\n", dartString(key), dartToJSON(a, prop, name, 0)))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("class " + t.name + " {\n")
+	if len(props.keys) == 0 {
+		b.WriteString("  const " + t.name + "();\n\n")
+		b.WriteString("  factory " + t.name + ".fromJson(Map<String, dynamic> json) => const " + t.name + "();\n\n")
+		b.WriteString("  Map<String, dynamic> toJson() => {};\n}\n")
+		return b.String()
+	}
+	b.WriteString("  " + t.name + "({\n" + ctor.String() + "  });\n\n")
+	b.WriteString("  factory " + t.name + ".fromJson(Map<String, dynamic> json) => " + t.name + "(\n" + fromJSON.String() + "      );\n\n")
+	b.WriteString(fields.String() + "\n")
+	b.WriteString("  Map<String, dynamic> toJson() => {\n" + toJSON.String() + "      };\n}\n")
+	return b.String()
+}
+
+// dartType renders schema as a Dart type; nullable adds the ?.
+func dartType(a *api, schema *object, nullable bool) string {
+	t := dartBaseType(a, schema)
+	if (nullable || schema.flag("nullable")) && !dartDynamic(a, schema) && !strings.HasSuffix(t, "?") {
+		t += "?"
+	}
+	return t
+}
+
+func dartBaseType(a *api, schema *object) string {
+	if ref := a.ref(schema); ref != nil {
+		return ref.name
+	}
+	if dartDynamic(a, schema) {
+		return "dynamic"
+	}
+	switch schema.str("type") {
+	case "string":
+		switch schema.str("format") {
+		case "date-time":
+			return "DateTime"
+		case "binary":
+			return "List<int>"
+		}
+		return "String"
+	case "integer":
+		return "int"
+	case "number":
+		return "double"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "List<" + dartType(a, schema.obj("items"), false) + ">"
+	case "object":
+		if values := schema.obj("additionalProperties"); values != nil && len(values.keys) > 0 {
+			return "Map<String, " + dartType(a, values, false) + ">"
+		}
+		return "Map<String, dynamic>"
+	}
+	return "dynamic"
+}
+
+// dartDynamic reports whether schema, or the typedef it refers to, is dynamic in Dart,
+// which already admits null.
+func dartDynamic(a *api, schema *object) bool {
+	if ref := a.ref(schema); ref != nil {
+		return !ref.isEnum() && !ref.isClass() && dartDynamic(a, ref.schema)
+	}
+	return len(schema.list("oneOf")) > 0 || schema.str("type") == ""
+}
+
+// dartFromJSON converts the decoded JSON expr to the Dart type of schema. depth names the
+// variables of nested closures.
+func dartFromJSON(a *api, schema *object, expr string, nullable bool, depth int) string {
+	if dartDynamic(a, schema) {
+		return expr
+	}
+	typ := dartBaseType(a, schema)
+	var conv string
+	if ref := a.ref(schema); ref != nil {
+		switch {
+		case ref.isEnum():
+			conv = ref.name + ".fromJson(" + expr + " as String)"
+		case ref.isClass():
+			conv = ref.name + ".fromJson(" + expr + " as Map<String, dynamic>)"
+		default:
+			return dartFromJSON(a, ref.schema, expr, nullable || schema.flag("nullable"), depth)
+		}
+	} else {
+		v := fmt.Sprintf("e%d", depth)
+		switch {
+		case typ == "DateTime":
+			conv = "DateTime.parse(" + expr + " as String)"
+		case typ == "double":
+			conv = "(" + expr + " as num).toDouble()"
+		case strings.HasPrefix(typ, "List<") && schema.str("type") == "array":
+			conv = fmt.Sprintf("(%s as List<dynamic>).map((%s) => %s).toList()", expr, v, dartFromJSON(a, schema.obj("items"), v, false, depth+1))
+		case strings.HasPrefix(typ, "Map<String, ") && typ != "Map<String, dynamic>":
+			conv = fmt.Sprintf("(%s as Map<String, dynamic>).map((k%d, %s) => MapEntry(k%d, %s))", expr, depth, v, depth, dartFromJSON(a, schema.obj("additionalProperties"), v, false, depth+1))
+		default:
+			conv = expr + " as " + typ
+		}
+	}
+	if nullable || schema.flag("nullable") {
+		if strings.HasSuffix(conv, " as "+typ) && !strings.Contains(conv, "(") {
+			return conv + "?"
+		}
+		return expr + " == null ? null : " + conv
+	}
+	return conv
+}
+
+// dartToJSON converts the Dart value expr, which is not null, back to JSON.
+func dartToJSON(a *api, schema *object, expr string, depth int) string {
+	typ := dartBaseType(a, schema)
+	if ref := a.ref(schema); ref != nil {
+		if ref.isEnum() || ref.isClass() {
+			return expr + ".toJson()"
+		}
+		return dartToJSON(a, ref.schema, expr, depth)
+	}
+	v := fmt.Sprintf("e%d", depth)
+	switch {
+	case typ == "DateTime":
+		return expr + ".toIso8601String()"
+	case strings.HasPrefix(typ, "List<") && schema.str("type") == "array":
+		inner := dartToJSON(a, schema.obj("items"), v, depth+1)
+		if inner == v {
+			return expr
+		}
+		if schema.obj("items").flag("nullable") {
+			inner = v + " == null ? null : " + dartToJSON(a, schema.obj("items"), v+"!", depth+1)
+		}
+		return fmt.Sprintf("%s.map((%s) => %s).toList()", expr, v, inner)
+	case strings.HasPrefix(typ, "Map<String, ") && typ != "Map<String, dynamic>":
+		inner := dartToJSON(a, schema.obj("additionalProperties"), v, depth+1)
+		if inner == v {
+			return expr
+		}
+		return fmt.Sprintf("%s.map((k%d, %s) => MapEntry(k%d, %s))", expr, depth, v, depth, inner)
+	}
+	return expr
+}
+
+func dartString(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted[1:len(quoted)-1], `\"`, `"`)
+	return "'" + strings.NewReplacer("'", `\'`, "$", `\$`).Replace(quoted) + "'"
+}
+
+const dartClientPrelude = `
+import 'dart:convert';
+
+import 'package:http/http.dart' as http;
+
+import 'models.dart';
+
+/// Thrown for responses with a 4xx or 5xx status; error holds the decoded ErrorResponse.
+class ApiException implements Exception {
+  ApiException(this.statusCode, this.error);
+
+  final int statusCode;
+  final ErrorResponse? error;
+
+  @override
+  String toString() => 'ApiException($statusCode): ${error?.message ?? 'HTTP $statusCode'}';
+}
+
+class FinsolvzClient {
+  /// [baseUrl] is the server URL without a trailing slash, e.g. http://localhost:8787.
+  /// [token] is the JWT sent as a bearer token; it can be changed after logging in.
+  FinsolvzClient(this.baseUrl, {this.token, http.Client? httpClient}) : _http = httpClient ?? http.Client();
+
+  final String baseUrl;
+  String? token;
+  final http.Client _http;
+
+  void close() => _http.close();
+
+  Future<dynamic> _send(
+    String method,
+    String path, {
+    Map<String, Object?> query = const {},
+    Object? body,
+    List<http.MultipartFile> files = const [],
+    Map<String, String> fields = const {},
+  }) async {
+    final params = {
+      for (final entry in query.entries)
+        if (entry.value != null) entry.key: entry.value.toString(),
+    };
+    var uri = Uri.parse(baseUrl + path);
+    if (params.isNotEmpty) {
+      uri = uri.replace(queryParameters: params);
+    }
+
+    final http.BaseRequest request;
+    if (files.isNotEmpty || fields.isNotEmpty) {
+      request = http.MultipartRequest(method, uri)
+        ..files.addAll(files)
+        ..fields.addAll(fields);
+    } else {
+      final plain = http.Request(method, uri);
+      if (body != null) {
+        plain.headers['Content-Type'] = 'application/json';
+        plain.body = jsonEncode(body);
+      }
+      request = plain;
+    }
+    request.headers['Accept'] = 'application/json';
+    if (token != null) {
+      request.headers['Authorization'] = 'Bearer $token';
+    }
+
+    final response = await http.Response.fromStream(await _http.send(request));
+    final data = response.body.isEmpty ? null : jsonDecode(response.body);
+    if (response.statusCode >= 400) {
+      throw ApiException(
+        response.statusCode,
+        data is Map<String, dynamic> ? ErrorResponse.fromJson(data) : null,
+      );
+    }
+    return data;
+  }
+`
+
+func dartOperation(a *api, op *operation) string {
+	var positional, named []string
+	for _, p := range op.pathParams {
+		positional = append(positional, "String "+dartName(p.name))
+	}
+	if op.body != nil {
+		if op.bodyOptional {
+			named = append(named, dartType(a, op.body, true)+" body")
+		} else {
+			positional = append(positional, dartType(a, op.body, false)+" body")
+		}
+	}
+	for _, p := range op.form {
+		typ := "String"
+		if p.schema.str("format") == "binary" {
+			typ = "http.MultipartFile"
+		}
+		if p.required {
+			positional = append(positional, typ+" "+dartName(p.name))
+		} else {
+			named = append(named, typ+"? "+dartName(p.name))
+		}
+	}
+	for _, p := range op.queryParams {
+		typ := dartType(a, p.schema, !p.required)
+		if p.required {
+			named = append(named, "required "+typ+" "+dartName(p.name))
+		} else {
+			named = append(named, typ+" "+dartName(p.name))
+		}
+	}
+	args := strings.Join(positional, ", ")
+	if len(named) > 0 {
+		if args != "" {
+			args += ", "
+		}
+		args += "{" + strings.Join(named, ", ") + "}"
+	}
+
+	var path strings.Builder
+	for _, segment := range pathSegments(op.path) {
+		if strings.HasPrefix(segment, "{") {
+			path.WriteString("${Uri.encodeComponent(" + dartName(strings.Trim(segment, "{}")) + ")}")
+		} else {
+			path.WriteString(segment)
+		}
+	}
+
+	call := []string{dartString(op.method), "'" + path.String() + "'"}
+	if len(op.queryParams) > 0 {
+		var entries []string
+		for _, p := range op.queryParams {
+			entries = append(entries, dartString(p.name)+": "+dartQueryValue(a, p.schema, dartName(p.name), !p.required))
+		}
+		call = append(call, "query: {"+strings.Join(entries, ", ")+"}")
+	}
+	if op.body != nil {
+		body := dartToJSON(a, op.body, "body", 0)
+		if op.bodyOptional && body != "body" {
+			body = "body == null ? null : " + dartToJSON(a, op.body, "body!", 0)
+		}
+		call = append(call, "body: "+body)
+	}
+	var files, fields []string
+	for _, p := range op.form {
+		name := dartName(p.name)
+		entry := name
+		if !p.required {
+			entry = "if (" + name + " != null) " + name
+		}
+		if p.schema.str("format") == "binary" {
+			files = append(files, entry)
+		} else if p.required {
+			fields = append(fields, dartString(p.name)+": "+name)
+		} else {
+			fields = append(fields, "if ("+name+" != null) "+dartString(p.name)+": "+name)
+		}
+	}
+	if len(files) > 0 {
+		call = append(call, "files: ["+strings.Join(files, ", ")+"]")
+	}
+	if len(fields) > 0 {
+		call = append(call, "fields: {"+strings.Join(fields, ", ")+"}")
+	}
+
+	var b strings.Builder
+	b.WriteString(docLines("  ///", op.summary))
+	if op.deprecated {
+		b.WriteString("  @Deprecated('See the API documentation for its successor')\n")
+	}
+	send := "_send(" + strings.Join(call, ", ") + ")"
+	if op.result == nil {
+		b.WriteString(fmt.Sprintf("  Future<void> %s(%s) async {\n    await %s;\n  }\n", op.id, args, send))
+		return b.String()
+	}
+	result := dartType(a, op.result, false)
+	b.WriteString(fmt.Sprintf("  Future<%s> %s(%s) async {\n", result, op.id, args))
+	b.WriteString("    final json = await " + send + ";\n")
+	b.WriteString("    return " + dartFromJSON(a, op.result, "json", false, 0) + ";\n")
+	b.WriteString("  }\n")
+	return b.String()
+}
+
+func dartQueryValue(a *api, schema *object, name string, nullable bool) string {
+	value := dartToJSON(a, schema, name, 0)
+	if value == name {
+		return name
+	}
+	if nullable {
+		return name + "?." + strings.TrimPrefix(value, name+".")
+	}
+	return value
+}
@@ -0,0 +1,133 @@
+// Command gensdk generates the official TypeScript and Dart client SDKs from
+// api/openapi.yaml, so the clients follow the API without hand-written models:
+//
+//   - sdk/typescript: models.ts with an interface per schema and string unions for enums,
+//     and client.ts with a fetch based FinsolvzClient method per operationId
+//   - sdk/dart: lib/models.dart with classes (fromJson/toJson) and enums, and
+//     lib/client.dart with a package:http based FinsolvzClient
+//
+// Schema names drop the Go package (report.ReportResponse becomes ReportResponse) unless
+// two packages share a name. Nullable properties become "| null" and "?" types; oneOf
+// schemas become unions in TypeScript and dynamic in Dart, which has none. WebSocket routes
+// are left out.
+//
+// The spec is read with a reader for the YAML that cmd/openapi-gen writes, so run
+// `make openapi` first after changing routes.
+//
+// Usage:
+//
+//	go run ./cmd/gensdk                        # both SDKs into sdk/
+//	go run ./cmd/gensdk -lang typescript -out ../web/src/api
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var generators = map[string]func(a *api, header func(comment string) string) map[string]string{
+	"typescript": typescript,
+	"dart":       dart,
+}
+
+func main() {
+	root := flag.String("root", ".", "module root")
+	spec := flag.String("spec", "api/openapi.yaml", "OpenAPI spec to generate from")
+	out := flag.String("out", "sdk", "output directory; each language is written to a subdirectory unless -lang selects one")
+	lang := flag.String("lang", "", "generate only this SDK: typescript or dart")
+	flag.Parse()
+
+	if err := run(*root, *spec, *out, *lang); err != nil {
+		fmt.Fprintln(os.Stderr, "gensdk:", err)
+		os.Exit(1)
+	}
+}
+
+func run(root, specPath, outDir, lang string) error {
+	data, err := os.ReadFile(filepath.Join(root, specPath))
+	if err != nil {
+		return err
+	}
+	doc, err := parseYAML(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specPath, err)
+	}
+	a, err := loadAPI(doc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specPath, err)
+	}
+
+	header := func(comment string) string {
+		return fmt.Sprintf("%s Code generated by cmd/gensdk from %s (%s %s); DO NOT EDIT.\n", comment, specPath, a.title, a.version)
+	}
+
+	langs := []string{lang}
+	if lang == "" {
+		langs = nil
+		for name := range generators {
+			langs = append(langs, name)
+		}
+		sort.Strings(langs)
+	}
+	for _, name := range langs {
+		generate, ok := generators[name]
+		if !ok {
+			return fmt.Errorf("unknown language %q", name)
+		}
+		files := generate(a, header)
+		for path, content := range packageFiles(a, name, header) {
+			files[path] = content
+		}
+
+		dir := outDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		if lang == "" {
+			dir = filepath.Join(dir, name)
+		}
+		for path, content := range files {
+			target := filepath.Join(dir, filepath.FromSlash(path))
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("gensdk: %s SDK (%d types, %d operations) written to %s\n", name, len(a.types), len(a.operations), dir)
+	}
+	return nil
+}
+
+// packageFiles are the entry point and package manifest of each SDK.
+func packageFiles(a *api, lang string, header func(comment string) string) map[string]string {
+	switch lang {
+	case "typescript":
+		return map[string]string{
+			"index.ts": header("//") + "\nexport * from \"./models\";\nexport * from \"./client\";\n",
+		}
+	case "dart":
+		return map[string]string{
+			"lib/finsolvz_api.dart": header("//") + "\nlibrary finsolvz_api;\n\nexport 'client.dart';\nexport 'models.dart';\n",
+			"pubspec.yaml": header("#") + strings.Join([]string{
+				"name: finsolvz_api",
+				"description: Client for the " + a.title + ".",
+				"version: " + a.version,
+				"publish_to: none",
+				"",
+				"environment:",
+				"  sdk: '>=3.0.0 <4.0.0'",
+				"",
+				"dependencies:",
+				"  http: ^1.1.0",
+				"",
+			}, "\n"),
+		}
+	}
+	return nil
+}
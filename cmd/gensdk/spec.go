@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// api is what the SDKs are generated from: the component schemas under their SDK type
+// names, and one operation per path and method.
+type api struct {
+	title      string
+	version    string
+	types      []*typeDef
+	byRef      map[string]*typeDef // "#/components/schemas/report.ReportResponse" -> type
+	operations []*operation
+}
+
+// typeDef is a component schema. Its name is the Go type name, prefixed with the package
+// when two packages export the same name (report.UserInfo and company.UserInfo become
+// ReportUserInfo and CompanyUserInfo) or the name is a builtin of TypeScript or Dart
+// (graphql.Error becomes GraphqlError).
+type typeDef struct {
+	name   string
+	schema *object
+}
+
+func (t *typeDef) isEnum() bool {
+	return len(t.schema.list("enum")) > 0
+}
+
+func (t *typeDef) isClass() bool {
+	return t.schema.str("type") == "object" && t.schema.obj("properties") != nil
+}
+
+type operation struct {
+	id           string
+	method       string // upper case
+	path         string
+	summary      string
+	deprecated   bool
+	pathParams   []param
+	queryParams  []param
+	body         *object // JSON request body schema
+	bodyOptional bool
+	form         []param // multipart/form-data fields
+	result       *object // JSON schema of the first success response, nil when it has no body
+}
+
+type param struct {
+	name     string
+	required bool
+	schema   *object
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// builtins are global types of TypeScript (with the DOM) and Dart that schemas must not
+// shadow.
+var builtins = map[string]bool{
+	"Error": true, "Request": true, "Response": true, "Location": true, "Object": true,
+	"Record": true, "Map": true, "Set": true, "List": true, "Date": true, "DateTime": true,
+	"Type": true, "Function": true, "Promise": true, "Future": true, "Stream": true,
+	"Uri": true, "Duration": true, "Symbol": true,
+}
+
+func loadAPI(doc *object) (*api, error) {
+	a := &api{
+		title:   doc.obj("info").str("title"),
+		version: fmt.Sprint(doc.obj("info").get("version")),
+		byRef:   map[string]*typeDef{},
+	}
+
+	schemas := doc.obj("components").obj("schemas")
+	if schemas == nil {
+		return nil, fmt.Errorf("spec has no components.schemas")
+	}
+	bare := map[string]int{}
+	for _, key := range schemas.keys {
+		bare[typeName(key)]++
+	}
+	for _, key := range schemas.keys {
+		name := typeName(key)
+		if bare[name] > 1 || builtins[name] {
+			pkg, _, _ := strings.Cut(key, ".")
+			name = exported(pkg) + name
+		}
+		t := &typeDef{name: name, schema: schemas.obj(key)}
+		a.types = append(a.types, t)
+		a.byRef[schemaRefPrefix+key] = t
+	}
+	sort.Slice(a.types, func(i, j int) bool { return a.types[i].name < a.types[j].name })
+
+	paths := doc.obj("paths")
+	for _, path := range paths.keys {
+		item := paths.obj(path)
+		for _, method := range item.keys {
+			if item.obj(method).obj("responses").obj("101") != nil {
+				continue // WebSocket upgrades need a socket client, not an HTTP one
+			}
+			op, err := a.operation(path, method, item.obj(method))
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			a.operations = append(a.operations, op)
+		}
+	}
+	return a, nil
+}
+
+func (a *api) operation(path, method string, o *object) (*operation, error) {
+	op := &operation{
+		id:         o.str("operationId"),
+		method:     strings.ToUpper(method),
+		path:       path,
+		summary:    o.str("summary"),
+		deprecated: o.flag("deprecated"),
+	}
+	if op.id == "" {
+		return nil, fmt.Errorf("missing operationId")
+	}
+
+	for _, item := range o.list("parameters") {
+		p, _ := item.(*object)
+		param := param{name: p.str("name"), required: p.flag("required"), schema: p.obj("schema")}
+		switch p.str("in") {
+		case "path":
+			op.pathParams = append(op.pathParams, param)
+		case "query":
+			op.queryParams = append(op.queryParams, param)
+		}
+	}
+
+	if body := o.obj("requestBody"); body != nil {
+		content := body.obj("content")
+		if form := content.obj("multipart/form-data").obj("schema"); form != nil {
+			required := stringSet(form.list("required"))
+			for _, name := range form.obj("properties").keys {
+				op.form = append(op.form, param{name: name, required: required[name], schema: form.obj("properties").obj(name)})
+			}
+		} else {
+			op.body = content.obj("application/json").obj("schema")
+			op.bodyOptional = !body.flag("required")
+		}
+	}
+
+	responses := o.obj("responses")
+	for _, code := range responses.keys {
+		if len(code) != 3 || code[0] != '2' {
+			continue
+		}
+		op.result = a.resolve(responses.obj(code).obj("content").obj("application/json").obj("schema"))
+		break
+	}
+	return op, nil
+}
+
+// resolve drops the {} schema of responses with an unknown body, so they are treated as
+// having none.
+func (a *api) resolve(schema *object) *object {
+	if schema == nil || len(schema.keys) == 0 {
+		return nil
+	}
+	return schema
+}
+
+// ref returns the type schema refers to, looking through the allOf wrapper openapi-gen
+// puts around nullable references.
+func (a *api) ref(schema *object) *typeDef {
+	if ref := schema.str("$ref"); ref != "" {
+		return a.byRef[ref]
+	}
+	if all := schema.list("allOf"); len(all) == 1 {
+		if inner, ok := all[0].(*object); ok {
+			return a.ref(inner)
+		}
+	}
+	return nil
+}
+
+// pathSegments splits an OpenAPI path into its literal parts and parameter names:
+// /api/reports/{id}/export -> ["/api/reports/", "{id}", "/export"].
+func pathSegments(path string) []string {
+	var segments []string
+	for path != "" {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			segments = append(segments, path)
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		if start > 0 {
+			segments = append(segments, path[:start])
+		}
+		segments = append(segments, path[start:end+1])
+		path = path[end+1:]
+	}
+	return segments
+}
+
+func typeName(key string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+func stringSet(items []interface{}) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// words splits an identifier or enum value into words: "SUPER_ADMIN" -> [super admin],
+// "_links" -> [links], "reportTypeId" -> [report type id].
+func words(s string) []string {
+	var out []string
+	var word []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(word) > 0 {
+			out = append(out, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return out
+}
+
+// camel joins the words of s as lowerCamelCase.
+func camel(s string) string {
+	w := words(s)
+	if len(w) == 0 {
+		return "value"
+	}
+	for i := 1; i < len(w); i++ {
+		w[i] = exported(w[i])
+	}
+	name := strings.Join(w, "")
+	if unicode.IsDigit(rune(name[0])) {
+		name = "v" + name
+	}
+	return name
+}
+
+func exported(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// docLines turns a description into comment lines behind prefix.
+func docLines(prefix, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(strings.TrimRight(prefix+" "+line, " ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// typescript renders models.ts, with an interface or type alias per schema, and client.ts,
+// with a fetch based client method per operation.
+func typescript(a *api, header func(comment string) string) map[string]string {
+	var models strings.Builder
+	models.WriteString(header("//"))
+	for _, t := range a.types {
+		models.WriteString("\n" + tsDoc("", t.schema.str("description")))
+		if t.isClass() {
+			models.WriteString("export interface " + t.name + " " + tsObject(a, t.schema, "", "") + "\n")
+		} else {
+			models.WriteString("export type " + t.name + " = " + tsType(a, t.schema, "", "") + ";\n")
+		}
+	}
+
+	var client strings.Builder
+	client.WriteString(header("//") + "\n")
+	client.WriteString(tsClientPrelude)
+	for _, op := range a.operations {
+		client.WriteString("\n" + tsOperation(a, op))
+	}
+	client.WriteString("}\n")
+
+	return map[string]string{
+		"models.ts": models.String(),
+		"client.ts": client.String(),
+	}
+}
+
+const tsClientPrelude = `
+import type * as m from "./models";
+
+/** Thrown for responses with a 4xx or 5xx status; error holds the decoded ErrorResponse. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly error?: m.ErrorResponse,
+  ) {
+    super(error?.message ?? ` + "`HTTP ${status}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Server URL without a trailing slash, e.g. http://localhost:8787 */
+  baseUrl: string;
+  /** JWT sent as a bearer token, or a function returning the current one */
+  token?: string | (() => string | undefined);
+  /** Replaces the global fetch, e.g. in tests */
+  fetch?: typeof fetch;
+}
+
+type Query = Record<string, string | number | boolean | undefined>;
+
+export class FinsolvzClient {
+  constructor(private readonly options: ClientOptions) {}
+
+  private async request<T>(method: string, path: string, query?: Query, body?: unknown, form?: FormData): Promise<T> {
+    const url = new URL(this.options.baseUrl + path);
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined) url.searchParams.set(key, String(value));
+    }
+
+    const headers: Record<string, string> = { Accept: "application/json" };
+    const token = typeof this.options.token === "function" ? this.options.token() : this.options.token;
+    if (token) headers.Authorization = ` + "`Bearer ${token}`" + `;
+    let payload: BodyInit | undefined = form;
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      payload = JSON.stringify(body);
+    }
+
+    const response = await (this.options.fetch ?? fetch)(url, { method, headers, body: payload });
+    const text = await response.text();
+    const data = text ? JSON.parse(text) : undefined;
+    if (!response.ok) {
+      throw new ApiError(response.status, data as m.ErrorResponse | undefined);
+    }
+    return data as T;
+  }
+`
+
+func tsOperation(a *api, op *operation) string {
+	var args []string
+	for _, p := range op.pathParams {
+		args = append(args, camel(p.name)+": string")
+	}
+	if op.body != nil {
+		arg := "body: " + tsType(a, op.body, "m.", "  ")
+		if op.bodyOptional {
+			arg = "body?: " + tsType(a, op.body, "m.", "  ")
+		}
+		args = append(args, arg)
+	}
+	for _, p := range op.form {
+		args = append(args, camel(p.name)+optional(!p.required, "?")+": "+tsFormType(a, p.schema))
+	}
+	query := "undefined"
+	if len(op.queryParams) > 0 {
+		var fields []string
+		required := false
+		for _, p := range op.queryParams {
+			fields = append(fields, tsKey(p.name)+optional(!p.required, "?")+": "+tsType(a, p.schema, "m.", "  "))
+			required = required || p.required
+		}
+		args = append(args, "query"+optional(!required, "?")+": { "+strings.Join(fields, "; ")+" }")
+		query = "query"
+	}
+
+	result := "void"
+	if op.result != nil {
+		result = tsType(a, op.result, "m.", "  ")
+	}
+
+	var path strings.Builder
+	path.WriteString("`")
+	for _, segment := range pathSegments(op.path) {
+		if strings.HasPrefix(segment, "{") {
+			path.WriteString("${encodeURIComponent(" + camel(strings.Trim(segment, "{}")) + ")}")
+		} else {
+			path.WriteString(segment)
+		}
+	}
+	path.WriteString("`")
+
+	body, form := "undefined", "undefined"
+	var b strings.Builder
+	doc := op.summary
+	if op.deprecated {
+		doc += "\n\n@deprecated"
+	}
+	b.WriteString(tsDoc("  ", doc))
+	b.WriteString(fmt.Sprintf("  %s(%s): Promise<%s> {\n", op.id, strings.Join(args, ", "), result))
+	if op.body != nil {
+		body = "body"
+	}
+	if len(op.form) > 0 {
+		b.WriteString("    const form = new FormData();\n")
+		for _, p := range op.form {
+			name := camel(p.name)
+			set := fmt.Sprintf("form.set(%s, %s);", strconv.Quote(p.name), tsFormValue(p.schema, name))
+			if p.required {
+				b.WriteString("    " + set + "\n")
+			} else {
+				b.WriteString(fmt.Sprintf("    if (%s !== undefined) %s\n", name, set))
+			}
+		}
+		form = "form"
+	}
+	call := []string{strconv.Quote(op.method), path.String(), query, body, form}
+	for call[len(call)-1] == "undefined" {
+		call = call[:len(call)-1]
+	}
+	b.WriteString("    return this.request(" + strings.Join(call, ", ") + ");\n")
+	b.WriteString("  }\n")
+	return b.String()
+}
+
+func tsFormType(a *api, schema *object) string {
+	if schema.str("format") == "binary" {
+		return "Blob"
+	}
+	return tsType(a, schema, "m.", "  ")
+}
+
+func tsFormValue(schema *object, name string) string {
+	if schema.str("format") == "binary" {
+		return name
+	}
+	return "String(" + name + ")"
+}
+
+// tsType renders schema as a TypeScript type, naming components with prefix.
+func tsType(a *api, schema *object, prefix, indent string) string {
+	t := tsBaseType(a, schema, prefix, indent)
+	if schema.flag("nullable") {
+		t += " | null"
+	}
+	return t
+}
+
+func tsBaseType(a *api, schema *object, prefix, indent string) string {
+	if ref := a.ref(schema); ref != nil {
+		return prefix + ref.name
+	}
+	if variants := schema.list("oneOf"); len(variants) > 0 {
+		var types []string
+		for _, v := range variants {
+			types = append(types, tsGroup(tsType(a, v.(*object), prefix, indent)))
+		}
+		return strings.Join(types, " | ")
+	}
+	if values := schema.list("enum"); len(values) > 0 {
+		var literals []string
+		for _, v := range values {
+			literals = append(literals, strconv.Quote(fmt.Sprint(v)))
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch schema.str("type") {
+	case "string":
+		if schema.str("format") == "binary" {
+			return "Blob"
+		}
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return tsGroup(tsType(a, schema.obj("items"), prefix, indent)) + "[]"
+	case "object":
+		if schema.obj("properties") != nil {
+			return tsObject(a, schema, prefix, indent)
+		}
+		if values := schema.obj("additionalProperties"); values != nil {
+			return "Record<string, " + tsType(a, values, prefix, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+func tsObject(a *api, schema *object, prefix, indent string) string {
+	props := schema.obj("properties")
+	if len(props.keys) == 0 {
+		return "{}"
+	}
+	required := stringSet(schema.list("required"))
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range props.keys {
+		prop := props.obj(name)
+		b.WriteString(tsDoc(indent+"  ", prop.str("description")))
+		b.WriteString(fmt.Sprintf("%s  %s%s: %s;\n", indent, tsKey(name), optional(!required[name], "?"), tsType(a, prop, prefix, indent+"  ")))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// tsGroup parenthesizes unions before they are used as array items.
+func tsGroup(t string) string {
+	if strings.Contains(t, " | ") && !strings.HasPrefix(t, "{") {
+		return "(" + t + ")"
+	}
+	return t
+}
+
+func tsKey(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+func tsDoc(indent, text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "*/", "*\\/"))
+	if text == "" {
+		return ""
+	}
+	if !strings.Contains(text, "\n") {
+		return indent + "/** " + text + " */\n"
+	}
+	return indent + "/**\n" + docLines(indent+" *", text) + indent + " */\n"
+}
+
+func optional(cond bool, s string) string {
+	if cond {
+		return s
+	}
+	return ""
+}
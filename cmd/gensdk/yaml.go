@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// object is a YAML mapping that keeps the order of its keys, so the SDKs list properties
+// and operations the way the spec does.
+type object struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func (o *object) get(key string) interface{} {
+	if o == nil {
+		return nil
+	}
+	return o.vals[key]
+}
+
+func (o *object) obj(key string) *object {
+	v, _ := o.get(key).(*object)
+	return v
+}
+
+func (o *object) list(key string) []interface{} {
+	v, _ := o.get(key).([]interface{})
+	return v
+}
+
+func (o *object) str(key string) string {
+	v, _ := o.get(key).(string)
+	return v
+}
+
+func (o *object) flag(key string) bool {
+	v, _ := o.get(key).(bool)
+	return v
+}
+
+// parseYAML reads the subset of YAML found in api/openapi.yaml: block mappings and
+// sequences, plain and double-quoted scalars, literal blocks (|), {} and []. It is not a
+// general YAML parser; openapi-gen writes nothing else.
+func parseYAML(data []byte) (*object, error) {
+	r := &yamlReader{lines: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")}
+	r.skip()
+	if r.pos == len(r.lines) {
+		return &object{vals: map[string]interface{}{}}, nil
+	}
+	doc, err := r.mapping(r.indent())
+	if err != nil {
+		return nil, err
+	}
+	if r.skip(); r.pos < len(r.lines) {
+		return nil, r.errorf("unexpected indentation")
+	}
+	return doc, nil
+}
+
+type yamlReader struct {
+	lines []string
+	pos   int
+}
+
+func (r *yamlReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", r.pos+1, fmt.Sprintf(format, args...))
+}
+
+// skip moves past blank and comment lines.
+func (r *yamlReader) skip() {
+	for r.pos < len(r.lines) {
+		text := strings.TrimSpace(r.lines[r.pos])
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return
+		}
+		r.pos++
+	}
+}
+
+func (r *yamlReader) indent() int {
+	line := r.lines[r.pos]
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func (r *yamlReader) text() string {
+	return strings.TrimSpace(r.lines[r.pos])
+}
+
+// block reads the mapping or sequence starting at the current line.
+func (r *yamlReader) block() (interface{}, error) {
+	indent := r.indent()
+	if strings.HasPrefix(r.text(), "- ") || r.text() == "-" {
+		return r.sequence(indent)
+	}
+	return r.mapping(indent)
+}
+
+func (r *yamlReader) mapping(indent int) (*object, error) {
+	m := &object{vals: map[string]interface{}{}}
+	for r.skip(); r.pos < len(r.lines) && r.indent() == indent; r.skip() {
+		key, rest, ok := splitKey(r.text())
+		if !ok {
+			return nil, r.errorf("expected a key in %q", r.text())
+		}
+		value, err := r.value(indent, rest)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := m.vals[key]; !dup {
+			m.keys = append(m.keys, key)
+		}
+		m.vals[key] = value
+	}
+	return m, nil
+}
+
+func (r *yamlReader) sequence(indent int) ([]interface{}, error) {
+	var items []interface{}
+	for r.skip(); r.pos < len(r.lines) && r.indent() == indent; r.skip() {
+		text := r.text()
+		if !strings.HasPrefix(text, "-") {
+			break
+		}
+		item := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+		if _, _, ok := splitKey(item); ok {
+			// A mapping starting on the dash line; its other keys are indented past the dash
+			r.lines[r.pos] = strings.Repeat(" ", indent+2) + item
+			m, err := r.mapping(indent + 2)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, m)
+			continue
+		}
+		value, err := r.value(indent, item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+// value reads what follows "key:" or "-" on a line at indent: a scalar, a literal block or
+// a nested block on the next lines.
+func (r *yamlReader) value(indent int, rest string) (interface{}, error) {
+	r.pos++
+	switch rest {
+	case "":
+		if r.skip(); r.pos < len(r.lines) && r.indent() > indent {
+			return r.block()
+		}
+		return nil, nil
+	case "|":
+		return r.literal(indent), nil
+	}
+	return scalar(rest)
+}
+
+// literal reads a | block, keeping its line breaks and a single final one.
+func (r *yamlReader) literal(indent int) string {
+	var lines []string
+	blockIndent := -1
+	for ; r.pos < len(r.lines); r.pos++ {
+		line := r.lines[r.pos]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if r.indent() <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = r.indent()
+		}
+		lines = append(lines, strings.TrimRight(line[min(blockIndent, r.indent()):], " "))
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// splitKey splits "key: rest" or "key:"; keys may be double-quoted.
+func splitKey(text string) (key, rest string, ok bool) {
+	if strings.HasPrefix(text, `"`) {
+		quoted, err := strconv.QuotedPrefix(text)
+		if err != nil {
+			return "", "", false
+		}
+		after := text[len(quoted):]
+		if !strings.HasPrefix(after, ":") {
+			return "", "", false
+		}
+		key, _ = strconv.Unquote(quoted)
+		return key, strings.TrimSpace(after[1:]), true
+	}
+	if strings.HasSuffix(text, ":") {
+		return text[:len(text)-1], "", true
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return text[:i], strings.TrimSpace(text[i+2:]), true
+	}
+	return "", "", false
+}
+
+func scalar(text string) (interface{}, error) {
+	switch text {
+	case "{}":
+		return &object{vals: map[string]interface{}{}}, nil
+	case "[]":
+		return []interface{}{}, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		return strconv.Unquote(text)
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return text, nil
+}
@@ -0,0 +1,398 @@
+// Command openapi-gen derives api/openapi.yaml from the routes registered in code, so the
+// spec cannot drift from the router. It reads RegisterRoutes of every package below
+// internal/app and the routes the application container in internal/app registers inline,
+// and documents each route from:
+//
+//   - the path, methods, auth middleware, RequirePermission roles (per the built-in access
+//     policy) and Deprecated marker of its registration
+//   - the handler's doc comment, or the comment above its registration (first sentence
+//     as summary, the rest as description)
+//   - the request body decoded with utils.DecodeJSON, files read with utils.MultipartFile
+//     and the query parameters read from r.URL.Query(), utils.GetPaginationParams and
+//     utils.WithProjection
+//   - the utils.RespondJSON calls, resolving service results to their DTOs, and the
+//     headers set by utils.SetPaginationHeaders and the row formats of utils.NewRowWriter
+//
+// Annotations in the doc comment override or complete the inference:
+//
+//	// @Tags Reports                                (on RegisterRoutes: tag of all its routes)
+//	// @Summary List reports
+//	// @Description Longer text
+//	// @Param status query string false "Filter by status"
+//	// @Success 200 {array} domain.Report "Reports visible to the user"
+//	// @Failure 404 {object} utils.ErrorResponse "Not found"
+//
+// Types whose JSON takes several shapes list them on their declaration with @OneOf (and
+// @Discriminator when a property names the shape), e.g. report.ReportData.
+//
+// The info, servers and tags sections are copied from api/openapi.header.yaml.
+//
+// Usage:
+//
+//	go run ./cmd/openapi-gen          # rewrite api/openapi.yaml
+//	go run ./cmd/openapi-gen -check   # fail if api/openapi.yaml is out of date
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const generatedHeader = "# Code generated by cmd/openapi-gen from api/openapi.header.yaml and the route\n" +
+	"# registrations; DO NOT EDIT. Run `make openapi` after changing routes or handlers.\n"
+
+func main() {
+	root := flag.String("root", ".", "module root")
+	header := flag.String("header", "api/openapi.header.yaml", "spec header with info, servers and tags")
+	out := flag.String("out", "api/openapi.yaml", "generated spec")
+	check := flag.Bool("check", false, "fail if the generated spec differs from -out instead of writing it")
+	flag.Parse()
+
+	if err := run(*root, *header, *out, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "openapi-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(root, headerPath, outPath string, check bool) error {
+	m, err := loadModule(root, "api", "internal")
+	if err != nil {
+		return err
+	}
+
+	header, err := os.ReadFile(filepath.Join(root, headerPath))
+	if err != nil {
+		return err
+	}
+
+	spec := generatedHeader + "\n" + strings.TrimRight(string(header), "\n") + "\n\n" + toYAML(document(m))
+
+	target := filepath.Join(root, outPath)
+	if check {
+		current, err := os.ReadFile(target)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, []byte(spec)) {
+			return fmt.Errorf("%s is out of date; run `make openapi`", outPath)
+		}
+		return nil
+	}
+	return os.WriteFile(target, []byte(spec), 0o644)
+}
+
+var methodOrder = map[string]int{"get": 0, "post": 1, "put": 2, "patch": 3, "delete": 4}
+
+func document(m *module) *omap {
+	s := newSchemas(m)
+	utilsPkg := m.pkgs[m.path+"/internal/utils"]
+
+	paths := newMap()
+	operationIDs := map[string]int{}
+	for _, r := range moduleRoutes(m, m.path+"/internal/app") {
+		path, params := normalizePath(r.path)
+		if strings.HasPrefix(path, "/debug/") {
+			continue
+		}
+
+		item, ok := paths.get(path)
+		if !ok {
+			item = newMap()
+			paths.set(path, item)
+		}
+		item.(*omap).set(r.method, operation(s, utilsPkg, r, path, params, operationIDs))
+	}
+
+	paths.sortKeys(func(a, b string) bool { return a < b })
+	for _, key := range paths.keys {
+		item := paths.vals[key].(*omap)
+		item.sortKeys(func(a, b string) bool { return methodOrder[a] < methodOrder[b] })
+	}
+
+	s.components.sortKeys(func(a, b string) bool { return a < b })
+	components := newMap(
+		"securitySchemes", newMap("BearerAuth", newMap(
+			"type", "http",
+			"scheme", "bearer",
+			"bearerFormat", "JWT",
+			"description", "JWT token obtained from login endpoint",
+		)),
+		"schemas", s.components,
+	)
+	return newMap("paths", paths, "components", components)
+}
+
+func operation(s *schemas, utilsPkg *pkg, r *route, path string, pathParams []string, operationIDs map[string]int) *omap {
+	h := r.handler
+	info := analyze(s, h)
+
+	name := lowerFirst(h.name)
+	if name == "" {
+		name = operationName(r.method, path)
+	}
+	if id := h.annotation("@ID"); id != "" {
+		name = id
+	}
+	if n := operationIDs[name]; n > 0 {
+		operationIDs[name]++
+		name = fmt.Sprintf("%s%d", name, n+1)
+	} else {
+		operationIDs[name] = 1
+	}
+
+	summaryText, description := summary(h, upperFirst(strings.TrimSpace(r.method+" "+path)))
+	if len(r.roles) > 0 {
+		requires := "Requires role " + strings.Join(r.roles, " or ") + "."
+		description = strings.TrimSpace(description + "\n\n" + requires)
+	}
+	if d := r.deprecation; d != nil {
+		notice := "Deprecated"
+		if d["Since"] != "" {
+			notice += " since " + d["Since"]
+		}
+		notice += "."
+		if d["Successor"] != "" {
+			notice += " Use " + d["Successor"] + " instead."
+		}
+		if d["Sunset"] != "" {
+			notice += " Removed after " + d["Sunset"] + "."
+		}
+		description = strings.TrimSpace(description + "\n\n" + notice)
+	}
+
+	op := newMap("summary", summaryText)
+	if description != "" {
+		op.set("description", description)
+	}
+	op.set("operationId", name)
+
+	tag := r.tag
+	if t := h.annotation("@Tags"); t != "" {
+		tag = t
+	}
+	if tag == "" {
+		tag = "General"
+		if strings.HasPrefix(path, "/api/admin") {
+			tag = "Administration"
+		}
+	}
+	op.set("tags", []interface{}{tag})
+	if r.deprecation != nil {
+		op.set("deprecated", true)
+	}
+
+	if r.auth {
+		op.set("security", []interface{}{newMap("BearerAuth", []interface{}{})})
+	}
+	if len(r.roles) > 0 {
+		roles := []interface{}{}
+		for _, role := range r.roles {
+			roles = append(roles, role)
+		}
+		op.set("x-roles", roles)
+	}
+
+	if params := parameters(h, pathParams, info.query); len(params) > 0 {
+		op.set("parameters", params)
+	}
+
+	if info.request != nil {
+		op.set("requestBody", newMap(
+			"required", true,
+			"content", newMap("application/json", newMap("schema", info.request)),
+		))
+	} else if len(info.multipart) > 0 {
+		properties, required := newMap(), []interface{}{}
+		for _, field := range info.multipart {
+			properties.set(field, newMap("type", "string", "format", "binary"))
+			required = append(required, field)
+		}
+		op.set("requestBody", newMap(
+			"required", true,
+			"content", newMap("multipart/form-data", newMap("schema", newMap(
+				"type", "object",
+				"required", required,
+				"properties", properties,
+			))),
+		))
+	}
+
+	op.set("responses", responses(s, utilsPkg, h, r, info))
+	return op
+}
+
+var projectionParams = map[string]string{
+	"fields": "Comma-separated fields to return, with dot paths for nested fields (_id is always returned)",
+	"expand": "Comma-separated relations to return in full instead of as {_id}",
+}
+
+// parameters lists path parameters, query parameters read by the handler and @Param
+// annotations, which may add a description or parameters the handler reads indirectly.
+func parameters(h handler, pathParams, query []string) []interface{} {
+	byKey := newMap()
+	for _, name := range pathParams {
+		byKey.set("path:"+name, newMap("name", name, "in", "path", "required", true, "schema", newMap("type", "string")))
+	}
+	for _, name := range query {
+		schema := newMap("type", "string")
+		if name == "page" || name == "limit" {
+			schema = newMap("type", "integer", "minimum", 1)
+		}
+		param := newMap("name", name, "in", "query", "required", false, "schema", schema)
+		if desc, ok := projectionParams[name]; ok {
+			param.set("description", desc)
+		}
+		byKey.set("query:"+name, param)
+	}
+
+	for _, line := range annotations(h.doc, "@Param") {
+		// @Param name in type required "description"
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		param := newMap("name", fields[0], "in", fields[1], "required", fields[3] == "true" || fields[1] == "path")
+		if _, desc, ok := strings.Cut(line, `"`); ok {
+			param.set("description", strings.TrimSuffix(desc, `"`))
+		}
+		param.set("schema", newMap("type", fields[2]))
+		byKey.set(fields[1]+":"+fields[0], param)
+	}
+
+	params := []interface{}{}
+	for _, key := range byKey.keys {
+		params = append(params, byKey.vals[key])
+	}
+	return params
+}
+
+func responses(s *schemas, utilsPkg *pkg, h handler, r *route, info *analysis) *omap {
+	jsonContent := func(schema *omap) *omap {
+		return newMap("application/json", newMap("schema", schema))
+	}
+	errorSchema := s.ref(utilsPkg, "ErrorResponse")
+
+	byCode := map[string]*omap{}
+	for code, schema := range info.responses {
+		response := newMap("description", statusText(code))
+		if code == http.StatusOK && info.paginationHeaders {
+			response.set("headers", newMap(
+				"X-Total-Count", newMap("description", "Total number of items", "schema", newMap("type", "integer")),
+				"Link", newMap("description", "RFC 5988 links to the first, prev, next and last pages", "schema", newMap("type", "string")),
+			))
+		}
+		if schema != nil {
+			content := jsonContent(schema)
+			if code == http.StatusOK && info.rows {
+				item := schema
+				if items, ok := schema.get("items"); ok {
+					item = items.(*omap)
+				}
+				content.set("text/csv", newMap("schema", newMap("type", "string")))
+				content.set("application/x-ndjson", newMap("schema", item))
+			}
+			response.set("content", content)
+		}
+		byCode[fmt.Sprint(code)] = response
+	}
+
+	for _, kind := range []string{"@Success", "@Failure"} {
+		for _, line := range annotations(h.doc, kind) {
+			// @Success 200 {object|array} pkg.Type "description"
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			response := newMap("description", statusText(atoi(fields[0])))
+			if _, desc, ok := strings.Cut(line, `"`); ok {
+				response.set("description", strings.TrimSuffix(desc, `"`))
+			}
+			if len(fields) >= 3 && strings.HasPrefix(fields[1], "{") {
+				schema := annotatedType(s, h, fields[2])
+				if fields[1] == "{array}" {
+					schema = newMap("type", "array", "items", schema)
+				}
+				response.set("content", jsonContent(schema))
+			}
+			byCode[fields[0]] = response
+		}
+	}
+
+	if len(byCode) == 0 {
+		byCode["200"] = newMap("description", "OK")
+	}
+	if r.auth {
+		setDefault(byCode, "401", newMap("description", "Missing or invalid token", "content", jsonContent(errorSchema)))
+	}
+	if len(r.roles) > 0 {
+		setDefault(byCode, "403", newMap("description", "Insufficient role", "content", jsonContent(errorSchema)))
+	}
+	if info.errors {
+		setDefault(byCode, "default", newMap("description", "Error", "content", jsonContent(errorSchema)))
+	}
+
+	codes := make([]string, 0, len(byCode))
+	for code := range byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes) // "default" sorts after the numeric codes
+
+	result := newMap()
+	for _, code := range codes {
+		result.set(code, byCode[code])
+	}
+	return result
+}
+
+func setDefault(byCode map[string]*omap, code string, response *omap) {
+	if _, ok := byCode[code]; !ok {
+		byCode[code] = response
+	}
+}
+
+// annotatedType resolves "Type" in the handler's package or "pkg.Type" anywhere in the module.
+func annotatedType(s *schemas, h handler, name string) *omap {
+	qualifier, typeName, ok := strings.Cut(name, ".")
+	if !ok {
+		return s.ref(h.pkg, name)
+	}
+	if p := s.m.lookup(h.pkg, h.file, qualifier); p != nil {
+		return s.ref(p, typeName)
+	}
+	for _, importPath := range sortedKeys(s.m.pkgs) {
+		if p := s.m.pkgs[importPath]; p.name == qualifier {
+			if _, ok := p.types[typeName]; ok {
+				return s.ref(p, typeName)
+			}
+		}
+	}
+	return newMap()
+}
+
+func statusText(code int) string {
+	if text := http.StatusText(code); text != "" {
+		return text
+	}
+	return "Response"
+}
+
+func atoi(s string) int {
+	n := 0
+	fmt.Sscan(s, &n)
+	return n
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,578 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// analysis is what the body of a handler reveals about its request and responses.
+type analysis struct {
+	request   *omap
+	multipart []string
+	query     []string
+	responses map[int]*omap // nil schema for responses without a JSON body
+	errors    bool
+
+	// paginationHeaders is set when the handler calls utils.SetPaginationHeaders
+	paginationHeaders bool
+	// rows is set when the handler streams its list as CSV or NDJSON with utils.NewRowWriter
+	rows bool
+}
+
+type analyzer struct {
+	s        *schemas
+	h        handler
+	result   *analysis
+	visited  map[*ast.BlockStmt]bool
+	queryVar map[string]bool
+}
+
+// analyze inspects the handler body and the helper methods it calls.
+func analyze(s *schemas, h handler) *analysis {
+	a := &analyzer{s: s, h: h, result: &analysis{responses: map[int]*omap{}}, visited: map[*ast.BlockStmt]bool{}, queryVar: map[string]bool{}}
+	if h.body != nil {
+		a.inspect(h)
+	}
+	return a.result
+}
+
+func (a *analyzer) inspect(h handler) {
+	if a.visited[h.body] {
+		return
+	}
+	a.visited[h.body] = true
+	scope := &scope{a: a, h: h}
+
+	ast.Inspect(h.body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 1 && len(n.Rhs) == 1 && isQueryCall(n.Rhs[0]) {
+				if ident, ok := n.Lhs[0].(*ast.Ident); ok {
+					a.queryVar[ident.Name] = true
+				}
+			}
+		case *ast.CallExpr:
+			a.call(scope, n)
+		}
+		return true
+	})
+}
+
+func (a *analyzer) call(scope *scope, call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	qualifier := ""
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		qualifier = ident.Name
+	}
+
+	switch {
+	case qualifier == "utils" && sel.Sel.Name == "DecodeJSON" && len(call.Args) == 2:
+		if a.result.request == nil {
+			a.result.request = scope.schema(call.Args[1])
+		}
+	case qualifier == "utils" && sel.Sel.Name == "MultipartFile" && len(call.Args) == 2:
+		if field, ok := stringLit(call.Args[1]); ok {
+			a.result.multipart = append(a.result.multipart, field)
+		}
+	case qualifier == "utils" && sel.Sel.Name == "GetPaginationParams":
+		a.result.query = append(a.result.query, "page", "limit")
+	case qualifier == "utils" && sel.Sel.Name == "SetPaginationHeaders":
+		a.result.paginationHeaders = true
+	case qualifier == "utils" && sel.Sel.Name == "NewRowWriter":
+		a.result.rows = true
+	case qualifier == "utils" && sel.Sel.Name == "WithProjection":
+		a.result.query = append(a.result.query, "fields", "expand")
+	case qualifier == "utils" && (sel.Sel.Name == "HandleHTTPError" || sel.Sel.Name == "HandleValidationError"):
+		a.result.errors = true
+	case qualifier == "utils" && sel.Sel.Name == "RespondJSON" && len(call.Args) == 3:
+		schema := scope.schema(call.Args[2])
+		for _, status := range scope.statuses(call.Args[1]) {
+			if _, ok := a.result.responses[status]; !ok {
+				a.result.responses[status] = schema
+			}
+		}
+	case sel.Sel.Name == "WriteHeader" && len(call.Args) == 1:
+		for _, status := range scope.statuses(call.Args[0]) {
+			if _, ok := a.result.responses[status]; !ok {
+				a.result.responses[status] = nil
+			}
+		}
+	case sel.Sel.Name == "Get" && len(call.Args) == 1 && (isQueryCall(sel.X) || a.queryVar[qualifier]):
+		if name, ok := stringLit(call.Args[0]); ok {
+			a.result.query = append(a.result.query, name)
+		}
+	case qualifier == "h":
+		// Helpers of the handler, e.g. createBackupTask, respond on its behalf
+		key := "Handler." + sel.Sel.Name
+		if decl, ok := a.h.pkg.funcs[key]; ok && decl.Body != nil {
+			a.inspect(handler{name: sel.Sel.Name, pkg: a.h.pkg, file: a.h.pkg.funcFiles[key], fn: decl.Type, body: decl.Body})
+		}
+	}
+}
+
+func isQueryCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Query" && len(call.Args) == 0
+}
+
+// typed is a type expression together with where it was written.
+type typed struct {
+	pkg  *pkg
+	file *ast.File
+	expr ast.Expr
+}
+
+// scope resolves the types of expressions inside one function body.
+type scope struct {
+	a *analyzer
+	h handler
+}
+
+// schema returns the JSON schema of the value of expr.
+func (sc *scope) schema(expr ast.Expr) *omap {
+	return sc.schemaDepth(expr, 0)
+}
+
+func (sc *scope) schemaDepth(expr ast.Expr, depth int) *omap {
+	if depth > 4 {
+		return newMap()
+	}
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return sc.schemaDepth(e.X, depth+1)
+		}
+	case *ast.CompositeLit:
+		if mt, ok := e.Type.(*ast.MapType); ok && len(e.Elts) > 0 {
+			if key, ok := mt.Key.(*ast.Ident); ok && key.Name == "string" {
+				properties := newMap()
+				for _, elt := range e.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					if name, ok := stringLit(kv.Key); ok {
+						properties.set(name, sc.schemaDepth(kv.Value, depth+1))
+					}
+				}
+				return newMap("type", "object", "properties", properties)
+			}
+		}
+	case *ast.Ident:
+		// Map literals assigned to a variable keep their keys, including ones added later
+		if value := sc.definition(e.Name); value != nil {
+			if lit, ok := value.(*ast.CompositeLit); ok {
+				if _, ok := lit.Type.(*ast.MapType); ok && len(lit.Elts) > 0 {
+					schema := sc.schemaDepth(lit, depth+1)
+					if properties, ok := schema.get("properties"); ok {
+						sc.indexAssignments(e.Name, properties.(*omap), depth)
+					}
+					return schema
+				}
+			}
+		}
+	}
+
+	t := sc.typeOf(expr, depth)
+	if t == nil {
+		return newMap()
+	}
+	return sc.a.s.of(t.pkg, t.file, t.expr)
+}
+
+// indexAssignments adds the m["key"] = value assignments of a map variable to its properties.
+func (sc *scope) indexAssignments(name string, properties *omap, depth int) {
+	ast.Inspect(sc.h.body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return true
+		}
+		index, ok := assign.Lhs[0].(*ast.IndexExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := index.X.(*ast.Ident); ok && ident.Name == name {
+			if key, ok := stringLit(index.Index); ok {
+				properties.set(key, sc.schemaDepth(assign.Rhs[0], depth+1))
+			}
+		}
+		return true
+	})
+}
+
+// definition returns the expression first assigned to a local variable.
+func (sc *scope) definition(name string) ast.Expr {
+	var value ast.Expr
+	ast.Inspect(sc.h.body, func(n ast.Node) bool {
+		if value != nil {
+			return false
+		}
+		if assign, ok := n.(*ast.AssignStmt); ok && len(assign.Lhs) == len(assign.Rhs) {
+			for i, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+					value = assign.Rhs[i]
+				}
+			}
+		}
+		return true
+	})
+	return value
+}
+
+// typeOf infers the static type of expr as far as the generator needs it.
+func (sc *scope) typeOf(expr ast.Expr, depth int) *typed {
+	if depth > 6 {
+		return nil
+	}
+	builtin := func(name string) *typed { return &typed{pkg: sc.h.pkg, file: sc.h.file, expr: ast.NewIdent(name)} }
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return builtin("string")
+		case token.INT:
+			return builtin("int")
+		case token.FLOAT:
+			return builtin("float64")
+		}
+	case *ast.UnaryExpr:
+		return sc.typeOf(e.X, depth+1)
+	case *ast.StarExpr:
+		return sc.typeOf(e.X, depth+1)
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return &typed{pkg: sc.h.pkg, file: sc.h.file, expr: e.Type}
+		}
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return builtin("bool")
+		}
+		return sc.identType(e.Name, depth)
+	case *ast.CallExpr:
+		return sc.callResult(e, 0, depth)
+	case *ast.SelectorExpr:
+		owner := sc.typeOf(e.X, depth+1)
+		if owner == nil {
+			return nil
+		}
+		if p, file, st := sc.a.s.m.structOf(owner.pkg, owner.file, owner.expr); st != nil {
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					if name.Name == e.Sel.Name {
+						return &typed{pkg: p, file: file, expr: field.Type}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// identType finds the declaration of a local variable or parameter.
+func (sc *scope) identType(name string, depth int) *typed {
+	if sc.h.fn != nil {
+		if found := sc.param(sc.h.fn.Params, name); found != nil {
+			return found
+		}
+	}
+
+	found := sc.declared(sc.h.body, name, depth)
+	if found == nil && sc.h.outer != nil {
+		if found = sc.param(sc.h.outer.Recv, name); found == nil {
+			found = sc.param(sc.h.outer.Type.Params, name)
+		}
+		if found == nil {
+			found = sc.declared(sc.h.outer.Body, name, depth)
+		}
+	}
+	return found
+}
+
+// param finds the receiver or parameter called name in fields.
+func (sc *scope) param(fields *ast.FieldList, name string) *typed {
+	if fields == nil {
+		return nil
+	}
+	for _, field := range fields.List {
+		for _, param := range field.Names {
+			if param.Name == name {
+				return &typed{pkg: sc.h.pkg, file: sc.h.file, expr: field.Type}
+			}
+		}
+	}
+	return nil
+}
+
+// declared finds the declaration of a variable in body.
+func (sc *scope) declared(body *ast.BlockStmt, name string, depth int) *typed {
+	var found *typed
+	ast.Inspect(body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.DeclStmt:
+			gen, ok := n.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				return true
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, ident := range vs.Names {
+					if ident.Name == name && vs.Type != nil {
+						found = &typed{pkg: sc.h.pkg, file: sc.h.file, expr: vs.Type}
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || ident.Name != name {
+					continue
+				}
+				if len(n.Rhs) == len(n.Lhs) {
+					found = sc.typeOf(n.Rhs[i], depth+1)
+				} else if call, ok := n.Rhs[0].(*ast.CallExpr); ok && len(n.Rhs) == 1 {
+					found = sc.callResult(call, i, depth+1)
+				}
+				if found != nil {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// callResult returns the type of the i-th result of a call to a service method, a package
+// function or a handler method.
+func (sc *scope) callResult(call *ast.CallExpr, i int, depth int) *typed {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		switch fun.Name {
+		case "len", "cap":
+			return &typed{pkg: sc.h.pkg, file: sc.h.file, expr: ast.NewIdent("int")}
+		case "string":
+			return &typed{pkg: sc.h.pkg, file: sc.h.file, expr: ast.NewIdent("string")}
+		}
+		if decl, ok := sc.h.pkg.funcs[fun.Name]; ok {
+			return result(sc.h.pkg, sc.h.pkg.funcFiles[fun.Name], decl.Type, i)
+		}
+
+	case *ast.SelectorExpr:
+		if fun.Sel.Name == "String" || fun.Sel.Name == "Hex" || fun.Sel.Name == "Error" {
+			return &typed{pkg: sc.h.pkg, file: sc.h.file, expr: ast.NewIdent("string")}
+		}
+		switch x := fun.X.(type) {
+		case *ast.Ident:
+			// A package function, e.g. utils.CreatePaginatedResponse
+			if p := sc.a.s.m.lookup(sc.h.pkg, sc.h.file, x.Name); p != nil && sc.identType(x.Name, depth+1) == nil {
+				if decl, ok := p.funcs[fun.Sel.Name]; ok {
+					return result(p, p.funcFiles[fun.Sel.Name], decl.Type, i)
+				}
+			}
+			if x.Name == "h" {
+				if decl, ok := sc.h.pkg.funcs["Handler."+fun.Sel.Name]; ok {
+					return result(sc.h.pkg, sc.h.pkg.funcFiles["Handler."+fun.Sel.Name], decl.Type, i)
+				}
+			}
+		case *ast.SelectorExpr:
+			// A method of one of the handler's services, declared on an interface of the package
+			if recv, ok := x.X.(*ast.Ident); ok && recv.Name == "h" {
+				return serviceResult(sc.h.pkg, handlerFieldType(sc.h.pkg, x.Sel.Name), fun.Sel.Name, i)
+			}
+		}
+	}
+	return nil
+}
+
+// handlerFieldType names the type of a field of the package's Handler, e.g. Service for
+// service, or returns "" when it is not a type of the package.
+func handlerFieldType(p *pkg, field string) string {
+	ts, ok := p.types["Handler"]
+	if !ok {
+		return ""
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			if ident, ok := f.Type.(*ast.Ident); ok && name.Name == field {
+				return ident.Name
+			}
+		}
+	}
+	return ""
+}
+
+func serviceResult(p *pkg, iface, method string, i int) *typed {
+	ts, ok := p.types[iface]
+	if !ok {
+		return nil
+	}
+	it, ok := ts.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil
+	}
+	for _, m := range it.Methods.List {
+		if len(m.Names) == 1 && m.Names[0].Name == method {
+			if fn, ok := m.Type.(*ast.FuncType); ok {
+				return result(p, p.typeFiles[iface], fn, i)
+			}
+		}
+	}
+	return nil
+}
+
+func result(p *pkg, file *ast.File, fn *ast.FuncType, i int) *typed {
+	if fn.Results == nil {
+		return nil
+	}
+	n := 0
+	for _, field := range fn.Results.List {
+		count := max(1, len(field.Names))
+		if i < n+count {
+			return &typed{pkg: p, file: file, expr: field.Type}
+		}
+		n += count
+	}
+	return nil
+}
+
+// statuses resolves a status code argument, following a local variable through all the
+// http.Status constants assigned to it.
+func (sc *scope) statuses(expr ast.Expr) []int {
+	if code, ok := statusCode(expr); ok {
+		return []int{code}
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	var codes []int
+	ast.Inspect(sc.h.body, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok && len(assign.Lhs) == len(assign.Rhs) {
+			for i, lhs := range assign.Lhs {
+				if l, ok := lhs.(*ast.Ident); ok && l.Name == ident.Name {
+					if code, ok := statusCode(assign.Rhs[i]); ok {
+						codes = append(codes, code)
+					}
+				}
+			}
+		}
+		return true
+	})
+	sort.Ints(codes)
+	return codes
+}
+
+var statusCodes = map[string]int{
+	"StatusOK":                    http.StatusOK,
+	"StatusCreated":               http.StatusCreated,
+	"StatusAccepted":              http.StatusAccepted,
+	"StatusNoContent":             http.StatusNoContent,
+	"StatusBadRequest":            http.StatusBadRequest,
+	"StatusUnauthorized":          http.StatusUnauthorized,
+	"StatusForbidden":             http.StatusForbidden,
+	"StatusNotFound":              http.StatusNotFound,
+	"StatusConflict":              http.StatusConflict,
+	"StatusGone":                  http.StatusGone,
+	"StatusPreconditionFailed":    http.StatusPreconditionFailed,
+	"StatusRequestEntityTooLarge": http.StatusRequestEntityTooLarge,
+	"StatusUnsupportedMediaType":  http.StatusUnsupportedMediaType,
+	"StatusUnprocessableEntity":   http.StatusUnprocessableEntity,
+	"StatusTooManyRequests":       http.StatusTooManyRequests,
+	"StatusInternalServerError":   http.StatusInternalServerError,
+	"StatusServiceUnavailable":    http.StatusServiceUnavailable,
+}
+
+func statusCode(expr ast.Expr) (int, bool) {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		code, ok := statusCodes[e.Sel.Name]
+		return code, ok
+	case *ast.BasicLit:
+		code, err := strconv.Atoi(e.Value)
+		return code, err == nil
+	}
+	return 0, false
+}
+
+// summary derives an operation summary from "@Summary" or the first sentence of the doc
+// comment, dropping the leading function name Go doc comments start with.
+func summary(h handler, fallback string) (string, string) {
+	if s := h.annotation("@Summary"); s != "" {
+		return s, strings.Join(annotations(h.doc, "@Description"), " ")
+	}
+
+	doc := docText(h.doc)
+	if doc == "" {
+		return fallback, ""
+	}
+	if h.name != "" {
+		if rest, ok := strings.CutPrefix(doc, h.name+" "); ok {
+			doc = upperFirst(rest)
+		}
+	}
+
+	first, rest := doc, ""
+	if loc := sentenceEnd.FindStringIndex(doc); loc != nil {
+		first, rest = doc[:loc[0]], doc[loc[1]-1:]
+	}
+	return strings.TrimSuffix(first, "."), rest
+}
+
+// sentenceEnd matches the end of a sentence, but not abbreviations like "e.g. the"
+var sentenceEnd = regexp.MustCompile(`\.\s+[A-Z]`)
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// operationName derives an operationId for routes without a named handler, e.g.
+// "putAdminLogLevel" for PUT /api/admin/log-level.
+func operationName(method, path string) string {
+	name := method
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if part == "api" {
+			continue
+		}
+		name += upperFirst(part)
+	}
+	if name == method {
+		name += "Root"
+	}
+	return name
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pkg is one parsed package of the module.
+type pkg struct {
+	importPath string
+	name       string
+	files      []*ast.File
+	types      map[string]*ast.TypeSpec
+	typeFiles  map[string]*ast.File
+	funcs      map[string]*ast.FuncDecl // "Name" or "Recv.Name"
+	funcFiles  map[string]*ast.File
+}
+
+// module holds every non-test package below root, keyed by import path.
+type module struct {
+	root string
+	path string
+	fset *token.FileSet
+	pkgs map[string]*pkg
+}
+
+func loadModule(root string, dirs ...string) (*module, error) {
+	modPath, err := modulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &module{root: root, path: modPath, fset: token.NewFileSet(), pkgs: make(map[string]*pkg)}
+	for _, dir := range dirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(p string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			return m.parseDir(p)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func modulePath(goMod string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[0] == "module" {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("%s has no module directive", goMod)
+}
+
+func (m *module) parseDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(m.root, dir)
+	if err != nil {
+		return err
+	}
+	p := &pkg{
+		importPath: path.Join(m.path, filepath.ToSlash(rel)),
+		types:      make(map[string]*ast.TypeSpec),
+		typeFiles:  make(map[string]*ast.File),
+		funcs:      make(map[string]*ast.FuncDecl),
+		funcFiles:  make(map[string]*ast.File),
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") && !strings.HasSuffix(e.Name(), "_test.go") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		file, err := parser.ParseFile(m.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		p.name = file.Name.Name
+		p.files = append(p.files, file)
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						if ts.Doc == nil && len(decl.Specs) == 1 {
+							ts.Doc = decl.Doc
+						}
+						p.types[ts.Name.Name] = ts
+						p.typeFiles[ts.Name.Name] = file
+					}
+				}
+			case *ast.FuncDecl:
+				key := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) == 1 {
+					key = receiverName(decl.Recv.List[0].Type) + "." + key
+				}
+				p.funcs[key] = decl
+				p.funcFiles[key] = file
+			}
+		}
+	}
+
+	if len(p.files) > 0 {
+		m.pkgs[p.importPath] = p
+	}
+	return nil
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	}
+	return ""
+}
+
+// imports maps the names a file uses for its imports to their import paths.
+func (m *module) imports(file *ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if imported, ok := m.pkgs[importPath]; ok {
+			name = imported.name
+		} else if strings.HasPrefix(name, "v") && strings.Count(importPath, "/") > 1 {
+			if _, err := strconv.Atoi(name[1:]); err == nil {
+				name = path.Base(path.Dir(importPath))
+			}
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		names[name] = importPath
+	}
+	return names
+}
+
+// lookup resolves a "pkg.Name" reference as written in file, or a bare name in p.
+func (m *module) lookup(p *pkg, file *ast.File, qualifier string) *pkg {
+	if qualifier == "" {
+		return p
+	}
+	return m.pkgs[m.imports(file)[qualifier]]
+}
+
+func (m *module) line(pos token.Pos) int {
+	return m.fset.Position(pos).Line
+}
+
+// leadingComment returns the comment block ending on the line above pos, if any.
+func (m *module) leadingComment(file *ast.File, pos token.Pos) *ast.CommentGroup {
+	line := m.line(pos)
+	for _, group := range file.Comments {
+		if m.line(group.End()) == line-1 {
+			return group
+		}
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"finsolvz-backend/internal/platform/policy"
+)
+
+// builtinPolicy decides the roles documented for RequirePermission routes. Deployments with
+// their own POLICY_FILE may differ from the spec.
+var builtinPolicy = policy.New(policy.Builtin(), nil)
+
+// route is one method and path registered on the gorilla/mux router.
+type route struct {
+	method  string
+	path    string
+	tag     string
+	auth    bool
+	roles   []string
+	handler handler
+
+	// deprecation holds the fields of the middleware.Deprecation wrapping the handler
+	deprecation map[string]string
+}
+
+// handler is the code serving a route: a method, a function literal, or a handler factory
+// whose body is not analysed.
+type handler struct {
+	name string
+	pkg  *pkg
+	file *ast.File
+	doc  *ast.CommentGroup
+	fn   *ast.FuncType
+	body *ast.BlockStmt
+
+	// outer is the function enclosing a function literal, whose receiver, parameters and
+	// variables it may capture
+	outer *ast.FuncDecl
+
+	// note is the comment above the route registration, which may carry annotations for
+	// handlers declared elsewhere
+	note *ast.CommentGroup
+}
+
+// annotation looks name up in the handler's doc comment, then above its registration.
+func (h handler) annotation(name string) string {
+	if value := annotation(h.doc, name); value != "" {
+		return value
+	}
+	return annotation(h.note, name)
+}
+
+// subrouter is what a router variable adds to the routes registered on it.
+type subrouter struct {
+	prefix      string
+	auth        bool
+	roles       []string
+	deprecation map[string]string
+}
+
+type routeWalker struct {
+	m       *module
+	pkg     *pkg
+	file    *ast.File
+	tag     string
+	authMW  string // name of the auth middleware parameter of RegisterRoutes
+	routers map[string]subrouter
+	routes  []*route
+	decl    *ast.FuncDecl
+}
+
+// moduleRoutes finds the routes of every RegisterRoutes method below internal/app and those
+// the application container registers inline, in registration order.
+func moduleRoutes(m *module, appPkg string) []*route {
+	var routes []*route
+	for _, importPath := range sortedKeys(m.pkgs) {
+		p := m.pkgs[importPath]
+		decl, ok := p.funcs["Handler.RegisterRoutes"]
+		if !ok {
+			continue
+		}
+		w := &routeWalker{m: m, pkg: p, file: p.funcFiles["Handler.RegisterRoutes"], routers: map[string]subrouter{}, decl: decl}
+		w.tag = annotation(decl.Doc, "@Tags")
+		params := decl.Type.Params.List
+		if len(params) > 0 && len(params[0].Names) > 0 {
+			w.routers[params[0].Names[0].Name] = subrouter{}
+		}
+		if len(params) > 1 && len(params[1].Names) > 0 {
+			w.authMW = params[1].Names[0].Name
+		}
+		w.walk(decl.Body.List)
+		routes = append(routes, w.routes...)
+	}
+
+	// The container's functions create the root router or take it as a parameter
+	if p, ok := m.pkgs[appPkg]; ok {
+		for _, key := range sortedKeys(p.funcs) {
+			decl := p.funcs[key]
+			if decl.Body == nil {
+				continue
+			}
+			w := &routeWalker{m: m, pkg: p, file: p.funcFiles[key], routers: map[string]subrouter{}, decl: decl}
+			for _, param := range decl.Type.Params.List {
+				if isRouterType(param.Type) {
+					for _, name := range param.Names {
+						w.routers[name.Name] = subrouter{}
+					}
+				}
+			}
+			w.walk(decl.Body.List)
+			routes = append(routes, w.routes...)
+		}
+	}
+	return routes
+}
+
+// isRouterType reports whether expr is *mux.Router.
+func isRouterType(expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Router"
+}
+
+func (w *routeWalker) walk(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(s.Lhs) == 1 && len(s.Rhs) == 1 {
+				if name, ok := s.Lhs[0].(*ast.Ident); ok {
+					if r, ok := w.subrouter(s.Rhs[0]); ok {
+						w.routers[name.Name] = r
+					}
+				}
+			}
+		case *ast.ExprStmt:
+			if call, ok := s.X.(*ast.CallExpr); ok {
+				w.call(call, stmt)
+			}
+		case *ast.IfStmt:
+			w.walk(s.Body.List)
+			if s.Else != nil {
+				w.walk([]ast.Stmt{s.Else})
+			}
+		case *ast.BlockStmt:
+			w.walk(s.List)
+		}
+	}
+}
+
+// subrouter recognises mux.NewRouter() and r.PathPrefix("/x").Subrouter().
+func (w *routeWalker) subrouter(expr ast.Expr) (subrouter, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return subrouter{}, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return subrouter{}, false
+	}
+	if sel.Sel.Name == "NewRouter" {
+		return subrouter{}, true
+	}
+	if sel.Sel.Name != "Subrouter" {
+		return subrouter{}, false
+	}
+	prefixCall, ok := sel.X.(*ast.CallExpr)
+	if !ok {
+		return subrouter{}, false
+	}
+	prefixSel, ok := prefixCall.Fun.(*ast.SelectorExpr)
+	if !ok || prefixSel.Sel.Name != "PathPrefix" || len(prefixCall.Args) != 1 {
+		return subrouter{}, false
+	}
+	parent, ok := w.router(prefixSel.X)
+	if !ok {
+		return subrouter{}, false
+	}
+	prefix, _ := stringLit(prefixCall.Args[0])
+	parent.prefix += prefix
+	parent.roles = append([]string(nil), parent.roles...)
+	return parent, true
+}
+
+func (w *routeWalker) router(expr ast.Expr) (subrouter, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return subrouter{}, false
+	}
+	r, ok := w.routers[ident.Name]
+	return r, ok
+}
+
+func (w *routeWalker) call(call *ast.CallExpr, stmt ast.Stmt) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	switch sel.Sel.Name {
+	case "Use":
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || len(call.Args) != 1 {
+			return
+		}
+		if r, ok := w.routers[ident.Name]; ok {
+			w.middleware(&r, call.Args[0])
+			w.routers[ident.Name] = r
+		}
+
+	case "Methods":
+		inner, ok := sel.X.(*ast.CallExpr)
+		if !ok || len(inner.Args) != 2 {
+			return
+		}
+		innerSel, ok := inner.Fun.(*ast.SelectorExpr)
+		if !ok || (innerSel.Sel.Name != "HandleFunc" && innerSel.Sel.Name != "Handle") {
+			return
+		}
+		r, ok := w.router(innerSel.X)
+		if !ok {
+			return
+		}
+		path, ok := stringLit(inner.Args[0])
+		if !ok {
+			return
+		}
+		r.roles = append([]string(nil), r.roles...)
+		h := w.handler(&r, inner.Args[1], stmt)
+		for _, arg := range call.Args {
+			if method, ok := stringLit(arg); ok {
+				w.routes = append(w.routes, &route{
+					method:      strings.ToLower(method),
+					path:        r.prefix + path,
+					tag:         w.tag,
+					auth:        r.auth,
+					roles:       r.roles,
+					handler:     h,
+					deprecation: r.deprecation,
+				})
+			}
+		}
+	}
+}
+
+// middleware records the effect of the auth and role middlewares on r.
+func (w *routeWalker) middleware(r *subrouter, expr ast.Expr) bool {
+	switch mw := expr.(type) {
+	case *ast.Ident:
+		if mw.Name == w.authMW {
+			r.auth = true
+			return true
+		}
+	case *ast.SelectorExpr:
+		if mw.Sel.Name == "AuthMiddleware" {
+			r.auth = true
+			return true
+		}
+	case *ast.CallExpr:
+		// middleware.RequirePermission("manage", "webhook"): the roles the built-in policy allows
+		if sel, ok := mw.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "RequirePermission" && len(mw.Args) == 2 {
+			action, _ := stringLit(mw.Args[0])
+			resource, _ := stringLit(mw.Args[1])
+			if roles := builtinPolicy.Roles(action, resource); len(roles) != 1 || roles[0] != policy.Any {
+				r.roles = append(r.roles, roles...)
+			}
+			return true
+		}
+		// middleware.Deprecated(middleware.Deprecation{Since: "...", ...})
+		if sel, ok := mw.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Deprecated" && len(mw.Args) == 1 {
+			r.deprecation = map[string]string{}
+			if lit, ok := mw.Args[0].(*ast.CompositeLit); ok {
+				for _, elt := range lit.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						key, _ := kv.Key.(*ast.Ident)
+						value, _ := stringLit(kv.Value)
+						if key != nil {
+							r.deprecation[key.Name] = value
+						}
+					}
+				}
+			}
+			return true
+		}
+		// middleware.CacheResponses(middleware.ResponseCacheCompanies, ttl) doesn't change the operation
+		if sel, ok := mw.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "CacheResponses" {
+			return true
+		}
+	}
+	return false
+}
+
+// handler resolves the handler expression of a route, unwrapping middlewares applied inline.
+func (w *routeWalker) handler(r *subrouter, expr ast.Expr, stmt ast.Stmt) handler {
+	h := w.resolve(r, expr)
+	h.note = w.m.leadingComment(w.file, stmt.Pos())
+	if h.doc == nil {
+		h.doc = h.note
+	}
+	return h
+}
+
+func (w *routeWalker) resolve(r *subrouter, expr ast.Expr) handler {
+	switch h := expr.(type) {
+	case *ast.FuncLit:
+		return handler{pkg: w.pkg, file: w.file, fn: h.Type, body: h.Body, outer: w.decl}
+
+	case *ast.SelectorExpr:
+		if recv, ok := h.X.(*ast.Ident); ok && recv.Name == "h" {
+			if decl, ok := w.pkg.funcs["Handler."+h.Sel.Name]; ok {
+				return handler{name: h.Sel.Name, pkg: w.pkg, file: w.pkg.funcFiles["Handler."+h.Sel.Name], doc: decl.Doc, fn: decl.Type, body: decl.Body}
+			}
+		}
+		// A method of some other value, e.g. diagnosticsRunner.ReadyHandler
+		for _, importPath := range sortedKeys(w.m.pkgs) {
+			p := w.m.pkgs[importPath]
+			for _, key := range sortedKeys(p.funcs) {
+				if strings.HasSuffix(key, "."+h.Sel.Name) {
+					decl := p.funcs[key]
+					return handler{name: h.Sel.Name, pkg: p, file: p.funcFiles[key], doc: decl.Doc, fn: decl.Type, body: decl.Body}
+				}
+			}
+		}
+
+	case *ast.CallExpr:
+		if len(h.Args) == 1 && w.middleware(r, h.Fun) {
+			return w.resolve(r, h.Args[0])
+		}
+		if sel, ok := h.Fun.(*ast.SelectorExpr); ok {
+			if sel.Sel.Name == "HandlerFunc" && len(h.Args) == 1 {
+				return w.resolve(r, h.Args[0])
+			}
+		}
+	}
+	// A handler factory such as metrics.Handler, documented by the comment above it
+	return handler{pkg: w.pkg, file: w.file}
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// annotation returns the value of the first "@Name value" line of a comment.
+func annotation(group *ast.CommentGroup, name string) string {
+	values := annotations(group, name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func annotations(group *ast.CommentGroup, name string) []string {
+	if group == nil {
+		return nil
+	}
+	var values []string
+	for _, line := range strings.Split(group.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, name+" "); ok {
+			values = append(values, strings.TrimSpace(rest))
+		}
+	}
+	return values
+}
+
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// normalizePath turns gorilla/mux patterns like {id:[0-9]+} into OpenAPI templates.
+func normalizePath(path string) (string, []string) {
+	var params []string
+	normalized := pathParam.ReplaceAllStringFunc(path, func(m string) string {
+		name := pathParam.FindStringSubmatch(m)[1]
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	return normalized, params
+}
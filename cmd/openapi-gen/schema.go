@@ -0,0 +1,348 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// schemas converts Go types to OpenAPI schemas, collecting named types as components.
+type schemas struct {
+	m          *module
+	components *omap
+}
+
+func newSchemas(m *module) *schemas {
+	return &schemas{m: m, components: newMap()}
+}
+
+// ref returns a reference to the component for the named type, generating it on first use.
+func (s *schemas) ref(p *pkg, name string) *omap {
+	ts, ok := p.types[name]
+	if !ok {
+		return newMap()
+	}
+
+	key := p.name + "." + name
+	if !s.components.has(key) {
+		s.components.set(key, newMap()) // placeholder, so recursive types terminate
+		s.components.set(key, s.named(p, ts))
+	}
+	return newMap("$ref", "#/components/schemas/"+key)
+}
+
+func (s *schemas) named(p *pkg, ts *ast.TypeSpec) *omap {
+	file := p.typeFiles[ts.Name.Name]
+
+	var schema *omap
+	if variants := annotations(ts.Doc, "@OneOf"); len(variants) > 0 {
+		schema = s.oneOf(p, file, variants, annotation(ts.Doc, "@Discriminator"))
+	} else if st, ok := ts.Type.(*ast.StructType); ok {
+		schema = s.object(p, file, st)
+	} else {
+		schema = s.of(p, file, ts.Type)
+		if enum := constValues(p, ts.Name.Name); len(enum) > 0 {
+			schema.set("enum", enum)
+		}
+	}
+
+	if doc := docText(ts.Doc); doc != "" {
+		described := newMap("description", doc)
+		for _, k := range schema.keys {
+			described.set(k, schema.vals[k])
+		}
+		schema = described
+	}
+	return schema
+}
+
+// oneOf builds the schema of a type annotated with the Go types of its variants:
+//
+//	// @OneOf []ReportRow
+//	// @OneOf map[string]interface{}
+//	// @Discriminator kind              (optional: property naming the variant)
+func (s *schemas) oneOf(p *pkg, file *ast.File, variants []string, discriminator string) *omap {
+	schemas := []interface{}{}
+	for _, variant := range variants {
+		expr, err := parser.ParseExpr(variant)
+		if err != nil {
+			continue
+		}
+		schemas = append(schemas, s.of(p, file, expr))
+	}
+	schema := newMap("oneOf", schemas)
+	if discriminator != "" {
+		schema.set("discriminator", newMap("propertyName", discriminator))
+	}
+	return schema
+}
+
+// constValues lists the string constants declared with the given type, e.g. the roles of UserRole.
+func constValues(p *pkg, typeName string) []interface{} {
+	var values []interface{}
+	for _, file := range p.files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != typeName {
+					continue
+				}
+				for _, value := range vs.Values {
+					if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						v, _ := strconv.Unquote(lit.Value)
+						values = append(values, v)
+					}
+				}
+			}
+		}
+	}
+	return values
+}
+
+// of converts a type expression written in file of package p.
+func (s *schemas) of(p *pkg, file *ast.File, expr ast.Expr) *omap {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string", "error":
+			return newMap("type", "string")
+		case "bool":
+			return newMap("type", "boolean")
+		case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+			return newMap("type", "integer")
+		case "int64", "uint64":
+			return newMap("type", "integer", "format", "int64")
+		case "float32", "float64":
+			return newMap("type", "number")
+		case "any":
+			return newMap()
+		}
+		return s.ref(p, t.Name)
+
+	case *ast.SelectorExpr:
+		qualifier, ok := t.X.(*ast.Ident)
+		if !ok {
+			return newMap()
+		}
+		importPath := s.m.imports(file)[qualifier.Name]
+		switch importPath + "." + t.Sel.Name {
+		case "time.Time":
+			return newMap("type", "string", "format", "date-time")
+		case "time.Duration":
+			return newMap("type", "integer", "format", "int64", "description", "Duration in nanoseconds")
+		case "go.mongodb.org/mongo-driver/bson/primitive.ObjectID":
+			return newMap("type", "string", "pattern", "^[0-9a-f]{24}$", "example", "507f1f77bcf86cd799439011")
+		case "go.mongodb.org/mongo-driver/bson/primitive.M", "go.mongodb.org/mongo-driver/bson.M":
+			return newMap("type", "object", "additionalProperties", true)
+		}
+		if imported, ok := s.m.pkgs[importPath]; ok {
+			return s.ref(imported, t.Sel.Name)
+		}
+		return newMap()
+
+	case *ast.StarExpr:
+		schema := s.of(p, file, t.X)
+		if !schema.has("$ref") && len(schema.keys) > 0 {
+			schema.set("nullable", true)
+		}
+		return schema
+
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return newMap("type", "string", "format", "byte")
+		}
+		return newMap("type", "array", "items", s.of(p, file, t.Elt))
+
+	case *ast.MapType:
+		values := s.of(p, file, t.Value)
+		if len(values.keys) == 0 {
+			return newMap("type", "object", "additionalProperties", true)
+		}
+		return newMap("type", "object", "additionalProperties", values)
+
+	case *ast.StructType:
+		return s.object(p, file, t)
+	}
+	return newMap()
+}
+
+// object converts a struct using its json tags, validate tags and field comments.
+func (s *schemas) object(p *pkg, file *ast.File, st *ast.StructType) *omap {
+	properties, required := newMap(), []interface{}{}
+	s.fields(p, file, st, properties, &required)
+
+	schema := newMap("type", "object")
+	if len(required) > 0 {
+		schema.set("required", required)
+	}
+	schema.set("properties", properties)
+	return schema
+}
+
+func (s *schemas) fields(p *pkg, file *ast.File, st *ast.StructType, properties *omap, required *[]interface{}) {
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw)
+		}
+		jsonName, jsonOpts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && jsonOpts == "" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			// Embedded structs without a json name are flattened into the parent
+			if jsonName == "" {
+				if ep, efile, est := s.m.structOf(p, file, field.Type); est != nil {
+					s.fields(ep, efile, est, properties, required)
+				}
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			key := jsonName
+			if key == "" {
+				key = name.Name
+			}
+
+			schema := s.of(p, file, field.Type)
+			if _, pointer := field.Type.(*ast.StarExpr); pointer && schema.has("$ref") {
+				// OpenAPI 3.0 ignores siblings of $ref
+				schema = newMap("allOf", []interface{}{schema}, "nullable", true)
+			}
+			if strings.Contains(jsonOpts, "string") {
+				schema = newMap("type", "string")
+			}
+			isRequired := applyValidation(schema, tag.Get("validate"))
+			if tag.Get("validate") == "" && !strings.Contains(jsonOpts, "omitempty") {
+				_, pointer := field.Type.(*ast.StarExpr)
+				isRequired = !pointer
+			}
+			if doc := docText(field.Doc); doc != "" && !schema.has("$ref") {
+				schema.set("description", doc)
+			} else if doc := docText(field.Comment); doc != "" && !schema.has("$ref") {
+				schema.set("description", doc)
+			}
+
+			properties.set(key, schema)
+			if isRequired {
+				*required = append(*required, key)
+			}
+		}
+	}
+}
+
+// applyValidation maps go-playground/validator rules onto schema and reports whether the
+// field is required. Rules after "dive" apply to elements and are ignored.
+func applyValidation(schema *omap, rules string) bool {
+	required := false
+	typ, _ := schema.get("type")
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.set("format", "email")
+		case "url":
+			schema.set("format", "uri")
+		case "oneof":
+			enum := []interface{}{}
+			for _, v := range strings.Fields(param) {
+				enum = append(enum, v)
+			}
+			schema.set("enum", enum)
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			lower := name == "min" || name == "gte" || name == "len"
+			upper := name == "max" || name == "lte" || name == "len"
+			switch typ {
+			case "string":
+				setBound(schema, "minLength", "maxLength", lower, upper, n)
+			case "array":
+				setBound(schema, "minItems", "maxItems", lower, upper, n)
+			case "integer", "number":
+				setBound(schema, "minimum", "maximum", lower, upper, n)
+			}
+		}
+	}
+	return required
+}
+
+func setBound(schema *omap, minKey, maxKey string, lower, upper bool, n int) {
+	if lower {
+		schema.set(minKey, n)
+	}
+	if upper {
+		schema.set(maxKey, n)
+	}
+}
+
+// structOf resolves a type expression to the struct declaration behind it.
+func (m *module) structOf(p *pkg, file *ast.File, expr ast.Expr) (*pkg, *ast.File, *ast.StructType) {
+	for i := 0; i < 8; i++ {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+			continue
+		case *ast.StructType:
+			return p, file, t
+		case *ast.Ident:
+			ts, ok := p.types[t.Name]
+			if !ok {
+				return nil, nil, nil
+			}
+			file, expr = p.typeFiles[t.Name], ts.Type
+			continue
+		case *ast.SelectorExpr:
+			qualifier, ok := t.X.(*ast.Ident)
+			if !ok {
+				return nil, nil, nil
+			}
+			imported := m.lookup(p, file, qualifier.Name)
+			if imported == nil {
+				return nil, nil, nil
+			}
+			p, expr = imported, t.Sel
+			continue
+		}
+		return nil, nil, nil
+	}
+	return nil, nil, nil
+}
+
+// docText returns the first paragraph of a comment without its annotations.
+func docText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(group.Text()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
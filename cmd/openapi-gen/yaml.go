@@ -0,0 +1,147 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// omap is a JSON object that keeps insertion order, so the generated spec is stable.
+type omap struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func newMap(kv ...interface{}) *omap {
+	m := &omap{vals: make(map[string]interface{})}
+	for i := 0; i+1 < len(kv); i += 2 {
+		m.set(kv[i].(string), kv[i+1])
+	}
+	return m
+}
+
+func (m *omap) set(key string, value interface{}) {
+	if _, ok := m.vals[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.vals[key] = value
+}
+
+func (m *omap) get(key string) (interface{}, bool) {
+	v, ok := m.vals[key]
+	return v, ok
+}
+
+func (m *omap) has(key string) bool {
+	_, ok := m.vals[key]
+	return ok
+}
+
+func (m *omap) sortKeys(less func(a, b string) bool) {
+	sort.SliceStable(m.keys, func(i, j int) bool { return less(m.keys[i], m.keys[j]) })
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_/$][A-Za-z0-9_./{}$-]*$`)
+
+// toYAML renders v as block-style YAML. Strings that could be misread are double-quoted,
+// which YAML parses the same way as JSON strings.
+func toYAML(v interface{}) string {
+	var b strings.Builder
+	writeYAML(&b, v, 0)
+	return b.String()
+}
+
+func writeYAML(b *strings.Builder, v interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch v := v.(type) {
+	case *omap:
+		for _, key := range v.keys {
+			b.WriteString(pad + yamlKey(key) + ":")
+			writeValue(b, v.vals[key], indent)
+		}
+	case []interface{}:
+		for _, item := range v {
+			var nested strings.Builder
+			if isScalar(item) {
+				b.WriteString(pad + "- " + scalar(item) + "\n")
+				continue
+			}
+			writeYAML(&nested, item, indent+2)
+			b.WriteString(pad + "- " + strings.TrimPrefix(nested.String(), pad+"  "))
+		}
+	}
+}
+
+func writeValue(b *strings.Builder, v interface{}, indent int) {
+	switch value := v.(type) {
+	case *omap:
+		if len(value.keys) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, value, indent+2)
+	case []interface{}:
+		if len(value) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, value, indent+2)
+	default:
+		b.WriteString(" " + scalar(v) + "\n")
+	}
+}
+
+func isScalar(v interface{}) bool {
+	switch v := v.(type) {
+	case *omap:
+		return len(v.keys) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return true
+}
+
+func scalar(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if plainString(v) {
+			return v
+		}
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case *omap:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	case nil:
+		return "null"
+	}
+	return strconv.Quote("")
+}
+
+var plainValue = regexp.MustCompile(`^[A-Za-z/][A-Za-z0-9 _./(),'-]*[A-Za-z0-9_./)']$`)
+
+// plainString reports whether s can be written unquoted without YAML reading it as
+// something other than a string.
+func plainString(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return false
+	}
+	return plainValue.MatchString(s)
+}
+
+func yamlKey(key string) string {
+	if plainKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
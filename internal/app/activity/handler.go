@@ -0,0 +1,43 @@
+package activity
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the activity feed routes
+// @Tags Activity
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/activity", h.GetActivity).Methods("GET")
+}
+
+// GetActivity lists the newest reports created or updated, companies added and users joined
+// that the caller may see, newest first, for the dashboard's recent activity widget. Each
+// report appears once, with its latest change.
+func (h *Handler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	pagination := utils.GetPaginationParams(r)
+
+	activity, total, err := h.service.GetActivity(r.Context(), pagination.Skip, pagination.Limit)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	pagination.Total = total
+	utils.SetPaginationHeaders(w, r, pagination)
+	utils.RespondJSON(w, http.StatusOK, utils.CreatePaginatedResponse(activity, pagination))
+}
@@ -0,0 +1,40 @@
+package activity
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// ActivityResponse is an entry of the activity feed, with the names the widget shows.
+type ActivityResponse struct {
+	Kind    domain.ActivityKind `json:"kind"`
+	At      time.Time           `json:"at"`
+	Subject NamedRef            `json:"subject"`
+	Company *NamedRef           `json:"company,omitempty"`
+	Actor   *NamedRef           `json:"actor,omitempty"`
+}
+
+// NamedRef is a populated reference; Name is empty when the document no longer exists.
+type NamedRef struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+// ToActivityResponse converts an activity entry, naming its company and actor with the given names by ID
+func ToActivityResponse(activity *domain.Activity, names map[string]string) *ActivityResponse {
+	response := &ActivityResponse{
+		Kind:    activity.Kind,
+		At:      activity.At,
+		Subject: NamedRef{ID: activity.Subject.Hex(), Name: activity.Name},
+	}
+	if activity.Company != nil {
+		id := activity.Company.Hex()
+		response.Company = &NamedRef{ID: id, Name: names[id]}
+	}
+	if activity.Actor != nil {
+		id := activity.Actor.Hex()
+		response.Actor = &NamedRef{ID: id, Name: names[id]}
+	}
+	return response
+}
@@ -0,0 +1,91 @@
+package activity
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+)
+
+type Service interface {
+	// GetActivity returns a page of the newest activity the caller may see and how many
+	// entries there are
+	GetActivity(ctx context.Context, skip, limit int) ([]*ActivityResponse, int, error)
+}
+
+type service struct {
+	activityRepo domain.ActivityRepository
+	companyRepo  domain.CompanyRepository
+	userRepo     domain.UserRepository
+}
+
+func NewService(activityRepo domain.ActivityRepository, companyRepo domain.CompanyRepository, userRepo domain.UserRepository) Service {
+	return &service{
+		activityRepo: activityRepo,
+		companyRepo:  companyRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// GetActivity lists reports and companies as the repositories limit them for the caller, and
+// users joining only to those allowed to list users.
+func (s *service) GetActivity(ctx context.Context, skip, limit int) ([]*ActivityResponse, int, error) {
+	includeUsers := middleware.Authorize(ctx, "list", policy.Resource{Type: "user"}) == nil
+
+	activity, total, err := s.activityRepo.Recent(ctx, includeUsers, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	names, err := s.names(ctx, activity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*ActivityResponse, len(activity))
+	for i, entry := range activity {
+		responses[i] = ToActivityResponse(entry, names)
+	}
+	return responses, total, nil
+}
+
+// names maps the IDs of the companies and creators of the reports in activity to their names.
+func (s *service) names(ctx context.Context, activity []*domain.Activity) (map[string]string, error) {
+	names := make(map[string]string)
+
+	seen := make(map[primitive.ObjectID]bool)
+	var companyIDs, userIDs []primitive.ObjectID
+	for _, entry := range activity {
+		if entry.Company != nil && !seen[*entry.Company] {
+			seen[*entry.Company] = true
+			companyIDs = append(companyIDs, *entry.Company)
+		}
+		if entry.Actor != nil && !seen[*entry.Actor] {
+			seen[*entry.Actor] = true
+			userIDs = append(userIDs, *entry.Actor)
+		}
+	}
+
+	if len(companyIDs) > 0 {
+		companies, err := s.companyRepo.GetByIDs(ctx, companyIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, company := range companies {
+			names[company.ID.Hex()] = company.Name
+		}
+	}
+	if len(userIDs) > 0 {
+		users, err := s.userRepo.GetByIDs(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			names[user.ID.Hex()] = user.Name
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,17 @@
+package apikey
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidAPIKeyID = errors.New("INVALID_API_KEY_ID", "Invalid API key ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidUserID   = errors.New("INVALID_USER_ID", "Invalid user ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidExpiry   = errors.New("INVALID_API_KEY_EXPIRY", "expiresAt must be in the future", http.StatusBadRequest, nil, nil)
+	ErrInvalidDate     = errors.New("INVALID_DATE", "Dates must be formatted as YYYY-MM-DD", http.StatusBadRequest, nil, nil)
+	ErrInvalidRange    = errors.New("INVALID_USAGE_RANGE", "Usage is kept for 31 days; from must not be after to", http.StatusBadRequest, nil, nil)
+	ErrInvalidAPIKey   = errors.New("INVALID_API_KEY", "API key is invalid or expired", http.StatusUnauthorized, nil, nil)
+	ErrRateLimited     = errors.New("API_KEY_RATE_LIMITED", "The API key's per-minute rate limit was reached", http.StatusTooManyRequests, nil, nil)
+	ErrQuotaExceeded   = errors.New("API_KEY_QUOTA_EXCEEDED", "The API key's daily quota is used up until midnight UTC", http.StatusTooManyRequests, nil, nil)
+)
@@ -0,0 +1,145 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers API key routes
+// @Tags API Keys
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/apikeys", h.GetAPIKeys).Methods("GET")
+	protected.HandleFunc("/api/apikeys", h.CreateAPIKey).Methods("POST")
+	protected.HandleFunc("/api/apikeys/{id}", h.GetAPIKeyByID).Methods("GET")
+	protected.HandleFunc("/api/apikeys/{id}", h.UpdateAPIKey).Methods("PUT")
+	protected.HandleFunc("/api/apikeys/{id}", h.DeleteAPIKey).Methods("DELETE")
+	protected.HandleFunc("/api/apikeys/{id}/usage", h.GetUsage).Methods("GET")
+}
+
+// GetAPIKeys lists the user's API keys, newest first. Super admins list another user's keys
+// @Param user query string false "User ID, the caller by default"
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.GetAPIKeys(r.Context(), r.URL.Query().Get("user"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, keys)
+}
+
+// CreateAPIKey creates an API key acting as the user, with the server's default limits. The
+// key is in the response only; store it, as it cannot be shown again
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, key)
+}
+
+// @Summary Get API key by ID
+func (h *Handler) GetAPIKeyByID(w http.ResponseWriter, r *http.Request) {
+	key, err := h.service.GetAPIKeyByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, key)
+}
+
+// UpdateAPIKey renames an API key. Only super admins change its rate limit and daily quota
+func (h *Handler) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req UpdateAPIKeyRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	key, err := h.service.UpdateAPIKey(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "API key updated successfully",
+		"apiKey":  key,
+	})
+}
+
+// DeleteAPIKey revokes an API key along with its usage
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteAPIKey(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{
+		"message": "API key deleted successfully",
+	})
+}
+
+// GetUsage counts the requests accepted with an API key by day and endpoint, with what is
+// left of today's quota. Usage is kept for 31 days
+// @Param from query string false "First day, YYYY-MM-DD (UTC); 6 days before to by default"
+// @Param to query string false "Last day, YYYY-MM-DD (UTC); today by default"
+func (h *Handler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	usage, err := h.service.GetUsage(r.Context(), mux.Vars(r)["id"], query.Get("from"), query.Get("to"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, usage)
+}
+
+// requester is the ID of the user making the request, who owns the keys they create.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
@@ -0,0 +1,90 @@
+package apikey
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Limits bound the requests made with an API key.
+type Limits struct {
+	RateLimitPerMinute int
+	DailyQuota         int // 0 is unlimited
+}
+
+// Request DTOs
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // never when unset
+}
+
+// UpdateAPIKeyRequest renames a key; only super admins change its limits.
+type UpdateAPIKeyRequest struct {
+	Name               *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	RateLimitPerMinute *int    `json:"rateLimitPerMinute,omitempty" validate:"omitempty,min=1"`
+	DailyQuota         *int    `json:"dailyQuota,omitempty" validate:"omitempty,min=0"` // 0 is unlimited
+	// DefaultLimits puts the key back on the server's limits
+	DefaultLimits bool `json:"defaultLimits,omitempty"`
+}
+
+// Response DTOs
+type APIKeyResponse struct {
+	ID                 string     `json:"id"`
+	User               string     `json:"user"`
+	Name               string     `json:"name"`
+	Prefix             string     `json:"prefix"`
+	RateLimitPerMinute int        `json:"rateLimitPerMinute"`
+	DailyQuota         int        `json:"dailyQuota"`   // 0 is unlimited
+	CustomLimits       bool       `json:"customLimits"` // false when the key has the server's limits
+	ExpiresAt          *time.Time `json:"expiresAt"`
+	LastUsedAt         *time.Time `json:"lastUsedAt"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+// CreatedAPIKeyResponse carries the key itself, which is never shown again.
+type CreatedAPIKeyResponse struct {
+	*APIKeyResponse
+	APIKey string `json:"apiKey"`
+}
+
+// UsageResponse counts the requests accepted with a key by day and endpoint.
+type UsageResponse struct {
+	APIKey             string          `json:"apiKey"`
+	From               string          `json:"from"`
+	To                 string          `json:"to"`
+	RateLimitPerMinute int             `json:"rateLimitPerMinute"`
+	DailyQuota         int             `json:"dailyQuota"`
+	UsedToday          int64           `json:"usedToday"`
+	RemainingToday     *int64          `json:"remainingToday"` // null when unlimited
+	Total              int64           `json:"total"`
+	Endpoints          []EndpointUsage `json:"endpoints"` // most used first
+	Days               []DayUsage      `json:"days"`
+}
+
+type DayUsage struct {
+	Date      string          `json:"date"`
+	Total     int64           `json:"total"`
+	Endpoints []EndpointUsage `json:"endpoints"`
+}
+
+type EndpointUsage struct {
+	Endpoint string `json:"endpoint"` // method and route, e.g. "GET /api/reports/{id}"
+	Count    int64  `json:"count"`
+}
+
+const dateLayout = "2006-01-02"
+
+func ToAPIKeyResponse(key *domain.APIKey, limits Limits) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:                 key.ID.Hex(),
+		User:               key.User.Hex(),
+		Name:               key.Name,
+		Prefix:             key.Prefix,
+		RateLimitPerMinute: limits.RateLimitPerMinute,
+		DailyQuota:         limits.DailyQuota,
+		CustomLimits:       key.RateLimitPerMinute != nil || key.DailyQuota != nil,
+		ExpiresAt:          key.ExpiresAt,
+		LastUsedAt:         key.LastUsedAt,
+		CreatedAt:          key.CreatedAt,
+	}
+}
@@ -0,0 +1,397 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	// keyPrefix starts every key, so leaked keys are easy to search for
+	keyPrefix = "fsk_"
+	// usageRetention is how long the daily usage counters are kept
+	usageRetention = 31 * 24 * time.Hour
+	// touchInterval is how stale lastUsedAt may get, sparing a write per request
+	touchInterval = time.Minute
+)
+
+type Service interface {
+	CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest, userID primitive.ObjectID) (*CreatedAPIKeyResponse, error)
+	// GetAPIKeys lists the keys of a user, the caller when user is empty.
+	GetAPIKeys(ctx context.Context, user string) ([]*APIKeyResponse, error)
+	GetAPIKeyByID(ctx context.Context, id string) (*APIKeyResponse, error)
+	UpdateAPIKey(ctx context.Context, id string, req UpdateAPIKeyRequest) (*APIKeyResponse, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+	// GetUsage counts the requests accepted with a key from one day to another, UTC.
+	GetUsage(ctx context.Context, id, from, to string) (*UsageResponse, error)
+
+	// Authenticate is the middleware.APIKeyAuth of the keys: it finds the key's user and
+	// counts the request against the key's limits and usage.
+	Authenticate(r *http.Request, key string) (*middleware.APIKeyIdentity, error)
+}
+
+type service struct {
+	apiKeyRepo domain.APIKeyRepository
+	userRepo   domain.UserRepository
+	cache      utils.Cache
+	defaults   Limits
+}
+
+// NewService counts requests in cache, which must be shared by every instance for the limits
+// to hold across them.
+func NewService(apiKeyRepo domain.APIKeyRepository, userRepo domain.UserRepository, cache utils.Cache, defaults Limits) Service {
+	return &service{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		cache:      cache,
+		defaults:   defaults,
+	}
+}
+
+func (s *service) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest, userID primitive.ObjectID) (*CreatedAPIKeyResponse, error) {
+	if err := authorize(ctx, "manage", userID); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	secret, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	key := &domain.APIKey{
+		User:      userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    secret[:len(keyPrefix)+8],
+		Hash:      hashKey(secret),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKeyResponse{APIKeyResponse: ToAPIKeyResponse(key, s.limits(key)), APIKey: secret}, nil
+}
+
+func (s *service) GetAPIKeys(ctx context.Context, user string) ([]*APIKeyResponse, error) {
+	caller, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+	if user == "" {
+		user = caller.UserID
+	}
+	userID, err := primitive.ObjectIDFromHex(user)
+	if err != nil {
+		return nil, ErrInvalidUserID
+	}
+	if err := authorize(ctx, "manage", userID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.apiKeyRepo.GetAll(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = ToAPIKeyResponse(key, s.limits(key))
+	}
+	return responses, nil
+}
+
+func (s *service) GetAPIKeyByID(ctx context.Context, id string) (*APIKeyResponse, error) {
+	key, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToAPIKeyResponse(key, s.limits(key)), nil
+}
+
+func (s *service) UpdateAPIKey(ctx context.Context, id string, req UpdateAPIKeyRequest) (*APIKeyResponse, error) {
+	key, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		key.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.DefaultLimits || req.RateLimitPerMinute != nil || req.DailyQuota != nil {
+		// Owners may not raise their own limits
+		if err := middleware.Authorize(ctx, "limit", policy.Resource{Type: "apikey"}); err != nil {
+			return nil, err
+		}
+		if req.DefaultLimits {
+			key.RateLimitPerMinute, key.DailyQuota = nil, nil
+		}
+		if req.RateLimitPerMinute != nil {
+			key.RateLimitPerMinute = req.RateLimitPerMinute
+		}
+		if req.DailyQuota != nil {
+			key.DailyQuota = req.DailyQuota
+		}
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	return ToAPIKeyResponse(key, s.limits(key)), nil
+}
+
+func (s *service) DeleteAPIKey(ctx context.Context, id string) error {
+	key, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.apiKeyRepo.Delete(ctx, key.ID); err != nil {
+		return err
+	}
+	s.cache.DeletePrefix(usagePrefix(key.ID))
+	return nil
+}
+
+func (s *service) GetUsage(ctx context.Context, id, from, to string) (*UsageResponse, error) {
+	key, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	end := today
+	if to != "" {
+		if end, err = parseDate(to); err != nil {
+			return nil, err
+		}
+	}
+	start := end.AddDate(0, 0, -6)
+	if from != "" {
+		if start, err = parseDate(from); err != nil {
+			return nil, err
+		}
+	}
+	if start.After(end) || start.Before(today.Add(-usageRetention)) {
+		return nil, ErrInvalidRange
+	}
+
+	limits := s.limits(key)
+	response := &UsageResponse{
+		APIKey:             key.ID.Hex(),
+		From:               start.Format(dateLayout),
+		To:                 end.Format(dateLayout),
+		RateLimitPerMinute: limits.RateLimitPerMinute,
+		DailyQuota:         limits.DailyQuota,
+		Endpoints:          []EndpointUsage{},
+		Days:               []DayUsage{},
+	}
+
+	// Counters are keyed "<date>:<endpoint>"
+	byDay := map[string]map[string]int64{}
+	for name, count := range s.cache.Counters(usagePrefix(key.ID)) {
+		date, endpoint, ok := strings.Cut(name, ":")
+		if !ok {
+			continue
+		}
+		if date == today.Format(dateLayout) {
+			response.UsedToday += count
+		}
+		if date < response.From || date > response.To {
+			continue
+		}
+		if byDay[date] == nil {
+			byDay[date] = map[string]int64{}
+		}
+		byDay[date][endpoint] += count
+	}
+	if limits.DailyQuota > 0 {
+		remaining := int64(limits.DailyQuota) - response.UsedToday
+		if remaining < 0 {
+			remaining = 0
+		}
+		response.RemainingToday = &remaining
+	}
+
+	totals := map[string]int64{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		usage := DayUsage{Date: date, Endpoints: sortedUsage(byDay[date])}
+		for endpoint, count := range byDay[date] {
+			usage.Total += count
+			totals[endpoint] += count
+		}
+		response.Total += usage.Total
+		response.Days = append(response.Days, usage)
+	}
+	response.Endpoints = sortedUsage(totals)
+
+	return response, nil
+}
+
+func (s *service) Authenticate(r *http.Request, secret string) (*middleware.APIKeyIdentity, error) {
+	ctx := r.Context()
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashKey(secret))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if key.Expired(now) {
+		return nil, ErrInvalidAPIKey
+	}
+	// The user's current role applies, not the one they had when creating the key
+	user, err := s.userRepo.GetByID(ctx, key.User)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !user.Active() {
+		return nil, middleware.ErrAccountDisabled
+	}
+
+	limits := s.limits(key)
+	identity := &middleware.APIKeyIdentity{
+		KeyID:     key.ID.Hex(),
+		Claims:    &utils.Claims{UserID: user.ID.Hex(), Role: string(user.Role), Organization: user.OrganizationClaim()},
+		RateLimit: limits.RateLimitPerMinute,
+	}
+
+	// Counts of zero mean the cache is unavailable; requests are let through rather than refused
+	minute := now.Truncate(time.Minute)
+	count := int(s.cache.Increment(fmt.Sprintf("apikey:rate:%s:%d", key.ID.Hex(), minute.Unix()), time.Minute))
+	identity.RateRemaining = max(limits.RateLimitPerMinute-count, 0)
+	if count > limits.RateLimitPerMinute {
+		identity.RetryAfter = minute.Add(time.Minute).Sub(now)
+		return identity, ErrRateLimited
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	if limits.DailyQuota > 0 {
+		used := int(s.cache.Increment(fmt.Sprintf("apikey:quota:%s:%s", key.ID.Hex(), day.Format(dateLayout)), 25*time.Hour))
+		if used > limits.DailyQuota {
+			identity.RetryAfter = day.AddDate(0, 0, 1).Sub(now)
+			return identity, ErrQuotaExceeded
+		}
+	}
+
+	s.cache.Increment(usagePrefix(key.ID)+day.Format(dateLayout)+":"+endpoint(r), usageRetention)
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval {
+		if err := s.apiKeyRepo.Touch(ctx, key.ID, now); err != nil {
+			log.Warnf(ctx, "Failed to record use of API key %s: %v", key.ID.Hex(), err)
+		}
+	}
+
+	return identity, nil
+}
+
+// limits returns the key's own limits, or the server's where it has none.
+func (s *service) limits(key *domain.APIKey) Limits {
+	limits := s.defaults
+	if key.RateLimitPerMinute != nil {
+		limits.RateLimitPerMinute = *key.RateLimitPerMinute
+	}
+	if key.DailyQuota != nil {
+		limits.DailyQuota = *key.DailyQuota
+	}
+	return limits
+}
+
+// getAPIKey returns a key the caller may manage: one of their own, or any for super admins.
+func (s *service) getAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidAPIKeyID
+	}
+	key, err := s.apiKeyRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, "manage", key.User); err != nil {
+		if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusForbidden {
+			// Others' keys are not found rather than forbidden, so their IDs can't be probed
+			return nil, errors.New("API_KEY_NOT_FOUND", "API key not found", 404, nil, nil)
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func authorize(ctx context.Context, action string, owner primitive.ObjectID) error {
+	return middleware.Authorize(ctx, action, policy.Resource{Type: "apikey", Owners: []string{owner.Hex()}})
+}
+
+func generateKey() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.New("RANDOM_GENERATION_ERROR", "Failed to generate API key", 500, err, nil)
+	}
+	return keyPrefix + hex.EncodeToString(bytes), nil
+}
+
+func hashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func usagePrefix(id primitive.ObjectID) string {
+	return "apikey:usage:" + id.Hex() + ":"
+}
+
+// endpoint names the route a request matched, e.g. "GET /api/reports/{id}", so usage is
+// counted per endpoint rather than per URL.
+func endpoint(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return r.Method + " " + template
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+func sortedUsage(counts map[string]int64) []EndpointUsage {
+	usage := make([]EndpointUsage, 0, len(counts))
+	for endpoint, count := range counts {
+		usage = append(usage, EndpointUsage{Endpoint: endpoint, Count: count})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Endpoint < usage[j].Endpoint
+	})
+	return usage
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return date, nil
+}
+
+func isNotFound(err error) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Status() == http.StatusNotFound
+}
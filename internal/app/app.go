@@ -0,0 +1,619 @@
+// Package app assembles the server from its configuration: it connects the stores, builds the
+// repositories, services, handlers and middleware of every feature, and runs their background
+// workers between Start and Stop. The feature packages below it know nothing of each other's
+// wiring; this is the only place that does.
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"finsolvz-backend/internal/app/auth"
+	"finsolvz-backend/internal/app/backup"
+	"finsolvz-backend/internal/app/company"
+	"finsolvz-backend/internal/app/deadline"
+	"finsolvz-backend/internal/app/digest"
+	"finsolvz-backend/internal/app/export"
+	"finsolvz-backend/internal/app/integrity"
+	"finsolvz-backend/internal/app/invitation"
+	"finsolvz-backend/internal/app/kpi"
+	"finsolvz-backend/internal/app/legal"
+	"finsolvz-backend/internal/app/rate"
+	"finsolvz-backend/internal/app/realtime"
+	"finsolvz-backend/internal/app/report"
+	"finsolvz-backend/internal/app/reporttype"
+	"finsolvz-backend/internal/app/retention"
+	"finsolvz-backend/internal/app/sandbox"
+	"finsolvz-backend/internal/app/system"
+	"finsolvz-backend/internal/app/user"
+	"finsolvz-backend/internal/app/warehouse"
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/ai"
+	"finsolvz-backend/internal/platform/diagnostics"
+	"finsolvz-backend/internal/platform/fx"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/metrics"
+	"finsolvz-backend/internal/platform/notify"
+	"finsolvz-backend/internal/platform/oauth"
+	"finsolvz-backend/internal/platform/outbox"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/platform/tasks"
+	warehousesink "finsolvz-backend/internal/platform/warehouse"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// repoCacheTTL is how long the repository cache keeps lookups.
+const repoCacheTTL = 5 * time.Minute
+
+// releaseWait is how long Stop lets interrupted tasks put themselves back once the shutdown
+// deadline has passed.
+const releaseWait = 2 * time.Second
+
+// App is the assembled server. New builds it, Start runs its background workers, Handler
+// serves its API and Stop shuts it down.
+type App struct {
+	cfg   *config.Config
+	repos repositories
+	// sandboxRepos are opened on the sandbox database, for the features it serves
+	sandboxRepos repositories
+
+	// Repository-level cache for the lookups hit by population and ownership checks. With
+	// Redis, it and the service cache and rate limits are shared by every instance, so a
+	// user update or session revocation on one instance is seen by all of them at once.
+	repoCache      utils.Cache
+	rateLimitCache utils.Cache
+	redis          *utils.RedisClient
+
+	httpMetrics      *metrics.HTTPCollector
+	connMetrics      *metrics.ConnCollector
+	metricCollectors []metrics.Collector
+	// Statistics of the active driver, reported by /api/admin/system
+	databaseStats system.DatabaseStatsFunc
+	// Startup self-diagnostics, also served at /readyz
+	diagnosticChecks []diagnostics.Check
+	diagnostics      *diagnostics.Runner
+
+	// Pushes outbox events to WebSocket clients
+	realtimeHub  *realtime.Hub
+	emailService utils.EmailService
+	store        storage.ObjectStore
+	downloads    *storage.Downloads
+	model        ai.Model
+	taskQueue    *tasks.Queue
+
+	legalService      legal.Service
+	authService       auth.Service
+	oauthService      auth.OAuthService  // nil unless Google sign-in is configured
+	ssoService        auth.SSOService    // nil without APP_URL, which providers redirect back to
+	sessions          *auth.Sessions     // nil on Postgres
+	loginMonitor      *auth.LoginMonitor // nil on Postgres
+	userService       user.Service
+	invitationService invitation.Service // nil on Postgres
+	reportTypeService reporttype.Service
+	companyService    company.Service
+	reportService     report.Service
+	integrityService  integrity.Service
+	kpiService        kpi.Service
+	backupService     backup.Service
+	exportService     export.Service
+	deadlineService   deadline.Service
+	rateService       rate.Service
+	retentionService  retention.Service
+	warehouseService  warehouse.Service
+	sandboxService    sandbox.Service
+
+	handler http.Handler
+
+	// workers run from Start until Stop cancels their context
+	workers     []func(context.Context)
+	stopWorkers context.CancelFunc
+	running     sync.WaitGroup
+	// drain outlives the workers' context so running tasks can finish; Stop cancels it when
+	// its deadline comes first
+	drain     context.Context
+	stopDrain context.CancelFunc
+	// closers release connections on Stop, last opened first
+	closers []func()
+}
+
+// New connects to the configured stores and builds every feature. Nothing runs in the
+// background until Start.
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
+	a := &App{
+		cfg:            cfg,
+		repoCache:      utils.NewMemoryCache(),
+		rateLimitCache: utils.NewMemoryCache(),
+		httpMetrics:    metrics.NewHTTPCollector(),
+		connMetrics:    metrics.NewConnCollector(),
+		realtimeHub:    realtime.NewHub(),
+	}
+	a.metricCollectors = []metrics.Collector{a.httpMetrics, a.connMetrics, middleware.DeprecatedRequests, middleware.ResponseCacheRequests}
+	a.diagnosticChecks = []diagnostics.Check{{
+		Name: "config",
+		Run: func(ctx context.Context) (string, error) {
+			detail := fmt.Sprintf("APP_ENV=%q, database %s, secrets from %s, storage %s", cfg.Env, cfg.Database.Driver, cfg.SecretsProvider(), cfg.Storage.Driver)
+			if warnings := cfg.Warnings(); len(warnings) > 0 {
+				return detail, diagnostics.Warning("%s", strings.Join(warnings, "; "))
+			}
+			return detail, nil
+		},
+	}}
+
+	// In-memory caches run a janitor until closed; Redis ones close with their client
+	a.onStop(func() {
+		closeCache(a.repoCache)
+		closeCache(a.rateLimitCache)
+		closeCache(utils.GetCache())
+	})
+
+	if err := utils.SetJWTConfig(cfg.JWT); err != nil {
+		a.close()
+		return nil, fmt.Errorf("failed to configure JWT keys: %w", err)
+	}
+	utils.SetTokenLifetimes(cfg.TokenLifetimes)
+	utils.SetCookieConfig(cfg.Cookies)
+	utils.SetPasswordPolicy(cfg.PasswordPolicy)
+	utils.ExposeErrorDetails(cfg.Profile.ErrorDetails)
+
+	if cfg.RedisURL != "" {
+		redisClient, err := utils.NewRedisClient(ctx, cfg.RedisURL)
+		if err != nil {
+			a.close()
+			return nil, err
+		}
+		a.redis = redisClient
+		a.onStop(func() { redisClient.Close() })
+		closeCache(a.repoCache)
+		closeCache(a.rateLimitCache)
+		a.repoCache = utils.NewRedisCache(redisClient, "finsolvz:repo:")
+		a.rateLimitCache = utils.NewRedisCache(redisClient, "finsolvz:ratelimit:")
+		utils.SetCache(utils.NewRedisCache(redisClient, "finsolvz:service:"))
+		log.Infof(ctx, "Caches are shared through Redis at %s", redisClient.Addr())
+	}
+
+	if err := a.connect(ctx); err != nil {
+		a.close()
+		return nil, err
+	}
+	a.setAccessChecks()
+
+	if err := a.buildServices(ctx); err != nil {
+		a.close()
+		return nil, err
+	}
+	a.diagnostics = diagnostics.NewRunner(a.diagnosticChecks...)
+
+	handler, err := a.routes(ctx)
+	if err != nil {
+		a.close()
+		return nil, err
+	}
+	a.handler = handler
+
+	return a, nil
+}
+
+// setAccessChecks installs the policy, tenant and session lookups of the auth middleware.
+func (a *App) setAccessChecks() {
+	userRepo, organizationRepo := a.repos.user, a.repos.organization
+
+	// Company rules of the access policy need the companies of the user asking
+	policy.SetDefault(policy.New(a.cfg.Policy, func(ctx context.Context, userID string) ([]string, error) {
+		id, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return nil, nil
+		}
+		user, err := userRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		companies := make([]string, len(user.Company))
+		for i, companyID := range user.Company {
+			companies[i] = companyID.Hex()
+		}
+		return companies, nil
+	}))
+
+	// Requests only reach the users, companies and reports of the organization in their token,
+	// or of the instance for users outside any organization
+	if organizationRepo != nil {
+		middleware.SetTenantLookup(func(ctx context.Context, organizationID, userID string) (*domain.OrganizationMembership, error) {
+			membership := &domain.OrganizationMembership{}
+			if organizationID != "" {
+				id, err := primitive.ObjectIDFromHex(organizationID)
+				if err != nil {
+					return nil, utils.ErrUnauthorized
+				}
+				org, err := organizationRepo.GetByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				membership.Organization = org.ID
+				if uid, err := primitive.ObjectIDFromHex(userID); err == nil {
+					membership.Admin = org.IsAdmin(uid)
+				}
+			}
+			companies, err := organizationRepo.Companies(ctx, membership.Organization)
+			if err != nil {
+				return nil, err
+			}
+			membership.Companies = companies
+			return membership, nil
+		})
+	}
+
+	// Token requests act with the user's current role, read through the user cache, and users
+	// who were deleted lose access at once (deactivated ones do regardless)
+	if a.cfg.RoleFromDatabase {
+		middleware.SetRoleLookup(func(ctx context.Context, userID string) (string, error) {
+			id, err := primitive.ObjectIDFromHex(userID)
+			if err != nil {
+				return "", utils.ErrUnauthorized
+			}
+			user, err := userRepo.GetByID(ctx, id)
+			if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusNotFound {
+				return "", middleware.ErrAccountDisabled
+			} else if err != nil {
+				return "", err
+			}
+			if !user.Active() {
+				return "", middleware.ErrAccountDisabled
+			}
+			return string(user.Role), nil
+		})
+	}
+
+	// Tokens revoked by logging out are rejected until they expire
+	utils.SetTokenDenylist(auth.NewDenylist(a.repos.token, a.repoCache))
+
+	// Sessions record the tokens issued at login and when they were last used
+	if a.repos.session != nil {
+		a.sessions = auth.NewSessions(a.repos.session, a.repoCache)
+	}
+	sessions := a.sessions
+
+	// Tokens issued before the user revoked their sessions are rejected, and those of deactivated
+	// and deleted users. Lookup failures reject the request too, rather than let them through.
+	middleware.SetSessionCheck(func(ctx context.Context, claims *utils.Claims) error {
+		if sessions != nil {
+			sessions.Touch(ctx, claims)
+		}
+		id, err := primitive.ObjectIDFromHex(claims.UserID)
+		if err != nil {
+			return utils.ErrUnauthorized
+		}
+		user, err := userRepo.GetByID(ctx, id)
+		if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusNotFound {
+			return middleware.ErrAccountDisabled
+		} else if err != nil {
+			log.Errorf(ctx, "Session check failed for user %s: %v", claims.UserID, err)
+			return utils.ErrAuthUnavailable
+		}
+		if !user.Active() {
+			return middleware.ErrAccountDisabled
+		}
+		if claims.IssuedAt == nil {
+			return nil
+		}
+		// Renewed and refreshed tokens belong to the login they were issued from
+		issuedAt := claims.IssuedAt.Time
+		if claims.AuthTime != nil {
+			issuedAt = claims.AuthTime.Time
+		}
+		// So are those naming an organization the user has since left or joined
+		if user.SessionRevoked(issuedAt) || claims.Organization != user.OrganizationClaim() {
+			return utils.ErrSessionRevoked
+		}
+		return nil
+	})
+}
+
+// buildServices builds the services shared by several handlers or run by background workers,
+// and schedules those workers.
+func (a *App) buildServices(ctx context.Context) error {
+	cfg, r := a.cfg, &a.repos
+
+	a.emailService = utils.NewEmailService(cfg.Email)
+	textSender, err := utils.NewMessageSender(cfg.SMS)
+	if err != nil {
+		return fmt.Errorf("failed to configure SMS/WhatsApp: %w", err)
+	}
+	notifier := notify.NewNotifier(a.emailService, textSender)
+	if a.store, err = storage.New(cfg.Storage); err != nil {
+		return fmt.Errorf("failed to configure storage: %w", err)
+	}
+	if a.model, err = ai.New(cfg.AI); err != nil {
+		return fmt.Errorf("failed to configure AI: %w", err)
+	}
+	fxProvider, err := fx.New(cfg.FX)
+	if err != nil {
+		return fmt.Errorf("failed to configure exchange rates: %w", err)
+	}
+
+	// Users must accept the current terms and privacy policy before anything else
+	a.legalService = legal.NewService(r.user, cfg.Legal)
+	if len(cfg.Legal) > 0 {
+		middleware.SetConsentCheck(a.legalService.RequireAccepted)
+	}
+
+	if r.login != nil {
+		a.loginMonitor = auth.NewLoginMonitor(r.login, r.outbox, r.token, notifier, cfg.AppURL)
+	}
+	a.authService = auth.NewService(r.user, r.token, notifier, a.loginMonitor, a.sessions, cfg.AppURL)
+	google, err := oauth.NewGoogle(cfg.GoogleOAuth)
+	if err != nil {
+		return fmt.Errorf("failed to configure Google sign-in: %w", err)
+	}
+	if google != nil {
+		a.oauthService = auth.NewOAuthService(google, r.user, r.token, a.loginMonitor, a.sessions)
+	}
+	if r.sso != nil && cfg.AppURL != "" {
+		a.ssoService = auth.NewSSOService(r.sso, r.company, r.user, r.token, a.loginMonitor, a.sessions, cfg.AppURL)
+	}
+	a.userService = user.NewService(r.user, r.outbox, r.transactor, a.store)
+	if r.invitation != nil {
+		a.invitationService = invitation.NewService(r.invitation, r.user, r.token, notifier, cfg.AppURL)
+	}
+	a.reportTypeService = reporttype.NewService(r.reportType)
+	a.companyService = company.NewService(r.company, r.user, r.outbox, r.transactor, a.store)
+	a.reportService = report.NewService(r.report, r.outbox, r.transactor)
+	a.integrityService = integrity.NewService(r.integrity)
+
+	// Each consumer takes the events on its own: one failing doesn't hold up or replay the others
+	consumers := []outbox.Consumer{{Name: "log", Publisher: outbox.NewLogPublisher()}}
+	if len(cfg.Outbox.WebhookURLs) > 0 {
+		consumers[0] = outbox.Consumer{Name: "webhooks", Publisher: outbox.NewWebhookPublisher(cfg.Outbox.WebhookURLs, cfg.Outbox.WebhookSecret)}
+	}
+	accessNotifier := report.NewAccessNotifier(r.user, a.emailService, cfg.AppURL, cfg.Jobs.AccessEmailWindow)
+	a.goWorker(accessNotifier.Run)
+	anomalyDetector := report.NewAnomalyDetector(r.user, r.company, r.outbox, notifier, cfg.AppURL, cfg.Anomaly.MassDeleteThreshold, cfg.Anomaly.MassDeleteWindow)
+	a.goWorker(anomalyDetector.Run)
+	consumers = append(consumers,
+		outbox.Consumer{Name: "access-notifier", Publisher: accessNotifier},
+		outbox.Consumer{Name: "anomaly-detector", Publisher: anomalyDetector})
+
+	// KPIs are computed from report events as well
+	if r.kpi != nil {
+		a.kpiService = kpi.NewService(r.kpi, r.report, r.company, r.reportType)
+		consumers = append(consumers, outbox.Consumer{Name: "kpi", Publisher: kpi.NewCalculator(a.kpiService)})
+	}
+
+	consumers = append(consumers, outbox.Consumer{Name: "realtime", Publisher: a.realtimeHub})
+	a.goWorker(a.realtimeHub.Run)
+
+	if r.webhook != nil {
+		consumers = append(consumers, outbox.Consumer{Name: "subscriptions", Publisher: outbox.NewSubscriptionPublisher(r.webhook, r.delivery)})
+		a.goWorker(outbox.NewDeliveryWorker(r.webhook, r.delivery, 5*time.Second).Run)
+	}
+	eventPublisher := outbox.NewMultiPublisher(consumers...)
+	a.goWorker(outbox.NewDispatcher(r.outbox, eventPublisher, 5*time.Second).Run)
+
+	// Report writes keep the summaries current; rebuilding them at startup also covers
+	// reports changed outside the API, such as by a backup restore
+	if r.summary != nil {
+		a.goWorker(func(ctx context.Context) {
+			count, err := r.summary.Rebuild(ctx)
+			if err != nil {
+				log.Errorf(ctx, "Failed to rebuild report summaries: %v", err)
+				return
+			}
+			log.Infof(ctx, "Rebuilt %d report summaries", count)
+		})
+	}
+
+	a.diagnosticChecks = append(a.diagnosticChecks, diagnostics.Check{
+		Name: "email",
+		Run:  a.emailService.Verify,
+	}, diagnostics.Check{
+		Name: "storage",
+		Run: func(ctx context.Context) (string, error) {
+			if err := storage.Probe(ctx, a.store); err != nil {
+				return "", err
+			}
+			return cfg.Storage.Driver + " store is writable", nil
+		},
+	})
+	if a.redis != nil {
+		a.diagnosticChecks = append(a.diagnosticChecks, diagnostics.Check{
+			Name: "redis",
+			Run: func(ctx context.Context) (string, error) {
+				if err := a.redis.Ping(ctx); err != nil {
+					return "", err
+				}
+				return "connected to " + a.redis.Addr(), nil
+			},
+		})
+	}
+
+	if r.sandbox != nil {
+		seed, err := sandbox.LoadSeed(cfg.Sandbox.SeedFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the sandbox seed: %w", err)
+		}
+		a.sandboxService = sandbox.NewService(r.sandbox, r.user, seed, cfg.Sandbox.ResetInterval)
+		a.goWorker(sandbox.NewJob(a.sandboxService, cfg.Sandbox.ResetInterval).Run)
+	}
+
+	a.downloads = storage.NewDownloads(a.store, r.outbox, cfg.Storage.LinkExpiry)
+
+	if r.backup != nil {
+		a.backupService = backup.NewService(r.backup, a.store, a.downloads, cfg.Backups)
+		if cfg.Backups.Interval > 0 {
+			a.goWorker(backup.NewJob(a.backupService, cfg.Backups.Interval).Run)
+		}
+	}
+
+	// Background tasks are stored in Mongo as well, so they are only available on the Mongo driver
+	if r.task != nil {
+		a.taskQueue = tasks.NewQueue(r.task, 2*time.Second, cfg.Jobs.TaskWorkers)
+		if a.backupService != nil {
+			a.taskQueue.Register(backup.TaskCreateBackup, backup.NewTaskHandler(a.backupService))
+		}
+		a.exportService = export.NewService(r.export, r.report, r.company, r.user, r.task, a.taskQueue, a.store, a.downloads, cfg.Jobs.ExportTTL)
+		a.taskQueue.Register(export.TaskRenderExport, export.NewTaskHandler(a.exportService))
+		if cfg.Jobs.ExportExpiryInterval > 0 {
+			a.goWorker(export.NewJob(a.exportService, cfg.Jobs.ExportExpiryInterval).Run)
+		}
+		a.goWorker(func(ctx context.Context) {
+			a.taskQueue.Run(ctx, a.drain)
+		})
+	}
+
+	if cfg.Jobs.IntegrityInterval > 0 {
+		a.goWorker(integrity.NewJob(a.integrityService, cfg.Jobs.IntegrityInterval, cfg.Jobs.IntegrityAutoRepair).Run)
+	}
+
+	if r.deadline != nil {
+		a.deadlineService = deadline.NewService(r.deadline, r.report, r.company, r.reportType, r.user, r.outbox, a.emailService)
+		if cfg.Jobs.DeadlineReminderInterval > 0 {
+			a.goWorker(deadline.NewJob(a.deadlineService, cfg.Jobs.DeadlineReminderInterval).Run)
+		}
+	}
+
+	if r.rate != nil {
+		a.rateService = rate.NewService(r.rate, fxProvider)
+		if cfg.Jobs.RateSyncInterval > 0 && fxProvider != nil {
+			a.goWorker(rate.NewJob(a.rateService, cfg.Jobs.RateSyncInterval).Run)
+		}
+	}
+
+	if r.retention != nil {
+		a.retentionService = retention.NewService(r.retention, r.company, r.user, cfg.Retention)
+		if cfg.Jobs.RetentionInterval > 0 {
+			a.goWorker(retention.NewJob(a.retentionService, cfg.Jobs.RetentionInterval, cfg.Jobs.RetentionDryRun).Run)
+		}
+	}
+
+	// Companies and reports are exported to the warehouse for analytics, off the API
+	sink, err := warehousesink.New(cfg.Warehouse, a.store)
+	if err != nil {
+		return fmt.Errorf("failed to configure the warehouse export: %w", err)
+	}
+	if r.warehouse != nil && sink != nil {
+		a.warehouseService = warehouse.NewService(r.warehouse, sink)
+		if cfg.Jobs.WarehouseInterval > 0 {
+			a.goWorker(warehouse.NewJob(a.warehouseService, cfg.Jobs.WarehouseInterval).Run)
+		}
+	}
+
+	if cfg.Jobs.DigestInterval > 0 {
+		a.goWorker(digest.NewJob(digest.NewService(r.user, r.report, r.company, a.emailService, cfg.AppURL), r.digest, cfg.Jobs.DigestInterval).Run)
+	}
+
+	return nil
+}
+
+// goWorker schedules fn to run in the background from Start until Stop cancels its context.
+func (a *App) goWorker(fn func(ctx context.Context)) {
+	a.workers = append(a.workers, fn)
+}
+
+// onStop registers fn to release a connection on Stop.
+func (a *App) onStop(fn func()) {
+	a.closers = append(a.closers, fn)
+}
+
+// Start runs the background workers and the startup diagnostics. Workers stop when ctx is
+// cancelled or Stop is called.
+func (a *App) Start(ctx context.Context) {
+	workerCtx, stop := context.WithCancel(ctx)
+	a.stopWorkers = stop
+	a.drain, a.stopDrain = context.WithCancel(context.WithoutCancel(ctx))
+	for _, worker := range a.workers {
+		a.running.Add(1)
+		go func(worker func(context.Context)) {
+			defer a.running.Done()
+			worker(workerCtx)
+		}(worker)
+	}
+	go a.diagnostics.Run(ctx)
+}
+
+// Stop cancels the background workers, then waits until they return or ctx is done, letting
+// running tasks finish. Tasks still running at the deadline are interrupted and put back for
+// the next instance, without counting an attempt. It then closes the connections.
+func (a *App) Stop(ctx context.Context) {
+	if a.stopWorkers != nil {
+		a.stopWorkers()
+
+		stopped := make(chan struct{})
+		go func() {
+			a.running.Wait()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			log.Warn(ctx, "Background workers did not stop in time, interrupting running tasks")
+			a.stopDrain()
+			select {
+			case <-stopped:
+			case <-time.After(releaseWait):
+				log.Warn(ctx, "Background workers are still running, closing connections anyway")
+			}
+		}
+		a.stopDrain()
+	}
+	a.close()
+}
+
+// closeCache stops the janitor of an in-memory cache.
+func closeCache(cache utils.Cache) {
+	if memory, ok := cache.(*utils.MemoryCache); ok {
+		memory.Close()
+	}
+}
+
+func (a *App) close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+	a.closers = nil
+}
+
+// Health runs the diagnostics checks of the database, caches, storage and email now.
+func (a *App) Health(ctx context.Context) *diagnostics.Report {
+	return a.diagnostics.Run(ctx)
+}
+
+// Handler serves the API.
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// Server returns the HTTP server of the API, configured with the HTTP settings.
+func (a *App) Server() *http.Server {
+	handler := a.handler
+	if a.cfg.HTTP.H2C {
+		// Clients that don't start with HTTP/2 keep being served over HTTP/1.1
+		handler = h2c.NewHandler(handler, &http2.Server{
+			MaxConcurrentStreams: uint32(a.cfg.HTTP.MaxConcurrentStreams),
+			IdleTimeout:          a.cfg.HTTP.IdleTimeout,
+		})
+	}
+
+	server := &http.Server{
+		Addr:              ":" + a.cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: a.cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       a.cfg.HTTP.ReadTimeout,
+		WriteTimeout:      a.cfg.HTTP.WriteTimeout,
+		IdleTimeout:       a.cfg.HTTP.IdleTimeout,
+		MaxHeaderBytes:    a.cfg.HTTP.MaxHeaderBytes,
+		ConnState:         a.connMetrics.ConnState,
+	}
+	server.SetKeepAlivesEnabled(a.cfg.HTTP.KeepAlives)
+	return server
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+)
+
+// Client describes where a login comes from.
+type Client struct {
+	IP        string
+	Country   string
+	Location  *domain.GeoPoint
+	UserAgent string
+}
+
+// Headers with the client's country code and "latitude,longitude", in order of preference.
+// Google Cloud load balancers add them as custom request headers ({client_region} and
+// {client_city_lat_long}); Cloudflare and App Engine set theirs on their own. They can only be
+// trusted when the proxy in front of the API overwrites what clients send.
+var (
+	countryHeaders  = []string{"X-Client-Region", "CF-IPCountry", "X-Appengine-Country"}
+	locationHeaders = []string{"X-Client-City-Lat-Long", "X-Appengine-CityLatLong"}
+)
+
+// clientFromRequest reads the IP address, country and location of the client making r.
+func clientFromRequest(r *http.Request) Client {
+	client := Client{IP: utils.ClientIP(r), UserAgent: r.UserAgent()}
+
+	for _, header := range countryHeaders {
+		// ZZ and XX stand for an unknown country
+		if country := strings.ToUpper(strings.TrimSpace(r.Header.Get(header))); country != "" && country != "ZZ" && country != "XX" {
+			client.Country = country
+			break
+		}
+	}
+
+	for _, header := range locationHeaders {
+		if location := parseLatLong(r.Header.Get(header)); location != nil {
+			client.Location = location
+			break
+		}
+	}
+
+	return client
+}
+
+// parseLatLong parses "latitude,longitude", returning nil for missing or unknown (0,0) locations.
+func parseLatLong(value string) *domain.GeoPoint {
+	lat, long, ok := strings.Cut(value, ",")
+	if !ok {
+		return nil
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(long), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil
+	}
+	if latitude == 0 && longitude == 0 {
+		return nil
+	}
+	return &domain.GeoPoint{Latitude: latitude, Longitude: longitude}
+}
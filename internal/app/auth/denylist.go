@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+)
+
+// denylistMissTTL bounds how long an instance keeps accepting a token revoked through another
+// instance, when the cache is not shared through Redis.
+const denylistMissTTL = time.Minute
+
+type denylist struct {
+	tokenRepo domain.SecurityTokenRepository
+	cache     utils.Cache
+}
+
+// NewDenylist stores revoked token IDs as security tokens until the tokens expire, and
+// remembers lookups in cache so requests rarely reach the store.
+func NewDenylist(tokenRepo domain.SecurityTokenRepository, cache utils.Cache) utils.TokenDenylist {
+	return &denylist{tokenRepo: tokenRepo, cache: cache}
+}
+
+func denylistKey(tokenID string) string {
+	return "denylist:" + tokenID
+}
+
+func (d *denylist) Revoke(ctx context.Context, claims *utils.Claims) error {
+	// Tokens renewed or refreshed from it share its ID, and stay revoked until the last expires
+	expiresAt := utils.SessionExpiry(claims)
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(utils.DefaultTokenLifetimes.Access)
+	}
+	userID, _ := primitive.ObjectIDFromHex(claims.UserID)
+
+	if err := d.tokenRepo.Create(ctx, &domain.SecurityToken{
+		Kind:      domain.TokenRevoked,
+		Token:     claims.ID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+	d.cache.Set(denylistKey(claims.ID), true, time.Until(expiresAt))
+	return nil
+}
+
+func (d *denylist) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+	if d.cache.Get(denylistKey(tokenID), &revoked) {
+		return revoked, nil
+	}
+
+	token, err := d.tokenRepo.GetValid(ctx, domain.TokenRevoked, tokenID)
+	if err != nil {
+		if hasCode(err, "INVALID_TOKEN") {
+			d.cache.Set(denylistKey(tokenID), false, denylistMissTTL)
+			return false, nil
+		}
+		return false, err
+	}
+	d.cache.Set(denylistKey(tokenID), true, time.Until(token.ExpiresAt))
+	return true, nil
+}
@@ -12,4 +12,18 @@ var (
 	ErrInvalidToken       = errors.New("INVALID_TOKEN", "Invalid token", http.StatusUnauthorized, nil, nil)
 	ErrUserNotFound       = errors.New("USER_NOT_FOUND", "User not found", http.StatusNotFound, nil, nil)
 	ErrEmailSendFailed    = errors.New("EMAIL_SEND_FAILED", "Failed to send email", http.StatusInternalServerError, nil, nil)
+
+	ErrOAuthStateInvalid    = errors.New("OAUTH_STATE_INVALID", "Sign-in has expired, please start again", http.StatusBadRequest, nil, nil)
+	ErrOAuthEmailUnverified = errors.New("OAUTH_EMAIL_UNVERIFIED", "The email of this account is not verified", http.StatusForbidden, nil, nil)
+	ErrOAuthAccountNotFound = errors.New("OAUTH_ACCOUNT_NOT_FOUND", "No user is registered with the email of this account", http.StatusForbidden, nil, nil)
+	ErrOAuthAccountMismatch = errors.New("OAUTH_ACCOUNT_MISMATCH", "This user is linked to another account of the provider", http.StatusForbidden, nil, nil)
+
+	ErrMagicLinkInvalid    = errors.New("MAGIC_LINK_INVALID", "This login link is invalid, expired or already used", http.StatusUnauthorized, nil, nil)
+	ErrSSODisabled         = errors.New("SSO_DISABLED", "Single sign-on is disabled for this company", http.StatusForbidden, nil, nil)
+	ErrSSOIssuerInvalid    = errors.New("SSO_ISSUER_INVALID", "Issuer must be an https URL", http.StatusBadRequest, nil, nil)
+	ErrSSODomainMismatch   = errors.New("SSO_DOMAIN_MISMATCH", "The identity provider signed in an email outside the company's domains", http.StatusForbidden, nil, nil)
+	ErrSSOUserNotInCompany = errors.New("SSO_USER_NOT_IN_COMPANY", "This user does not belong to the company of the identity provider", http.StatusForbidden, nil, nil)
+
+	ErrMagicLinkUnavailable = errors.New("MAGIC_LINK_UNAVAILABLE", "Login links are not available", http.StatusServiceUnavailable, nil, nil)
+	ErrSessionNotFound      = errors.New("SESSION_NOT_FOUND", "Session not found", http.StatusNotFound, nil, nil)
 )
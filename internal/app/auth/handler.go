@@ -2,32 +2,84 @@ package auth
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
 	"finsolvz-backend/internal/utils"
 )
 
 type Handler struct {
 	service   Service
+	oauth     OAuthService
+	sso       SSOService
+	sessions  *Sessions
+	monitor   *LoginMonitor
 	validator *validator.Validate
 }
 
-func NewHandler(service Service) *Handler {
+// NewHandler creates the auth handler. oauth may be nil, which leaves out signing in with
+// Google, sso too, which leaves out single sign-on, sessions, which leaves out session
+// management, and monitor, which leaves out login history.
+func NewHandler(service Service, oauth OAuthService, sso SSOService, sessions *Sessions, monitor *LoginMonitor) *Handler {
 	return &Handler{
 		service:   service,
+		oauth:     oauth,
+		sso:       sso,
+		sessions:  sessions,
+		monitor:   monitor,
 		validator: validator.New(),
 	}
 }
 
 // RegisterRoutes registers auth routes
-func (h *Handler) RegisterRoutes(router *mux.Router) {
+// @Tags Authentication
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
 	router.HandleFunc("/api/login", h.Login).Methods("POST")
+	router.HandleFunc("/api/logout", h.Logout).Methods("POST")
+	router.HandleFunc("/api/token/refresh", h.RefreshToken).Methods("POST")
 	router.HandleFunc("/api/forgot-password", h.ForgotPassword).Methods("POST")
 	router.HandleFunc("/api/reset-password", h.ResetPassword).Methods("POST")
+	router.HandleFunc("/api/password-policy", h.GetPasswordPolicy).Methods("GET")
+	router.HandleFunc("/.well-known/jwks.json", h.GetJWKS).Methods("GET")
+	router.HandleFunc("/api/login-alerts/revoke", h.RevokeLogin).Methods("POST")
+	router.HandleFunc("/api/login/magic-link", h.RequestMagicLink).Methods("POST")
+	router.HandleFunc("/api/login/magic-link/verify", h.MagicLogin).Methods("POST")
+	if h.oauth != nil {
+		router.HandleFunc("/api/login/google", h.StartGoogleLogin).Methods("GET")
+		router.HandleFunc("/api/login/google", h.GoogleLogin).Methods("POST")
+	}
+	if h.sso != nil {
+		router.HandleFunc("/api/login/sso/start", h.StartSSOLogin).Methods("POST")
+		router.HandleFunc("/api/login/sso", h.LoginWithSSO).Methods("POST")
+		router.HandleFunc("/api/sso/companies/{id}/metadata", h.GetSSOMetadata).Methods("GET")
+
+		adminOnly := router.PathPrefix("").Subrouter()
+		adminOnly.Use(authMiddleware)
+		adminOnly.Use(middleware.RequirePermission("manage", "sso"))
+		adminOnly.HandleFunc("/api/admin/sso/companies/{id}", h.GetSSOConnection).Methods("GET")
+		adminOnly.HandleFunc("/api/admin/sso/companies/{id}", h.SaveSSOConnection).Methods("PUT")
+		adminOnly.HandleFunc("/api/admin/sso/companies/{id}", h.DeleteSSOConnection).Methods("DELETE")
+	}
+	if h.sessions != nil {
+		protected := router.PathPrefix("").Subrouter()
+		protected.Use(authMiddleware)
+		protected.HandleFunc("/api/sessions", h.GetSessions).Methods("GET")
+		protected.HandleFunc("/api/sessions/{id}", h.RevokeSession).Methods("DELETE")
+	}
+	if h.monitor != nil {
+		protected := router.PathPrefix("").Subrouter()
+		protected.Use(authMiddleware)
+		protected.HandleFunc("/api/me/login-history", h.GetLoginHistory).Methods("GET")
+		protected.HandleFunc("/api/users/{id}/login-history", h.GetUserLoginHistory).Methods("GET")
+	}
 }
 
+// @Summary User login
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -39,6 +91,11 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		utils.HandleValidationError(w, err, r)
 		return
 	}
+	req.Client = clientFromRequest(r)
+	if req.SessionCookie && !utils.CookieAuthEnabled() {
+		utils.HandleHTTPError(w, utils.ErrCookieAuthDisabled, r)
+		return
+	}
 
 	response, err := h.service.Login(r.Context(), req)
 	if err != nil {
@@ -46,11 +103,71 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+	respondLogin(w, response, req.SessionCookie)
+}
+
+// respondLogin answers a successful login with the token and when it expires in seconds, and
+// the refresh token when they are enabled.
+func respondLogin(w http.ResponseWriter, response *AuthResponse, sessionCookie bool) {
+	// Cookie clients never see the token; they send the CSRF token with every change instead
+	if sessionCookie {
+		csrf := utils.SetSessionCookies(w, response.Token, response.ExpiresAt)
+		utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+			"csrf_token": csrf,
+		})
+		return
+	}
+
+	body := map[string]interface{}{
 		"access_token": response.Token,
-	})
+		"expires_in":   int(time.Until(response.ExpiresAt).Seconds()),
+	}
+	if response.RefreshToken != "" {
+		body["refresh_token"] = response.RefreshToken
+	}
+	utils.RespondJSON(w, http.StatusOK, body)
+}
+
+// @Summary Refresh a token
+// @Description Exchanges a refresh token, returned by logins when JWT_REFRESH_TTL is set, for a new
+// @Description token and refresh token. Fails with SESSION_EXPIRED once the login reaches JWT_MAX_AGE.
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	response, err := h.service.Refresh(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	respondLogin(w, response, false)
 }
 
+// @Summary Log out
+// @Description Revokes the bearer token or session cookie of the request, which is rejected
+// @Description from then on, and removes the session cookies.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if token, err := utils.ExtractToken(r); err == nil {
+		if err := h.service.Logout(r.Context(), token); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	}
+
+	utils.ClearSessionCookies(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Request password reset
 func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	var req ForgotPasswordRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -73,6 +190,21 @@ func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Get the password policy
+// @Description The rules new passwords must satisfy, for forms to check them as users type.
+func (h *Handler) GetPasswordPolicy(w http.ResponseWriter, r *http.Request) {
+	utils.RespondJSON(w, http.StatusOK, utils.CurrentPasswordPolicy())
+}
+
+// @Summary Get the token signing keys
+// @Description The JSON Web Key Set of the public keys tokens are verified with, for other
+// @Description services to validate them. Empty unless tokens are signed with RS256.
+func (h *Handler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.RespondJSON(w, http.StatusOK, utils.PublicJWKS())
+}
+
+// @Summary Reset password with token
 func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	var req ResetPasswordRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -94,3 +226,113 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		"message": "Password successfully reset",
 	})
 }
+
+// @Summary Sign out everywhere after a suspicious login
+// @Description Redeems the token of the "This wasn't me" link in a suspicious login alert. Every
+// @Description session of the user is revoked and a new password is sent to them.
+func (h *Handler) RevokeLogin(w http.ResponseWriter, r *http.Request) {
+	var req RevokeLoginRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	if err := h.service.RevokeLogin(r.Context(), req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "All sessions have been signed out and a new password has been sent to you",
+	})
+}
+
+// @Summary List your sessions
+// @Description The devices you are signed in on, with the IP address and user agent they logged
+// @Description in from and when they were last seen. The session of the request is current.
+func (h *Handler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userCtx, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.HandleHTTPError(w, utils.ErrUnauthorized, r)
+		return
+	}
+
+	sessions, err := h.sessions.List(r.Context(), userCtx.UserID, userCtx.TokenID)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, sessions)
+}
+
+// @Summary Sign a session out
+// @Description Revokes the token of one of your sessions, which is rejected from then on.
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userCtx, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.HandleHTTPError(w, utils.ErrUnauthorized, r)
+		return
+	}
+
+	if err := h.sessions.Revoke(r.Context(), userCtx.UserID, mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List your logins
+// @Description Your sign-in attempts, successful or not, newest first, with the IP address and
+// @Description user agent they came from and why successful ones looked suspicious.
+func (h *Handler) GetLoginHistory(w http.ResponseWriter, r *http.Request) {
+	userCtx, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		utils.HandleHTTPError(w, utils.ErrUnauthorized, r)
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(userCtx.UserID)
+	if err != nil {
+		utils.HandleHTTPError(w, utils.ErrUnauthorized, r)
+		return
+	}
+
+	h.respondLoginHistory(w, r, userID)
+}
+
+// @Summary List the logins of a user
+// @Description The sign-in attempts of a user, as they see them. Only for those allowed to list
+// @Description users.
+func (h *Handler) GetUserLoginHistory(w http.ResponseWriter, r *http.Request) {
+	if err := middleware.Authorize(r.Context(), "list", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, ErrUserNotFound, r)
+		return
+	}
+
+	h.respondLoginHistory(w, r, userID)
+}
+
+func (h *Handler) respondLoginHistory(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	pagination := utils.GetPaginationParams(r)
+
+	logins, total, err := h.monitor.History(r.Context(), userID, pagination.Skip, pagination.Limit)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	pagination.Total = total
+	utils.SetPaginationHeaders(w, r, pagination)
+	utils.RespondJSON(w, http.StatusOK, utils.CreatePaginatedResponse(logins, pagination))
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/notify"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	recentLogins      = 20                 // logins a new one is compared with
+	maxTravelSpeed    = 1000.0             // km/h, faster than any airliner
+	minTravelDistance = 500.0              // km; closer locations are within geolocation error
+	revokeLinkTTL     = 7 * 24 * time.Hour // as long as the tokens it revokes stay valid
+)
+
+// LoginMonitor flags logins from a country or IP address the user did not recently log in
+// from, or too far from the previous login to have travelled in between. Flagged logins are
+// published as user.suspicious_login events, and the user is alerted with a "This wasn't me"
+// link that revokes their sessions. Failed logins are recorded too, for the user's login
+// history.
+type LoginMonitor struct {
+	loginRepo  domain.LoginRepository
+	outboxRepo domain.OutboxRepository
+	tokenRepo  domain.SecurityTokenRepository
+	notifier   notify.Notifier
+	appURL     string
+}
+
+// NewLoginMonitor creates a monitor. Alerts only carry the revocation link when appURL, the
+// base URL of the web app, is set.
+func NewLoginMonitor(loginRepo domain.LoginRepository, outboxRepo domain.OutboxRepository, tokenRepo domain.SecurityTokenRepository, notifier notify.Notifier, appURL string) *LoginMonitor {
+	return &LoginMonitor{
+		loginRepo:  loginRepo,
+		outboxRepo: outboxRepo,
+		tokenRepo:  tokenRepo,
+		notifier:   notifier,
+		appURL:     strings.TrimRight(appURL, "/"),
+	}
+}
+
+// Record stores a successful login and reports it when suspicious. Failures are logged rather
+// than returned, so they never block the login itself.
+func (m *LoginMonitor) Record(ctx context.Context, user *domain.User, client Client) {
+	recent, err := m.loginRepo.GetRecent(ctx, user.ID, recentLogins)
+	if err != nil {
+		log.Warnf(ctx, "Login monitor: failed to read recent logins of user %s: %v", user.ID.Hex(), err)
+	}
+
+	login := newLogin(user, client, domain.LoginSucceeded)
+	login.Suspicious = suspiciousReasons(login, recent, login.CreatedAt)
+
+	if err := m.loginRepo.Create(ctx, login); err != nil {
+		log.Warnf(ctx, "Login monitor: failed to record login of user %s: %v", user.ID.Hex(), err)
+	}
+	if len(login.Suspicious) == 0 {
+		return
+	}
+
+	log.Warnf(ctx, "Suspicious login of user %s from %s (%s): %v", user.ID.Hex(), login.IP, login.Country, login.Suspicious)
+
+	event, err := domain.NewEvent(domain.EventUserSuspiciousLogin, user.ID, SuspiciousLoginEvent{
+		UserID:    user.ID.Hex(),
+		IP:        login.IP,
+		Country:   login.Country,
+		UserAgent: login.UserAgent,
+		Reasons:   login.Suspicious,
+		At:        login.CreatedAt,
+	})
+	if err == nil {
+		err = m.outboxRepo.Append(ctx, event)
+	}
+	if err != nil {
+		log.Errorf(ctx, "Login monitor: failed to record suspicious login event of user %s: %v", user.ID.Hex(), err)
+	}
+
+	// Sending may take a while; the login response doesn't wait for it
+	go m.alert(context.WithoutCancel(ctx), user, login)
+}
+
+// RecordFailure stores a login of the user that failed. Failures are logged rather than
+// returned, so the login fails with its own error.
+func (m *LoginMonitor) RecordFailure(ctx context.Context, user *domain.User, client Client) {
+	if err := m.loginRepo.Create(ctx, newLogin(user, client, domain.LoginFailed)); err != nil {
+		log.Warnf(ctx, "Login monitor: failed to record failed login of user %s: %v", user.ID.Hex(), err)
+	}
+}
+
+// History returns a page of the logins of the user, successful or not, newest first, and how
+// many there are.
+func (m *LoginMonitor) History(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]*LoginResponse, int, error) {
+	logins, total, err := m.loginRepo.GetByUser(ctx, userID, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*LoginResponse, len(logins))
+	for i, login := range logins {
+		responses[i] = ToLoginResponse(login)
+	}
+	return responses, total, nil
+}
+
+func newLogin(user *domain.User, client Client, outcome string) *domain.Login {
+	return &domain.Login{
+		UserID:    user.ID,
+		Outcome:   outcome,
+		IP:        client.IP,
+		Country:   client.Country,
+		Location:  client.Location,
+		UserAgent: client.UserAgent,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (m *LoginMonitor) alert(ctx context.Context, user *domain.User, login *domain.Login) {
+	place := login.Country
+	if place == "" {
+		place = "an unknown location"
+	}
+	alert := notify.Alert{
+		Subject: "Unusual sign-in to your account",
+		Message: fmt.Sprintf("Your account was signed in to from %s (IP address %s) on %s UTC, which doesn't match your usual sign-ins.",
+			place, login.IP, login.CreatedAt.UTC().Format("2 Jan 2006 15:04")),
+	}
+
+	if m.appURL != "" {
+		token, err := m.revokeToken(ctx, user)
+		if err != nil {
+			log.Errorf(ctx, "Login monitor: failed to create revocation link for user %s: %v", user.ID.Hex(), err)
+		} else {
+			alert.Message += " If this wasn't you, sign out everywhere and get a new password with the link below."
+			alert.Action = &utils.EmailAction{
+				Label: "This wasn't me",
+				URL:   m.appURL + "/security/revoke?token=" + url.QueryEscape(token),
+			}
+		}
+	}
+	if alert.Action == nil {
+		alert.Message += " If this wasn't you, reset your password right away."
+	}
+
+	if err := m.notifier.SendAlert(ctx, user, alert); err != nil {
+		log.Errorf(ctx, "Login monitor: failed to alert user %s: %v", user.ID.Hex(), err)
+	}
+}
+
+func (m *LoginMonitor) revokeToken(ctx context.Context, user *domain.User) (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.New("RANDOM_GENERATION_ERROR", "Failed to generate revocation token", 500, err, nil)
+	}
+	token := hex.EncodeToString(bytes)
+
+	err := m.tokenRepo.Create(ctx, &domain.SecurityToken{
+		Kind:      domain.TokenLoginRevoke,
+		Token:     token,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(revokeLinkTTL),
+	})
+	return token, err
+}
+
+// suspiciousReasons compares a login with the recent ones of the user, newest first. The first
+// login of a user has nothing to compare with and is never suspicious.
+func suspiciousReasons(login *domain.Login, recent []*domain.Login, now time.Time) []string {
+	if len(recent) == 0 {
+		return nil
+	}
+
+	var reasons []string
+	knownIP, knownCountry, anyCountry := false, false, false
+	for _, previous := range recent {
+		knownIP = knownIP || previous.IP == login.IP
+		knownCountry = knownCountry || previous.Country == login.Country
+		anyCountry = anyCountry || previous.Country != ""
+	}
+	if login.Country != "" && anyCountry && !knownCountry {
+		reasons = append(reasons, domain.LoginNewCountry)
+	}
+	if !knownIP {
+		reasons = append(reasons, domain.LoginNewIP)
+	}
+
+	if login.Location != nil {
+		for _, previous := range recent {
+			if previous.Location == nil {
+				continue
+			}
+			distance := distanceKm(*previous.Location, *login.Location)
+			hours := now.Sub(previous.CreatedAt).Hours()
+			if distance > minTravelDistance && (hours <= 0 || distance/hours > maxTravelSpeed) {
+				reasons = append(reasons, domain.LoginImpossibleTravel)
+			}
+			break // only the latest located login matters
+		}
+	}
+
+	return reasons
+}
+
+// distanceKm is the great-circle distance between two points (haversine formula).
+func distanceKm(a, b domain.GeoPoint) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(b.Latitude - a.Latitude)
+	dLong := toRad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Latitude))*math.Cos(toRad(b.Latitude))*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// magicLinkTTL is how long a login link can be used.
+const magicLinkTTL = 15 * time.Minute
+
+func (s *service) RequestMagicLink(ctx context.Context, req MagicLinkRequest) error {
+	if s.appURL == "" {
+		return ErrMagicLinkUnavailable
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if hasCode(err, "USER_NOT_FOUND") {
+			log.Infof(ctx, "Login link requested for unregistered email")
+			return nil
+		}
+		return err
+	}
+	if !user.Active() {
+		log.Infof(ctx, "Login link requested for deactivated user %s", user.ID.Hex())
+		return nil
+	}
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return errors.New("RANDOM_GENERATION_ERROR", "Failed to generate login link", 500, err, nil)
+	}
+	token := hex.EncodeToString(bytes)
+
+	if err := s.tokenRepo.Create(ctx, &domain.SecurityToken{
+		Kind:      domain.TokenMagicLink,
+		Token:     token,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(magicLinkTTL),
+	}); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/login/magic?token=" + url.QueryEscape(token)
+	return s.notifier.SendLoginLink(ctx, user, link, magicLinkTTL)
+}
+
+func (s *service) MagicLogin(ctx context.Context, req MagicLoginRequest) (*AuthResponse, error) {
+	token, err := s.tokenRepo.GetValid(ctx, domain.TokenMagicLink, req.Token)
+	if err != nil {
+		if hasCode(err, "INVALID_TOKEN") {
+			return nil, ErrMagicLinkInvalid
+		}
+		return nil, err
+	}
+	if err := s.tokenRepo.Delete(ctx, domain.TokenMagicLink, req.Token); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		if hasCode(err, "USER_NOT_FOUND") {
+			return nil, ErrMagicLinkInvalid
+		}
+		return nil, err
+	}
+
+	return issueToken(ctx, s.monitor, s.sessions, user, req.Client)
+}
+
+// @Summary Request a login link
+// @Description Emails a link to APP_URL/login/magic?token=... that logs the user in without a
+// @Description password. The web app posts the token to POST /api/login/magic-link/verify; it
+// @Description can be used once, within 15 minutes. The response is the same whether or not
+// @Description the email is registered.
+func (h *Handler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	if err := h.service.RequestMagicLink(r.Context(), req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "If the email is registered, a login link has been sent to it",
+	})
+}
+
+// @Summary Log in with a login link
+// @Description Logs in with the token of an emailed login link, like POST /api/login.
+func (h *Handler) MagicLogin(w http.ResponseWriter, r *http.Request) {
+	var req MagicLoginRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+	req.Client = clientFromRequest(r)
+	if req.SessionCookie && !utils.CookieAuthEnabled() {
+		utils.HandleHTTPError(w, utils.ErrCookieAuthDisabled, r)
+		return
+	}
+
+	response, err := h.service.MagicLogin(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	respondLogin(w, response, req.SessionCookie)
+}
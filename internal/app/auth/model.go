@@ -9,13 +9,56 @@ import (
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required,min=2,max=50"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"` // checked against the password policy
 	Role     string `json:"role" validate:"required,oneof=SUPER_ADMIN ADMIN CLIENT"`
+	Locale   string `json:"locale,omitempty" validate:"omitempty,oneof=en id"`
+	Phone    string `json:"phone,omitempty" validate:"omitempty,e164"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// SessionCookie keeps the session in an HTTP-only cookie instead of returning the token
+	SessionCookie bool `json:"sessionCookie,omitempty"`
+	// Client is where the request comes from, set by the handler
+	Client Client `json:"-"`
+}
+
+// OAuthLoginRequest carries the code and state an identity provider redirected the user back
+// with.
+type OAuthLoginRequest struct {
+	Code          string `json:"code" validate:"required"`
+	State         string `json:"state" validate:"required"`
+	SessionCookie bool   `json:"sessionCookie,omitempty"`
+	Client        Client `json:"-"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// MagicLoginRequest carries the token of a login link.
+type MagicLoginRequest struct {
+	Token         string `json:"token" validate:"required"`
+	SessionCookie bool   `json:"sessionCookie,omitempty"`
+	Client        Client `json:"-"`
+}
+
+// SSOConnectionRequest sets up the OpenID Connect provider of a company.
+type SSOConnectionRequest struct {
+	Issuer       string `json:"issuer" validate:"required,url"`
+	ClientID     string `json:"clientId" validate:"required"`
+	ClientSecret string `json:"clientSecret,omitempty"` // required when creating the connection
+	// Domains are the email domains whose users sign in through the provider
+	Domains         []string `json:"domains" validate:"required,min=1"`
+	JITProvisioning bool     `json:"jitProvisioning"`
+	// DefaultRole of provisioned users, CLIENT when empty
+	DefaultRole string `json:"defaultRole,omitempty" validate:"omitempty,oneof=ADMIN CLIENT"`
+	Enabled     *bool  `json:"enabled,omitempty"` // true when omitted
+}
+
+type SSOStartRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
 type ForgotPasswordRequest struct {
@@ -24,23 +67,66 @@ type ForgotPasswordRequest struct {
 
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"newPassword" validate:"required,min=6"`
+	NewPassword string `json:"newPassword" validate:"required"`
+}
+
+// RevokeLoginRequest carries the token of the "This wasn't me" link of a suspicious login alert.
+type RevokeLoginRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// SuspiciousLoginEvent is the payload of user.suspicious_login events.
+type SuspiciousLoginEvent struct {
+	UserID    string    `json:"userId"`
+	IP        string    `json:"ip"`
+	Country   string    `json:"country,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Reasons   []string  `json:"reasons"`
+	At        time.Time `json:"at"`
 }
 
 // Response DTOs
 type AuthResponse struct {
-	Token string   `json:"access_token"`
-	User  UserInfo `json:"user,omitempty"`
+	Token string `json:"access_token"`
+	// RefreshToken gets new tokens from POST /api/token/refresh; empty unless refresh tokens are enabled
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"-"`
+	User         UserInfo  `json:"user,omitempty"`
+}
+
+// RefreshRequest exchanges a refresh token for a new token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// OAuthStartResponse is where to send the user to sign in with an identity provider. Clients
+// keep State and check the provider redirects back with the same one before logging in.
+type OAuthStartResponse struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+// SSOMetadata describes the app as a client of an OpenID Connect provider, for registering it
+// there.
+type SSOMetadata struct {
+	Protocol                string   `json:"protocol"`
+	RedirectURIs            []string `json:"redirectUris"`
+	ResponseTypes           []string `json:"responseTypes"`
+	GrantTypes              []string `json:"grantTypes"`
+	Scopes                  []string `json:"scopes"`
+	TokenEndpointAuthMethod string   `json:"tokenEndpointAuthMethod"`
+	RequiredClaims          []string `json:"requiredClaims"`
 }
 
 type UserInfo struct {
-	ID        string    `json:"_id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	Company   []string  `json:"company"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string          `json:"_id"`
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	Role      domain.UserRole `json:"role"`
+	Company   []string        `json:"company"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
 }
 
 // Helper to convert domain.User to UserInfo
@@ -54,9 +140,66 @@ func ToUserInfo(user *domain.User) UserInfo {
 		ID:        user.ID.Hex(),
 		Name:      user.Name,
 		Email:     user.Email,
-		Role:      string(user.Role),
+		Role:      user.Role,
 		Company:   companyIDs,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
 }
+
+// SessionResponse is a device the user is signed in on.
+type SessionResponse struct {
+	ID        string `json:"id"`
+	IP        string `json:"ip"`
+	Country   string `json:"country,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	// Current marks the session of the request's own token
+	Current    bool      `json:"current"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ToSessionResponse converts a session, which is current when its token has currentTokenID.
+func ToSessionResponse(session *domain.Session, currentTokenID string) *SessionResponse {
+	return &SessionResponse{
+		ID:         session.ID.Hex(),
+		IP:         session.IP,
+		Country:    session.Country,
+		UserAgent:  session.UserAgent,
+		Current:    currentTokenID != "" && session.TokenID == currentTokenID,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
+
+// LoginResponse is a sign-in attempt of the user.
+type LoginResponse struct {
+	ID string `json:"id"`
+	// Outcome is success or failure
+	Outcome   string `json:"outcome"`
+	IP        string `json:"ip"`
+	Country   string `json:"country,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	// Suspicious lists why a successful login looked unusual
+	Suspicious []string  `json:"suspicious,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ToLoginResponse converts a login. Logins recorded before failures were succeeded.
+func ToLoginResponse(login *domain.Login) *LoginResponse {
+	outcome := login.Outcome
+	if outcome == "" {
+		outcome = domain.LoginSucceeded
+	}
+	return &LoginResponse{
+		ID:         login.ID.Hex(),
+		Outcome:    outcome,
+		IP:         login.IP,
+		Country:    login.Country,
+		UserAgent:  login.UserAgent,
+		Suspicious: login.Suspicious,
+		CreatedAt:  login.CreatedAt,
+	}
+}
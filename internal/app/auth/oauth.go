@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/oauth"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// oauthStateTTL is how long users have to sign in at the provider.
+const oauthStateTTL = 10 * time.Minute
+
+// OAuthService signs users in with their account at an identity provider. Accounts are linked
+// by email: the first sign-in links the user registered with the account's verified email,
+// and later ones must come from the same account.
+type OAuthService interface {
+	Start(ctx context.Context) (*OAuthStartResponse, error)
+	Login(ctx context.Context, req OAuthLoginRequest) (*AuthResponse, error)
+}
+
+type oauthService struct {
+	provider  oauth.Provider
+	userRepo  domain.UserRepository
+	tokenRepo domain.SecurityTokenRepository
+	monitor   *LoginMonitor
+	sessions  *Sessions
+}
+
+// NewOAuthService creates the sign-in with provider. monitor may be nil, which leaves logins
+// unchecked, and sessions too, which leaves them unrecorded.
+func NewOAuthService(provider oauth.Provider, userRepo domain.UserRepository, tokenRepo domain.SecurityTokenRepository, monitor *LoginMonitor, sessions *Sessions) OAuthService {
+	return &oauthService{
+		provider:  provider,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		monitor:   monitor,
+		sessions:  sessions,
+	}
+}
+
+func (s *oauthService) Start(ctx context.Context) (*OAuthStartResponse, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, errors.New("RANDOM_GENERATION_ERROR", "Failed to generate sign-in state", 500, err, nil)
+	}
+	state := hex.EncodeToString(bytes)
+
+	// The state is stored so only sign-ins started here are accepted, each once
+	expiresAt := time.Now().Add(oauthStateTTL)
+	if err := s.tokenRepo.Create(ctx, &domain.SecurityToken{
+		Kind:      domain.TokenOAuthState,
+		Token:     state,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OAuthStartResponse{
+		AuthorizationURL: s.provider.AuthCodeURL(state),
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+func (s *oauthService) Login(ctx context.Context, req OAuthLoginRequest) (*AuthResponse, error) {
+	if _, err := s.tokenRepo.GetValid(ctx, domain.TokenOAuthState, req.State); err != nil {
+		if hasCode(err, "INVALID_TOKEN") {
+			return nil, ErrOAuthStateInvalid
+		}
+		return nil, err
+	}
+	if err := s.tokenRepo.Delete(ctx, domain.TokenOAuthState, req.State); err != nil {
+		return nil, err
+	}
+
+	identity, err := s.provider.Exchange(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.linkedUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return issueToken(ctx, s.monitor, s.sessions, user, req.Client)
+}
+
+// linkedUser returns the user identity signs in as, linking them on their first sign-in.
+func (s *oauthService) linkedUser(ctx context.Context, identity *oauth.Identity) (*domain.User, error) {
+	if !identity.EmailVerified || identity.Email == "" {
+		return nil, ErrOAuthEmailUnverified
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		if hasCode(err, "USER_NOT_FOUND") {
+			return nil, ErrOAuthAccountNotFound
+		}
+		return nil, err
+	}
+
+	if linked := user.Identity(identity.Provider); linked != nil {
+		// The email may have moved to another account of the provider since
+		if linked.Subject != identity.Subject {
+			return nil, ErrOAuthAccountMismatch
+		}
+		return user, nil
+	}
+
+	user.Identities = append(user.Identities, domain.ExternalIdentity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: time.Now(),
+	})
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// @Summary Start signing in with Google
+// @Description Returns the Google page to send the user to. Google redirects them back to
+// @Description GOOGLE_OAUTH_REDIRECT_URL with a code and the state, which must match the one
+// @Description returned here, to be posted to POST /api/login/google within 10 minutes.
+func (h *Handler) StartGoogleLogin(w http.ResponseWriter, r *http.Request) {
+	response, err := h.oauth.Start(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, response)
+}
+
+// @Summary Sign in with Google
+// @Description Logs in with the code Google redirected the user back with, like POST /api/login.
+// @Description The first sign-in links the user registered with the Google account's email.
+func (h *Handler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
+	var req OAuthLoginRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+	req.Client = clientFromRequest(r)
+	if req.SessionCookie && !utils.CookieAuthEnabled() {
+		utils.HandleHTTPError(w, utils.ErrCookieAuthDisabled, r)
+		return
+	}
+
+	response, err := h.oauth.Login(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	respondLogin(w, response, req.SessionCookie)
+}
+
+func hasCode(err error, code string) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Code() == code
+}
@@ -8,26 +8,52 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/notify"
 	"finsolvz-backend/internal/utils"
 	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
 )
 
 type Service interface {
 	Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error)
 	Login(ctx context.Context, req LoginRequest) (*AuthResponse, error)
+	// Logout revokes token until it expires. Invalid or expired tokens are ignored.
+	Logout(ctx context.Context, token string) error
+	// Refresh exchanges a refresh token for a new token and refresh token of the same login.
+	Refresh(ctx context.Context, req RefreshRequest) (*AuthResponse, error)
 	ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error
 	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+	// RevokeLogin signs the user of a suspicious login alert out everywhere and sends them a
+	// new password, so whoever logged in loses access.
+	RevokeLogin(ctx context.Context, req RevokeLoginRequest) error
+	// RequestMagicLink emails a one-time login link to the user registered with the email.
+	// It succeeds for unknown emails too, so it cannot be used to find registered users.
+	RequestMagicLink(ctx context.Context, req MagicLinkRequest) error
+	// MagicLogin logs in with the token of a login link, which can only be used once.
+	MagicLogin(ctx context.Context, req MagicLoginRequest) (*AuthResponse, error)
 }
 
 type service struct {
-	userRepo     domain.UserRepository
-	emailService utils.EmailService
+	userRepo  domain.UserRepository
+	tokenRepo domain.SecurityTokenRepository
+	notifier  notify.Notifier
+	monitor   *LoginMonitor
+	sessions  *Sessions
+	appURL    string
 }
 
-func NewService(userRepo domain.UserRepository, emailService utils.EmailService) Service {
+// NewService creates the auth service. monitor may be nil, which leaves logins unchecked, and
+// sessions too, which leaves them unrecorded. Login links point to appURL, the base URL of the
+// web app, and are unavailable without it.
+func NewService(userRepo domain.UserRepository, tokenRepo domain.SecurityTokenRepository, notifier notify.Notifier, monitor *LoginMonitor, sessions *Sessions, appURL string) Service {
 	return &service{
-		userRepo:     userRepo,
-		emailService: emailService,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		notifier:  notifier,
+		monitor:   monitor,
+		sessions:  sessions,
+		appURL:    strings.TrimRight(appURL, "/"),
 	}
 }
 
@@ -39,8 +65,8 @@ func (s *service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 	if req.Email == "" || !strings.Contains(req.Email, "@") {
 		return nil, errors.New("INVALID_EMAIL", "Valid email is required", 400, nil, nil)
 	}
-	if len(req.Password) < 6 {
-		return nil, errors.New("INVALID_PASSWORD", "Password must be at least 6 characters", 400, nil, nil)
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return nil, err
 	}
 
 	existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
@@ -59,6 +85,8 @@ func (s *service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 		Password:  hashedPassword,
 		Role:      domain.UserRole(req.Role),
 		Company:   []primitive.ObjectID{},
+		Locale:    req.Locale,
+		Phone:     req.Phone,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -67,7 +95,7 @@ func (s *service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 		return nil, err
 	}
 
-	token, err := utils.GenerateJWT(user.ID.Hex(), string(user.Role))
+	token, err := utils.GenerateJWT(user.ID.Hex(), string(user.Role), user.OrganizationClaim())
 	if err != nil {
 		return nil, err
 	}
@@ -85,17 +113,64 @@ func (s *service) Login(ctx context.Context, req LoginRequest) (*AuthResponse, e
 	}
 
 	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
+		if s.monitor != nil {
+			s.monitor.RecordFailure(ctx, user, req.Client)
+		}
 		return nil, ErrInvalidCredentials
 	}
 
-	token, err := utils.GenerateJWT(user.ID.Hex(), string(user.Role))
+	return issueToken(ctx, s.monitor, s.sessions, user, req.Client)
+}
+
+func (s *service) Logout(ctx context.Context, token string) error {
+	claims, err := utils.ValidateJWT(ctx, token)
+	if err == utils.ErrAuthUnavailable {
+		return err
+	} else if err != nil {
+		return nil
+	}
+	if err := utils.RevokeJWT(ctx, claims); err != nil {
+		return err
+	}
+	return s.sessions.forget(ctx, claims.ID)
+}
+
+func (s *service) Refresh(ctx context.Context, req RefreshRequest) (*AuthResponse, error) {
+	claims, err := utils.ValidateRefreshJWT(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !user.Active() {
+		return nil, middleware.ErrAccountDisabled
+	}
+	if claims.AuthTime != nil && user.SessionRevoked(claims.AuthTime.Time) {
+		return nil, utils.ErrSessionRevoked
+	}
+
+	// The new token carries the user's current role and organization
+	token, renewed, err := utils.RenewJWT(claims, string(user.Role), user.OrganizationClaim())
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := utils.IssueRefreshJWT(renewed)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResponse{
-		Token: token,
-		User:  ToUserInfo(user),
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    renewed.ExpiresAt.Time,
+		User:         ToUserInfo(user),
 	}, nil
 }
 
@@ -104,6 +179,10 @@ func (s *service) ForgotPassword(ctx context.Context, req ForgotPasswordRequest)
 	if err != nil {
 		return errors.New("USER_NOT_FOUND", "User not found", 404, err, nil)
 	}
+	if !user.Active() {
+		log.Infof(ctx, "Password reset requested for deactivated user %s", user.ID.Hex())
+		return nil
+	}
 
 	newPassword, err := utils.GenerateRandomPassword()
 	if err != nil {
@@ -120,7 +199,7 @@ func (s *service) ForgotPassword(ctx context.Context, req ForgotPasswordRequest)
 		return err
 	}
 
-	if err := s.emailService.SendForgotPasswordEmail(user.Email, user.Name, newPassword); err != nil {
+	if err := s.notifier.SendPassword(ctx, user, newPassword); err != nil {
 		return err
 	}
 
@@ -128,24 +207,71 @@ func (s *service) ForgotPassword(ctx context.Context, req ForgotPasswordRequest)
 }
 
 func (s *service) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
-	user, err := s.userRepo.GetByResetToken(ctx, req.Token)
+	token, err := s.tokenRepo.GetValid(ctx, domain.TokenPasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
 	if err != nil {
 		return err
 	}
+	if err := utils.ValidatePasswordChange(req.NewPassword, user.Password); err != nil {
+		return err
+	}
 
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
 		return err
 	}
 
-	// Clear reset token after successful password change
 	user.Password = hashedPassword
-	user.ResetPasswordToken = nil
-	user.ResetPasswordExpires = nil
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return err
+	}
+
+	// Invalidate every outstanding reset token for the user after a successful change
+	return s.tokenRepo.DeleteByUser(ctx, domain.TokenPasswordReset, user.ID)
+}
+
+func (s *service) RevokeLogin(ctx context.Context, req RevokeLoginRequest) error {
+	token, err := s.tokenRepo.GetValid(ctx, domain.TokenLoginRevoke, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
 
+	// Whoever logged in knows the password, so it is replaced along with the sessions
+	newPassword, err := utils.GenerateRandomPassword()
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.SessionsRevokedAt = &now
 	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
 		return err
 	}
 
-	return nil
+	if err := s.tokenRepo.DeleteByUser(ctx, domain.TokenLoginRevoke, user.ID); err != nil {
+		return err
+	}
+	if err := s.sessions.forgetUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if !user.Active() {
+		return nil
+	}
+
+	return s.notifier.SendPassword(ctx, user, newPassword)
 }
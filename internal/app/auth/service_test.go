@@ -2,14 +2,20 @@ package auth
 
 import (
 	"context"
-	"os"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
 	"testing"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/notify"
 	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
 )
 
 // Mock repository untuk testing
@@ -45,6 +51,16 @@ func (m *mockUserRepository) GetByID(ctx context.Context, id primitive.ObjectID)
 	return nil, ErrUserNotFound
 }
 
+func (m *mockUserRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.User, error) {
+	var result []*domain.User
+	for _, id := range ids {
+		if user, err := m.GetByID(ctx, id); err == nil {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockUserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 	var result []*domain.User
 	for i := range m.users {
@@ -53,6 +69,20 @@ func (m *mockUserRepository) GetAll(ctx context.Context) ([]*domain.User, error)
 	return result, nil
 }
 
+func (m *mockUserRepository) GetPage(ctx context.Context, query domain.UserQuery) ([]*domain.User, int, error) {
+	users, _ := m.GetAll(ctx)
+	return users, len(users), nil
+}
+
+func (m *mockUserRepository) Each(ctx context.Context, fn func(*domain.User) error) error {
+	for i := range m.users {
+		if err := fn(&m.users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *mockUserRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
 	for i := range m.users {
 		if m.users[i].ID == id {
@@ -74,47 +104,168 @@ func (m *mockUserRepository) Delete(ctx context.Context, id primitive.ObjectID)
 	return ErrUserNotFound
 }
 
-func (m *mockUserRepository) SetResetToken(ctx context.Context, email, token string, expires time.Time) error {
-	for i := range m.users {
-		if m.users[i].Email == email {
-			m.users[i].ResetPasswordToken = &token
-			m.users[i].ResetPasswordExpires = &expires
+// Mock security token repository
+type mockTokenRepository struct {
+	tokens []domain.SecurityToken
+	err    error // returned by GetValid when set
+}
+
+func (m *mockTokenRepository) Create(ctx context.Context, token *domain.SecurityToken) error {
+	token.ID = primitive.NewObjectID()
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *mockTokenRepository) GetValid(ctx context.Context, kind domain.TokenKind, token string) (*domain.SecurityToken, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for i := range m.tokens {
+		if m.tokens[i].Kind == kind && m.tokens[i].Token == token && time.Now().Before(m.tokens[i].ExpiresAt) {
+			return &m.tokens[i], nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+func (m *mockTokenRepository) Delete(ctx context.Context, kind domain.TokenKind, token string) error {
+	for i := range m.tokens {
+		if m.tokens[i].Kind == kind && m.tokens[i].Token == token {
+			m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
 			return nil
 		}
 	}
-	return ErrUserNotFound
+	return nil
 }
 
-func (m *mockUserRepository) GetByResetToken(ctx context.Context, token string) (*domain.User, error) {
-	for i := range m.users {
-		if m.users[i].ResetPasswordToken != nil && *m.users[i].ResetPasswordToken == token {
-			if m.users[i].ResetPasswordExpires != nil && time.Now().Before(*m.users[i].ResetPasswordExpires) {
-				return &m.users[i], nil
-			}
+func (m *mockTokenRepository) DeleteByUser(ctx context.Context, kind domain.TokenKind, userID primitive.ObjectID) error {
+	kept := m.tokens[:0]
+	for _, t := range m.tokens {
+		if t.Kind != kind || t.UserID != userID {
+			kept = append(kept, t)
 		}
 	}
-	return nil, ErrUserNotFound
+	m.tokens = kept
+	return nil
 }
 
-// Mock email service
-type mockEmailService struct {
+// Mock notifier
+type mockNotifier struct {
 	lastEmailTo   string
 	lastEmailName string
 	shouldFail    bool
 }
 
-func (m *mockEmailService) SendForgotPasswordEmail(to, name, newPassword string) error {
-	m.lastEmailTo = to
-	m.lastEmailName = name
+func (m *mockNotifier) SendPassword(ctx context.Context, user *domain.User, password string) error {
+	m.lastEmailTo = user.Email
+	m.lastEmailName = user.Name
 	if m.shouldFail {
 		return ErrEmailSendFailed
 	}
 	return nil
 }
 
+func (m *mockNotifier) SendAlert(ctx context.Context, user *domain.User, alert notify.Alert) error {
+	return nil
+}
+
+func (m *mockNotifier) SendLoginLink(ctx context.Context, user *domain.User, link string, expiresIn time.Duration) error {
+	m.lastEmailTo = user.Email
+	m.lastEmailName = user.Name
+	if m.shouldFail {
+		return ErrEmailSendFailed
+	}
+	return nil
+}
+
+func (m *mockNotifier) SendInvitation(ctx context.Context, user *domain.User, inviter, link string, expiresIn time.Duration) error {
+	return nil
+}
+
+// Mock session repository
+type mockSessionRepository struct {
+	sessions []domain.Session
+}
+
+func (m *mockSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	session.ID = primitive.NewObjectID()
+	m.sessions = append(m.sessions, *session)
+	return nil
+}
+
+func (m *mockSessionRepository) GetByUser(ctx context.Context, userID primitive.ObjectID) ([]*domain.Session, error) {
+	var sessions []*domain.Session
+	for i := range m.sessions {
+		if m.sessions[i].UserID == userID {
+			sessions = append(sessions, &m.sessions[i])
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepository) GetByID(ctx context.Context, userID, id primitive.ObjectID) (*domain.Session, error) {
+	for i := range m.sessions {
+		if m.sessions[i].UserID == userID && m.sessions[i].ID == id {
+			return &m.sessions[i], nil
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+func (m *mockSessionRepository) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	return nil
+}
+
+func (m *mockSessionRepository) Delete(ctx context.Context, tokenID string) error {
+	kept := m.sessions[:0]
+	for _, session := range m.sessions {
+		if session.TokenID != tokenID {
+			kept = append(kept, session)
+		}
+	}
+	m.sessions = kept
+	return nil
+}
+
+func (m *mockSessionRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	return nil
+}
+
+type mockLoginRepository struct {
+	logins []*domain.Login
+}
+
+func (m *mockLoginRepository) Create(ctx context.Context, login *domain.Login) error {
+	login.ID = primitive.NewObjectID()
+	m.logins = append([]*domain.Login{login}, m.logins...)
+	return nil
+}
+
+func (m *mockLoginRepository) GetRecent(ctx context.Context, userID primitive.ObjectID, limit int) ([]*domain.Login, error) {
+	var recent []*domain.Login
+	for _, login := range m.logins {
+		if login.UserID == userID && login.Outcome != domain.LoginFailed && len(recent) < limit {
+			recent = append(recent, login)
+		}
+	}
+	return recent, nil
+}
+
+func (m *mockLoginRepository) GetByUser(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]*domain.Login, int, error) {
+	var logins []*domain.Login
+	for _, login := range m.logins {
+		if login.UserID == userID {
+			logins = append(logins, login)
+		}
+	}
+	total := len(logins)
+	logins = logins[min(skip, total):min(skip+limit, total)]
+	return logins, total, nil
+}
+
 // Setup test environment
 func setupTestEnv() {
-	os.Setenv("JWT_SECRET", "test-jwt-secret-key-for-testing")
+	utils.SetJWTSecret("test-jwt-secret-key-for-testing")
 }
 
 // Test functions
@@ -131,7 +282,7 @@ func TestAuthService_Register(t *testing.T) {
 			request: RegisterRequest{
 				Name:     "John Doe",
 				Email:    "john@example.com",
-				Password: "password123",
+				Password: "Ledger2024Balance",
 				Role:     "CLIENT",
 			},
 			expectError: false,
@@ -169,14 +320,25 @@ func TestAuthService_Register(t *testing.T) {
 			expectError: true,
 			errorType:   "VALIDATION_ERROR",
 		},
+		{
+			name: "Common password",
+			request: RegisterRequest{
+				Name:     "John Doe",
+				Email:    "john@example.com",
+				Password: "Password123",
+				Role:     "CLIENT",
+			},
+			expectError: true,
+			errorType:   "PASSWORD_TOO_WEAK",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockRepo := &mockUserRepository{}
-			mockEmail := &mockEmailService{}
-			service := NewService(mockRepo, mockEmail)
+			mockEmail := &mockNotifier{}
+			service := NewService(mockRepo, &mockTokenRepository{}, mockEmail, nil, nil, "")
 
 			// Execute
 			response, err := service.Register(context.Background(), tt.request)
@@ -213,8 +375,8 @@ func TestAuthService_Login(t *testing.T) {
 	setupTestEnv()
 	// Setup
 	mockRepo := &mockUserRepository{}
-	mockEmail := &mockEmailService{}
-	service := NewService(mockRepo, mockEmail)
+	mockEmail := &mockNotifier{}
+	service := NewService(mockRepo, &mockTokenRepository{}, mockEmail, nil, nil, "")
 
 	// Create test user
 	hashedPassword, _ := utils.HashPassword("password123")
@@ -225,7 +387,15 @@ func TestAuthService_Login(t *testing.T) {
 		Password: hashedPassword,
 		Role:     "CLIENT",
 	}
-	mockRepo.users = append(mockRepo.users, testUser)
+	deactivatedAt := time.Now()
+	mockRepo.users = append(mockRepo.users, testUser, domain.User{
+		ID:            primitive.NewObjectID(),
+		Name:          "Deactivated User",
+		Email:         "deactivated@example.com",
+		Password:      hashedPassword,
+		Role:          "CLIENT",
+		DeactivatedAt: &deactivatedAt,
+	})
 
 	tests := []struct {
 		name        string
@@ -264,6 +434,14 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Deactivated user",
+			request: LoginRequest{
+				Email:    "deactivated@example.com",
+				Password: "password123",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -297,6 +475,7 @@ func TestAuthService_ForgotPassword(t *testing.T) {
 		name        string
 		email       string
 		userExists  bool
+		deactivated bool
 		emailFails  bool
 		expectError bool
 	}{
@@ -321,14 +500,21 @@ func TestAuthService_ForgotPassword(t *testing.T) {
 			emailFails:  true,
 			expectError: true,
 		},
+		{
+			name:        "Deactivated user is not emailed",
+			email:       "test@example.com",
+			userExists:  true,
+			deactivated: true,
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockRepo := &mockUserRepository{}
-			mockEmail := &mockEmailService{shouldFail: tt.emailFails}
-			service := NewService(mockRepo, mockEmail)
+			mockEmail := &mockNotifier{shouldFail: tt.emailFails}
+			service := NewService(mockRepo, &mockTokenRepository{}, mockEmail, nil, nil, "")
 
 			if tt.userExists {
 				testUser := domain.User{
@@ -337,6 +523,10 @@ func TestAuthService_ForgotPassword(t *testing.T) {
 					Email: tt.email,
 					Role:  "CLIENT",
 				}
+				if tt.deactivated {
+					now := time.Now()
+					testUser.DeactivatedAt = &now
+				}
 				mockRepo.users = append(mockRepo.users, testUser)
 			}
 
@@ -353,21 +543,383 @@ func TestAuthService_ForgotPassword(t *testing.T) {
 					t.Errorf("Expected no error but got: %v", err)
 				}
 				// Check if email was sent
-				if mockEmail.lastEmailTo == "" {
-					t.Errorf("Expected email to be sent")
+				if sent := mockEmail.lastEmailTo != ""; sent == tt.deactivated {
+					t.Errorf("Expected email sent to be %v, got %v", !tt.deactivated, sent)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	setupTestEnv()
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name        string
+		expiresIn   time.Duration
+		expectError bool
+	}{
+		{name: "Valid token", expiresIn: time.Hour, expectError: false},
+		{name: "Expired token", expiresIn: -time.Minute, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockUserRepository{users: []domain.User{{ID: userID, Email: "reset@example.com", Role: "CLIENT"}}}
+			mockTokens := &mockTokenRepository{}
+			service := NewService(mockRepo, mockTokens, &mockNotifier{}, nil, nil, "")
+
+			mockTokens.Create(context.Background(), &domain.SecurityToken{
+				Kind:      domain.TokenPasswordReset,
+				Token:     "reset-token",
+				UserID:    userID,
+				ExpiresAt: time.Now().Add(tt.expiresIn),
+			})
+
+			err := service.ResetPassword(context.Background(), ResetPasswordRequest{Token: "reset-token", NewPassword: "NewPassword123"})
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if utils.ComparePassword(mockRepo.users[0].Password, "NewPassword123") != nil {
+				t.Errorf("Expected password to be updated")
+			}
+			if len(mockTokens.tokens) != 0 {
+				t.Errorf("Expected reset token to be consumed")
+			}
+		})
+	}
+}
+
+func TestAuthService_PasswordPolicy(t *testing.T) {
+	setupTestEnv()
+	strict := utils.DefaultPasswordPolicy
+	strict.RequireSymbol = true
+	strict.Banned = []string{"Finsolvz2024"}
+
+	tests := []struct {
+		name     string
+		policy   utils.PasswordPolicy
+		password string
+		current  string   // the user's password before the reset
+		problems []string // nil when the password is accepted
+	}{
+		// Length
+		{name: "long enough", policy: utils.DefaultPasswordPolicy, password: "Ledger2024Q", current: "Balance2023Sheet"},
+		{name: "one character short", policy: utils.DefaultPasswordPolicy, password: "Ledger24Q", current: "Balance2023Sheet", problems: []string{"must be at least 10 characters"}},
+		{name: "length in characters, not bytes", policy: utils.DefaultPasswordPolicy, password: "Ünïcödé1ä", current: "Balance2023Sheet", problems: []string{"must be at least 10 characters"}},
+		{name: "longer than bcrypt hashes", policy: utils.DefaultPasswordPolicy, password: "Ledger2024" + strings.Repeat("q", 63), current: "Balance2023Sheet", problems: []string{"must be at most 72 bytes"}},
+
+		// Character classes
+		{name: "no uppercase letter", policy: utils.DefaultPasswordPolicy, password: "ledger2024q", current: "Balance2023Sheet", problems: []string{"must contain an uppercase letter"}},
+		{name: "no lowercase letter", policy: utils.DefaultPasswordPolicy, password: "LEDGER2024Q", current: "Balance2023Sheet", problems: []string{"must contain a lowercase letter"}},
+		{name: "no digit", policy: utils.DefaultPasswordPolicy, password: "LedgerBalance", current: "Balance2023Sheet", problems: []string{"must contain a digit"}},
+		{name: "no symbol when required", policy: strict, password: "Ledger2024Q", current: "Balance2023Sheet", problems: []string{"must contain a symbol"}},
+		{name: "symbol when required", policy: strict, password: "Ledger 2024-Q", current: "Balance2023Sheet"},
+		{name: "every rule broken at once", policy: strict, password: "abc", current: "Balance2023Sheet", problems: []string{"must be at least 10 characters", "must contain an uppercase letter", "must contain a digit", "must contain a symbol"}},
+
+		// Breached and banned
+		{name: "breached password", policy: utils.DefaultPasswordPolicy, password: "Welcome123!", current: "Balance2023Sheet", problems: []string{"is too common"}},
+		{name: "breached password in another case", policy: utils.DefaultPasswordPolicy, password: "SUMMER2024", current: "Balance2023Sheet", problems: []string{"must contain a lowercase letter", "is too common"}},
+		{name: "breached password allowed when not rejected", policy: utils.PasswordPolicy{MinLength: 10}, password: "Welcome123!", current: "Balance2023Sheet"},
+		{name: "banned password", policy: strict, password: "finsolvz2024", current: "Balance2023Sheet", problems: []string{"must contain an uppercase letter", "must contain a symbol", "is not allowed"}},
+
+		// Reuse
+		{name: "current password", policy: utils.DefaultPasswordPolicy, password: "Balance2023Sheet", current: "Balance2023Sheet", problems: []string{"must differ from the current password"}},
+		{name: "current password in another case", policy: utils.DefaultPasswordPolicy, password: "balance2023sheeT", current: "Balance2023Sheet"},
+		{name: "no current password", policy: utils.DefaultPasswordPolicy, password: "Balance2023Sheet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utils.SetPasswordPolicy(tt.policy)
+			t.Cleanup(func() { utils.SetPasswordPolicy(utils.DefaultPasswordPolicy) })
+
+			user := domain.User{ID: primitive.NewObjectID(), Email: "policy@example.com", Role: "CLIENT"}
+			if tt.current != "" {
+				hashed, err := utils.HashPassword(tt.current)
+				if err != nil {
+					t.Fatalf("Failed to hash the current password: %v", err)
+				}
+				user.Password = hashed
+			}
+			mockRepo := &mockUserRepository{users: []domain.User{user}}
+			mockTokens := &mockTokenRepository{}
+			service := NewService(mockRepo, mockTokens, &mockNotifier{}, nil, nil, "")
+			mockTokens.Create(context.Background(), &domain.SecurityToken{
+				Kind:      domain.TokenPasswordReset,
+				Token:     "reset-token",
+				UserID:    user.ID,
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+
+			err := service.ResetPassword(context.Background(), ResetPasswordRequest{Token: "reset-token", NewPassword: tt.password})
+
+			if tt.problems == nil {
+				if err != nil {
+					t.Fatalf("Expected the password to be accepted, got %v", err)
 				}
+				if utils.ComparePassword(mockRepo.users[0].Password, tt.password) != nil {
+					t.Fatalf("Expected the password to be updated")
+				}
+				return
+			}
+
+			appErr, ok := err.(errors.AppError)
+			if !ok || appErr.Code() != "PASSWORD_TOO_WEAK" || appErr.Status() != 400 {
+				t.Fatalf("Expected PASSWORD_TOO_WEAK, got %v", err)
+			}
+			problems, _ := appErr.Details()["problems"].([]string)
+			if strings.Join(problems, "; ") != strings.Join(tt.problems, "; ") {
+				t.Fatalf("Expected problems %q, got %q", tt.problems, problems)
+			}
+			if mockRepo.users[0].Password != user.Password {
+				t.Fatalf("Expected the password to be kept")
+			}
+			if len(mockTokens.tokens) != 1 {
+				t.Fatalf("Expected the reset token to be kept for another try")
 			}
 		})
 	}
 }
 
+func TestAuthService_MagicLink(t *testing.T) {
+	setupTestEnv()
+	userID := primitive.NewObjectID()
+	mockRepo := &mockUserRepository{users: []domain.User{{ID: userID, Name: "Client", Email: "client@example.com", Role: "CLIENT"}}}
+	mockTokens := &mockTokenRepository{}
+	mockEmail := &mockNotifier{}
+	service := NewService(mockRepo, mockTokens, mockEmail, nil, nil, "https://app.example.com/")
+
+	// Unknown emails get the same answer, without an email
+	if err := service.RequestMagicLink(context.Background(), MagicLinkRequest{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("Expected no error for unknown email but got: %v", err)
+	}
+	if mockEmail.lastEmailTo != "" || len(mockTokens.tokens) != 0 {
+		t.Fatalf("Expected no link for unknown email")
+	}
+
+	if err := service.RequestMagicLink(context.Background(), MagicLinkRequest{Email: "client@example.com"}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if mockEmail.lastEmailTo != "client@example.com" || len(mockTokens.tokens) != 1 {
+		t.Fatalf("Expected a link to be sent to the user")
+	}
+	token := mockTokens.tokens[0].Token
+
+	response, err := service.MagicLogin(context.Background(), MagicLoginRequest{Token: token})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if response.Token == "" || response.User.ID != userID.Hex() {
+		t.Errorf("Expected a token for the user")
+	}
+
+	// Links work once
+	if _, err := service.MagicLogin(context.Background(), MagicLoginRequest{Token: token}); err != ErrMagicLinkInvalid {
+		t.Errorf("Expected MAGIC_LINK_INVALID on reuse but got: %v", err)
+	}
+}
+
+func TestAuthService_LoginHistory(t *testing.T) {
+	setupTestEnv()
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := domain.User{ID: primitive.NewObjectID(), Email: "test@example.com", Password: hashedPassword, Role: "CLIENT"}
+	logins := &mockLoginRepository{}
+	monitor := NewLoginMonitor(logins, nil, &mockTokenRepository{}, &mockNotifier{}, "")
+	service := NewService(&mockUserRepository{users: []domain.User{user}}, &mockTokenRepository{}, &mockNotifier{}, monitor, nil, "")
+	client := Client{IP: "203.0.113.7", UserAgent: "Firefox"}
+
+	if _, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "wrong", Client: client}); err != ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials but got: %v", err)
+	}
+	// A failed login is not a previous login to compare with, so this one is not suspicious
+	if _, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "password123", Client: client}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	history, total, err := monitor.History(context.Background(), user.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if total != 2 || history[0].Outcome != domain.LoginSucceeded || history[1].Outcome != domain.LoginFailed {
+		t.Fatalf("Expected a success after a failure, got %d logins: %+v", total, history)
+	}
+	if len(history[0].Suspicious) != 0 || history[1].IP != "203.0.113.7" {
+		t.Errorf("Unexpected logins: %+v %+v", history[0], history[1])
+	}
+}
+
+func TestAuthService_RevocationLookupFailure(t *testing.T) {
+	setupTestEnv()
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := domain.User{ID: primitive.NewObjectID(), Email: "test@example.com", Password: hashedPassword, Role: "CLIENT"}
+	tokens := &mockTokenRepository{}
+	utils.SetTokenDenylist(NewDenylist(tokens, utils.NewMemoryCache()))
+	defer utils.SetTokenDenylist(nil)
+	service := NewService(&mockUserRepository{users: []domain.User{user}}, tokens, &mockNotifier{}, nil, nil, "")
+
+	response, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	// A token that can't be checked against the denylist is rejected, not let through
+	tokens.err = errors.New("DATABASE_ERROR", "Failed to get token", 500, nil, nil)
+	if _, err := utils.ValidateJWT(context.Background(), response.Token); err != utils.ErrAuthUnavailable {
+		t.Fatalf("Expected %v, got %v", utils.ErrAuthUnavailable, err)
+	}
+	// and logging out with it fails rather than leave it unrevoked
+	if err := service.Logout(context.Background(), response.Token); err != utils.ErrAuthUnavailable {
+		t.Fatalf("Expected %v, got %v", utils.ErrAuthUnavailable, err)
+	}
+
+	tokens.err = nil
+	if _, err := utils.ValidateJWT(context.Background(), response.Token); err != nil {
+		t.Fatalf("Expected the token to be valid once the lookup works, got %v", err)
+	}
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	setupTestEnv()
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := domain.User{ID: primitive.NewObjectID(), Email: "test@example.com", Password: hashedPassword, Role: "CLIENT"}
+	mockSessions := &mockSessionRepository{}
+	sessions := NewSessions(mockSessions, utils.NewMemoryCache())
+	service := NewService(&mockUserRepository{users: []domain.User{user}}, &mockTokenRepository{}, &mockNotifier{}, nil, sessions, "")
+
+	response, err := service.Login(context.Background(), LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+		Client:   Client{IP: "203.0.113.7", UserAgent: "Firefox"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	claims, err := utils.ValidateJWT(context.Background(), response.Token)
+	if err != nil {
+		t.Fatalf("Expected a valid token but got: %v", err)
+	}
+
+	listed, err := sessions.List(context.Background(), user.ID.Hex(), claims.ID)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(listed) != 1 || listed[0].IP != "203.0.113.7" || listed[0].UserAgent != "Firefox" || !listed[0].Current {
+		t.Fatalf("Expected the login to be the current session, got %+v", listed)
+	}
+
+	// Logging out forgets the session
+	if err := service.Logout(context.Background(), response.Token); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(mockSessions.sessions) != 0 {
+		t.Errorf("Expected no session after logout, got %d", len(mockSessions.sessions))
+	}
+}
+
+func TestAuthService_KeyRotation(t *testing.T) {
+	setupTestEnv()
+	defer setupTestEnv()
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo := &mockUserRepository{users: []domain.User{{ID: primitive.NewObjectID(), Email: "test@example.com", Password: hashedPassword, Role: "CLIENT"}}}
+	service := NewService(mockRepo, &mockTokenRepository{}, &mockNotifier{}, nil, nil, "")
+	login := func() string {
+		response, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "password123"})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		return response.Token
+	}
+	oldToken := login()
+
+	// Tokens of the previous secret stay valid after it is rotated
+	rotated := utils.JWTConfig{Secret: "rotated-jwt-secret-key-for-testing", PreviousSecrets: []string{"test-jwt-secret-key-for-testing"}}
+	if err := utils.SetJWTConfig(rotated); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if _, err := utils.ValidateJWT(context.Background(), oldToken); err != nil {
+		t.Errorf("Expected the token of the previous secret to be valid but got: %v", err)
+	}
+
+	// And so do those of the secret once tokens are signed with RS256
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	rotated.Algorithm = utils.JWTAlgorithmRS256
+	rotated.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+	if err := utils.SetJWTConfig(rotated); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	newToken := login()
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := utils.ValidateJWT(context.Background(), token); err != nil {
+			t.Errorf("Expected a valid token but got: %v", err)
+		}
+	}
+	if jwks := utils.PublicJWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].Algorithm != utils.JWTAlgorithmRS256 {
+		t.Errorf("Expected the RS256 key to be published, got %+v", jwks)
+	}
+
+	// Dropping a secret rejects its tokens
+	utils.SetJWTSecret("rotated-jwt-secret-key-for-testing")
+	if _, err := utils.ValidateJWT(context.Background(), oldToken); err == nil {
+		t.Errorf("Expected the token of a dropped secret to be rejected")
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	setupTestEnv()
+	utils.SetTokenLifetimes(utils.TokenLifetimes{Access: 15 * time.Minute, Refresh: 24 * time.Hour, MaxAge: 30 * 24 * time.Hour})
+	defer utils.SetTokenLifetimes(utils.DefaultTokenLifetimes)
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo := &mockUserRepository{users: []domain.User{{ID: primitive.NewObjectID(), Email: "test@example.com", Password: hashedPassword, Role: "CLIENT"}}}
+	service := NewService(mockRepo, &mockTokenRepository{}, &mockNotifier{}, nil, nil, "")
+
+	login, err := service.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if login.RefreshToken == "" {
+		t.Fatalf("Expected a refresh token")
+	}
+
+	// Refresh tokens are not accepted as access tokens, nor the other way around
+	if _, err := utils.ValidateJWT(context.Background(), login.RefreshToken); err == nil {
+		t.Errorf("Expected the refresh token to be rejected as an access token")
+	}
+	if _, err := service.Refresh(context.Background(), RefreshRequest{RefreshToken: login.Token}); err == nil {
+		t.Errorf("Expected refreshing with an access token to fail")
+	}
+
+	refreshed, err := service.Refresh(context.Background(), RefreshRequest{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	claims, err := utils.ValidateJWT(context.Background(), refreshed.Token)
+	if err != nil {
+		t.Fatalf("Expected a valid token but got: %v", err)
+	}
+	if claims.Role != "CLIENT" || claims.AuthTime == nil {
+		t.Errorf("Expected the token of the login, got %+v", claims)
+	}
+}
+
 // Performance test
 func TestAuthService_LoginPerformance(t *testing.T) {
 	setupTestEnv()
 	// Setup
 	mockRepo := &mockUserRepository{}
-	mockEmail := &mockEmailService{}
-	service := NewService(mockRepo, mockEmail)
+	mockEmail := &mockNotifier{}
+	service := NewService(mockRepo, &mockTokenRepository{}, mockEmail, nil, nil, "")
 
 	// Create test user
 	hashedPassword, _ := utils.HashPassword("password123")
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// sessionTouchInterval is how often the last use of a session is written at most.
+const sessionTouchInterval = 5 * time.Minute
+
+// Sessions records the tokens issued at login with the device they were issued to, so users
+// can see where they are signed in and sign devices out.
+type Sessions struct {
+	repo  domain.SessionRepository
+	cache utils.Cache
+}
+
+// NewSessions creates the session store. cache throttles the writes of when sessions were
+// last seen.
+func NewSessions(repo domain.SessionRepository, cache utils.Cache) *Sessions {
+	return &Sessions{repo: repo, cache: cache}
+}
+
+// issueToken logs user in with a token, and a refresh token when they are enabled, recorded
+// with monitor and as a session of client unless they are nil. Deactivated users are turned
+// away first. Failures to record the login are logged rather than returned, so they never
+// block it.
+func issueToken(ctx context.Context, monitor *LoginMonitor, sessions *Sessions, user *domain.User, client Client) (*AuthResponse, error) {
+	if !user.Active() {
+		return nil, middleware.ErrAccountDisabled
+	}
+	if monitor != nil {
+		monitor.Record(ctx, user, client)
+	}
+
+	token, claims, err := utils.IssueJWT(user.ID.Hex(), string(user.Role), user.OrganizationClaim())
+	if err != nil {
+		return nil, err
+	}
+	response := &AuthResponse{Token: token, ExpiresAt: claims.ExpiresAt.Time, User: ToUserInfo(user)}
+	if utils.RefreshTokensEnabled() {
+		if response.RefreshToken, err = utils.IssueRefreshJWT(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if sessions != nil {
+		session := &domain.Session{
+			UserID:    user.ID,
+			TokenID:   claims.ID,
+			IP:        client.IP,
+			Country:   client.Country,
+			UserAgent: client.UserAgent,
+			ExpiresAt: utils.SessionExpiry(claims),
+		}
+		if err := sessions.repo.Create(ctx, session); err != nil {
+			log.Warnf(ctx, "Failed to record session of user %s: %v", user.ID.Hex(), err)
+		}
+	}
+	return response, nil
+}
+
+func sessionSeenKey(tokenID string) string {
+	return "session_seen:" + tokenID
+}
+
+// Touch records that the token of claims was just used, at most every sessionTouchInterval.
+// Failures are logged rather than returned, as the token stays valid regardless.
+func (s *Sessions) Touch(ctx context.Context, claims *utils.Claims) {
+	if claims.ID == "" {
+		return
+	}
+	var seen bool
+	if s.cache.Get(sessionSeenKey(claims.ID), &seen) {
+		return
+	}
+	s.cache.Set(sessionSeenKey(claims.ID), true, sessionTouchInterval)
+
+	if err := s.repo.Touch(ctx, claims.ID, time.Now()); err != nil {
+		log.Warnf(ctx, "Failed to update last use of session of user %s: %v", claims.UserID, err)
+	}
+}
+
+// List returns the active sessions of the user, marking the one of currentTokenID, the
+// token of the request.
+func (s *Sessions) List(ctx context.Context, userID, currentTokenID string) ([]*SessionResponse, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	sessions, err := s.repo.GetByUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = ToSessionResponse(session, currentTokenID)
+	}
+	return responses, nil
+}
+
+// Revoke signs a session of the user out: its token is rejected from now on.
+func (s *Sessions) Revoke(ctx context.Context, userID, id string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return utils.ErrUnauthorized
+	}
+	sessionID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrSessionNotFound
+	}
+
+	session, err := s.repo.GetByID(ctx, uid, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.RevokeJWT(ctx, &utils.Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        session.TokenID,
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+		},
+	}); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, session.TokenID)
+}
+
+// forget removes the session of a token that was revoked otherwise, e.g. by logging out.
+func (s *Sessions) forget(ctx context.Context, tokenID string) error {
+	if s == nil || tokenID == "" {
+		return nil
+	}
+	return s.repo.Delete(ctx, tokenID)
+}
+
+// forgetUser removes every session of a user whose sessions were all revoked.
+func (s *Sessions) forgetUser(ctx context.Context, userID primitive.ObjectID) error {
+	if s == nil {
+		return nil
+	}
+	return s.repo.DeleteByUser(ctx, userID)
+}
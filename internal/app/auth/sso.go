@@ -0,0 +1,464 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/oauth"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// ssoCallbackPath is the page of the web app identity providers redirect users back to.
+const ssoCallbackPath = "/login/sso/callback"
+
+// SSOService signs the users of a company in with the company's own identity provider through
+// OpenID Connect. Connections are set up per company and trusted for the email domains they
+// list: users of those domains that belong to the company are linked on their first sign-in,
+// and unknown ones are created when the connection provisions them.
+type SSOService interface {
+	GetConnection(ctx context.Context, companyID string) (*domain.SSOConnection, error)
+	SaveConnection(ctx context.Context, companyID string, req SSOConnectionRequest) (*domain.SSOConnection, error)
+	DeleteConnection(ctx context.Context, companyID string) error
+	// Metadata is what the company's identity provider needs to register the app as a client.
+	Metadata(ctx context.Context, companyID string) (*SSOMetadata, error)
+	// Start begins a sign-in with the identity provider of the email's domain.
+	Start(ctx context.Context, req SSOStartRequest) (*OAuthStartResponse, error)
+	Login(ctx context.Context, req OAuthLoginRequest) (*AuthResponse, error)
+}
+
+type ssoService struct {
+	connectionRepo domain.SSOConnectionRepository
+	companyRepo    domain.CompanyRepository
+	userRepo       domain.UserRepository
+	tokenRepo      domain.SecurityTokenRepository
+	monitor        *LoginMonitor
+	sessions       *Sessions
+	redirectURL    string
+}
+
+// NewSSOService creates the single sign-on service. Identity providers redirect users back to
+// a page under appURL, the base URL of the web app. monitor may be nil, which leaves logins
+// unchecked, and sessions too, which leaves them unrecorded.
+func NewSSOService(connectionRepo domain.SSOConnectionRepository, companyRepo domain.CompanyRepository, userRepo domain.UserRepository, tokenRepo domain.SecurityTokenRepository, monitor *LoginMonitor, sessions *Sessions, appURL string) SSOService {
+	return &ssoService{
+		connectionRepo: connectionRepo,
+		companyRepo:    companyRepo,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		monitor:        monitor,
+		sessions:       sessions,
+		redirectURL:    strings.TrimRight(appURL, "/") + ssoCallbackPath,
+	}
+}
+
+func (s *ssoService) GetConnection(ctx context.Context, companyID string) (*domain.SSOConnection, error) {
+	id, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return nil, errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+	return s.connectionRepo.Get(ctx, id)
+}
+
+func (s *ssoService) SaveConnection(ctx context.Context, companyID string, req SSOConnectionRequest) (*domain.SSOConnection, error) {
+	id, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return nil, errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+	if _, err := s.companyRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	issuer := strings.TrimRight(req.Issuer, "/")
+	if u, err := url.Parse(issuer); err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, ErrSSOIssuerInvalid
+	}
+	domains := make([]string, 0, len(req.Domains))
+	for _, d := range req.Domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || strings.ContainsAny(d, "@/ ") || !strings.Contains(d, ".") {
+			return nil, errors.New("INVALID_SSO_DOMAIN", "Domains must be email domains such as acme.com", 400, nil, map[string]interface{}{"domain": d})
+		}
+		if !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+
+	connection, err := s.connectionRepo.Get(ctx, id)
+	if err != nil {
+		if !hasCode(err, "SSO_CONNECTION_NOT_FOUND") {
+			return nil, err
+		}
+		connection = &domain.SSOConnection{CompanyID: id}
+	}
+	// The secret is write-only, so updates may leave it out to keep the current one
+	if req.ClientSecret != "" {
+		connection.ClientSecret = req.ClientSecret
+	}
+	if connection.ClientSecret == "" {
+		return nil, errors.New("SSO_CLIENT_SECRET_REQUIRED", "Client secret is required", 400, nil, nil)
+	}
+
+	// Only connections to a working provider are saved
+	if _, err := oauth.Discover(ctx, issuer); err != nil {
+		return nil, err
+	}
+
+	connection.Issuer = issuer
+	connection.ClientID = req.ClientID
+	connection.Domains = domains
+	connection.JITProvisioning = req.JITProvisioning
+	connection.DefaultRole = domain.UserRole(req.DefaultRole)
+	if connection.DefaultRole == "" {
+		connection.DefaultRole = domain.RoleClient
+	}
+	connection.Enabled = req.Enabled == nil || *req.Enabled
+
+	if err := s.connectionRepo.Save(ctx, connection); err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
+
+func (s *ssoService) DeleteConnection(ctx context.Context, companyID string) error {
+	id, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+	return s.connectionRepo.Delete(ctx, id)
+}
+
+func (s *ssoService) Metadata(ctx context.Context, companyID string) (*SSOMetadata, error) {
+	id, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return nil, errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+	if _, err := s.companyRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return &SSOMetadata{
+		Protocol:                "openid-connect",
+		RedirectURIs:            []string{s.redirectURL},
+		ResponseTypes:           []string{"code"},
+		GrantTypes:              []string{"authorization_code"},
+		Scopes:                  []string{"openid", "email", "profile"},
+		TokenEndpointAuthMethod: "client_secret_post",
+		RequiredClaims:          []string{"sub", "email"},
+	}, nil
+}
+
+func (s *ssoService) Start(ctx context.Context, req SSOStartRequest) (*OAuthStartResponse, error) {
+	connection, err := s.connectionRepo.GetByDomain(ctx, emailDomain(req.Email))
+	if err != nil {
+		return nil, err
+	}
+	if !connection.Enabled {
+		return nil, ErrSSODisabled
+	}
+
+	provider, err := s.provider(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, errors.New("RANDOM_GENERATION_ERROR", "Failed to generate sign-in state", 500, err, nil)
+	}
+	// The company travels in the state, so the callback knows which provider to ask
+	state := connection.CompanyID.Hex() + "." + hex.EncodeToString(bytes)
+
+	expiresAt := time.Now().Add(oauthStateTTL)
+	if err := s.tokenRepo.Create(ctx, &domain.SecurityToken{
+		Kind:      domain.TokenSSOState,
+		Token:     state,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OAuthStartResponse{
+		AuthorizationURL: provider.AuthCodeURL(state),
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+func (s *ssoService) Login(ctx context.Context, req OAuthLoginRequest) (*AuthResponse, error) {
+	if _, err := s.tokenRepo.GetValid(ctx, domain.TokenSSOState, req.State); err != nil {
+		if hasCode(err, "INVALID_TOKEN") {
+			return nil, ErrOAuthStateInvalid
+		}
+		return nil, err
+	}
+	if err := s.tokenRepo.Delete(ctx, domain.TokenSSOState, req.State); err != nil {
+		return nil, err
+	}
+
+	companyHex, _, _ := strings.Cut(req.State, ".")
+	companyID, err := primitive.ObjectIDFromHex(companyHex)
+	if err != nil {
+		return nil, ErrOAuthStateInvalid
+	}
+	connection, err := s.connectionRepo.Get(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if !connection.Enabled {
+		return nil, ErrSSODisabled
+	}
+
+	provider, err := s.provider(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := provider.Exchange(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider is trusted for the emails of its domains only, verified or not: enterprise
+	// providers often leave out email_verified for the accounts they manage
+	if identity.Email == "" || !slices.Contains(connection.Domains, emailDomain(identity.Email)) {
+		return nil, ErrSSODomainMismatch
+	}
+
+	user, err := s.linkedUser(ctx, connection, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return issueToken(ctx, s.monitor, s.sessions, user, req.Client)
+}
+
+// linkedUser returns the user identity signs in as, linking users of the company on their
+// first sign-in and creating unknown ones when the connection provisions them.
+func (s *ssoService) linkedUser(ctx context.Context, connection *domain.SSOConnection, identity *oauth.Identity) (*domain.User, error) {
+	user, err := s.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		if !hasCode(err, "USER_NOT_FOUND") {
+			return nil, err
+		}
+		if !connection.JITProvisioning {
+			return nil, ErrOAuthAccountNotFound
+		}
+		return s.provision(ctx, connection, identity)
+	}
+
+	if linked := user.Identity(identity.Provider); linked != nil {
+		if linked.Subject != identity.Subject {
+			return nil, ErrOAuthAccountMismatch
+		}
+		return user, nil
+	}
+
+	// Users outside the company, e.g. super admins, never sign in through its provider
+	if !slices.Contains(user.Company, connection.CompanyID) {
+		return nil, ErrSSOUserNotInCompany
+	}
+
+	user.Identities = append(user.Identities, domain.ExternalIdentity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: time.Now(),
+	})
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *ssoService) provision(ctx context.Context, connection *domain.SSOConnection, identity *oauth.Identity) (*domain.User, error) {
+	company, err := s.companyRepo.GetByID(ctx, connection.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Provisioned users sign in through the provider; nobody knows this password
+	password, err := utils.GenerateRandomPassword()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	now := time.Now()
+	user := &domain.User{
+		Name:         name,
+		Email:        identity.Email,
+		Password:     hashedPassword,
+		Role:         connection.DefaultRole,
+		Company:      []primitive.ObjectID{company.ID},
+		Organization: company.Organization,
+		Identities: []domain.ExternalIdentity{{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			LinkedAt: now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	company.User = append(company.User, user.ID)
+	if err := s.companyRepo.Update(ctx, company.ID, company); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *ssoService) provider(ctx context.Context, connection *domain.SSOConnection) (oauth.Provider, error) {
+	return oauth.NewOIDC(ctx, oauth.OIDCConfig{
+		Name:         ssoProviderName(connection.CompanyID),
+		Issuer:       connection.Issuer,
+		ClientID:     connection.ClientID,
+		ClientSecret: connection.ClientSecret,
+		RedirectURL:  s.redirectURL,
+	})
+}
+
+// ssoProviderName names the identities of a company's provider, so an account there is never
+// mistaken for one of another company's provider.
+func ssoProviderName(companyID primitive.ObjectID) string {
+	return "sso:" + companyID.Hex()
+}
+
+func emailDomain(email string) string {
+	_, domain, _ := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	return domain
+}
+
+// @Summary Get the SSO connection of a company
+func (h *Handler) GetSSOConnection(w http.ResponseWriter, r *http.Request) {
+	connection, err := h.sso.GetConnection(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, connection)
+}
+
+// @Summary Set up SSO for a company
+// @Description Creates or replaces the company's OpenID Connect connection. The issuer's discovery
+// @Description document must be reachable. The client secret is never returned; leave it out to
+// @Description keep the current one.
+func (h *Handler) SaveSSOConnection(w http.ResponseWriter, r *http.Request) {
+	var req SSOConnectionRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	connection, err := h.sso.SaveConnection(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, connection)
+}
+
+// @Summary Remove the SSO connection of a company
+// @Description Users already linked keep their accounts and log in with a password or login link.
+func (h *Handler) DeleteSSOConnection(w http.ResponseWriter, r *http.Request) {
+	if err := h.sso.DeleteConnection(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get SSO client metadata
+// @Description What to register the app with at the company's identity provider: its redirect
+// @Description URI, flows and scopes.
+func (h *Handler) GetSSOMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, err := h.sso.Metadata(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, metadata)
+}
+
+// @Summary Start signing in with SSO
+// @Description Finds the identity provider of the email's domain and returns the page to send
+// @Description the user to, like GET /api/login/google. The provider redirects back to
+// @Description APP_URL/login/sso/callback, which posts the code and state to POST /api/login/sso.
+func (h *Handler) StartSSOLogin(w http.ResponseWriter, r *http.Request) {
+	var req SSOStartRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	response, err := h.sso.Start(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, response)
+}
+
+// @Summary Sign in with SSO
+// @Description Logs in with the code the company's identity provider redirected the user back
+// @Description with, like POST /api/login.
+func (h *Handler) LoginWithSSO(w http.ResponseWriter, r *http.Request) {
+	var req OAuthLoginRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+	req.Client = clientFromRequest(r)
+	if req.SessionCookie && !utils.CookieAuthEnabled() {
+		utils.HandleHTTPError(w, utils.ErrCookieAuthDisabled, r)
+		return
+	}
+
+	response, err := h.sso.Login(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	respondLogin(w, response, req.SessionCookie)
+}
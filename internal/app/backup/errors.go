@@ -0,0 +1,12 @@
+package backup
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidCollection = errors.New("INVALID_COLLECTION", "Collection cannot be backed up", http.StatusBadRequest, nil, nil)
+	ErrBackupFailed      = errors.New("BACKUP_FAILED", "Failed to create backup", http.StatusInternalServerError, nil, nil)
+	ErrInvalidBackupKey  = errors.New("INVALID_BACKUP_KEY", "Key does not name a backup", http.StatusBadRequest, nil, nil)
+)
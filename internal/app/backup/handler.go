@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/tasks"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	tasks     tasks.Enqueuer
+	validator *validator.Validate
+}
+
+func NewHandler(service Service, taskQueue tasks.Enqueuer) *Handler {
+	return &Handler{
+		service:   service,
+		tasks:     taskQueue,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers backup routes
+// @Tags Administration
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "backup"))
+
+	adminOnly.HandleFunc("/api/admin/backup", h.CreateBackup).Methods("POST")
+	adminOnly.HandleFunc("/api/admin/backup/download", h.GetDownloadLink).Methods("GET")
+}
+
+// CreateBackup dumps the selected collections (all when omitted) to the object store.
+// With ?async=true it returns 202 and a task to poll at /api/tasks/{id} instead.
+func (h *Handler) CreateBackup(w http.ResponseWriter, r *http.Request) {
+	var req CreateBackupRequest
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	if r.URL.Query().Get("async") == "true" {
+		h.createBackupTask(w, r, req)
+		return
+	}
+
+	backup, err := h.service.CreateBackup(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "Backup created successfully",
+		"backup":  backup,
+	})
+}
+
+// GetDownloadLink issues a short-lived signed link to the backup named by ?key=
+// @Param key query string true "Object store key of the backup, e.g. backups/finsolvz-20240101T000000Z.jsonl.gz"
+func (h *Handler) GetDownloadLink(w http.ResponseWriter, r *http.Request) {
+	link, err := h.service.DownloadLink(r.Context(), r.URL.Query().Get("key"), requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, link)
+}
+
+func (h *Handler) createBackupTask(w http.ResponseWriter, r *http.Request, req CreateBackupRequest) {
+	task, err := h.tasks.Enqueue(r.Context(), TaskCreateBackup, req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message":   "Backup started",
+		"taskId":    task.ID.Hex(),
+		"status":    task.Status,
+		"statusUrl": "/api/tasks/" + task.ID.Hex(),
+	})
+}
+
+// requester is the ID of the user making the request, recorded with tasks and download links.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
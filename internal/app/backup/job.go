@@ -0,0 +1,46 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// checkInterval caps how often the job checks whether a backup is due. Every instance runs
+// the job, and the first to find one due takes it.
+const checkInterval = 5 * time.Minute
+
+// Job takes scheduled backups and rotates the old ones.
+type Job struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewJob(service Service, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run checks at start, taking a backup missed while stopped, and then until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(j.interval, checkInterval))
+	defer ticker.Stop()
+
+	for {
+		backup, err := j.service.RunSchedule(ctx)
+		if err != nil {
+			log.Errorf(ctx, "Backup: scheduled backup failed: %v", err)
+		} else if backup != nil {
+			log.Infof(ctx, "Backup: wrote %s (%d bytes)", backup.Key, backup.Size)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package backup
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateBackupRequest struct {
+	Collections []string `json:"collections,omitempty" validate:"omitempty,dive,oneof=users companies reports reporttypes"`
+}
+
+// Response DTOs
+type BackupResponse struct {
+	Key         string         `json:"key"`
+	Collections map[string]int `json:"collections"` // document count per collection
+	Size        int64          `json:"size"`        // compressed size in bytes
+	DownloadURL string         `json:"downloadUrl,omitempty"`
+	// DownloadExpiresAt is when DownloadURL stops working; GET /api/admin/backup/download issues a new one
+	DownloadExpiresAt *time.Time `json:"downloadExpiresAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+type RestoreResponse struct {
+	Key         string         `json:"key"`
+	Collections map[string]int `json:"collections"`
+}
+
+// ScheduleStatus reports the scheduled backups, for the system status.
+type ScheduleStatus struct {
+	Enabled    bool                  `json:"enabled"`
+	Interval   string                `json:"interval,omitempty"`
+	Retention  domain.BackupSchedule `json:"retention"`
+	LastBackup *domain.Backup        `json:"lastBackup,omitempty"`
+	// LastFailure is the newest failed backup, when none succeeded since
+	LastFailure  *domain.Backup `json:"lastFailure,omitempty"`
+	NextBackupAt *time.Time     `json:"nextBackupAt,omitempty"`
+	Retained     int            `json:"retained"`     // scheduled backups in the object store
+	RetainedSize int64          `json:"retainedSize"` // their compressed size in bytes
+	Error        string         `json:"error,omitempty"`
+}
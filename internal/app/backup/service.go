@@ -0,0 +1,281 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	// keyPrefix is where backups are stored; download links are only issued below it.
+	keyPrefix = "backups/"
+	// scheduledPrefix keeps scheduled backups apart from those requested by hand.
+	scheduledPrefix = keyPrefix + "scheduled/"
+)
+
+type Service interface {
+	// CreateBackup writes a backup; requestedBy is recorded with its download link.
+	CreateBackup(ctx context.Context, req CreateBackupRequest, requestedBy primitive.ObjectID) (*BackupResponse, error)
+	RestoreBackup(ctx context.Context, key string) (*RestoreResponse, error)
+	DownloadLink(ctx context.Context, key string, requestedBy primitive.ObjectID) (*storage.Link, error)
+
+	// RunSchedule takes a scheduled backup of every collection when the last one is older
+	// than the schedule's interval, then deletes those past its retention. It returns nil
+	// when no backup was due.
+	RunSchedule(ctx context.Context) (*domain.Backup, error)
+	ScheduleStatus(ctx context.Context) (*ScheduleStatus, error)
+}
+
+type service struct {
+	backupRepo domain.BackupRepository
+	store      storage.ObjectStore
+	downloads  *storage.Downloads
+	schedule   domain.BackupSchedule
+}
+
+func NewService(backupRepo domain.BackupRepository, store storage.ObjectStore, downloads *storage.Downloads, schedule domain.BackupSchedule) Service {
+	return &service{
+		backupRepo: backupRepo,
+		store:      store,
+		downloads:  downloads,
+		schedule:   schedule,
+	}
+}
+
+type exportResult struct {
+	counts map[string]int
+	err    error
+}
+
+func (s *service) CreateBackup(ctx context.Context, req CreateBackupRequest, requestedBy primitive.ObjectID) (*BackupResponse, error) {
+	collections := req.Collections
+	if len(collections) == 0 {
+		collections = domain.BackupCollections
+	}
+
+	backup, err := s.write(ctx, collections, false, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &BackupResponse{
+		Key:         backup.Key,
+		Collections: backup.Collections,
+		Size:        backup.Size,
+		CreatedAt:   backup.CreatedAt,
+	}
+
+	// A download link is a convenience; backups are still restorable by key without one
+	if link, err := s.downloads.Link(ctx, backup.Key, requestedBy); err == nil {
+		response.DownloadURL = link.URL
+		response.DownloadExpiresAt = &link.ExpiresAt
+	} else {
+		log.Warnf(ctx, "Backup: no download link for %s: %v", backup.Key, err)
+	}
+
+	return response, nil
+}
+
+// write streams a gzipped dump straight into the object store without buffering it in memory,
+// and records it.
+func (s *service) write(ctx context.Context, collections []string, scheduled bool, requestedBy primitive.ObjectID) (*domain.Backup, error) {
+	now := time.Now().UTC()
+	prefix := keyPrefix
+	if scheduled {
+		prefix = scheduledPrefix
+	}
+	key := fmt.Sprintf("%sfinsolvz-%s.jsonl.gz", prefix, now.Format("20060102T150405Z"))
+
+	pr, pw := io.Pipe()
+	done := make(chan exportResult, 1)
+
+	go func() {
+		gz := gzip.NewWriter(pw)
+		counts, err := s.backupRepo.Export(ctx, collections, gz)
+		if err == nil {
+			err = gz.Close()
+		}
+		pw.CloseWithError(err)
+		done <- exportResult{counts: counts, err: err}
+	}()
+
+	obj, putErr := storage.Upload(ctx, s.store, key, pr, "application/gzip", storage.ExportPolicy)
+	// Unblock the exporter if the store stopped reading early
+	pr.CloseWithError(io.ErrClosedPipe)
+	result := <-done
+
+	if result.err != nil {
+		return nil, result.err
+	}
+	if putErr != nil {
+		return nil, putErr
+	}
+
+	backup := &domain.Backup{
+		Key:         key,
+		Status:      domain.BackupStatusCompleted,
+		Scheduled:   scheduled,
+		Collections: result.counts,
+		Size:        obj.Size,
+		CreatedAt:   now,
+	}
+	if !requestedBy.IsZero() {
+		backup.CreatedBy = &requestedBy
+	}
+	// The backup is in the store either way; an unrecorded one is only never rotated
+	if err := s.backupRepo.CreateRecord(ctx, backup); err != nil {
+		log.Warnf(ctx, "Backup: failed to record %s: %v", key, err)
+	}
+	return backup, nil
+}
+
+func (s *service) RunSchedule(ctx context.Context) (*domain.Backup, error) {
+	records, err := s.backupRepo.GetRecords(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if last := lastCompleted(records); last != nil && time.Since(last.CreatedAt) < s.schedule.Interval {
+		return nil, nil
+	}
+
+	backup, err := s.write(ctx, domain.BackupCollections, true, primitive.NilObjectID)
+	if err != nil {
+		failed := &domain.Backup{
+			Status:    domain.BackupStatusFailed,
+			Scheduled: true,
+			Error:     err.Error(),
+			CreatedAt: time.Now().UTC(),
+		}
+		if recordErr := s.backupRepo.CreateRecord(ctx, failed); recordErr != nil {
+			log.Warnf(ctx, "Backup: failed to record the failed backup: %v", recordErr)
+		}
+		return nil, err
+	}
+
+	s.rotate(ctx, append([]*domain.Backup{backup}, records...))
+	return backup, nil
+}
+
+// rotate deletes the scheduled backups past the retention, given newest first, along with
+// the failures older than the newest backup. A backup whose object can't be deleted keeps its
+// record, so the next rotation tries again.
+func (s *service) rotate(ctx context.Context, records []*domain.Backup) {
+	var cutoff time.Time
+	if s.schedule.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -s.schedule.MaxAgeDays)
+	}
+
+	kept := 0
+	for _, record := range records {
+		if record.Status != domain.BackupStatusCompleted {
+			if kept == 0 {
+				continue
+			}
+		} else if kept < max(s.schedule.Keep, 1) && (kept == 0 || record.CreatedAt.After(cutoff)) {
+			// The newest backup is kept whatever its age
+			kept++
+			continue
+		} else if err := s.store.Delete(ctx, record.Key); err != nil {
+			log.Warnf(ctx, "Backup: failed to delete %s: %v", record.Key, err)
+			continue
+		}
+
+		if err := s.backupRepo.DeleteRecord(ctx, record.ID); err != nil {
+			log.Warnf(ctx, "Backup: failed to delete the record of %s: %v", record.Key, err)
+			continue
+		}
+		if record.Key != "" {
+			log.Infof(ctx, "Backup: deleted %s past retention", record.Key)
+		}
+	}
+}
+
+func (s *service) ScheduleStatus(ctx context.Context) (*ScheduleStatus, error) {
+	records, err := s.backupRepo.GetRecords(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ScheduleStatus{Enabled: s.schedule.Interval > 0, Retention: s.schedule}
+	if status.Enabled {
+		status.Interval = s.schedule.Interval.String()
+	}
+	for _, record := range records {
+		if record.Status == domain.BackupStatusCompleted {
+			if status.LastBackup == nil {
+				status.LastBackup = record
+			}
+			status.Retained++
+			status.RetainedSize += record.Size
+		} else if status.LastBackup == nil && status.LastFailure == nil {
+			// Only failures since the last backup are reported
+			status.LastFailure = record
+		}
+	}
+	if status.Enabled && status.LastBackup != nil {
+		next := status.LastBackup.CreatedAt.Add(s.schedule.Interval)
+		status.NextBackupAt = &next
+	}
+
+	return status, nil
+}
+
+// lastCompleted returns the newest completed backup of records, given newest first.
+func lastCompleted(records []*domain.Backup) *domain.Backup {
+	for _, record := range records {
+		if record.Status == domain.BackupStatusCompleted {
+			return record
+		}
+	}
+	return nil
+}
+
+// DownloadLink issues a fresh signed link to an existing backup.
+func (s *service) DownloadLink(ctx context.Context, key string, requestedBy primitive.ObjectID) (*storage.Link, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil, ErrInvalidBackupKey
+	}
+
+	obj, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	obj.Close()
+
+	return s.downloads.Link(ctx, key, requestedBy)
+}
+
+// RestoreBackup upserts every document from a stored dump back into the database.
+func (s *service) RestoreBackup(ctx context.Context, key string) (*RestoreResponse, error) {
+	obj, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	gz, err := gzip.NewReader(obj)
+	if err != nil {
+		return nil, errors.New("INVALID_BACKUP", "Backup is not gzip compressed", 400, err, nil)
+	}
+	defer gz.Close()
+
+	counts, err := s.backupRepo.Import(ctx, gz)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RestoreResponse{
+		Key:         key,
+		Collections: counts,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/tasks"
+)
+
+// TaskCreateBackup runs CreateBackup in the background with a CreateBackupRequest payload.
+const TaskCreateBackup domain.TaskType = "backup.create"
+
+// NewTaskHandler runs TaskCreateBackup tasks. Re-running a task writes a new backup,
+// which is harmless.
+func NewTaskHandler(service Service) tasks.Handler {
+	return func(ctx context.Context, task *domain.Task, report func(int)) (interface{}, error) {
+		var req CreateBackupRequest
+		if err := json.Unmarshal(task.Payload, &req); err != nil {
+			return nil, err
+		}
+		return service.CreateBackup(ctx, req, task.CreatedBy)
+	}
+}
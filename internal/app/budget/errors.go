@@ -0,0 +1,14 @@
+package budget
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidBudgetID     = errors.New("INVALID_BUDGET_ID", "Invalid budget ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidCompanyID    = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidYear         = errors.New("INVALID_YEAR", "Year must be a number between 1900 and 2200", http.StatusBadRequest, nil, nil)
+	ErrInvalidVersion      = errors.New("INVALID_BUDGET_VERSION", "Version must be a positive number", http.StatusBadRequest, nil, nil)
+)
@@ -0,0 +1,196 @@
+package budget
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers budget routes
+// @Tags Budgets
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/budgets", h.GetBudgets).Methods("GET")
+	protected.HandleFunc("/api/budgets", h.CreateBudget).Methods("POST")
+	protected.HandleFunc("/api/budgets/{id}", h.GetBudgetByID).Methods("GET")
+	protected.HandleFunc("/api/budgets/{id}", h.UpdateBudget).Methods("PUT")
+	protected.HandleFunc("/api/budgets/{id}", h.DeleteBudget).Methods("DELETE")
+	protected.HandleFunc("/api/budgets/{id}/versions", h.GetVersions).Methods("GET")
+	protected.HandleFunc("/api/budgets/{id}/versions/{version}", h.GetVersion).Methods("GET")
+	protected.HandleFunc("/api/budgets/{id}/variance", h.GetVariance).Methods("GET")
+}
+
+// GetBudgets lists the budgets of the companies the user can see, without lines
+// @Param company query string false "Only the budgets of this company"
+// @Param year query integer false "Only the budgets of this year"
+func (h *Handler) GetBudgets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	year := 0
+	if value := query.Get("year"); value != "" {
+		var err error
+		if year, err = strconv.Atoi(value); err != nil {
+			utils.HandleHTTPError(w, ErrInvalidYear, r)
+			return
+		}
+	}
+
+	budgets, err := h.service.GetBudgets(r.Context(), query.Get("company"), year)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, budgets)
+}
+
+// CreateBudget stores a company's budget for a year as its first version. Admins manage the
+// budgets of their companies.
+func (h *Handler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var req CreateBudgetRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	budget, err := h.service.CreateBudget(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, budget)
+}
+
+// @Summary Get budget by ID
+func (h *Handler) GetBudgetByID(w http.ResponseWriter, r *http.Request) {
+	budget, err := h.service.GetBudgetByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, budget)
+}
+
+// UpdateBudget changes a budget as of the version given in the body, making its next version.
+// It fails with 409 when the budget has a newer version.
+func (h *Handler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	var req UpdateBudgetRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	budget, err := h.service.UpdateBudget(r.Context(), mux.Vars(r)["id"], req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Budget updated successfully",
+		"budget":  budget,
+	})
+}
+
+// DeleteBudget removes a budget with all its versions
+func (h *Handler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteBudget(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{
+		"message": "Budget deleted successfully",
+	})
+}
+
+// GetVersions lists the versions of a budget, newest first, without lines
+func (h *Handler) GetVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.service.GetVersions(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, versions)
+}
+
+// @Summary Get a version of a budget
+func (h *Handler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		utils.HandleHTTPError(w, ErrInvalidVersion, r)
+		return
+	}
+
+	budgetVersion, err := h.service.GetVersion(r.Context(), vars["id"], version)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, budgetVersion)
+}
+
+// GetVariance compares each line of a budget with the same line item in the company's reports
+// for the budget's year
+// @Param version query integer false "Version of the budget to compare; the current one when unset"
+func (h *Handler) GetVariance(w http.ResponseWriter, r *http.Request) {
+	version := 0
+	if value := r.URL.Query().Get("version"); value != "" {
+		var err error
+		if version, err = strconv.Atoi(value); err != nil {
+			utils.HandleHTTPError(w, ErrInvalidVersion, r)
+			return
+		}
+	}
+
+	variance, err := h.service.GetVariance(r.Context(), mux.Vars(r)["id"], version)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, variance)
+}
+
+// requester is the ID of the user making the request, recorded on budgets and their versions.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
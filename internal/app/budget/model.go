@@ -0,0 +1,125 @@
+package budget
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+)
+
+// Request DTOs
+type BudgetLineRequest struct {
+	// Item names a line item of the company's reports, e.g. "Revenue"
+	Item   string  `json:"item" validate:"required,max=200"`
+	Amount float64 `json:"amount"`
+}
+
+type CreateBudgetRequest struct {
+	Company    string              `json:"company" validate:"required"`
+	Name       string              `json:"name" validate:"required,min=1,max=100"`
+	Year       int                 `json:"year" validate:"required,min=1900,max=2200"`
+	ReportType *string             `json:"reportType,omitempty"` // reports holding the actuals; every report of the year when unset
+	Currency   *string             `json:"currency,omitempty"`
+	Lines      []BudgetLineRequest `json:"lines" validate:"required,min=1,max=5000,dive"`
+	Note       string              `json:"note,omitempty" validate:"max=500"`
+}
+
+// UpdateBudgetRequest changes a budget as of the version the caller read, making its next
+// version. It fails when someone else made one in the meantime.
+type UpdateBudgetRequest struct {
+	Version    int                 `json:"version" validate:"required,min=1"`
+	Name       *string             `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	ReportType *string             `json:"reportType,omitempty"` // empty for every report
+	Currency   *string             `json:"currency,omitempty"`   // empty for none
+	Lines      []BudgetLineRequest `json:"lines,omitempty" validate:"omitempty,max=5000,dive"`
+	Note       string              `json:"note,omitempty" validate:"max=500"`
+}
+
+// Response DTOs
+type BudgetResponse struct {
+	ID         string              `json:"id"`
+	Company    string              `json:"company"`
+	Name       string              `json:"name"`
+	Year       int                 `json:"year"`
+	ReportType *string             `json:"reportType"`
+	Currency   *string             `json:"currency"`
+	Version    int                 `json:"version"`
+	Lines      []domain.BudgetLine `json:"lines,omitempty"` // left out of lists
+	CreatedBy  string              `json:"createdBy"`
+	UpdatedBy  string              `json:"updatedBy"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+}
+
+type VersionResponse struct {
+	Version   int                 `json:"version"`
+	Lines     []domain.BudgetLine `json:"lines,omitempty"` // left out of lists
+	Note      string              `json:"note,omitempty"`
+	CreatedBy string              `json:"createdBy"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+// VarianceResponse compares a version of a budget with the line items of the company's
+// reports for its year.
+type VarianceResponse struct {
+	Budget   string         `json:"budget"`
+	Version  int            `json:"version"`
+	Company  string         `json:"company"`
+	Year     int            `json:"year"`
+	Currency *string        `json:"currency"`
+	Reports  []string       `json:"reports"` // reports the actuals were read from, latest change first
+	Lines    []VarianceLine `json:"lines"`
+	// Format is how the company writes amounts in the budget's currency
+	Format *format.Spec `json:"format"`
+}
+
+// VarianceLine is a budget line against its actual amount. Actual and the variances are
+// null when no report has the line item.
+type VarianceLine struct {
+	Item            string          `json:"item"`
+	Budget          float64         `json:"budget"`
+	Actual          *float64        `json:"actual"`
+	Variance        *float64        `json:"variance"`        // actual minus budget
+	VariancePercent *float64        `json:"variancePercent"` // of the budget; null when it is zero
+	Display         VarianceDisplay `json:"display"`
+}
+
+// VarianceDisplay is a variance line written in the company's number format, e.g. Rp 1.250.000
+// and -12,5%. Fields whose amounts are null are empty.
+type VarianceDisplay struct {
+	Budget          string `json:"budget"`
+	Actual          string `json:"actual,omitempty"`
+	Variance        string `json:"variance,omitempty"`
+	VariancePercent string `json:"variancePercent,omitempty"`
+}
+
+func ToBudgetResponse(budget *domain.Budget) *BudgetResponse {
+	response := &BudgetResponse{
+		ID:        budget.ID.Hex(),
+		Company:   budget.Company.Hex(),
+		Name:      budget.Name,
+		Year:      budget.Year,
+		Currency:  budget.Currency,
+		Version:   budget.Version,
+		Lines:     budget.Lines,
+		CreatedBy: budget.CreatedBy.Hex(),
+		UpdatedBy: budget.UpdatedBy.Hex(),
+		CreatedAt: budget.CreatedAt,
+		UpdatedAt: budget.UpdatedAt,
+	}
+	if budget.ReportType != nil {
+		reportType := budget.ReportType.Hex()
+		response.ReportType = &reportType
+	}
+	return response
+}
+
+func ToVersionResponse(version *domain.BudgetVersion) *VersionResponse {
+	return &VersionResponse{
+		Version:   version.Version,
+		Lines:     version.Lines,
+		Note:      version.Note,
+		CreatedBy: version.CreatedBy.Hex(),
+		CreatedAt: version.CreatedAt,
+	}
+}
@@ -0,0 +1,353 @@
+package budget
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type Service interface {
+	CreateBudget(ctx context.Context, req CreateBudgetRequest, createdBy primitive.ObjectID) (*BudgetResponse, error)
+	// GetBudgets lists the budgets of a company, or of every company the caller can see when
+	// company is empty, of a year or of all when year is 0, without their lines.
+	GetBudgets(ctx context.Context, company string, year int) ([]*BudgetResponse, error)
+	GetBudgetByID(ctx context.Context, id string) (*BudgetResponse, error)
+	// UpdateBudget stores the changes of req as the budget's next version.
+	UpdateBudget(ctx context.Context, id string, req UpdateBudgetRequest, updatedBy primitive.ObjectID) (*BudgetResponse, error)
+	DeleteBudget(ctx context.Context, id string) error
+	GetVersions(ctx context.Context, id string) ([]*VersionResponse, error)
+	GetVersion(ctx context.Context, id string, version int) (*VersionResponse, error)
+	// GetVariance compares a version of a budget, the current one when version is 0, with
+	// the company's reports for its year.
+	GetVariance(ctx context.Context, id string, version int) (*VarianceResponse, error)
+}
+
+type service struct {
+	budgetRepo     domain.BudgetRepository
+	reportRepo     domain.ReportRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+}
+
+func NewService(budgetRepo domain.BudgetRepository, reportRepo domain.ReportRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	return &service{
+		budgetRepo:     budgetRepo,
+		reportRepo:     reportRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+}
+
+func (s *service) CreateBudget(ctx context.Context, req CreateBudgetRequest, createdBy primitive.ObjectID) (*BudgetResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(req.Company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, companyID); err != nil {
+		return nil, err
+	}
+
+	lines, err := toLines(req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &domain.Budget{
+		Company:   companyID,
+		Name:      strings.TrimSpace(req.Name),
+		Year:      req.Year,
+		Currency:  currency(req.Currency),
+		Lines:     lines,
+		CreatedBy: createdBy,
+		UpdatedBy: createdBy,
+	}
+	if req.ReportType != nil && *req.ReportType != "" {
+		reportType, err := s.reportType(ctx, *req.ReportType)
+		if err != nil {
+			return nil, err
+		}
+		budget.ReportType = &reportType
+	}
+
+	if err := s.budgetRepo.Create(ctx, budget, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+	return ToBudgetResponse(budget), nil
+}
+
+func (s *service) GetBudgets(ctx context.Context, company string, year int) ([]*BudgetResponse, error) {
+	var companyID *primitive.ObjectID
+	if company != "" {
+		id, err := primitive.ObjectIDFromHex(company)
+		if err != nil {
+			return nil, ErrInvalidCompanyID
+		}
+		companyID = &id
+	}
+
+	budgets, err := s.budgetRepo.GetAll(ctx, companyID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*BudgetResponse, len(budgets))
+	for i, budget := range budgets {
+		responses[i] = ToBudgetResponse(budget)
+	}
+	return responses, nil
+}
+
+func (s *service) GetBudgetByID(ctx context.Context, id string) (*BudgetResponse, error) {
+	budget, err := s.getBudget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToBudgetResponse(budget), nil
+}
+
+func (s *service) UpdateBudget(ctx context.Context, id string, req UpdateBudgetRequest, updatedBy primitive.ObjectID) (*BudgetResponse, error) {
+	budget, err := s.getBudget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, budget.Company); err != nil {
+		return nil, err
+	}
+
+	// The repository only updates the budget if it is still at the version the caller read
+	budget.Version = req.Version
+	budget.UpdatedBy = updatedBy
+	if req.Name != nil {
+		budget.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Currency != nil {
+		budget.Currency = currency(req.Currency)
+	}
+	if req.ReportType != nil {
+		budget.ReportType = nil
+		if *req.ReportType != "" {
+			reportType, err := s.reportType(ctx, *req.ReportType)
+			if err != nil {
+				return nil, err
+			}
+			budget.ReportType = &reportType
+		}
+	}
+	if len(req.Lines) > 0 {
+		if budget.Lines, err = toLines(req.Lines); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.budgetRepo.Update(ctx, budget, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+	return ToBudgetResponse(budget), nil
+}
+
+func (s *service) DeleteBudget(ctx context.Context, id string) error {
+	budget, err := s.getBudget(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, budget.Company); err != nil {
+		return err
+	}
+	return s.budgetRepo.Delete(ctx, budget.ID)
+}
+
+func (s *service) GetVersions(ctx context.Context, id string) ([]*VersionResponse, error) {
+	budget, err := s.getBudget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.budgetRepo.GetVersions(ctx, budget.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*VersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = ToVersionResponse(version)
+	}
+	return responses, nil
+}
+
+func (s *service) GetVersion(ctx context.Context, id string, version int) (*VersionResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidBudgetID
+	}
+	if version < 1 {
+		return nil, ErrInvalidVersion
+	}
+
+	budgetVersion, err := s.budgetRepo.GetVersion(ctx, objectID, version)
+	if err != nil {
+		return nil, err
+	}
+	return ToVersionResponse(budgetVersion), nil
+}
+
+func (s *service) GetVariance(ctx context.Context, id string, version int) (*VarianceResponse, error) {
+	budget, err := s.getBudget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version < 0 {
+		return nil, ErrInvalidVersion
+	}
+	if version != 0 && version != budget.Version {
+		budgetVersion, err := s.budgetRepo.GetVersion(ctx, budget.ID, version)
+		if err != nil {
+			return nil, err
+		}
+		budget.Version, budget.Lines = budgetVersion.Version, budgetVersion.Lines
+	}
+
+	reports, err := s.actualReports(ctx, budget)
+	if err != nil {
+		return nil, err
+	}
+	company, err := s.companyRepo.GetByID(ctx, budget.Company)
+	if err != nil {
+		return nil, err
+	}
+	var currency string
+	if budget.Currency != nil {
+		currency = *budget.Currency
+	}
+	formatter := format.ForBranding(company.Branding, "")
+
+	// A line item in several reports is taken from the one changed last
+	actuals := map[string]float64{}
+	response := &VarianceResponse{
+		Budget:   budget.ID.Hex(),
+		Version:  budget.Version,
+		Company:  budget.Company.Hex(),
+		Year:     budget.Year,
+		Currency: budget.Currency,
+		Reports:  make([]string, len(reports)),
+		Lines:    make([]VarianceLine, len(budget.Lines)),
+		Format:   formatter.Spec(currency),
+	}
+	for i, report := range reports {
+		response.Reports[i] = report.ID.Hex()
+		for item, amount := range domain.LineItems(report.ReportData) {
+			if _, ok := actuals[item]; !ok {
+				actuals[item] = amount
+			}
+		}
+	}
+
+	for i, line := range budget.Lines {
+		variance := VarianceLine{Item: line.Item, Budget: line.Amount}
+		variance.Display.Budget = formatter.Amount(line.Amount, currency)
+		if actual, ok := actuals[domain.LineItemName(line.Item)]; ok {
+			difference := roundCents(actual - line.Amount)
+			variance.Actual, variance.Variance = &actual, &difference
+			variance.Display.Actual = formatter.Amount(actual, currency)
+			variance.Display.Variance = formatter.Amount(difference, currency)
+			if line.Amount != 0 {
+				percent := roundCents(difference / math.Abs(line.Amount) * 100)
+				variance.VariancePercent = &percent
+				variance.Display.VariancePercent = formatter.Percent(percent)
+			}
+		}
+		response.Lines[i] = variance
+	}
+
+	return response, nil
+}
+
+// actualReports returns the company's reports for the budget's year, of its report type when
+// it has one and without those in another currency, latest change first.
+func (s *service) actualReports(ctx context.Context, budget *domain.Budget) ([]*domain.PopulatedReport, error) {
+	all, err := s.reportRepo.GetByCompany(domain.WithReportData(ctx), budget.Company)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []*domain.PopulatedReport{}
+	for _, report := range all {
+		if report.Year != budget.Year {
+			continue
+		}
+		if budget.ReportType != nil && (report.ReportType == nil || report.ReportType.ID != *budget.ReportType) {
+			continue
+		}
+		if budget.Currency != nil && report.Currency != nil && !strings.EqualFold(*report.Currency, *budget.Currency) {
+			continue
+		}
+		reports = append(reports, report)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].UpdatedAt.After(reports[j].UpdatedAt)
+	})
+	return reports, nil
+}
+
+func (s *service) getBudget(ctx context.Context, id string) (*domain.Budget, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidBudgetID
+	}
+	return s.budgetRepo.GetByID(ctx, objectID)
+}
+
+func (s *service) reportType(ctx context.Context, id string) (primitive.ObjectID, error) {
+	reportTypeID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidReportTypeID
+	}
+	if _, err := s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return reportTypeID, nil
+}
+
+// authorize checks that the caller may manage the budgets of the company.
+func authorize(ctx context.Context, companyID primitive.ObjectID) error {
+	return middleware.Authorize(ctx, "manage", policy.Resource{Type: "budget", Companies: []string{companyID.Hex()}})
+}
+
+// toLines converts budget lines, rejecting two lines for the same line item.
+func toLines(requests []BudgetLineRequest) ([]domain.BudgetLine, error) {
+	lines := make([]domain.BudgetLine, len(requests))
+	items := map[string]bool{}
+	for i, line := range requests {
+		item := strings.TrimSpace(line.Item)
+		name := domain.LineItemName(item)
+		if items[name] {
+			return nil, errors.New("DUPLICATE_LINE_ITEM", "A budget can list a line item only once", 400, nil, map[string]interface{}{"item": item})
+		}
+		items[name] = true
+		lines[i] = domain.BudgetLine{Item: item, Amount: line.Amount}
+	}
+	return lines, nil
+}
+
+func currency(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	code := strings.ToUpper(strings.TrimSpace(*value))
+	return &code
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
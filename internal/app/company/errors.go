@@ -11,4 +11,7 @@ var (
 	ErrInvalidCompanyName   = errors.New("INVALID_COMPANY_NAME", "Company name is invalid", http.StatusBadRequest, nil, nil)
 	ErrInvalidUserID        = errors.New("INVALID_USER_ID", "Invalid user ID format", http.StatusBadRequest, nil, nil)
 	ErrUserNotFound         = errors.New("USER_NOT_FOUND", "User not found", http.StatusNotFound, nil, nil)
+	ErrInvalidFiscalYear    = errors.New("INVALID_FISCAL_YEAR", "Fiscal year is invalid", http.StatusBadRequest, nil, nil)
+
+	ErrProfilePictureReadOnly = errors.New("PROFILE_PICTURE_READ_ONLY", "Upload the logo to PUT /api/company/{id}/logo instead of setting profilePicture", http.StatusBadRequest, nil, nil)
 )
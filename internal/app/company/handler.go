@@ -2,6 +2,7 @@ package company
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -23,24 +24,36 @@ func NewHandler(service Service) *Handler {
 }
 
 // RegisterRoutes registers company routes
+// @Tags Company Management
 func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
 	// Protected routes - require authentication
 	protected := router.PathPrefix("").Subrouter()
 	protected.Use(authMiddleware)
 
-	protected.HandleFunc("/api/company", h.GetCompanies).Methods("GET")
+	protected.Handle("/api/company", middleware.CacheResponses(middleware.ResponseCacheCompanies, 3*time.Minute)(
+		http.HandlerFunc(h.GetCompanies))).Methods("GET")
 	protected.HandleFunc("/api/company", h.CreateCompany).Methods("POST")
 	protected.HandleFunc("/api/user/companies", h.GetUserCompanies).Methods("GET")
 	protected.HandleFunc("/api/company/{idOrName}", h.GetCompanyByIDOrName).Methods("GET")
+	protected.HandleFunc("/api/company/{id}/fiscal-calendar", h.GetFiscalCalendar).Methods("GET")
 
 	// Admin-only routes
 	adminOnly := protected.PathPrefix("").Subrouter()
-	adminOnly.Use(middleware.RequireRole("SUPER_ADMIN"))
+	adminOnly.Use(middleware.RequirePermission("manage", "company"))
 	adminOnly.HandleFunc("/api/company/{id}", h.UpdateCompany).Methods("PUT")
 	adminOnly.HandleFunc("/api/company/{id}", h.DeleteCompany).Methods("DELETE")
+	adminOnly.HandleFunc("/api/company/{id}/logo", h.UploadLogo).Methods("PUT")
 }
 
+// @Summary Get all companies
 func (h *Handler) GetCompanies(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r)
+
+	if format := utils.RowFormat(r); format != "" {
+		h.streamCompanies(w, r, format)
+		return
+	}
+
 	companies, err := h.service.GetCompanies(r.Context())
 	if err != nil {
 		utils.HandleHTTPError(w, err, r)
@@ -50,6 +63,25 @@ func (h *Handler) GetCompanies(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, companies)
 }
 
+// companyColumns are the CSV columns of company lists when ?fields= doesn't choose them
+var companyColumns = []string{"_id", "name", "user.name", "createdAt", "updatedAt"}
+
+// streamCompanies writes all companies as CSV or NDJSON rows while they are read. Unlike
+// the JSON list, it is not capped at 100 companies.
+func (h *Handler) streamCompanies(w http.ResponseWriter, r *http.Request, format string) {
+	rows := utils.NewRowWriter(w, r, format, companyColumns...)
+	err := h.service.EachCompany(r.Context(), func(company *CompanyResponse) error {
+		return rows.Write(company)
+	})
+	if err == nil {
+		err = rows.Close()
+	}
+	if err != nil {
+		rows.Fail(err, r)
+	}
+}
+
+// @Summary Create new company
 func (h *Handler) CreateCompany(w http.ResponseWriter, r *http.Request) {
 	var req CreateCompanyRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -74,7 +106,10 @@ func (h *Handler) CreateCompany(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Get current user's companies
 func (h *Handler) GetUserCompanies(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r)
+
 	companies, err := h.service.GetUserCompanies(r.Context())
 	if err != nil {
 		utils.HandleHTTPError(w, err, r)
@@ -84,6 +119,7 @@ func (h *Handler) GetUserCompanies(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, companies)
 }
 
+// @Summary Update company
 func (h *Handler) UpdateCompany(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -111,6 +147,7 @@ func (h *Handler) UpdateCompany(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Delete company
 func (h *Handler) DeleteCompany(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -127,7 +164,42 @@ func (h *Handler) DeleteCompany(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetFiscalCalendar returns the company's fiscal calendar and the periods of a fiscal year
+// @Param year query string false "Fiscal year, named after the calendar year it ends in; the current one when omitted"
+func (h *Handler) GetFiscalCalendar(w http.ResponseWriter, r *http.Request) {
+	calendar, err := h.service.GetFiscalCalendar(r.Context(), mux.Vars(r)["id"], r.URL.Query().Get("year"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, calendar)
+}
+
+// UploadLogo accepts a JPEG, PNG or GIF in the multipart field "file"
+func (h *Handler) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	file, contentType, err := utils.MultipartFile(r, "file")
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	company, err := h.service.UploadLogo(r.Context(), mux.Vars(r)["id"], file, contentType)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Logo uploaded successfully",
+		"company": company,
+	})
+}
+
+// @Summary Get company by ID or name
 func (h *Handler) GetCompanyByIDOrName(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r)
+
 	vars := mux.Vars(r)
 	idOrName := vars["idOrName"]
 
@@ -4,29 +4,67 @@ import (
 	"time"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
 )
 
 // Request DTOs
 type CreateCompanyRequest struct {
 	Name           string   `json:"name" validate:"required,min=2,max=100"`
-	ProfilePicture *string  `json:"profilePicture,omitempty"`
-	User           []string `json:"user,omitempty"` // Array of user IDs as strings
+	ProfilePicture *string  `json:"profilePicture,omitempty"` // deprecated: upload via PUT /api/company/{id}/logo
+	User           []string `json:"user,omitempty"`           // Array of user IDs as strings
 }
 
 type UpdateCompanyRequest struct {
 	Name           *string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	ProfilePicture *string  `json:"profilePicture,omitempty"` // Simple URL string
+	ProfilePicture *string  `json:"profilePicture,omitempty"` // only the current value or "" to remove the logo
 	User           []string `json:"user,omitempty"`           // Array of user IDs as strings
+	// FiscalCalendar replaces the company's fiscal calendar; January and MONTHS restore calendar years
+	FiscalCalendar *FiscalCalendarRequest `json:"fiscalCalendar,omitempty"`
+	// Jurisdiction is where the company is taxed, e.g. "ID" or "US-CA"; "" removes it
+	Jurisdiction *string `json:"jurisdiction,omitempty" validate:"omitempty,max=10"`
+	// Branding replaces how the company's exported documents look; {} restores the default
+	Branding *domain.Branding `json:"branding,omitempty"`
+}
+
+type FiscalCalendarRequest struct {
+	StartMonth int    `json:"startMonth" validate:"required,min=1,max=12"`
+	Pattern    string `json:"pattern" validate:"required,oneof=MONTHS 4-4-5 4-5-4 5-4-4"`
+	WeekStart  int    `json:"weekStart" validate:"min=0,max=6"` // 0 is Sunday; only used by week-based patterns
 }
 
 // Response DTOs - exact legacy format
 type CompanyResponse struct {
-	ID             string     `json:"_id"` // ✅ Changed to "_id" exactly like legacy
-	Name           string     `json:"name"`
-	ProfilePicture *string    `json:"profilePicture"`
-	User           []UserInfo `json:"user"` // Populated user data
-	CreatedAt      time.Time  `json:"createdAt"`
-	UpdatedAt      time.Time  `json:"updatedAt"`
+	ID                  string     `json:"_id"` // ✅ Changed to "_id" exactly like legacy
+	Name                string     `json:"name"`
+	ProfilePicture      *string    `json:"profilePicture"`
+	ProfilePictureThumb *string    `json:"profilePictureThumb,omitempty"`
+	User                []UserInfo `json:"user"` // Populated user data
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+
+	// Organization is the ID of the company's organization, omitted for companies of the instance
+	Organization string `json:"organization,omitempty"`
+	// FiscalCalendar is omitted for companies whose fiscal years are calendar years
+	FiscalCalendar *domain.FiscalCalendar `json:"fiscalCalendar,omitempty"`
+	// Jurisdiction is where the company is taxed, omitted when unset
+	Jurisdiction *string `json:"jurisdiction,omitempty"`
+	// Branding is omitted for companies with the default look
+	Branding *domain.Branding `json:"branding,omitempty"`
+}
+
+// FiscalCalendarResponse is a company's fiscal calendar with the periods of one fiscal year.
+type FiscalCalendarResponse struct {
+	Calendar domain.FiscalCalendar `json:"calendar"`
+	Year     domain.FiscalYear     `json:"year"`
+	Periods  []domain.FiscalPeriod `json:"periods"`
+}
+
+// Links of a company in enveloped responses.
+func (c *CompanyResponse) Links() utils.Links {
+	return utils.Links{
+		"self":    {Href: "/api/company/" + c.ID},
+		"reports": {Href: "/api/reports/company/" + c.ID},
+	}
 }
 
 type UserInfo struct {
@@ -36,14 +74,7 @@ type UserInfo struct {
 
 // Helper to convert domain.Company to CompanyResponse
 func ToCompanyResponse(company *domain.Company) CompanyResponse {
-	return CompanyResponse{
-		ID:             company.ID.Hex(),
-		Name:           company.Name,
-		ProfilePicture: company.ProfilePicture,
-		User:           []UserInfo{}, // Will be populated by service layer
-		CreatedAt:      company.CreatedAt,
-		UpdatedAt:      company.UpdatedAt,
-	}
+	return ToCompanyResponseWithUsers(company, nil) // users are populated by the service layer
 }
 
 // Helper to convert domain.Company to CompanyResponse with populated users
@@ -56,12 +87,20 @@ func ToCompanyResponseWithUsers(company *domain.Company, users []*domain.User) C
 		}
 	}
 
-	return CompanyResponse{
-		ID:             company.ID.Hex(),
-		Name:           company.Name,
-		ProfilePicture: company.ProfilePicture,
-		User:           userInfos,
-		CreatedAt:      company.CreatedAt,
-		UpdatedAt:      company.UpdatedAt,
+	response := CompanyResponse{
+		ID:                  company.ID.Hex(),
+		Name:                company.Name,
+		ProfilePicture:      company.ProfilePicture,
+		ProfilePictureThumb: company.ProfilePictureThumb,
+		User:                userInfos,
+		CreatedAt:           company.CreatedAt,
+		UpdatedAt:           company.UpdatedAt,
+		FiscalCalendar:      company.FiscalCalendar,
+		Jurisdiction:        company.Jurisdiction,
+		Branding:            company.Branding,
+	}
+	if company.Organization != nil {
+		response.Organization = company.Organization.Hex()
 	}
+	return response
 }
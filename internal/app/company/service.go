@@ -3,36 +3,64 @@ package company
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/sync/errgroup"
 
 	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/storage"
 	"finsolvz-backend/internal/utils"
 	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	// userBatchSize bounds the IDs of one query populating company users
+	userBatchSize = 200
+	// populationConcurrency bounds the user queries of one request running at once
+	populationConcurrency = 4
+	// eachPageSize is the number of companies EachCompany populates at once
+	eachPageSize = 100
 )
 
 type Service interface {
 	CreateCompany(ctx context.Context, req CreateCompanyRequest) (*CompanyResponse, error)
 	GetCompanies(ctx context.Context) ([]*CompanyResponse, error)
+	// EachCompany calls fn with every company one at a time as they are read; unlike
+	// GetCompanies it is not capped
+	EachCompany(ctx context.Context, fn func(*CompanyResponse) error) error
 	GetCompanyByID(ctx context.Context, id string) (*CompanyResponse, error)
 	GetCompanyByName(ctx context.Context, name string) (*CompanyResponse, error)
 	GetUserCompanies(ctx context.Context) ([]*CompanyResponse, error)
 	UpdateCompany(ctx context.Context, id string, req UpdateCompanyRequest) (*CompanyResponse, error)
+	// GetFiscalCalendar returns the company's fiscal calendar with the periods of a fiscal year,
+	// the current one when year is empty
+	GetFiscalCalendar(ctx context.Context, id, year string) (*FiscalCalendarResponse, error)
 	DeleteCompany(ctx context.Context, id string) (*CompanyResponse, error)
+	// UploadLogo processes an image upload into the company's logo, replacing any previous one
+	UploadLogo(ctx context.Context, id string, r io.Reader, contentType string) (*CompanyResponse, error)
 }
 
 type service struct {
 	companyRepo domain.CompanyRepository
 	userRepo    domain.UserRepository
+	outboxRepo  domain.OutboxRepository
+	tx          domain.Transactor
+	store       storage.ObjectStore
 }
 
-func NewService(companyRepo domain.CompanyRepository, userRepo domain.UserRepository) Service {
+func NewService(companyRepo domain.CompanyRepository, userRepo domain.UserRepository, outboxRepo domain.OutboxRepository, tx domain.Transactor, store storage.ObjectStore) Service {
 	return &service{
 		companyRepo: companyRepo,
 		userRepo:    userRepo,
+		outboxRepo:  outboxRepo,
+		tx:          tx,
+		store:       store,
 	}
 }
 
@@ -62,13 +90,20 @@ func (s *service) CreateCompany(ctx context.Context, req CreateCompanyRequest) (
 		userIDs = append(userIDs, userID)
 	}
 
+	// Logos are only set through UploadLogo, never from a client-supplied URL
+	if req.ProfilePicture != nil && *req.ProfilePicture != "" {
+		return nil, ErrProfilePictureReadOnly
+	}
+
 	company := &domain.Company{
-		Name:           name,
-		ProfilePicture: req.ProfilePicture,
-		User:           userIDs,
+		Name: name,
+		User: userIDs,
 	}
 
-	if err := s.companyRepo.Create(ctx, company); err != nil {
+	err = s.saveWithEvent(ctx, domain.EventCompanyCreated, company, func(ctx context.Context) error {
+		return s.companyRepo.Create(ctx, company)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -83,12 +118,14 @@ func (s *service) CreateCompany(ctx context.Context, req CreateCompanyRequest) (
 }
 
 func (s *service) GetCompanies(ctx context.Context) ([]*CompanyResponse, error) {
-	// Try cache first
+	// Try cache first; it holds every company, so only unrestricted callers use it
 	cache := utils.GetCache()
 	cacheKey := "companies:all"
+	cacheable := domain.ReadsEverything(ctx)
 
-	if cached, found := cache.Get(cacheKey); found {
-		return cached.([]*CompanyResponse), nil
+	var cached []*CompanyResponse
+	if found := cache.Get(cacheKey, &cached); found && cacheable {
+		return cached, nil
 	}
 
 	companies, err := s.companyRepo.GetAll(ctx)
@@ -96,31 +133,50 @@ func (s *service) GetCompanies(ctx context.Context) ([]*CompanyResponse, error)
 		return nil, err
 	}
 
-	responses := make([]*CompanyResponse, len(companies))
-	for i, company := range companies {
-		users, err := s.getUsersByIDs(ctx, company.User)
-		if err != nil {
-			response := ToCompanyResponse(company)
-			responses[i] = &response
-		} else {
-			response := ToCompanyResponseWithUsers(company, users)
-			responses[i] = &response
-		}
-	}
+	responses := s.populateCompanies(ctx, companies)
 
 	// Cache for 3 minutes (companies don't change often)
-	cache.Set(cacheKey, responses, 3*time.Minute)
+	if cacheable {
+		cache.Set(cacheKey, responses, 3*time.Minute)
+	}
 
 	return responses, nil
 }
 
+func (s *service) EachCompany(ctx context.Context, fn func(*CompanyResponse) error) error {
+	// Companies are populated a page at a time, so the users of a page are read together
+	page := make([]*domain.Company, 0, eachPageSize)
+	flush := func() error {
+		for _, response := range s.populateCompanies(ctx, page) {
+			if err := fn(response); err != nil {
+				return err
+			}
+		}
+		page = page[:0]
+		return nil
+	}
+
+	err := s.companyRepo.Each(ctx, func(company *domain.Company) error {
+		page = append(page, company)
+		if len(page) < eachPageSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
 func (s *service) GetCompanyByID(ctx context.Context, id string) (*CompanyResponse, error) {
-	// Try cache first
+	// Try cache first; entries are shared, so callers limited by a tenant or access scope skip it
 	cache := utils.GetCache()
 	cacheKey := fmt.Sprintf("company:%s", id)
 
-	if cached, found := cache.Get(cacheKey); found {
-		return cached.(*CompanyResponse), nil
+	var cached *CompanyResponse
+	if found := cache.Get(cacheKey, &cached); found && domain.ReadsEverything(ctx) {
+		return cached, nil
 	}
 
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -133,11 +189,7 @@ func (s *service) GetCompanyByID(ctx context.Context, id string) (*CompanyRespon
 		return nil, err
 	}
 
-	// Convert relative URLs to absolute URLs
-	if company.ProfilePicture != nil && !strings.HasPrefix(*company.ProfilePicture, "http") {
-		fullURL := "http://152.42.172.219:8787" + *company.ProfilePicture
-		company.ProfilePicture = &fullURL
-	}
+	absolutePictureURLs(company)
 
 	users, err := s.getUsersByIDs(ctx, company.User)
 	if err != nil {
@@ -203,8 +255,15 @@ func (s *service) UpdateCompany(ctx context.Context, id string, req UpdateCompan
 		company.Name = name
 	}
 
-	if req.ProfilePicture != nil {
-		company.ProfilePicture = req.ProfilePicture
+	// Clients may echo the current logo back or clear it, but not point it elsewhere
+	var removedLogo []*string
+	if req.ProfilePicture != nil && !isCurrentPicture(company, *req.ProfilePicture) {
+		if *req.ProfilePicture != "" {
+			return nil, ErrProfilePictureReadOnly
+		}
+		removedLogo = []*string{company.ProfilePicture, company.ProfilePictureThumb}
+		company.ProfilePicture = nil
+		company.ProfilePictureThumb = nil
 	}
 
 	if req.User != nil {
@@ -225,9 +284,50 @@ func (s *service) UpdateCompany(ctx context.Context, id string, req UpdateCompan
 		company.User = userIDs
 	}
 
-	if err := s.companyRepo.Update(ctx, objectID, company); err != nil {
+	if req.FiscalCalendar != nil {
+		calendar := &domain.FiscalCalendar{
+			StartMonth: time.Month(req.FiscalCalendar.StartMonth),
+			Pattern:    domain.FiscalPattern(req.FiscalCalendar.Pattern),
+			WeekStart:  time.Weekday(req.FiscalCalendar.WeekStart),
+		}
+		if err := calendar.Validate(); err != nil {
+			return nil, errors.New("INVALID_FISCAL_CALENDAR", "Fiscal calendar is invalid", 400, err, map[string]interface{}{"reason": err.Error()})
+		}
+		if calendar.IsCalendarYear() {
+			calendar = nil
+		}
+		company.FiscalCalendar = calendar
+	}
+
+	if req.Jurisdiction != nil {
+		company.Jurisdiction = nil
+		if *req.Jurisdiction != "" {
+			jurisdiction, err := domain.ParseJurisdiction(*req.Jurisdiction)
+			if err != nil {
+				return nil, errors.New("INVALID_JURISDICTION", "Jurisdiction must be an ISO 3166 country code with an optional subdivision, e.g. ID or US-CA", 400, err, nil)
+			}
+			company.Jurisdiction = &jurisdiction
+		}
+	}
+
+	if req.Branding != nil {
+		branding := *req.Branding
+		if err := branding.Validate(); err != nil {
+			return nil, errors.New("INVALID_BRANDING", "Branding is invalid", 400, err, map[string]interface{}{"reason": err.Error()})
+		}
+		company.Branding = &branding
+		if branding.IsZero() {
+			company.Branding = nil
+		}
+	}
+
+	err = s.saveWithEvent(ctx, domain.EventCompanyUpdated, company, func(ctx context.Context) error {
+		return s.companyRepo.Update(ctx, objectID, company)
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.deleteImages(ctx, removedLogo...)
 
 	users, err := s.getUsersByIDs(ctx, company.User)
 	if err != nil {
@@ -239,6 +339,36 @@ func (s *service) UpdateCompany(ctx context.Context, id string, req UpdateCompan
 	return &response, nil
 }
 
+func (s *service) GetFiscalCalendar(ctx context.Context, id, year string) (*FiscalCalendarResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+
+	company, err := s.companyRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	calendar := company.FiscalCalendar
+
+	fiscalYear := calendar.YearOf(time.Now()).Year
+	if year != "" {
+		if fiscalYear, err = strconv.Atoi(year); err != nil || fiscalYear < 1900 || fiscalYear > 2200 {
+			return nil, ErrInvalidFiscalYear
+		}
+	}
+
+	response := &FiscalCalendarResponse{
+		Calendar: domain.FiscalCalendar{StartMonth: time.January, Pattern: domain.FiscalMonths},
+		Year:     calendar.Year(fiscalYear),
+		Periods:  calendar.Periods(fiscalYear),
+	}
+	if calendar != nil {
+		response.Calendar = *calendar
+	}
+	return response, nil
+}
+
 func (s *service) DeleteCompany(ctx context.Context, id string) (*CompanyResponse, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -250,7 +380,10 @@ func (s *service) DeleteCompany(ctx context.Context, id string) (*CompanyRespons
 		return nil, err
 	}
 
-	if err := s.companyRepo.Delete(ctx, objectID); err != nil {
+	err = s.saveWithEvent(ctx, domain.EventCompanyDeleted, company, func(ctx context.Context) error {
+		return s.companyRepo.Delete(ctx, objectID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -258,16 +391,172 @@ func (s *service) DeleteCompany(ctx context.Context, id string) (*CompanyRespons
 	return &response, nil
 }
 
-// getUsersByIDs retrieves users by their IDs, skipping any that are not found
+func (s *service) UploadLogo(ctx context.Context, id string, r io.Reader, contentType string) (*CompanyResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+
+	company, err := s.companyRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new key per upload lets the image handler cache responses forever
+	prefix := fmt.Sprintf("images/companies/%s/logo-%d", objectID.Hex(), time.Now().UnixNano())
+	logo, err := storage.UploadImage(ctx, s.store, prefix, r, contentType, storage.LogoPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := []*string{company.ProfilePicture, company.ProfilePictureThumb}
+	full, thumb := storage.ImagePath(logo.Full.Key), storage.ImagePath(logo.Thumb.Key)
+	company.ProfilePicture = &full
+	company.ProfilePictureThumb = &thumb
+
+	err = s.saveWithEvent(ctx, domain.EventCompanyUpdated, company, func(ctx context.Context) error {
+		return s.companyRepo.Update(ctx, objectID, company)
+	})
+	if err != nil {
+		s.deleteImages(ctx, &full, &thumb)
+		return nil, err
+	}
+	s.deleteImages(ctx, previous...)
+	utils.GetCache().Delete(fmt.Sprintf("company:%s", id))
+
+	return s.buildCompanyResponse(ctx, company)
+}
+
+// deleteImages removes stored image variants by path. Paths not produced by UploadLogo, such as
+// legacy URLs, are skipped; failures only leave orphaned objects, so they are logged and ignored.
+func (s *service) deleteImages(ctx context.Context, paths ...*string) {
+	for _, p := range paths {
+		if p == nil {
+			continue
+		}
+		if key, ok := storage.ImageKey(*p); ok {
+			if err := s.store.Delete(ctx, key); err != nil {
+				log.Warnf(ctx, "Failed to delete image %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// absolutePictureURLs converts relative logo paths to absolute URLs
+func absolutePictureURLs(company *domain.Company) {
+	for _, picture := range []**string{&company.ProfilePicture, &company.ProfilePictureThumb} {
+		if *picture != nil && !strings.HasPrefix(**picture, "http") {
+			fullURL := "http://152.42.172.219:8787" + **picture
+			*picture = &fullURL
+		}
+	}
+}
+
+// isCurrentPicture reports whether value is the company's logo as stored or as returned in responses
+func isCurrentPicture(company *domain.Company, value string) bool {
+	if company.ProfilePicture == nil {
+		return false
+	}
+	returned := *company
+	absolutePictureURLs(&returned)
+	return value == *company.ProfilePicture || value == *returned.ProfilePicture
+}
+
+// saveWithEvent runs save and records the company event in the same transaction, then drops
+// the cached responses rendering companies
+func (s *service) saveWithEvent(ctx context.Context, eventType domain.EventType, company *domain.Company, save func(ctx context.Context) error) error {
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := save(ctx); err != nil {
+			return err
+		}
+
+		event, err := domain.NewEvent(eventType, company.ID, ToCompanyResponse(company))
+		if err != nil {
+			return errors.New("EVENT_ENCODING_ERROR", "Failed to encode company event", 500, err, nil)
+		}
+		event.Company = &company.ID
+		return s.outboxRepo.Append(ctx, event)
+	})
+	if err == nil {
+		middleware.InvalidateResponses(middleware.ResponseCacheCompanies, middleware.ResponseCacheReports)
+	}
+	return err
+}
+
+// getUsersByIDs retrieves users by their IDs in that order, skipping any that are not found
 func (s *service) getUsersByIDs(ctx context.Context, userIDs []primitive.ObjectID) ([]*domain.User, error) {
+	found, err := s.loadUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pickUsers(found, userIDs), nil
+}
+
+// loadUsers reads users with $in queries of at most userBatchSize IDs, running up to
+// populationConcurrency of them at once, so populating a list costs a few round trips
+// rather than one per user.
+func (s *service) loadUsers(ctx context.Context, userIDs []primitive.ObjectID) (map[primitive.ObjectID]*domain.User, error) {
+	seen := make(map[primitive.ObjectID]bool, len(userIDs))
+	ids := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	batches := make([][]*domain.User, (len(ids)+userBatchSize-1)/userBatchSize)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(populationConcurrency)
+	for i := range batches {
+		batch := ids[i*userBatchSize : min((i+1)*userBatchSize, len(ids))]
+		g.Go(func() error {
+			users, err := s.userRepo.GetByIDs(gctx, batch)
+			batches[i] = users
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	found := make(map[primitive.ObjectID]*domain.User, len(ids))
+	for _, users := range batches {
+		for _, user := range users {
+			found[user.ID] = user
+		}
+	}
+	return found, nil
+}
+
+// pickUsers returns the users of found with the given IDs in that order
+func pickUsers(found map[primitive.ObjectID]*domain.User, userIDs []primitive.ObjectID) []*domain.User {
 	users := make([]*domain.User, 0, len(userIDs))
-	for _, userID := range userIDs {
-		user, err := s.userRepo.GetByID(ctx, userID)
-		if err == nil {
+	for _, id := range userIDs {
+		if user, ok := found[id]; ok {
 			users = append(users, user)
 		}
 	}
-	return users, nil
+	return users
+}
+
+// populateCompanies converts companies to responses with their users, read together
+func (s *service) populateCompanies(ctx context.Context, companies []*domain.Company) []*CompanyResponse {
+	var userIDs []primitive.ObjectID
+	for _, company := range companies {
+		userIDs = append(userIDs, company.User...)
+	}
+	found, err := s.loadUsers(ctx, userIDs)
+
+	responses := make([]*CompanyResponse, len(companies))
+	for i, company := range companies {
+		response := ToCompanyResponse(company)
+		if err == nil {
+			response = ToCompanyResponseWithUsers(company, pickUsers(found, company.User))
+		}
+		responses[i] = &response
+	}
+	return responses
 }
 
 func (s *service) GetCompanyByName(ctx context.Context, name string) (*CompanyResponse, error) {
@@ -293,11 +582,7 @@ func (s *service) GetCompanyByName(ctx context.Context, name string) (*CompanyRe
 
 // buildCompanyResponse creates a company response with populated users and processed URLs
 func (s *service) buildCompanyResponse(ctx context.Context, company *domain.Company) (*CompanyResponse, error) {
-	// Convert relative URLs to absolute URLs
-	if company.ProfilePicture != nil && !strings.HasPrefix(*company.ProfilePicture, "http") {
-		fullURL := "http://152.42.172.219:8787" + *company.ProfilePicture
-		company.ProfilePicture = &fullURL
-	}
+	absolutePictureURLs(company)
 
 	users, err := s.getUsersByIDs(ctx, company.User)
 	if err != nil {
@@ -43,6 +43,16 @@ func (m *mockCompanyRepository) GetByName(ctx context.Context, name string) (*do
 	return nil, ErrCompanyNotFound
 }
 
+func (m *mockCompanyRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.Company, error) {
+	var result []*domain.Company
+	for _, id := range ids {
+		if company, err := m.GetByID(ctx, id); err == nil {
+			result = append(result, company)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockCompanyRepository) GetAll(ctx context.Context) ([]*domain.Company, error) {
 	var result []*domain.Company
 	for i := range m.companies {
@@ -51,6 +61,15 @@ func (m *mockCompanyRepository) GetAll(ctx context.Context) ([]*domain.Company,
 	return result, nil
 }
 
+func (m *mockCompanyRepository) Each(ctx context.Context, fn func(*domain.Company) error) error {
+	for i := range m.companies {
+		if err := fn(&m.companies[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *mockCompanyRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*domain.Company, error) {
 	var result []*domain.Company
 	for i := range m.companies {
@@ -100,9 +119,12 @@ func (m *mockCompanyRepository) SearchByName(ctx context.Context, name string) (
 
 type mockUserRepository struct {
 	users []domain.User
+	// latency is the simulated round trip of every query
+	latency time.Duration
 }
 
 func (m *mockUserRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	time.Sleep(m.latency)
 	for i := range m.users {
 		if m.users[i].ID == id {
 			return &m.users[i], nil
@@ -115,18 +137,62 @@ func (m *mockUserRepository) Create(ctx context.Context, user *domain.User) erro
 func (m *mockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	return nil, nil
 }
+func (m *mockUserRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.User, error) {
+	time.Sleep(m.latency)
+	var result []*domain.User
+	for _, id := range ids {
+		for i := range m.users {
+			if m.users[i].ID == id {
+				result = append(result, &m.users[i])
+			}
+		}
+	}
+	return result, nil
+}
 func (m *mockUserRepository) GetAll(ctx context.Context) ([]*domain.User, error) { return nil, nil }
+func (m *mockUserRepository) GetPage(ctx context.Context, query domain.UserQuery) ([]*domain.User, int, error) {
+	return nil, 0, nil
+}
+func (m *mockUserRepository) Each(ctx context.Context, fn func(*domain.User) error) error {
+	return nil
+}
 func (m *mockUserRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
 	return nil
 }
 func (m *mockUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error { return nil }
-func (m *mockUserRepository) SetResetToken(ctx context.Context, email, token string, expires time.Time) error {
+
+type mockOutboxRepository struct {
+	events []*domain.Event
+}
+
+func (m *mockOutboxRepository) Append(ctx context.Context, event *domain.Event) error {
+	event.ID = primitive.NewObjectID()
+	m.events = append(m.events, event)
 	return nil
 }
-func (m *mockUserRepository) GetByResetToken(ctx context.Context, token string) (*domain.User, error) {
+
+func (m *mockOutboxRepository) Claim(ctx context.Context, lockedUntil time.Time) (*domain.Event, error) {
 	return nil, nil
 }
 
+func (m *mockOutboxRepository) CountPending(ctx context.Context) (int64, error) {
+	return int64(len(m.events)), nil
+}
+
+func (m *mockOutboxRepository) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+func (m *mockOutboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, deliveredTo []string, nextAttemptAt time.Time, final bool) error {
+	return nil
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestCompanyService_CreateCompany(t *testing.T) {
 	// Setup test user
 	testUserID := primitive.NewObjectID()
@@ -199,7 +265,7 @@ func TestCompanyService_CreateCompany(t *testing.T) {
 			mockUserRepo := &mockUserRepository{}
 			tt.setupData(mockCompanyRepo, mockUserRepo)
 
-			service := NewService(mockCompanyRepo, mockUserRepo)
+			service := NewService(mockCompanyRepo, mockUserRepo, &mockOutboxRepository{}, mockTransactor{}, nil)
 
 			// Execute
 			response, err := service.CreateCompany(context.Background(), tt.request)
@@ -248,7 +314,7 @@ func TestCompanyService_GetCompanies(t *testing.T) {
 	}
 	mockCompanyRepo.companies = append(mockCompanyRepo.companies, testCompany)
 
-	service := NewService(mockCompanyRepo, mockUserRepo)
+	service := NewService(mockCompanyRepo, mockUserRepo, &mockOutboxRepository{}, mockTransactor{}, nil)
 
 	// Execute
 	companies, err := service.GetCompanies(context.Background())
@@ -289,7 +355,7 @@ func TestCompanyService_GetCompanyByID(t *testing.T) {
 	}
 	mockCompanyRepo.companies = append(mockCompanyRepo.companies, testCompany)
 
-	service := NewService(mockCompanyRepo, mockUserRepo)
+	service := NewService(mockCompanyRepo, mockUserRepo, &mockOutboxRepository{}, mockTransactor{}, nil)
 
 	tests := []struct {
 		name        string
@@ -367,7 +433,7 @@ func TestCompanyService_GetCompaniesPerformance(t *testing.T) {
 		mockCompanyRepo.companies = append(mockCompanyRepo.companies, company)
 	}
 
-	service := NewService(mockCompanyRepo, mockUserRepo)
+	service := NewService(mockCompanyRepo, mockUserRepo, &mockOutboxRepository{}, mockTransactor{}, nil)
 
 	// First call (no cache)
 	start := time.Now()
@@ -414,3 +480,71 @@ func TestCompanyService_GetCompaniesPerformance(t *testing.T) {
 		t.Errorf("Cached call too slow: %v", secondCallDuration)
 	}
 }
+
+// Populating 200 companies of 5 users each, with a simulated 200µs database round trip
+func BenchmarkCompanyService_PopulateCompanies(b *testing.B) {
+	mockUserRepo := &mockUserRepository{latency: 200 * time.Microsecond}
+	for i := 0; i < 1000; i++ {
+		mockUserRepo.users = append(mockUserRepo.users, domain.User{ID: primitive.NewObjectID(), Name: fmt.Sprintf("User %d", i)})
+	}
+	companies := make([]*domain.Company, 200)
+	for i := range companies {
+		companies[i] = &domain.Company{ID: primitive.NewObjectID(), Name: fmt.Sprintf("Company %d", i)}
+		for j := 0; j < 5; j++ {
+			companies[i].User = append(companies[i].User, mockUserRepo.users[(i*5+j)%len(mockUserRepo.users)].ID)
+		}
+	}
+
+	s := NewService(&mockCompanyRepository{}, mockUserRepo, &mockOutboxRepository{}, mockTransactor{}, nil).(*service)
+
+	// One query per user, as companies were populated before
+	b.Run("PerUser", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, company := range companies {
+				var users []*domain.User
+				for _, id := range company.User {
+					if user, err := mockUserRepo.GetByID(context.Background(), id); err == nil {
+						users = append(users, user)
+					}
+				}
+				ToCompanyResponseWithUsers(company, users)
+			}
+		}
+	})
+
+	b.Run("Batched", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			s.populateCompanies(context.Background(), companies)
+		}
+	})
+}
+
+func TestCompanyService_PopulateCompaniesKeepsUserOrder(t *testing.T) {
+	mockUserRepo := &mockUserRepository{}
+	var ids []primitive.ObjectID
+	for i := 0; i < userBatchSize+10; i++ {
+		user := domain.User{ID: primitive.NewObjectID(), Name: fmt.Sprintf("User %d", i)}
+		mockUserRepo.users = append(mockUserRepo.users, user)
+		ids = append([]primitive.ObjectID{user.ID}, ids...)
+	}
+	missing := primitive.NewObjectID()
+	companies := []*domain.Company{
+		{ID: primitive.NewObjectID(), User: ids},
+		{ID: primitive.NewObjectID(), User: []primitive.ObjectID{ids[3], missing, ids[1]}},
+	}
+
+	s := NewService(&mockCompanyRepository{}, mockUserRepo, &mockOutboxRepository{}, mockTransactor{}, nil).(*service)
+	responses := s.populateCompanies(context.Background(), companies)
+
+	if len(responses[0].User) != len(ids) {
+		t.Fatalf("Expected %d users, got %d", len(ids), len(responses[0].User))
+	}
+	for i, id := range ids {
+		if responses[0].User[i].ID != id.Hex() {
+			t.Fatalf("User %d out of order", i)
+		}
+	}
+	if len(responses[1].User) != 2 || responses[1].User[0].ID != ids[3].Hex() || responses[1].User[1].ID != ids[1].Hex() {
+		t.Errorf("Expected the two existing users in company order, got %+v", responses[1].User)
+	}
+}
@@ -0,0 +1,11 @@
+package dashboard
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidCompanyID = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidYear      = errors.New("INVALID_YEAR", "Year format is invalid", http.StatusBadRequest, nil, nil)
+)
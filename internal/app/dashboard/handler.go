@@ -0,0 +1,43 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the dashboard routes
+// @Tags Dashboard
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/dashboard/summary", h.GetSummaries).Methods("GET")
+}
+
+// GetSummaries lists the key figures of each company's reports per year: how many there are,
+// by report type, and in which currencies. They are precomputed on every report write.
+// @Param company query string false "Only this company's summaries"
+// @Param year query integer false "Only this year's summaries"
+func (h *Handler) GetSummaries(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.service.GetSummaries(r.Context(), SummaryRequest{
+		Company: r.URL.Query().Get("company"),
+		Year:    r.URL.Query().Get("year"),
+	})
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, summaries)
+}
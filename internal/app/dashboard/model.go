@@ -0,0 +1,54 @@
+package dashboard
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// SummaryRequest selects summaries; empty fields select every company or year.
+type SummaryRequest struct {
+	Company string
+	Year    string
+}
+
+// SummaryResponse holds the key figures of a company's reports of one year.
+type SummaryResponse struct {
+	Company       NamedRef          `json:"company"`
+	Year          int               `json:"year"`
+	Period        domain.FiscalYear `json:"period"` // when the year runs in the company's fiscal calendar
+	ReportCount   int               `json:"reportCount"`
+	ReportTypes   []ReportTypeCount `json:"reportTypes"`
+	Currencies    []string          `json:"currencies"`
+	LastUpdatedAt time.Time         `json:"lastUpdatedAt"`
+}
+
+// NamedRef is a populated reference; Name is empty when the document no longer exists.
+type NamedRef struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+type ReportTypeCount struct {
+	ReportType NamedRef `json:"reportType"`
+	Count      int      `json:"count"`
+}
+
+// ToSummaryResponse converts a summary, naming its company and report types with the given names
+// by ID and dating its year with the company's fiscal calendar
+func ToSummaryResponse(summary *domain.ReportSummary, names map[string]string, calendar *domain.FiscalCalendar) *SummaryResponse {
+	response := &SummaryResponse{
+		Company:       NamedRef{ID: summary.Company.Hex(), Name: names[summary.Company.Hex()]},
+		Year:          summary.Year,
+		Period:        calendar.Year(summary.Year),
+		ReportCount:   summary.ReportCount,
+		ReportTypes:   make([]ReportTypeCount, len(summary.ReportTypes)),
+		Currencies:    summary.Currencies,
+		LastUpdatedAt: summary.LastUpdatedAt,
+	}
+	for i, count := range summary.ReportTypes {
+		id := count.ReportType.Hex()
+		response.ReportTypes[i] = ReportTypeCount{ReportType: NamedRef{ID: id, Name: names[id]}, Count: count.Count}
+	}
+	return response
+}
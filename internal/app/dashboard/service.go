@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"context"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+)
+
+type Service interface {
+	// GetSummaries returns the report summaries the caller may see, newest year first
+	GetSummaries(ctx context.Context, req SummaryRequest) ([]*SummaryResponse, error)
+}
+
+type service struct {
+	summaryRepo    domain.ReportSummaryRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+}
+
+func NewService(summaryRepo domain.ReportSummaryRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	return &service{
+		summaryRepo:    summaryRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+}
+
+func (s *service) GetSummaries(ctx context.Context, req SummaryRequest) ([]*SummaryResponse, error) {
+	var companyIDs []primitive.ObjectID
+	if req.Company != "" {
+		companyID, err := primitive.ObjectIDFromHex(req.Company)
+		if err != nil {
+			return nil, ErrInvalidCompanyID
+		}
+		companyIDs = append(companyIDs, companyID)
+	}
+
+	var year int
+	if req.Year != "" {
+		var err error
+		if year, err = strconv.Atoi(req.Year); err != nil || year <= 0 {
+			return nil, ErrInvalidYear
+		}
+	}
+
+	summaries, err := s.summaryRepo.List(ctx, companyIDs, year)
+	if err != nil {
+		return nil, err
+	}
+
+	names, calendars, err := s.lookup(ctx, summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*SummaryResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = ToSummaryResponse(summary, names, calendars[summary.Company])
+	}
+	return responses, nil
+}
+
+// lookup maps the IDs of the companies and report types of summaries to their names, and the
+// companies to their fiscal calendars. Report types are few and cached, so they are all read.
+func (s *service) lookup(ctx context.Context, summaries []*domain.ReportSummary) (map[string]string, map[primitive.ObjectID]*domain.FiscalCalendar, error) {
+	names := make(map[string]string)
+	calendars := make(map[primitive.ObjectID]*domain.FiscalCalendar)
+
+	seen := make(map[primitive.ObjectID]bool)
+	var companyIDs []primitive.ObjectID
+	for _, summary := range summaries {
+		if !seen[summary.Company] {
+			seen[summary.Company] = true
+			companyIDs = append(companyIDs, summary.Company)
+		}
+	}
+	if len(companyIDs) > 0 {
+		companies, err := s.companyRepo.GetByIDs(ctx, companyIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, company := range companies {
+			names[company.ID.Hex()] = company.Name
+			calendars[company.ID] = company.FiscalCalendar
+		}
+	}
+
+	reportTypes, err := s.reportTypeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, reportType := range reportTypes {
+		names[reportType.ID.Hex()] = reportType.Name
+	}
+	return names, calendars, nil
+}
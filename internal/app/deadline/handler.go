@@ -0,0 +1,149 @@
+package deadline
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers report deadline routes
+// @Tags Deadlines
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	// Registered first so /overdue isn't taken for a deadline ID
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("list", "overdue"))
+	adminOnly.HandleFunc("/api/deadlines/overdue", h.GetOverdue).Methods("GET")
+
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/deadlines", h.GetDeadlines).Methods("GET")
+	protected.HandleFunc("/api/deadlines", h.CreateDeadline).Methods("POST")
+	protected.HandleFunc("/api/deadlines/{id}", h.GetDeadlineByID).Methods("GET")
+	protected.HandleFunc("/api/deadlines/{id}", h.UpdateDeadline).Methods("PUT")
+	protected.HandleFunc("/api/deadlines/{id}", h.DeleteDeadline).Methods("DELETE")
+}
+
+// GetDeadlines lists the report deadlines of the companies the user can see
+// @Param company query string false "Only the deadlines of this company"
+func (h *Handler) GetDeadlines(w http.ResponseWriter, r *http.Request) {
+	deadlines, err := h.service.GetDeadlines(r.Context(), r.URL.Query().Get("company"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, deadlines)
+}
+
+// CreateDeadline expects a report of a type from a company every period, due dueDays after
+// the period closes. Admins manage the deadlines of their companies.
+func (h *Handler) CreateDeadline(w http.ResponseWriter, r *http.Request) {
+	var req CreateDeadlineRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	deadline, err := h.service.CreateDeadline(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":  "Deadline created successfully",
+		"deadline": deadline,
+	})
+}
+
+// @Summary Get report deadline by ID
+func (h *Handler) GetDeadlineByID(w http.ResponseWriter, r *http.Request) {
+	deadline, err := h.service.GetDeadlineByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, deadline)
+}
+
+// @Summary Update report deadline
+func (h *Handler) UpdateDeadline(w http.ResponseWriter, r *http.Request) {
+	var req UpdateDeadlineRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	deadline, err := h.service.UpdateDeadline(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Deadline updated successfully",
+		"deadline": deadline,
+	})
+}
+
+// @Summary Delete report deadline
+func (h *Handler) DeleteDeadline(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteDeadline(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOverdue lists the reports missing past their due date in the last 12 periods of every
+// deadline the admin can see, oldest first
+func (h *Handler) GetOverdue(w http.ResponseWriter, r *http.Request) {
+	overdue, err := h.service.GetOverdue(r.Context(), time.Now())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, overdue)
+}
+
+// requester is the ID of the user making the request, recorded as the creator of deadlines.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
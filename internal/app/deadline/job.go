@@ -0,0 +1,43 @@
+package deadline
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Job sends deadline reminders on a fixed interval.
+type Job struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewJob(service Service, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run sends reminders until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			result, err := j.service.SendReminders(ctx, now)
+			if err != nil {
+				log.Errorf(ctx, "Deadlines: reminder run failed: %v", err)
+				continue
+			}
+			if result.Reminded > 0 {
+				log.Infof(ctx, "Deadlines: reminded of %d reports, sent %d, failed %d", result.Reminded, result.Sent, result.Failed)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package deadline
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateDeadlineRequest struct {
+	Company    string `json:"company" validate:"required"`
+	ReportType string `json:"reportType" validate:"required"`
+	Frequency  string `json:"frequency" validate:"required,oneof=MONTHLY QUARTERLY YEARLY"`
+	DueDays    int    `json:"dueDays" validate:"min=0,max=366"`
+	RemindDays int    `json:"remindDays" validate:"min=0,max=366"`
+}
+
+type UpdateDeadlineRequest struct {
+	ReportType *string `json:"reportType,omitempty"`
+	Frequency  *string `json:"frequency,omitempty" validate:"omitempty,oneof=MONTHLY QUARTERLY YEARLY"`
+	DueDays    *int    `json:"dueDays,omitempty" validate:"omitempty,min=0,max=366"`
+	RemindDays *int    `json:"remindDays,omitempty" validate:"omitempty,min=0,max=366"`
+}
+
+// Response DTOs
+type DeadlineResponse struct {
+	ID           string                   `json:"id"`
+	Company      string                   `json:"company"`
+	ReportType   string                   `json:"reportType"`
+	Frequency    domain.DeadlineFrequency `json:"frequency"`
+	DueDays      int                      `json:"dueDays"`
+	RemindDays   int                      `json:"remindDays"`
+	LastReminder *domain.DeadlineReminder `json:"lastReminder,omitempty"`
+	CreatedBy    string                   `json:"createdBy"`
+	CreatedAt    time.Time                `json:"createdAt"`
+	UpdatedAt    time.Time                `json:"updatedAt"`
+}
+
+// OverdueReportResponse is a period of a deadline whose report was not submitted by its due date.
+type OverdueReportResponse struct {
+	Deadline    string                   `json:"deadline"`
+	Company     NamedRef                 `json:"company"`
+	ReportType  NamedRef                 `json:"reportType"`
+	Frequency   domain.DeadlineFrequency `json:"frequency"`
+	Period      domain.ReportPeriod      `json:"period"`
+	DaysOverdue int                      `json:"daysOverdue"`
+}
+
+type NamedRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ReminderResult counts the outcome of one reminder run.
+type ReminderResult struct {
+	Reminded int `json:"reminded"` // deadlines reminded of
+	Sent     int `json:"sent"`     // emails
+	Failed   int `json:"failed"`
+}
+
+func ToDeadlineResponse(deadline *domain.ReportDeadline) *DeadlineResponse {
+	return &DeadlineResponse{
+		ID:           deadline.ID.Hex(),
+		Company:      deadline.Company.Hex(),
+		ReportType:   deadline.ReportType.Hex(),
+		Frequency:    deadline.Frequency,
+		DueDays:      deadline.DueDays,
+		RemindDays:   deadline.RemindDays,
+		LastReminder: deadline.LastReminder,
+		CreatedBy:    deadline.CreatedBy.Hex(),
+		CreatedAt:    deadline.CreatedAt,
+		UpdatedAt:    deadline.UpdatedAt,
+	}
+}
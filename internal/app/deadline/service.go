@@ -0,0 +1,418 @@
+package deadline
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// trackedPeriods is how many past periods of a deadline are checked for missing reports.
+const trackedPeriods = 12
+
+type Service interface {
+	CreateDeadline(ctx context.Context, req CreateDeadlineRequest, createdBy primitive.ObjectID) (*DeadlineResponse, error)
+	// GetDeadlines lists the deadlines of a company, or of every company the caller can see
+	// when company is empty.
+	GetDeadlines(ctx context.Context, company string) ([]*DeadlineResponse, error)
+	GetDeadlineByID(ctx context.Context, id string) (*DeadlineResponse, error)
+	UpdateDeadline(ctx context.Context, id string, req UpdateDeadlineRequest) (*DeadlineResponse, error)
+	DeleteDeadline(ctx context.Context, id string) error
+	// GetOverdue lists the periods whose reports are missing past their due date, oldest first.
+	GetOverdue(ctx context.Context, now time.Time) ([]*OverdueReportResponse, error)
+	// SendReminders emails the users of companies with reports due soon or overdue, once per
+	// period and stage, and publishes report.overdue events.
+	SendReminders(ctx context.Context, now time.Time) (*ReminderResult, error)
+}
+
+type service struct {
+	deadlineRepo   domain.DeadlineRepository
+	reportRepo     domain.ReportRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+	userRepo       domain.UserRepository
+	outboxRepo     domain.OutboxRepository
+	emailService   utils.EmailService
+}
+
+func NewService(deadlineRepo domain.DeadlineRepository, reportRepo domain.ReportRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository, userRepo domain.UserRepository, outboxRepo domain.OutboxRepository, emailService utils.EmailService) Service {
+	return &service{
+		deadlineRepo:   deadlineRepo,
+		reportRepo:     reportRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+		userRepo:       userRepo,
+		outboxRepo:     outboxRepo,
+		emailService:   emailService,
+	}
+}
+
+func (s *service) CreateDeadline(ctx context.Context, req CreateDeadlineRequest, createdBy primitive.ObjectID) (*DeadlineResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(req.Company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	reportTypeID, err := primitive.ObjectIDFromHex(req.ReportType)
+	if err != nil {
+		return nil, ErrInvalidReportTypeID
+	}
+
+	// Both must exist; the company also has to be one the caller can see
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+	if _, err := s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, companyID); err != nil {
+		return nil, err
+	}
+
+	deadline := &domain.ReportDeadline{
+		Company:    companyID,
+		ReportType: reportTypeID,
+		Frequency:  domain.DeadlineFrequency(req.Frequency),
+		DueDays:    req.DueDays,
+		RemindDays: req.RemindDays,
+		CreatedBy:  createdBy,
+	}
+	if err := s.deadlineRepo.Create(ctx, deadline); err != nil {
+		return nil, err
+	}
+
+	return ToDeadlineResponse(deadline), nil
+}
+
+func (s *service) GetDeadlines(ctx context.Context, company string) ([]*DeadlineResponse, error) {
+	var companyID *primitive.ObjectID
+	if company != "" {
+		id, err := primitive.ObjectIDFromHex(company)
+		if err != nil {
+			return nil, ErrInvalidCompanyID
+		}
+		companyID = &id
+	}
+
+	deadlines, err := s.deadlineRepo.GetAll(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*DeadlineResponse, len(deadlines))
+	for i, deadline := range deadlines {
+		responses[i] = ToDeadlineResponse(deadline)
+	}
+	return responses, nil
+}
+
+func (s *service) GetDeadlineByID(ctx context.Context, id string) (*DeadlineResponse, error) {
+	deadline, err := s.getDeadline(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToDeadlineResponse(deadline), nil
+}
+
+func (s *service) UpdateDeadline(ctx context.Context, id string, req UpdateDeadlineRequest) (*DeadlineResponse, error) {
+	deadline, err := s.getDeadline(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, deadline.Company); err != nil {
+		return nil, err
+	}
+
+	if req.ReportType != nil {
+		reportTypeID, err := primitive.ObjectIDFromHex(*req.ReportType)
+		if err != nil {
+			return nil, ErrInvalidReportTypeID
+		}
+		if _, err := s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+			return nil, err
+		}
+		deadline.ReportType = reportTypeID
+	}
+	if req.Frequency != nil {
+		deadline.Frequency = domain.DeadlineFrequency(*req.Frequency)
+	}
+	if req.DueDays != nil {
+		deadline.DueDays = *req.DueDays
+	}
+	if req.RemindDays != nil {
+		deadline.RemindDays = *req.RemindDays
+	}
+
+	if err := s.deadlineRepo.Update(ctx, deadline.ID, deadline); err != nil {
+		return nil, err
+	}
+	return ToDeadlineResponse(deadline), nil
+}
+
+func (s *service) DeleteDeadline(ctx context.Context, id string) error {
+	deadline, err := s.getDeadline(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, deadline.Company); err != nil {
+		return err
+	}
+	return s.deadlineRepo.Delete(ctx, deadline.ID)
+}
+
+func (s *service) getDeadline(ctx context.Context, id string) (*domain.ReportDeadline, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidDeadlineID
+	}
+	return s.deadlineRepo.GetByID(ctx, objectID)
+}
+
+// authorize checks that the caller may manage the deadlines of the company.
+func authorize(ctx context.Context, companyID primitive.ObjectID) error {
+	return middleware.Authorize(ctx, "manage", policy.Resource{Type: "deadline", Companies: []string{companyID.Hex()}})
+}
+
+func (s *service) GetOverdue(ctx context.Context, now time.Time) ([]*OverdueReportResponse, error) {
+	deadlines, err := s.deadlineRepo.GetAll(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := s.lookup(ctx, deadlines)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := s.reportsOf(ctx)
+	overdue := []*OverdueReportResponse{}
+	for _, deadline := range deadlines {
+		companyReports, err := reports(deadline.Company)
+		if err != nil {
+			return nil, err
+		}
+		calendar := refs.calendar(deadline.Company)
+		for _, period := range deadline.ClosedPeriods(calendar, now, trackedPeriods) {
+			if now.Before(period.DueAt) || submitted(calendar, deadline, period, companyReports) {
+				continue
+			}
+			overdue = append(overdue, &OverdueReportResponse{
+				Deadline:    deadline.ID.Hex(),
+				Company:     NamedRef{ID: deadline.Company.Hex(), Name: refs.companyName(deadline.Company)},
+				ReportType:  NamedRef{ID: deadline.ReportType.Hex(), Name: refs.reportTypes[deadline.ReportType]},
+				Frequency:   deadline.Frequency,
+				Period:      period,
+				DaysOverdue: int(now.Sub(period.DueAt).Hours() / 24),
+			})
+		}
+	}
+
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].Period.DueAt.Before(overdue[j].Period.DueAt)
+	})
+	return overdue, nil
+}
+
+// SendReminders only looks at the latest closed period of each deadline; older missing
+// reports were reminded of when they were due and are listed by GetOverdue.
+func (s *service) SendReminders(ctx context.Context, now time.Time) (*ReminderResult, error) {
+	deadlines, err := s.deadlineRepo.GetAll(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReminderResult{}
+	if len(deadlines) == 0 {
+		return result, nil
+	}
+
+	refs, err := s.lookup(ctx, deadlines)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each user gets one email listing the reports of all their companies
+	byUser := make(map[primitive.ObjectID][]utils.DueReport)
+
+	reports := s.reportsOf(ctx)
+	for _, deadline := range deadlines {
+		company, ok := refs.companies[deadline.Company]
+		if !ok {
+			continue // deleted
+		}
+
+		periods := deadline.ClosedPeriods(company.FiscalCalendar, now, 1)
+		if len(periods) == 0 {
+			continue
+		}
+		period := periods[0]
+
+		overdue := !now.Before(period.DueAt)
+		if !overdue && (deadline.RemindDays == 0 || now.Before(period.DueAt.AddDate(0, 0, -deadline.RemindDays))) {
+			continue
+		}
+		if last := deadline.LastReminder; last != nil && last.Period == period.Label && (last.Overdue || !overdue) {
+			continue
+		}
+
+		companyReports, err := reports(deadline.Company)
+		if err != nil {
+			return nil, err
+		}
+		if submitted(company.FiscalCalendar, deadline, period, companyReports) {
+			continue
+		}
+
+		dueReport := utils.DueReport{
+			Company:    company.Name,
+			ReportType: refs.reportTypes[deadline.ReportType],
+			Period:     period.Label,
+			DueAt:      period.DueAt,
+			Overdue:    overdue,
+		}
+		for _, userID := range company.User {
+			byUser[userID] = append(byUser[userID], dueReport)
+		}
+
+		if overdue {
+			s.publishOverdue(ctx, deadline, period)
+		}
+		reminder := domain.DeadlineReminder{Period: period.Label, Overdue: overdue, At: now}
+		if err := s.deadlineRepo.SetReminder(ctx, deadline.ID, reminder); err != nil {
+			return result, err
+		}
+		result.Reminded++
+	}
+
+	if len(byUser) == 0 {
+		return result, nil
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(byUser))
+	for userID := range byUser {
+		userIDs = append(userIDs, userID)
+	}
+	users, err := s.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return result, err
+	}
+	for _, user := range users {
+		if !user.Active() {
+			continue
+		}
+		if err := s.emailService.SendDeadlineReminderEmail(user.Email, user.Name, user.Locale, byUser[user.ID]); err != nil {
+			log.Errorf(ctx, "Deadlines: failed to email %s: %v", user.Email, err)
+			result.Failed++
+			continue
+		}
+		result.Sent++
+	}
+
+	return result, nil
+}
+
+// publishOverdue records a report.overdue event for webhooks and realtime clients. Failures
+// are only logged; the reminder email still goes out.
+func (s *service) publishOverdue(ctx context.Context, deadline *domain.ReportDeadline, period domain.ReportPeriod) {
+	event, err := domain.NewEvent(domain.EventReportOverdue, deadline.ID, map[string]interface{}{
+		"deadline":   deadline.ID.Hex(),
+		"company":    deadline.Company.Hex(),
+		"reportType": deadline.ReportType.Hex(),
+		"period":     period,
+	})
+	if err == nil {
+		event.Company = &deadline.Company
+		err = s.outboxRepo.Append(ctx, event)
+	}
+	if err != nil {
+		log.Errorf(ctx, "Deadlines: failed to record overdue report of deadline %s: %v", deadline.ID.Hex(), err)
+	}
+}
+
+// reportsOf returns a loader of the reports of a company, reading each company once.
+func (s *service) reportsOf(ctx context.Context) func(primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	byCompany := make(map[primitive.ObjectID][]*domain.PopulatedReport)
+	return func(companyID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+		if reports, ok := byCompany[companyID]; ok {
+			return reports, nil
+		}
+		reports, err := s.reportRepo.GetByCompany(ctx, companyID)
+		if err != nil {
+			return nil, err
+		}
+		byCompany[companyID] = reports
+		return reports, nil
+	}
+}
+
+func submitted(calendar *domain.FiscalCalendar, deadline *domain.ReportDeadline, period domain.ReportPeriod, reports []*domain.PopulatedReport) bool {
+	for _, report := range reports {
+		if deadline.SubmittedFor(calendar, period, report) {
+			return true
+		}
+	}
+	return false
+}
+
+// deadlineRefs holds the companies and report type names of a set of deadlines.
+type deadlineRefs struct {
+	companies   map[primitive.ObjectID]*domain.Company
+	reportTypes map[primitive.ObjectID]string
+}
+
+// calendar returns the fiscal calendar of a company, nil for calendar years.
+func (r *deadlineRefs) calendar(companyID primitive.ObjectID) *domain.FiscalCalendar {
+	if company, ok := r.companies[companyID]; ok {
+		return company.FiscalCalendar
+	}
+	return nil
+}
+
+func (r *deadlineRefs) companyName(companyID primitive.ObjectID) string {
+	if company, ok := r.companies[companyID]; ok {
+		return company.Name
+	}
+	return ""
+}
+
+// lookup reads the companies of deadlines and the report types. Report types are few and
+// cached, so they are all read.
+func (s *service) lookup(ctx context.Context, deadlines []*domain.ReportDeadline) (*deadlineRefs, error) {
+	r := &deadlineRefs{
+		companies:   make(map[primitive.ObjectID]*domain.Company),
+		reportTypes: make(map[primitive.ObjectID]string),
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	var companyIDs []primitive.ObjectID
+	for _, deadline := range deadlines {
+		if !seen[deadline.Company] {
+			seen[deadline.Company] = true
+			companyIDs = append(companyIDs, deadline.Company)
+		}
+	}
+	if len(companyIDs) > 0 {
+		companies, err := s.companyRepo.GetByIDs(ctx, companyIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, company := range companies {
+			r.companies[company.ID] = company
+		}
+	}
+
+	reportTypes, err := s.reportTypeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, reportType := range reportTypes {
+		r.reportTypes[reportType.ID] = reportType.Name
+	}
+	return r, nil
+}
@@ -0,0 +1,83 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	// checkInterval is the longest an instance waits between checks whether the digest is
+	// due, so one missed while every instance was down goes out soon after they are back.
+	checkInterval = time.Hour
+	// lease is how long an instance may take to send a digest before another one retries it.
+	lease = time.Hour
+)
+
+// Job sends the digest once per interval across all instances. The schedule is stored, and
+// each digest covers the reports changed since the previous one started, so restarts and
+// missed checks neither skip nor repeat a digest.
+type Job struct {
+	service  Service
+	runs     domain.DigestRepository
+	interval time.Duration
+}
+
+func NewJob(service Service, runs domain.DigestRepository, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		runs:     runs,
+		interval: interval,
+	}
+}
+
+// Run sends digests until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(j.interval, checkInterval))
+	defer ticker.Stop()
+
+	j.sendDue(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			j.sendDue(ctx, now)
+		}
+	}
+}
+
+// sendDue sends the digest when the last one started an interval ago and no other instance is
+// sending it.
+func (j *Job) sendDue(ctx context.Context, now time.Time) {
+	run, err := j.runs.Claim(ctx, now.Add(-j.interval), now.Add(lease))
+	if err != nil {
+		log.Errorf(ctx, "Digest: failed to claim run: %v", err)
+		return
+	}
+	if run == nil {
+		return
+	}
+
+	// The first digest covers the interval that just ended
+	since := now.Add(-j.interval)
+	if run.LastRunAt != nil {
+		since = *run.LastRunAt
+	}
+
+	result, err := j.service.Send(ctx, since)
+	if err != nil {
+		log.Errorf(ctx, "Digest: run failed: %v", err)
+		// Released even on shutdown, so another instance retries without waiting out the lease
+		if err := j.runs.Release(context.Background()); err != nil {
+			log.Errorf(ctx, "Digest: failed to release run: %v", err)
+		}
+		return
+	}
+	if err := j.runs.Complete(context.Background(), now); err != nil {
+		log.Errorf(ctx, "Digest: failed to record run: %v", err)
+	}
+	log.Infof(ctx, "Digest: sent %d, skipped %d, failed %d", result.Sent, result.Skipped, result.Failed)
+}
@@ -0,0 +1,127 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// mockDigestRepository claims runs like the real ones, against its own clock.
+type mockDigestRepository struct {
+	run domain.DigestRun
+	now time.Time
+}
+
+func (m *mockDigestRepository) Claim(ctx context.Context, due, lockedUntil time.Time) (*domain.DigestRun, error) {
+	if m.run.LastRunAt != nil && m.run.LastRunAt.After(due) {
+		return nil, nil
+	}
+	if m.run.LockedUntil != nil && !m.run.LockedUntil.Before(m.now) {
+		return nil, nil
+	}
+	before := m.run
+	m.run.LockedUntil = &lockedUntil
+	return &before, nil
+}
+
+func (m *mockDigestRepository) Complete(ctx context.Context, startedAt time.Time) error {
+	m.run = domain.DigestRun{LastRunAt: &startedAt}
+	return nil
+}
+
+func (m *mockDigestRepository) Release(ctx context.Context) error {
+	m.run.LockedUntil = nil
+	return nil
+}
+
+// mockService records the runs it is asked for. While sending it can let another instance
+// check whether the digest is due.
+type mockService struct {
+	since  []time.Time
+	err    error
+	during func()
+}
+
+func (m *mockService) Send(ctx context.Context, since time.Time) (*Result, error) {
+	m.since = append(m.since, since)
+	if m.during != nil {
+		m.during()
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &Result{}, nil
+}
+
+func TestJob_SendDue(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	start := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	repo := &mockDigestRepository{}
+	service := &mockService{}
+	check := func(job *Job, now time.Time) {
+		repo.now = now
+		job.sendDue(context.Background(), now)
+	}
+
+	// The first digest covers the week that just ended
+	check(NewJob(service, repo, week), start)
+	if len(service.since) != 1 || !service.since[0].Equal(start.Add(-week)) {
+		t.Fatalf("Expected a digest since %v, got %v", start.Add(-week), service.since)
+	}
+
+	// A restarted instance doesn't send another before the week is over
+	job := NewJob(service, repo, week)
+	check(job, start.Add(time.Hour))
+	check(job, start.Add(week-time.Hour))
+	if len(service.since) != 1 {
+		t.Fatalf("Expected no digest within the week, got %d", len(service.since)-1)
+	}
+
+	// Checks missed while every instance was down don't lose the reports of those weeks
+	check(job, start.Add(3*week))
+	if len(service.since) != 2 || !service.since[1].Equal(start) {
+		t.Fatalf("Expected a digest since the last one at %v, got %v", start, service.since)
+	}
+	if !repo.run.LastRunAt.Equal(start.Add(3 * week)) {
+		t.Fatalf("Expected the run to be recorded at %v, got %v", start.Add(3*week), repo.run.LastRunAt)
+	}
+}
+
+func TestJob_SendDue_OneInstanceSends(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	now := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	repo := &mockDigestRepository{now: now}
+	other := &mockService{}
+	service := &mockService{during: func() {
+		NewJob(other, repo, week).sendDue(context.Background(), now)
+	}}
+
+	NewJob(service, repo, week).sendDue(context.Background(), now)
+	if len(service.since) != 1 || len(other.since) != 0 {
+		t.Fatalf("Expected one instance to send the digest, got %d and %d", len(service.since), len(other.since))
+	}
+}
+
+func TestJob_SendDue_RetriesFailedRun(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	last := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	now := last.Add(week)
+	repo := &mockDigestRepository{run: domain.DigestRun{LastRunAt: &last}, now: now}
+	service := &mockService{err: errors.New("DATABASE_ERROR", "Failed to get users", 500, nil, nil)}
+	job := NewJob(service, repo, week)
+
+	job.sendDue(context.Background(), now)
+	if repo.run.LockedUntil != nil || !repo.run.LastRunAt.Equal(last) {
+		t.Fatalf("Expected a failed run to be released unrecorded, got %+v", repo.run)
+	}
+
+	service.err = nil
+	repo.now = now.Add(time.Hour)
+	job.sendDue(context.Background(), now.Add(time.Hour))
+	if len(service.since) != 2 || !service.since[1].Equal(last) {
+		t.Fatalf("Expected the retry to cover the same reports since %v, got %v", last, service.since)
+	}
+}
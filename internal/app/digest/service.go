@@ -0,0 +1,147 @@
+package digest
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Result counts the outcome of one digest run.
+type Result struct {
+	Sent    int `json:"sent"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+type Service interface {
+	// Send emails every active, opted-in user a summary of reports created or updated in their companies since the given time.
+	Send(ctx context.Context, since time.Time) (*Result, error)
+}
+
+type service struct {
+	userRepo     domain.UserRepository
+	reportRepo   domain.ReportRepository
+	companyRepo  domain.CompanyRepository
+	emailService utils.EmailService
+	appURL       string
+}
+
+func NewService(userRepo domain.UserRepository, reportRepo domain.ReportRepository, companyRepo domain.CompanyRepository, emailService utils.EmailService, appURL string) Service {
+	return &service{
+		userRepo:     userRepo,
+		reportRepo:   reportRepo,
+		companyRepo:  companyRepo,
+		emailService: emailService,
+		appURL:       strings.TrimRight(appURL, "/"),
+	}
+}
+
+func (s *service) Send(ctx context.Context, since time.Time) (*Result, error) {
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	var recipients []*domain.User
+	var companyIDs []primitive.ObjectID
+	seen := make(map[primitive.ObjectID]bool)
+	for _, user := range users {
+		if !user.Active() || user.Preferences.Notifications.WeeklyDigestDisabled || len(user.Company) == 0 {
+			result.Skipped++
+			continue
+		}
+		recipients = append(recipients, user)
+		for _, companyID := range user.Company {
+			if !seen[companyID] {
+				seen[companyID] = true
+				companyIDs = append(companyIDs, companyID)
+			}
+		}
+	}
+	if len(recipients) == 0 {
+		return result, nil
+	}
+
+	// Users usually share companies, so the reports changed since the last digest are read
+	// once for all of them, with their data for the totals
+	reports, err := s.reportRepo.GetUpdatedSince(domain.WithReportData(ctx), companyIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	byCompany := make(map[primitive.ObjectID][]*domain.PopulatedReport)
+	for _, report := range reports {
+		if report.Company != nil {
+			byCompany[report.Company.ID] = append(byCompany[report.Company.ID], report)
+		}
+	}
+	branding := make(map[primitive.ObjectID]*domain.Branding)
+
+	for _, user := range recipients {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		digest := utils.Digest{Since: since.In(user.Preferences.Location())}
+		for _, companyID := range user.Company {
+			reports := byCompany[companyID]
+			if len(reports) == 0 {
+				continue
+			}
+			if _, ok := branding[companyID]; !ok {
+				branding[companyID] = s.branding(ctx, companyID)
+			}
+
+			// Totals are written as the company writes them, or as the user reads numbers, in
+			// the user's default currency when the report has none
+			formatter := format.ForBranding(branding[companyID], user.Locale)
+			for _, report := range reports {
+				link := utils.ReportLink{Name: report.ReportName, URL: s.appURL + "/reports/" + report.ID.Hex()}
+				if _, amount, ok := domain.ReportTotal(report.ReportData); ok {
+					currency := user.Preferences.Currency
+					if report.Currency != nil {
+						currency = *report.Currency
+					}
+					link.Total = formatter.Amount(amount, currency)
+				}
+				if report.CreatedAt.Before(since) {
+					digest.Updated = append(digest.Updated, link)
+				} else {
+					digest.Created = append(digest.Created, link)
+				}
+			}
+		}
+
+		if len(digest.Created) == 0 && len(digest.Updated) == 0 {
+			result.Skipped++
+			continue
+		}
+
+		if err := s.emailService.SendWeeklyDigestEmail(user.Email, user.Name, user.Locale, digest); err != nil {
+			log.Errorf(ctx, "Digest: failed to email %s: %v", user.Email, err)
+			result.Failed++
+			continue
+		}
+		result.Sent++
+	}
+
+	return result, nil
+}
+
+// branding returns the branding of a company, or nil when it has none or can't be read; the
+// digest then falls back to the default number format.
+func (s *service) branding(ctx context.Context, companyID primitive.ObjectID) *domain.Branding {
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		log.Warnf(ctx, "Digest: formatting totals of company %s unbranded: %v", companyID.Hex(), err)
+		return nil
+	}
+	return company.Branding
+}
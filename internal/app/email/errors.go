@@ -0,0 +1,10 @@
+package email
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrTemplateRequired = errors.New("TEMPLATE_REQUIRED", "Query parameter 'template' is required", http.StatusBadRequest, nil, nil)
+)
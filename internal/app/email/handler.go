@@ -0,0 +1,57 @@
+package email
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers email template routes
+// @Tags Administration
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "email"))
+
+	adminOnly.HandleFunc("/api/admin/emails/templates", h.GetTemplates).Methods("GET")
+	adminOnly.HandleFunc("/api/admin/emails/preview", h.Preview).Methods("GET")
+}
+
+// @Summary List email templates and their locales
+func (h *Handler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	utils.RespondJSON(w, http.StatusOK, h.service.Templates(r.Context()))
+}
+
+// Preview renders a template with sample data without sending it. With format=html the
+// rendered body is returned as a page so it can be opened directly in a browser.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	preview, err := h.service.Preview(r.Context(), query.Get("template"), query.Get("locale"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if query.Get("format") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(preview.HTML))
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, preview)
+}
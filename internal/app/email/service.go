@@ -0,0 +1,140 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// samples holds representative data for every template so previews exercise all branches
+var samples = map[string]interface{}{
+	utils.EmailTemplateForgotPassword: utils.ForgotPasswordEmail{
+		Name:        "Jane Doe",
+		NewPassword: "a1b2c3d4e5f6",
+	},
+	utils.EmailTemplateReportAccess: utils.ReportAccessEmail{
+		Name: "Jane Doe",
+		Reports: []utils.ReportLink{
+			{Name: "Balance Sheet 2024", URL: "https://app.example.com/reports/000000000000000000000001"},
+			{Name: "Profit & Loss Q4", URL: "https://app.example.com/reports/000000000000000000000002"},
+		},
+	},
+	utils.EmailTemplateWeeklyDigest: utils.WeeklyDigestEmail{
+		Name: "Jane Doe",
+		Digest: utils.Digest{
+			Since:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Created: []utils.ReportLink{{Name: "Cash Flow January", URL: "https://app.example.com/reports/000000000000000000000003"}},
+			Updated: []utils.ReportLink{{Name: "Balance Sheet 2024", URL: "https://app.example.com/reports/000000000000000000000001"}},
+		},
+	},
+	utils.EmailTemplateDeadlineReminder: utils.DeadlineReminderEmail{
+		Name: "Jane Doe",
+		Reports: []utils.DueReport{
+			{Company: "Acme Ltd", ReportType: "Profit & Loss", Period: "2024-03", DueAt: time.Date(2024, time.April, 11, 0, 0, 0, 0, time.UTC), Overdue: true},
+			{Company: "Acme Ltd", ReportType: "Balance Sheet", Period: "2024-Q1", DueAt: time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC)},
+		},
+	},
+	utils.EmailTemplateAlert: utils.AlertEmail{
+		Name:    "Jane Doe",
+		Subject: "New sign-in to your account",
+		Message: "Your account was accessed from a new device.",
+		Action:  &utils.EmailAction{Label: "This wasn't me", URL: "https://app.example.com/security/revoke?token=example"},
+	},
+	utils.EmailTemplateMagicLink: utils.MagicLinkEmail{
+		Name:    "Jane Doe",
+		Link:    "https://app.example.com/login/magic?token=example",
+		Minutes: 15,
+	},
+	utils.EmailTemplateInvitation: utils.InvitationEmail{
+		Name:    "Jane Doe",
+		Inviter: "John Smith",
+		Link:    "https://app.example.com/invitations/accept?token=example",
+		Days:    7,
+	},
+}
+
+type Service interface {
+	Preview(ctx context.Context, template, locale string) (*PreviewResponse, error)
+	Templates(ctx context.Context) []TemplateInfo
+}
+
+type service struct {
+	templates *utils.EmailTemplates
+}
+
+func NewService(templates *utils.EmailTemplates) Service {
+	return &service{
+		templates: templates,
+	}
+}
+
+// Preview renders a template with sample data. Template names may be given as
+// file names (forgot_password) or in camelCase (forgotPassword).
+func (s *service) Preview(ctx context.Context, template, locale string) (*PreviewResponse, error) {
+	if template == "" {
+		return nil, ErrTemplateRequired
+	}
+
+	name := snakeCase(template)
+	data, ok := samples[name]
+	if !ok {
+		return nil, errors.New("TEMPLATE_NOT_FOUND", "Unknown email template", 404, nil, map[string]interface{}{
+			"template":  template,
+			"available": s.names(),
+		})
+	}
+
+	locale = utils.NormalizeLocale(locale)
+	subject, body, err := s.templates.Render(name, locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreviewResponse{
+		Template: name,
+		Locale:   locale,
+		Subject:  subject,
+		HTML:     body,
+	}, nil
+}
+
+// Templates lists the previewable templates with the locales available on disk or embedded
+func (s *service) Templates(ctx context.Context) []TemplateInfo {
+	locales := s.templates.Locales()
+
+	infos := make([]TemplateInfo, 0, len(samples))
+	for _, name := range s.names() {
+		infos = append(infos, TemplateInfo{Name: name, Locales: locales})
+	}
+	return infos
+}
+
+func (s *service) names() []string {
+	return []string{
+		utils.EmailTemplateForgotPassword,
+		utils.EmailTemplateReportAccess,
+		utils.EmailTemplateWeeklyDigest,
+		utils.EmailTemplateDeadlineReminder,
+		utils.EmailTemplateAlert,
+		utils.EmailTemplateMagicLink,
+		utils.EmailTemplateInvitation,
+	}
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,13 @@
+package export
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidExportID     = errors.New("INVALID_EXPORT_ID", "Invalid export ID format", http.StatusBadRequest, nil, nil)
+	ErrExportNotFound      = errors.New("EXPORT_NOT_FOUND", "Export not found", http.StatusNotFound, nil, nil)
+	ErrInvalidCompanyID    = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+)
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers export routes
+// @Tags Reports
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/exports", h.CreateExport).Methods("POST")
+	protected.HandleFunc("/api/exports/{id}", h.GetExport).Methods("GET")
+}
+
+// CreateExport queues an XLSX or PDF listing of the reports the user can see, optionally
+// limited to a company, report type or year. Poll GET /api/exports/{id} for the download link.
+func (h *Handler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	var req CreateExportRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	export, err := h.service.CreateExport(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message":   "Export started",
+		"export":    export,
+		"statusUrl": "/api/exports/" + export.ID,
+	})
+}
+
+// GetExport returns the status of an export and, once rendered, a short-lived download link
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request) {
+	export, err := h.service.GetExport(r.Context(), mux.Vars(r)["id"], requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, export)
+}
+
+// requester is the ID of the user making the request, recorded with exports and download links.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
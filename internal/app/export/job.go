@@ -0,0 +1,43 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Job removes the files of expired exports on a fixed interval.
+type Job struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewJob(service Service, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run removes expired files until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := j.service.Expire(ctx)
+			if err != nil {
+				log.Errorf(ctx, "Exports: expiry failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Infof(ctx, "Exports: removed %d expired files", removed)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Status of an export whose file was removed after it expired
+const StatusExpired = "EXPIRED"
+
+// Request DTOs
+type CreateExportRequest struct {
+	Format     string `json:"format" validate:"required,oneof=xlsx pdf"`
+	Company    string `json:"company,omitempty"`
+	ReportType string `json:"reportType,omitempty"`
+	Year       int    `json:"year,omitempty" validate:"omitempty,min=1900,max=2100"`
+}
+
+// Response DTOs
+type ExportResponse struct {
+	ID     string `json:"id"`
+	Format string `json:"format"`
+	// Status is that of the rendering task (PENDING, RUNNING, SUCCEEDED or FAILED), or
+	// EXPIRED once the file was removed
+	Status     string `json:"status"`
+	Progress   int    `json:"progress"`
+	Error      string `json:"error,omitempty"`
+	Company    string `json:"company,omitempty"`
+	ReportType string `json:"reportType,omitempty"`
+	Year       int    `json:"year,omitempty"`
+	Rows       int    `json:"rows"`
+	Size       int64  `json:"size"` // bytes
+	// DownloadURL is a fresh signed link to the file, valid until DownloadExpiresAt
+	DownloadURL       string     `json:"downloadUrl,omitempty"`
+	DownloadExpiresAt *time.Time `json:"downloadExpiresAt,omitempty"`
+	// ExpiresAt is when the file is removed
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// ToExportResponse converts an export, with the status and progress of its task when known
+func ToExportResponse(export *domain.Export, task *domain.Task) *ExportResponse {
+	response := &ExportResponse{
+		ID:        export.ID.Hex(),
+		Format:    export.Format,
+		Status:    string(domain.TaskStatusPending),
+		Year:      export.Filter.Year,
+		Rows:      export.Rows,
+		Size:      export.Size,
+		ExpiresAt: export.ExpiresAt,
+		CreatedAt: export.CreatedAt,
+	}
+	if export.Filter.Company != nil {
+		response.Company = export.Filter.Company.Hex()
+	}
+	if export.Filter.ReportType != nil {
+		response.ReportType = export.Filter.ReportType.Hex()
+	}
+	if task != nil {
+		response.Status = string(task.Status)
+		response.Progress = task.Progress
+		if task.Error != nil {
+			response.Error = *task.Error
+		}
+	}
+	if export.ExpiredAt != nil {
+		response.Status = StatusExpired
+	}
+	return response
+}
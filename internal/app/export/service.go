@@ -0,0 +1,429 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register the decoders of uploaded logos
+	_ "image/png"
+	"io"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/platform/render"
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/platform/tasks"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// keyPrefix is where rendered exports are stored.
+const keyPrefix = "exports/"
+
+// expireBatch is how many expired exports Expire removes per query.
+const expireBatch = 100
+
+// columns heads every export.
+var columns = []string{"Report", "Company", "Report type", "Year", "Currency", "Total", "Created by", "Created at", "Updated at"}
+
+type Service interface {
+	// CreateExport queues the rendering of an export requested by requestedBy.
+	CreateExport(ctx context.Context, req CreateExportRequest, requestedBy primitive.ObjectID) (*ExportResponse, error)
+	// GetExport returns the status of an export, with a fresh download link once it is rendered.
+	GetExport(ctx context.Context, id string, requestedBy primitive.ObjectID) (*ExportResponse, error)
+	// Render writes the file of an export to the object store, reporting progress in percent.
+	Render(ctx context.Context, id primitive.ObjectID, progress func(int)) (*ExportResponse, error)
+	// Expire removes the files of exports that expired and returns how many it removed.
+	Expire(ctx context.Context) (int, error)
+}
+
+type service struct {
+	exportRepo  domain.ExportRepository
+	reportRepo  domain.ReportRepository
+	companyRepo domain.CompanyRepository
+	userRepo    domain.UserRepository
+	taskRepo    domain.TaskRepository
+	tasks       tasks.Enqueuer
+	store       storage.ObjectStore
+	downloads   *storage.Downloads
+	ttl         time.Duration
+}
+
+// NewService returns the export service; rendered files are kept for ttl.
+func NewService(exportRepo domain.ExportRepository, reportRepo domain.ReportRepository, companyRepo domain.CompanyRepository, userRepo domain.UserRepository, taskRepo domain.TaskRepository, enqueuer tasks.Enqueuer, store storage.ObjectStore, downloads *storage.Downloads, ttl time.Duration) Service {
+	return &service{
+		exportRepo:  exportRepo,
+		reportRepo:  reportRepo,
+		companyRepo: companyRepo,
+		userRepo:    userRepo,
+		taskRepo:    taskRepo,
+		tasks:       enqueuer,
+		store:       store,
+		downloads:   downloads,
+		ttl:         ttl,
+	}
+}
+
+func (s *service) CreateExport(ctx context.Context, req CreateExportRequest, requestedBy primitive.ObjectID) (*ExportResponse, error) {
+	filter := domain.ExportFilter{Year: req.Year}
+	if req.Company != "" {
+		companyID, err := primitive.ObjectIDFromHex(req.Company)
+		if err != nil {
+			return nil, ErrInvalidCompanyID
+		}
+		filter.Company = &companyID
+	}
+	if req.ReportType != "" {
+		reportTypeID, err := primitive.ObjectIDFromHex(req.ReportType)
+		if err != nil {
+			return nil, ErrInvalidReportTypeID
+		}
+		filter.ReportType = &reportTypeID
+	}
+
+	now := time.Now()
+	export := &domain.Export{
+		Format:    req.Format,
+		Filter:    filter,
+		Tenant:    domain.TenantOf(ctx),
+		Scope:     domain.AccessScopeOf(ctx),
+		CreatedBy: requestedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	task, err := s.tasks.Enqueue(ctx, TaskRenderExport, RenderExportPayload{ExportID: export.ID.Hex()}, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exportRepo.SetTask(ctx, export.ID, task.ID); err != nil {
+		return nil, err
+	}
+	export.TaskID = task.ID
+
+	return ToExportResponse(export, task), nil
+}
+
+func (s *service) GetExport(ctx context.Context, id string, requestedBy primitive.ObjectID) (*ExportResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidExportID
+	}
+
+	export, err := s.exportRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other users' exports are reported as missing rather than forbidden
+	if err := middleware.Authorize(ctx, "read", policy.Resource{Type: "export", Owners: []string{export.CreatedBy.Hex()}}); err != nil {
+		return nil, ErrExportNotFound
+	}
+
+	var task *domain.Task
+	if !export.TaskID.IsZero() {
+		task, err = s.taskRepo.GetByID(ctx, export.TaskID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := ToExportResponse(export, task)
+	if export.Key != "" && export.ExpiredAt == nil {
+		link, err := s.downloads.Link(ctx, export.Key, requestedBy)
+		if err != nil {
+			return nil, err
+		}
+		// The link must not outlive the file
+		if export.ExpiresAt != nil && link.ExpiresAt.After(*export.ExpiresAt) {
+			link.ExpiresAt = *export.ExpiresAt
+		}
+		response.DownloadURL = link.URL
+		response.DownloadExpiresAt = &link.ExpiresAt
+	}
+	return response, nil
+}
+
+type renderResult struct {
+	rows int
+	err  error
+}
+
+// Render reads the reports with the tenant and access scope of the requester and streams
+// the document straight into the object store.
+func (s *service) Render(ctx context.Context, id primitive.ObjectID, progress func(int)) (*ExportResponse, error) {
+	export, err := s.exportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Tenant != nil {
+		ctx = domain.WithTenant(ctx, export.Tenant)
+	}
+	if export.Scope != nil {
+		ctx = domain.WithAccessScope(ctx, export.Scope)
+	}
+
+	key := fmt.Sprintf("%s%s.%s", keyPrefix, export.ID.Hex(), export.Format)
+
+	pr, pw := io.Pipe()
+	done := make(chan renderResult, 1)
+
+	go func() {
+		rows, err := s.writeReports(ctx, pw, export, progress)
+		pw.CloseWithError(err)
+		done <- renderResult{rows: rows, err: err}
+	}()
+
+	size, putErr := s.store.Put(ctx, key, pr, render.ContentType(export.Format))
+	// Unblock the renderer if the store stopped reading early
+	pr.CloseWithError(io.ErrClosedPipe)
+	result := <-done
+
+	if result.err != nil {
+		return nil, result.err
+	}
+	if putErr != nil {
+		return nil, errors.New("STORAGE_ERROR", "Failed to store export", 500, putErr, nil)
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	if err := s.exportRepo.SetRendered(ctx, export.ID, key, result.rows, size, expiresAt); err != nil {
+		return nil, err
+	}
+	export.Key = key
+	export.Rows = result.rows
+	export.Size = size
+	export.ExpiresAt = &expiresAt
+
+	return ToExportResponse(export, nil), nil
+}
+
+// rowFormat is how an export writes dates and totals.
+type rowFormat struct {
+	dateLayout string
+	location   *time.Location
+	currency   string // of reports without one
+	formatter  *format.Formatter
+}
+
+// writeReports writes the reports selected by the export's filter to w and returns how many
+// it wrote. Exports of one company take its branding, and its number format for totals. Dates
+// are in the timezone of the user who requested the export, and numbers in their language
+// unless the branding sets one.
+func (s *service) writeReports(ctx context.Context, w io.Writer, export *domain.Export, progress func(int)) (int, error) {
+	locale, preferences := s.requesterPreferences(ctx, export.CreatedBy)
+	rf := rowFormat{
+		dateLayout: domain.DateLayout(locale),
+		location:   preferences.Location(),
+		currency:   preferences.Currency,
+		formatter:  format.New(locale, nil),
+	}
+	var style render.Style
+	if export.Filter.Company != nil {
+		style, rf.dateLayout, rf.formatter = s.companyStyle(ctx, *export.Filter.Company, locale)
+	}
+	// Totals are read from the report data
+	ctx = domain.WithReportData(ctx)
+
+	table, err := render.NewTableWriter(w, export.Format, "Reports", columns, style)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	write := func(report *domain.PopulatedReport) error {
+		if export.Filter.Year != 0 && report.Year != export.Filter.Year {
+			return nil
+		}
+		if export.Filter.ReportType != nil && (report.ReportType == nil || report.ReportType.ID != *export.Filter.ReportType) {
+			return nil
+		}
+		if export.Filter.Company != nil && (report.Company == nil || report.Company.ID != *export.Filter.Company) {
+			return nil
+		}
+		rows++
+		return table.WriteRow(reportRow(report, rf))
+	}
+
+	// Narrow filters are read as lists, the rest one report at a time as the cursor yields them
+	var reports []*domain.PopulatedReport
+	switch {
+	case export.Filter.Company != nil:
+		reports, err = s.reportRepo.GetByCompany(ctx, *export.Filter.Company)
+	case export.Filter.ReportType != nil:
+		reports, err = s.reportRepo.GetByReportType(ctx, *export.Filter.ReportType)
+	default:
+		var total int
+		if _, total, err = s.reportRepo.GetAllPaginated(ctx, 0, 1); err != nil {
+			return 0, err
+		}
+		read := 0
+		err = s.reportRepo.Each(ctx, func(report *domain.PopulatedReport) error {
+			read++
+			reportProgress(progress, read, total)
+			return write(report)
+		})
+		if err != nil {
+			return 0, err
+		}
+		return rows, table.Close()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for i, report := range reports {
+		if err := write(report); err != nil {
+			return 0, err
+		}
+		reportProgress(progress, i+1, len(reports))
+	}
+	return rows, table.Close()
+}
+
+// reportProgress reports progress every hundred reports, short of 100 until the file is stored.
+func reportProgress(progress func(int), read, total int) {
+	if read%100 != 0 || total == 0 {
+		return
+	}
+	percent := read * 100 / total
+	if percent > 99 {
+		percent = 99
+	}
+	progress(percent)
+}
+
+// requesterPreferences returns the language and preferences of the user who requested an
+// export. Exports use the defaults rather than fail when the user can't be read.
+func (s *service) requesterPreferences(ctx context.Context, userID primitive.ObjectID) (string, domain.UserPreferences) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Warnf(ctx, "Exports: rendering with default preferences, user %s can't be read: %v", userID.Hex(), err)
+		return "", domain.UserPreferences{}
+	}
+	return user.Locale, user.Preferences
+}
+
+// companyStyle returns the document style, date layout and number format of a company's
+// branding, falling back to locale when it sets none. Exports render unbranded rather than
+// fail when the company or its logo can't be read.
+func (s *service) companyStyle(ctx context.Context, companyID primitive.ObjectID, locale string) (render.Style, string, *format.Formatter) {
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		log.Warnf(ctx, "Exports: rendering unbranded, company %s can't be read: %v", companyID.Hex(), err)
+		return render.Style{}, domain.DateLayout(locale), format.New(locale, nil)
+	}
+	branding := company.Branding
+	if branding == nil {
+		return render.Style{}, domain.DateLayout(locale), format.New(locale, nil)
+	}
+
+	style := render.Style{
+		LogoPlacement: branding.LogoPlacement,
+		PrimaryColor:  branding.PrimaryColor,
+		AccentColor:   branding.AccentColor,
+		FooterText:    branding.FooterText,
+	}
+	if branding.LogoPlacement != domain.LogoNone && company.ProfilePictureThumb != nil {
+		logo, err := s.logo(ctx, *company.ProfilePictureThumb)
+		if err != nil {
+			log.Warnf(ctx, "Exports: rendering without the logo of company %s: %v", companyID.Hex(), err)
+		}
+		style.Logo = logo
+	}
+	if branding.Locale != "" {
+		locale = branding.Locale
+	}
+	return style, domain.DateLayout(locale), format.ForBranding(branding, locale)
+}
+
+// logo reads an uploaded image by its path.
+func (s *service) logo(ctx context.Context, path string) (image.Image, error) {
+	key, ok := storage.ImageKey(path)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a stored image", path)
+	}
+	obj, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	img, _, err := image.Decode(obj)
+	return img, err
+}
+
+func reportRow(report *domain.PopulatedReport, rf rowFormat) []string {
+	var company, reportType, currency, total, createdBy string
+	if report.Company != nil {
+		company = report.Company.Name
+	}
+	if report.ReportType != nil {
+		reportType = report.ReportType.Name
+	}
+	if report.Currency != nil {
+		currency = *report.Currency
+	}
+	if report.CreatedBy != nil {
+		createdBy = report.CreatedBy.Name
+	}
+	if _, amount, ok := domain.ReportTotal(report.ReportData); ok {
+		code := currency
+		if code == "" {
+			code = rf.currency
+		}
+		total = rf.formatter.Amount(amount, code)
+	}
+	return []string{
+		report.ReportName,
+		company,
+		reportType,
+		strconv.Itoa(report.Year),
+		currency,
+		total,
+		createdBy,
+		report.CreatedAt.In(rf.location).Format(rf.dateLayout),
+		report.UpdatedAt.In(rf.location).Format(rf.dateLayout),
+	}
+}
+
+// Expire removes expired files batch by batch. A file that cannot be removed is logged and
+// retried on the next run.
+func (s *service) Expire(ctx context.Context) (int, error) {
+	removed := 0
+	now := time.Now()
+	for {
+		exports, err := s.exportRepo.Expired(ctx, now, expireBatch)
+		if err != nil {
+			return removed, err
+		}
+
+		failed := 0
+		for _, export := range exports {
+			if err := s.store.Delete(ctx, export.Key); err != nil {
+				log.Errorf(ctx, "Exports: removing %s failed: %v", export.Key, err)
+				failed++
+				continue
+			}
+			if err := s.exportRepo.MarkExpired(ctx, export.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		// A short batch was the last one; one that only failed would be read again forever
+		if len(exports) < expireBatch || failed == len(exports) {
+			return removed, nil
+		}
+	}
+}
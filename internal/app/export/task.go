@@ -0,0 +1,34 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/tasks"
+)
+
+// TaskRenderExport runs Render in the background with a RenderExportPayload.
+const TaskRenderExport domain.TaskType = "export.render"
+
+type RenderExportPayload struct {
+	ExportID string `json:"exportId"`
+}
+
+// NewTaskHandler runs TaskRenderExport tasks. Re-running a task renders the file again
+// under the same key, which is harmless.
+func NewTaskHandler(service Service) tasks.Handler {
+	return func(ctx context.Context, task *domain.Task, report func(int)) (interface{}, error) {
+		var payload RenderExportPayload
+		if err := json.Unmarshal(task.Payload, &payload); err != nil {
+			return nil, err
+		}
+		id, err := primitive.ObjectIDFromHex(payload.ExportID)
+		if err != nil {
+			return nil, ErrInvalidExportID
+		}
+		return service.Render(ctx, id, report)
+	}
+}
@@ -0,0 +1,12 @@
+package graph
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidID          = errors.New("INVALID_ID", "Invalid ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidLimit       = errors.New("INVALID_LIMIT", "limit must be between 1 and 100 and offset not negative", http.StatusBadRequest, nil, nil)
+	ErrUserContextMissing = errors.New("USER_CONTEXT_MISSING", "User context not found", http.StatusUnauthorized, nil, nil)
+)
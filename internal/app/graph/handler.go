@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/graphql"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the GraphQL routes
+// @Tags GraphQL
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/graphql", h.Query).Methods("POST")
+	protected.HandleFunc("/graphql/schema", h.GetSchema).Methods("GET")
+}
+
+// Query executes a GraphQL query over users, companies and reports. Errors are reported
+// in the errors array of a 200 response, next to any partial data.
+// @Summary Execute a GraphQL query
+// @ID graphqlQuery
+func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, h.service.Execute(r.Context(), req))
+}
+
+// GetSchema returns the GraphQL schema in SDL, for client code generation
+// @Summary Get the GraphQL schema
+func (h *Handler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(h.service.SDL()))
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/graphql"
+)
+
+// loaders batch the lookups of one request, so a list of N companies costs one query
+// for all their users instead of N.
+type loaders struct {
+	users            *graphql.Loader[primitive.ObjectID, *domain.User]
+	companies        *graphql.Loader[primitive.ObjectID, *domain.Company]
+	reportsByCompany *graphql.Loader[primitive.ObjectID, []*domain.PopulatedReport]
+}
+
+type loadersKey struct{}
+
+func (s *service) newLoaders() *loaders {
+	return &loaders{
+		users: graphql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*domain.User, error) {
+			users, err := s.userRepo.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[primitive.ObjectID]*domain.User, len(users))
+			for _, user := range users {
+				byID[user.ID] = user
+			}
+			return byID, nil
+		}),
+		companies: graphql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*domain.Company, error) {
+			companies, err := s.companyRepo.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[primitive.ObjectID]*domain.Company, len(companies))
+			for _, company := range companies {
+				byID[company.ID] = company
+			}
+			return byID, nil
+		}),
+		reportsByCompany: graphql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID][]*domain.PopulatedReport, error) {
+			reports, err := s.reportRepo.GetByCompanies(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byCompany := make(map[primitive.ObjectID][]*domain.PopulatedReport, len(ids))
+			for _, id := range ids {
+				byCompany[id] = []*domain.PopulatedReport{}
+			}
+			for _, report := range reports {
+				if report.Company != nil {
+					byCompany[report.Company.ID] = append(byCompany[report.Company.ID], report)
+				}
+			}
+			return byCompany, nil
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
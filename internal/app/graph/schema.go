@@ -0,0 +1,238 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/graphql"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// maxReports caps the reports query, like the paginated REST listing
+const maxReports = 100
+
+var (
+	dateTime = &graphql.Scalar{
+		Name:        "DateTime",
+		Description: "RFC 3339 timestamp.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			t, ok := value.(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("DateTime cannot represent %v", value)
+			}
+			return t.Format(time.RFC3339Nano), nil
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("DateTime cannot represent %v", value)
+			}
+			return time.Parse(time.RFC3339, s)
+		},
+	}
+	jsonScalar = &graphql.Scalar{
+		Name:        "JSON",
+		Description: "Arbitrary JSON value, as stored.",
+		Serialize:   func(value interface{}) (interface{}, error) { return value, nil },
+		Parse:       func(value interface{}) (interface{}, error) { return value, nil },
+	}
+)
+
+// newSchema declares the read-only graph of users, companies and reports. Fields not
+// listed with a resolver are read from the domain structs by json name.
+func (s *service) newSchema() *graphql.Schema {
+	user := &graphql.Object{Name: "User"}
+	company := &graphql.Object{Name: "Company"}
+	reportType := &graphql.Object{Name: "ReportType"}
+	report := &graphql.Object{Name: "Report"}
+
+	nonNull := graphql.NonNullOf
+	listOf := func(t graphql.Type) graphql.Type { return nonNull(graphql.ListOf(nonNull(t))) }
+
+	user.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "name", Type: nonNull(graphql.String)},
+		{Name: "email", Type: nonNull(graphql.String)},
+		{Name: "role", Type: nonNull(graphql.String), Description: "SUPER_ADMIN, ADMIN or CLIENT"},
+		{Name: "avatar", Type: graphql.String, Description: "Path of the avatar, served by this API"},
+		{Name: "avatarThumb", Type: graphql.String},
+		{Name: "createdAt", Type: nonNull(dateTime)},
+		{Name: "updatedAt", Type: nonNull(dateTime)},
+		{Name: "companies", Type: listOf(company), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return loadersFrom(p.Context).companies.LoadMany(p.Context, p.Source.(*domain.User).Company), nil
+		}},
+	}
+
+	company.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "name", Type: nonNull(graphql.String)},
+		{Name: "profilePicture", Type: graphql.String, Description: "Path of the logo, served by this API"},
+		{Name: "profilePictureThumb", Type: graphql.String},
+		{Name: "createdAt", Type: nonNull(dateTime)},
+		{Name: "updatedAt", Type: nonNull(dateTime)},
+		{Name: "users", Type: listOf(user), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return loadersFrom(p.Context).users.LoadMany(p.Context, p.Source.(*domain.Company).User), nil
+		}},
+		{Name: "reports", Type: listOf(report), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return loadersFrom(p.Context).reportsByCompany.Load(p.Context, p.Source.(*domain.Company).ID), nil
+		}},
+	}
+
+	reportType.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID)},
+		{Name: "name", Type: nonNull(graphql.String)},
+	}
+
+	report.Fields = []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*domain.PopulatedReport).ID, nil
+		}},
+		{Name: "reportName", Type: nonNull(graphql.String)},
+		{Name: "year", Type: nonNull(graphql.Int)},
+		{Name: "currency", Type: graphql.String},
+		{Name: "reportType", Type: reportType},
+		{Name: "company", Type: company},
+		{Name: "createdBy", Type: user},
+		{Name: "userAccess", Type: listOf(user)},
+		{Name: "reportData", Type: jsonScalar},
+		{Name: "createdAt", Type: nonNull(dateTime)},
+		{Name: "updatedAt", Type: nonNull(dateTime)},
+	}
+
+	id := []*graphql.Arg{{Name: "id", Type: nonNull(graphql.ID)}}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "me", Type: user, Description: "The logged-in user", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			userCtx, ok := middleware.GetUserFromContext(p.Context)
+			if !ok {
+				return nil, ErrUserContextMissing
+			}
+			userID, err := primitive.ObjectIDFromHex(userCtx.UserID)
+			if err != nil {
+				return nil, ErrInvalidID
+			}
+			return loadersFrom(p.Context).users.Load(p.Context, userID), nil
+		}},
+		{Name: "user", Type: user, Args: id, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			userID, err := objectID(p.Args["id"])
+			if err != nil {
+				return nil, err
+			}
+			return loadersFrom(p.Context).users.Load(p.Context, userID), nil
+		}},
+		{Name: "users", Type: listOf(user), Description: "Every user, for roles the access policy lets list them", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			// Like GET /api/users; the repository only limits users to the tenant
+			if err := middleware.Authorize(p.Context, "list", policy.Resource{Type: "user"}); err != nil {
+				return nil, err
+			}
+			return s.userRepo.GetAll(p.Context)
+		}},
+		{Name: "company", Type: company, Args: id, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			companyID, err := objectID(p.Args["id"])
+			if err != nil {
+				return nil, err
+			}
+			return loadersFrom(p.Context).companies.Load(p.Context, companyID), nil
+		}},
+		{Name: "companies", Type: listOf(company), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return s.companyRepo.GetAll(p.Context)
+		}},
+		{Name: "report", Type: report, Args: id, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			reportID, err := objectID(p.Args["id"])
+			if err != nil {
+				return nil, err
+			}
+			found, err := s.reportRepo.GetByID(p.Context, reportID)
+			if isNotFound(err) {
+				return nil, nil
+			}
+			return found, err
+		}},
+		{
+			Name:        "reports",
+			Type:        listOf(report),
+			Description: "Reports, newest first, optionally of one company and/or report type",
+			Args: []*graphql.Arg{
+				{Name: "companyId", Type: graphql.ID},
+				{Name: "reportTypeId", Type: graphql.ID},
+				{Name: "limit", Type: graphql.Int, Default: 20},
+				{Name: "offset", Type: graphql.Int, Default: 0},
+			},
+			Resolve: s.resolveReports,
+		},
+		{Name: "reportTypes", Type: listOf(reportType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return s.reportTypeRepo.GetAll(p.Context)
+		}},
+	}}
+
+	return &graphql.Schema{Query: query, MaxDepth: 8, PresentError: presentError}
+}
+
+func (s *service) resolveReports(p graphql.ResolveParams) (interface{}, error) {
+	limit, _ := p.Args["limit"].(int)
+	offset, _ := p.Args["offset"].(int)
+	if limit < 1 || limit > maxReports || offset < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	var (
+		reports []*domain.PopulatedReport
+		err     error
+	)
+	companyArg, byCompany := p.Args["companyId"].(string)
+	typeArg, byType := p.Args["reportTypeId"].(string)
+	switch {
+	case byCompany:
+		companyID, idErr := objectID(companyArg)
+		if idErr != nil {
+			return nil, idErr
+		}
+		reports, err = s.reportRepo.GetByCompany(p.Context, companyID)
+	case byType:
+		typeID, idErr := objectID(typeArg)
+		if idErr != nil {
+			return nil, idErr
+		}
+		reports, err = s.reportRepo.GetByReportType(p.Context, typeID)
+	default:
+		reports, _, err = s.reportRepo.GetAllPaginated(p.Context, offset, limit)
+		return reports, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if byCompany && byType {
+		filtered := reports[:0]
+		for _, report := range reports {
+			if report.ReportType != nil && report.ReportType.ID.Hex() == typeArg {
+				filtered = append(filtered, report)
+			}
+		}
+		reports = filtered
+	}
+
+	if offset >= len(reports) {
+		return []*domain.PopulatedReport{}, nil
+	}
+	return reports[offset:min(offset+limit, len(reports))], nil
+}
+
+func objectID(arg interface{}) (primitive.ObjectID, error) {
+	hex, _ := arg.(string)
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidID
+	}
+	return id, nil
+}
+
+func isNotFound(err error) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Status() == 404
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/graphql"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+type Service interface {
+	Execute(ctx context.Context, req graphql.Request) *graphql.Response
+	// SDL returns the schema in the GraphQL schema definition language
+	SDL() string
+}
+
+type service struct {
+	userRepo       domain.UserRepository
+	companyRepo    domain.CompanyRepository
+	reportRepo     domain.ReportRepository
+	reportTypeRepo domain.ReportTypeRepository
+	schema         *graphql.Schema
+}
+
+func NewService(userRepo domain.UserRepository, companyRepo domain.CompanyRepository, reportRepo domain.ReportRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	s := &service{
+		userRepo:       userRepo,
+		companyRepo:    companyRepo,
+		reportRepo:     reportRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+	s.schema = s.newSchema()
+	return s
+}
+
+// Execute runs a query with fresh loaders, so batching and caching never span requests.
+func (s *service) Execute(ctx context.Context, req graphql.Request) *graphql.Response {
+	ctx = context.WithValue(ctx, loadersKey{}, s.newLoaders())
+	if strings.Contains(req.Query, "reportData") {
+		// Report lists leave reportData out unless it's asked for
+		ctx = domain.WithReportData(ctx)
+	}
+	return s.schema.Execute(ctx, req)
+}
+
+func (s *service) SDL() string {
+	return s.schema.SDL()
+}
+
+// presentError reports application errors with their code, like the REST error
+// responses, and hides the cause of server errors.
+func presentError(ctx context.Context, err error) *graphql.Error {
+	appErr, ok := err.(errors.AppError)
+	if !ok {
+		log.Errorf(ctx, "Unhandled GraphQL error: %v", err)
+		appErr = errors.ErrInternalServer
+	} else if appErr.Status() >= http.StatusInternalServerError {
+		log.Errorf(ctx, "GraphQL server error: %v", appErr)
+	}
+	return &graphql.Error{
+		Message:    appErr.Message(),
+		Extensions: map[string]interface{}{"code": appErr.Code()},
+	}
+}
@@ -0,0 +1,453 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/graphql"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// store holds the rows of the mock repositories and, like the real ones, limits reads to the
+// tenant and access scope of their context. It records reads made without them.
+type store struct {
+	users     []*domain.User
+	companies []*domain.Company
+	reports   []*domain.PopulatedReport
+	unscoped  []string
+}
+
+func (s *store) read(ctx context.Context, method string) {
+	if domain.TenantOf(ctx) == nil || domain.AccessScopeOf(ctx) == nil {
+		s.unscoped = append(s.unscoped, method)
+	}
+}
+
+func (s *store) visibleCompany(ctx context.Context, company *domain.Company) bool {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(company.ID) {
+		return false
+	}
+	scope := domain.AccessScopeOf(ctx)
+	return scope == nil || scope.AllowsCompany(company)
+}
+
+func (s *store) visibleReport(ctx context.Context, report *domain.PopulatedReport) bool {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(report.Company.ID) {
+		return false
+	}
+	scope := domain.AccessScopeOf(ctx)
+	if scope == nil || report.CreatedBy.ID == scope.UserID || containsID(scope.Companies, report.Company.ID) {
+		return true
+	}
+	for _, user := range report.UserAccess {
+		if user.ID == scope.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *store) visibleUser(ctx context.Context, user *domain.User) bool {
+	tenant := domain.TenantOf(ctx)
+	return tenant == nil || tenant.Owns(user.Organization)
+}
+
+func (s *store) reportsWhere(ctx context.Context, method string, match func(*domain.PopulatedReport) bool) []*domain.PopulatedReport {
+	s.read(ctx, method)
+	reports := []*domain.PopulatedReport{}
+	for _, report := range s.reports {
+		if s.visibleReport(ctx, report) && match(report) {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+var errNotFound = errors.New("NOT_FOUND", "Not found", 404, nil, nil)
+
+type mockUserRepository struct{ *store }
+
+func (m mockUserRepository) Create(ctx context.Context, user *domain.User) error { return nil }
+
+func (m mockUserRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	users, _ := m.GetByIDs(ctx, []primitive.ObjectID{id})
+	if len(users) == 0 {
+		return nil, errNotFound
+	}
+	return users[0], nil
+}
+
+func (m mockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return nil, errNotFound
+}
+
+func (m mockUserRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.User, error) {
+	m.read(ctx, "users.GetByIDs")
+	var users []*domain.User
+	for _, user := range m.users {
+		if m.visibleUser(ctx, user) && containsID(ids, user.ID) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m mockUserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
+	m.read(ctx, "users.GetAll")
+	var users []*domain.User
+	for _, user := range m.users {
+		if m.visibleUser(ctx, user) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m mockUserRepository) GetPage(ctx context.Context, query domain.UserQuery) ([]*domain.User, int, error) {
+	return nil, 0, nil
+}
+
+func (m mockUserRepository) Each(ctx context.Context, fn func(*domain.User) error) error {
+	return nil
+}
+
+func (m mockUserRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
+	return nil
+}
+
+func (m mockUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error { return nil }
+
+type mockCompanyRepository struct{ *store }
+
+func (m mockCompanyRepository) Create(ctx context.Context, company *domain.Company) error {
+	return nil
+}
+
+func (m mockCompanyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Company, error) {
+	companies, _ := m.GetByIDs(ctx, []primitive.ObjectID{id})
+	if len(companies) == 0 {
+		return nil, errNotFound
+	}
+	return companies[0], nil
+}
+
+func (m mockCompanyRepository) GetByName(ctx context.Context, name string) (*domain.Company, error) {
+	return nil, errNotFound
+}
+
+func (m mockCompanyRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.Company, error) {
+	m.read(ctx, "companies.GetByIDs")
+	var companies []*domain.Company
+	for _, company := range m.companies {
+		if m.visibleCompany(ctx, company) && containsID(ids, company.ID) {
+			companies = append(companies, company)
+		}
+	}
+	return companies, nil
+}
+
+func (m mockCompanyRepository) SearchByName(ctx context.Context, name string) ([]*domain.Company, error) {
+	return nil, nil
+}
+
+func (m mockCompanyRepository) GetAll(ctx context.Context) ([]*domain.Company, error) {
+	m.read(ctx, "companies.GetAll")
+	var companies []*domain.Company
+	for _, company := range m.companies {
+		if m.visibleCompany(ctx, company) {
+			companies = append(companies, company)
+		}
+	}
+	return companies, nil
+}
+
+func (m mockCompanyRepository) Each(ctx context.Context, fn func(*domain.Company) error) error {
+	return nil
+}
+
+func (m mockCompanyRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*domain.Company, error) {
+	return nil, nil
+}
+
+func (m mockCompanyRepository) Update(ctx context.Context, id primitive.ObjectID, company *domain.Company) error {
+	return nil
+}
+
+func (m mockCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error { return nil }
+
+type mockReportRepository struct{ *store }
+
+func (m mockReportRepository) Create(ctx context.Context, report *domain.Report) error { return nil }
+
+func (m mockReportRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.PopulatedReport, error) {
+	reports := m.reportsWhere(ctx, "reports.GetByID", func(report *domain.PopulatedReport) bool { return report.ID == id })
+	if len(reports) == 0 {
+		return nil, errNotFound
+	}
+	return reports[0], nil
+}
+
+func (m mockReportRepository) GetByName(ctx context.Context, name string) (*domain.PopulatedReport, error) {
+	return nil, errNotFound
+}
+
+func (m mockReportRepository) GetAll(ctx context.Context) ([]*domain.PopulatedReport, error) {
+	return m.reportsWhere(ctx, "reports.GetAll", func(*domain.PopulatedReport) bool { return true }), nil
+}
+
+func (m mockReportRepository) Each(ctx context.Context, fn func(*domain.PopulatedReport) error) error {
+	return nil
+}
+
+func (m mockReportRepository) GetAllPaginated(ctx context.Context, skip, limit int) ([]*domain.PopulatedReport, int, error) {
+	reports := m.reportsWhere(ctx, "reports.GetAllPaginated", func(*domain.PopulatedReport) bool { return true })
+	return reports[min(skip, len(reports)):min(skip+limit, len(reports))], len(reports), nil
+}
+
+func (m mockReportRepository) GetByCompany(ctx context.Context, companyID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return m.reportsWhere(ctx, "reports.GetByCompany", func(report *domain.PopulatedReport) bool { return report.Company.ID == companyID }), nil
+}
+
+func (m mockReportRepository) GetByCompanies(ctx context.Context, companyIDs []primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return m.reportsWhere(ctx, "reports.GetByCompanies", func(report *domain.PopulatedReport) bool { return containsID(companyIDs, report.Company.ID) }), nil
+}
+
+func (m mockReportRepository) GetUpdatedSince(ctx context.Context, companyIDs []primitive.ObjectID, since time.Time) ([]*domain.PopulatedReport, error) {
+	return m.reportsWhere(ctx, "reports.GetUpdatedSince", func(report *domain.PopulatedReport) bool {
+		return containsID(companyIDs, report.Company.ID) && !report.UpdatedAt.Before(since)
+	}), nil
+}
+
+func (m mockReportRepository) GetByReportType(ctx context.Context, reportTypeID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return m.reportsWhere(ctx, "reports.GetByReportType", func(report *domain.PopulatedReport) bool { return report.ReportType.ID == reportTypeID }), nil
+}
+
+func (m mockReportRepository) GetByUserAccess(ctx context.Context, userID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return nil, nil
+}
+
+func (m mockReportRepository) GetByCreatedBy(ctx context.Context, userID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return nil, nil
+}
+
+func (m mockReportRepository) Update(ctx context.Context, id primitive.ObjectID, report *domain.Report) (*domain.PopulatedReport, error) {
+	return nil, nil
+}
+
+func (m mockReportRepository) Delete(ctx context.Context, id primitive.ObjectID) error { return nil }
+
+type mockReportTypeRepository struct{ types []*domain.ReportType }
+
+func (m mockReportTypeRepository) Create(ctx context.Context, reportType *domain.ReportType) error {
+	return nil
+}
+
+func (m mockReportTypeRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ReportType, error) {
+	return nil, errNotFound
+}
+
+func (m mockReportTypeRepository) GetByName(ctx context.Context, name string) (*domain.ReportType, error) {
+	return nil, errNotFound
+}
+
+func (m mockReportTypeRepository) GetAll(ctx context.Context) ([]*domain.ReportType, error) {
+	return m.types, nil
+}
+
+func (m mockReportTypeRepository) Update(ctx context.Context, id primitive.ObjectID, reportType *domain.ReportType) error {
+	return nil
+}
+
+func (m mockReportTypeRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+// fixture is an organization with two companies, a client of the first and an admin, plus a
+// company and report of another organization.
+type fixture struct {
+	store                        *store
+	service                      Service
+	organization                 primitive.ObjectID
+	client, admin, stranger      *domain.User
+	own, sibling, foreign        *domain.Company
+	ownReport, shared            *domain.PopulatedReport
+	siblingReport, foreignReport *domain.PopulatedReport
+}
+
+func newFixture() *fixture {
+	f := &fixture{store: &store{}}
+	f.organization = primitive.NewObjectID()
+	other := primitive.NewObjectID()
+	reportType := &domain.ReportType{ID: primitive.NewObjectID(), Name: "Balance Sheet"}
+
+	f.own = &domain.Company{ID: primitive.NewObjectID(), Name: "Own", Organization: &f.organization}
+	f.sibling = &domain.Company{ID: primitive.NewObjectID(), Name: "Sibling", Organization: &f.organization}
+	f.foreign = &domain.Company{ID: primitive.NewObjectID(), Name: "Foreign", Organization: &other}
+
+	f.client = &domain.User{ID: primitive.NewObjectID(), Name: "Client", Role: domain.RoleClient, Organization: &f.organization, Company: []primitive.ObjectID{f.own.ID}}
+	f.admin = &domain.User{ID: primitive.NewObjectID(), Name: "Admin", Role: domain.RoleAdmin, Organization: &f.organization}
+	f.stranger = &domain.User{ID: primitive.NewObjectID(), Name: "Stranger", Role: domain.RoleAdmin, Organization: &other}
+	f.own.User = []primitive.ObjectID{f.client.ID}
+
+	report := func(name string, company *domain.Company, access ...*domain.User) *domain.PopulatedReport {
+		return &domain.PopulatedReport{ID: primitive.NewObjectID(), ReportName: name, Year: 2024, ReportType: reportType, Company: company, CreatedBy: f.admin, UserAccess: access}
+	}
+	f.ownReport = report("Own report", f.own)
+	f.shared = report("Shared report", f.sibling, f.client)
+	f.siblingReport = report("Sibling report", f.sibling)
+	f.foreignReport = report("Foreign report", f.foreign, f.client)
+
+	f.store.users = []*domain.User{f.client, f.admin, f.stranger}
+	f.store.companies = []*domain.Company{f.own, f.sibling, f.foreign}
+	f.store.reports = []*domain.PopulatedReport{f.ownReport, f.shared, f.siblingReport, f.foreignReport}
+	f.service = NewService(mockUserRepository{f.store}, mockCompanyRepository{f.store}, mockReportRepository{f.store}, mockReportTypeRepository{types: []*domain.ReportType{reportType}})
+	return f
+}
+
+// as returns the context the auth middleware builds for a request of the user: their tenant
+// and, for clients, their access scope.
+func (f *fixture) as(user *domain.User) context.Context {
+	ctx := context.WithValue(context.Background(), "user", &middleware.UserContext{UserID: user.ID.Hex(), Role: string(user.Role)})
+	ctx = domain.WithTenant(ctx, &domain.Tenant{Organization: *user.Organization, Companies: []primitive.ObjectID{f.own.ID, f.sibling.ID}})
+	if user.Role == domain.RoleClient {
+		ctx = domain.WithAccessScope(ctx, &domain.AccessScope{UserID: user.ID, Companies: user.Company})
+	}
+	return ctx
+}
+
+func (f *fixture) query(t *testing.T, ctx context.Context, query string) (map[string]interface{}, []*graphql.Error) {
+	t.Helper()
+	response := f.service.Execute(ctx, graphql.Request{Query: query})
+	encoded, err := json.Marshal(response.Data)
+	if err != nil {
+		t.Fatalf("Failed to encode data: %v", err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		t.Fatalf("Failed to decode data: %v", err)
+	}
+	return data, response.Errors
+}
+
+// names lists the name or reportName of each object of a list in the data.
+func names(list interface{}) []string {
+	result := []string{}
+	items, _ := list.([]interface{})
+	for _, item := range items {
+		object := item.(map[string]interface{})
+		if name, ok := object["reportName"].(string); ok {
+			result = append(result, name)
+		} else {
+			result = append(result, object["name"].(string))
+		}
+	}
+	return result
+}
+
+func TestService_Execute_ClientSeesOnlyTheirScope(t *testing.T) {
+	f := newFixture()
+	ctx := f.as(f.client)
+
+	data, errs := f.query(t, ctx, `{
+		reports { reportName }
+		companies { name reports { reportName } }
+		me { name companies { name reports { reportName } } }
+		sibling: company(id: "`+f.sibling.ID.Hex()+`") { name }
+		foreign: report(id: "`+f.foreignReport.ID.Hex()+`") { reportName }
+		sharedReport: report(id: "`+f.shared.ID.Hex()+`") { reportName company { name } }
+	}`)
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %+v", errs[0])
+	}
+
+	// The foreign report was shared with the client, but belongs to another organization
+	if got := strings.Join(names(data["reports"]), ", "); got != "Own report, Shared report" {
+		t.Fatalf("Expected the client's reports, got %s", got)
+	}
+	companies := data["companies"].([]interface{})
+	if got := strings.Join(names(companies), ", "); got != "Own" {
+		t.Fatalf("Expected the client's companies, got %s", got)
+	}
+	if got := strings.Join(names(companies[0].(map[string]interface{})["reports"]), ", "); got != "Own report" {
+		t.Fatalf("Expected the reports of the client's company, got %s", got)
+	}
+	me := data["me"].(map[string]interface{})
+	if got := strings.Join(names(me["companies"]), ", "); got != "Own" {
+		t.Fatalf("Expected the client's companies, got %s", got)
+	}
+	if data["sibling"] != nil || data["foreign"] != nil {
+		t.Fatalf("Expected companies and reports out of scope to be null, got %v and %v", data["sibling"], data["foreign"])
+	}
+	shared := data["sharedReport"].(map[string]interface{})
+	if shared["reportName"] != "Shared report" {
+		t.Fatalf("Expected the report shared with the client, got %v", shared)
+	}
+
+	if len(f.store.unscoped) != 0 {
+		t.Fatalf("Expected every read to carry the tenant and access scope, got unscoped %v", f.store.unscoped)
+	}
+}
+
+func TestService_Execute_UsersFollowTheAccessPolicy(t *testing.T) {
+	f := newFixture()
+
+	_, errs := f.query(t, f.as(f.client), `{ users { name } }`)
+	if len(errs) != 1 || errs[0].Extensions["code"] != "FORBIDDEN" {
+		t.Fatalf("Expected clients not to list users, got %+v", errs)
+	}
+
+	data, errs := f.query(t, f.as(f.admin), `{ users { name } stranger: user(id: "`+f.stranger.ID.Hex()+`") { name } }`)
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %+v", errs[0])
+	}
+	if got := strings.Join(names(data["users"]), ", "); got != "Client, Admin" {
+		t.Fatalf("Expected the users of the admin's organization, got %s", got)
+	}
+	if data["stranger"] != nil {
+		t.Fatalf("Expected a user of another organization to be null, got %v", data["stranger"])
+	}
+}
+
+func TestService_Execute_RejectsInvalidQueries(t *testing.T) {
+	f := newFixture()
+	ctx := f.as(f.admin)
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{name: "malformed", query: `{ reports { reportName }`, code: "GRAPHQL_PARSE_FAILED"},
+		{name: "nonexistent field", query: `{ reports { password } }`, code: "GRAPHQL_VALIDATION_FAILED"},
+		{name: "password hash of users", query: `{ users { password } }`, code: "GRAPHQL_VALIDATION_FAILED"},
+		{name: "too deep", query: `{ companies { users { companies { users { companies { users { companies { users { name } } } } } } } } }`, code: "GRAPHQL_VALIDATION_FAILED"},
+		{name: "too many fields", query: "{ reportTypes {" + strings.Repeat(" name", 1000) + " } }", code: "GRAPHQL_VALIDATION_FAILED"},
+		{name: "invalid ID", query: `{ report(id: "nope") { reportName } }`, code: "INVALID_ID"},
+		{name: "limit out of range", query: `{ reports(limit: 1000) { reportName } }`, code: "INVALID_LIMIT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := f.query(t, ctx, tt.query)
+			if len(errs) == 0 || errs[0].Extensions["code"] != tt.code {
+				t.Fatalf("Expected a %s error, got %+v", tt.code, errs)
+			}
+		})
+	}
+}
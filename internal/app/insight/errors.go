@@ -0,0 +1,14 @@
+package insight
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidReportID   = errors.New("INVALID_REPORT_ID", "Invalid report ID format", http.StatusBadRequest, nil, nil)
+	ErrInsightsDisabled  = errors.New("INSIGHTS_DISABLED", "AI insights are not configured on this server", http.StatusServiceUnavailable, nil, nil)
+	ErrReportTooLarge    = errors.New("REPORT_TOO_LARGE_FOR_INSIGHTS", "The report is too large to generate insights from", http.StatusUnprocessableEntity, nil, nil)
+	ErrReportHasNoData   = errors.New("REPORT_HAS_NO_DATA", "The report has no data to generate insights from", http.StatusUnprocessableEntity, nil, nil)
+	ErrInvalidAIResponse = errors.New("AI_INVALID_ANSWER", "The language model gave an answer that could not be used", http.StatusBadGateway, nil, nil)
+)
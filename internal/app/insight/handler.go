@@ -0,0 +1,58 @@
+package insight
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+// generateTimeout is how long a response may take to write, longer than other requests since
+// the model takes a while to answer
+const generateTimeout = 30 * time.Second
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers report insight routes
+// @Tags Insights
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/reports/{id}/insights", h.GenerateInsights).Methods("POST")
+}
+
+// GenerateInsights returns an AI-generated narrative summary, anomalies and forecasts of a
+// report, generated with Gemini from the report data and earlier years of its type. Insights are
+// kept per version of the data, so asking again for an unchanged report doesn't call the model.
+func (h *Handler) GenerateInsights(w http.ResponseWriter, r *http.Request) {
+	// Failing to extend the deadline leaves the server's write timeout, which usually suffices
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(generateTimeout))
+
+	insight, err := h.service.GenerateInsights(r.Context(), mux.Vars(r)["id"], requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, insight)
+}
+
+// requester is the ID of the user making the request, recorded on the insights they generate.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
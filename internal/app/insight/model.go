@@ -0,0 +1,39 @@
+package insight
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Disclaimer labels every insight, for clients to show next to it.
+const Disclaimer = "AI-generated from the report data. It may be inaccurate; verify figures against the report before relying on them."
+
+// Response DTOs
+type InsightResponse struct {
+	Report      string                   `json:"report"`
+	Version     string                   `json:"version"` // fingerprint of the data it was generated from
+	AIGenerated bool                     `json:"aiGenerated"`
+	Disclaimer  string                   `json:"disclaimer"`
+	Model       string                   `json:"model"`
+	Summary     string                   `json:"summary"`
+	Anomalies   []domain.InsightAnomaly  `json:"anomalies"`
+	Forecasts   []domain.InsightForecast `json:"forecasts"`
+	GeneratedAt time.Time                `json:"generatedAt"`
+	Cached      bool                     `json:"cached"` // generated earlier from the same data
+}
+
+func ToInsightResponse(insight *domain.ReportInsight, cached bool) *InsightResponse {
+	return &InsightResponse{
+		Report:      insight.Report.Hex(),
+		Version:     insight.Version,
+		AIGenerated: true,
+		Disclaimer:  Disclaimer,
+		Model:       insight.Model,
+		Summary:     insight.Summary,
+		Anomalies:   insight.Anomalies,
+		Forecasts:   insight.Forecasts,
+		GeneratedAt: insight.GeneratedAt,
+		Cached:      cached,
+	}
+}
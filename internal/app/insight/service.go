@@ -0,0 +1,253 @@
+package insight
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/ai"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const (
+	// maxInputBytes bounds the report data sent to the model
+	maxInputBytes = 200 << 10
+	// historyYears is how many earlier years of the report type are sent along for forecasts
+	historyYears = 5
+)
+
+const instructions = `You are a financial analyst reviewing a company's financial report.
+Answer with a JSON object of this shape and nothing else:
+{"summary": string, "anomalies": [{"item": string, "description": string}], "forecasts": [{"item": string, "year": number, "value": number, "rationale": string}]}
+- summary: a narrative of three to six sentences on the report's key figures and what they mean.
+- anomalies: line items that look unusual, inconsistent, or changed sharply from earlier years; empty when none do.
+- forecasts: simple projections of the main line items for the year after the report, based on its figures and the earlier years given; empty when there is too little data.
+Only use the figures given. Amounts are in the report's currency.`
+
+type Service interface {
+	// GenerateInsights returns the insight of a report the caller can read, generating it
+	// unless one was already generated from the same data.
+	GenerateInsights(ctx context.Context, reportID string, requestedBy primitive.ObjectID) (*InsightResponse, error)
+}
+
+type service struct {
+	insightRepo domain.InsightRepository
+	reportRepo  domain.ReportRepository
+	model       ai.Model
+}
+
+// NewService generates insights with model, which is nil when AI is not configured.
+func NewService(insightRepo domain.InsightRepository, reportRepo domain.ReportRepository, model ai.Model) Service {
+	return &service{
+		insightRepo: insightRepo,
+		reportRepo:  reportRepo,
+		model:       model,
+	}
+}
+
+// input is what the model is given about a report.
+type input struct {
+	Report  reportInput    `json:"report"`
+	History []historyInput `json:"earlierYears"`
+}
+
+type reportInput struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type,omitempty"`
+	Company  string      `json:"company,omitempty"`
+	Year     int         `json:"year"`
+	Currency string      `json:"currency,omitempty"`
+	Data     interface{} `json:"data"`
+}
+
+type historyInput struct {
+	Year      int                `json:"year"`
+	LineItems map[string]float64 `json:"lineItems"`
+}
+
+func (s *service) GenerateInsights(ctx context.Context, reportID string, requestedBy primitive.ObjectID) (*InsightResponse, error) {
+	if s.model == nil {
+		return nil, ErrInsightsDisabled
+	}
+	objectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, ErrInvalidReportID
+	}
+
+	report, err := s.reportRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Company == nil || report.ReportData == nil {
+		return nil, ErrReportHasNoData
+	}
+
+	prompt, err := s.prompt(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same data given to the same model is answered from the cache
+	sum := sha256.Sum256([]byte(s.model.Name() + "\n" + prompt))
+	version := hex.EncodeToString(sum[:])
+	if cached, err := s.insightRepo.Get(ctx, report.ID, version); err == nil {
+		return ToInsightResponse(cached, true), nil
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+
+	answer, err := s.model.GenerateJSON(ctx, instructions, prompt)
+	if err != nil {
+		return nil, err
+	}
+	insight, err := parseAnswer(answer)
+	if err != nil {
+		return nil, err
+	}
+
+	insight.Report = report.ID
+	insight.Company = report.Company.ID
+	insight.Version = version
+	insight.Model = s.model.Name()
+	insight.GeneratedBy = requestedBy
+	insight.GeneratedAt = time.Now()
+	if err := s.insightRepo.Save(ctx, insight); err != nil {
+		return nil, err
+	}
+
+	return ToInsightResponse(insight, false), nil
+}
+
+// prompt describes the report, and the line items of the company's reports of the same type
+// for earlier years, as JSON.
+func (s *service) prompt(ctx context.Context, report *domain.PopulatedReport) (string, error) {
+	in := input{
+		Report: reportInput{
+			Name:    report.ReportName,
+			Company: report.Company.Name,
+			Year:    report.Year,
+			Data:    plain(report.ReportData),
+		},
+		History: []historyInput{},
+	}
+	if report.Currency != nil {
+		in.Report.Currency = *report.Currency
+	}
+
+	if report.ReportType != nil {
+		in.Report.Type = report.ReportType.Name
+
+		others, err := s.reportRepo.GetByCompany(domain.WithReportData(ctx), report.Company.ID)
+		if err != nil {
+			return "", err
+		}
+		// The report changed last stands for its year
+		sort.SliceStable(others, func(i, j int) bool {
+			return others[i].UpdatedAt.After(others[j].UpdatedAt)
+		})
+		years := map[int]bool{}
+		for _, other := range others {
+			if other.Year >= report.Year || years[other.Year] || other.ReportType == nil || other.ReportType.ID != report.ReportType.ID {
+				continue
+			}
+			items := domain.LineItems(other.ReportData)
+			if len(items) == 0 {
+				continue
+			}
+			years[other.Year] = true
+			in.History = append(in.History, historyInput{Year: other.Year, LineItems: items})
+		}
+		sort.Slice(in.History, func(i, j int) bool { return in.History[i].Year > in.History[j].Year })
+		if len(in.History) > historyYears {
+			in.History = in.History[:historyYears]
+		}
+	}
+
+	prompt, err := json.Marshal(in)
+	if err != nil {
+		return "", errors.New("INSIGHT_PROMPT_FAILED", "Failed to read the report data", 500, err, nil)
+	}
+	if len(prompt) > maxInputBytes {
+		return "", ErrReportTooLarge
+	}
+	return string(prompt), nil
+}
+
+// parseAnswer reads the model's JSON answer, dropping anomalies and forecasts it left incomplete.
+func parseAnswer(answer string) (*domain.ReportInsight, error) {
+	var parsed struct {
+		Summary   string                   `json:"summary"`
+		Anomalies []domain.InsightAnomaly  `json:"anomalies"`
+		Forecasts []domain.InsightForecast `json:"forecasts"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(answer)), &parsed); err != nil {
+		return nil, errors.New("AI_INVALID_ANSWER", "The language model gave an answer that could not be used", 502, err, nil)
+	}
+	if strings.TrimSpace(parsed.Summary) == "" {
+		return nil, ErrInvalidAIResponse
+	}
+
+	insight := &domain.ReportInsight{
+		Summary:   strings.TrimSpace(parsed.Summary),
+		Anomalies: []domain.InsightAnomaly{},
+		Forecasts: []domain.InsightForecast{},
+	}
+	for _, anomaly := range parsed.Anomalies {
+		if anomaly.Item != "" && anomaly.Description != "" {
+			insight.Anomalies = append(insight.Anomalies, anomaly)
+		}
+	}
+	for _, forecast := range parsed.Forecasts {
+		if forecast.Item != "" && forecast.Year != 0 {
+			insight.Forecasts = append(insight.Forecasts, forecast)
+		}
+	}
+	return insight, nil
+}
+
+// plain converts report data decoded from BSON into maps and slices, which marshal to the
+// JSON the data was stored from.
+func plain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.D:
+		m := make(map[string]interface{}, len(v))
+		for _, element := range v {
+			m[element.Key] = plain(element.Value)
+		}
+		return m
+	case primitive.M:
+		m := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			m[key] = plain(element)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			m[key] = plain(element)
+		}
+		return m
+	case primitive.A:
+		return plain([]interface{}(v))
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, element := range v {
+			s[i] = plain(element)
+		}
+		return s
+	}
+	return value
+}
+
+func isNotFound(err error) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Status() == http.StatusNotFound
+}
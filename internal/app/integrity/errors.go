@@ -0,0 +1,10 @@
+package integrity
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrNoReport = errors.New("INTEGRITY_REPORT_NOT_FOUND", "No integrity check has run yet", http.StatusNotFound, nil, nil)
+)
@@ -0,0 +1,61 @@
+package integrity
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers integrity check routes
+// @Tags Administration
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "integrity"))
+
+	adminOnly.HandleFunc("/api/admin/integrity/check", h.RunCheck).Methods("POST")
+	adminOnly.HandleFunc("/api/admin/integrity/report", h.GetLastReport).Methods("GET")
+}
+
+// RunCheck scans for orphaned references, repairing what it can when asked to
+func (h *Handler) RunCheck(w http.ResponseWriter, r *http.Request) {
+	var req CheckRequest
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	}
+
+	report, err := h.service.Check(r.Context(), req.Repair)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, report)
+}
+
+// GetLastReport returns the result of the most recent manual or scheduled check
+func (h *Handler) GetLastReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.LastReport(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, report)
+}
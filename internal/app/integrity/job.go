@@ -0,0 +1,46 @@
+package integrity
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Job runs the integrity check on a fixed interval.
+type Job struct {
+	service  Service
+	interval time.Duration
+	repair   bool
+}
+
+func NewJob(service Service, interval time.Duration, repair bool) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+		repair:   repair,
+	}
+}
+
+// Run checks integrity until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := j.service.Check(ctx, j.repair)
+			if err != nil {
+				log.Errorf(ctx, "Integrity: check failed: %v", err)
+				continue
+			}
+			if report.Total > 0 {
+				log.Warnf(ctx, "Integrity: found %d orphaned references (%d repaired, %d need manual action)",
+					report.Total, report.Repaired, report.Manual)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package integrity
+
+import "time"
+
+// Request DTOs
+type CheckRequest struct {
+	Repair bool `json:"repair"`
+}
+
+// Response DTOs
+type Issue struct {
+	Check       string `json:"check"`
+	DocumentID  string `json:"documentId"`
+	ReferenceID string `json:"referenceId"`
+	Problem     string `json:"problem"` // "missing" or "deleted"
+	Repairable  bool   `json:"repairable"`
+	Repaired    bool   `json:"repaired"`
+	Action      string `json:"action"`
+	Error       string `json:"error,omitempty"`
+}
+
+type Report struct {
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	Repair     bool           `json:"repair"`
+	Counts     map[string]int `json:"counts"` // orphans per check
+	Total      int            `json:"total"`
+	Repaired   int            `json:"repaired"`
+	Manual     int            `json:"manual"` // issues that need a human decision
+	Issues     []Issue        `json:"issues"`
+}
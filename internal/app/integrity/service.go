@@ -0,0 +1,102 @@
+package integrity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+var actions = map[domain.ReferenceCheck]string{
+	domain.CheckReportCompany:    "Reassign the report to an existing company or delete the report",
+	domain.CheckReportType:       "Assign an existing report type to the report",
+	domain.CheckReportCreatedBy:  "Transfer the report to an existing user",
+	domain.CheckReportUserAccess: "Remove the user from the report's access list",
+	domain.CheckUserCompany:      "Remove the company from the user's companies",
+	domain.CheckCompanyUser:      "Remove the user from the company's users",
+}
+
+type Service interface {
+	Check(ctx context.Context, repair bool) (*Report, error)
+	LastReport(ctx context.Context) (*Report, error)
+}
+
+type service struct {
+	integrityRepo domain.IntegrityRepository
+
+	mu   sync.RWMutex
+	last *Report
+}
+
+func NewService(integrityRepo domain.IntegrityRepository) Service {
+	return &service{
+		integrityRepo: integrityRepo,
+	}
+}
+
+// Check scans every reference for orphans. With repair set, repairable orphans are fixed in place;
+// the rest are reported with a suggested manual action.
+func (s *service) Check(ctx context.Context, repair bool) (*Report, error) {
+	report := &Report{
+		StartedAt: time.Now(),
+		Repair:    repair,
+		Counts:    make(map[string]int, len(domain.ReferenceChecks)),
+		Issues:    []Issue{},
+	}
+
+	for _, check := range domain.ReferenceChecks {
+		orphans, err := s.integrityRepo.FindOrphans(ctx, check)
+		if err != nil {
+			return nil, err
+		}
+		report.Counts[string(check)] = len(orphans)
+
+		for _, orphan := range orphans {
+			issue := Issue{
+				Check:       string(check),
+				DocumentID:  orphan.DocumentID.Hex(),
+				ReferenceID: orphan.ReferenceID.Hex(),
+				Problem:     "missing",
+				Repairable:  check.Repairable(),
+				Action:      actions[check],
+			}
+			if orphan.Deleted {
+				issue.Problem = "deleted"
+			}
+
+			if issue.Repairable && repair {
+				if err := s.integrityRepo.RemoveReference(ctx, orphan); err != nil {
+					issue.Error = err.Error()
+				} else {
+					issue.Repaired = true
+					report.Repaired++
+				}
+			}
+			if !issue.Repairable {
+				report.Manual++
+			}
+
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	report.Total = len(report.Issues)
+	report.FinishedAt = time.Now()
+
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+func (s *service) LastReport(ctx context.Context) (*Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.last == nil {
+		return nil, ErrNoReport
+	}
+	return s.last, nil
+}
@@ -0,0 +1,17 @@
+package invitation
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidInvitationID = errors.New("INVALID_INVITATION_ID", "Invalid invitation ID format", http.StatusBadRequest, nil, nil)
+	ErrUserAlreadyExists   = errors.New("USER_ALREADY_EXISTS", "Email already registered", http.StatusConflict, nil, nil)
+	ErrAlreadyAccepted     = errors.New("INVITATION_ALREADY_ACCEPTED", "This invitation has already been accepted", http.StatusConflict, nil, nil)
+	ErrInvitationInvalid   = errors.New("INVITATION_INVALID", "This invitation link is invalid, expired or already used", http.StatusBadRequest, nil, nil)
+	ErrInvalidStatus       = errors.New("INVALID_INVITATION_STATUS", "Status must be pending or all", http.StatusBadRequest, nil, nil)
+	ErrUserDeactivated     = errors.New("INVITED_USER_DEACTIVATED", "The invited user is deactivated; activate them to resend the invitation", http.StatusConflict, nil, nil)
+
+	ErrInvitationsUnavailable = errors.New("INVITATIONS_UNAVAILABLE", "Invitations are not available", http.StatusServiceUnavailable, nil, nil)
+)
@@ -0,0 +1,142 @@
+package invitation
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers invitation routes
+// @Tags Invitations
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	// Invitees have no account to log in with yet
+	router.HandleFunc("/api/invitations/accept", h.AcceptInvitation).Methods("POST")
+
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/invitations", h.GetInvitations).Methods("GET")
+	protected.HandleFunc("/api/invitations", h.CreateInvitation).Methods("POST")
+	protected.HandleFunc("/api/invitations/{id}/resend", h.ResendInvitation).Methods("POST")
+}
+
+// @Summary List invitations
+// @Description Lists invitations newest first, page by page. Expired ones must be resent
+// @Description before the user can accept them.
+// @Param status query string false "pending (default) or all, which includes accepted invitations"
+func (h *Handler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	if err := middleware.Authorize(r.Context(), "list", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	var pending bool
+	switch r.URL.Query().Get("status") {
+	case "", "pending":
+		pending = true
+	case "all":
+	default:
+		utils.HandleHTTPError(w, ErrInvalidStatus, r)
+		return
+	}
+
+	pagination := utils.GetPaginationParams(r)
+	invitations, total, err := h.service.GetInvitations(r.Context(), pending, pagination.Skip, pagination.Limit)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	pagination.Total = total
+	utils.SetPaginationHeaders(w, r, pagination)
+	utils.RespondJSON(w, http.StatusOK, utils.CreatePaginatedResponse(invitations, pagination))
+}
+
+// @Summary Invite a user
+// @Description Creates a pending user and emails them a link to APP_URL/invitations/accept?token=...
+// @Description where they set their own password. The link works once, for 7 days. Pending
+// @Description users cannot log in.
+func (h *Handler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	if err := middleware.Authorize(r.Context(), "create", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	var req InviteRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	invitation, err := h.service.Invite(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, invitation)
+}
+
+// @Summary Resend an invitation
+// @Description Emails a new link that works for another 7 days. Links sent before stop working.
+func (h *Handler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	if err := middleware.Authorize(r.Context(), "create", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	invitation, err := h.service.Resend(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, invitation)
+}
+
+// @Summary Accept an invitation
+// @Description Sets the password of an invited user with the token of their invitation link.
+// @Description They can then log in with POST /api/login.
+func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req AcceptInvitationRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, utils.ErrBadRequest, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	if err := h.service.Accept(r.Context(), req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{
+		"message": "Invitation accepted, you can now log in",
+	})
+}
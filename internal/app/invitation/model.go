@@ -0,0 +1,52 @@
+package invitation
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type InviteRequest struct {
+	Name   string `json:"name" validate:"required,min=2,max=50"`
+	Email  string `json:"email" validate:"required,email"`
+	Role   string `json:"role" validate:"required,oneof=SUPER_ADMIN ADMIN CLIENT"`
+	Locale string `json:"locale,omitempty" validate:"omitempty,oneof=en id"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"` // checked against the password policy
+}
+
+// Response DTOs
+type InvitationResponse struct {
+	ID        string          `json:"id"`
+	User      string          `json:"user"`
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	Role      domain.UserRole `json:"role"`
+	InvitedBy string          `json:"invitedBy"`
+	Sends     int             `json:"sends"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+	// Expired is true when the latest link no longer works and the invitation must be resent
+	Expired    bool       `json:"expired"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func ToInvitationResponse(invitation *domain.Invitation) *InvitationResponse {
+	return &InvitationResponse{
+		ID:         invitation.ID.Hex(),
+		User:       invitation.User.Hex(),
+		Name:       invitation.Name,
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		InvitedBy:  invitation.InvitedBy.Hex(),
+		Sends:      invitation.Sends,
+		ExpiresAt:  invitation.ExpiresAt,
+		Expired:    invitation.AcceptedAt == nil && invitation.Expired(time.Now()),
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,245 @@
+package invitation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/notify"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// invitationTTL is how long an invitation link can be used.
+const invitationTTL = 7 * 24 * time.Hour
+
+type Service interface {
+	// Invite creates a pending user, who cannot log in, and emails them a link to set their
+	// password. When the email fails the invitation is kept and can be resent.
+	Invite(ctx context.Context, req InviteRequest) (*InvitationResponse, error)
+	// GetInvitations returns a page of invitations, newest first, and how many there are
+	GetInvitations(ctx context.Context, pending bool, skip, limit int) ([]*InvitationResponse, int, error)
+	// Resend emails a new link, which replaces the previous one and expires later
+	Resend(ctx context.Context, id string) (*InvitationResponse, error)
+	// Accept sets the invited user's password, activating them
+	Accept(ctx context.Context, req AcceptInvitationRequest) error
+}
+
+type service struct {
+	invitationRepo domain.InvitationRepository
+	userRepo       domain.UserRepository
+	tokenRepo      domain.SecurityTokenRepository
+	notifier       notify.Notifier
+	appURL         string
+}
+
+// NewService builds invitation links on appURL, the web app's address. Invitations are not
+// available without it.
+func NewService(invitationRepo domain.InvitationRepository, userRepo domain.UserRepository, tokenRepo domain.SecurityTokenRepository, notifier notify.Notifier, appURL string) Service {
+	return &service{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		notifier:       notifier,
+		appURL:         appURL,
+	}
+}
+
+func (s *service) Invite(ctx context.Context, req InviteRequest) (*InvitationResponse, error) {
+	if s.appURL == "" {
+		return nil, ErrInvitationsUnavailable
+	}
+
+	inviter, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil && existingUser != nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	now := time.Now()
+	user := &domain.User{
+		Name:      strings.TrimSpace(req.Name),
+		Email:     email,
+		Role:      domain.UserRole(req.Role),
+		Company:   []primitive.ObjectID{},
+		Locale:    req.Locale,
+		InvitedAt: &now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.Invitation{
+		User:      user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		InvitedBy: inviter.ID,
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	if err := s.send(ctx, invitation, user, inviter); err != nil {
+		return nil, err
+	}
+	return ToInvitationResponse(invitation), nil
+}
+
+func (s *service) GetInvitations(ctx context.Context, pending bool, skip, limit int) ([]*InvitationResponse, int, error) {
+	invitations, total, err := s.invitationRepo.GetPage(ctx, pending, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = ToInvitationResponse(invitation)
+	}
+	return responses, total, nil
+}
+
+func (s *service) Resend(ctx context.Context, id string) (*InvitationResponse, error) {
+	if s.appURL == "" {
+		return nil, ErrInvitationsUnavailable
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidInvitationID
+	}
+
+	invitation, err := s.invitationRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, ErrAlreadyAccepted
+	}
+
+	user, err := s.userRepo.GetByID(ctx, invitation.User)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeactivatedAt != nil {
+		return nil, ErrUserDeactivated
+	}
+	inviter, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the latest link works
+	if err := s.tokenRepo.DeleteByUser(ctx, domain.TokenInvitation, user.ID); err != nil {
+		return nil, err
+	}
+	if err := s.send(ctx, invitation, user, inviter); err != nil {
+		return nil, err
+	}
+	return ToInvitationResponse(invitation), nil
+}
+
+func (s *service) Accept(ctx context.Context, req AcceptInvitationRequest) error {
+	token, err := s.tokenRepo.GetValid(ctx, domain.TokenInvitation, req.Token)
+	if err != nil {
+		if hasCode(err, "INVALID_TOKEN") {
+			return ErrInvitationInvalid
+		}
+		return err
+	}
+
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		if hasCode(err, "USER_NOT_FOUND") {
+			return ErrInvitationInvalid
+		}
+		return err
+	}
+	if user.InvitedAt == nil {
+		return ErrInvitationInvalid
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	user.InvitedAt = nil
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.DeleteByUser(ctx, domain.TokenInvitation, user.ID); err != nil {
+		return err
+	}
+
+	invitation, err := s.invitationRepo.GetByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	invitation.AcceptedAt = &now
+	return s.invitationRepo.Update(ctx, invitation)
+}
+
+// send emails the user a new invitation link and records it on the invitation.
+func (s *service) send(ctx context.Context, invitation *domain.Invitation, user, inviter *domain.User) error {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return errors.New("RANDOM_GENERATION_ERROR", "Failed to generate invitation link", 500, err, nil)
+	}
+	token := hex.EncodeToString(bytes)
+
+	expiresAt := time.Now().Add(invitationTTL)
+	if err := s.tokenRepo.Create(ctx, &domain.SecurityToken{
+		Kind:      domain.TokenInvitation,
+		Token:     token,
+		UserID:    user.ID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	invitation.Sends++
+	invitation.ExpiresAt = expiresAt
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/invitations/accept?token=" + url.QueryEscape(token)
+	return s.notifier.SendInvitation(ctx, user, inviter.Name, link, invitationTTL)
+}
+
+// caller is the logged-in user, named in the invitation emails they send.
+func (s *service) caller(ctx context.Context) (*domain.User, error) {
+	userCtx, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+	id, err := primitive.ObjectIDFromHex(userCtx.UserID)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+	return s.userRepo.GetByID(ctx, id)
+}
+
+func hasCode(err error, code string) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Code() == code
+}
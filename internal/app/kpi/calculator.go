@@ -0,0 +1,29 @@
+package kpi
+
+import (
+	"context"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Calculator keeps KPI values current: it consumes report events from the outbox, computing
+// the KPIs of reports as they are created or updated and removing the values of deleted ones.
+type Calculator struct {
+	service Service
+}
+
+func NewCalculator(service Service) *Calculator {
+	return &Calculator{service: service}
+}
+
+// Publish handles report.created, report.updated and report.deleted events and ignores other
+// events. Computing a report again replaces its values, so redelivery is harmless.
+func (c *Calculator) Publish(ctx context.Context, event *domain.Event) error {
+	switch event.Type {
+	case domain.EventReportCreated, domain.EventReportUpdated:
+		return c.service.ComputeReport(ctx, event.AggregateID)
+	case domain.EventReportDeleted:
+		return c.service.RemoveReport(ctx, event.AggregateID)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package kpi
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidKPIID        = errors.New("INVALID_KPI_ID", "Invalid KPI ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidCompanyID    = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+)
@@ -0,0 +1,142 @@
+package kpi
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers KPI routes
+// @Tags KPIs
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/kpis", h.GetKPIs).Methods("GET")
+	protected.HandleFunc("/api/kpis", h.CreateKPI).Methods("POST")
+	protected.HandleFunc("/api/kpis/{id}", h.GetKPIByID).Methods("GET")
+	protected.HandleFunc("/api/kpis/{id}", h.UpdateKPI).Methods("PUT")
+	protected.HandleFunc("/api/kpis/{id}", h.DeleteKPI).Methods("DELETE")
+	protected.HandleFunc("/api/company/{id}/kpis", h.GetTrends).Methods("GET")
+}
+
+// @Summary Get the KPIs of the organization
+func (h *Handler) GetKPIs(w http.ResponseWriter, r *http.Request) {
+	kpis, err := h.service.GetKPIs(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, kpis)
+}
+
+// CreateKPI defines a KPI as a formula over report line items, e.g. "[EBITDA] / [Revenue] * 100".
+// It is computed for existing reports right away and for every report saved afterwards.
+func (h *Handler) CreateKPI(w http.ResponseWriter, r *http.Request) {
+	var req CreateKPIRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	kpi, err := h.service.CreateKPI(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "KPI created successfully",
+		"kpi":     kpi,
+	})
+}
+
+// @Summary Get KPI by ID
+func (h *Handler) GetKPIByID(w http.ResponseWriter, r *http.Request) {
+	kpi, err := h.service.GetKPIByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, kpi)
+}
+
+// @Summary Update KPI
+func (h *Handler) UpdateKPI(w http.ResponseWriter, r *http.Request) {
+	var req UpdateKPIRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	kpi, err := h.service.UpdateKPI(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "KPI updated successfully",
+		"kpi":     kpi,
+	})
+}
+
+// @Summary Delete KPI and its values
+func (h *Handler) DeleteKPI(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteKPI(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrends returns the value of each KPI of a company per year, for dashboard charts
+// @Param kpi query string false "Only the trend of this KPI"
+func (h *Handler) GetTrends(w http.ResponseWriter, r *http.Request) {
+	trends, err := h.service.GetTrends(r.Context(), mux.Vars(r)["id"], r.URL.Query().Get("kpi"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, trends)
+}
+
+// requester is the ID of the user making the request, recorded as the creator of KPIs.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
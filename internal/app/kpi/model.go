@@ -0,0 +1,79 @@
+package kpi
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateKPIRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description,omitempty" validate:"max=500"`
+	// Formula computes the KPI from the line items of a report, e.g.
+	// "[EBITDA] / [Revenue] * 100"
+	Formula    string  `json:"formula" validate:"required,max=1000"`
+	Unit       string  `json:"unit,omitempty" validate:"max=20"`
+	ReportType *string `json:"reportType,omitempty"` // only reports of this type; every report when unset
+}
+
+type UpdateKPIRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	Formula     *string `json:"formula,omitempty" validate:"omitempty,min=1,max=1000"`
+	Unit        *string `json:"unit,omitempty" validate:"omitempty,max=20"`
+	ReportType  *string `json:"reportType,omitempty"` // empty for every report
+}
+
+// Response DTOs
+type KPIResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Formula     string    `json:"formula"`
+	Unit        string    `json:"unit,omitempty"`
+	ReportType  *string   `json:"reportType"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TrendResponse is a KPI of a company over the years.
+type TrendResponse struct {
+	KPI     NamedRef     `json:"kpi"`
+	Unit    string       `json:"unit,omitempty"`
+	Company string       `json:"company"`
+	Points  []TrendPoint `json:"points"`
+}
+
+// TrendPoint is the value of a KPI for a year: the one computed last from a report of the
+// company for the year, when several apply.
+type TrendPoint struct {
+	Year       int       `json:"year"`
+	Value      float64   `json:"value"`
+	Report     string    `json:"report"`
+	ComputedAt time.Time `json:"computedAt"`
+}
+
+type NamedRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func ToKPIResponse(kpi *domain.KPI) *KPIResponse {
+	response := &KPIResponse{
+		ID:          kpi.ID.Hex(),
+		Name:        kpi.Name,
+		Description: kpi.Description,
+		Formula:     kpi.Formula,
+		Unit:        kpi.Unit,
+		CreatedBy:   kpi.CreatedBy.Hex(),
+		CreatedAt:   kpi.CreatedAt,
+		UpdatedAt:   kpi.UpdatedAt,
+	}
+	if kpi.ReportType != nil {
+		reportType := kpi.ReportType.Hex()
+		response.ReportType = &reportType
+	}
+	return response
+}
@@ -0,0 +1,372 @@
+package kpi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/formula"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+type Service interface {
+	// CreateKPI defines a KPI for the organization of the caller and computes it for the
+	// organization's existing reports.
+	CreateKPI(ctx context.Context, req CreateKPIRequest, createdBy primitive.ObjectID) (*KPIResponse, error)
+	GetKPIs(ctx context.Context) ([]*KPIResponse, error)
+	GetKPIByID(ctx context.Context, id string) (*KPIResponse, error)
+	// UpdateKPI changes a KPI, computing it again for existing reports when its formula or
+	// report type changed.
+	UpdateKPI(ctx context.Context, id string, req UpdateKPIRequest) (*KPIResponse, error)
+	DeleteKPI(ctx context.Context, id string) error
+	// GetTrends returns the yearly values of the KPIs of a company, or of one KPI when kpi
+	// is set.
+	GetTrends(ctx context.Context, company, kpi string) ([]*TrendResponse, error)
+
+	// ComputeReport computes every KPI that applies to a report, replacing its values.
+	ComputeReport(ctx context.Context, reportID primitive.ObjectID) error
+	// RemoveReport removes the values computed from a deleted report.
+	RemoveReport(ctx context.Context, reportID primitive.ObjectID) error
+}
+
+type service struct {
+	kpiRepo        domain.KPIRepository
+	reportRepo     domain.ReportRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+}
+
+func NewService(kpiRepo domain.KPIRepository, reportRepo domain.ReportRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	return &service{
+		kpiRepo:        kpiRepo,
+		reportRepo:     reportRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+}
+
+func (s *service) CreateKPI(ctx context.Context, req CreateKPIRequest, createdBy primitive.ObjectID) (*KPIResponse, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := parseFormula(req.Formula); err != nil {
+		return nil, err
+	}
+
+	kpi := &domain.KPI{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Formula:     strings.TrimSpace(req.Formula),
+		Unit:        strings.TrimSpace(req.Unit),
+		CreatedBy:   createdBy,
+	}
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		kpi.Organization = tenant.Stamp()
+	}
+	if req.ReportType != nil && *req.ReportType != "" {
+		reportType, err := s.reportType(ctx, *req.ReportType)
+		if err != nil {
+			return nil, err
+		}
+		kpi.ReportType = &reportType
+	}
+
+	if err := s.kpiRepo.Create(ctx, kpi); err != nil {
+		return nil, err
+	}
+	s.backfill(ctx, kpi)
+
+	return ToKPIResponse(kpi), nil
+}
+
+func (s *service) GetKPIs(ctx context.Context) ([]*KPIResponse, error) {
+	kpis, err := s.kpiRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*KPIResponse, len(kpis))
+	for i, kpi := range kpis {
+		responses[i] = ToKPIResponse(kpi)
+	}
+	return responses, nil
+}
+
+func (s *service) GetKPIByID(ctx context.Context, id string) (*KPIResponse, error) {
+	kpi, err := s.getKPI(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToKPIResponse(kpi), nil
+}
+
+func (s *service) UpdateKPI(ctx context.Context, id string, req UpdateKPIRequest) (*KPIResponse, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	kpi, err := s.getKPI(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recompute := false
+	if req.Name != nil {
+		kpi.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		kpi.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Unit != nil {
+		kpi.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.Formula != nil {
+		if _, err := parseFormula(*req.Formula); err != nil {
+			return nil, err
+		}
+		kpi.Formula = strings.TrimSpace(*req.Formula)
+		recompute = true
+	}
+	if req.ReportType != nil {
+		kpi.ReportType = nil
+		if *req.ReportType != "" {
+			reportType, err := s.reportType(ctx, *req.ReportType)
+			if err != nil {
+				return nil, err
+			}
+			kpi.ReportType = &reportType
+		}
+		recompute = true
+	}
+
+	if err := s.kpiRepo.Update(ctx, kpi.ID, kpi); err != nil {
+		return nil, err
+	}
+	if recompute {
+		s.backfill(ctx, kpi)
+	}
+
+	return ToKPIResponse(kpi), nil
+}
+
+func (s *service) DeleteKPI(ctx context.Context, id string) error {
+	if err := authorize(ctx); err != nil {
+		return err
+	}
+	kpi, err := s.getKPI(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.kpiRepo.Delete(ctx, kpi.ID)
+}
+
+func (s *service) GetTrends(ctx context.Context, company, kpi string) ([]*TrendResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	// The company must be one the caller can see
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+
+	var kpis []*domain.KPI
+	var only *primitive.ObjectID
+	if kpi != "" {
+		one, err := s.getKPI(ctx, kpi)
+		if err != nil {
+			return nil, err
+		}
+		kpis, only = []*domain.KPI{one}, &one.ID
+	} else if kpis, err = s.kpiRepo.GetAll(ctx); err != nil {
+		return nil, err
+	}
+
+	trends := make([]*TrendResponse, len(kpis))
+	byKPI := map[primitive.ObjectID]*TrendResponse{}
+	for i, definition := range kpis {
+		trends[i] = &TrendResponse{
+			KPI:     NamedRef{ID: definition.ID.Hex(), Name: definition.Name},
+			Unit:    definition.Unit,
+			Company: companyID.Hex(),
+			Points:  []TrendPoint{},
+		}
+		byKPI[definition.ID] = trends[i]
+	}
+
+	values, err := s.kpiRepo.GetValues(ctx, companyID, only)
+	if err != nil {
+		return nil, err
+	}
+
+	// Values come by year and then computation, so the last of a year replaces the others
+	for _, value := range values {
+		trend, ok := byKPI[value.KPI]
+		if !ok {
+			continue
+		}
+		point := TrendPoint{Year: value.Year, Value: value.Value, Report: value.Report.Hex(), ComputedAt: value.ComputedAt}
+		if n := len(trend.Points); n > 0 && trend.Points[n-1].Year == value.Year {
+			trend.Points[n-1] = point
+		} else {
+			trend.Points = append(trend.Points, point)
+		}
+	}
+
+	return trends, nil
+}
+
+func (s *service) ComputeReport(ctx context.Context, reportID primitive.ObjectID) error {
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil // deleted since; its report.deleted event removes the values
+		}
+		return err
+	}
+	if report.Company == nil || report.ReportType == nil {
+		return nil
+	}
+	company, err := s.companyRepo.GetByID(ctx, report.Company.ID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	kpis, err := s.kpiRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	// KPIs that no longer apply, as the report moved to another type or company, lose
+	// their values
+	items := domain.LineItems(report.ReportData)
+	for _, kpi := range kpis {
+		if !kpi.AppliesTo(company.Organization, report.ReportType.ID) {
+			err = s.kpiRepo.DeleteValues(ctx, report.ID, &kpi.ID)
+		} else {
+			err = s.compute(ctx, kpi, report, items)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) RemoveReport(ctx context.Context, reportID primitive.ObjectID) error {
+	return s.kpiRepo.DeleteValues(ctx, reportID, nil)
+}
+
+// compute stores the value of kpi computed from the line items of report, or removes its
+// previous value when the report lacks a line item the formula needs or it divides by zero.
+func (s *service) compute(ctx context.Context, kpi *domain.KPI, report *domain.PopulatedReport, items map[string]float64) error {
+	parsed, err := formula.Parse(kpi.Formula)
+	if err != nil {
+		return s.kpiRepo.DeleteValues(ctx, report.ID, &kpi.ID)
+	}
+
+	value, err := parsed.Eval(func(name string) (float64, bool) {
+		amount, ok := items[domain.LineItemName(name)]
+		return amount, ok
+	})
+	if err != nil {
+		return s.kpiRepo.DeleteValues(ctx, report.ID, &kpi.ID)
+	}
+
+	return s.kpiRepo.SaveValue(ctx, &domain.KPIValue{
+		KPI:        kpi.ID,
+		Company:    report.Company.ID,
+		Report:     report.ID,
+		Year:       report.Year,
+		Value:      value,
+		ComputedAt: time.Now(),
+	})
+}
+
+// backfill computes a new or changed KPI for the existing reports it applies to. It reads
+// every report of the tenant, not only those of the companies of the admin who changed the
+// KPI, and logs failures: the KPI itself is saved, and reports saved later are computed anyway.
+func (s *service) backfill(ctx context.Context, kpi *domain.KPI) {
+	readCtx := context.Background()
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		readCtx = domain.WithTenant(readCtx, tenant)
+	}
+	readCtx = domain.WithReportData(readCtx)
+
+	organizations := map[primitive.ObjectID]*primitive.ObjectID{}
+	computed := 0
+	err := s.reportRepo.Each(readCtx, func(report *domain.PopulatedReport) error {
+		if report.Company == nil || report.ReportType == nil {
+			return nil
+		}
+		organization, ok := organizations[report.Company.ID]
+		if !ok {
+			company, err := s.companyRepo.GetByID(readCtx, report.Company.ID)
+			if isNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			organization = company.Organization
+			organizations[report.Company.ID] = organization
+		}
+
+		if !kpi.AppliesTo(organization, report.ReportType.ID) {
+			return s.kpiRepo.DeleteValues(ctx, report.ID, &kpi.ID)
+		}
+		computed++
+		return s.compute(ctx, kpi, report, domain.LineItems(report.ReportData))
+	})
+	if err != nil {
+		log.Errorf(ctx, "Failed to compute KPI %s for existing reports: %v", kpi.ID.Hex(), err)
+		return
+	}
+	log.Infof(ctx, "Computed KPI %s for %d existing reports", kpi.ID.Hex(), computed)
+}
+
+func (s *service) getKPI(ctx context.Context, id string) (*domain.KPI, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidKPIID
+	}
+	return s.kpiRepo.GetByID(ctx, objectID)
+}
+
+func (s *service) reportType(ctx context.Context, id string) (primitive.ObjectID, error) {
+	reportTypeID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidReportTypeID
+	}
+	if _, err := s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return reportTypeID, nil
+}
+
+// authorize checks that the caller may define the KPIs of their organization.
+func authorize(ctx context.Context) error {
+	return middleware.Authorize(ctx, "manage", policy.Resource{Type: "kpi"})
+}
+
+func parseFormula(src string) (*formula.Formula, error) {
+	parsed, err := formula.Parse(src)
+	if err != nil {
+		return nil, errors.New("INVALID_FORMULA", "Formula is invalid", 400, err, map[string]interface{}{"reason": err.Error()})
+	}
+	return parsed, nil
+}
+
+func isNotFound(err error) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Status() == http.StatusNotFound
+}
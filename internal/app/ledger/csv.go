@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// maxCSVLines caps the lines of an uploaded trial balance like the JSON request does
+const maxCSVLines = 10000
+
+// ParseTrialBalanceCSV reads the lines of a trial balance exported as CSV. The header names
+// the columns: account (or code), debit and credit; other columns, like the account name,
+// are ignored. Empty amounts are zero, and thousands separators are allowed.
+func ParseTrialBalanceCSV(r io.Reader) ([]TrialBalanceLineRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, csvError("The file is empty", 1, nil)
+	}
+	if err != nil {
+		return nil, csvError("The file is not valid CSV", 1, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "code" {
+			name = "account"
+		}
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{"account", "debit", "credit"} {
+		if _, ok := columns[required]; !ok {
+			return nil, csvError("The header must name the account, debit and credit columns", 1, nil)
+		}
+	}
+
+	lines := []TrialBalanceLineRequest{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvError("The file is not valid CSV", row, err)
+		}
+
+		account := field(record, columns["account"])
+		if account == "" {
+			continue // blank and total rows
+		}
+		debit, err := amount(field(record, columns["debit"]))
+		if err != nil {
+			return nil, csvError("Debit is not a number", row, err)
+		}
+		credit, err := amount(field(record, columns["credit"]))
+		if err != nil {
+			return nil, csvError("Credit is not a number", row, err)
+		}
+
+		if len(lines) == maxCSVLines {
+			return nil, csvError("The file has too many lines", row, nil)
+		}
+		lines = append(lines, TrialBalanceLineRequest{Account: account, Debit: debit, Credit: credit})
+	}
+
+	return lines, nil
+}
+
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func amount(value string) (float64, error) {
+	value = strings.ReplaceAll(value, ",", "")
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func csvError(message string, row int, err error) error {
+	return errors.New("INVALID_CSV", message, 400, err, map[string]interface{}{"row": row})
+}
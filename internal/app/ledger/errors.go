@@ -0,0 +1,13 @@
+package ledger
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidCompanyID      = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID   = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidTrialBalanceID = errors.New("INVALID_TRIAL_BALANCE_ID", "Invalid trial balance ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidYear           = errors.New("INVALID_YEAR", "Year must be a number between 1900 and 2200", http.StatusBadRequest, nil, nil)
+)
@@ -0,0 +1,177 @@
+package ledger
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers chart of accounts and trial balance routes
+// @Tags Ledger
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/company/{id}/accounts", h.GetChart).Methods("GET")
+	protected.HandleFunc("/api/company/{id}/accounts", h.SaveChart).Methods("PUT")
+
+	protected.HandleFunc("/api/trial-balances", h.GetTrialBalances).Methods("GET")
+	protected.HandleFunc("/api/trial-balances", h.IngestTrialBalance).Methods("POST")
+	protected.HandleFunc("/api/trial-balances/csv", h.IngestTrialBalanceCSV).Methods("POST")
+	protected.HandleFunc("/api/trial-balances/{id}", h.GetTrialBalanceByID).Methods("GET")
+}
+
+// @Summary Get a company's chart of accounts
+func (h *Handler) GetChart(w http.ResponseWriter, r *http.Request) {
+	chart, err := h.service.GetChart(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, chart)
+}
+
+// SaveChart replaces a company's chart of accounts, which trial balances are mapped to.
+// Admins manage those of their companies.
+func (h *Handler) SaveChart(w http.ResponseWriter, r *http.Request) {
+	var req SaveChartRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	chart, err := h.service.SaveChart(r.Context(), mux.Vars(r)["id"], req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Chart of accounts saved successfully",
+		"chart":   chart,
+	})
+}
+
+// GetTrialBalances lists the trial balances of the companies the user can see, without lines
+// @Param company query string false "Only the trial balances of this company"
+func (h *Handler) GetTrialBalances(w http.ResponseWriter, r *http.Request) {
+	trialBalances, err := h.service.GetTrialBalances(r.Context(), r.URL.Query().Get("company"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, trialBalances)
+}
+
+// IngestTrialBalance stores a company's trial balance for a year and generates the balance
+// sheet and P&L reports of the report types named in generate
+func (h *Handler) IngestTrialBalance(w http.ResponseWriter, r *http.Request) {
+	var req IngestTrialBalanceRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	h.ingest(w, r, req)
+}
+
+// IngestTrialBalanceCSV ingests a trial balance exported as CSV, with account, debit and credit
+// columns, from the multipart field "file", generating reports like the JSON upload
+// @Param company query string true "Company of the trial balance"
+// @Param year query integer true "Year of the trial balance"
+// @Param currency query string false "Currency of the amounts"
+// @Param balanceSheet query string false "Report type of the balance sheet to generate"
+// @Param profitAndLoss query string false "Report type of the P&L to generate"
+func (h *Handler) IngestTrialBalanceCSV(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := IngestTrialBalanceRequest{
+		Company: query.Get("company"),
+		Generate: GenerateRequest{
+			BalanceSheet:  query.Get("balanceSheet"),
+			ProfitAndLoss: query.Get("profitAndLoss"),
+		},
+	}
+	if year := query.Get("year"); year != "" {
+		var err error
+		if req.Year, err = strconv.Atoi(year); err != nil {
+			utils.HandleHTTPError(w, ErrInvalidYear, r)
+			return
+		}
+	}
+	if currency := query.Get("currency"); currency != "" {
+		req.Currency = &currency
+	}
+
+	file, _, err := utils.MultipartFile(r, "file")
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+	if req.Lines, err = ParseTrialBalanceCSV(file); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	h.ingest(w, r, req)
+}
+
+func (h *Handler) ingest(w http.ResponseWriter, r *http.Request, req IngestTrialBalanceRequest) {
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	result, err := h.service.IngestTrialBalance(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, result)
+}
+
+// @Summary Get trial balance by ID
+func (h *Handler) GetTrialBalanceByID(w http.ResponseWriter, r *http.Request) {
+	trialBalance, err := h.service.GetTrialBalanceByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, trialBalance)
+}
+
+// requester is the ID of the user making the request, recorded on charts, trial balances and
+// the reports generated from them.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
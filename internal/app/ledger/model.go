@@ -0,0 +1,103 @@
+package ledger
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/app/report"
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type AccountRequest struct {
+	Code     string `json:"code" validate:"required,max=50"`
+	Name     string `json:"name" validate:"required,max=200"`
+	Category string `json:"category" validate:"required,oneof=ASSET LIABILITY EQUITY REVENUE EXPENSE"`
+	Group    string `json:"group,omitempty" validate:"max=200"`
+}
+
+// SaveChartRequest replaces a company's chart of accounts; accounts are listed in the order
+// of the statements generated from it.
+type SaveChartRequest struct {
+	Accounts []AccountRequest `json:"accounts" validate:"required,min=1,max=5000,dive"`
+}
+
+type TrialBalanceLineRequest struct {
+	Account string  `json:"account" validate:"required,max=50"`
+	Debit   float64 `json:"debit" validate:"min=0"`
+	Credit  float64 `json:"credit" validate:"min=0"`
+}
+
+// IngestTrialBalanceRequest is a trial balance, or a journal summary with the debit and
+// credit totals of each account, of a company's year. Lines of the same account are added up.
+type IngestTrialBalanceRequest struct {
+	Company  string                    `json:"company" validate:"required"`
+	Year     int                       `json:"year" validate:"required,min=1900,max=2200"`
+	Currency *string                   `json:"currency,omitempty"`
+	Lines    []TrialBalanceLineRequest `json:"lines" validate:"required,min=1,max=10000,dive"`
+	Generate GenerateRequest           `json:"generate"`
+}
+
+// GenerateRequest names the report types of the statements to generate from a trial balance;
+// statements without one are not generated.
+type GenerateRequest struct {
+	BalanceSheet  string `json:"balanceSheet,omitempty"`
+	ProfitAndLoss string `json:"profitAndLoss,omitempty"`
+}
+
+// Response DTOs
+type ChartOfAccountsResponse struct {
+	Company   string                 `json:"company"`
+	Accounts  []domain.Account       `json:"accounts"`
+	Template  *domain.TemplateOrigin `json:"template"`
+	UpdatedBy string                 `json:"updatedBy"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+type TrialBalanceResponse struct {
+	ID        string                    `json:"id"`
+	Company   string                    `json:"company"`
+	Year      int                       `json:"year"`
+	Currency  *string                   `json:"currency"`
+	Debit     float64                   `json:"debit"`           // total
+	Credit    float64                   `json:"credit"`          // total
+	Lines     []domain.TrialBalanceLine `json:"lines,omitempty"` // left out of lists
+	Reports   []string                  `json:"reports"`         // generated from the trial balance
+	CreatedBy string                    `json:"createdBy"`
+	CreatedAt time.Time                 `json:"createdAt"`
+}
+
+// IngestResponse is an ingested trial balance and the reports generated from it.
+type IngestResponse struct {
+	TrialBalance *TrialBalanceResponse    `json:"trialBalance"`
+	Reports      []*report.ReportResponse `json:"reports"`
+}
+
+func ToChartOfAccountsResponse(chart *domain.ChartOfAccounts) *ChartOfAccountsResponse {
+	return &ChartOfAccountsResponse{
+		Company:   chart.Company.Hex(),
+		Accounts:  chart.Accounts,
+		Template:  chart.Template,
+		UpdatedBy: chart.UpdatedBy.Hex(),
+		UpdatedAt: chart.UpdatedAt,
+	}
+}
+
+func ToTrialBalanceResponse(trialBalance *domain.TrialBalance) *TrialBalanceResponse {
+	reports := make([]string, len(trialBalance.Reports))
+	for i, id := range trialBalance.Reports {
+		reports[i] = id.Hex()
+	}
+
+	return &TrialBalanceResponse{
+		ID:        trialBalance.ID.Hex(),
+		Company:   trialBalance.Company.Hex(),
+		Year:      trialBalance.Year,
+		Currency:  trialBalance.Currency,
+		Debit:     trialBalance.Debit,
+		Credit:    trialBalance.Credit,
+		Lines:     trialBalance.Lines,
+		Reports:   reports,
+		CreatedBy: trialBalance.CreatedBy.Hex(),
+		CreatedAt: trialBalance.CreatedAt,
+	}
+}
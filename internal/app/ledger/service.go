@@ -0,0 +1,257 @@
+package ledger
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/app/report"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type Service interface {
+	GetChart(ctx context.Context, company string) (*ChartOfAccountsResponse, error)
+	SaveChart(ctx context.Context, company string, req SaveChartRequest, updatedBy primitive.ObjectID) (*ChartOfAccountsResponse, error)
+	// IngestTrialBalance stores a trial balance mapped to the company's chart of accounts and
+	// generates the requested statements from it as reports created by createdBy.
+	IngestTrialBalance(ctx context.Context, req IngestTrialBalanceRequest, createdBy primitive.ObjectID) (*IngestResponse, error)
+	// GetTrialBalances lists the trial balances of a company, or of every company the caller
+	// can see when company is empty, without their lines.
+	GetTrialBalances(ctx context.Context, company string) ([]*TrialBalanceResponse, error)
+	GetTrialBalanceByID(ctx context.Context, id string) (*TrialBalanceResponse, error)
+}
+
+type service struct {
+	ledgerRepo     domain.LedgerRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+	reportService  report.Service
+}
+
+func NewService(ledgerRepo domain.LedgerRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository, reportService report.Service) Service {
+	return &service{
+		ledgerRepo:     ledgerRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+		reportService:  reportService,
+	}
+}
+
+func (s *service) GetChart(ctx context.Context, company string) (*ChartOfAccountsResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+
+	chart, err := s.ledgerRepo.GetChart(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return ToChartOfAccountsResponse(chart), nil
+}
+
+func (s *service) SaveChart(ctx context.Context, company string, req SaveChartRequest, updatedBy primitive.ObjectID) (*ChartOfAccountsResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, companyID); err != nil {
+		return nil, err
+	}
+
+	chart := &domain.ChartOfAccounts{
+		Company:   companyID,
+		Accounts:  make([]domain.Account, len(req.Accounts)),
+		UpdatedBy: updatedBy,
+	}
+	codes := map[string]bool{}
+	for i, account := range req.Accounts {
+		code := strings.TrimSpace(account.Code)
+		if codes[code] {
+			return nil, errors.New("DUPLICATE_ACCOUNT", "Account codes must be unique", 400, nil, map[string]interface{}{"code": code})
+		}
+		codes[code] = true
+
+		chart.Accounts[i] = domain.Account{
+			Code:     code,
+			Name:     strings.TrimSpace(account.Name),
+			Category: domain.AccountCategory(account.Category),
+			Group:    strings.TrimSpace(account.Group),
+		}
+	}
+
+	if err := s.ledgerRepo.SaveChart(ctx, chart); err != nil {
+		return nil, err
+	}
+	return ToChartOfAccountsResponse(chart), nil
+}
+
+func (s *service) IngestTrialBalance(ctx context.Context, req IngestTrialBalanceRequest, createdBy primitive.ObjectID) (*IngestResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(req.Company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, companyID); err != nil {
+		return nil, err
+	}
+
+	chart, err := s.ledgerRepo.GetChart(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report types of the statements to generate, checked up front so nothing is stored for
+	// a bad request
+	statements := []*statement{}
+	for _, generate := range []*statement{
+		{requested: req.Generate.BalanceSheet, build: (*domain.TrialBalance).BalanceSheet},
+		{requested: req.Generate.ProfitAndLoss, build: (*domain.TrialBalance).ProfitAndLoss},
+	} {
+		if generate.requested == "" {
+			continue
+		}
+		reportTypeID, err := primitive.ObjectIDFromHex(generate.requested)
+		if err != nil {
+			return nil, ErrInvalidReportTypeID
+		}
+		if generate.reportType, err = s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+			return nil, err
+		}
+		statements = append(statements, generate)
+	}
+
+	trialBalance := &domain.TrialBalance{
+		Company:   companyID,
+		Year:      req.Year,
+		Currency:  req.Currency,
+		Lines:     mergeLines(req.Lines),
+		CreatedBy: createdBy,
+	}
+
+	unknown := []string{}
+	for _, line := range trialBalance.Lines {
+		if chart.Account(line.Account) == nil {
+			unknown = append(unknown, line.Account)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, errors.New("UNKNOWN_ACCOUNTS", "Trial balance has accounts missing from the chart of accounts", 400, nil, map[string]interface{}{"accounts": unknown})
+	}
+
+	trialBalance.Debit, trialBalance.Credit = trialBalance.Totals()
+	if !trialBalance.Balanced() {
+		return nil, errors.New("UNBALANCED_TRIAL_BALANCE", "Total debits and credits of the trial balance differ", 400, nil, map[string]interface{}{
+			"debit":  trialBalance.Debit,
+			"credit": trialBalance.Credit,
+		})
+	}
+
+	if err := s.ledgerRepo.CreateTrialBalance(ctx, trialBalance); err != nil {
+		return nil, err
+	}
+
+	// The generated reports trace back to the trial balance
+	ctx = domain.WithLineage(ctx, &domain.ReportLineage{Source: domain.LineageTrialBalance, TrialBalance: &trialBalance.ID})
+	reports := []*report.ReportResponse{}
+	for _, generate := range statements {
+		created, err := s.reportService.CreateReport(ctx, report.CreateReportRequest{
+			ReportName: generate.reportType.Name + " " + strconv.Itoa(req.Year),
+			ReportType: generate.reportType.ID.Hex(),
+			Year:       strconv.Itoa(req.Year),
+			Company:    companyID.Hex(),
+			Currency:   req.Currency,
+			CreateBy:   createdBy.Hex(),
+			ReportData: generate.build(trialBalance, chart),
+		})
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, created)
+
+		reportID, _ := primitive.ObjectIDFromHex(created.ID)
+		trialBalance.Reports = append(trialBalance.Reports, reportID)
+		if err := s.ledgerRepo.SetReports(ctx, trialBalance.ID, trialBalance.Reports); err != nil {
+			return nil, err
+		}
+	}
+
+	return &IngestResponse{TrialBalance: ToTrialBalanceResponse(trialBalance), Reports: reports}, nil
+}
+
+func (s *service) GetTrialBalances(ctx context.Context, company string) ([]*TrialBalanceResponse, error) {
+	var companyID *primitive.ObjectID
+	if company != "" {
+		id, err := primitive.ObjectIDFromHex(company)
+		if err != nil {
+			return nil, ErrInvalidCompanyID
+		}
+		companyID = &id
+	}
+
+	trialBalances, err := s.ledgerRepo.GetTrialBalances(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*TrialBalanceResponse, len(trialBalances))
+	for i, trialBalance := range trialBalances {
+		responses[i] = ToTrialBalanceResponse(trialBalance)
+	}
+	return responses, nil
+}
+
+func (s *service) GetTrialBalanceByID(ctx context.Context, id string) (*TrialBalanceResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidTrialBalanceID
+	}
+
+	trialBalance, err := s.ledgerRepo.GetTrialBalance(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	return ToTrialBalanceResponse(trialBalance), nil
+}
+
+// statement is a financial statement to generate as a report of reportType, requested by ID.
+type statement struct {
+	requested  string
+	reportType *domain.ReportType
+	build      func(*domain.TrialBalance, *domain.ChartOfAccounts) domain.Statement
+}
+
+// authorize checks that the caller may manage the ledger of the company.
+func authorize(ctx context.Context, companyID primitive.ObjectID) error {
+	return middleware.Authorize(ctx, "manage", policy.Resource{Type: "ledger", Companies: []string{companyID.Hex()}})
+}
+
+// mergeLines adds up the lines of each account, as journal summaries may list an account
+// once per journal, and orders them by account code.
+func mergeLines(requests []TrialBalanceLineRequest) []domain.TrialBalanceLine {
+	index := map[string]int{}
+	lines := []domain.TrialBalanceLine{}
+	for _, request := range requests {
+		account := strings.TrimSpace(request.Account)
+		if i, ok := index[account]; ok {
+			lines[i].Debit += request.Debit
+			lines[i].Credit += request.Credit
+			continue
+		}
+		index[account] = len(lines)
+		lines = append(lines, domain.TrialBalanceLine{Account: account, Debit: request.Debit, Credit: request.Credit})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Account < lines[j].Account })
+	return lines
+}
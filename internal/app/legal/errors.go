@@ -0,0 +1,11 @@
+package legal
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrUnknownDocument = errors.New("UNKNOWN_LEGAL_DOCUMENT", "No such legal document needs to be accepted", http.StatusBadRequest, nil, nil)
+	ErrInvalidUserID   = errors.New("INVALID_USER_ID", "Invalid user ID format", http.StatusBadRequest, nil, nil)
+)
@@ -0,0 +1,68 @@
+package legal
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers the legal document routes. They must stay reachable before the
+// user accepted the current versions, so authMiddleware should skip the consent check.
+// @Tags Legal
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/legal", h.GetLegalStatus).Methods("GET")
+	protected.HandleFunc("/api/legal/accept", h.AcceptDocuments).Methods("POST")
+}
+
+// GetLegalStatus lists the current terms of service and privacy policy versions, and which of
+// them the logged-in user accepted
+func (h *Handler) GetLegalStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.GetStatus(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, status)
+}
+
+// AcceptDocuments records that the logged-in user accepted the current versions of the given documents
+func (h *Handler) AcceptDocuments(w http.ResponseWriter, r *http.Request) {
+	var req AcceptRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+	req.IP = utils.ClientIP(r)
+
+	status, err := h.service.Accept(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,31 @@
+package legal
+
+import "time"
+
+// Request DTOs
+type AcceptRequest struct {
+	Documents []AcceptedDocument `json:"documents" validate:"required,min=1,dive"`
+	// IP is where the request comes from, set by the handler
+	IP string `json:"-"`
+}
+
+type AcceptedDocument struct {
+	Name    string `json:"name" validate:"required"`
+	Version string `json:"version" validate:"required"`
+}
+
+// Response DTOs
+type DocumentStatus struct {
+	Name            string     `json:"name"`
+	Version         string     `json:"version"` // current version
+	URL             string     `json:"url,omitempty"`
+	Accepted        bool       `json:"accepted"` // whether the current version was accepted
+	AcceptedVersion string     `json:"acceptedVersion,omitempty"`
+	AcceptedAt      *time.Time `json:"acceptedAt,omitempty"`
+}
+
+type StatusResponse struct {
+	Documents []DocumentStatus `json:"documents"`
+	// Accepted is false while any document still needs to be accepted; the API answers 451 until then
+	Accepted bool `json:"accepted"`
+}
@@ -0,0 +1,145 @@
+package legal
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type Service interface {
+	GetStatus(ctx context.Context) (*StatusResponse, error)
+	Accept(ctx context.Context, req AcceptRequest) (*StatusResponse, error)
+	// RequireAccepted fails with a 451 error while the user hasn't accepted the current
+	// version of every document.
+	RequireAccepted(ctx context.Context, userID string) error
+}
+
+type service struct {
+	userRepo  domain.UserRepository
+	documents []domain.LegalDocument
+}
+
+// NewService creates a service for the current versions of the legal documents. Without
+// documents there is nothing to accept and every user passes RequireAccepted.
+func NewService(userRepo domain.UserRepository, documents []domain.LegalDocument) Service {
+	return &service{
+		userRepo:  userRepo,
+		documents: documents,
+	}
+}
+
+func (s *service) GetStatus(ctx context.Context) (*StatusResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(user), nil
+}
+
+func (s *service) Accept(ctx context.Context, req AcceptRequest) (*StatusResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, accepted := range req.Documents {
+		document, ok := s.document(accepted.Name)
+		if !ok {
+			return nil, ErrUnknownDocument
+		}
+		if accepted.Version != document.Version {
+			return nil, errors.New("LEGAL_VERSION_OUTDATED", "Only the current version of a legal document can be accepted", http.StatusConflict, nil,
+				map[string]interface{}{"document": document.Name, "currentVersion": document.Version})
+		}
+		if user.HasAccepted(document) {
+			continue
+		}
+		user.Consents = append(user.Consents, domain.Consent{
+			Document:   document.Name,
+			Version:    document.Version,
+			AcceptedAt: now,
+			IP:         req.IP,
+		})
+	}
+
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return nil, err
+	}
+	return s.status(user), nil
+}
+
+func (s *service) RequireAccepted(ctx context.Context, userID string) error {
+	if len(s.documents) == 0 {
+		return nil
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var pending []domain.LegalDocument
+	for _, document := range s.documents {
+		if !user.HasAccepted(document) {
+			pending = append(pending, document)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return errors.New("CONSENT_REQUIRED", "Accept the current terms of service and privacy policy to continue", http.StatusUnavailableForLegalReasons, nil,
+		map[string]interface{}{"documents": pending, "accept": "POST /api/legal/accept"})
+}
+
+func (s *service) status(user *domain.User) *StatusResponse {
+	response := &StatusResponse{Documents: make([]DocumentStatus, 0, len(s.documents)), Accepted: true}
+	for _, document := range s.documents {
+		status := DocumentStatus{
+			Name:     document.Name,
+			Version:  document.Version,
+			URL:      document.URL,
+			Accepted: user.HasAccepted(document),
+		}
+		if latest := user.LatestConsent(document.Name); latest != nil {
+			status.AcceptedVersion = latest.Version
+			acceptedAt := latest.AcceptedAt
+			status.AcceptedAt = &acceptedAt
+		}
+		response.Accepted = response.Accepted && status.Accepted
+		response.Documents = append(response.Documents, status)
+	}
+	return response
+}
+
+func (s *service) document(name string) (domain.LegalDocument, bool) {
+	for _, document := range s.documents {
+		if document.Name == name {
+			return document, true
+		}
+	}
+	return domain.LegalDocument{}, false
+}
+
+func (s *service) currentUser(ctx context.Context) (*domain.User, error) {
+	userCtx, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("USER_CONTEXT_MISSING", "User context not found", 401, nil, nil)
+	}
+	return s.getUser(ctx, userCtx.UserID)
+}
+
+func (s *service) getUser(ctx context.Context, userID string) (*domain.User, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrInvalidUserID
+	}
+	return s.userRepo.GetByID(ctx, id)
+}
@@ -0,0 +1,19 @@
+package organization
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidOrganizationID = errors.New("INVALID_ORGANIZATION_ID", "Invalid organization ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidCompanyID      = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidUserID         = errors.New("INVALID_USER_ID", "Invalid user ID format", http.StatusBadRequest, nil, nil)
+	ErrOrganizationNotFound  = errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", http.StatusNotFound, nil, nil)
+	ErrOrganizationNotEmpty  = errors.New("ORGANIZATION_NOT_EMPTY", "Move the organization's users and companies out before deleting it", http.StatusConflict, nil, nil)
+	ErrAdminNotMember        = errors.New("ORGANIZATION_ADMIN_NOT_MEMBER", "Organization admins must be users of the organization", http.StatusBadRequest, nil, nil)
+	ErrSuperAdminMember      = errors.New("SUPER_ADMIN_NOT_ALLOWED", "Super admins belong to the instance and cannot join an organization", http.StatusBadRequest, nil, nil)
+
+	errCompanyNotMember = errors.New("COMPANY_NOT_FOUND", "Company is not part of the organization", http.StatusNotFound, nil, nil)
+	errUserNotMember    = errors.New("USER_NOT_FOUND", "User is not part of the organization", http.StatusNotFound, nil, nil)
+)
@@ -0,0 +1,166 @@
+package organization
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers organization routes
+// @Tags Organizations
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	// Members read their own organization; its admins update it
+	protected.HandleFunc("/api/organizations", h.GetOrganizations).Methods("GET")
+	protected.HandleFunc("/api/organizations/{id}", h.GetOrganizationByID).Methods("GET")
+	protected.HandleFunc("/api/organizations/{id}", h.UpdateOrganization).Methods("PUT")
+
+	// Creating organizations and moving users and companies between them spans tenants
+	adminOnly := protected.PathPrefix("").Subrouter()
+	adminOnly.Use(middleware.RequirePermission("manage", "organization"))
+	adminOnly.HandleFunc("/api/organizations", h.CreateOrganization).Methods("POST")
+	adminOnly.HandleFunc("/api/organizations/{id}", h.DeleteOrganization).Methods("DELETE")
+	adminOnly.HandleFunc("/api/organizations/{id}/companies/{companyId}", h.AddCompany).Methods("PUT")
+	adminOnly.HandleFunc("/api/organizations/{id}/companies/{companyId}", h.RemoveCompany).Methods("DELETE")
+	adminOnly.HandleFunc("/api/organizations/{id}/users/{userId}", h.AddUser).Methods("PUT")
+	adminOnly.HandleFunc("/api/organizations/{id}/users/{userId}", h.RemoveUser).Methods("DELETE")
+}
+
+// @Summary List organizations
+func (h *Handler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	organizations, err := h.service.GetOrganizations(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, organizations)
+}
+
+// @Summary Create organization
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrganizationRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	organization, err := h.service.CreateOrganization(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, organization)
+}
+
+// GetOrganizationByID returns an organization with the IDs of its users and companies
+func (h *Handler) GetOrganizationByID(w http.ResponseWriter, r *http.Request) {
+	organization, err := h.service.GetOrganizationByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, organization)
+}
+
+// UpdateOrganization changes the name, settings or admins of an organization. Admins must
+// already be users of the organization.
+func (h *Handler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req UpdateOrganizationRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	organization, err := h.service.UpdateOrganization(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, organization)
+}
+
+// DeleteOrganization deletes an organization without users or companies
+func (h *Handler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteOrganization(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Organization deleted successfully"})
+}
+
+// AddCompany moves a company, with its reports, into the organization
+func (h *Handler) AddCompany(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.AddCompany(r.Context(), vars["id"], vars["companyId"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Company added to organization"})
+}
+
+// RemoveCompany moves a company back to the instance
+func (h *Handler) RemoveCompany(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.RemoveCompany(r.Context(), vars["id"], vars["companyId"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "Company removed from organization"})
+}
+
+// AddUser moves a user into the organization
+func (h *Handler) AddUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.AddUser(r.Context(), vars["id"], vars["userId"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "User added to organization"})
+}
+
+// RemoveUser moves a user back to the instance
+func (h *Handler) RemoveUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.RemoveUser(r.Context(), vars["id"], vars["userId"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{"message": "User removed from organization"})
+}
@@ -0,0 +1,63 @@
+package organization
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateOrganizationRequest struct {
+	Name     string           `json:"name" validate:"required,min=2,max=100"`
+	Settings *SettingsRequest `json:"settings,omitempty"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name     *string          `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Settings *SettingsRequest `json:"settings,omitempty"`
+	Admins   []string         `json:"admins,omitempty"` // user IDs; replaces the current admins
+}
+
+type SettingsRequest struct {
+	Currency string `json:"currency,omitempty" validate:"omitempty,len=3,uppercase"`
+	Locale   string `json:"locale,omitempty" validate:"omitempty,min=2,max=10"`
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+// Response DTOs
+type OrganizationResponse struct {
+	ID        string                      `json:"_id"`
+	Name      string                      `json:"name"`
+	Admins    []string                    `json:"admins"`
+	Settings  domain.OrganizationSettings `json:"settings"`
+	CreatedAt time.Time                   `json:"createdAt"`
+	UpdatedAt time.Time                   `json:"updatedAt"`
+
+	// Users and Companies list the members, only when a single organization is requested
+	Users     []string `json:"users,omitempty"`
+	Companies []string `json:"companies,omitempty"`
+}
+
+func ToOrganizationResponse(organization *domain.Organization) OrganizationResponse {
+	admins := make([]string, len(organization.Admins))
+	for i, id := range organization.Admins {
+		admins[i] = id.Hex()
+	}
+
+	return OrganizationResponse{
+		ID:        organization.ID.Hex(),
+		Name:      organization.Name,
+		Admins:    admins,
+		Settings:  organization.Settings,
+		CreatedAt: organization.CreatedAt,
+		UpdatedAt: organization.UpdatedAt,
+	}
+}
+
+func (r *SettingsRequest) toDomain() domain.OrganizationSettings {
+	return domain.OrganizationSettings{
+		Currency: r.Currency,
+		Locale:   r.Locale,
+		Timezone: r.Timezone,
+	}
+}
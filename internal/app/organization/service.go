@@ -0,0 +1,336 @@
+package organization
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils"
+)
+
+type Service interface {
+	CreateOrganization(ctx context.Context, req CreateOrganizationRequest) (*OrganizationResponse, error)
+	GetOrganizations(ctx context.Context) ([]*OrganizationResponse, error)
+	GetOrganizationByID(ctx context.Context, id string) (*OrganizationResponse, error)
+	UpdateOrganization(ctx context.Context, id string, req UpdateOrganizationRequest) (*OrganizationResponse, error)
+	DeleteOrganization(ctx context.Context, id string) error
+	AddCompany(ctx context.Context, id, companyID string) error
+	RemoveCompany(ctx context.Context, id, companyID string) error
+	AddUser(ctx context.Context, id, userID string) error
+	RemoveUser(ctx context.Context, id, userID string) error
+}
+
+type service struct {
+	organizationRepo domain.OrganizationRepository
+	userRepo         domain.UserRepository
+}
+
+func NewService(organizationRepo domain.OrganizationRepository, userRepo domain.UserRepository) Service {
+	return &service{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+	}
+}
+
+func (s *service) CreateOrganization(ctx context.Context, req CreateOrganizationRequest) (*OrganizationResponse, error) {
+	organization := &domain.Organization{Name: req.Name}
+	if req.Settings != nil {
+		organization.Settings = req.Settings.toDomain()
+	}
+
+	if err := s.organizationRepo.Create(ctx, organization); err != nil {
+		return nil, err
+	}
+
+	response := ToOrganizationResponse(organization)
+	return &response, nil
+}
+
+// GetOrganizations lists every organization to super admins, and their own to members.
+func (s *service) GetOrganizations(ctx context.Context) ([]*OrganizationResponse, error) {
+	if !canManage(ctx) {
+		user, ok := middleware.GetUserFromContext(ctx)
+		if !ok {
+			return nil, utils.ErrUnauthorized
+		}
+		if user.Organization == "" {
+			return nil, utils.ErrForbidden
+		}
+	}
+
+	// The repository limits members to their own organization
+	organizations, err := s.organizationRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*OrganizationResponse, len(organizations))
+	for i, organization := range organizations {
+		response := ToOrganizationResponse(organization)
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+func (s *service) GetOrganizationByID(ctx context.Context, id string) (*OrganizationResponse, error) {
+	organization, err := s.getOrganization(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.organizationRepo.Users(ctx, organization.ID)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := s.organizationRepo.Companies(ctx, organization.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ToOrganizationResponse(organization)
+	response.Users = hexIDs(users)
+	response.Companies = hexIDs(companies)
+	return &response, nil
+}
+
+// UpdateOrganization changes the name, settings or admins; organization admins may do so too.
+func (s *service) UpdateOrganization(ctx context.Context, id string, req UpdateOrganizationRequest) (*OrganizationResponse, error) {
+	organization, err := s.getOrganization(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		organization.Name = *req.Name
+	}
+	if req.Settings != nil {
+		organization.Settings = req.Settings.toDomain()
+	}
+	if req.Admins != nil {
+		if organization.Admins, err = s.members(ctx, organization.ID, req.Admins); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.organizationRepo.Update(ctx, organization.ID, organization); err != nil {
+		return nil, err
+	}
+
+	response := ToOrganizationResponse(organization)
+	return &response, nil
+}
+
+// DeleteOrganization only deletes empty organizations, so no user or company is left in one
+// that no longer exists.
+func (s *service) DeleteOrganization(ctx context.Context, id string) error {
+	organization, err := s.getOrganization(ctx, id, false)
+	if err != nil {
+		return err
+	}
+
+	users, err := s.organizationRepo.Users(ctx, organization.ID)
+	if err != nil {
+		return err
+	}
+	companies, err := s.organizationRepo.Companies(ctx, organization.ID)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 || len(companies) > 0 {
+		return ErrOrganizationNotEmpty
+	}
+
+	return s.organizationRepo.Delete(ctx, organization.ID)
+}
+
+func (s *service) AddCompany(ctx context.Context, id, companyID string) error {
+	organization, err := s.getOrganization(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	companyObjectID, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return ErrInvalidCompanyID
+	}
+
+	if err := s.organizationRepo.SetCompanyOrganization(ctx, companyObjectID, &organization.ID); err != nil {
+		return err
+	}
+	invalidateResponses()
+	return nil
+}
+
+func (s *service) RemoveCompany(ctx context.Context, id, companyID string) error {
+	organization, err := s.getOrganization(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	companyObjectID, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return ErrInvalidCompanyID
+	}
+
+	companies, err := s.organizationRepo.Companies(ctx, organization.ID)
+	if err != nil {
+		return err
+	}
+	if !containsID(companies, companyObjectID) {
+		return errCompanyNotMember
+	}
+
+	if err := s.organizationRepo.SetCompanyOrganization(ctx, companyObjectID, nil); err != nil {
+		return err
+	}
+	invalidateResponses()
+	return nil
+}
+
+// AddUser moves a user into the organization. Super admins administer the whole instance, so
+// they can't be members of one organization.
+func (s *service) AddUser(ctx context.Context, id, userID string) error {
+	organization, err := s.getOrganization(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userObjectID)
+	if err != nil {
+		return err
+	}
+	if user.Role == domain.RoleSuperAdmin {
+		return ErrSuperAdminMember
+	}
+
+	if err := s.organizationRepo.SetUserOrganization(ctx, userObjectID, &organization.ID); err != nil {
+		return err
+	}
+	invalidateResponses()
+	return nil
+}
+
+// RemoveUser moves a user back to the instance, dropping them from the organization's admins.
+func (s *service) RemoveUser(ctx context.Context, id, userID string) error {
+	organization, err := s.getOrganization(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userObjectID)
+	if err != nil {
+		return err
+	}
+	if user.Organization == nil || *user.Organization != organization.ID {
+		return errUserNotMember
+	}
+
+	if organization.IsAdmin(userObjectID) {
+		admins := make([]primitive.ObjectID, 0, len(organization.Admins))
+		for _, admin := range organization.Admins {
+			if admin != userObjectID {
+				admins = append(admins, admin)
+			}
+		}
+		organization.Admins = admins
+		if err := s.organizationRepo.Update(ctx, organization.ID, organization); err != nil {
+			return err
+		}
+	}
+
+	if err := s.organizationRepo.SetUserOrganization(ctx, userObjectID, nil); err != nil {
+		return err
+	}
+	invalidateResponses()
+	return nil
+}
+
+// getOrganization reads an organization the user may see: any for super admins, their own
+// for members. With admin set, members must also be admins of the organization.
+func (s *service) getOrganization(ctx context.Context, id string, admin bool) (*domain.Organization, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidOrganizationID
+	}
+
+	organization, err := s.organizationRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if canManage(ctx) {
+		return organization, nil
+	}
+
+	user, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+	if user.Organization != organization.ID.Hex() {
+		return nil, ErrOrganizationNotFound
+	}
+	if admin && !user.OrganizationAdmin {
+		return nil, utils.ErrForbidden
+	}
+	return organization, nil
+}
+
+// members parses user IDs and checks that they all belong to the organization.
+func (s *service) members(ctx context.Context, id primitive.ObjectID, userIDs []string) ([]primitive.ObjectID, error) {
+	users, err := s.organizationRepo.Users(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		objectID, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return nil, ErrInvalidUserID
+		}
+		if !containsID(users, objectID) {
+			return nil, ErrAdminNotMember
+		}
+		if !containsID(ids, objectID) {
+			ids = append(ids, objectID)
+		}
+	}
+	return ids, nil
+}
+
+// canManage reports whether the user may manage every organization, super admins by default.
+func canManage(ctx context.Context) bool {
+	return middleware.Authorize(ctx, "manage", policy.Resource{Type: "organization"}) == nil
+}
+
+// invalidateResponses drops cached lists, which may include companies and reports of the
+// organizations a member moved between.
+func invalidateResponses() {
+	middleware.InvalidateResponses(middleware.ResponseCacheCompanies, middleware.ResponseCacheReports)
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hexIDs(ids []primitive.ObjectID) []string {
+	hexes := make([]string, len(ids))
+	for i, id := range ids {
+		hexes[i] = id.Hex()
+	}
+	return hexes
+}
@@ -0,0 +1,16 @@
+package rate
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidRateID    = errors.New("INVALID_RATE_ID", "Invalid rate ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidCurrency  = errors.New("INVALID_CURRENCY", "Currencies must be three-letter ISO 4217 codes", http.StatusBadRequest, nil, nil)
+	ErrInvalidDate      = errors.New("INVALID_DATE", "Dates must be formatted as YYYY-MM-DD", http.StatusBadRequest, nil, nil)
+	ErrInvalidAmount    = errors.New("INVALID_AMOUNT", "Amount must be a number", http.StatusBadRequest, nil, nil)
+	ErrSamePair         = errors.New("INVALID_CURRENCY_PAIR", "Base and quote currencies must differ", http.StatusBadRequest, nil, nil)
+	ErrProviderNotSet   = errors.New("FX_PROVIDER_NOT_CONFIGURED", "No exchange rate provider is configured", http.StatusServiceUnavailable, nil, nil)
+	ErrRateNotAvailable = errors.New("RATE_NOT_AVAILABLE", "No rate converts between these currencies on this date", http.StatusNotFound, nil, nil)
+)
@@ -0,0 +1,197 @@
+package rate
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers exchange rate routes
+// @Tags Exchange Rates
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "rate"))
+
+	protected.HandleFunc("/api/rates", h.GetRates).Methods("GET")
+	protected.HandleFunc("/api/rates/convert", h.Convert).Methods("GET")
+	adminOnly.HandleFunc("/api/rates", h.CreateRate).Methods("POST")
+	adminOnly.HandleFunc("/api/rates/sync", h.Sync).Methods("POST")
+	protected.HandleFunc("/api/rates/{id}", h.GetRateByID).Methods("GET")
+	adminOnly.HandleFunc("/api/rates/{id}", h.UpdateRate).Methods("PUT")
+	adminOnly.HandleFunc("/api/rates/{id}", h.DeleteRate).Methods("DELETE")
+}
+
+// GetRates lists exchange rates by currency pair, newest first
+// @Param base query string false "Only rates of this base currency"
+// @Param quote query string false "Only rates of this quote currency"
+// @Param from query string false "Only rates effective on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Only rates effective on or before this date (YYYY-MM-DD)"
+// @Param page query integer false "Page number"
+// @Param limit query integer false "Rates per page, up to 100"
+func (h *Handler) GetRates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pagination := utils.GetPaginationParams(r)
+
+	rates, total, err := h.service.GetRates(r.Context(), RatesQuery{
+		Base:  query.Get("base"),
+		Quote: query.Get("quote"),
+		From:  query.Get("from"),
+		To:    query.Get("to"),
+	}, pagination.Skip, pagination.Limit)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	pagination.Total = total
+	utils.SetPaginationHeaders(w, r, pagination)
+	utils.RespondJSON(w, http.StatusOK, utils.CreatePaginatedResponse(rates, pagination))
+}
+
+// Convert converts an amount between currencies with the rates in effect on a date
+// @Param from query string true "Currency of the amount"
+// @Param to query string true "Currency to convert to"
+// @Param amount query number false "Amount to convert; 1 when unset"
+// @Param date query string false "Date of the rates (YYYY-MM-DD); today when unset"
+func (h *Handler) Convert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	amount := 1.0
+	if value := query.Get("amount"); value != "" {
+		var err error
+		if amount, err = strconv.ParseFloat(value, 64); err != nil {
+			utils.HandleHTTPError(w, ErrInvalidAmount, r)
+			return
+		}
+	}
+	date := time.Now()
+	if value := query.Get("date"); value != "" {
+		var err error
+		if date, err = parseDate(value); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	}
+
+	conversion, err := h.service.Convert(r.Context(), query.Get("from"), query.Get("to"), amount, date)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, conversion)
+}
+
+// CreateRate enters a rate by hand, effective from its date. Syncs never replace manual rates
+func (h *Handler) CreateRate(w http.ResponseWriter, r *http.Request) {
+	var req CreateRateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	rate, err := h.service.CreateRate(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, rate)
+}
+
+// Sync fetches the latest rates from the configured provider at once
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Sync(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, result)
+}
+
+// @Summary Get exchange rate by ID
+func (h *Handler) GetRateByID(w http.ResponseWriter, r *http.Request) {
+	rate, err := h.service.GetRateByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, rate)
+}
+
+// UpdateRate overrides a rate by hand, making it a manual rate that syncs leave alone
+func (h *Handler) UpdateRate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	rate, err := h.service.UpdateRate(r.Context(), mux.Vars(r)["id"], req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Rate updated successfully",
+		"rate":    rate,
+	})
+}
+
+// DeleteRate removes a rate. Once a manual rate is gone, the next sync stores the provider's rate
+// for its day, if that is still the provider's latest
+func (h *Handler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRate(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{
+		"message": "Rate deleted successfully",
+	})
+}
+
+// requester is the ID of the user making the request, recorded on the rates they enter.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
@@ -0,0 +1,42 @@
+package rate
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Job syncs rates from the provider on a fixed interval.
+type Job struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewJob(service Service, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run syncs once at start, so a fresh instance has rates, and then until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := j.service.Sync(ctx)
+		if err != nil {
+			log.Errorf(ctx, "Rates: sync failed: %v", err)
+		} else {
+			log.Infof(ctx, "Rates: synced %d of %d %s rates against %s for %s", result.Saved, result.Fetched, result.Provider, result.Base, result.Date)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rate
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateRateRequest struct {
+	Base          string  `json:"base" validate:"required,len=3,alpha"`
+	Quote         string  `json:"quote" validate:"required,len=3,alpha"`
+	Rate          float64 `json:"rate" validate:"required,gt=0"`
+	EffectiveDate string  `json:"effectiveDate" validate:"required"` // YYYY-MM-DD
+}
+
+// UpdateRateRequest overrides a rate by hand; it is kept as a manual rate from then on.
+type UpdateRateRequest struct {
+	Rate          *float64 `json:"rate,omitempty" validate:"omitempty,gt=0"`
+	EffectiveDate *string  `json:"effectiveDate,omitempty"` // YYYY-MM-DD
+}
+
+// RatesQuery filters the rates listed
+type RatesQuery struct {
+	Base  string
+	Quote string
+	From  string // YYYY-MM-DD
+	To    string // YYYY-MM-DD
+}
+
+// Response DTOs
+type RateResponse struct {
+	ID            string    `json:"id"`
+	Base          string    `json:"base"`
+	Quote         string    `json:"quote"`
+	Rate          float64   `json:"rate"`
+	EffectiveDate string    `json:"effectiveDate"`
+	Source        string    `json:"source"` // manual, ecb or openexchangerates
+	UpdatedBy     *string   `json:"updatedBy"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// ConversionResponse is an amount converted with the rates in effect on a date.
+type ConversionResponse struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Amount        float64 `json:"amount"`
+	Rate          float64 `json:"rate"`
+	Converted     float64 `json:"converted"`
+	Date          string  `json:"date"`
+	EffectiveDate string  `json:"effectiveDate"` // of the oldest rate used
+	Via           *string `json:"via"`           // currency both were converted through, when there is no direct rate
+}
+
+type SyncResponse struct {
+	Provider string `json:"provider"`
+	Base     string `json:"base"`
+	Date     string `json:"date"`
+	Fetched  int    `json:"fetched"`
+	Saved    int    `json:"saved"` // the others are held by manual rates
+}
+
+const dateLayout = "2006-01-02"
+
+func ToRateResponse(rate *domain.ExchangeRate) *RateResponse {
+	response := &RateResponse{
+		ID:            rate.ID.Hex(),
+		Base:          rate.Base,
+		Quote:         rate.Quote,
+		Rate:          rate.Rate,
+		EffectiveDate: rate.EffectiveDate.Format(dateLayout),
+		Source:        rate.Source,
+		UpdatedAt:     rate.UpdatedAt,
+	}
+	if rate.UpdatedBy != nil {
+		updatedBy := rate.UpdatedBy.Hex()
+		response.UpdatedBy = &updatedBy
+	}
+	return response
+}
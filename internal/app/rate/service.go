@@ -0,0 +1,312 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+	"finsolvz-backend/internal/platform/fx"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// pivots are the currencies the supported providers quote against, which pairs without a
+// rate of their own are converted through
+var pivots = []string{"EUR", "USD"}
+
+type Service interface {
+	GetRates(ctx context.Context, query RatesQuery, skip, limit int) ([]*RateResponse, int, error)
+	GetRateByID(ctx context.Context, id string) (*RateResponse, error)
+	CreateRate(ctx context.Context, req CreateRateRequest, createdBy primitive.ObjectID) (*RateResponse, error)
+	UpdateRate(ctx context.Context, id string, req UpdateRateRequest, updatedBy primitive.ObjectID) (*RateResponse, error)
+	DeleteRate(ctx context.Context, id string) error
+
+	// Convert converts amount between currencies with the rates in effect on date: the pair's
+	// own rate, its inverse, or the rates of both against a pivot currency. The result is
+	// rounded to the minor units of the target currency.
+	Convert(ctx context.Context, from, to string, amount float64, date time.Time) (*ConversionResponse, error)
+	// Sync stores the latest rates of the configured provider.
+	Sync(ctx context.Context) (*SyncResponse, error)
+}
+
+type service struct {
+	rateRepo domain.RateRepository
+	provider fx.Provider
+}
+
+// NewService syncs rates from provider, which is nil when rates are only entered by hand.
+func NewService(rateRepo domain.RateRepository, provider fx.Provider) Service {
+	return &service{
+		rateRepo: rateRepo,
+		provider: provider,
+	}
+}
+
+func (s *service) GetRates(ctx context.Context, query RatesQuery, skip, limit int) ([]*RateResponse, int, error) {
+	filter := domain.RateFilter{}
+	var err error
+	if query.Base != "" {
+		if filter.Base, err = currency(query.Base); err != nil {
+			return nil, 0, err
+		}
+	}
+	if query.Quote != "" {
+		if filter.Quote, err = currency(query.Quote); err != nil {
+			return nil, 0, err
+		}
+	}
+	for _, bound := range []struct {
+		value string
+		into  **time.Time
+	}{{query.From, &filter.From}, {query.To, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		date, err := parseDate(bound.value)
+		if err != nil {
+			return nil, 0, err
+		}
+		*bound.into = &date
+	}
+
+	rates, total, err := s.rateRepo.GetAll(ctx, filter, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*RateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = ToRateResponse(rate)
+	}
+	return responses, total, nil
+}
+
+func (s *service) GetRateByID(ctx context.Context, id string) (*RateResponse, error) {
+	rate, err := s.getRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToRateResponse(rate), nil
+}
+
+func (s *service) CreateRate(ctx context.Context, req CreateRateRequest, createdBy primitive.ObjectID) (*RateResponse, error) {
+	base, err := currency(req.Base)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := currency(req.Quote)
+	if err != nil {
+		return nil, err
+	}
+	if base == quote {
+		return nil, ErrSamePair
+	}
+	date, err := parseDate(req.EffectiveDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &domain.ExchangeRate{
+		Base:          base,
+		Quote:         quote,
+		Rate:          req.Rate,
+		EffectiveDate: date,
+		Source:        domain.RateSourceManual,
+		UpdatedBy:     &createdBy,
+	}
+	if err := s.rateRepo.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+	return ToRateResponse(rate), nil
+}
+
+func (s *service) UpdateRate(ctx context.Context, id string, req UpdateRateRequest, updatedBy primitive.ObjectID) (*RateResponse, error) {
+	rate, err := s.getRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Rate != nil {
+		rate.Rate = *req.Rate
+	}
+	if req.EffectiveDate != nil {
+		if rate.EffectiveDate, err = parseDate(*req.EffectiveDate); err != nil {
+			return nil, err
+		}
+	}
+	// A synced rate changed by hand is an override the next sync must keep
+	rate.Source = domain.RateSourceManual
+	rate.UpdatedBy = &updatedBy
+
+	if err := s.rateRepo.Update(ctx, rate.ID, rate); err != nil {
+		return nil, err
+	}
+	return ToRateResponse(rate), nil
+}
+
+func (s *service) DeleteRate(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidRateID
+	}
+	return s.rateRepo.Delete(ctx, objectID)
+}
+
+func (s *service) Convert(ctx context.Context, from, to string, amount float64, date time.Time) (*ConversionResponse, error) {
+	from, err := currency(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err = currency(to)
+	if err != nil {
+		return nil, err
+	}
+	date = fx.Day(date)
+
+	response := &ConversionResponse{
+		From:          from,
+		To:            to,
+		Amount:        amount,
+		Rate:          1,
+		Date:          date.Format(dateLayout),
+		EffectiveDate: date.Format(dateLayout),
+	}
+	if from != to {
+		rate, effective, via, err := s.rate(ctx, from, to, date)
+		if err != nil {
+			return nil, err
+		}
+		response.Rate, response.EffectiveDate, response.Via = rate, effective.Format(dateLayout), via
+	}
+	response.Converted = format.New("", nil).Round(amount*response.Rate, to)
+
+	return response, nil
+}
+
+// rate finds the rate from one currency to another on date, returning the effective date of
+// the oldest rate used and the pivot currency, if any.
+func (s *service) rate(ctx context.Context, from, to string, date time.Time) (float64, time.Time, *string, error) {
+	direct, err := s.effective(ctx, from, to, date)
+	if err != nil {
+		return 0, time.Time{}, nil, err
+	}
+	if direct != nil {
+		return direct.Rate, direct.EffectiveDate, nil, nil
+	}
+
+	inverse, err := s.effective(ctx, to, from, date)
+	if err != nil {
+		return 0, time.Time{}, nil, err
+	}
+	if inverse != nil {
+		return 1 / inverse.Rate, inverse.EffectiveDate, nil, nil
+	}
+
+	for _, pivot := range pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		fromLeg, err := s.effective(ctx, pivot, from, date)
+		if err != nil {
+			return 0, time.Time{}, nil, err
+		}
+		toLeg, err := s.effective(ctx, pivot, to, date)
+		if err != nil {
+			return 0, time.Time{}, nil, err
+		}
+		if fromLeg == nil || toLeg == nil {
+			continue
+		}
+
+		effective := fromLeg.EffectiveDate
+		if toLeg.EffectiveDate.Before(effective) {
+			effective = toLeg.EffectiveDate
+		}
+		via := pivot
+		return toLeg.Rate / fromLeg.Rate, effective, &via, nil
+	}
+
+	return 0, time.Time{}, nil, ErrRateNotAvailable
+}
+
+// effective returns the rate of the pair in effect on date, or nil when it has none.
+func (s *service) effective(ctx context.Context, base, quote string, date time.Time) (*domain.ExchangeRate, error) {
+	rate, err := s.rateRepo.Effective(ctx, base, quote, date)
+	if err != nil {
+		if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *service) Sync(ctx context.Context) (*SyncResponse, error) {
+	if s.provider == nil {
+		return nil, ErrProviderNotSet
+	}
+
+	quotes, err := s.provider.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	date := fx.Day(quotes.Date)
+	rates := make([]*domain.ExchangeRate, 0, len(quotes.Rates))
+	for quote, value := range quotes.Rates {
+		rates = append(rates, &domain.ExchangeRate{
+			Base:          quotes.Base,
+			Quote:         quote,
+			Rate:          value,
+			EffectiveDate: date,
+			Source:        s.provider.Name(),
+		})
+	}
+
+	saved, err := s.rateRepo.SaveSynced(ctx, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyncResponse{
+		Provider: s.provider.Name(),
+		Base:     quotes.Base,
+		Date:     date.Format(dateLayout),
+		Fetched:  len(rates),
+		Saved:    saved,
+	}, nil
+}
+
+func (s *service) getRate(ctx context.Context, id string) (*domain.ExchangeRate, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidRateID
+	}
+	return s.rateRepo.GetByID(ctx, objectID)
+}
+
+// currency normalizes an ISO 4217 code to upper case.
+func currency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return date, nil
+}
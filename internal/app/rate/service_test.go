@@ -0,0 +1,203 @@
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// mockRateRepository finds effective rates like the real one: the latest of the pair effective
+// on or before the date. It counts the lookups made.
+type mockRateRepository struct {
+	rates   []*domain.ExchangeRate
+	err     error
+	lookups int
+}
+
+func (m *mockRateRepository) Create(ctx context.Context, rate *domain.ExchangeRate) error {
+	rate.ID = primitive.NewObjectID()
+	m.rates = append(m.rates, rate)
+	return nil
+}
+
+func (m *mockRateRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ExchangeRate, error) {
+	for _, rate := range m.rates {
+		if rate.ID == id {
+			return rate, nil
+		}
+	}
+	return nil, errors.New("RATE_NOT_FOUND", "Rate not found", 404, nil, nil)
+}
+
+func (m *mockRateRepository) GetAll(ctx context.Context, filter domain.RateFilter, skip, limit int) ([]*domain.ExchangeRate, int, error) {
+	return m.rates, len(m.rates), nil
+}
+
+func (m *mockRateRepository) Update(ctx context.Context, id primitive.ObjectID, rate *domain.ExchangeRate) error {
+	return nil
+}
+
+func (m *mockRateRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+func (m *mockRateRepository) SaveSynced(ctx context.Context, rates []*domain.ExchangeRate) (int, error) {
+	return len(rates), nil
+}
+
+func (m *mockRateRepository) Effective(ctx context.Context, base, quote string, date time.Time) (*domain.ExchangeRate, error) {
+	m.lookups++
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	var latest *domain.ExchangeRate
+	for _, rate := range m.rates {
+		if rate.Base != base || rate.Quote != quote || rate.EffectiveDate.After(date) {
+			continue
+		}
+		if latest == nil || rate.EffectiveDate.After(latest.EffectiveDate) {
+			latest = rate
+		}
+	}
+	if latest == nil {
+		return nil, errors.New("RATE_NOT_FOUND", "Rate not found", 404, nil, nil)
+	}
+	return latest, nil
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testRates() []*domain.ExchangeRate {
+	rate := func(base, quote string, value float64, effective time.Time) *domain.ExchangeRate {
+		return &domain.ExchangeRate{ID: primitive.NewObjectID(), Base: base, Quote: quote, Rate: value, EffectiveDate: effective, Source: domain.RateSourceManual}
+	}
+	// Kept out of order, as the repository doesn't promise any
+	return []*domain.ExchangeRate{
+		rate("USD", "IDR", 16000, day(2025, time.January, 10)),
+		rate("USD", "IDR", 15000, day(2025, time.January, 1)),
+		rate("USD", "IDR", 16500, day(2025, time.February, 1)),
+		rate("EUR", "USD", 1.1, day(2025, time.January, 1)),
+		rate("USD", "JPY", 150, day(2025, time.January, 5)),
+		rate("USD", "KWD", 0.30812, day(2025, time.January, 1)),
+	}
+}
+
+func TestService_Convert(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name      string
+		from, to  string
+		amount    float64
+		date      time.Time
+		rate      float64
+		converted float64
+		effective string
+		via       string
+	}{
+		// The latest rate on or before the date
+		{name: "rate effective on the date", from: "USD", to: "IDR", amount: 2, date: day(2025, time.January, 10), rate: 16000, converted: 32000, effective: "2025-01-10"},
+		{name: "day before a new rate", from: "USD", to: "IDR", amount: 2, date: day(2025, time.January, 9), rate: 15000, converted: 30000, effective: "2025-01-01"},
+		{name: "days after the latest rate", from: "USD", to: "IDR", amount: 2, date: day(2025, time.March, 15), rate: 16500, converted: 33000, effective: "2025-02-01"},
+		{name: "time of day is ignored", from: "USD", to: "IDR", amount: 1, date: time.Date(2025, time.January, 10, 23, 59, 0, 0, time.UTC), rate: 16000, converted: 16000, effective: "2025-01-10"},
+		// 5am in Jakarta is still the day before in UTC, the day rates are kept in
+		{name: "date is taken in UTC", from: "USD", to: "IDR", amount: 1, date: time.Date(2025, time.January, 10, 5, 0, 0, 0, jakarta), rate: 15000, converted: 15000, effective: "2025-01-01"},
+
+		// Inverse and pivot rates
+		{name: "inverse rate", from: "IDR", to: "USD", amount: 1600000, date: day(2025, time.January, 10), rate: 1.0 / 16000, converted: 100, effective: "2025-01-10"},
+		{name: "through USD, dated by the older rate", from: "IDR", to: "JPY", amount: 1000000, date: day(2025, time.January, 10), rate: 150.0 / 16000, converted: 9375, effective: "2025-01-05", via: "USD"},
+		{name: "inverse of a EUR rate", from: "USD", to: "EUR", amount: 11, date: day(2025, time.January, 2), rate: 1 / 1.1, converted: 10, effective: "2025-01-01"},
+
+		// Rounding at the precision of the target currency
+		{name: "IDR has no minor units", from: "USD", to: "IDR", amount: 10.00005, date: day(2025, time.January, 10), rate: 16000, converted: 160001, effective: "2025-01-10"},
+		{name: "JPY has no minor units", from: "USD", to: "JPY", amount: 1.2345, date: day(2025, time.January, 10), rate: 150, converted: 185, effective: "2025-01-05"},
+		{name: "KWD has three", from: "USD", to: "KWD", amount: 10, date: day(2025, time.January, 10), rate: 0.30812, converted: 3.081, effective: "2025-01-01"},
+		{name: "EUR has two", from: "USD", to: "EUR", amount: 10, date: day(2025, time.January, 10), rate: 1 / 1.1, converted: 9.09, effective: "2025-01-01"},
+		{name: "negative amounts", from: "USD", to: "IDR", amount: -0.00005, date: day(2025, time.January, 10), rate: 16000, converted: -1, effective: "2025-01-10"},
+
+		// Codes are normalized
+		{name: "lower case codes", from: " usd", to: "idr ", amount: 1, date: day(2025, time.January, 10), rate: 16000, converted: 16000, effective: "2025-01-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(&mockRateRepository{rates: testRates()}, nil)
+
+			response, err := service.Convert(context.Background(), tt.from, tt.to, tt.amount, tt.date)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if diff := response.Rate - tt.rate; diff > 1e-12 || diff < -1e-12 {
+				t.Fatalf("Expected rate %v, got %v", tt.rate, response.Rate)
+			}
+			if response.Converted != tt.converted {
+				t.Fatalf("Expected %v, got %v", tt.converted, response.Converted)
+			}
+			if response.EffectiveDate != tt.effective {
+				t.Fatalf("Expected the rate of %s, got %s", tt.effective, response.EffectiveDate)
+			}
+			if via := response.Via; (via == nil) != (tt.via == "") || (via != nil && *via != tt.via) {
+				t.Fatalf("Expected via %q, got %v", tt.via, via)
+			}
+		})
+	}
+}
+
+func TestService_Convert_SameCurrency(t *testing.T) {
+	// No rates at all: converting to the same currency must not need one
+	repo := &mockRateRepository{}
+	service := NewService(repo, nil)
+	date := time.Date(2025, time.January, 10, 15, 30, 0, 0, time.UTC)
+
+	response, err := service.Convert(context.Background(), "idr", "IDR", 1234567, date)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if response.Rate != 1 || response.Converted != 1234567 || response.Via != nil {
+		t.Fatalf("Expected 1234567 IDR at a rate of 1, got %v at %v via %v", response.Converted, response.Rate, response.Via)
+	}
+	if response.Date != "2025-01-10" || response.EffectiveDate != "2025-01-10" {
+		t.Fatalf("Expected the date of the conversion, got %s and %s", response.Date, response.EffectiveDate)
+	}
+	if repo.lookups != 0 {
+		t.Fatalf("Expected no rate lookups, got %d", repo.lookups)
+	}
+}
+
+func TestService_Convert_Errors(t *testing.T) {
+	databaseError := errors.New("DATABASE_ERROR", "Failed to get rate", 500, nil, nil)
+
+	tests := []struct {
+		name     string
+		repoErr  error
+		from, to string
+		date     time.Time
+		wantErr  error
+	}{
+		{name: "before the first rate", from: "USD", to: "IDR", date: day(2024, time.December, 31), wantErr: ErrRateNotAvailable},
+		{name: "before the first rate of a pivot leg", from: "IDR", to: "JPY", date: day(2025, time.January, 4), wantErr: ErrRateNotAvailable},
+		{name: "pair without any rate", from: "USD", to: "CHF", date: day(2025, time.January, 10), wantErr: ErrRateNotAvailable},
+		{name: "invalid source currency", from: "US", to: "IDR", date: day(2025, time.January, 10), wantErr: ErrInvalidCurrency},
+		{name: "invalid target currency", from: "USD", to: "ID1", date: day(2025, time.January, 10), wantErr: ErrInvalidCurrency},
+		{name: "repository failure is not a missing rate", repoErr: databaseError, from: "USD", to: "IDR", date: day(2025, time.January, 10), wantErr: databaseError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(&mockRateRepository{rates: testRates(), err: tt.repoErr}, nil)
+
+			response, err := service.Convert(context.Background(), tt.from, tt.to, 100, tt.date)
+			if err != tt.wantErr {
+				t.Fatalf("Expected %v, got %+v, %v", tt.wantErr, response, err)
+			}
+		})
+	}
+}
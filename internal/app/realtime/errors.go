@@ -0,0 +1,15 @@
+package realtime
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrUpgradeRequired  = errors.New("UPGRADE_REQUIRED", "Connect with a WebSocket client", http.StatusUpgradeRequired, nil, nil)
+	ErrAuthTimeout      = errors.New("AUTH_TIMEOUT", "Send an auth message with a token first", http.StatusUnauthorized, nil, nil)
+	ErrTokenExpired     = errors.New("TOKEN_EXPIRED", "Token expired, reconnect with a fresh token", http.StatusUnauthorized, nil, nil)
+	ErrInvalidMessage   = errors.New("INVALID_MESSAGE", "Messages must be JSON objects with a known type", http.StatusBadRequest, nil, nil)
+	ErrInvalidEventType = errors.New("INVALID_EVENT_TYPE", "Unknown event type", http.StatusBadRequest, nil, nil)
+	ErrOriginNotAllowed = errors.New("ORIGIN_NOT_ALLOWED", "Origin not allowed", http.StatusForbidden, nil, nil)
+)
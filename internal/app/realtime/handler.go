@@ -0,0 +1,245 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/net/websocket"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	authTimeout     = 10 * time.Second
+	writeTimeout    = 10 * time.Second
+	pingInterval    = 30 * time.Second
+	maxMessageBytes = 4 << 10
+)
+
+type Handler struct {
+	service Service
+	hub     *Hub
+	origins []string
+}
+
+// NewHandler serves the hub to browsers from the given origins ("*" allows any) and to
+// clients that send no Origin header.
+func NewHandler(service Service, hub *Hub, allowedOrigins []string) *Handler {
+	return &Handler{
+		service: service,
+		hub:     hub,
+		origins: allowedOrigins,
+	}
+}
+
+// RegisterRoutes registers the WebSocket route. It is not behind the auth middleware:
+// browsers can't set headers on WebSocket requests, so clients may authenticate with
+// their first message instead.
+// @Tags Realtime
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ws", h.Connect).Methods("GET")
+}
+
+// Connect upgrades the request to a WebSocket that pushes domain events (report created,
+// updated, deleted and shared; user and company changes) as they happen. The client
+// authenticates with an Authorization header, the session cookie of cookie-based clients
+// or an {"type":"auth","token":"..."} first message, then sends {"type":"subscribe","events":[...]} for the event types it wants,
+// optionally preselected with ?events=a,b. Users only receive events about reports and
+// companies they can access; admins receive every event.
+// @Summary Open a WebSocket for real-time events
+// @ID connectRealtime
+// @Success 101 "Switching to the WebSocket protocol"
+func (h *Handler) Connect(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		utils.HandleHTTPError(w, ErrUpgradeRequired, r)
+		return
+	}
+	if !h.allowsOrigin(r.Header.Get("Origin")) {
+		utils.HandleHTTPError(w, ErrOriginNotAllowed, r)
+		return
+	}
+
+	// Header authentication fails with a regular HTTP error, before the upgrade
+	var viewer *Viewer
+	if r.Header.Get("Authorization") != "" {
+		token, err := utils.ExtractBearerToken(r)
+		if err == nil {
+			viewer, err = h.service.Authenticate(r.Context(), token)
+		}
+		if err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	} else if token := utils.SessionCookieToken(r); token != "" {
+		// Browsers send the session cookie with the handshake; the origin was checked above
+		var err error
+		if viewer, err = h.service.Authenticate(r.Context(), token); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	}
+
+	var events []string
+	if list := r.URL.Query().Get("events"); list != "" {
+		events = strings.Split(list, ",")
+	}
+
+	server := websocket.Server{
+		// The origin has been checked above
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			h.serve(ws, viewer, events)
+		},
+	}
+	server.ServeHTTP(hijacker{w}, r)
+}
+
+// serve runs the read loop of a connection until the client leaves or is disconnected.
+func (h *Handler) serve(ws *websocket.Conn, viewer *Viewer, events []string) {
+	defer ws.Close()
+
+	// The connection outlives the request, so drop its timeout and the server's deadlines
+	ctx := context.WithoutCancel(ws.Request().Context())
+	ws.SetDeadline(time.Time{})
+	ws.MaxPayloadBytes = maxMessageBytes
+
+	if viewer == nil {
+		var err error
+		if viewer, err = h.authenticate(ctx, ws); err != nil {
+			websocket.JSON.Send(ws, errorMessage(err))
+			return
+		}
+	}
+
+	c := h.hub.register(viewer)
+	if c == nil {
+		return
+	}
+	defer h.hub.unregister(c)
+	go h.write(ws, c)
+
+	available := make([]string, len(domain.EventTypes))
+	for i, eventType := range domain.EventTypes {
+		available[i] = string(eventType)
+	}
+	h.hub.send(c, ServerMessage{Type: MessageReady, Events: available})
+	if len(events) > 0 {
+		h.hub.subscribe(c, events, true)
+	}
+
+	for {
+		var message ClientMessage
+		err := websocket.JSON.Receive(ws, &message)
+		if err != nil && !isMalformed(err) {
+			return
+		}
+
+		switch {
+		case err != nil:
+			h.hub.send(c, errorMessage(ErrInvalidMessage))
+		case message.Type == MessageSubscribe:
+			h.hub.subscribe(c, message.Events, true)
+		case message.Type == MessageUnsubscribe:
+			h.hub.subscribe(c, message.Events, false)
+		case message.Type == MessagePing:
+			h.hub.send(c, ServerMessage{Type: MessagePong})
+		default:
+			h.hub.send(c, errorMessage(ErrInvalidMessage))
+		}
+	}
+}
+
+// authenticate waits for the auth message of a client that connected without a header.
+func (h *Handler) authenticate(ctx context.Context, ws *websocket.Conn) (*Viewer, error) {
+	ws.SetReadDeadline(time.Now().Add(authTimeout))
+	defer ws.SetReadDeadline(time.Time{})
+
+	var message ClientMessage
+	if err := websocket.JSON.Receive(ws, &message); err != nil || message.Type != MessageAuth {
+		return nil, ErrAuthTimeout
+	}
+
+	viewer, err := h.service.Authenticate(ctx, message.Token)
+	if err != nil {
+		log.Warnf(ctx, "Realtime: authentication failed: %v", err)
+		return nil, err
+	}
+	return viewer, nil
+}
+
+// write sends queued messages and keepalive pings, closing the connection when the
+// client is removed from the hub or its token expires.
+func (h *Handler) write(ws *websocket.Conn, c *client) {
+	defer ws.Close()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	var expired <-chan time.Time
+	if !c.viewer.ExpiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(c.viewer.ExpiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			if !ok {
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := websocket.JSON.Send(ws, message); err != nil {
+				return
+			}
+		case <-ping.C:
+			ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			ws.PayloadType = websocket.PingFrame
+			if _, err := ws.Write(nil); err != nil {
+				return
+			}
+		case <-expired:
+			ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			websocket.JSON.Send(ws, errorMessage(ErrTokenExpired))
+			return
+		}
+	}
+}
+
+func (h *Handler) allowsOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMalformed reports whether a receive error is about the message rather than the connection
+func isMalformed(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return err == websocket.ErrFrameTooLarge || errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// hijacker exposes the connection of a ResponseWriter wrapped by middleware, which
+// websocket.Server needs to take over.
+type hijacker struct {
+	http.ResponseWriter
+}
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(h.ResponseWriter).Hijack()
+}
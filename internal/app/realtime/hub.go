@@ -0,0 +1,211 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/outbox"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// sendBuffer is how many messages a client may fall behind before it is disconnected
+const sendBuffer = 64
+
+// Hub pushes domain events to connected WebSocket clients. Each client receives the event
+// types it subscribed to, limited to the events its user may see.
+//
+// The hub is an outbox.Publisher, so events reach the clients of the instance whose
+// dispatcher picked them up. On Mongo, repository.WatchOutbox feeds every instance from
+// the outbox change stream instead, and the dispatcher is ignored while the stream is open.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+
+	streaming atomic.Bool
+}
+
+type client struct {
+	viewer *Viewer
+	events map[domain.EventType]bool
+	send   chan ServerMessage
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Publish pushes an event handed over by the outbox dispatcher, unless the change stream
+// is feeding the hub.
+func (h *Hub) Publish(ctx context.Context, event *domain.Event) error {
+	if !h.streaming.Load() {
+		h.Broadcast(event)
+	}
+	return nil
+}
+
+// SetStreaming records whether the outbox change stream is feeding the hub.
+func (h *Hub) SetStreaming(on bool) {
+	h.streaming.Store(on)
+}
+
+// Broadcast pushes an event to every client subscribed to its type that may see it.
+func (h *Hub) Broadcast(event *domain.Event) {
+	s := scopeOf(event)
+	envelope := outbox.NewEnvelope(event)
+	message := ServerMessage{Type: MessageEvent, Event: &envelope}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		if event.Type == domain.EventUserUpdated && event.AggregateID.Hex() == c.viewer.UserID {
+			c.viewer.refresh(event.Payload)
+		}
+		if c.events[event.Type] && s.allows(c.viewer) {
+			h.deliver(c, message)
+		}
+	}
+}
+
+// Run disconnects every client once ctx is cancelled.
+func (h *Hub) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for c := range h.clients {
+		h.remove(c)
+	}
+}
+
+// register adds a client with no subscriptions; it returns nil once the hub is closed.
+func (h *Hub) register(viewer *Viewer) *client {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	c := &client{
+		viewer: viewer,
+		events: make(map[domain.EventType]bool),
+		send:   make(chan ServerMessage, sendBuffer),
+	}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(c)
+}
+
+// subscribe adds event types to a client's subscriptions, or removes them, and confirms
+// the resulting set. Unknown types are rejected without changing anything.
+func (h *Hub) subscribe(c *client, events []string, on bool) {
+	types := domain.EventTypes
+	if len(events) > 0 {
+		types = nil
+		for _, name := range events {
+			eventType := domain.EventType(name)
+			if !isEventType(eventType) {
+				h.send(c, errorMessage(ErrInvalidEventType))
+				return
+			}
+			types = append(types, eventType)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, eventType := range types {
+		if on {
+			c.events[eventType] = true
+		} else {
+			delete(c.events, eventType)
+		}
+	}
+
+	subscribed := []string{}
+	for _, eventType := range domain.EventTypes {
+		if c.events[eventType] {
+			subscribed = append(subscribed, string(eventType))
+		}
+	}
+	h.deliver(c, ServerMessage{Type: MessageSubscribed, Events: subscribed})
+}
+
+// send queues a message for a single client.
+func (h *Hub) send(c *client, message ServerMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.deliver(c, message)
+}
+
+// deliver queues a message without blocking the hub: a client whose buffer is full is
+// disconnected, and has to reconnect and refetch what it missed. h.mu must be held.
+func (h *Hub) deliver(c *client, message ServerMessage) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+		log.Warnf(context.Background(), "Realtime: disconnecting user %s, %d messages behind", c.viewer.UserID, sendBuffer)
+		h.remove(c)
+	}
+}
+
+// remove closes the client's queue, which makes its writer close the connection. h.mu must be held.
+func (h *Hub) remove(c *client) {
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// refresh applies the role and companies of a user.updated payload about the viewer, so
+// scoping follows changes made while connected.
+func (v *Viewer) refresh(payload []byte) {
+	var user struct {
+		Role    domain.UserRole `json:"role"`
+		Company []string        `json:"company"`
+	}
+	if err := json.Unmarshal(payload, &user); err != nil || user.Role == "" {
+		return
+	}
+
+	v.Role = user.Role
+	v.Companies = make(map[string]bool, len(user.Company))
+	for _, companyID := range user.Company {
+		v.Companies[companyID] = true
+	}
+}
+
+func isEventType(eventType domain.EventType) bool {
+	for _, known := range domain.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func errorMessage(err error) ServerMessage {
+	appErr, ok := err.(errors.AppError)
+	if !ok {
+		appErr = errors.ErrInternalServer
+	}
+	return ServerMessage{Type: MessageError, Error: &ErrorBody{Code: appErr.Code(), Message: appErr.Message()}}
+}
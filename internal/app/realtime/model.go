@@ -0,0 +1,42 @@
+package realtime
+
+import "finsolvz-backend/internal/platform/outbox"
+
+// Message types sent by clients
+const (
+	MessageAuth        = "auth"
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessagePing        = "ping"
+)
+
+// Message types sent by the server
+const (
+	MessageReady      = "ready"
+	MessageSubscribed = "subscribed"
+	MessageEvent      = "event"
+	MessageError      = "error"
+	MessagePong       = "pong"
+)
+
+// ClientMessage is a message received from a client. Events lists event types for
+// subscribe and unsubscribe; omitting it on subscribe selects every type.
+type ClientMessage struct {
+	Type   string   `json:"type"`
+	Token  string   `json:"token,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// ServerMessage is a message sent to a client. Events lists the available types in ready
+// and the current subscriptions in subscribed.
+type ServerMessage struct {
+	Type   string           `json:"type"`
+	Events []string         `json:"events,omitempty"`
+	Event  *outbox.Envelope `json:"event,omitempty"`
+	Error  *ErrorBody       `json:"error,omitempty"`
+}
+
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
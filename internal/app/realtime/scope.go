@@ -0,0 +1,81 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/policy"
+)
+
+// scope lists the users and companies an event concerns; the access policy decides who
+// sees it (by default admins see every event, others those naming them or their companies).
+type scope struct {
+	users     []string
+	companies []string
+}
+
+// scopeOf reads an event's scope from its payload. Event types without a rule are only
+// pushed to admins.
+func scopeOf(event *domain.Event) scope {
+	switch event.Type {
+	case domain.EventReportCreated, domain.EventReportUpdated, domain.EventReportApproved, domain.EventReportDeleted:
+		var payload struct {
+			Company    string   `json:"company"`
+			CreatedBy  string   `json:"createdBy"`
+			UserAccess []string `json:"userAccess"`
+		}
+		if json.Unmarshal(event.Payload, &payload) != nil {
+			return scope{}
+		}
+		return scope{users: append(payload.UserAccess, payload.CreatedBy), companies: []string{payload.Company}}
+
+	case domain.EventReportAccessGranted:
+		var payload struct {
+			UserIDs []string `json:"userIds"`
+		}
+		if json.Unmarshal(event.Payload, &payload) != nil {
+			return scope{}
+		}
+		return scope{users: payload.UserIDs}
+
+	case domain.EventReportOverdue:
+		var payload struct {
+			Company string `json:"company"`
+		}
+		if json.Unmarshal(event.Payload, &payload) != nil {
+			return scope{}
+		}
+		return scope{companies: []string{payload.Company}}
+
+	case domain.EventUserUpdated, domain.EventUserSuspiciousLogin:
+		return scope{users: []string{event.AggregateID.Hex()}}
+
+	case domain.EventCompanyCreated, domain.EventCompanyUpdated, domain.EventCompanyDeleted:
+		var payload struct {
+			User []struct {
+				ID string `json:"_id"`
+			} `json:"user"`
+		}
+		s := scope{companies: []string{event.AggregateID.Hex()}}
+		if json.Unmarshal(event.Payload, &payload) == nil {
+			for _, user := range payload.User {
+				s.users = append(s.users, user.ID)
+			}
+		}
+		return s
+	}
+	return scope{}
+}
+
+// allows reports whether the access policy lets the viewer read events of the scope
+func (s scope) allows(viewer *Viewer) bool {
+	sub := policy.Subject{UserID: viewer.UserID, Role: string(viewer.Role), Companies: make([]string, 0, len(viewer.Companies))}
+	for companyID, member := range viewer.Companies {
+		if member {
+			sub.Companies = append(sub.Companies, companyID)
+		}
+	}
+	allowed, err := policy.Allowed(context.Background(), sub, "read", policy.Resource{Type: "event", Owners: s.users, Companies: s.companies})
+	return err == nil && allowed
+}
@@ -0,0 +1,72 @@
+package realtime
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type Service interface {
+	// Authenticate validates a JWT and loads what the hub needs to scope events to its user
+	Authenticate(ctx context.Context, token string) (*Viewer, error)
+}
+
+// Viewer is the user behind a connection.
+type Viewer struct {
+	UserID    string
+	Role      domain.UserRole
+	Companies map[string]bool
+	ExpiresAt time.Time
+}
+
+type service struct {
+	userRepo domain.UserRepository
+}
+
+func NewService(userRepo domain.UserRepository) Service {
+	return &service{
+		userRepo: userRepo,
+	}
+}
+
+func (s *service) Authenticate(ctx context.Context, token string) (*Viewer, error) {
+	claims, err := utils.ValidateJWT(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, errors.New("JWT_INVALID", "Invalid JWT token claims", 401, err, nil)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active() {
+		return nil, middleware.ErrAccountDisabled
+	}
+	if claims.IssuedAt != nil && user.SessionRevoked(claims.IssuedAt.Time) {
+		return nil, utils.ErrSessionRevoked
+	}
+
+	viewer := &Viewer{
+		UserID:    user.ID.Hex(),
+		Role:      user.Role,
+		Companies: make(map[string]bool, len(user.Company)),
+	}
+	for _, companyID := range user.Company {
+		viewer.Companies[companyID.Hex()] = true
+	}
+	if claims.ExpiresAt != nil {
+		viewer.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return viewer, nil
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// AccessNotifier emails users when they are granted access to reports. It consumes
+// report.access_granted events from the outbox and holds them for a batching window, so a
+// bulk grant across many reports produces a single digest email per user.
+//
+// Pending grants live in memory: they are flushed on shutdown, but a crash inside the window
+// loses them since the outbox has already marked the events dispatched.
+type AccessNotifier struct {
+	userRepo     domain.UserRepository
+	emailService utils.EmailService
+	appURL       string
+	window       time.Duration
+
+	mu      sync.Mutex
+	pending map[primitive.ObjectID][]utils.ReportLink
+}
+
+func NewAccessNotifier(userRepo domain.UserRepository, emailService utils.EmailService, appURL string, window time.Duration) *AccessNotifier {
+	return &AccessNotifier{
+		userRepo:     userRepo,
+		emailService: emailService,
+		appURL:       strings.TrimRight(appURL, "/"),
+		window:       window,
+		pending:      make(map[primitive.ObjectID][]utils.ReportLink),
+	}
+}
+
+// Publish queues a grant for every user in a report.access_granted event and ignores other events.
+func (n *AccessNotifier) Publish(ctx context.Context, event *domain.Event) error {
+	if event.Type != domain.EventReportAccessGranted {
+		return nil
+	}
+
+	var payload ReportAccessGrantedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	link := utils.ReportLink{
+		Name: payload.ReportName,
+		URL:  n.appURL + "/reports/" + payload.ReportID,
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, id := range payload.UserIDs {
+		userID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		if !containsLink(n.pending[userID], link) {
+			n.pending[userID] = append(n.pending[userID], link)
+		}
+	}
+
+	return nil
+}
+
+// Run sends a digest per user at the end of every window until ctx is cancelled,
+// then flushes whatever is still pending.
+func (n *AccessNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.flush(context.Background())
+			return
+		case <-ticker.C:
+			n.flush(ctx)
+		}
+	}
+}
+
+func (n *AccessNotifier) flush(ctx context.Context) {
+	n.mu.Lock()
+	batch := n.pending
+	n.pending = make(map[primitive.ObjectID][]utils.ReportLink)
+	n.mu.Unlock()
+
+	for userID, reports := range batch {
+		user, err := n.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			log.Warnf(ctx, "Access notifier: skipping user %s: %v", userID.Hex(), err)
+			continue
+		}
+		if !user.Active() {
+			continue
+		}
+
+		if err := n.emailService.SendReportAccessEmail(user.Email, user.Name, user.Locale, reports); err != nil {
+			log.Errorf(ctx, "Access notifier: failed to email %s about %d reports: %v", user.Email, len(reports), err)
+		}
+	}
+}
+
+func containsLink(links []utils.ReportLink, link utils.ReportLink) bool {
+	for _, l := range links {
+		if l.URL == link.URL {
+			return true
+		}
+	}
+	return false
+}
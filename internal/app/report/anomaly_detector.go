@@ -0,0 +1,370 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/notify"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// AnomalyDetector is an early warning for tampering with financial data. It consumes report
+// events from the outbox and raises a report.anomaly event, which webhooks can subscribe to,
+// and alerts the super admins and the admins of the reports' organization when:
+//   - one user deletes deleteThreshold or more reports of an organization within window
+//   - a report is shared with client users outside the report's company
+//   - the contents of an approved report are edited
+//
+// Recent deletions live in memory, so a restart forgets them. Alerts are sent by Run.
+type AnomalyDetector struct {
+	userRepo        domain.UserRepository
+	companyRepo     domain.CompanyRepository
+	outboxRepo      domain.OutboxRepository
+	notifier        notify.Notifier
+	appURL          string
+	deleteThreshold int
+	window          time.Duration
+
+	mu        sync.Mutex
+	deletions map[deletionKey][]deletion
+	alerts    []*ReportAnomalyEvent // raised but not yet sent
+	wake      chan struct{}
+}
+
+// deletionKey counts an actor's deletions per organization, so that each organization's
+// admins are only told about their own reports.
+type deletionKey struct {
+	actor        string
+	organization string
+}
+
+type deletion struct {
+	reportID string
+	at       time.Time
+	reported bool // already part of an anomaly
+}
+
+// NewAnomalyDetector creates a detector; a zero deleteThreshold disables the mass deletion check.
+func NewAnomalyDetector(userRepo domain.UserRepository, companyRepo domain.CompanyRepository, outboxRepo domain.OutboxRepository, notifier notify.Notifier, appURL string, deleteThreshold int, window time.Duration) *AnomalyDetector {
+	return &AnomalyDetector{
+		userRepo:        userRepo,
+		companyRepo:     companyRepo,
+		outboxRepo:      outboxRepo,
+		notifier:        notifier,
+		appURL:          strings.TrimRight(appURL, "/"),
+		deleteThreshold: deleteThreshold,
+		window:          window,
+		deletions:       make(map[deletionKey][]deletion),
+		wake:            make(chan struct{}, 1),
+	}
+}
+
+// Run sends the alerts of the anomalies raised until ctx is cancelled, and then the ones left.
+func (d *AnomalyDetector) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			d.sendAlerts(context.Background())
+			return
+		case <-d.wake:
+			d.sendAlerts(ctx)
+		}
+	}
+}
+
+func (d *AnomalyDetector) sendAlerts(ctx context.Context) {
+	d.mu.Lock()
+	alerts := d.alerts
+	d.alerts = nil
+	d.mu.Unlock()
+
+	for _, anomaly := range alerts {
+		d.alertAdmins(ctx, anomaly)
+	}
+}
+
+// Publish checks report.deleted, report.updated and report.access_granted events and ignores
+// other events.
+func (d *AnomalyDetector) Publish(ctx context.Context, event *domain.Event) error {
+	switch event.Type {
+	case domain.EventReportDeleted:
+		var payload ReportChangedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		companyID, err := primitive.ObjectIDFromHex(payload.Company)
+		if err != nil {
+			return nil
+		}
+		// Looked up before the deletion is counted; checkDeletion ignores redeliveries anyway
+		organization, err := d.organizationOf(ctx, companyID)
+		if err != nil {
+			return err
+		}
+		if anomaly := d.checkDeletion(payload, organization, event.CreatedAt); anomaly != nil {
+			d.raise(ctx, event.AggregateID, anomaly)
+		}
+
+	case domain.EventReportUpdated:
+		var payload ReportChangedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		anomaly, err := d.checkApprovedEdit(ctx, payload)
+		if err != nil {
+			return err
+		}
+		if anomaly != nil {
+			d.raise(ctx, event.AggregateID, anomaly)
+		}
+
+	case domain.EventReportAccessGranted:
+		var payload ReportAccessGrantedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		anomaly, err := d.checkAccessGrant(ctx, payload)
+		if err != nil {
+			return err
+		}
+		if anomaly != nil {
+			d.raise(ctx, event.AggregateID, anomaly)
+		}
+	}
+	return nil
+}
+
+// checkDeletion records a deletion of a report of organization and returns an anomaly once
+// its actor reaches the threshold within the window. The deletions reported then no longer
+// count, so a long purge raises one anomaly per threshold deletions rather than one per
+// deletion. The outbox may deliver an event more than once: a report already deleted within
+// the window isn't counted again.
+func (d *AnomalyDetector) checkDeletion(payload ReportChangedEvent, organization string, at time.Time) *ReportAnomalyEvent {
+	if d.deleteThreshold <= 0 || payload.Actor == "" {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := deletionKey{actor: payload.Actor, organization: organization}
+	recent := d.deletions[key][:0]
+	redelivered := false
+	for _, del := range d.deletions[key] {
+		if at.Sub(del.at) < d.window {
+			recent = append(recent, del)
+			redelivered = redelivered || del.reportID == payload.ReportID
+		}
+	}
+	if !redelivered {
+		recent = append(recent, deletion{reportID: payload.ReportID, at: at})
+	}
+	d.deletions[key] = recent
+
+	var unreported []string
+	for _, del := range recent {
+		if !del.reported {
+			unreported = append(unreported, del.reportID)
+		}
+	}
+	if redelivered || len(unreported) < d.deleteThreshold {
+		return nil
+	}
+	for i := range recent {
+		recent[i].reported = true
+	}
+
+	return &ReportAnomalyEvent{
+		Kind:         AnomalyMassDeletion,
+		Message:      fmt.Sprintf("%d reports were deleted within %s", len(unreported), d.window),
+		Actor:        payload.Actor,
+		Organization: organization,
+		ReportIDs:    unreported,
+	}
+}
+
+// checkAccessGrant returns an anomaly when the grant includes client users that don't
+// belong to the report's company. Admins see every company's reports anyway.
+func (d *AnomalyDetector) checkAccessGrant(ctx context.Context, payload ReportAccessGrantedEvent) (*ReportAnomalyEvent, error) {
+	companyID, err := primitive.ObjectIDFromHex(payload.Company)
+	if err != nil {
+		// Events recorded before grants named the company can't be checked
+		return nil, nil
+	}
+
+	organization, err := d.organizationOf(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []primitive.ObjectID
+	for _, id := range payload.UserIDs {
+		if userID, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, userID)
+		}
+	}
+	users, err := d.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var outsiders []string
+	for _, user := range users {
+		if user.Role == domain.RoleClient && !containsID(user.Company, companyID) {
+			outsiders = append(outsiders, user.ID.Hex())
+		}
+	}
+	if len(outsiders) == 0 {
+		return nil, nil
+	}
+
+	return &ReportAnomalyEvent{
+		Kind:         AnomalyExternalAccess,
+		Message:      fmt.Sprintf("Report %q was shared with %d user(s) outside its company", payload.ReportName, len(outsiders)),
+		Actor:        payload.Actor,
+		Organization: organization,
+		Company:      payload.Company,
+		ReportIDs:    []string{payload.ReportID},
+		UserIDs:      outsiders,
+	}, nil
+}
+
+// checkApprovedEdit returns an anomaly when the update changed the contents of an approved report.
+func (d *AnomalyDetector) checkApprovedEdit(ctx context.Context, payload ReportChangedEvent) (*ReportAnomalyEvent, error) {
+	if !payload.ApprovedEdit {
+		return nil, nil
+	}
+	companyID, err := primitive.ObjectIDFromHex(payload.Company)
+	if err != nil {
+		return nil, nil
+	}
+
+	organization, err := d.organizationOf(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportAnomalyEvent{
+		Kind:         AnomalyApprovedEdit,
+		Message:      fmt.Sprintf("Approved report %q was edited", payload.ReportName),
+		Actor:        payload.Actor,
+		Organization: organization,
+		Company:      payload.Company,
+		ReportIDs:    []string{payload.ReportID},
+	}, nil
+}
+
+// organizationOf returns the ID of the company's organization, or empty for a company of
+// the instance itself. Deleted companies are still found.
+func (d *AnomalyDetector) organizationOf(ctx context.Context, companyID primitive.ObjectID) (string, error) {
+	company, err := d.companyRepo.GetByID(domain.WithDeleted(ctx), companyID)
+	if err != nil {
+		return "", err
+	}
+	if company.Organization == nil {
+		return "", nil
+	}
+	return company.Organization.Hex(), nil
+}
+
+// raise records the anomaly in the outbox and queues the alert of the anomaly's admins for Run.
+// Failures are only logged, since retrying the triggering event wouldn't raise it again.
+func (d *AnomalyDetector) raise(ctx context.Context, aggregateID primitive.ObjectID, anomaly *ReportAnomalyEvent) {
+	log.Warnf(ctx, "Report anomaly (%s): %s, actor %s", anomaly.Kind, anomaly.Message, anomaly.Actor)
+
+	event, err := domain.NewEvent(domain.EventReportAnomaly, aggregateID, anomaly)
+	if err == nil {
+		// Mass deletions span the companies of an organization and follow the default retention
+		if company, parseErr := primitive.ObjectIDFromHex(anomaly.Company); parseErr == nil {
+			event.Company = &company
+		}
+		err = d.outboxRepo.Append(ctx, event)
+	}
+	if err != nil {
+		log.Errorf(ctx, "Anomaly detector: failed to record %s anomaly: %v", anomaly.Kind, err)
+	}
+
+	// Alerting the admins may take a while; the outbox dispatcher doesn't wait for it
+	d.mu.Lock()
+	d.alerts = append(d.alerts, anomaly)
+	d.mu.Unlock()
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// alertAdmins alerts the super admins and the admins of the anomaly's organization; admins
+// of other organizations must not learn about its reports or users.
+func (d *AnomalyDetector) alertAdmins(ctx context.Context, anomaly *ReportAnomalyEvent) {
+	alert := notify.Alert{
+		Subject: "Unusual report activity",
+		Message: anomaly.Message + ".",
+	}
+	if anomaly.Actor != "" {
+		alert.Message += " The change was made by " + d.userLabel(ctx, anomaly.Actor) + "."
+	}
+	alert.Message += " Check that it was intended."
+	if d.appURL != "" {
+		switch anomaly.Kind {
+		case AnomalyExternalAccess:
+			alert.Action = &utils.EmailAction{
+				Label: "Review report access",
+				URL:   d.appURL + "/reports/" + anomaly.ReportIDs[0],
+			}
+		case AnomalyApprovedEdit:
+			alert.Action = &utils.EmailAction{
+				Label: "Review report",
+				URL:   d.appURL + "/reports/" + anomaly.ReportIDs[0],
+			}
+		}
+	}
+
+	err := d.userRepo.Each(ctx, func(user *domain.User) error {
+		switch {
+		case !user.Active():
+			return nil
+		case user.Role == domain.RoleSuperAdmin:
+		case user.Role == domain.RoleAdmin && user.OrganizationClaim() == anomaly.Organization:
+		default:
+			return nil
+		}
+		if err := d.notifier.SendAlert(ctx, user, alert); err != nil {
+			log.Errorf(ctx, "Anomaly detector: failed to alert admin %s: %v", user.ID.Hex(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf(ctx, "Anomaly detector: failed to list admins: %v", err)
+	}
+}
+
+// userLabel names a user in alerts, falling back to the ID when the user can't be read
+func (d *AnomalyDetector) userLabel(ctx context.Context, id string) string {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return id
+	}
+	user, err := d.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", user.Name, user.Email)
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -16,5 +16,7 @@ var (
 	ErrInvalidYear           = errors.New("INVALID_YEAR", "Year format is invalid", http.StatusBadRequest, nil, nil)
 	ErrInsufficientCompanies = errors.New("INSUFFICIENT_COMPANIES", "Need 2 or more companies", http.StatusBadRequest, nil, nil)
 	ErrReportDataProcessing  = errors.New("REPORT_DATA_PROCESSING_ERROR", "Failed to process report data", http.StatusInternalServerError, nil, nil)
+	ErrReportAccessNotFound  = errors.New("REPORT_ACCESS_NOT_FOUND", "User has no access to this report", http.StatusNotFound, nil, nil)
 	ErrGeminiProcessing      = errors.New("GEMINI_PROCESSING_ERROR", "Failed to process data with AI", http.StatusInternalServerError, nil, nil)
+	ErrReportAlreadyApproved = errors.New("REPORT_ALREADY_APPROVED", "Report is already approved", http.StatusConflict, nil, nil)
 )
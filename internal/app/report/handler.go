@@ -2,13 +2,24 @@ package report
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
 	"finsolvz-backend/internal/utils"
 )
 
+// collapsedRelations are populated lists that can be long, so report responses reduce them
+// to their _id unless they are requested with ?expand=
+var collapsedRelations = []string{"userAccess"}
+
+// reportColumns are the CSV columns of report lists when ?fields= doesn't choose them
+var reportColumns = []string{"_id", "reportName", "reportType.name", "year", "company.name", "currency",
+	"createdBy.name", "userAccess.name", "createdAt", "updatedAt"}
+
 type Handler struct {
 	service   Service
 	validator *validator.Validate
@@ -22,19 +33,27 @@ func NewHandler(service Service) *Handler {
 }
 
 // RegisterRoutes registers report routes
+// @Tags Reports
 func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
 	protected := router.PathPrefix("").Subrouter()
 	protected.Use(authMiddleware)
+	protected.Use(includeReportData)
 
 	protected.HandleFunc("/api/reports", h.CreateReport).Methods("POST")
 	protected.HandleFunc("/api/reports/{id}", h.UpdateReport).Methods("PUT")
 	protected.HandleFunc("/api/reports/{id}", h.DeleteReport).Methods("DELETE")
+	protected.HandleFunc("/api/reports/{id}/access", h.GetReportAccess).Methods("GET")
+	protected.HandleFunc("/api/reports/{id}/access/{userId}", h.SetReportAccess).Methods("PUT")
+	protected.HandleFunc("/api/reports/{id}/access/{userId}", h.RevokeReportAccess).Methods("DELETE")
+	protected.HandleFunc("/api/reports/{id}/approve", h.ApproveReport).Methods("POST")
 
-	protected.HandleFunc("/api/reports", h.GetReports).Methods("GET")
+	protected.Handle("/api/reports", middleware.CacheResponses(middleware.ResponseCacheReports, time.Minute)(
+		http.HandlerFunc(h.GetReports))).Methods("GET")
 	protected.HandleFunc("/api/reports/paginated", h.GetReportsPaginated).Methods("GET")
 	protected.HandleFunc("/api/reports/{id}", h.GetReportByID).Methods("GET")
 	protected.HandleFunc("/api/reports/name/{name}", h.GetReportByName).Methods("GET")
-	protected.HandleFunc("/api/reports/company/{companyId}", h.GetReportsByCompany).Methods("GET")
+	protected.Handle("/api/reports/company/{companyId}", middleware.CacheResponses(middleware.ResponseCacheReports, time.Minute)(
+		http.HandlerFunc(h.GetReportsByCompany))).Methods("GET")
 	protected.HandleFunc("/api/reports/companies", h.GetReportsByCompanies).Methods("POST")
 
 	protected.HandleFunc("/api/reports/reportType/{reportType}", h.GetReportsByReportType).Methods("GET")
@@ -42,6 +61,18 @@ func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Ha
 	protected.HandleFunc("/api/reports/createdBy/{id}", h.GetReportsByCreatedBy).Methods("GET")
 }
 
+// includeReportData lets ?include=reportData add the report contents to list responses,
+// which otherwise carry metadata only.
+func includeReportData(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if utils.Included(r, "reportData") {
+			r = r.WithContext(domain.WithReportData(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// @Summary Create new report
 func (h *Handler) CreateReport(w http.ResponseWriter, r *http.Request) {
 	var req CreateReportRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -63,6 +94,7 @@ func (h *Handler) CreateReport(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusCreated, report)
 }
 
+// @Summary Update existing report
 func (h *Handler) UpdateReport(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -87,6 +119,7 @@ func (h *Handler) UpdateReport(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, report)
 }
 
+// @Summary Delete report
 func (h *Handler) DeleteReport(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -102,7 +135,79 @@ func (h *Handler) DeleteReport(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary List users with access to a report
+// @Description Each user given access has a level: VIEW, EDIT (change the report) or ADMIN (also delete it and manage who has access)
+func (h *Handler) GetReportAccess(w http.ResponseWriter, r *http.Request) {
+	access, err := h.service.GetReportAccess(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, access)
+}
+
+// @Summary Give a user access to a report or change their level
+// @Description Requires ADMIN access to the report, unless the access policy lets the caller update it
+func (h *Handler) SetReportAccess(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req SetReportAccessRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	access, err := h.service.SetReportAccess(r.Context(), vars["id"], vars["userId"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, access)
+}
+
+// @Summary Revoke a user's access to a report
+// @Description Requires ADMIN access to the report, unless the access policy lets the caller update it
+func (h *Handler) RevokeReportAccess(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := h.service.RevokeReportAccess(r.Context(), vars["id"], vars["userId"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Approve a report
+// @Description Signs the report off as the caller. Admins approve the reports of their companies.
+// @Description Later changes to its contents are raised as approved_edit anomalies.
+func (h *Handler) ApproveReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.ApproveReport(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, report)
+}
+
+// @Summary Get all reports with full population
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReports(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
+	if format := utils.RowFormat(r); format != "" {
+		h.streamReports(w, r, format)
+		return
+	}
+
 	reports, err := h.service.GetReports(r.Context())
 	if err != nil {
 		utils.HandleHTTPError(w, err, r)
@@ -112,7 +217,25 @@ func (h *Handler) GetReports(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, reports)
 }
 
+// streamReports writes all reports as CSV or NDJSON rows while they are read.
+func (h *Handler) streamReports(w http.ResponseWriter, r *http.Request, format string) {
+	rows := utils.NewRowWriter(w, r, format, reportColumns...)
+	err := h.service.EachReport(r.Context(), func(report *ReportResponse) error {
+		return rows.Write(report)
+	})
+	if err == nil {
+		err = rows.Close()
+	}
+	if err != nil {
+		rows.Fail(err, r)
+	}
+}
+
+// @Summary Get reports page by page
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReportsPaginated(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	pagination := utils.GetPaginationParams(r)
 
 	reports, total, err := h.service.GetReportsPaginated(r.Context(), pagination.Skip, pagination.Limit)
@@ -122,11 +245,15 @@ func (h *Handler) GetReportsPaginated(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pagination.Total = total
+	utils.SetPaginationHeaders(w, r, pagination)
 	response := utils.CreatePaginatedResponse(reports, pagination)
 	utils.RespondJSON(w, http.StatusOK, response)
 }
 
+// @Summary Get report by ID with full population
 func (h *Handler) GetReportByID(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	vars := mux.Vars(r)
 	id := vars["id"]
 
@@ -139,7 +266,10 @@ func (h *Handler) GetReportByID(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, report)
 }
 
+// @Summary Get report by name
 func (h *Handler) GetReportByName(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	vars := mux.Vars(r)
 	name := vars["name"]
 
@@ -152,7 +282,11 @@ func (h *Handler) GetReportByName(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, report)
 }
 
+// @Summary Get reports by company ID
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReportsByCompany(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	vars := mux.Vars(r)
 	companyId := vars["companyId"]
 
@@ -165,7 +299,11 @@ func (h *Handler) GetReportsByCompany(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, reports)
 }
 
+// @Summary Get reports by multiple company IDs
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReportsByCompanies(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	var req GetReportsByCompaniesRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
 		utils.HandleHTTPError(w, err, r)
@@ -186,7 +324,11 @@ func (h *Handler) GetReportsByCompanies(w http.ResponseWriter, r *http.Request)
 	utils.RespondJSON(w, http.StatusOK, reports)
 }
 
+// @Summary Get reports by report type ID
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReportsByReportType(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	vars := mux.Vars(r)
 	reportType := vars["reportType"]
 
@@ -199,7 +341,11 @@ func (h *Handler) GetReportsByReportType(w http.ResponseWriter, r *http.Request)
 	utils.RespondJSON(w, http.StatusOK, reports)
 }
 
+// @Summary Get reports accessible by user ID
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReportsByUserAccess(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	vars := mux.Vars(r)
 	id := vars["id"]
 
@@ -212,7 +358,11 @@ func (h *Handler) GetReportsByUserAccess(w http.ResponseWriter, r *http.Request)
 	utils.RespondJSON(w, http.StatusOK, reports)
 }
 
+// @Summary Get reports created by user ID
+// @Param include query string false "Set to reportData to include the report contents"
 func (h *Handler) GetReportsByCreatedBy(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r, collapsedRelations...)
+
 	vars := mux.Vars(r)
 	id := vars["id"]
 
@@ -5,28 +5,38 @@ import (
 	"time"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+	"finsolvz-backend/internal/utils"
 )
 
+// ReportData is the content of a report, stored as the client sent it: a list of rows
+// (the legacy format) or an object of sections. The variants carry no type tag, so clients
+// tell them apart by their JSON kind.
+//
+// @OneOf []interface{}
+// @OneOf map[string]interface{}
+type ReportData interface{}
+
 // ✅ FIXED: Request DTOs - exact field names sesuai dengan legacy Node.js
 type CreateReportRequest struct {
-	ReportName string      `json:"reportName" validate:"required,min=1,max=200"`
-	ReportType string      `json:"reportType" validate:"required"`
-	Year       string      `json:"year" validate:"required"`
-	Company    string      `json:"company" validate:"required"`
-	Currency   *string     `json:"currency,omitempty"`
-	CreateBy   string      `json:"createBy" validate:"required"` // ✅ FIXED: "createBy" bukan "createdBy"
-	UserAccess []string    `json:"userAccess,omitempty"`
-	ReportData interface{} `json:"reportData,omitempty"`
+	ReportName string     `json:"reportName" validate:"required,min=1,max=200"`
+	ReportType string     `json:"reportType" validate:"required"`
+	Year       string     `json:"year" validate:"required"`
+	Company    string     `json:"company" validate:"required"`
+	Currency   *string    `json:"currency,omitempty"`
+	CreateBy   string     `json:"createBy" validate:"required"` // ✅ FIXED: "createBy" bukan "createdBy"
+	UserAccess []string   `json:"userAccess,omitempty"`
+	ReportData ReportData `json:"reportData,omitempty"`
 }
 
 type UpdateReportRequest struct {
-	ReportName *string     `json:"reportName,omitempty" validate:"omitempty,min=1,max=200"`
-	ReportType *string     `json:"reportType,omitempty"`
-	Year       *string     `json:"year,omitempty"`
-	Company    *string     `json:"company,omitempty"`
-	Currency   *string     `json:"currency,omitempty"`
-	UserAccess []string    `json:"userAccess,omitempty"`
-	ReportData interface{} `json:"reportData,omitempty"`
+	ReportName *string    `json:"reportName,omitempty" validate:"omitempty,min=1,max=200"`
+	ReportType *string    `json:"reportType,omitempty"`
+	Year       *string    `json:"year,omitempty"`
+	Company    *string    `json:"company,omitempty"`
+	Currency   *string    `json:"currency,omitempty"`
+	UserAccess []string   `json:"userAccess,omitempty"`
+	ReportData ReportData `json:"reportData,omitempty"`
 }
 
 type GetReportsByCompaniesRequest struct {
@@ -43,9 +53,94 @@ type ReportResponse struct {
 	Currency   *string         `json:"currency"`
 	CreatedBy  *UserInfo       `json:"createdBy"` // ✅ Response uses "createdBy"
 	UserAccess []*UserInfo     `json:"userAccess"`
-	ReportData interface{}     `json:"reportData"`
+	ReportData ReportData      `json:"reportData,omitempty"` // left out of lists without ?include=reportData
 	CreatedAt  time.Time       `json:"createdAt"`
 	UpdatedAt  time.Time       `json:"updatedAt"`
+
+	// Lineage is where the report's data came from; nil for reports written before it was recorded
+	Lineage *domain.ReportLineage `json:"lineage,omitempty"`
+	// Approval is who signed the report off and when; nil until it is approved
+	Approval *domain.ReportApproval `json:"approval,omitempty"`
+	// AccessLevels are the levels of the users in UserAccess; users without one can view
+	AccessLevels []domain.ReportAccess `json:"accessLevels,omitempty"`
+
+	// FiscalYear is when Year runs in the company's fiscal calendar, set by comparisons across
+	// companies, whose fiscal years may not line up
+	FiscalYear *domain.FiscalYear `json:"fiscalYear,omitempty"`
+	// Format is how the company writes amounts in the report's currency, set by comparisons
+	// across companies so their figures can be shown alike
+	Format *format.Spec `json:"format,omitempty"`
+}
+
+// Links of a report in enveloped responses.
+func (r *ReportResponse) Links() utils.Links {
+	links := utils.Links{"self": {Href: "/api/reports/" + r.ID}}
+	if r.Company != nil {
+		links["company"] = utils.Link{Href: "/api/company/" + r.Company.ID}
+	}
+	if r.ReportType != nil {
+		links["reportType"] = utils.Link{Href: "/api/reportTypes/" + r.ReportType.ID}
+	}
+	return links
+}
+
+// ReportCreatedEvent is the payload of the report.created domain event
+type ReportCreatedEvent struct {
+	ReportID   string   `json:"reportId"`
+	ReportName string   `json:"reportName"`
+	ReportType string   `json:"reportType"`
+	Year       string   `json:"year"`
+	Company    string   `json:"company"`
+	CreatedBy  string   `json:"createdBy"`
+	UserAccess []string `json:"userAccess"`
+	// Lineage is where the report's data came from, for the audit trail
+	Lineage *domain.ReportLineage `json:"lineage,omitempty"`
+}
+
+// ReportChangedEvent is the payload of the report.updated, report.approved and report.deleted
+// domain events. It carries the report's company and users so consumers can tell who may see
+// the change, and the actor who made it.
+type ReportChangedEvent struct {
+	ReportID   string   `json:"reportId"`
+	ReportName string   `json:"reportName"`
+	Company    string   `json:"company"`
+	CreatedBy  string   `json:"createdBy"`
+	UserAccess []string `json:"userAccess"`
+	Actor      string   `json:"actor,omitempty"`
+	// Lineage is where the report's data came from after the change
+	Lineage *domain.ReportLineage `json:"lineage,omitempty"`
+	// ApprovedEdit is set on report.updated when the contents of an approved report changed
+	ApprovedEdit bool `json:"approvedEdit,omitempty"`
+}
+
+// ReportAccessGrantedEvent is the payload of the report.access_granted domain event.
+// UserIDs only lists users that did not have access before.
+type ReportAccessGrantedEvent struct {
+	ReportID   string   `json:"reportId"`
+	ReportName string   `json:"reportName"`
+	Company    string   `json:"company"`
+	UserIDs    []string `json:"userIds"`
+	Actor      string   `json:"actor,omitempty"`
+}
+
+// Anomaly kinds raised by the AnomalyDetector
+const (
+	AnomalyMassDeletion   = "mass_deletion"
+	AnomalyExternalAccess = "external_access"
+	AnomalyApprovedEdit   = "approved_edit"
+)
+
+// ReportAnomalyEvent is the payload of the report.anomaly domain event, raised when report
+// activity looks like tampering with financial data.
+type ReportAnomalyEvent struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Actor   string `json:"actor,omitempty"`
+	// Organization owns the reports; empty for the instance's own reports
+	Organization string   `json:"organization,omitempty"`
+	Company      string   `json:"company,omitempty"`
+	ReportIDs    []string `json:"reportIds"`
+	UserIDs      []string `json:"userIds,omitempty"` // users outside the company, for external_access
 }
 
 // Nested response types untuk populated data (exact legacy format)
@@ -63,12 +158,12 @@ type CompanyInfo struct {
 }
 
 type UserInfo struct {
-	ID        string    `json:"_id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string          `json:"_id"`
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	Role      domain.UserRole `json:"role"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
 }
 
 // ✅ ENHANCED: Helper functions untuk konversi domain ke response
@@ -79,8 +174,12 @@ func ToReportResponse(report *domain.PopulatedReport) *ReportResponse {
 		Year:       strconv.Itoa(report.Year), // Convert int to string for response
 		Currency:   report.Currency,
 		ReportData: report.ReportData,
+		Lineage:    report.Lineage,
+		Approval:   report.Approval,
 		CreatedAt:  report.CreatedAt,
 		UpdatedAt:  report.UpdatedAt,
+
+		AccessLevels: report.AccessLevels,
 	}
 
 	// ✅ Handle nil case untuk reportData seperti legacy
@@ -113,7 +212,7 @@ func ToReportResponse(report *domain.PopulatedReport) *ReportResponse {
 			ID:        report.CreatedBy.ID.Hex(),
 			Name:      report.CreatedBy.Name,
 			Email:     report.CreatedBy.Email,
-			Role:      string(report.CreatedBy.Role),
+			Role:      report.CreatedBy.Role,
 			CreatedAt: report.CreatedBy.CreatedAt,
 			UpdatedAt: report.CreatedBy.UpdatedAt,
 		}
@@ -127,7 +226,7 @@ func ToReportResponse(report *domain.PopulatedReport) *ReportResponse {
 				ID:        user.ID.Hex(),
 				Name:      user.Name,
 				Email:     user.Email,
-				Role:      string(user.Role),
+				Role:      user.Role,
 				CreatedAt: user.CreatedAt,
 				UpdatedAt: user.UpdatedAt,
 			}
@@ -139,6 +238,36 @@ func ToReportResponse(report *domain.PopulatedReport) *ReportResponse {
 	return response
 }
 
+// SetReportAccessRequest is the level a user is given on a report
+type SetReportAccessRequest struct {
+	Level string `json:"level" validate:"required,oneof=VIEW EDIT ADMIN"`
+}
+
+// ReportAccessResponse is a user given access to a report and their level
+type ReportAccessResponse struct {
+	User  *UserInfo                `json:"user"`
+	Level domain.ReportAccessLevel `json:"level"`
+}
+
+// ToReportAccessResponses lists the users given access to the report with their levels
+func ToReportAccessResponses(report *domain.PopulatedReport) []*ReportAccessResponse {
+	responses := make([]*ReportAccessResponse, 0, len(report.UserAccess))
+	for _, user := range report.UserAccess {
+		responses = append(responses, &ReportAccessResponse{
+			User: &UserInfo{
+				ID:        user.ID.Hex(),
+				Name:      user.Name,
+				Email:     user.Email,
+				Role:      user.Role,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			},
+			Level: report.AccessLevel(user.ID),
+		})
+	}
+	return responses
+}
+
 // ToReportResponseArray converts array of domain reports to response array
 func ToReportResponseArray(reports []*domain.PopulatedReport) []*ReportResponse {
 	responses := make([]*ReportResponse, len(reports))
@@ -3,6 +3,7 @@ package report
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -10,6 +11,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/format"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
 	"finsolvz-backend/internal/utils"
 	"finsolvz-backend/internal/utils/errors"
 )
@@ -19,6 +23,8 @@ type Service interface {
 	UpdateReport(ctx context.Context, id string, req UpdateReportRequest) (*ReportResponse, error)
 	DeleteReport(ctx context.Context, id string) error
 	GetReports(ctx context.Context) ([]*ReportResponse, error)
+	// EachReport calls fn with the reports of GetReports one at a time as they are read
+	EachReport(ctx context.Context, fn func(*ReportResponse) error) error
 	GetReportsPaginated(ctx context.Context, skip, limit int) ([]*ReportResponse, int, error)
 	GetReportByID(ctx context.Context, id string) (*ReportResponse, error)
 	GetReportByName(ctx context.Context, name string) (*ReportResponse, error)
@@ -27,15 +33,26 @@ type Service interface {
 	GetReportsByReportType(ctx context.Context, reportTypeID string) ([]*ReportResponse, error)
 	GetReportsByUserAccess(ctx context.Context, userID string) ([]*ReportResponse, error)
 	GetReportsByCreatedBy(ctx context.Context, userID string) ([]*ReportResponse, error)
+	// GetReportAccess lists the users given access to the report, with their levels.
+	GetReportAccess(ctx context.Context, id string) ([]*ReportAccessResponse, error)
+	// SetReportAccess gives the user access to the report at the level, or changes their level.
+	SetReportAccess(ctx context.Context, id, userID string, req SetReportAccessRequest) ([]*ReportAccessResponse, error)
+	RevokeReportAccess(ctx context.Context, id, userID string) error
+	// ApproveReport signs the report off. Later edits to its contents are flagged as anomalies.
+	ApproveReport(ctx context.Context, id string) (*ReportResponse, error)
 }
 
 type service struct {
 	reportRepo domain.ReportRepository
+	outboxRepo domain.OutboxRepository
+	tx         domain.Transactor
 }
 
-func NewService(reportRepo domain.ReportRepository) Service {
+func NewService(reportRepo domain.ReportRepository, outboxRepo domain.OutboxRepository, tx domain.Transactor) Service {
 	return &service{
 		reportRepo: reportRepo,
+		outboxRepo: outboxRepo,
+		tx:         tx,
 	}
 }
 
@@ -93,11 +110,40 @@ func (s *service) CreateReport(ctx context.Context, req CreateReportRequest) (*R
 		CreatedBy:  createdByID,
 		UserAccess: userAccessIDs,
 		ReportData: reportData,
+		Lineage:    lineage(ctx),
 	}
+	report.AccessLevels = accessLevels(report.UserAccess, nil)
 
-	if err := s.reportRepo.Create(ctx, report); err != nil {
+	// Persist the report and its event atomically so the notification can't be lost
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.reportRepo.Create(ctx, report); err != nil {
+			return err
+		}
+
+		event, err := domain.NewEvent(domain.EventReportCreated, report.ID, ReportCreatedEvent{
+			ReportID:   report.ID.Hex(),
+			ReportName: report.ReportName,
+			ReportType: report.ReportType.Hex(),
+			Year:       strconv.Itoa(report.Year),
+			Company:    report.Company.Hex(),
+			CreatedBy:  report.CreatedBy.Hex(),
+			UserAccess: hexIDs(report.UserAccess),
+			Lineage:    report.Lineage,
+		})
+		if err != nil {
+			return errors.New("EVENT_ENCODING_ERROR", "Failed to encode report event", 500, err, nil)
+		}
+		event.Company = &report.Company
+		if err := s.outboxRepo.Append(ctx, event); err != nil {
+			return err
+		}
+
+		return s.recordAccessGranted(ctx, report, grantedUsers(nil, report.UserAccess))
+	})
+	if err != nil {
 		return nil, err
 	}
+	middleware.InvalidateResponses(middleware.ResponseCacheReports)
 
 	populatedReport, err := s.reportRepo.GetByID(ctx, report.ID)
 	if err != nil {
@@ -117,27 +163,17 @@ func (s *service) UpdateReport(ctx context.Context, id string, req UpdateReportR
 	if err != nil {
 		return nil, err
 	}
+	// Users given access may edit at EDIT, but only change who has access at ADMIN
+	level := domain.ReportAccessEdit
+	if req.UserAccess != nil {
+		level = domain.ReportAccessAdmin
+	}
+	if err := authorizeReport(ctx, "update", existingReport, level); err != nil {
+		return nil, err
+	}
 
 	// Prepare update data from existing report
-	updateReport := &domain.Report{
-		ID:         existingReport.ID,
-		ReportName: existingReport.ReportName,
-		ReportType: existingReport.ReportType.ID,
-		Year:       existingReport.Year,
-		Company:    existingReport.Company.ID,
-		Currency:   existingReport.Currency,
-		CreatedBy:  existingReport.CreatedBy.ID,
-		UserAccess: []primitive.ObjectID{},
-		ReportData: existingReport.ReportData,
-		CreatedAt:  existingReport.CreatedAt,
-	}
-
-	// Convert populated user access back to ObjectIDs
-	if existingReport.UserAccess != nil {
-		for _, user := range existingReport.UserAccess {
-			updateReport.UserAccess = append(updateReport.UserAccess, user.ID)
-		}
-	}
+	updateReport := unpopulate(existingReport)
 
 	if req.ReportName != nil {
 		updateReport.ReportName = strings.TrimSpace(*req.ReportName)
@@ -167,6 +203,8 @@ func (s *service) UpdateReport(ctx context.Context, id string, req UpdateReportR
 		updateReport.Currency = req.Currency
 	}
 
+	previousAccess := updateReport.UserAccess
+
 	if req.UserAccess != nil {
 		var userAccessIDs []primitive.ObjectID
 		for _, userIDStr := range req.UserAccess {
@@ -177,40 +215,234 @@ func (s *service) UpdateReport(ctx context.Context, id string, req UpdateReportR
 			userAccessIDs = append(userAccessIDs, userID)
 		}
 		updateReport.UserAccess = userAccessIDs
+		updateReport.AccessLevels = accessLevels(userAccessIDs, updateReport.AccessLevels)
 	}
 
 	if req.ReportData != nil {
 		updateReport.ReportData = req.ReportData
+		updateReport.Lineage = lineage(ctx)
 	}
 
-	updatedReport, err := s.reportRepo.Update(ctx, reportID, updateReport)
+	// Changing who has access leaves the approved figures as they were
+	approvedEdit := existingReport.Approval != nil && (req.ReportName != nil || req.ReportType != nil ||
+		req.Year != nil || req.Company != nil || req.Currency != nil || req.ReportData != nil)
+
+	var updatedReport *domain.PopulatedReport
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if updatedReport, err = s.reportRepo.Update(ctx, reportID, updateReport); err != nil {
+			return err
+		}
+
+		if err := s.recordChanged(ctx, domain.EventReportUpdated, updateReport, approvedEdit); err != nil {
+			return err
+		}
+		return s.recordAccessGranted(ctx, updateReport, grantedUsers(previousAccess, updateReport.UserAccess))
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Invalidate cache
-	cache := utils.GetCache()
-	cacheKey := fmt.Sprintf("report:%s", id)
-	cache.Delete(cacheKey)
+	invalidateReport(id)
 
 	return ToReportResponse(updatedReport), nil
 }
 
+// invalidateReport drops the cached copies of the report and of report lists.
+func invalidateReport(id string) {
+	utils.GetCache().Delete(fmt.Sprintf("report:%s", id))
+	middleware.InvalidateResponses(middleware.ResponseCacheReports)
+}
+
+// recordAccessGranted appends a report.access_granted event for users newly added to userAccess
+func (s *service) recordAccessGranted(ctx context.Context, report *domain.Report, userIDs []primitive.ObjectID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	event, err := domain.NewEvent(domain.EventReportAccessGranted, report.ID, ReportAccessGrantedEvent{
+		ReportID:   report.ID.Hex(),
+		ReportName: report.ReportName,
+		Company:    report.Company.Hex(),
+		UserIDs:    hexIDs(userIDs),
+		Actor:      actor(ctx),
+	})
+	if err != nil {
+		return errors.New("EVENT_ENCODING_ERROR", "Failed to encode report event", 500, err, nil)
+	}
+	event.Company = &report.Company
+	return s.outboxRepo.Append(ctx, event)
+}
+
+// recordChanged appends a report.updated, report.approved or report.deleted event for the report. approvedEdit
+// marks an update that changed the contents of an approved report.
+func (s *service) recordChanged(ctx context.Context, eventType domain.EventType, report *domain.Report, approvedEdit bool) error {
+	event, err := domain.NewEvent(eventType, report.ID, ReportChangedEvent{
+		ReportID:   report.ID.Hex(),
+		ReportName: report.ReportName,
+		Company:    report.Company.Hex(),
+		CreatedBy:  report.CreatedBy.Hex(),
+		UserAccess: hexIDs(report.UserAccess),
+		Actor:      actor(ctx),
+		Lineage:    report.Lineage,
+
+		ApprovedEdit: approvedEdit,
+	})
+	if err != nil {
+		return errors.New("EVENT_ENCODING_ERROR", "Failed to encode report event", 500, err, nil)
+	}
+	event.Company = &report.Company
+	return s.outboxRepo.Append(ctx, event)
+}
+
+// lineage returns the source of the report data ctx writes: the one set with
+// domain.WithLineage, or else a manual entry, or an entry sent with the request's API key
+func lineage(ctx context.Context) *domain.ReportLineage {
+	recorded := domain.ReportLineage{Source: domain.LineageManual}
+	set := domain.LineageOf(ctx)
+	if set != nil {
+		recorded = *set
+	}
+	if userCtx, ok := middleware.GetUserFromContext(ctx); ok {
+		if id, err := primitive.ObjectIDFromHex(userCtx.UserID); err == nil && recorded.Actor == nil {
+			recorded.Actor = &id
+		}
+		if id, err := primitive.ObjectIDFromHex(userCtx.APIKeyID); err == nil && recorded.APIKey == nil {
+			recorded.APIKey = &id
+			if set == nil {
+				recorded.Source = domain.LineageAPIKey
+			}
+		}
+	}
+	recorded.RecordedAt = time.Now()
+	return &recorded
+}
+
+// actor returns the ID of the user making the request, or "" outside a request
+func actor(ctx context.Context) string {
+	if userCtx, ok := middleware.GetUserFromContext(ctx); ok {
+		return userCtx.UserID
+	}
+	return ""
+}
+
+// reportResource describes a report to the access policy: owned by its creator and
+// belonging to its company.
+func reportResource(report *domain.PopulatedReport) policy.Resource {
+	res := policy.Resource{Type: "report"}
+	if report.CreatedBy != nil {
+		res.Owners = []string{report.CreatedBy.ID.Hex()}
+	}
+	if report.Company != nil {
+		res.Companies = []string{report.Company.ID.Hex()}
+	}
+	return res
+}
+
+// authorizeReport checks the access policy allows action on the report, or else that the
+// user was given access to it at level or above.
+func authorizeReport(ctx context.Context, action string, report *domain.PopulatedReport, level domain.ReportAccessLevel) error {
+	err := middleware.Authorize(ctx, action, reportResource(report))
+	if err != utils.ErrForbidden {
+		return err
+	}
+	if userCtx, ok := middleware.GetUserFromContext(ctx); ok {
+		if userID, idErr := primitive.ObjectIDFromHex(userCtx.UserID); idErr == nil && report.AccessLevel(userID).Includes(level) {
+			return nil
+		}
+	}
+	return err
+}
+
+// accessLevels returns the level of each user of userAccess: the one in previous, or VIEW for
+// users given access without one.
+func accessLevels(userAccess []primitive.ObjectID, previous []domain.ReportAccess) []domain.ReportAccess {
+	levels := make([]domain.ReportAccess, 0, len(userAccess))
+	for _, userID := range userAccess {
+		level := domain.ReportAccessView
+		for _, access := range previous {
+			if access.User == userID && access.Level.IsValid() {
+				level = access.Level
+			}
+		}
+		levels = append(levels, domain.ReportAccess{User: userID, Level: level})
+	}
+	return levels
+}
+
+// unpopulate converts a populated report back to the references it is stored with
+func unpopulate(report *domain.PopulatedReport) *domain.Report {
+	stored := &domain.Report{
+		ID:           report.ID,
+		ReportName:   report.ReportName,
+		ReportType:   report.ReportType.ID,
+		Year:         report.Year,
+		Company:      report.Company.ID,
+		Currency:     report.Currency,
+		CreatedBy:    report.CreatedBy.ID,
+		UserAccess:   []primitive.ObjectID{},
+		AccessLevels: report.AccessLevels,
+		ReportData:   report.ReportData,
+		Lineage:      report.Lineage,
+		Approval:     report.Approval,
+		CreatedAt:    report.CreatedAt,
+	}
+	for _, user := range report.UserAccess {
+		stored.UserAccess = append(stored.UserAccess, user.ID)
+	}
+	return stored
+}
+
+func hexIDs(ids []primitive.ObjectID) []string {
+	hex := make([]string, len(ids))
+	for i, id := range ids {
+		hex[i] = id.Hex()
+	}
+	return hex
+}
+
+// grantedUsers returns the IDs in current that are not in previous
+func grantedUsers(previous, current []primitive.ObjectID) []primitive.ObjectID {
+	had := make(map[primitive.ObjectID]bool, len(previous))
+	for _, id := range previous {
+		had[id] = true
+	}
+
+	var granted []primitive.ObjectID
+	for _, id := range current {
+		if !had[id] {
+			granted = append(granted, id)
+			had[id] = true
+		}
+	}
+	return granted
+}
+
 func (s *service) DeleteReport(ctx context.Context, id string) error {
 	reportID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return errors.New("INVALID_REPORT_ID", "Invalid report ID format", 400, err, nil)
 	}
 
-	err = s.reportRepo.Delete(ctx, reportID)
+	existingReport, err := s.reportRepo.GetByID(ctx, reportID)
 	if err != nil {
 		return err
 	}
+	if err := authorizeReport(ctx, "delete", existingReport, domain.ReportAccessAdmin); err != nil {
+		return err
+	}
 
-	// Invalidate cache
-	cache := utils.GetCache()
-	cacheKey := fmt.Sprintf("report:%s", id)
-	cache.Delete(cacheKey)
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.reportRepo.Delete(ctx, reportID); err != nil {
+			return err
+		}
+		return s.recordChanged(ctx, domain.EventReportDeleted, unpopulate(existingReport), false)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateReport(id)
 
 	return nil
 }
@@ -221,7 +453,32 @@ func (s *service) GetReports(ctx context.Context) ([]*ReportResponse, error) {
 		return nil, err
 	}
 
-	return ToReportResponseArray(reports), nil
+	return listResponses(ctx, reports), nil
+}
+
+func (s *service) EachReport(ctx context.Context, fn func(*ReportResponse) error) error {
+	return s.reportRepo.Each(ctx, func(report *domain.PopulatedReport) error {
+		return fn(listResponse(ctx, report))
+	})
+}
+
+// listResponses converts the reports of a list read, which carry no reportData unless ctx
+// asks for it (see domain.WithReportData)
+func listResponses(ctx context.Context, reports []*domain.PopulatedReport) []*ReportResponse {
+	responses := make([]*ReportResponse, len(reports))
+	for i, report := range reports {
+		responses[i] = listResponse(ctx, report)
+	}
+	return responses
+}
+
+func listResponse(ctx context.Context, report *domain.PopulatedReport) *ReportResponse {
+	response := ToReportResponse(report)
+	if !domain.IncludesReportData(ctx) {
+		// Omitted rather than the empty array of reports without data
+		response.ReportData = nil
+	}
+	return response
 }
 
 func (s *service) GetReportsPaginated(ctx context.Context, skip, limit int) ([]*ReportResponse, int, error) {
@@ -230,16 +487,17 @@ func (s *service) GetReportsPaginated(ctx context.Context, skip, limit int) ([]*
 		return nil, 0, err
 	}
 
-	return ToReportResponseArray(reports), total, nil
+	return listResponses(ctx, reports), total, nil
 }
 
 func (s *service) GetReportByID(ctx context.Context, id string) (*ReportResponse, error) {
-	// Try cache first
+	// Try cache first; entries are shared, so callers limited by a tenant or access scope skip it
 	cache := utils.GetCache()
 	cacheKey := fmt.Sprintf("report:%s", id)
 
-	if cached, found := cache.Get(cacheKey); found {
-		return cached.(*ReportResponse), nil
+	var cached *ReportResponse
+	if found := cache.Get(cacheKey, &cached); found && domain.ReadsEverything(ctx) {
+		return cached, nil
 	}
 
 	reportID, err := primitive.ObjectIDFromHex(id)
@@ -285,7 +543,7 @@ func (s *service) GetReportsByCompany(ctx context.Context, companyID string) ([]
 		return nil, err
 	}
 
-	return ToReportResponseArray(reports), nil
+	return listResponses(ctx, reports), nil
 }
 
 func (s *service) GetReportsByCompanies(ctx context.Context, req GetReportsByCompaniesRequest) ([]*ReportResponse, error) {
@@ -308,7 +566,19 @@ func (s *service) GetReportsByCompanies(ctx context.Context, req GetReportsByCom
 		return nil, err
 	}
 
-	return ToReportResponseArray(reports), nil
+	responses := listResponses(ctx, reports)
+	for i, report := range reports {
+		if report.Company != nil {
+			fiscalYear := report.Company.FiscalCalendar.Year(report.Year)
+			responses[i].FiscalYear = &fiscalYear
+			var currency string
+			if report.Currency != nil {
+				currency = *report.Currency
+			}
+			responses[i].Format = format.ForBranding(report.Company.Branding, "").Spec(currency)
+		}
+	}
+	return responses, nil
 }
 
 func (s *service) GetReportsByReportType(ctx context.Context, reportTypeID string) ([]*ReportResponse, error) {
@@ -322,7 +592,7 @@ func (s *service) GetReportsByReportType(ctx context.Context, reportTypeID strin
 		return nil, err
 	}
 
-	return ToReportResponseArray(reports), nil
+	return listResponses(ctx, reports), nil
 }
 
 func (s *service) GetReportsByUserAccess(ctx context.Context, userID string) ([]*ReportResponse, error) {
@@ -336,7 +606,7 @@ func (s *service) GetReportsByUserAccess(ctx context.Context, userID string) ([]
 		return nil, err
 	}
 
-	return ToReportResponseArray(reports), nil
+	return listResponses(ctx, reports), nil
 }
 
 func (s *service) GetReportsByCreatedBy(ctx context.Context, userID string) ([]*ReportResponse, error) {
@@ -350,5 +620,140 @@ func (s *service) GetReportsByCreatedBy(ctx context.Context, userID string) ([]*
 		return nil, err
 	}
 
-	return ToReportResponseArray(reports), nil
+	return listResponses(ctx, reports), nil
+}
+
+func (s *service) GetReportAccess(ctx context.Context, id string) ([]*ReportAccessResponse, error) {
+	reportID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_REPORT_ID", "Invalid report ID format", 400, err, nil)
+	}
+
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	return ToReportAccessResponses(report), nil
+}
+
+func (s *service) SetReportAccess(ctx context.Context, id, userID string, req SetReportAccessRequest) ([]*ReportAccessResponse, error) {
+	return s.changeAccess(ctx, id, userID, func(report *domain.Report, user primitive.ObjectID) error {
+		if !slices.Contains(report.UserAccess, user) {
+			report.UserAccess = append(report.UserAccess, user)
+		}
+		report.AccessLevels = accessLevels(report.UserAccess, report.AccessLevels)
+		for i := range report.AccessLevels {
+			if report.AccessLevels[i].User == user {
+				report.AccessLevels[i].Level = domain.ReportAccessLevel(req.Level)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *service) RevokeReportAccess(ctx context.Context, id, userID string) error {
+	_, err := s.changeAccess(ctx, id, userID, func(report *domain.Report, user primitive.ObjectID) error {
+		index := slices.Index(report.UserAccess, user)
+		if index < 0 {
+			return ErrReportAccessNotFound
+		}
+		report.UserAccess = slices.Delete(report.UserAccess, index, index+1)
+		report.AccessLevels = accessLevels(report.UserAccess, report.AccessLevels)
+		return nil
+	})
+	return err
+}
+
+func (s *service) ApproveReport(ctx context.Context, id string) (*ReportResponse, error) {
+	reportID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_REPORT_ID", "Invalid report ID format", 400, err, nil)
+	}
+
+	existingReport, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	// Signing off is up to the policy alone, not to the users given access
+	if err := middleware.Authorize(ctx, "approve", reportResource(existingReport)); err != nil {
+		return nil, err
+	}
+	if existingReport.Approval != nil {
+		return nil, ErrReportAlreadyApproved
+	}
+
+	userCtx, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+	approver, err := primitive.ObjectIDFromHex(userCtx.UserID)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	report := unpopulate(existingReport)
+	report.Approval = &domain.ReportApproval{By: approver, At: time.Now()}
+
+	var updatedReport *domain.PopulatedReport
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if updatedReport, err = s.reportRepo.Update(ctx, reportID, report); err != nil {
+			return err
+		}
+		return s.recordChanged(ctx, domain.EventReportApproved, report, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateReport(id)
+
+	return ToReportResponse(updatedReport), nil
+}
+
+// changeAccess applies change to who has access to the report, for users the policy lets
+// update it or with ADMIN access to it.
+func (s *service) changeAccess(ctx context.Context, id, userID string, change func(report *domain.Report, user primitive.ObjectID) error) ([]*ReportAccessResponse, error) {
+	reportID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_REPORT_ID", "Invalid report ID format", 400, err, nil)
+	}
+	user, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("INVALID_USER_ACCESS_ID", "Invalid user access ID format", 400, err, nil)
+	}
+
+	existingReport, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeReport(ctx, "update", existingReport, domain.ReportAccessAdmin); err != nil {
+		return nil, err
+	}
+
+	report := unpopulate(existingReport)
+	previousAccess := slices.Clone(report.UserAccess)
+	if err := change(report, user); err != nil {
+		return nil, err
+	}
+
+	var updatedReport *domain.PopulatedReport
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if updatedReport, err = s.reportRepo.Update(ctx, reportID, report); err != nil {
+			return err
+		}
+
+		if err := s.recordChanged(ctx, domain.EventReportUpdated, report, false); err != nil {
+			return err
+		}
+		return s.recordAccessGranted(ctx, report, grantedUsers(previousAccess, report.UserAccess))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateReport(id)
+
+	return ToReportAccessResponses(updatedReport), nil
 }
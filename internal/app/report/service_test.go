@@ -2,12 +2,18 @@ package report
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/notify"
+	"finsolvz-backend/internal/utils/errors"
 )
 
 // Mock repository for testing
@@ -17,6 +23,11 @@ type mockReportRepository struct {
 
 func (m *mockReportRepository) Create(ctx context.Context, report *domain.Report) error {
 	report.ID = primitive.NewObjectID()
+	m.reports = append(m.reports, domain.PopulatedReport{
+		ID:         report.ID,
+		ReportName: report.ReportName,
+		Year:       report.Year,
+	})
 	return nil
 }
 
@@ -41,6 +52,15 @@ func (m *mockReportRepository) GetAll(ctx context.Context) ([]*domain.PopulatedR
 	return result, nil
 }
 
+func (m *mockReportRepository) Each(ctx context.Context, fn func(*domain.PopulatedReport) error) error {
+	for i := range m.reports {
+		if err := fn(&m.reports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *mockReportRepository) GetAllPaginated(ctx context.Context, skip, limit int) ([]*domain.PopulatedReport, int, error) {
 	total := len(m.reports)
 	end := skip + limit
@@ -66,6 +86,10 @@ func (m *mockReportRepository) GetByCompanies(ctx context.Context, companyIDs []
 	return []*domain.PopulatedReport{&m.reports[0]}, nil
 }
 
+func (m *mockReportRepository) GetUpdatedSince(ctx context.Context, companyIDs []primitive.ObjectID, since time.Time) ([]*domain.PopulatedReport, error) {
+	return []*domain.PopulatedReport{&m.reports[0]}, nil
+}
+
 func (m *mockReportRepository) GetByReportType(ctx context.Context, reportTypeID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
 	return []*domain.PopulatedReport{&m.reports[0]}, nil
 }
@@ -86,6 +110,152 @@ func (m *mockReportRepository) Delete(ctx context.Context, id primitive.ObjectID
 	return nil
 }
 
+type mockOutboxRepository struct {
+	events []*domain.Event
+}
+
+func (m *mockOutboxRepository) Append(ctx context.Context, event *domain.Event) error {
+	event.ID = primitive.NewObjectID()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockOutboxRepository) Claim(ctx context.Context, lockedUntil time.Time) (*domain.Event, error) {
+	return nil, nil
+}
+
+func (m *mockOutboxRepository) CountPending(ctx context.Context) (int64, error) {
+	return int64(len(m.events)), nil
+}
+
+func (m *mockOutboxRepository) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+func (m *mockOutboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, deliveredTo []string, nextAttemptAt time.Time, final bool) error {
+	return nil
+}
+
+type mockUserRepository struct {
+	users []domain.User
+	// listed receives a value each time Each has gone through every user
+	listed chan struct{}
+}
+
+func (m *mockUserRepository) Create(ctx context.Context, user *domain.User) error { return nil }
+func (m *mockUserRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	for i := range m.users {
+		if m.users[i].ID == id {
+			return &m.users[i], nil
+		}
+	}
+	return nil, errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil)
+}
+func (m *mockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return nil, nil
+}
+func (m *mockUserRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.User, error) {
+	var result []*domain.User
+	for _, id := range ids {
+		if user, err := m.GetByID(ctx, id); err == nil {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+func (m *mockUserRepository) GetAll(ctx context.Context) ([]*domain.User, error) { return nil, nil }
+func (m *mockUserRepository) GetPage(ctx context.Context, query domain.UserQuery) ([]*domain.User, int, error) {
+	return nil, 0, nil
+}
+func (m *mockUserRepository) Each(ctx context.Context, fn func(*domain.User) error) error {
+	defer func() { m.listed <- struct{}{} }()
+	for i := range m.users {
+		if err := fn(&m.users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *mockUserRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
+	return nil
+}
+func (m *mockUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error { return nil }
+
+type mockCompanyRepository struct {
+	companies []domain.Company
+}
+
+func (m *mockCompanyRepository) Create(ctx context.Context, company *domain.Company) error {
+	return nil
+}
+func (m *mockCompanyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Company, error) {
+	for i := range m.companies {
+		if m.companies[i].ID == id {
+			return &m.companies[i], nil
+		}
+	}
+	return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+}
+func (m *mockCompanyRepository) GetByName(ctx context.Context, name string) (*domain.Company, error) {
+	return nil, nil
+}
+func (m *mockCompanyRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.Company, error) {
+	return nil, nil
+}
+func (m *mockCompanyRepository) SearchByName(ctx context.Context, name string) ([]*domain.Company, error) {
+	return nil, nil
+}
+func (m *mockCompanyRepository) GetAll(ctx context.Context) ([]*domain.Company, error) {
+	return nil, nil
+}
+func (m *mockCompanyRepository) Each(ctx context.Context, fn func(*domain.Company) error) error {
+	return nil
+}
+func (m *mockCompanyRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*domain.Company, error) {
+	return nil, nil
+}
+func (m *mockCompanyRepository) Update(ctx context.Context, id primitive.ObjectID, company *domain.Company) error {
+	return nil
+}
+func (m *mockCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error { return nil }
+
+type mockNotifier struct {
+	mu      sync.Mutex
+	alerted []string // names of the alerted users
+}
+
+func (m *mockNotifier) SendPassword(ctx context.Context, user *domain.User, password string) error {
+	return nil
+}
+func (m *mockNotifier) SendAlert(ctx context.Context, user *domain.User, alert notify.Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alerted = append(m.alerted, user.Name)
+	return nil
+}
+func (m *mockNotifier) SendLoginLink(ctx context.Context, user *domain.User, link string, expiresIn time.Duration) error {
+	return nil
+}
+func (m *mockNotifier) SendInvitation(ctx context.Context, user *domain.User, inviter, link string, expiresIn time.Duration) error {
+	return nil
+}
+
+// takeAlerted returns the users alerted so far, sorted, and forgets them
+func (m *mockNotifier) takeAlerted() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alerted := m.alerted
+	m.alerted = nil
+	sort.Strings(alerted)
+	return alerted
+}
+
+type mockTransactor struct{}
+
+func (mockTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestService_GetReportsPaginated(t *testing.T) {
 	// Setup mock data
 	mockRepo := &mockReportRepository{
@@ -107,7 +277,7 @@ func TestService_GetReportsPaginated(t *testing.T) {
 		},
 	}
 
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, &mockOutboxRepository{}, mockTransactor{})
 
 	// Test pagination
 	reports, total, err := service.GetReportsPaginated(context.Background(), 0, 1)
@@ -138,7 +308,7 @@ func TestService_GetReportByID_Performance(t *testing.T) {
 		},
 	}
 
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, &mockOutboxRepository{}, mockTransactor{})
 	reportID := mockRepo.reports[0].ID.Hex()
 
 	// Measure performance
@@ -169,3 +339,365 @@ func TestService_GetReportByID_Performance(t *testing.T) {
 		t.Fatalf("Cached request took too long: %v", cachedDuration)
 	}
 }
+
+func TestService_CreateReport_RecordsEvent(t *testing.T) {
+	mockRepo := &mockReportRepository{}
+	mockOutbox := &mockOutboxRepository{}
+	service := NewService(mockRepo, mockOutbox, mockTransactor{})
+
+	report, err := service.CreateReport(context.Background(), CreateReportRequest{
+		ReportName: "Event Report",
+		ReportType: primitive.NewObjectID().Hex(),
+		Year:       "2024",
+		Company:    primitive.NewObjectID().Hex(),
+		CreateBy:   primitive.NewObjectID().Hex(),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(mockOutbox.events) != 1 {
+		t.Fatalf("Expected 1 outbox event, got %d", len(mockOutbox.events))
+	}
+
+	event := mockOutbox.events[0]
+	if event.Type != domain.EventReportCreated {
+		t.Fatalf("Expected event type %s, got %s", domain.EventReportCreated, event.Type)
+	}
+	if event.AggregateID.Hex() != report.ID {
+		t.Fatalf("Expected aggregate ID %s, got %s", report.ID, event.AggregateID.Hex())
+	}
+	if event.Status != domain.EventStatusPending {
+		t.Fatalf("Expected pending event, got %s", event.Status)
+	}
+}
+
+func TestService_UpdateReport_RecordsAccessGrantedForNewUsersOnly(t *testing.T) {
+	existingUser := primitive.NewObjectID()
+	newUser := primitive.NewObjectID()
+
+	mockRepo := &mockReportRepository{
+		reports: []domain.PopulatedReport{
+			{
+				ID:         primitive.NewObjectID(),
+				ReportName: "Shared Report",
+				ReportType: &domain.ReportType{ID: primitive.NewObjectID()},
+				Company:    &domain.Company{ID: primitive.NewObjectID()},
+				CreatedBy:  &domain.User{ID: primitive.NewObjectID()},
+				UserAccess: []*domain.User{{ID: existingUser}},
+			},
+		},
+	}
+	mockOutbox := &mockOutboxRepository{}
+	service := NewService(mockRepo, mockOutbox, mockTransactor{})
+
+	ctx := context.WithValue(context.Background(), "user", &middleware.UserContext{UserID: primitive.NewObjectID().Hex(), Role: string(domain.RoleSuperAdmin)})
+	_, err := service.UpdateReport(ctx, mockRepo.reports[0].ID.Hex(), UpdateReportRequest{
+		UserAccess: []string{existingUser.Hex(), newUser.Hex(), newUser.Hex()},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(mockOutbox.events) != 2 {
+		t.Fatalf("Expected 2 outbox events, got %d", len(mockOutbox.events))
+	}
+	if mockOutbox.events[0].Type != domain.EventReportUpdated {
+		t.Fatalf("Expected event type %s, got %s", domain.EventReportUpdated, mockOutbox.events[0].Type)
+	}
+
+	event := mockOutbox.events[1]
+	if event.Type != domain.EventReportAccessGranted {
+		t.Fatalf("Expected event type %s, got %s", domain.EventReportAccessGranted, event.Type)
+	}
+
+	var payload ReportAccessGrantedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if len(payload.UserIDs) != 1 || payload.UserIDs[0] != newUser.Hex() {
+		t.Fatalf("Expected only %s to be granted, got %v", newUser.Hex(), payload.UserIDs)
+	}
+}
+
+func TestService_UpdateReport_EnforcesAccessLevel(t *testing.T) {
+	editor := primitive.NewObjectID()
+	viewer := primitive.NewObjectID()
+
+	mockRepo := &mockReportRepository{
+		reports: []domain.PopulatedReport{
+			{
+				ID:           primitive.NewObjectID(),
+				ReportName:   "Shared Report",
+				ReportType:   &domain.ReportType{ID: primitive.NewObjectID()},
+				Company:      &domain.Company{ID: primitive.NewObjectID()},
+				CreatedBy:    &domain.User{ID: primitive.NewObjectID()},
+				UserAccess:   []*domain.User{{ID: editor}, {ID: viewer}},
+				AccessLevels: []domain.ReportAccess{{User: editor, Level: domain.ReportAccessEdit}},
+			},
+		},
+	}
+	service := NewService(mockRepo, &mockOutboxRepository{}, mockTransactor{})
+	id := mockRepo.reports[0].ID.Hex()
+	name := "Renamed Report"
+
+	asUser := func(userID primitive.ObjectID) context.Context {
+		return context.WithValue(context.Background(), "user", &middleware.UserContext{UserID: userID.Hex(), Role: string(domain.RoleClient)})
+	}
+
+	if _, err := service.UpdateReport(asUser(editor), id, UpdateReportRequest{ReportName: &name}); err != nil {
+		t.Fatalf("Expected editor to update the report, got %v", err)
+	}
+	if _, err := service.UpdateReport(asUser(viewer), id, UpdateReportRequest{ReportName: &name}); err == nil {
+		t.Fatal("Expected viewer not to update the report")
+	}
+	if _, err := service.UpdateReport(asUser(editor), id, UpdateReportRequest{UserAccess: []string{editor.Hex()}}); err == nil {
+		t.Fatal("Expected editor not to change who has access")
+	}
+	if err := service.DeleteReport(asUser(editor), id); err == nil {
+		t.Fatal("Expected editor not to delete the report")
+	}
+}
+
+func TestService_UpdateReport_FlagsEditsToApprovedReports(t *testing.T) {
+	org := primitive.NewObjectID()
+	company := domain.Company{ID: primitive.NewObjectID(), Organization: &org}
+	viewer := primitive.NewObjectID()
+
+	mockRepo := &mockReportRepository{
+		reports: []domain.PopulatedReport{
+			{
+				ID:         primitive.NewObjectID(),
+				ReportName: "Approved Report",
+				ReportType: &domain.ReportType{ID: primitive.NewObjectID()},
+				Company:    &company,
+				CreatedBy:  &domain.User{ID: primitive.NewObjectID()},
+				Approval:   &domain.ReportApproval{By: primitive.NewObjectID(), At: time.Now()},
+			},
+		},
+	}
+	mockOutbox := &mockOutboxRepository{}
+	service := NewService(mockRepo, mockOutbox, mockTransactor{})
+	id := mockRepo.reports[0].ID.Hex()
+	name := "Restated Report"
+
+	ctx := context.WithValue(context.Background(), "user", &middleware.UserContext{UserID: primitive.NewObjectID().Hex(), Role: string(domain.RoleSuperAdmin)})
+	if _, err := service.UpdateReport(ctx, id, UpdateReportRequest{UserAccess: []string{viewer.Hex()}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.UpdateReport(ctx, id, UpdateReportRequest{ReportName: &name}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.ApproveReport(ctx, id); err != ErrReportAlreadyApproved {
+		t.Fatalf("Expected %v, got %v", ErrReportAlreadyApproved, err)
+	}
+
+	// The access change records report.updated and report.access_granted, the rename report.updated
+	if len(mockOutbox.events) != 3 {
+		t.Fatalf("Expected 3 outbox events, got %d", len(mockOutbox.events))
+	}
+	var accessChange, rename ReportChangedEvent
+	if err := json.Unmarshal(mockOutbox.events[0].Payload, &accessChange); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if err := json.Unmarshal(mockOutbox.events[2].Payload, &rename); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if accessChange.ApprovedEdit {
+		t.Fatal("Expected changing who has access not to be an approved edit")
+	}
+	if !rename.ApprovedEdit {
+		t.Fatal("Expected renaming an approved report to be an approved edit")
+	}
+
+	users := &mockUserRepository{listed: make(chan struct{}, 1)}
+	anomalies := &mockOutboxRepository{}
+	detector := NewAnomalyDetector(users, &mockCompanyRepository{companies: []domain.Company{company}}, anomalies, &mockNotifier{}, "", 0, time.Hour)
+	for _, event := range mockOutbox.events {
+		if err := detector.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if len(anomalies.events) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(anomalies.events))
+	}
+	var anomaly ReportAnomalyEvent
+	if err := json.Unmarshal(anomalies.events[0].Payload, &anomaly); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if anomaly.Kind != AnomalyApprovedEdit || anomaly.Organization != org.Hex() || anomaly.ReportIDs[0] != id {
+		t.Fatalf("Expected an approved edit of %s in %s, got %+v", id, org.Hex(), anomaly)
+	}
+}
+
+func TestAnomalyDetector_AlertsOnlyAdminsOfTheReportsOrganization(t *testing.T) {
+	orgA, orgB := primitive.NewObjectID(), primitive.NewObjectID()
+	companyA := domain.Company{ID: primitive.NewObjectID(), Organization: &orgA}
+	companyB := domain.Company{ID: primitive.NewObjectID(), Organization: &orgB}
+	actor := domain.User{ID: primitive.NewObjectID(), Name: "actor", Role: domain.RoleSuperAdmin}
+	outsider := domain.User{ID: primitive.NewObjectID(), Name: "outsider", Role: domain.RoleClient, Company: []primitive.ObjectID{companyB.ID}}
+	deactivatedAt := time.Now()
+
+	users := &mockUserRepository{
+		users: []domain.User{
+			actor,
+			outsider,
+			{ID: primitive.NewObjectID(), Name: "admin A", Role: domain.RoleAdmin, Organization: &orgA},
+			{ID: primitive.NewObjectID(), Name: "deactivated admin A", Role: domain.RoleAdmin, Organization: &orgA, DeactivatedAt: &deactivatedAt},
+			{ID: primitive.NewObjectID(), Name: "admin B", Role: domain.RoleAdmin, Organization: &orgB},
+			{ID: primitive.NewObjectID(), Name: "instance admin", Role: domain.RoleAdmin},
+		},
+		listed: make(chan struct{}, 1),
+	}
+	notifier := &mockNotifier{}
+	outbox := &mockOutboxRepository{}
+	detector := NewAnomalyDetector(users, &mockCompanyRepository{companies: []domain.Company{companyA, companyB}}, outbox, notifier, "", 2, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go detector.Run(ctx)
+
+	publish := func(eventType domain.EventType, payload interface{}) {
+		t.Helper()
+		event, err := domain.NewEvent(eventType, primitive.NewObjectID(), payload)
+		if err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		event.CreatedAt = time.Now()
+		if err := detector.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	waitForAlerts := func() []string {
+		t.Helper()
+		select {
+		case <-users.listed:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the admins to be alerted")
+		}
+		return notifier.takeAlerted()
+	}
+	expected := []string{"actor", "admin A"}
+
+	publish(domain.EventReportAccessGranted, ReportAccessGrantedEvent{
+		ReportID: primitive.NewObjectID().Hex(),
+		Company:  companyA.ID.Hex(),
+		UserIDs:  []string{outsider.ID.Hex()},
+		Actor:    actor.ID.Hex(),
+	})
+	if alerted := waitForAlerts(); !equalStrings(alerted, expected) {
+		t.Fatalf("Expected %v to be alerted of external access, got %v", expected, alerted)
+	}
+
+	// Deletions are counted per organization: one in each doesn't reach the threshold of 2
+	publish(domain.EventReportDeleted, ReportChangedEvent{ReportID: primitive.NewObjectID().Hex(), Company: companyA.ID.Hex(), Actor: actor.ID.Hex()})
+	publish(domain.EventReportDeleted, ReportChangedEvent{ReportID: primitive.NewObjectID().Hex(), Company: companyB.ID.Hex(), Actor: actor.ID.Hex()})
+	if len(outbox.events) != 1 {
+		t.Fatalf("Expected no mass deletion anomaly yet, got %d anomalies", len(outbox.events))
+	}
+
+	publish(domain.EventReportDeleted, ReportChangedEvent{ReportID: primitive.NewObjectID().Hex(), Company: companyA.ID.Hex(), Actor: actor.ID.Hex()})
+	if alerted := waitForAlerts(); !equalStrings(alerted, expected) {
+		t.Fatalf("Expected %v to be alerted of mass deletion, got %v", expected, alerted)
+	}
+
+	var anomaly ReportAnomalyEvent
+	if err := json.Unmarshal(outbox.events[1].Payload, &anomaly); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if anomaly.Kind != AnomalyMassDeletion || anomaly.Organization != orgA.Hex() || len(anomaly.ReportIDs) != 2 {
+		t.Fatalf("Expected a mass deletion of 2 reports of %s, got %+v", orgA.Hex(), anomaly)
+	}
+}
+
+func TestAnomalyDetector_IgnoresRedeliveredDeletions(t *testing.T) {
+	org := primitive.NewObjectID()
+	company := domain.Company{ID: primitive.NewObjectID(), Organization: &org}
+	actor := primitive.NewObjectID().Hex()
+	outbox := &mockOutboxRepository{}
+	detector := NewAnomalyDetector(&mockUserRepository{}, &mockCompanyRepository{companies: []domain.Company{company}}, outbox, &mockNotifier{}, "", 3, time.Hour)
+
+	deletions := make([]*domain.Event, 4)
+	for i := range deletions {
+		event, err := domain.NewEvent(domain.EventReportDeleted, primitive.NewObjectID(), ReportChangedEvent{ReportID: primitive.NewObjectID().Hex(), Company: company.ID.Hex(), Actor: actor})
+		if err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		event.ID = primitive.NewObjectID()
+		deletions[i] = event
+	}
+	publish := func(events ...*domain.Event) {
+		t.Helper()
+		for _, event := range events {
+			if err := detector.Publish(context.Background(), event); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+	}
+
+	// Two deletions, each delivered three times, stay below the threshold of 3
+	publish(deletions[0], deletions[0], deletions[1], deletions[0], deletions[1], deletions[1])
+	if len(outbox.events) != 0 {
+		t.Fatalf("Expected redeliveries not to count, got %d anomalies", len(outbox.events))
+	}
+
+	publish(deletions[2])
+	if len(outbox.events) != 1 {
+		t.Fatalf("Expected the third deletion to raise an anomaly, got %d anomalies", len(outbox.events))
+	}
+
+	// Deletions already reported don't count towards the next anomaly, redelivered or not
+	publish(deletions[0], deletions[1], deletions[2], deletions[3], deletions[2])
+	if len(outbox.events) != 1 {
+		t.Fatalf("Expected no further anomaly, got %d anomalies", len(outbox.events))
+	}
+	var anomaly ReportAnomalyEvent
+	if err := json.Unmarshal(outbox.events[0].Payload, &anomaly); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if len(anomaly.ReportIDs) != 3 {
+		t.Fatalf("Expected 3 deleted reports, got %v", anomaly.ReportIDs)
+	}
+}
+
+func TestAnomalyDetector_SendsQueuedAlertsWhenStopped(t *testing.T) {
+	org := primitive.NewObjectID()
+	company := domain.Company{ID: primitive.NewObjectID(), Organization: &org}
+	users := &mockUserRepository{
+		users:  []domain.User{{ID: primitive.NewObjectID(), Name: "admin", Role: domain.RoleAdmin, Organization: &org}},
+		listed: make(chan struct{}, 1),
+	}
+	notifier := &mockNotifier{}
+	detector := NewAnomalyDetector(users, &mockCompanyRepository{companies: []domain.Company{company}}, &mockOutboxRepository{}, notifier, "", 1, time.Hour)
+
+	event, err := domain.NewEvent(domain.EventReportDeleted, primitive.NewObjectID(), ReportChangedEvent{ReportID: primitive.NewObjectID().Hex(), Company: company.ID.Hex(), Actor: primitive.NewObjectID().Hex()})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := detector.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if alerted := notifier.takeAlerted(); len(alerted) != 0 {
+		t.Fatalf("Expected alerts to wait for Run, got %v", alerted)
+	}
+
+	// Stopped before it ever ran, Run still sends what was queued and returns
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	detector.Run(ctx)
+	if alerted := notifier.takeAlerted(); !equalStrings(alerted, []string{"admin"}) {
+		t.Fatalf("Expected the admin to be alerted, got %v", alerted)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
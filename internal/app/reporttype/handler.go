@@ -2,10 +2,12 @@ package reporttype
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 
+	"finsolvz-backend/internal/platform/http/middleware"
 	"finsolvz-backend/internal/utils"
 )
 
@@ -22,17 +24,24 @@ func NewHandler(service Service) *Handler {
 }
 
 // RegisterRoutes registers report type routes
+// @Tags Report Types
 func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
 	protected := router.PathPrefix("").Subrouter()
 	protected.Use(authMiddleware)
 
-	protected.HandleFunc("/api/reportTypes", h.GetReportTypes).Methods("GET")
-	protected.HandleFunc("/api/reportTypes", h.CreateReportType).Methods("POST")
-	protected.HandleFunc("/api/reportTypes/{id}", h.UpdateReportType).Methods("PUT")
-	protected.HandleFunc("/api/reportTypes/{id}", h.DeleteReportType).Methods("DELETE")
+	protected.Handle("/api/reportTypes", middleware.CacheResponses(middleware.ResponseCacheReportTypes, 5*time.Minute)(
+		http.HandlerFunc(h.GetReportTypes))).Methods("GET")
 	protected.HandleFunc("/api/reportTypes/{idOrName}", h.GetReportTypeByIDOrName).Methods("GET")
+
+	manage := protected.PathPrefix("").Subrouter()
+	manage.Use(middleware.RequirePermission("manage", "reporttype"))
+
+	manage.HandleFunc("/api/reportTypes", h.CreateReportType).Methods("POST")
+	manage.HandleFunc("/api/reportTypes/{id}", h.UpdateReportType).Methods("PUT")
+	manage.HandleFunc("/api/reportTypes/{id}", h.DeleteReportType).Methods("DELETE")
 }
 
+// @Summary Get all report types
 func (h *Handler) GetReportTypes(w http.ResponseWriter, r *http.Request) {
 	reportTypes, err := h.service.GetReportTypes(r.Context())
 	if err != nil {
@@ -66,6 +75,7 @@ func (h *Handler) GetReportTypeByIDOrName(w http.ResponseWriter, r *http.Request
 	utils.RespondJSON(w, http.StatusOK, reportType)
 }
 
+// @Summary Create new report type
 func (h *Handler) CreateReportType(w http.ResponseWriter, r *http.Request) {
 	var req CreateReportTypeRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -90,6 +100,7 @@ func (h *Handler) CreateReportType(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Update report type
 func (h *Handler) UpdateReportType(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -117,6 +128,7 @@ func (h *Handler) UpdateReportType(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Delete report type
 func (h *Handler) DeleteReportType(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
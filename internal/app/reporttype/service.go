@@ -7,6 +7,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
 	"finsolvz-backend/internal/utils/errors"
 )
 
@@ -47,6 +48,7 @@ func (s *service) CreateReportType(ctx context.Context, req CreateReportTypeRequ
 	if err := s.reportTypeRepo.Create(ctx, reportType); err != nil {
 		return nil, err
 	}
+	middleware.InvalidateResponses(middleware.ResponseCacheReportTypes)
 
 	response := ToReportTypeResponse(reportType)
 	return &response, nil
@@ -126,6 +128,7 @@ func (s *service) UpdateReportType(ctx context.Context, id string, req UpdateRep
 	if err := s.reportTypeRepo.Update(ctx, objectID, reportType); err != nil {
 		return nil, err
 	}
+	invalidateResponses()
 
 	response := ToReportTypeResponse(reportType)
 	return &response, nil
@@ -142,5 +145,15 @@ func (s *service) DeleteReportType(ctx context.Context, id string) error {
 		return err
 	}
 
-	return s.reportTypeRepo.Delete(ctx, objectID)
+	if err := s.reportTypeRepo.Delete(ctx, objectID); err != nil {
+		return err
+	}
+	invalidateResponses()
+	return nil
+}
+
+// invalidateResponses drops the cached responses rendering report types, including the
+// reports populated with them
+func invalidateResponses() {
+	middleware.InvalidateResponses(middleware.ResponseCacheReportTypes, middleware.ResponseCacheReports)
 }
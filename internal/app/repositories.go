@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"finsolvz-backend/internal/app/system"
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/diagnostics"
+	"finsolvz-backend/internal/platform/metrics"
+	searchbackend "finsolvz-backend/internal/platform/search"
+	"finsolvz-backend/internal/repository"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// repositories are the stores of the configured driver. Those only MongoDB implements stay
+// nil on Postgres, and the features built on them are left out.
+type repositories struct {
+	user         domain.UserRepository
+	reportType   domain.ReportTypeRepository
+	company      domain.CompanyRepository
+	report       domain.ReportRepository
+	outbox       domain.OutboxRepository
+	token        domain.SecurityTokenRepository
+	transactor   domain.Transactor
+	backup       domain.BackupRepository
+	integrity    domain.IntegrityRepository
+	webhook      domain.WebhookRepository
+	delivery     domain.WebhookDeliveryRepository
+	task         domain.TaskRepository
+	login        domain.LoginRepository
+	session      domain.SessionRepository
+	retention    domain.RetentionRepository
+	summary      domain.ReportSummaryRepository
+	organization domain.OrganizationRepository
+	activity     domain.ActivityRepository
+	export       domain.ExportRepository
+	deadline     domain.DeadlineRepository
+	digest       domain.DigestRepository
+	ledger       domain.LedgerRepository
+	template     domain.ReportTemplateRepository
+	kpi          domain.KPIRepository
+	budget       domain.BudgetRepository
+	insight      domain.InsightRepository
+	rate         domain.RateRepository
+	taxRate      domain.TaxRateRepository
+	apiKey       domain.APIKeyRepository
+	search       domain.SearchRepository
+	// searchFallback answers searches when the search backend fails
+	searchFallback domain.SearchRepository
+	warehouse      domain.WarehouseRepository
+	sandbox        domain.SandboxRepository
+	sso            domain.SSOConnectionRepository
+	invitation     domain.InvitationRepository
+}
+
+// connect opens the configured database, migrating Postgres, and builds its repositories. It
+// registers the database's diagnostics, statistics and connection close.
+func (a *App) connect(ctx context.Context) error {
+	searchIndex, err := searchbackend.New(a.cfg.Search)
+	if err != nil {
+		return fmt.Errorf("failed to configure search: %w", err)
+	}
+
+	r := &a.repos
+	switch a.cfg.Database.Driver {
+	case config.DriverPostgres:
+		pg, err := config.ConnectPostgres(ctx, a.cfg.Database.PostgresDSN)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		a.onStop(func() { pg.Close() })
+
+		if err := repository.MigratePostgres(ctx, pg); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+
+		r.user = repository.NewUserPostgresRepository(pg)
+		r.reportType = repository.NewReportTypePostgresRepository(pg)
+		r.company = repository.NewCompanyPostgresRepository(pg)
+		r.report = repository.NewReportPostgresRepository(pg)
+		r.outbox = repository.NewOutboxPostgresRepository(pg)
+		r.token = repository.NewSecurityTokenPostgresRepository(pg)
+		r.transactor = repository.NewPostgresTransactor(pg)
+		r.integrity = repository.NewIntegrityPostgresRepository(pg)
+		r.digest = repository.NewDigestPostgresRepository(pg)
+		a.databaseStats = system.PostgresStats(pg)
+
+		a.diagnosticChecks = append(a.diagnosticChecks, diagnostics.Check{
+			Name: "database",
+			Run: func(ctx context.Context) (string, error) {
+				if err := pg.PingContext(ctx); err != nil {
+					return "", err
+				}
+				return "postgres reachable", nil
+			},
+		})
+	default:
+		mongoMetrics := metrics.NewMongoCollector()
+		a.metricCollectors = append(a.metricCollectors, mongoMetrics)
+
+		db, err := config.ConnectMongoDB(ctx, a.cfg.Database.MongoURI, options.Client().
+			SetMonitor(mongoMetrics.CommandMonitor()).
+			SetPoolMonitor(mongoMetrics.PoolMonitor()))
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		a.onStop(func() { db.Client().Disconnect(context.Background()) })
+
+		r.user = repository.NewUserMongoRepository(db)
+		r.reportType = repository.NewReportTypeMongoRepository(db)
+		r.company = repository.NewCompanyMongoRepository(db)
+		r.summary = repository.NewReportSummaryMongoRepository(db)
+		r.report = repository.NewCachedReportRepository(repository.NewReportMongoRepository(db, a.cfg.Database.ReportReadPreference), db, a.repoCache, repoCacheTTL)
+		r.report = repository.NewSummarizingReportRepository(r.report, db, r.summary)
+		a.goWorker(func(ctx context.Context) {
+			repository.WatchReportListCache(ctx, db, a.repoCache)
+		})
+		a.goWorker(func(ctx context.Context) {
+			repository.WatchOutbox(ctx, db, a.realtimeHub)
+		})
+		r.outbox = repository.NewOutboxMongoRepository(db)
+		r.token = repository.NewSecurityTokenMongoRepository(db)
+		supportsTx := config.SupportsTransactions(ctx, db)
+		r.transactor = repository.NewMongoTransactor(db.Client(), supportsTx)
+		r.backup = repository.NewBackupMongoRepository(db)
+		r.integrity = repository.NewIntegrityMongoRepository(db)
+		r.webhook = repository.NewWebhookMongoRepository(db)
+		r.delivery = repository.NewWebhookDeliveryMongoRepository(db)
+		r.task = repository.NewTaskMongoRepository(db)
+		r.login = repository.NewLoginMongoRepository(db)
+		r.session = repository.NewSessionMongoRepository(db)
+		r.retention = repository.NewRetentionMongoRepository(db)
+		r.sso = repository.NewSSOConnectionMongoRepository(db)
+		r.invitation = repository.NewInvitationMongoRepository(db)
+		r.organization = repository.NewOrganizationMongoRepository(db)
+		r.activity = repository.NewActivityMongoRepository(db)
+		r.export = repository.NewExportMongoRepository(db)
+		r.deadline = repository.NewDeadlineMongoRepository(db)
+		r.digest = repository.NewDigestMongoRepository(db)
+		r.ledger = repository.NewLedgerMongoRepository(db)
+		r.template = repository.NewReportTemplateMongoRepository(db)
+		r.kpi = repository.NewKPIMongoRepository(db)
+		r.budget = repository.NewBudgetMongoRepository(db)
+		r.insight = repository.NewInsightMongoRepository(db)
+		r.rate = repository.NewRateMongoRepository(db)
+		r.taxRate = repository.NewTaxRateMongoRepository(db)
+		r.apiKey = repository.NewAPIKeyMongoRepository(db)
+		r.warehouse = repository.NewWarehouseMongoRepository(db)
+
+		// Searches go to the configured backend, and to regular expressions when it fails
+		r.search = repository.NewSearchMongoRepository(db, "")
+		if searchIndex != nil {
+			r.search, r.searchFallback = searchIndex, r.search
+			a.goWorker(func(ctx context.Context) {
+				repository.SyncSearchIndex(ctx, db, searchIndex)
+			})
+		} else if strings.EqualFold(a.cfg.Search.Backend, searchbackend.BackendAtlas) {
+			r.search, r.searchFallback = repository.NewSearchMongoRepository(db, a.cfg.Search.AtlasIndex), r.search
+		}
+		a.databaseStats = system.MongoStats(db, mongoMetrics)
+
+		// The sandbox is a second database on the same cluster. Its repositories skip the
+		// repository cache, which is keyed by ID alone.
+		if a.cfg.Sandbox.Enabled {
+			sdb := db.Client().Database(a.cfg.Sandbox.Database)
+			r.sandbox = repository.NewSandboxMongoRepository(db, sdb)
+			a.sandboxRepos = repositories{
+				user:       repository.NewUserMongoRepository(sdb),
+				reportType: repository.NewReportTypeMongoRepository(sdb),
+				company:    repository.NewCompanyMongoRepository(sdb),
+				report:     repository.NewReportMongoRepository(sdb, a.cfg.Database.ReportReadPreference),
+				outbox:     repository.NewOutboxMongoRepository(sdb),
+				transactor: repository.NewMongoTransactor(db.Client(), supportsTx),
+			}
+		}
+
+		a.diagnosticChecks = append(a.diagnosticChecks, diagnostics.Check{
+			Name: "database",
+			Run: func(ctx context.Context) (string, error) {
+				if err := db.Client().Ping(ctx, readpref.Primary()); err != nil {
+					return "", err
+				}
+				return "mongo primary reachable", nil
+			},
+		}, diagnostics.Check{
+			Name: "indexes",
+			Run: func(ctx context.Context) (string, error) {
+				missing, err := config.MissingIndexes(ctx, db)
+				if err != nil {
+					return "", err
+				}
+				if len(missing) > 0 {
+					return "", diagnostics.Warning("missing indexes %v; run finsolvzctl reindex", missing)
+				}
+				return "all expected indexes exist", nil
+			},
+		})
+	}
+
+	r.user = repository.NewCachedUserRepository(r.user, a.repoCache, repoCacheTTL)
+	r.reportType = repository.NewCachedReportTypeRepository(r.reportType, a.repoCache, repoCacheTTL)
+	r.company = repository.NewCachedCompanyRepository(r.company, a.repoCache, repoCacheTTL)
+	r.integrity = repository.NewCachedIntegrityRepository(r.integrity, a.repoCache)
+	if r.organization != nil {
+		r.organization = repository.NewCachedOrganizationRepository(r.organization, a.repoCache, repoCacheTTL)
+	}
+
+	if disabled := r.disabledFeatures(); len(disabled) > 0 {
+		log.Warnf(ctx, "The %s database has no store for these features, which are disabled: %s",
+			a.cfg.Database.Driver, strings.Join(disabled, ", "))
+	}
+	return nil
+}
+
+// disabledFeatures names the features left out because their repository is nil.
+func (r *repositories) disabledFeatures() []string {
+	features := []struct {
+		name    string
+		missing bool
+	}{
+		{"organizations", r.organization == nil},
+		{"sessions", r.session == nil},
+		{"login history and suspicious login alerts", r.login == nil},
+		{"single sign-on", r.sso == nil},
+		{"invitations", r.invitation == nil},
+		{"API keys", r.apiKey == nil},
+		{"webhooks", r.webhook == nil},
+		{"background tasks and exports", r.task == nil},
+		{"backups", r.backup == nil},
+		{"retention", r.retention == nil},
+		{"activity feed", r.activity == nil},
+		{"deadlines", r.deadline == nil},
+		{"ledger", r.ledger == nil},
+		{"report templates", r.template == nil},
+		{"KPIs", r.kpi == nil},
+		{"budgets", r.budget == nil},
+		{"insights", r.insight == nil},
+		{"exchange rates", r.rate == nil},
+		{"tax rates", r.taxRate == nil},
+		{"dashboard", r.summary == nil},
+		{"search", r.search == nil},
+		{"data warehouse sync", r.warehouse == nil},
+	}
+
+	var disabled []string
+	for _, feature := range features {
+		if feature.missing {
+			disabled = append(disabled, feature.name)
+		}
+	}
+	return disabled
+}
@@ -0,0 +1,10 @@
+package retention
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrNoReport = errors.New("RETENTION_REPORT_NOT_FOUND", "No retention purge has run yet", http.StatusNotFound, nil, nil)
+)
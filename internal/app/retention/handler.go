@@ -0,0 +1,110 @@
+package retention
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers data retention routes
+// @Tags Administration
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "retention"))
+
+	adminOnly.HandleFunc("/api/admin/retention", h.GetPolicies).Methods("GET")
+	adminOnly.HandleFunc("/api/admin/retention/companies/{id}", h.SetCompanyRetention).Methods("PUT")
+	adminOnly.HandleFunc("/api/admin/retention/companies/{id}", h.DeleteCompanyRetention).Methods("DELETE")
+	adminOnly.HandleFunc("/api/admin/retention/purge", h.Purge).Methods("POST")
+	adminOnly.HandleFunc("/api/admin/retention/report", h.GetLastReport).Methods("GET")
+}
+
+// GetPolicies returns the default retention policy and the company overrides
+func (h *Handler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.service.GetPolicies(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, policies)
+}
+
+// SetCompanyRetention overrides how long a company's trashed reports and its users' logins are kept
+func (h *Handler) SetCompanyRetention(w http.ResponseWriter, r *http.Request) {
+	var req CompanyRetentionRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	retention, err := h.service.SetCompanyRetention(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, retention)
+}
+
+// DeleteCompanyRetention makes a company use the default retention policy again
+func (h *Handler) DeleteCompanyRetention(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteCompanyRetention(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Purge removes expired data now, or with dryRun only reports what would be removed
+func (h *Handler) Purge(w http.ResponseWriter, r *http.Request) {
+	var req PurgeRequest
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+	}
+
+	report, err := h.service.Purge(r.Context(), req.DryRun)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, report)
+}
+
+// GetLastReport returns the result of the most recent manual or scheduled purge
+func (h *Handler) GetLastReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.LastReport(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,54 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Job purges expired data on a fixed interval.
+type Job struct {
+	service  Service
+	interval time.Duration
+	dryRun   bool
+}
+
+func NewJob(service Service, interval time.Duration, dryRun bool) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+		dryRun:   dryRun,
+	}
+}
+
+// Run purges until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := j.service.Purge(ctx, j.dryRun)
+			if err != nil {
+				log.Errorf(ctx, "Retention: purge failed: %v", err)
+				continue
+			}
+
+			verb := "purged"
+			if report.DryRun {
+				verb = "would purge"
+			}
+			log.Infof(ctx, "Retention: %s %d audit logs, %d trashed reports and %d auth events", verb,
+				report.Counts[KindAuditLogs], report.Counts[KindTrashedReports], report.Counts[KindAuthEvents])
+			for _, result := range report.Results {
+				if result.Error != "" {
+					log.Errorf(ctx, "Retention: purging %s (%s) failed: %s", result.Kind, result.Scope, result.Error)
+				}
+			}
+		}
+	}
+}
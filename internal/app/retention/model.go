@@ -0,0 +1,48 @@
+package retention
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Data kinds a purge covers
+const (
+	KindAuditLogs      = "auditLogs"
+	KindTrashedReports = "trashedReports"
+	KindAuthEvents     = "authEvents"
+)
+
+// Request DTOs
+type CompanyRetentionRequest struct {
+	AuditLogYears     int `json:"auditLogYears" validate:"min=0"`
+	TrashedReportDays int `json:"trashedReportDays" validate:"min=0"`
+	AuthEventMonths   int `json:"authEventMonths" validate:"min=0"`
+}
+
+type PurgeRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+// Response DTOs
+type PoliciesResponse struct {
+	Default   domain.RetentionPolicy     `json:"default"`
+	Companies []*domain.CompanyRetention `json:"companies"`
+}
+
+type PurgeResult struct {
+	Kind    string    `json:"kind"`
+	Scope   string    `json:"scope"` // "default", the company of an override, or "override" for users of overriding companies
+	Cutoff  time.Time `json:"cutoff"`
+	Matched int64     `json:"matched"` // purged, or that would be purged on a dry run
+	Error   string    `json:"error,omitempty"`
+}
+
+type PurgeReport struct {
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+	DryRun     bool             `json:"dryRun"`
+	Counts     map[string]int64 `json:"counts"` // matched per kind
+	Total      int64            `json:"total"`
+	Results    []PurgeResult    `json:"results"`
+}
@@ -0,0 +1,229 @@
+package retention
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type Service interface {
+	GetPolicies(ctx context.Context) (*PoliciesResponse, error)
+	SetCompanyRetention(ctx context.Context, companyID string, req CompanyRetentionRequest) (*domain.CompanyRetention, error)
+	DeleteCompanyRetention(ctx context.Context, companyID string) error
+	Purge(ctx context.Context, dryRun bool) (*PurgeReport, error)
+	LastReport(ctx context.Context) (*PurgeReport, error)
+}
+
+type service struct {
+	retentionRepo domain.RetentionRepository
+	companyRepo   domain.CompanyRepository
+	userRepo      domain.UserRepository
+	defaults      domain.RetentionPolicy
+
+	mu   sync.RWMutex
+	last *PurgeReport
+}
+
+func NewService(retentionRepo domain.RetentionRepository, companyRepo domain.CompanyRepository, userRepo domain.UserRepository, defaults domain.RetentionPolicy) Service {
+	return &service{
+		retentionRepo: retentionRepo,
+		companyRepo:   companyRepo,
+		userRepo:      userRepo,
+		defaults:      defaults,
+	}
+}
+
+func (s *service) GetPolicies(ctx context.Context) (*PoliciesResponse, error) {
+	companies, err := s.retentionRepo.GetCompanyRetentions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &PoliciesResponse{Default: s.defaults, Companies: companies}, nil
+}
+
+func (s *service) SetCompanyRetention(ctx context.Context, companyID string, req CompanyRetentionRequest) (*domain.CompanyRetention, error) {
+	id, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return nil, errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+	if _, err := s.companyRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	retention := &domain.CompanyRetention{
+		CompanyID:         id,
+		AuditLogYears:     req.AuditLogYears,
+		TrashedReportDays: req.TrashedReportDays,
+		AuthEventMonths:   req.AuthEventMonths,
+	}
+	if err := s.retentionRepo.SaveCompanyRetention(ctx, retention); err != nil {
+		return nil, err
+	}
+	return retention, nil
+}
+
+func (s *service) DeleteCompanyRetention(ctx context.Context, companyID string) error {
+	id, err := primitive.ObjectIDFromHex(companyID)
+	if err != nil {
+		return errors.New("INVALID_COMPANY_ID", "Invalid company ID format", 400, err, nil)
+	}
+	return s.retentionRepo.DeleteCompanyRetention(ctx, id)
+}
+
+// Purge removes the data each policy no longer keeps, or with dryRun only counts it. A failing
+// purge is recorded in its result and doesn't stop the others.
+func (s *service) Purge(ctx context.Context, dryRun bool) (*PurgeReport, error) {
+	overrides, err := s.retentionRepo.GetCompanyRetentions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &PurgeReport{
+		StartedAt: now,
+		DryRun:    dryRun,
+		Counts:    map[string]int64{},
+		Results:   []PurgeResult{},
+	}
+	record := func(kind, scope string, cutoff time.Time, matched int64, err error) {
+		result := PurgeResult{Kind: kind, Scope: scope, Cutoff: cutoff, Matched: matched}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		report.Counts[kind] += matched
+		report.Total += matched
+		report.Results = append(report.Results, result)
+	}
+
+	// Audit log: per company override, then the default for every other company and the
+	// events of none
+	var overridden []primitive.ObjectID
+	for _, override := range overrides {
+		if override.AuditLogYears == 0 {
+			continue
+		}
+		overridden = append(overridden, override.CompanyID)
+		cutoff := now.AddDate(-override.AuditLogYears, 0, 0)
+		matched, err := s.retentionRepo.PurgeEvents(ctx, cutoff, domain.PurgeScope{Only: []primitive.ObjectID{override.CompanyID}}, dryRun)
+		record(KindAuditLogs, override.CompanyID.Hex(), cutoff, matched, err)
+	}
+	if years := s.defaults.AuditLogYears; years > 0 {
+		cutoff := now.AddDate(-years, 0, 0)
+		matched, err := s.retentionRepo.PurgeEvents(ctx, cutoff, domain.PurgeScope{Except: overridden}, dryRun)
+		record(KindAuditLogs, "default", cutoff, matched, err)
+	}
+
+	// Trashed reports: per company override, then the default for every other company
+	overridden = nil
+	for _, override := range overrides {
+		if override.TrashedReportDays == 0 {
+			continue
+		}
+		overridden = append(overridden, override.CompanyID)
+		cutoff := now.AddDate(0, 0, -override.TrashedReportDays)
+		matched, err := s.retentionRepo.PurgeTrashedReports(ctx, cutoff, domain.PurgeScope{Only: []primitive.ObjectID{override.CompanyID}}, dryRun)
+		record(KindTrashedReports, override.CompanyID.Hex(), cutoff, matched, err)
+	}
+	if days := s.defaults.TrashedReportDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+		matched, err := s.retentionRepo.PurgeTrashedReports(ctx, cutoff, domain.PurgeScope{Except: overridden}, dryRun)
+		record(KindTrashedReports, "default", cutoff, matched, err)
+	}
+
+	// Logins: users of overriding companies are grouped by their retention, the rest use the default
+	groups, err := s.loginGroups(ctx, overrides)
+	if err != nil {
+		record(KindAuthEvents, "default", time.Time{}, 0, err)
+	} else {
+		var grouped []primitive.ObjectID
+		months := make([]int, 0, len(groups))
+		for m, users := range groups {
+			grouped = append(grouped, users...)
+			months = append(months, m)
+		}
+		sort.Ints(months)
+
+		for _, m := range months {
+			if m == 0 {
+				continue // kept forever
+			}
+			cutoff := now.AddDate(0, -m, 0)
+			matched, err := s.retentionRepo.PurgeLogins(ctx, cutoff, domain.PurgeScope{Only: groups[m]}, dryRun)
+			record(KindAuthEvents, "override", cutoff, matched, err)
+		}
+		if m := s.defaults.AuthEventMonths; m > 0 {
+			cutoff := now.AddDate(0, -m, 0)
+			matched, err := s.retentionRepo.PurgeLogins(ctx, cutoff, domain.PurgeScope{Except: grouped}, dryRun)
+			record(KindAuthEvents, "default", cutoff, matched, err)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+// loginGroups groups the users of companies overriding the login retention by the months
+// their logins are kept: the longest of their companies, as one company's shorter retention
+// mustn't cut another's short. Zero months means forever and wins over any other.
+func (s *service) loginGroups(ctx context.Context, overrides []*domain.CompanyRetention) (map[int][]primitive.ObjectID, error) {
+	months := map[primitive.ObjectID]int{}
+	for _, override := range overrides {
+		if override.AuthEventMonths > 0 {
+			months[override.CompanyID] = override.AuthEventMonths
+		}
+	}
+	groups := map[int][]primitive.ObjectID{}
+	if len(months) == 0 {
+		return groups, nil
+	}
+
+	err := s.userRepo.Each(ctx, func(user *domain.User) error {
+		overridden := false
+		for _, companyID := range user.Company {
+			if _, ok := months[companyID]; ok {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			return nil
+		}
+
+		keep := -1
+		for _, companyID := range user.Company {
+			m, ok := months[companyID]
+			if !ok {
+				m = s.defaults.AuthEventMonths
+			}
+			if m == 0 || keep == 0 {
+				keep = 0
+			} else if m > keep {
+				keep = m
+			}
+		}
+		groups[keep] = append(groups[keep], user.ID)
+		return nil
+	})
+	return groups, err
+}
+
+func (s *service) LastReport(ctx context.Context) (*PurgeReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.last == nil {
+		return nil, ErrNoReport
+	}
+	return s.last, nil
+}
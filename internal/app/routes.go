@@ -0,0 +1,517 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+
+	"finsolvz-backend/api"
+	"finsolvz-backend/internal/app/activity"
+	"finsolvz-backend/internal/app/apikey"
+	"finsolvz-backend/internal/app/auth"
+	"finsolvz-backend/internal/app/backup"
+	"finsolvz-backend/internal/app/budget"
+	"finsolvz-backend/internal/app/company"
+	"finsolvz-backend/internal/app/dashboard"
+	"finsolvz-backend/internal/app/deadline"
+	"finsolvz-backend/internal/app/email"
+	"finsolvz-backend/internal/app/export"
+	"finsolvz-backend/internal/app/graph"
+	"finsolvz-backend/internal/app/insight"
+	"finsolvz-backend/internal/app/integrity"
+	"finsolvz-backend/internal/app/invitation"
+	"finsolvz-backend/internal/app/kpi"
+	"finsolvz-backend/internal/app/ledger"
+	"finsolvz-backend/internal/app/legal"
+	"finsolvz-backend/internal/app/organization"
+	"finsolvz-backend/internal/app/rate"
+	"finsolvz-backend/internal/app/realtime"
+	"finsolvz-backend/internal/app/report"
+	"finsolvz-backend/internal/app/reporttype"
+	"finsolvz-backend/internal/app/retention"
+	"finsolvz-backend/internal/app/sandbox"
+	"finsolvz-backend/internal/app/search"
+	"finsolvz-backend/internal/app/system"
+	"finsolvz-backend/internal/app/task"
+	"finsolvz-backend/internal/app/tax"
+	"finsolvz-backend/internal/app/template"
+	"finsolvz-backend/internal/app/user"
+	"finsolvz-backend/internal/app/warehouse"
+	"finsolvz-backend/internal/app/webhook"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/metrics"
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// routes builds the router of every feature, behind the global middlewares and CORS.
+func (a *App) routes(ctx context.Context) (http.Handler, error) {
+	cfg, r := a.cfg, &a.repos
+
+	router := mux.NewRouter()
+
+	router.Use(middleware.LoggingMiddleware)
+	router.Use(a.httpMetrics.Middleware)
+	router.Use(middleware.RecoveryMiddleware)
+	router.Use(middleware.CompressionMiddleware)
+	router.Use(middleware.RequestLimitMiddleware)
+	router.Use(middleware.RateLimitMiddleware(a.rateLimitCache, cfg.RateLimitPerMinute))
+	router.Use(middleware.CSRFMiddleware)
+
+	c := cors.New(cors.Options{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"X-Total-Count", "Link", utils.RenewedTokenHeader},
+		AllowCredentials: true,
+	})
+
+	auth.NewHandler(a.authService, a.oauthService, a.ssoService, a.sessions, a.loginMonitor).RegisterRoutes(router, middleware.AuthMiddleware)
+	legal.NewHandler(a.legalService).RegisterRoutes(router, middleware.AuthMiddlewareWithoutConsent)
+	user.NewHandler(a.userService, a.authService).RegisterRoutes(router, middleware.AuthMiddleware)
+	reporttype.NewHandler(a.reportTypeService).RegisterRoutes(router, middleware.AuthMiddleware)
+	company.NewHandler(a.companyService).RegisterRoutes(router, middleware.AuthMiddleware)
+	report.NewHandler(a.reportService).RegisterRoutes(router, middleware.AuthMiddleware)
+	graph.NewHandler(graph.NewService(r.user, r.company, r.report, r.reportType)).RegisterRoutes(router, middleware.AuthMiddleware)
+	realtime.NewHandler(realtime.NewService(r.user), a.realtimeHub, cfg.CORSAllowedOrigins).RegisterRoutes(router)
+	integrity.NewHandler(a.integrityService).RegisterRoutes(router, middleware.AuthMiddleware)
+	system.NewHandler(system.NewService(system.Sources{
+		Driver:   cfg.Database.Driver,
+		Database: a.databaseStats,
+		Caches: map[string]utils.Cache{
+			"repository": a.repoCache,
+			"service":    utils.GetCache(),
+		},
+		Outbox:     r.outbox,
+		Tasks:      r.task,
+		Deliveries: r.delivery,
+		Backups:    a.backupService,
+		Requests:   a.httpMetrics,
+	})).RegisterRoutes(router, middleware.AuthMiddleware)
+	email.NewHandler(email.NewService(utils.NewEmailTemplates(cfg.Email.TemplateDir))).RegisterRoutes(router, middleware.AuthMiddleware)
+
+	// Backups export Mongo collections, so they are only available on the Mongo driver
+	if a.backupService != nil {
+		backup.NewHandler(a.backupService, a.taskQueue).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if a.taskQueue != nil {
+		task.NewHandler(task.NewService(r.task)).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// Report deadlines are stored in Mongo as well
+	if a.deadlineService != nil {
+		deadline.NewHandler(a.deadlineService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// Charts of accounts and trial balances too
+	if r.ledger != nil {
+		ledgerService := ledger.NewService(r.ledger, r.company, r.reportType, a.reportService)
+		ledger.NewHandler(ledgerService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if r.template != nil {
+		templateService := template.NewService(r.template, r.ledger, r.company, r.reportType)
+		template.NewHandler(templateService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if a.kpiService != nil {
+		kpi.NewHandler(a.kpiService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if r.budget != nil {
+		budgetService := budget.NewService(r.budget, r.report, r.company, r.reportType)
+		budget.NewHandler(budgetService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if a.rateService != nil {
+		rate.NewHandler(a.rateService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if r.taxRate != nil {
+		taxService := tax.NewService(r.taxRate, r.report, r.company, r.reportType)
+		tax.NewHandler(taxService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if r.search != nil {
+		searchService := search.NewService(r.search, r.searchFallback, r.company, r.reportType)
+		search.NewHandler(searchService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// API keys authenticate as their users, within limits counted in the shared rate limit cache
+	if r.apiKey != nil {
+		apiKeyService := apikey.NewService(r.apiKey, r.user, a.rateLimitCache, apikey.Limits{
+			RateLimitPerMinute: cfg.APIKeys.RateLimitPerMinute,
+			DailyQuota:         cfg.APIKeys.DailyQuota,
+		})
+		middleware.SetAPIKeyAuth(apiKeyService.Authenticate)
+		apikey.NewHandler(apiKeyService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// AI insights answer 503 until AI_PROVIDER is set
+	if r.insight != nil {
+		insightService := insight.NewService(r.insight, r.report, a.model)
+		insight.NewHandler(insightService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// Report exports are rendered by background tasks
+	if a.exportService != nil {
+		export.NewHandler(a.exportService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// Webhook subscriptions are stored in Mongo as well
+	if r.webhook != nil {
+		webhook.NewHandler(webhook.NewService(r.webhook, r.delivery)).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// So are the retention overrides and the logins the purge covers
+	if a.retentionService != nil {
+		retention.NewHandler(a.retentionService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	if a.warehouseService != nil {
+		warehouse.NewHandler(a.warehouseService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// And the report summaries of the dashboard
+	if r.summary != nil {
+		dashboard.NewHandler(dashboard.NewService(r.summary, r.company, r.reportType)).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// And the activity feed
+	if r.activity != nil {
+		activity.NewHandler(activity.NewService(r.activity, r.company, r.user)).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// And the invitations of pending users
+	if a.invitationService != nil {
+		invitation.NewHandler(a.invitationService).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// And the organizations
+	if r.organization != nil {
+		organization.NewHandler(organization.NewService(r.organization, r.user)).RegisterRoutes(router, middleware.AuthMiddleware)
+	}
+
+	// Requests sent with X-Sandbox go to the sandbox's routes once the global middlewares ran
+	if a.sandboxService != nil {
+		h := sandbox.NewHandler(a.sandboxService)
+		h.RegisterRoutes(router, middleware.AuthMiddleware)
+		router.Use(sandbox.Dispatch(a.sandboxRoutes(h)))
+	}
+
+	a.adminRoutes(router)
+	a.monitoringRoutes(ctx, router)
+	if err := a.docsRoutes(ctx, router); err != nil {
+		return nil, err
+	}
+
+	return c.Handler(middleware.EnvelopeMiddleware(router)), nil
+}
+
+// sandboxRoutes builds the router of the features the sandbox serves, on its own database.
+// They authenticate against the real users and the rest of the API answers NOT_IN_SANDBOX.
+func (a *App) sandboxRoutes(h *sandbox.Handler) http.Handler {
+	r := &a.sandboxRepos
+	router := mux.NewRouter()
+	router.NotFoundHandler = http.HandlerFunc(sandbox.NotInSandbox)
+
+	reporttype.NewHandler(reporttype.NewService(r.reportType)).RegisterRoutes(router, h.AuthMiddleware)
+	company.NewHandler(company.NewService(r.company, r.user, r.outbox, r.transactor, a.store)).RegisterRoutes(router, h.AuthMiddleware)
+	report.NewHandler(report.NewService(r.report, r.outbox, r.transactor)).RegisterRoutes(router, h.AuthMiddleware)
+	h.RegisterRoutes(router, middleware.AuthMiddleware)
+	return router
+}
+
+// adminRoutes registers the operations endpoints under /api/admin.
+func (a *App) adminRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/api/admin").Subrouter()
+	admin.Use(middleware.AuthMiddleware)
+	admin.Use(middleware.RequirePermission("manage", "system"))
+
+	var secretsMu sync.Mutex
+	secretsCfg := a.cfg
+
+	// Re-reads secrets after a rotation. The JWT keys and email credentials apply immediately;
+	// database and other credentials are only picked up on restart.
+	admin.HandleFunc("/secrets/refresh", func(w http.ResponseWriter, r *http.Request) {
+		secretsMu.Lock()
+		defer secretsMu.Unlock()
+
+		next, changed, err := secretsCfg.ReloadSecrets(r.Context())
+		if err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+
+		applied, restartRequired := []string{}, []string{}
+		for _, key := range changed {
+			switch key {
+			case "JWT_SECRET", "JWT_PREVIOUS_SECRETS", "JWT_PRIVATE_KEY", "JWT_PREVIOUS_PUBLIC_KEYS":
+				if err := utils.SetJWTConfig(next.JWT); err != nil {
+					utils.HandleHTTPError(w, err, r)
+					return
+				}
+			case "NODEMAILER_EMAIL", "NODEMAILER_PASS", "SENDGRID_API_KEY", "MAILGUN_API_KEY":
+				a.emailService.Reconfigure(next.Email)
+			default:
+				restartRequired = append(restartRequired, key)
+				continue
+			}
+			applied = append(applied, key)
+		}
+		secretsCfg = next
+
+		log.Infof(r.Context(), "Secrets refreshed from %s: applied=%v restartRequired=%v", next.SecretsProvider(), applied, restartRequired)
+		utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+			"provider":        next.SecretsProvider(),
+			"applied":         applied,
+			"restartRequired": restartRequired,
+		})
+	}).Methods("POST")
+
+	var logLevelMu sync.Mutex
+	var revertLogLevel *time.Timer
+
+	// Returns the current log level.
+	admin.HandleFunc("/log-level", func(w http.ResponseWriter, r *http.Request) {
+		utils.RespondJSON(w, http.StatusOK, map[string]interface{}{"level": log.Level().String()})
+	}).Methods("GET")
+
+	// Changes the log level without a redeploy, e.g. to enable debug logging during an incident.
+	// With a duration the configured level is restored afterwards, so it cannot be left on by mistake.
+	admin.HandleFunc("/log-level", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Level    string `json:"level"`
+			Duration string `json:"duration,omitempty"`
+		}
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+
+		level, err := log.ParseLevel(req.Level)
+		if err != nil {
+			utils.HandleHTTPError(w, errors.New("INVALID_LOG_LEVEL", "Level must be debug, info, warn or error", http.StatusBadRequest, err, nil), r)
+			return
+		}
+		var duration time.Duration
+		if req.Duration != "" {
+			if duration, err = time.ParseDuration(req.Duration); err != nil || duration <= 0 {
+				utils.HandleHTTPError(w, errors.New("INVALID_DURATION", "Duration must be positive, e.g. 30m", http.StatusBadRequest, err, nil), r)
+				return
+			}
+		}
+
+		logLevelMu.Lock()
+		defer logLevelMu.Unlock()
+
+		previous := log.Level()
+		log.SetLevel(level)
+		if revertLogLevel != nil {
+			revertLogLevel.Stop()
+			revertLogLevel = nil
+		}
+
+		response := map[string]interface{}{"level": level.String(), "previous": previous.String()}
+		if duration > 0 {
+			revertAt := time.Now().Add(duration)
+			var timer *time.Timer
+			timer = time.AfterFunc(duration, func() {
+				logLevelMu.Lock()
+				defer logLevelMu.Unlock()
+				if revertLogLevel != timer {
+					return // superseded by a later change
+				}
+				revertLogLevel = nil
+				log.SetLevel(a.cfg.LogLevel)
+				log.Warnf(context.Background(), "Log level reverted to %s", a.cfg.LogLevel)
+			})
+			revertLogLevel = timer
+			response["revertAt"] = revertAt
+		}
+
+		// Logged as a warning so the change is visible at any level
+		log.Warnf(r.Context(), "Log level changed from %s to %s (duration %s)", previous, level, req.Duration)
+		utils.RespondJSON(w, http.StatusOK, response)
+	}).Methods("PUT")
+
+	// Reports hit rates of the repository and service caches.
+	admin.HandleFunc("/cache/stats", func(w http.ResponseWriter, r *http.Request) {
+		utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+			"repository": a.repoCache.Stats(),
+			"service":    utils.GetCache().Stats(),
+		})
+	}).Methods("GET")
+}
+
+// monitoringRoutes registers the health, readiness, metrics and profiling endpoints, and the
+// files served by the API itself.
+func (a *App) monitoringRoutes(ctx context.Context, router *mux.Router) {
+	cfg := a.cfg
+
+	// @Tags Monitoring
+	router.HandleFunc("/readyz", a.diagnostics.ReadyHandler).Methods("GET")
+	// @Tags Monitoring
+	router.Handle("/readyz/details", middleware.AuthMiddleware(middleware.RequirePermission("manage", "system")(
+		http.HandlerFunc(a.diagnostics.DetailsHandler)))).Methods("GET")
+
+	// Signed download links of the local store are served by the API itself
+	if cfg.Storage.Driver == storage.DriverLocal {
+		router.PathPrefix("/files/").Handler(storage.NewSignedURLHandler(a.store, cfg.Storage.SigningSecret)).Methods("GET")
+	}
+
+	router.PathPrefix(storage.ImagePathPrefix).Handler(storage.NewImageHandler(a.store)).Methods("GET")
+
+	// Serves Prometheus metrics, protected by METRICS_TOKEN when set.
+	// @Tags Monitoring
+	router.Handle("/metrics", metrics.Handler(cfg.MetricsToken, a.metricCollectors...)).Methods("GET")
+
+	// Runtime profiles and expvar counters. They expose internals, so they are only served with
+	// PPROF_ENABLED and to users with the manage system permission (super admins by default).
+	// CPU profiles and traces must ask for ?seconds= below HTTP_WRITE_TIMEOUT.
+	if cfg.Profiling {
+		profiling := router.PathPrefix("/debug").Subrouter()
+		profiling.Use(middleware.AuthMiddleware)
+		profiling.Use(middleware.RequirePermission("manage", "system"))
+		profiling.HandleFunc("/pprof/cmdline", pprof.Cmdline).Methods("GET")
+		profiling.HandleFunc("/pprof/profile", pprof.Profile).Methods("GET")
+		profiling.HandleFunc("/pprof/symbol", pprof.Symbol).Methods("GET", "POST")
+		profiling.HandleFunc("/pprof/trace", pprof.Trace).Methods("GET")
+		profiling.PathPrefix("/pprof/").HandlerFunc(pprof.Index).Methods("GET")
+		profiling.Handle("/vars", expvar.Handler()).Methods("GET")
+		log.Warnf(ctx, "Profiling endpoints are enabled under /debug/pprof")
+	}
+
+	// Health check and server greeting.
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		utils.RespondJSON(w, http.StatusOK, map[string]string{
+			"message": cfg.Greeting,
+			"status":  "healthy",
+		})
+	}).Methods("GET")
+
+	// Diagnostics leak server paths, so only development-like profiles serve them
+	if cfg.Profile.DebugEndpoints {
+		router.HandleFunc("/debug/files", func(w http.ResponseWriter, r *http.Request) {
+			wd, _ := os.Getwd()
+			utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+				"openapi_yaml_embedded": len(api.OpenAPISpec) > 0,
+				"swagger_ui_embedded":   api.SwaggerUI() != nil,
+				"working_directory":     wd,
+			})
+		}).Methods("GET")
+	}
+}
+
+// docsRoutes registers Swagger UI, the OpenAPI specification and the error catalog.
+func (a *App) docsRoutes(ctx context.Context, router *mux.Router) error {
+	// The spec and Swagger UI are embedded, so docs work in containers without internet access.
+	// Binaries built without `make swagger-ui` load the UI from unpkg instead.
+	swaggerAssets := "https://unpkg.com/swagger-ui-dist@" + api.SwaggerUIVersion
+	if ui := api.SwaggerUI(); ui != nil {
+		swaggerAssets = "/docs/assets"
+		router.PathPrefix("/docs/assets/").Handler(http.StripPrefix("/docs/assets/", http.FileServer(http.FS(ui)))).Methods("GET")
+	} else {
+		log.Warnf(ctx, "Swagger UI assets are not embedded; /docs loads them from unpkg (run make swagger-ui before building)")
+	}
+
+	// Serves Swagger UI for this spec.
+	router.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(swaggerPage(swaggerAssets, "/api/openapi.yaml"))
+	}).Methods("GET")
+
+	// Returns this OpenAPI specification.
+	router.HandleFunc("/api/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(api.OpenAPISpec)
+	}).Methods("GET")
+
+	// Each audience gets the spec of the operations its role can call, so client developers
+	// are not shown admin endpoints they would only get 403s from
+	audienceSpecs := map[string][]byte{}
+	for _, audience := range api.Audiences {
+		spec, err := api.SpecFor(audience)
+		if err != nil {
+			return fmt.Errorf("failed to filter the OpenAPI spec for %s: %w", audience, err)
+		}
+		audienceSpecs[audience] = spec
+	}
+	errUnknownAudience := errors.New("DOCS_AUDIENCE_NOT_FOUND", "Audience must be public, client, admin or super-admin", http.StatusNotFound, nil, nil)
+
+	// Serves Swagger UI for the operations an audience can call. The audience is public (no
+	// sign-in), client, admin or super-admin.
+	router.HandleFunc("/docs/{audience}", func(w http.ResponseWriter, r *http.Request) {
+		audience := mux.Vars(r)["audience"]
+		if _, ok := audienceSpecs[audience]; !ok {
+			utils.HandleHTTPError(w, errUnknownAudience, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(swaggerPage(swaggerAssets, "/api/openapi/"+audience+".yaml"))
+	}).Methods("GET")
+
+	// Returns this OpenAPI specification reduced to the operations an audience can call. The
+	// audience is public (no sign-in), client, admin or super-admin; operations are kept by their
+	// security and x-roles, which follow the built-in access policy.
+	router.HandleFunc("/api/openapi/{audience}.yaml", func(w http.ResponseWriter, r *http.Request) {
+		spec, ok := audienceSpecs[mux.Vars(r)["audience"]]
+		if !ok {
+			utils.HandleHTTPError(w, errUnknownAudience, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write(spec)
+	}).Methods("GET")
+
+	var errorCatalog []api.ErrorCode
+	if err := json.Unmarshal(api.ErrorCatalog, &errorCatalog); err != nil {
+		return fmt.Errorf("failed to load error catalog: %w", err)
+	}
+
+	// Lists the error codes the API returns, with their HTTP status and messages.
+	// @Success 200 {array} api.ErrorCode "Error catalog"
+	router.HandleFunc("/api/errors", func(w http.ResponseWriter, r *http.Request) {
+		utils.RespondJSON(w, http.StatusOK, errorCatalog)
+	}).Methods("GET")
+	return nil
+}
+
+// swaggerPage is the Swagger UI page of the spec at specURL, loading its assets from assets.
+func swaggerPage(assets, specURL string) []byte {
+	return []byte(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Finsolvz API Documentation</title>
+    <link rel="stylesheet" type="text/css" href="` + assets + `/swagger-ui.css" />
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="` + assets + `/swagger-ui-bundle.js"></script>
+    <script>
+        SwaggerUIBundle({
+            url: '` + specURL + `',
+            dom_id: '#swagger-ui',
+            presets: [
+                SwaggerUIBundle.presets.apis,
+                SwaggerUIBundle.presets.standalone
+            ]
+        });
+    </script>
+</body>
+</html>`)
+}
@@ -0,0 +1,8 @@
+package sandbox
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var ErrNotInSandbox = errors.New("NOT_IN_SANDBOX", "This endpoint is not available in the sandbox; send the request without X-Sandbox", http.StatusNotFound, nil, nil)
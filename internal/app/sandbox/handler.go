@@ -0,0 +1,88 @@
+package sandbox
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers sandbox routes
+// @Tags Sandbox
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+	protected.HandleFunc("/api/sandbox", h.GetSandbox).Methods("GET")
+
+	admin := router.PathPrefix("/api/admin/sandbox").Subrouter()
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequirePermission("manage", "system"))
+	admin.HandleFunc("/reset", h.ResetSandbox).Methods("POST")
+}
+
+// GetSandbox describes the sandbox that requests sent with the X-Sandbox header are served
+// from, and when it is wiped next.
+func (h *Handler) GetSandbox(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.Status(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, status)
+}
+
+// ResetSandbox wipes the sandbox now and seeds it again.
+func (h *Handler) ResetSandbox(w http.ResponseWriter, r *http.Request) {
+	reset, err := h.service.Reset(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, reset)
+}
+
+// AuthMiddleware authenticates like middleware.AuthMiddleware, then moves the request into
+// the sandbox. The sandbox's routes are registered with it.
+func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
+	return middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := h.service.Enter(r.Context())
+		if err != nil {
+			utils.HandleHTTPError(w, err, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
+
+// Dispatch serves requests sent with the X-Sandbox header from sandbox instead of the real
+// routes. It runs after the router's global middlewares, so rate limits and logging apply.
+func Dispatch(sandbox http.Handler) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if enabled, _ := strconv.ParseBool(r.Header.Get(Header)); !enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set(Header, "true")
+			sandbox.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NotInSandbox answers requests for the routes the sandbox does not serve.
+func NotInSandbox(w http.ResponseWriter, r *http.Request) {
+	utils.HandleHTTPError(w, ErrNotInSandbox, r)
+}
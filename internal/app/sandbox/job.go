@@ -0,0 +1,53 @@
+package sandbox
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// checkInterval caps how often the job checks whether a reset is due. Every instance runs
+// the job, and the first to find one due takes it.
+const checkInterval = 5 * time.Minute
+
+// Job seeds the sandbox the first time and wipes it on schedule.
+type Job struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewJob(service Service, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run checks at start, seeding a sandbox that was never seeded, and then until ctx is
+// cancelled. Without an interval it stops after the first check.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	if j.interval > 0 {
+		ticker.Reset(min(j.interval, checkInterval))
+	}
+	defer ticker.Stop()
+
+	for {
+		reset, err := j.service.RunSchedule(ctx)
+		if err != nil {
+			log.Errorf(ctx, "Sandbox: reset failed: %v", err)
+		} else if reset != nil {
+			log.Infof(ctx, "Sandbox: reset with %d companies and %d reports", reset.Companies, reset.Reports)
+		}
+		if j.interval <= 0 && err == nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package sandbox
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Header marks a request for the sandbox, e.g. "X-Sandbox: true". Responses served from the
+// sandbox carry it as well.
+const Header = "X-Sandbox"
+
+// StatusResponse describes the sandbox and when it is wiped next.
+type StatusResponse struct {
+	Header        string               `json:"header"`
+	ResetInterval string               `json:"resetInterval,omitempty"` // empty when only reset on demand
+	LastReset     *domain.SandboxReset `json:"lastReset,omitempty"`
+	NextResetAt   *time.Time           `json:"nextResetAt,omitempty"`
+	Companies     int                  `json:"companies"` // live companies, seeded or created since
+}
@@ -0,0 +1,144 @@
+package sandbox
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+//go:embed seed.json
+var defaultSeed []byte
+
+type Service interface {
+	// Enter moves an authenticated request into the sandbox: its tenant becomes the sandbox's
+	// companies, which any caller limited to their own companies sees as theirs
+	Enter(ctx context.Context) (context.Context, error)
+	Status(ctx context.Context) (*StatusResponse, error)
+	Reset(ctx context.Context) (*domain.SandboxReset, error)
+	// RunSchedule resets the sandbox when it was never seeded or a reset is due, returning
+	// nil when there was nothing to do or another instance took it
+	RunSchedule(ctx context.Context) (*domain.SandboxReset, error)
+}
+
+type service struct {
+	repo     domain.SandboxRepository
+	userRepo domain.UserRepository // of the real database
+	seed     *domain.SandboxSeed
+	interval time.Duration
+}
+
+func NewService(repo domain.SandboxRepository, userRepo domain.UserRepository, seed *domain.SandboxSeed, interval time.Duration) Service {
+	return &service{
+		repo:     repo,
+		userRepo: userRepo,
+		seed:     seed,
+		interval: interval,
+	}
+}
+
+// LoadSeed reads the seed at path, or the built-in one when path is empty.
+func LoadSeed(path string) (*domain.SandboxSeed, error) {
+	data := defaultSeed
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	var seed domain.SandboxSeed
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return nil, fmt.Errorf("invalid sandbox seed: %w", err)
+	}
+	return &seed, nil
+}
+
+func (s *service) Enter(ctx context.Context) (context.Context, error) {
+	userCtx, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+	userID, err := primitive.ObjectIDFromHex(userCtx.UserID)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	// The caller is copied in so companies and reports can name them
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnsureUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	companies, err := s.repo.Companies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = domain.WithTenant(ctx, &domain.Tenant{Companies: companies, Sandbox: true})
+	if scope := domain.AccessScopeOf(ctx); scope != nil {
+		ctx = domain.WithAccessScope(ctx, &domain.AccessScope{UserID: scope.UserID, Companies: companies})
+	}
+	if userCtx.Companies != nil {
+		userCtx.Companies = make([]string, len(companies))
+		for i, id := range companies {
+			userCtx.Companies[i] = id.Hex()
+		}
+	}
+	userCtx.Organization, userCtx.OrganizationAdmin = "", false
+	return ctx, nil
+}
+
+func (s *service) Status(ctx context.Context) (*StatusResponse, error) {
+	last, err := s.repo.LastReset(ctx)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := s.repo.Companies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &StatusResponse{Header: Header, LastReset: last, Companies: len(companies)}
+	if s.interval > 0 {
+		status.ResetInterval = s.interval.String()
+		if last != nil {
+			next := last.ResetAt.Add(s.interval)
+			status.NextResetAt = &next
+		}
+	}
+	return status, nil
+}
+
+func (s *service) Reset(ctx context.Context) (*domain.SandboxReset, error) {
+	return s.repo.Reset(ctx, s.seed)
+}
+
+func (s *service) RunSchedule(ctx context.Context) (*domain.SandboxReset, error) {
+	last, err := s.repo.LastReset(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && (s.interval <= 0 || time.Since(last.ResetAt) < s.interval) {
+		return nil, nil
+	}
+
+	since := time.Now().Add(-s.interval)
+	if s.interval <= 0 {
+		since = time.Time{}
+	}
+	claimed, err := s.repo.ClaimReset(ctx, since)
+	if err != nil || !claimed {
+		return nil, err
+	}
+	return s.Reset(ctx)
+}
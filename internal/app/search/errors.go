@@ -0,0 +1,13 @@
+package search
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidCompanyID    = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidYear         = errors.New("INVALID_YEAR", "Year format is invalid", http.StatusBadRequest, nil, nil)
+	ErrInvalidLimit        = errors.New("INVALID_LIMIT", "limit must be between 1 and 50", http.StatusBadRequest, nil, nil)
+)
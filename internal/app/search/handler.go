@@ -0,0 +1,84 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers search routes
+// @Tags Search
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/search", h.Search).Methods("GET")
+}
+
+// Search finds the reports, companies and users the user can see by name, and counts the
+// matches by type, company, report type and year. Users are only found by those who may list them
+// @Param q query string true "Text to search for, at least 2 characters"
+// @Param type query string false "Comma-separated types to search: report, company, user"
+// @Param company query string false "Company ID"
+// @Param reportType query string false "Report type ID; limits the search to reports"
+// @Param year query int false "Report year; limits the search to reports"
+// @Param limit query int false "Results to return, 1 to 50 (default 20)"
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := SearchRequest{
+		Query:      strings.TrimSpace(query.Get("q")),
+		Company:    query.Get("company"),
+		ReportType: query.Get("reportType"),
+	}
+	if types := query.Get("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			req.Types = append(req.Types, strings.TrimSpace(t))
+		}
+	}
+	if year := query.Get("year"); year != "" {
+		value, err := strconv.Atoi(year)
+		if err != nil {
+			utils.HandleHTTPError(w, ErrInvalidYear, r)
+			return
+		}
+		req.Year = &value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			utils.HandleHTTPError(w, ErrInvalidLimit, r)
+			return
+		}
+		req.Limit = value
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	results, err := h.service.Search(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, results)
+}
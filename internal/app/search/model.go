@@ -0,0 +1,55 @@
+package search
+
+// SearchRequest is a search over the reports, companies and users the user can see.
+type SearchRequest struct {
+	Query      string   `validate:"required,min=2,max=200"`
+	Types      []string `validate:"dive,oneof=report company user"` // every type when empty
+	Company    string
+	ReportType string
+	Year       *int
+	Limit      int
+}
+
+// Response DTOs
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Backend string         `json:"backend"` // mongo, atlas or elasticsearch
+	Total   int64          `json:"total"`
+	Results []SearchResult `json:"results"` // best matches first
+	Facets  SearchFacets   `json:"facets"`
+}
+
+type SearchResult struct {
+	Type       string     `json:"type"` // report, company or user
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Detail     string     `json:"detail,omitempty"`
+	Company    *Reference `json:"company,omitempty"`
+	ReportType *Reference `json:"reportType,omitempty"`
+	Year       int        `json:"year,omitempty"`
+	Score      float64    `json:"score,omitempty"` // relevance; not ranked by the mongo backend
+}
+
+type Reference struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// SearchFacets count all the matches, not only the results returned, most frequent first.
+type SearchFacets struct {
+	Types       []FacetValue `json:"types"`
+	Companies   []FacetValue `json:"companies"`
+	ReportTypes []FacetValue `json:"reportTypes"`
+	Years       []FacetValue `json:"years"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Name  string `json:"name,omitempty"` // of companies and report types
+	Count int64  `json:"count"`
+}
+
+const (
+	defaultLimit = 20
+	maxLimit     = 50
+)
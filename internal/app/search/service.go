@@ -0,0 +1,184 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils/log"
+)
+
+type Service interface {
+	Search(ctx context.Context, req SearchRequest) (*SearchResponse, error)
+}
+
+type service struct {
+	searchRepo     domain.SearchRepository
+	fallback       domain.SearchRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+}
+
+// NewService searches with searchRepo, and with fallback, if any, when searchRepo fails.
+func NewService(searchRepo, fallback domain.SearchRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	return &service{
+		searchRepo:     searchRepo,
+		fallback:       fallback,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+}
+
+func (s *service) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	query := domain.SearchQuery{
+		Text:  strings.TrimSpace(req.Query),
+		Kinds: req.Types,
+		Year:  req.Year,
+		Limit: req.Limit,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Limit < 1 || query.Limit > maxLimit {
+		return nil, ErrInvalidLimit
+	}
+	if req.Company != "" {
+		id, err := primitive.ObjectIDFromHex(req.Company)
+		if err != nil {
+			return nil, ErrInvalidCompanyID
+		}
+		query.Company = &id
+	}
+	if req.ReportType != "" {
+		id, err := primitive.ObjectIDFromHex(req.ReportType)
+		if err != nil {
+			return nil, ErrInvalidReportTypeID
+		}
+		query.ReportType = &id
+	}
+
+	// Users are found only by those who may list them
+	if middleware.Authorize(ctx, "list", policy.Resource{Type: "user"}) != nil {
+		query.Kinds = withoutUsers(query.Kinds)
+		if len(query.Kinds) == 0 {
+			return s.toResponse(ctx, query, s.searchRepo.Backend(), &domain.SearchResults{})
+		}
+	}
+
+	backend := s.searchRepo
+	results, err := backend.Search(ctx, query)
+	if err != nil && s.fallback != nil {
+		log.Warnf(ctx, "Search: %s failed, falling back to %s: %v", backend.Backend(), s.fallback.Backend(), err)
+		backend = s.fallback
+		results, err = backend.Search(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(ctx, query, backend.Backend(), results)
+}
+
+// toResponse names the companies and report types of the results and facets.
+func (s *service) toResponse(ctx context.Context, query domain.SearchQuery, backend string, results *domain.SearchResults) (*SearchResponse, error) {
+	companyIDs := []primitive.ObjectID{}
+	for _, hit := range results.Hits {
+		if hit.Company != nil {
+			companyIDs = append(companyIDs, *hit.Company)
+		}
+	}
+	for _, value := range results.Facets[domain.SearchFacetCompany] {
+		if id, err := primitive.ObjectIDFromHex(value.Value); err == nil {
+			companyIDs = append(companyIDs, id)
+		}
+	}
+
+	companyNames := map[string]string{}
+	if len(companyIDs) > 0 {
+		companies, err := s.companyRepo.GetByIDs(ctx, companyIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, company := range companies {
+			companyNames[company.ID.Hex()] = company.Name
+		}
+	}
+	reportTypeNames := map[string]string{}
+	if len(results.Facets[domain.SearchFacetReportType]) > 0 || hasReportHits(results.Hits) {
+		reportTypes, err := s.reportTypeRepo.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, reportType := range reportTypes {
+			reportTypeNames[reportType.ID.Hex()] = reportType.Name
+		}
+	}
+
+	response := &SearchResponse{
+		Query:   query.Text,
+		Backend: backend,
+		Total:   results.Total,
+		Results: make([]SearchResult, len(results.Hits)),
+		Facets: SearchFacets{
+			Types:       toFacet(results.Facets[domain.SearchFacetKind], nil),
+			Companies:   toFacet(results.Facets[domain.SearchFacetCompany], companyNames),
+			ReportTypes: toFacet(results.Facets[domain.SearchFacetReportType], reportTypeNames),
+			Years:       toFacet(results.Facets[domain.SearchFacetYear], nil),
+		},
+	}
+	for i, hit := range results.Hits {
+		result := SearchResult{
+			Type:   hit.Kind,
+			ID:     hit.ID.Hex(),
+			Title:  hit.Title,
+			Detail: hit.Detail,
+			Year:   hit.Year,
+			Score:  hit.Score,
+		}
+		// A company's own result needs no reference to itself
+		if hit.Company != nil && hit.Kind != domain.SearchKindCompany {
+			result.Company = &Reference{ID: hit.Company.Hex(), Name: companyNames[hit.Company.Hex()]}
+		}
+		if hit.ReportType != nil {
+			result.ReportType = &Reference{ID: hit.ReportType.Hex(), Name: reportTypeNames[hit.ReportType.Hex()]}
+		}
+		response.Results[i] = result
+	}
+
+	return response, nil
+}
+
+func toFacet(values []domain.SearchFacetValue, names map[string]string) []FacetValue {
+	facet := make([]FacetValue, len(values))
+	for i, value := range values {
+		facet[i] = FacetValue{Value: value.Value, Name: names[value.Value], Count: value.Count}
+	}
+	return facet
+}
+
+func hasReportHits(hits []domain.SearchHit) bool {
+	for _, hit := range hits {
+		if hit.Kind == domain.SearchKindReport {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutUsers drops users from the kinds searched; every other kind when kinds is empty.
+func withoutUsers(kinds []string) []string {
+	if len(kinds) == 0 {
+		return []string{domain.SearchKindReport, domain.SearchKindCompany}
+	}
+	filtered := []string{}
+	for _, kind := range kinds {
+		if kind != domain.SearchKindUser {
+			filtered = append(filtered, kind)
+		}
+	}
+	return filtered
+}
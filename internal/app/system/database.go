@@ -0,0 +1,88 @@
+package system
+
+import (
+	"context"
+	"database/sql"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/platform/metrics"
+)
+
+// DatabaseStatsFunc reads the current database statistics.
+type DatabaseStatsFunc func(ctx context.Context) (*DatabaseStats, error)
+
+// MongoStats reads dbStats of db, and the pool usage from the collector when given.
+// The server-wide connection count needs the serverStatus privilege and is left out
+// when the user lacks it.
+func MongoStats(db *mongo.Database, pool *metrics.MongoCollector) DatabaseStatsFunc {
+	return func(ctx context.Context) (*DatabaseStats, error) {
+		var dbStats struct {
+			Collections int64   `bson:"collections"`
+			Objects     int64   `bson:"objects"`
+			DataSize    float64 `bson:"dataSize"`
+			IndexSize   float64 `bson:"indexSize"`
+		}
+		if err := db.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&dbStats); err != nil {
+			return nil, err
+		}
+
+		stats := &DatabaseStats{
+			Collections:    dbStats.Collections,
+			Objects:        dbStats.Objects,
+			DataSizeBytes:  int64(dbStats.DataSize),
+			IndexSizeBytes: int64(dbStats.IndexSize),
+		}
+		if pool != nil {
+			poolStats := pool.PoolStats()
+			stats.Pool = &poolStats
+		}
+
+		var serverStatus struct {
+			Connections struct {
+				Current int64 `bson:"current"`
+			} `bson:"connections"`
+		}
+		command := bson.D{{Key: "serverStatus", Value: 1}, {Key: "repl", Value: 0}, {Key: "metrics", Value: 0}}
+		if err := db.Client().Database("admin").RunCommand(ctx, command).Decode(&serverStatus); err == nil {
+			stats.ServerConnections = &serverStatus.Connections.Current
+		}
+		return stats, nil
+	}
+}
+
+// PostgresStats reads table sizes, pool usage and the connections to the current database.
+func PostgresStats(db *sql.DB) DatabaseStatsFunc {
+	return func(ctx context.Context) (*DatabaseStats, error) {
+		stats := &DatabaseStats{}
+		err := db.QueryRowContext(ctx, `SELECT
+			count(*),
+			coalesce(sum(greatest(c.reltuples, 0)), 0)::bigint,
+			coalesce(sum(pg_table_size(c.oid)), 0)::bigint,
+			coalesce(sum(pg_indexes_size(c.oid)), 0)::bigint
+			FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind = 'r' AND n.nspname = current_schema()`).
+			Scan(&stats.Collections, &stats.Objects, &stats.DataSizeBytes, &stats.IndexSizeBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		dbStats := db.Stats()
+		stats.Pool = &metrics.PoolStats{
+			MaxSize: int64(dbStats.MaxOpenConnections),
+			Open:    int64(dbStats.OpenConnections),
+			InUse:   int64(dbStats.InUse),
+			Waits:   dbStats.WaitCount,
+		}
+
+		var connections, hosts int64
+		err = db.QueryRowContext(ctx, `SELECT count(*), count(DISTINCT client_addr)
+			FROM pg_stat_activity WHERE datname = current_database()`).Scan(&connections, &hosts)
+		if err == nil {
+			stats.ServerConnections = &connections
+			stats.ClientHosts = &hosts
+		}
+		return stats, nil
+	}
+}
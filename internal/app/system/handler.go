@@ -0,0 +1,43 @@
+package system
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the system status routes
+// @Tags Administration
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "system"))
+
+	adminOnly.HandleFunc("/api/admin/system", h.GetStatus).Methods("GET")
+}
+
+// GetStatus returns uptime, build, database, cache, queue and request statistics of this
+// instance, for the ops dashboard
+// @Summary Get system status
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.Status(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, status)
+}
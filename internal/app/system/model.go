@@ -0,0 +1,59 @@
+package system
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/app/backup"
+	"finsolvz-backend/internal/platform/buildinfo"
+	"finsolvz-backend/internal/platform/metrics"
+	"finsolvz-backend/internal/utils"
+)
+
+// Response DTOs
+type Status struct {
+	CheckedAt     time.Time                   `json:"checkedAt"`
+	StartedAt     time.Time                   `json:"startedAt"`
+	Uptime        string                      `json:"uptime"`
+	UptimeSeconds int64                       `json:"uptimeSeconds"`
+	Build         buildinfo.Info              `json:"build"`
+	Instance      buildinfo.Instance          `json:"instance"`
+	Runtime       Runtime                     `json:"runtime"`
+	Database      Database                    `json:"database"`
+	Caches        map[string]utils.CacheStats `json:"caches"`
+	Queues        map[string]Queue            `json:"queues"`
+	Backups       *backup.ScheduleStatus      `json:"backups,omitempty"` // scheduled backups, on the Mongo driver
+	Requests      []metrics.RequestRates      `json:"requests"`          // last 1, 5 and 15 minutes of this instance
+}
+
+type Runtime struct {
+	Goroutines int    `json:"goroutines"`
+	HeapBytes  uint64 `json:"heapBytes"`
+	GCRuns     uint32 `json:"gcRuns"`
+	MaxProcs   int    `json:"maxProcs"`
+}
+
+type Database struct {
+	Driver string         `json:"driver"`
+	Stats  *DatabaseStats `json:"stats,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// DatabaseStats is a size and connection snapshot of the database. Collections are tables
+// on Postgres, objects are documents or (estimated) rows.
+type DatabaseStats struct {
+	Collections    int64              `json:"collections"`
+	Objects        int64              `json:"objects"`
+	DataSizeBytes  int64              `json:"dataSizeBytes"`
+	IndexSizeBytes int64              `json:"indexSizeBytes"`
+	Pool           *metrics.PoolStats `json:"pool,omitempty"`
+
+	// ServerConnections counts every client connected to the database server and
+	// ClientHosts the distinct hosts among them; both hint at how many instances run.
+	ServerConnections *int64 `json:"serverConnections,omitempty"`
+	ClientHosts       *int64 `json:"clientHosts,omitempty"`
+}
+
+type Queue struct {
+	Pending int64  `json:"pending"`
+	Error   string `json:"error,omitempty"`
+}
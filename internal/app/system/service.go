@@ -0,0 +1,120 @@
+package system
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"finsolvz-backend/internal/app/backup"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/buildinfo"
+	"finsolvz-backend/internal/platform/metrics"
+	"finsolvz-backend/internal/utils"
+)
+
+// requestWindows are the windows the recent request rates are reported for
+var requestWindows = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// Sources are what the status is read from. Repositories left nil are not reported,
+// e.g. task and webhook queues on the Postgres driver.
+type Sources struct {
+	Driver     string
+	Database   DatabaseStatsFunc
+	Caches     map[string]utils.Cache
+	Outbox     domain.OutboxRepository
+	Tasks      domain.TaskRepository
+	Deliveries domain.WebhookDeliveryRepository
+	Backups    backup.Service
+	Requests   *metrics.HTTPCollector
+}
+
+type Service interface {
+	Status(ctx context.Context) (*Status, error)
+}
+
+type service struct {
+	sources Sources
+}
+
+func NewService(sources Sources) Service {
+	return &service{
+		sources: sources,
+	}
+}
+
+// Status collects a snapshot of this instance and its dependencies. A failing dependency
+// is reported in its error field rather than failing the whole status.
+func (s *service) Status(ctx context.Context) (*Status, error) {
+	uptime := buildinfo.Uptime()
+	instance := buildinfo.CurrentInstance()
+
+	status := &Status{
+		CheckedAt:     time.Now(),
+		StartedAt:     instance.StartedAt,
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Build:         buildinfo.Get(),
+		Instance:      instance,
+		Runtime:       readRuntime(),
+		Database:      Database{Driver: s.sources.Driver},
+		Caches:        make(map[string]utils.CacheStats, len(s.sources.Caches)),
+		Queues:        map[string]Queue{},
+		Requests:      []metrics.RequestRates{},
+	}
+
+	if s.sources.Database != nil {
+		stats, err := s.sources.Database(ctx)
+		if err != nil {
+			status.Database.Error = err.Error()
+		}
+		status.Database.Stats = stats
+	}
+
+	for name, cache := range s.sources.Caches {
+		status.Caches[name] = cache.Stats()
+	}
+
+	if s.sources.Outbox != nil {
+		status.Queues["outbox"] = queue(s.sources.Outbox.CountPending(ctx))
+	}
+	if s.sources.Tasks != nil {
+		status.Queues["tasks"] = queue(s.sources.Tasks.CountByStatus(ctx, domain.TaskStatusPending))
+	}
+	if s.sources.Deliveries != nil {
+		status.Queues["webhookDeliveries"] = queue(s.sources.Deliveries.CountPending(ctx))
+	}
+
+	if s.sources.Backups != nil {
+		backups, err := s.sources.Backups.ScheduleStatus(ctx)
+		if err != nil {
+			backups = &backup.ScheduleStatus{Error: err.Error()}
+		}
+		status.Backups = backups
+	}
+
+	if s.sources.Requests != nil {
+		for _, window := range requestWindows {
+			status.Requests = append(status.Requests, s.sources.Requests.Recent(window))
+		}
+	}
+
+	return status, nil
+}
+
+func queue(pending int64, err error) Queue {
+	if err != nil {
+		return Queue{Error: err.Error()}
+	}
+	return Queue{Pending: pending}
+}
+
+func readRuntime() Runtime {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return Runtime{
+		Goroutines: runtime.NumGoroutine(),
+		HeapBytes:  mem.HeapAlloc,
+		GCRuns:     mem.NumGC,
+		MaxProcs:   runtime.GOMAXPROCS(0),
+	}
+}
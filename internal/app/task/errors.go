@@ -0,0 +1,11 @@
+package task
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTaskID = errors.New("INVALID_TASK_ID", "Invalid task ID format", http.StatusBadRequest, nil, nil)
+	ErrTaskNotFound  = errors.New("TASK_NOT_FOUND", "Task not found", http.StatusNotFound, nil, nil)
+)
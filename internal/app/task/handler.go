@@ -0,0 +1,99 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	streamLifetime    = 10 * time.Minute
+	keepAliveInterval = 15 * time.Second
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers task status routes
+// @Tags Tasks
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/tasks", h.GetMyTasks).Methods("GET")
+	protected.HandleFunc("/api/tasks/{id}", h.GetTask).Methods("GET")
+	protected.HandleFunc("/api/tasks/{id}/events", h.StreamTask).Methods("GET")
+}
+
+// @Summary List the current user's tasks
+func (h *Handler) GetMyTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.service.GetMyTasks(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, tasks)
+}
+
+// GetTask returns a task's status and progress, and its result once it succeeded
+func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
+	task, err := h.service.GetTask(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, task)
+}
+
+// StreamTask streams a task's progress as Server-Sent Events: a "progress" event with the
+// task on every change and a final "done" event once it succeeded or failed, after which
+// the stream ends. Streams are closed after 10 minutes; EventSource clients reconnect.
+// Native EventSource can't send the Authorization header, so use a fetch-based client.
+// @Summary Stream task progress
+// @Success 200 "text/event-stream of progress and done events"
+func (h *Handler) StreamTask(w http.ResponseWriter, r *http.Request) {
+	task, err := h.service.GetTask(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	stream, err := utils.NewEventStream(w)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), streamLifetime)
+	defer cancel()
+	go stream.KeepAlive(ctx, keepAliveInterval)
+
+	err = h.service.WatchTask(ctx, task, func(task *TaskResponse) error {
+		event := "progress"
+		if isFinished(task) {
+			event = "done"
+		}
+		return stream.Send(event, task)
+	})
+	if err != nil {
+		log.Warnf(ctx, "Task stream for %s ended: %v", task.ID, err)
+		if appErr, ok := err.(errors.AppError); ok {
+			stream.Send("error", utils.ErrorResponse{Code: appErr.Code(), Message: appErr.Message()})
+		}
+	}
+}
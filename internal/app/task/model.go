@@ -0,0 +1,44 @@
+package task
+
+import (
+	"encoding/json"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Response DTOs
+type TaskResponse struct {
+	ID         string            `json:"_id"`
+	Type       string            `json:"type"`
+	Status     domain.TaskStatus `json:"status"`
+	Progress   int               `json:"progress"`
+	Result     json.RawMessage   `json:"result,omitempty"` // only once the task succeeded
+	Error      *string           `json:"error,omitempty"`
+	Attempts   int               `json:"attempts"`
+	CreatedBy  string            `json:"createdBy"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	StartedAt  *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+func ToTaskResponse(task *domain.Task) TaskResponse {
+	response := TaskResponse{
+		ID:         task.ID.Hex(),
+		Type:       string(task.Type),
+		Status:     task.Status,
+		Progress:   task.Progress,
+		Error:      task.Error,
+		Attempts:   task.Attempts,
+		CreatedBy:  task.CreatedBy.Hex(),
+		CreatedAt:  task.CreatedAt,
+		StartedAt:  task.StartedAt,
+		FinishedAt: task.FinishedAt,
+		UpdatedAt:  task.UpdatedAt,
+	}
+	if task.Status == domain.TaskStatusSucceeded && len(task.Result) > 0 {
+		response.Result = json.RawMessage(task.Result)
+	}
+	return response
+}
@@ -0,0 +1,137 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const (
+	listLimit     = 50
+	watchInterval = time.Second
+)
+
+type Service interface {
+	GetTask(ctx context.Context, id string) (*TaskResponse, error)
+	GetMyTasks(ctx context.Context) ([]*TaskResponse, error)
+	// WatchTask calls send with the task every time its status or progress changes, until
+	// it has finished or ctx is done. The task must come from GetTask, which checks access.
+	WatchTask(ctx context.Context, task *TaskResponse, send func(*TaskResponse) error) error
+}
+
+type service struct {
+	taskRepo domain.TaskRepository
+}
+
+func NewService(taskRepo domain.TaskRepository) Service {
+	return &service{
+		taskRepo: taskRepo,
+	}
+}
+
+// GetTask returns a task to the user who started it, or to a SUPER_ADMIN.
+// Other users get a 404 so task IDs cannot be probed.
+func (s *service) GetTask(ctx context.Context, id string) (*TaskResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidTaskID
+	}
+
+	if _, ok := middleware.GetUserFromContext(ctx); !ok {
+		return nil, utils.ErrUnauthorized
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, objectID)
+	if err != nil {
+		if appErr, ok := err.(errors.AppError); ok && appErr.Status() == 404 {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	// Tasks the policy hides are reported as missing, so their IDs can't be probed
+	if err := middleware.Authorize(ctx, "read", policy.Resource{Type: "task", Owners: []string{task.CreatedBy.Hex()}}); err != nil {
+		if err == utils.ErrForbidden {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	response := ToTaskResponse(task)
+	return &response, nil
+}
+
+// GetMyTasks lists the caller's most recent tasks
+func (s *service) GetMyTasks(ctx context.Context) ([]*TaskResponse, error) {
+	userCtx, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userCtx.UserID)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	tasks, err := s.taskRepo.ListByUser(ctx, userID, listLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*TaskResponse, len(tasks))
+	for i, task := range tasks {
+		response := ToTaskResponse(task)
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+// WatchTask polls the task, which may be run by a worker on any instance
+func (s *service) WatchTask(ctx context.Context, task *TaskResponse, send func(*TaskResponse) error) error {
+	objectID, err := primitive.ObjectIDFromHex(task.ID)
+	if err != nil {
+		return ErrInvalidTaskID
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := send(task); err != nil {
+			return err
+		}
+		if isFinished(task) {
+			return nil
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			latest, err := s.taskRepo.GetByID(ctx, objectID)
+			if err != nil {
+				return err
+			}
+			if latest.Status != domain.TaskStatus(task.Status) || latest.Progress != task.Progress {
+				response := ToTaskResponse(latest)
+				task = &response
+				break
+			}
+		}
+	}
+}
+
+func isFinished(task *TaskResponse) bool {
+	return task.Status == domain.TaskStatusSucceeded || task.Status == domain.TaskStatusFailed
+}
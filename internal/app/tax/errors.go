@@ -0,0 +1,15 @@
+package tax
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTaxRateID    = errors.New("INVALID_TAX_RATE_ID", "Invalid tax rate ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportID     = errors.New("INVALID_REPORT_ID", "Invalid report ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidDate         = errors.New("INVALID_DATE", "Dates must be formatted as YYYY-MM-DD", http.StatusBadRequest, nil, nil)
+	ErrInvalidPeriod       = errors.New("INVALID_TAX_PERIOD", "effectiveTo must be after effectiveFrom", http.StatusBadRequest, nil, nil)
+	ErrRateOverlap         = errors.New("TAX_RATE_OVERLAP", "A rate of this name and jurisdiction already applies to part of this period", http.StatusConflict, nil, nil)
+)
@@ -0,0 +1,146 @@
+package tax
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers tax rate routes
+// @Tags Tax Rates
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "tax"))
+
+	protected.HandleFunc("/api/tax-rates", h.GetTaxRates).Methods("GET")
+	adminOnly.HandleFunc("/api/tax-rates", h.CreateTaxRate).Methods("POST")
+	protected.HandleFunc("/api/tax-rates/{id}", h.GetTaxRateByID).Methods("GET")
+	adminOnly.HandleFunc("/api/tax-rates/{id}", h.UpdateTaxRate).Methods("PUT")
+	adminOnly.HandleFunc("/api/tax-rates/{id}", h.DeleteTaxRate).Methods("DELETE")
+	protected.HandleFunc("/api/reports/{id}/warnings", h.GetReportWarnings).Methods("GET")
+}
+
+// GetTaxRates lists tax rates by jurisdiction, name and period, newest first
+// @Param jurisdiction query string false "Only rates of this jurisdiction and, for a subdivision, its country"
+func (h *Handler) GetTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.GetTaxRates(r.Context(), r.URL.Query().Get("jurisdiction"))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, rates)
+}
+
+// CreateTaxRate adds the rate of a tax in a jurisdiction over a period, with an optional check
+// of reports' effective rate
+func (h *Handler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var req CreateTaxRateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	rate, err := h.service.CreateTaxRate(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, rate)
+}
+
+// @Summary Get tax rate by ID
+func (h *Handler) GetTaxRateByID(w http.ResponseWriter, r *http.Request) {
+	rate, err := h.service.GetTaxRateByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, rate)
+}
+
+// @Summary Update tax rate
+func (h *Handler) UpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateTaxRateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	rate, err := h.service.UpdateTaxRate(r.Context(), mux.Vars(r)["id"], req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Tax rate updated successfully",
+		"taxRate": rate,
+	})
+}
+
+// @Summary Delete tax rate
+func (h *Handler) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteTaxRate(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{
+		"message": "Tax rate deleted successfully",
+	})
+}
+
+// GetReportWarnings checks a report's effective tax rates against the statutory rates of its
+// company's jurisdiction
+func (h *Handler) GetReportWarnings(w http.ResponseWriter, r *http.Request) {
+	warnings, err := h.service.GetReportWarnings(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, warnings)
+}
+
+// requester is the ID of the user making the request, recorded on the rates they change.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
@@ -0,0 +1,98 @@
+package tax
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateTaxRateRequest struct {
+	Jurisdiction  string  `json:"jurisdiction" validate:"required,max=10"` // e.g. "ID" or "US-CA"
+	Name          string  `json:"name" validate:"required,min=1,max=100"`
+	Rate          float64 `json:"rate" validate:"gte=0,lte=100"`     // percent
+	EffectiveFrom string  `json:"effectiveFrom" validate:"required"` // YYYY-MM-DD
+	EffectiveTo   *string `json:"effectiveTo,omitempty"`             // YYYY-MM-DD, exclusive; open-ended when unset
+	// Check computes the effective rate in percent from the line items of a report, e.g.
+	// "[Income Tax Expense] / [Profit Before Tax] * 100"
+	Check      *string  `json:"check,omitempty" validate:"omitempty,max=1000"`
+	Tolerance  *float64 `json:"tolerance,omitempty" validate:"omitempty,gte=0"` // percentage points; 1 when unset
+	ReportType *string  `json:"reportType,omitempty"`                           // only reports of this type; every report when unset
+}
+
+// UpdateTaxRateRequest changes a rate; an empty effectiveTo, check or reportType removes it.
+type UpdateTaxRateRequest struct {
+	Name          *string  `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Rate          *float64 `json:"rate,omitempty" validate:"omitempty,gte=0,lte=100"`
+	EffectiveFrom *string  `json:"effectiveFrom,omitempty"`
+	EffectiveTo   *string  `json:"effectiveTo,omitempty"`
+	Check         *string  `json:"check,omitempty" validate:"omitempty,max=1000"`
+	Tolerance     *float64 `json:"tolerance,omitempty" validate:"omitempty,gte=0"`
+	ReportType    *string  `json:"reportType,omitempty"`
+}
+
+// Response DTOs
+type TaxRateResponse struct {
+	ID            string    `json:"id"`
+	Jurisdiction  string    `json:"jurisdiction"`
+	Name          string    `json:"name"`
+	Rate          float64   `json:"rate"`
+	EffectiveFrom string    `json:"effectiveFrom"`
+	EffectiveTo   *string   `json:"effectiveTo"`
+	Check         string    `json:"check,omitempty"`
+	Tolerance     float64   `json:"tolerance"`
+	ReportType    *string   `json:"reportType"`
+	UpdatedBy     string    `json:"updatedBy"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// WarningsResponse lists what the tax checks in effect found in a report.
+type WarningsResponse struct {
+	Report       string    `json:"report"`
+	Jurisdiction *string   `json:"jurisdiction"` // of the company; no checks apply when unset
+	Date         *string   `json:"date"`         // last day of the report's fiscal year, which rates are taken on
+	Checked      int       `json:"checked"`
+	Warnings     []Warning `json:"warnings"`
+}
+
+// Warning is a tax check a report failed or couldn't be checked against.
+type Warning struct {
+	Code         string   `json:"code"` // EFFECTIVE_TAX_RATE_DEVIATION or TAX_CHECK_INCOMPLETE
+	Message      string   `json:"message"`
+	TaxRate      NamedRef `json:"taxRate"`
+	Jurisdiction string   `json:"jurisdiction"`
+	Statutory    float64  `json:"statutory"`
+	Effective    *float64 `json:"effective"`
+	Difference   *float64 `json:"difference"` // effective - statutory, in percentage points
+	Tolerance    float64  `json:"tolerance"`
+}
+
+type NamedRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+const dateLayout = "2006-01-02"
+
+func ToTaxRateResponse(rate *domain.TaxRate) *TaxRateResponse {
+	response := &TaxRateResponse{
+		ID:            rate.ID.Hex(),
+		Jurisdiction:  rate.Jurisdiction,
+		Name:          rate.Name,
+		Rate:          rate.Rate,
+		EffectiveFrom: rate.EffectiveFrom.Format(dateLayout),
+		Check:         rate.Check,
+		Tolerance:     rate.Tolerance,
+		UpdatedBy:     rate.UpdatedBy.Hex(),
+		UpdatedAt:     rate.UpdatedAt,
+	}
+	if rate.EffectiveTo != nil {
+		effectiveTo := rate.EffectiveTo.Format(dateLayout)
+		response.EffectiveTo = &effectiveTo
+	}
+	if rate.ReportType != nil {
+		reportType := rate.ReportType.Hex()
+		response.ReportType = &reportType
+	}
+	return response
+}
@@ -0,0 +1,344 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/formula"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// defaultTolerance is how many percentage points an effective rate may be off the statutory
+// one, for rates created without a tolerance
+const defaultTolerance = 1.0
+
+type Service interface {
+	// GetTaxRates lists the rates of a jurisdiction and, for a subdivision, of its country, or
+	// every rate when jurisdiction is empty.
+	GetTaxRates(ctx context.Context, jurisdiction string) ([]*TaxRateResponse, error)
+	GetTaxRateByID(ctx context.Context, id string) (*TaxRateResponse, error)
+	CreateTaxRate(ctx context.Context, req CreateTaxRateRequest, createdBy primitive.ObjectID) (*TaxRateResponse, error)
+	UpdateTaxRate(ctx context.Context, id string, req UpdateTaxRateRequest, updatedBy primitive.ObjectID) (*TaxRateResponse, error)
+	DeleteTaxRate(ctx context.Context, id string) error
+
+	// GetReportWarnings runs the checks of the rates in effect in the company's jurisdiction at
+	// the end of the report's fiscal year against the report's line items.
+	GetReportWarnings(ctx context.Context, reportID string) (*WarningsResponse, error)
+}
+
+type service struct {
+	taxRateRepo    domain.TaxRateRepository
+	reportRepo     domain.ReportRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+}
+
+func NewService(taxRateRepo domain.TaxRateRepository, reportRepo domain.ReportRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	return &service{
+		taxRateRepo:    taxRateRepo,
+		reportRepo:     reportRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+}
+
+func (s *service) GetTaxRates(ctx context.Context, jurisdiction string) ([]*TaxRateResponse, error) {
+	var scopes []string
+	if jurisdiction != "" {
+		parsed, err := parseJurisdiction(jurisdiction)
+		if err != nil {
+			return nil, err
+		}
+		scopes = domain.JurisdictionScopes(parsed)
+	}
+
+	rates, err := s.taxRateRepo.GetAll(ctx, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*TaxRateResponse, len(rates))
+	for i, rate := range rates {
+		responses[i] = ToTaxRateResponse(rate)
+	}
+	return responses, nil
+}
+
+func (s *service) GetTaxRateByID(ctx context.Context, id string) (*TaxRateResponse, error) {
+	rate, err := s.getTaxRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToTaxRateResponse(rate), nil
+}
+
+func (s *service) CreateTaxRate(ctx context.Context, req CreateTaxRateRequest, createdBy primitive.ObjectID) (*TaxRateResponse, error) {
+	jurisdiction, err := parseJurisdiction(req.Jurisdiction)
+	if err != nil {
+		return nil, err
+	}
+	effectiveFrom, err := parseDate(req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &domain.TaxRate{
+		Jurisdiction:  jurisdiction,
+		Name:          strings.TrimSpace(req.Name),
+		Rate:          req.Rate,
+		EffectiveFrom: effectiveFrom,
+		Tolerance:     defaultTolerance,
+		UpdatedBy:     createdBy,
+	}
+	if req.EffectiveTo != nil && *req.EffectiveTo != "" {
+		effectiveTo, err := parseDate(*req.EffectiveTo)
+		if err != nil {
+			return nil, err
+		}
+		rate.EffectiveTo = &effectiveTo
+	}
+	if req.Check != nil {
+		if rate.Check, err = parseCheck(*req.Check); err != nil {
+			return nil, err
+		}
+	}
+	if req.Tolerance != nil {
+		rate.Tolerance = *req.Tolerance
+	}
+	if req.ReportType != nil && *req.ReportType != "" {
+		reportType, err := s.reportType(ctx, *req.ReportType)
+		if err != nil {
+			return nil, err
+		}
+		rate.ReportType = &reportType
+	}
+
+	if err := s.checkPeriod(ctx, rate); err != nil {
+		return nil, err
+	}
+	if err := s.taxRateRepo.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+	return ToTaxRateResponse(rate), nil
+}
+
+func (s *service) UpdateTaxRate(ctx context.Context, id string, req UpdateTaxRateRequest, updatedBy primitive.ObjectID) (*TaxRateResponse, error) {
+	rate, err := s.getTaxRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rate.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Rate != nil {
+		rate.Rate = *req.Rate
+	}
+	if req.EffectiveFrom != nil {
+		if rate.EffectiveFrom, err = parseDate(*req.EffectiveFrom); err != nil {
+			return nil, err
+		}
+	}
+	if req.EffectiveTo != nil {
+		rate.EffectiveTo = nil
+		if *req.EffectiveTo != "" {
+			effectiveTo, err := parseDate(*req.EffectiveTo)
+			if err != nil {
+				return nil, err
+			}
+			rate.EffectiveTo = &effectiveTo
+		}
+	}
+	if req.Check != nil {
+		if rate.Check, err = parseCheck(*req.Check); err != nil {
+			return nil, err
+		}
+	}
+	if req.Tolerance != nil {
+		rate.Tolerance = *req.Tolerance
+	}
+	if req.ReportType != nil {
+		rate.ReportType = nil
+		if *req.ReportType != "" {
+			reportType, err := s.reportType(ctx, *req.ReportType)
+			if err != nil {
+				return nil, err
+			}
+			rate.ReportType = &reportType
+		}
+	}
+	rate.UpdatedBy = updatedBy
+
+	if err := s.checkPeriod(ctx, rate); err != nil {
+		return nil, err
+	}
+	if err := s.taxRateRepo.Update(ctx, rate.ID, rate); err != nil {
+		return nil, err
+	}
+	return ToTaxRateResponse(rate), nil
+}
+
+func (s *service) DeleteTaxRate(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidTaxRateID
+	}
+	return s.taxRateRepo.Delete(ctx, objectID)
+}
+
+func (s *service) GetReportWarnings(ctx context.Context, reportID string) (*WarningsResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, ErrInvalidReportID
+	}
+	report, err := s.reportRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &WarningsResponse{Report: report.ID.Hex(), Warnings: []Warning{}}
+	if report.Company == nil {
+		return response, nil
+	}
+	company, err := s.companyRepo.GetByID(ctx, report.Company.ID)
+	if err != nil {
+		return nil, err
+	}
+	if company.Jurisdiction == nil {
+		return response, nil
+	}
+	response.Jurisdiction = company.Jurisdiction
+
+	// Rates are those in effect on the last day of the fiscal year the report covers
+	date := company.FiscalCalendar.Year(report.Year).End.AddDate(0, 0, -1)
+	day := date.Format(dateLayout)
+	response.Date = &day
+
+	rates, err := s.taxRateRepo.GetAll(ctx, domain.JurisdictionScopes(*company.Jurisdiction))
+	if err != nil {
+		return nil, err
+	}
+
+	items := domain.LineItems(report.ReportData)
+	for _, rate := range rates {
+		if rate.Check == "" || !rate.InEffect(date) {
+			continue
+		}
+		if rate.ReportType != nil && (report.ReportType == nil || report.ReportType.ID != *rate.ReportType) {
+			continue
+		}
+		response.Checked++
+		if warning := check(rate, items); warning != nil {
+			response.Warnings = append(response.Warnings, *warning)
+		}
+	}
+
+	return response, nil
+}
+
+// check computes the effective rate of a report with the rate's check, returning a warning
+// when it is off the statutory rate by more than the tolerance or can't be computed.
+func check(rate *domain.TaxRate, items map[string]float64) *Warning {
+	warning := &Warning{
+		TaxRate:      NamedRef{ID: rate.ID.Hex(), Name: rate.Name},
+		Jurisdiction: rate.Jurisdiction,
+		Statutory:    rate.Rate,
+		Tolerance:    rate.Tolerance,
+	}
+
+	parsed, err := formula.Parse(rate.Check)
+	if err == nil {
+		var effective float64
+		effective, err = parsed.Eval(func(name string) (float64, bool) {
+			amount, ok := items[domain.LineItemName(name)]
+			return amount, ok
+		})
+		if err == nil {
+			effective = math.Round(effective*100) / 100
+			difference := math.Round((effective-rate.Rate)*100) / 100
+			if math.Abs(difference) <= rate.Tolerance {
+				return nil
+			}
+			warning.Code = "EFFECTIVE_TAX_RATE_DEVIATION"
+			warning.Message = fmt.Sprintf("Effective %s rate of %.2f%% is %.2f points off the statutory %.2f%%", rate.Name, effective, difference, rate.Rate)
+			warning.Effective, warning.Difference = &effective, &difference
+			return warning
+		}
+	}
+
+	warning.Code = "TAX_CHECK_INCOMPLETE"
+	warning.Message = fmt.Sprintf("Effective %s rate could not be computed: %v", rate.Name, err)
+	return warning
+}
+
+// checkPeriod rejects a period that ends before it starts or overlaps another rate of the
+// same name and jurisdiction.
+func (s *service) checkPeriod(ctx context.Context, rate *domain.TaxRate) error {
+	if rate.EffectiveTo != nil && !rate.EffectiveTo.After(rate.EffectiveFrom) {
+		return ErrInvalidPeriod
+	}
+
+	others, err := s.taxRateRepo.GetAll(ctx, []string{rate.Jurisdiction})
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != rate.ID && strings.EqualFold(other.Name, rate.Name) && rate.Overlaps(other) {
+			return ErrRateOverlap
+		}
+	}
+	return nil
+}
+
+func (s *service) getTaxRate(ctx context.Context, id string) (*domain.TaxRate, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidTaxRateID
+	}
+	return s.taxRateRepo.GetByID(ctx, objectID)
+}
+
+func (s *service) reportType(ctx context.Context, id string) (primitive.ObjectID, error) {
+	reportTypeID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidReportTypeID
+	}
+	if _, err := s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return reportTypeID, nil
+}
+
+func parseJurisdiction(value string) (string, error) {
+	jurisdiction, err := domain.ParseJurisdiction(value)
+	if err != nil {
+		return "", errors.New("INVALID_JURISDICTION", "Jurisdiction must be an ISO 3166 country code with an optional subdivision, e.g. ID or US-CA", 400, err, nil)
+	}
+	return jurisdiction, nil
+}
+
+// parseCheck validates the formula of a check; an empty one removes the check.
+func parseCheck(src string) (string, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return "", nil
+	}
+	if _, err := formula.Parse(src); err != nil {
+		return "", errors.New("INVALID_FORMULA", "Formula is invalid", 400, err, map[string]interface{}{"reason": err.Error()})
+	}
+	return src, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return date, nil
+}
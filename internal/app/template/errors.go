@@ -0,0 +1,14 @@
+package template
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTemplateID   = errors.New("INVALID_TEMPLATE_ID", "Invalid template ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidCompanyID    = errors.New("INVALID_COMPANY_ID", "Invalid company ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidReportTypeID = errors.New("INVALID_REPORT_TYPE_ID", "Invalid report type ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidVersion      = errors.New("INVALID_TEMPLATE_VERSION", "Version must be a positive number", http.StatusBadRequest, nil, nil)
+	ErrOtherOrganization   = errors.New("TEMPLATE_OTHER_ORGANIZATION", "Templates can only be copied by companies of the organization that published them", http.StatusBadRequest, nil, nil)
+)
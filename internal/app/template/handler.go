@@ -0,0 +1,209 @@
+package template
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers report template library routes
+// @Tags Templates
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	protected := router.PathPrefix("").Subrouter()
+	protected.Use(authMiddleware)
+
+	protected.HandleFunc("/api/templates", h.GetTemplates).Methods("GET")
+	protected.HandleFunc("/api/templates", h.PublishTemplate).Methods("POST")
+	protected.HandleFunc("/api/templates/{id}", h.GetTemplateByID).Methods("GET")
+	protected.HandleFunc("/api/templates/{id}", h.UpdateTemplate).Methods("PUT")
+	protected.HandleFunc("/api/templates/{id}", h.DeleteTemplate).Methods("DELETE")
+	protected.HandleFunc("/api/templates/{id}/versions", h.GetVersions).Methods("GET")
+	protected.HandleFunc("/api/templates/{id}/versions", h.PublishVersion).Methods("POST")
+	protected.HandleFunc("/api/templates/{id}/versions/{version}", h.GetVersion).Methods("GET")
+	protected.HandleFunc("/api/templates/{id}/copy", h.CopyTemplate).Methods("POST")
+}
+
+// GetTemplates lists the templates in the library of the user's organization, without accounts
+func (h *Handler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.GetTemplates(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, templates)
+}
+
+// PublishTemplate publishes a company's chart of accounts to its organization's library for
+// the other companies to copy. Admins publish those of their companies.
+func (h *Handler) PublishTemplate(w http.ResponseWriter, r *http.Request) {
+	var req PublishTemplateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	template, err := h.service.PublishTemplate(r.Context(), req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, template)
+}
+
+// @Summary Get template by ID
+func (h *Handler) GetTemplateByID(w http.ResponseWriter, r *http.Request) {
+	template, err := h.service.GetTemplateByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, template)
+}
+
+// UpdateTemplate renames or redescribes a template without publishing a new version
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateTemplateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Template updated successfully",
+		"template": template,
+	})
+}
+
+// DeleteTemplate removes a template with all its versions. Charts copied from it are kept
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteTemplate(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]string{
+		"message": "Template deleted successfully",
+	})
+}
+
+// GetVersions lists the versions of a template, newest first, without accounts
+func (h *Handler) GetVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.service.GetVersions(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, versions)
+}
+
+// PublishVersion publishes the current chart of accounts of the template's company as its
+// next version, as of the version given in the body. It fails with 409 when the template has
+// a newer version.
+func (h *Handler) PublishVersion(w http.ResponseWriter, r *http.Request) {
+	var req PublishVersionRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	template, err := h.service.PublishVersion(r.Context(), mux.Vars(r)["id"], req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, template)
+}
+
+// @Summary Get a version of a template
+func (h *Handler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		utils.HandleHTTPError(w, ErrInvalidVersion, r)
+		return
+	}
+
+	templateVersion, err := h.service.GetVersion(r.Context(), vars["id"], version)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, templateVersion)
+}
+
+// CopyTemplate replaces a company's chart of accounts with a version of a template, noting the
+// template, version and publishing company on the chart
+func (h *Handler) CopyTemplate(w http.ResponseWriter, r *http.Request) {
+	var req CopyTemplateRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	chart, err := h.service.CopyTemplate(r.Context(), mux.Vars(r)["id"], req, requester(r))
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, chart)
+}
+
+// requester is the ID of the user making the request, recorded on the versions they publish.
+func requester(r *http.Request) primitive.ObjectID {
+	var id primitive.ObjectID
+	if userCtx, ok := middleware.GetUserFromContext(r.Context()); ok {
+		id, _ = primitive.ObjectIDFromHex(userCtx.UserID)
+	}
+	return id
+}
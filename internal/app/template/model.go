@@ -0,0 +1,91 @@
+package template
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+
+// PublishTemplateRequest publishes a company's chart of accounts to its organization's library.
+type PublishTemplateRequest struct {
+	Company     string  `json:"company" validate:"required"`
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Description string  `json:"description,omitempty" validate:"max=500"`
+	ReportType  *string `json:"reportType,omitempty"` // reports the template suits; any when unset
+	Note        string  `json:"note,omitempty" validate:"max=500"`
+}
+
+type UpdateTemplateRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	ReportType  *string `json:"reportType,omitempty"` // empty for any
+}
+
+// PublishVersionRequest publishes the publishing company's current chart as the next version
+// of a template, as of the version the caller read. It fails when someone else published one
+// in the meantime.
+type PublishVersionRequest struct {
+	Version int    `json:"version" validate:"required,min=1"`
+	Note    string `json:"note,omitempty" validate:"max=500"`
+}
+
+// CopyTemplateRequest replaces a company's chart of accounts with a version of a template.
+type CopyTemplateRequest struct {
+	Company string `json:"company" validate:"required"`
+	Version int    `json:"version,omitempty" validate:"min=0"` // the latest when unset
+}
+
+// Response DTOs
+type TemplateResponse struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Company     string           `json:"company"` // that published the template
+	ReportType  *string          `json:"reportType"`
+	Version     int              `json:"version"`
+	Accounts    []domain.Account `json:"accounts,omitempty"` // left out of lists
+	Copies      int              `json:"copies"`
+	PublishedBy string           `json:"publishedBy"` // of the latest version
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+type VersionResponse struct {
+	Version     int              `json:"version"`
+	Accounts    []domain.Account `json:"accounts,omitempty"` // left out of lists
+	Note        string           `json:"note,omitempty"`
+	PublishedBy string           `json:"publishedBy"`
+	PublishedAt time.Time        `json:"publishedAt"`
+}
+
+func ToTemplateResponse(template *domain.ReportTemplate) *TemplateResponse {
+	response := &TemplateResponse{
+		ID:          template.ID.Hex(),
+		Name:        template.Name,
+		Description: template.Description,
+		Company:     template.Company.Hex(),
+		Version:     template.Version,
+		Accounts:    template.Accounts,
+		Copies:      template.Copies,
+		PublishedBy: template.PublishedBy.Hex(),
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+	if template.ReportType != nil {
+		reportType := template.ReportType.Hex()
+		response.ReportType = &reportType
+	}
+	return response
+}
+
+func ToVersionResponse(version *domain.ReportTemplateVersion) *VersionResponse {
+	return &VersionResponse{
+		Version:     version.Version,
+		Accounts:    version.Accounts,
+		Note:        version.Note,
+		PublishedBy: version.PublishedBy.Hex(),
+		PublishedAt: version.PublishedAt,
+	}
+}
@@ -0,0 +1,293 @@
+package template
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/app/ledger"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
+)
+
+type Service interface {
+	// PublishTemplate publishes the chart of accounts of a company as version 1 of a template
+	// in the library of the company's organization.
+	PublishTemplate(ctx context.Context, req PublishTemplateRequest, publishedBy primitive.ObjectID) (*TemplateResponse, error)
+	// GetTemplates lists the templates of the caller's organization, without their accounts.
+	GetTemplates(ctx context.Context) ([]*TemplateResponse, error)
+	GetTemplateByID(ctx context.Context, id string) (*TemplateResponse, error)
+	UpdateTemplate(ctx context.Context, id string, req UpdateTemplateRequest) (*TemplateResponse, error)
+	// PublishVersion publishes the current chart of the template's company as its next version.
+	PublishVersion(ctx context.Context, id string, req PublishVersionRequest, publishedBy primitive.ObjectID) (*TemplateResponse, error)
+	DeleteTemplate(ctx context.Context, id string) error
+	GetVersions(ctx context.Context, id string) ([]*VersionResponse, error)
+	GetVersion(ctx context.Context, id string, version int) (*VersionResponse, error)
+	// CopyTemplate replaces the chart of accounts of a company of the template's organization
+	// with a version of the template, recording where it came from.
+	CopyTemplate(ctx context.Context, id string, req CopyTemplateRequest, copiedBy primitive.ObjectID) (*ledger.ChartOfAccountsResponse, error)
+}
+
+type service struct {
+	templateRepo   domain.ReportTemplateRepository
+	ledgerRepo     domain.LedgerRepository
+	companyRepo    domain.CompanyRepository
+	reportTypeRepo domain.ReportTypeRepository
+}
+
+func NewService(templateRepo domain.ReportTemplateRepository, ledgerRepo domain.LedgerRepository, companyRepo domain.CompanyRepository, reportTypeRepo domain.ReportTypeRepository) Service {
+	return &service{
+		templateRepo:   templateRepo,
+		ledgerRepo:     ledgerRepo,
+		companyRepo:    companyRepo,
+		reportTypeRepo: reportTypeRepo,
+	}
+}
+
+func (s *service) PublishTemplate(ctx context.Context, req PublishTemplateRequest, publishedBy primitive.ObjectID) (*TemplateResponse, error) {
+	companyID, err := primitive.ObjectIDFromHex(req.Company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, companyID); err != nil {
+		return nil, err
+	}
+	chart, err := s.ledgerRepo.GetChart(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &domain.ReportTemplate{
+		Organization: company.Organization,
+		Name:         strings.TrimSpace(req.Name),
+		Description:  strings.TrimSpace(req.Description),
+		Company:      companyID,
+		Accounts:     chart.Accounts,
+		PublishedBy:  publishedBy,
+	}
+	if req.ReportType != nil && *req.ReportType != "" {
+		reportType, err := s.reportType(ctx, *req.ReportType)
+		if err != nil {
+			return nil, err
+		}
+		template.ReportType = &reportType
+	}
+
+	if err := s.templateRepo.Create(ctx, template, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+	return ToTemplateResponse(template), nil
+}
+
+func (s *service) GetTemplates(ctx context.Context) ([]*TemplateResponse, error) {
+	templates, err := s.templateRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*TemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = ToTemplateResponse(template)
+	}
+	return responses, nil
+}
+
+func (s *service) GetTemplateByID(ctx context.Context, id string) (*TemplateResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ToTemplateResponse(template), nil
+}
+
+func (s *service) UpdateTemplate(ctx context.Context, id string, req UpdateTemplateRequest) (*TemplateResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, template.Company); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		template.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.ReportType != nil {
+		template.ReportType = nil
+		if *req.ReportType != "" {
+			reportType, err := s.reportType(ctx, *req.ReportType)
+			if err != nil {
+				return nil, err
+			}
+			template.ReportType = &reportType
+		}
+	}
+
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return ToTemplateResponse(template), nil
+}
+
+func (s *service) PublishVersion(ctx context.Context, id string, req PublishVersionRequest, publishedBy primitive.ObjectID) (*TemplateResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, template.Company); err != nil {
+		return nil, err
+	}
+	chart, err := s.ledgerRepo.GetChart(ctx, template.Company)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository only publishes if the template is still at the version the caller read
+	template.Version = req.Version
+	template.Accounts = chart.Accounts
+	template.PublishedBy = publishedBy
+
+	if err := s.templateRepo.Publish(ctx, template, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+	return ToTemplateResponse(template), nil
+}
+
+func (s *service) DeleteTemplate(ctx context.Context, id string) error {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, template.Company); err != nil {
+		return err
+	}
+	return s.templateRepo.Delete(ctx, template.ID)
+}
+
+func (s *service) GetVersions(ctx context.Context, id string) ([]*VersionResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.templateRepo.GetVersions(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*VersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = ToVersionResponse(version)
+	}
+	return responses, nil
+}
+
+func (s *service) GetVersion(ctx context.Context, id string, version int) (*VersionResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidTemplateID
+	}
+	if version < 1 {
+		return nil, ErrInvalidVersion
+	}
+
+	templateVersion, err := s.templateRepo.GetVersion(ctx, objectID, version)
+	if err != nil {
+		return nil, err
+	}
+	return ToVersionResponse(templateVersion), nil
+}
+
+func (s *service) CopyTemplate(ctx context.Context, id string, req CopyTemplateRequest, copiedBy primitive.ObjectID) (*ledger.ChartOfAccountsResponse, error) {
+	template, err := s.getTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	companyID, err := primitive.ObjectIDFromHex(req.Company)
+	if err != nil {
+		return nil, ErrInvalidCompanyID
+	}
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameOrganization(company.Organization, template.Organization) {
+		return nil, ErrOtherOrganization
+	}
+	if err := authorize(ctx, companyID); err != nil {
+		return nil, err
+	}
+
+	version, accounts := template.Version, template.Accounts
+	if req.Version != 0 && req.Version != template.Version {
+		templateVersion, err := s.templateRepo.GetVersion(ctx, template.ID, req.Version)
+		if err != nil {
+			return nil, err
+		}
+		version, accounts = templateVersion.Version, templateVersion.Accounts
+	}
+
+	chart := &domain.ChartOfAccounts{
+		Company:  companyID,
+		Accounts: accounts,
+		Template: &domain.TemplateOrigin{
+			Template: template.ID,
+			Name:     template.Name,
+			Company:  template.Company,
+			Version:  version,
+			CopiedBy: copiedBy,
+			CopiedAt: time.Now(),
+		},
+		UpdatedBy: copiedBy,
+	}
+	if err := s.ledgerRepo.SaveChart(ctx, chart); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.CountCopy(ctx, template.ID); err != nil {
+		return nil, err
+	}
+
+	return ledger.ToChartOfAccountsResponse(chart), nil
+}
+
+func (s *service) getTemplate(ctx context.Context, id string) (*domain.ReportTemplate, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidTemplateID
+	}
+	return s.templateRepo.GetByID(ctx, objectID)
+}
+
+func (s *service) reportType(ctx context.Context, id string) (primitive.ObjectID, error) {
+	reportTypeID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidReportTypeID
+	}
+	if _, err := s.reportTypeRepo.GetByID(ctx, reportTypeID); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return reportTypeID, nil
+}
+
+// authorize checks that the caller may keep the chart of accounts of the company, which
+// publishing it or copying a template into it changes or shares.
+func authorize(ctx context.Context, companyID primitive.ObjectID) error {
+	return middleware.Authorize(ctx, "manage", policy.Resource{Type: "ledger", Companies: []string{companyID.Hex()}})
+}
+
+func sameOrganization(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
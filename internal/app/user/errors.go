@@ -6,8 +6,16 @@ import (
 )
 
 var (
-	ErrUserNotFound       = errors.New("USER_NOT_FOUND", "User not found", http.StatusNotFound, nil, nil)
-	ErrEmailAlreadyExists = errors.New("EMAIL_ALREADY_EXISTS", "Email already used by another user", http.StatusConflict, nil, nil)
-	ErrPasswordMismatch   = errors.New("PASSWORD_MISMATCH", "Passwords do not match", http.StatusBadRequest, nil, nil)
-	ErrUnauthorizedAccess = errors.New("UNAUTHORIZED_ACCESS", "You are not authorized to perform this action", http.StatusForbidden, nil, nil)
+	ErrUserNotFound        = errors.New("USER_NOT_FOUND", "User not found", http.StatusNotFound, nil, nil)
+	ErrEmailAlreadyExists  = errors.New("EMAIL_ALREADY_EXISTS", "Email already used by another user", http.StatusConflict, nil, nil)
+	ErrPasswordMismatch    = errors.New("PASSWORD_MISMATCH", "Passwords do not match", http.StatusBadRequest, nil, nil)
+	ErrInvalidRoleFilter   = errors.New("INVALID_ROLE", "Role must be SUPER_ADMIN, ADMIN or CLIENT", http.StatusBadRequest, nil, nil)
+	ErrInvalidExportFormat = errors.New("INVALID_EXPORT_FORMAT", "Format must be csv or ndjson", http.StatusBadRequest, nil, nil)
+	ErrInvalidSort         = errors.New("INVALID_SORT", "Users can be sorted by name, email, role, createdAt or updatedAt", http.StatusBadRequest, nil, nil)
+	ErrUnauthorizedAccess  = errors.New("UNAUTHORIZED_ACCESS", "You are not authorized to perform this action", http.StatusForbidden, nil, nil)
+
+	ErrDeactivateSelf           = errors.New("CANNOT_DEACTIVATE_SELF", "You cannot deactivate your own account", http.StatusBadRequest, nil, nil)
+	ErrImpersonationNotAllowed  = errors.New("IMPERSONATION_NOT_ALLOWED", "Super admins and yourself cannot be impersonated", http.StatusForbidden, nil, nil)
+	ErrDuplicateAssignment      = errors.New("DUPLICATE_ASSIGNMENT", "The user is assigned a role more than once", http.StatusBadRequest, nil, nil)
+	ErrSuperAdminInOrganization = errors.New("SUPER_ADMIN_NOT_ALLOWED", "Remove the user from their organization before making them a super admin", http.StatusBadRequest, nil, nil)
 )
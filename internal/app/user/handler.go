@@ -1,13 +1,19 @@
 package user
 
 import (
+	"mime"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 
 	"finsolvz-backend/internal/app/auth"
+	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/policy"
 	"finsolvz-backend/internal/utils"
 )
 
@@ -26,18 +32,29 @@ func NewHandler(service Service, authService auth.Service) *Handler {
 }
 
 // RegisterRoutes registers user routes
+// @Tags User Management
 func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
 	protected := router.PathPrefix("").Subrouter()
 	protected.Use(authMiddleware)
 
 	protected.HandleFunc("/api/users", h.GetUsers).Methods("GET")
+	protected.HandleFunc("/api/users/export", h.ExportUsers).Methods("GET")
 	protected.HandleFunc("/api/users/{id}", h.GetUserByID).Methods("GET")
 	protected.HandleFunc("/api/loginUser", h.GetLoginUser).Methods("GET")
+	// Before /api/users/{id}, which would match it too
+	protected.HandleFunc("/api/users/roles", h.UpdateRoles).Methods("PUT")
 	protected.HandleFunc("/api/users/{id}", h.UpdateUser).Methods("PUT")
 	protected.HandleFunc("/api/users/{id}", h.DeleteUser).Methods("DELETE")
+	protected.HandleFunc("/api/users/{id}/deactivate", h.DeactivateUser).Methods("PATCH")
+	protected.HandleFunc("/api/users/{id}/activate", h.ActivateUser).Methods("PATCH")
 	protected.HandleFunc("/api/register", h.Register).Methods("POST")
 	protected.HandleFunc("/api/updateRole", h.UpdateRole).Methods("PUT")
 	protected.HandleFunc("/api/change-password", h.ChangePassword).Methods("PATCH")
+	protected.HandleFunc("/api/me/preferences", h.GetPreferences).Methods("GET")
+	protected.HandleFunc("/api/me/preferences", h.UpdatePreferences).Methods("PUT")
+	protected.HandleFunc("/api/me/avatar", h.UploadAvatar).Methods("PUT")
+	protected.HandleFunc("/api/me/avatar", h.DeleteAvatar).Methods("DELETE")
+	protected.HandleFunc("/api/impersonate/{userId}", h.ImpersonateUser).Methods("POST")
 }
 
 // Register creates a new user account
@@ -53,10 +70,9 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only SUPER_ADMIN can register new users
-	userCtx, ok := middleware.GetUserFromContext(r.Context())
-	if !ok || userCtx.Role != "SUPER_ADMIN" {
-		utils.HandleHTTPError(w, utils.ErrForbidden, r)
+	// Only SUPER_ADMIN can register new users, per the access policy
+	if err := middleware.Authorize(r.Context(), "create", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
 		return
 	}
 
@@ -72,25 +88,117 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetUsers retrieves all users
+// @Summary List users
+// @Description Users page by page, oldest first unless sorted otherwise. q searches names and
+// @Description emails, role filters by role, and sort orders by name, email, role, createdAt or
+// @Description updatedAt, descending with a leading "-" (e.g. sort=-createdAt).
+// @Param q query string false "Text the name or email contains"
+// @Param role query string false "SUPER_ADMIN, ADMIN or CLIENT"
+// @Param sort query string false "Field to sort by, with - for descending"
 func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	// Only SUPER_ADMIN and ADMIN can view all users
-	userCtx, ok := middleware.GetUserFromContext(r.Context())
-	if !ok || (userCtx.Role != "SUPER_ADMIN" && userCtx.Role != "ADMIN") {
-		utils.HandleHTTPError(w, utils.ErrForbidden, r)
+	w = utils.WithProjection(w, r)
+
+	// Only SUPER_ADMIN and ADMIN can view all users, per the access policy
+	if err := middleware.Authorize(r.Context(), "list", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if format := utils.RowFormat(r); format != "" {
+		h.streamUsers(w, r, format, userColumns)
 		return
 	}
 
-	users, err := h.service.GetUsers(r.Context())
+	pagination := utils.GetPaginationParams(r)
+	query, err := userQuery(r, pagination)
 	if err != nil {
 		utils.HandleHTTPError(w, err, r)
 		return
 	}
 
-	utils.RespondJSON(w, http.StatusOK, users)
+	users, total, err := h.service.GetUsers(r.Context(), query)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	pagination.Total = total
+	utils.SetPaginationHeaders(w, r, pagination)
+	utils.RespondJSON(w, http.StatusOK, utils.CreatePaginatedResponse(users, pagination))
 }
 
+// userQuery reads the search, role filter and sort order of a user list request.
+func userQuery(r *http.Request, pagination utils.PaginationParams) (domain.UserQuery, error) {
+	values := r.URL.Query()
+	query := domain.UserQuery{
+		Search: strings.TrimSpace(values.Get("q")),
+		Role:   domain.UserRole(values.Get("role")),
+		Skip:   pagination.Skip,
+		Limit:  pagination.Limit,
+	}
+	if query.Role != "" && !query.Role.IsValid() {
+		return query, ErrInvalidRoleFilter
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		query.Sort, query.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+		if !slices.Contains(domain.UserSortFields, query.Sort) {
+			return query, ErrInvalidSort
+		}
+	}
+	return query, nil
+}
+
+// userColumns are the CSV columns of user lists when ?fields= doesn't choose them
+var userColumns = []string{"_id", "name", "email", "role", "company", "createdAt", "updatedAt"}
+
+// exportColumns are the CSV columns of user exports when ?fields= doesn't choose them
+var exportColumns = []string{"_id", "name", "email", "role", "company", "organization", "active", "invitedAt", "createdAt", "updatedAt"}
+
+// @Summary Export users
+// @Description Streams every user as a CSV download, or NDJSON with format=ndjson, while they are
+// @Description read, for audits and license reconciliation. Companies are listed by ID.
+// @Param format query string false "csv (default) or ndjson"
+func (h *Handler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	// Only SUPER_ADMIN and ADMIN can view all users, per the access policy
+	if err := middleware.Authorize(r.Context(), "list", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	format, extension := utils.FormatCSV, "csv"
+	switch r.URL.Query().Get("format") {
+	case "", "csv":
+	case "ndjson":
+		format, extension = utils.FormatNDJSON, "ndjson"
+	default:
+		utils.HandleHTTPError(w, ErrInvalidExportFormat, r)
+		return
+	}
+
+	filename := "users-" + time.Now().UTC().Format("20060102") + "." + extension
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	h.streamUsers(w, r, format, exportColumns)
+}
+
+// streamUsers writes all users as CSV or NDJSON rows while they are read.
+func (h *Handler) streamUsers(w http.ResponseWriter, r *http.Request, format string, columns []string) {
+	rows := utils.NewRowWriter(w, r, format, columns...)
+	err := h.service.EachUser(r.Context(), func(user *UserResponse) error {
+		return rows.Write(user)
+	})
+	if err == nil {
+		err = rows.Close()
+	}
+	if err != nil {
+		rows.Fail(err, r)
+	}
+}
+
+// @Summary Get user by ID
 func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r)
+
 	vars := mux.Vars(r)
 	id := vars["id"]
 
@@ -103,7 +211,10 @@ func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	utils.RespondJSON(w, http.StatusOK, user)
 }
 
+// @Summary Get current authenticated user
 func (h *Handler) GetLoginUser(w http.ResponseWriter, r *http.Request) {
+	w = utils.WithProjection(w, r)
+
 	user, err := h.service.GetLoginUser(r.Context())
 	if err != nil {
 		utils.HandleHTTPError(w, err, r)
@@ -129,10 +240,9 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only SUPER_ADMIN can update users
-	userCtx, ok := middleware.GetUserFromContext(r.Context())
-	if !ok || userCtx.Role != "SUPER_ADMIN" {
-		utils.HandleHTTPError(w, utils.ErrForbidden, r)
+	// Only SUPER_ADMIN can update users, per the access policy
+	if err := middleware.Authorize(r.Context(), "update", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
 		return
 	}
 
@@ -153,10 +263,9 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Only SUPER_ADMIN can delete users
-	userCtx, ok := middleware.GetUserFromContext(r.Context())
-	if !ok || userCtx.Role != "SUPER_ADMIN" {
-		utils.HandleHTTPError(w, utils.ErrForbidden, r)
+	// Only SUPER_ADMIN can delete users, per the access policy
+	if err := middleware.Authorize(r.Context(), "delete", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
 		return
 	}
 
@@ -172,6 +281,49 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Deactivate a user
+// @Description Signs the user out and rejects their logins, keeping the user and the reports they
+// @Description created. Safer than deleting them.
+func (h *Handler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	// Only SUPER_ADMIN can deactivate users, per the access policy
+	if err := middleware.Authorize(r.Context(), "update", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	response, err := h.service.DeactivateUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "User deactivated",
+		"user":    response,
+	})
+}
+
+// @Summary Activate a user
+// @Description Lets a deactivated user log in again. The tokens they had stay rejected.
+func (h *Handler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	// Only SUPER_ADMIN can activate users, per the access policy
+	if err := middleware.Authorize(r.Context(), "update", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	response, err := h.service.ActivateUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "User activated",
+		"user":    response,
+	})
+}
+
 // UpdateRole updates a user's role
 func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 	var req UpdateRoleRequest
@@ -185,10 +337,9 @@ func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only SUPER_ADMIN can update user roles
-	userCtx, ok := middleware.GetUserFromContext(r.Context())
-	if !ok || userCtx.Role != "SUPER_ADMIN" {
-		utils.HandleHTTPError(w, utils.ErrForbidden, r)
+	// Only SUPER_ADMIN can update user roles, per the access policy
+	if err := middleware.Authorize(r.Context(), "manage", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
 		return
 	}
 
@@ -204,6 +355,43 @@ func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Assign roles to many users
+// @Description Applies every {userId, role} assignment in one transaction, or none of them when
+// @Description one is invalid, e.g. names a missing user. Each result says whether the role was
+// @Description updated or unchanged, or why the assignment is invalid; nothing is applied and
+// @Description the response is 422 when one is.
+func (h *Handler) UpdateRoles(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRolesRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	// Only SUPER_ADMIN can update user roles, per the access policy
+	if err := middleware.Authorize(r.Context(), "manage", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	response, err := h.service.UpdateRoles(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	status := http.StatusOK
+	if !response.Applied {
+		status = http.StatusUnprocessableEntity
+	}
+	utils.RespondJSON(w, status, response)
+}
+
+// @Summary Change current user password
 func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	var req ChangePasswordRequest
 	if err := utils.DecodeJSON(r, &req); err != nil {
@@ -225,3 +413,84 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		"message": "Password successfully changed",
 	})
 }
+
+// GetPreferences returns the logged-in user's language, timezone, default currency and
+// notification settings
+func (h *Handler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	preferences, err := h.service.GetPreferences(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, preferences)
+}
+
+// UpdatePreferences updates the logged-in user's preferences, keeping those left out
+func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePreferencesRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, preferences)
+}
+
+// UploadAvatar sets the logged-in user's avatar from a JPEG, PNG or GIF in the multipart field "file"
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	file, contentType, err := utils.MultipartFile(r, "file")
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	user, err := h.service.UploadAvatar(r.Context(), file, contentType)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, user)
+}
+
+// DeleteAvatar removes the logged-in user's avatar
+func (h *Handler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.DeleteAvatar(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, user)
+}
+
+// @Summary Impersonate a user
+// @Description Returns a read-only token acting as the user, valid for JWT_IMPERSONATION_TTL, so
+// @Description support can see what they see. Requests made with it are flagged in the audit log.
+func (h *Handler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	// Only SUPER_ADMIN can impersonate users, per the access policy
+	if err := middleware.Authorize(r.Context(), "impersonate", policy.Resource{Type: "user"}); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	response, err := h.service.Impersonate(r.Context(), mux.Vars(r)["userId"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, response)
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
 	"time" // ✅ Added missing import
 )
 
@@ -9,15 +10,19 @@ import (
 type CreateUserRequest struct {
 	Name     string `json:"name" validate:"required,min=2,max=50"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"` // checked against the password policy
 	Role     string `json:"role" validate:"required,oneof=SUPER_ADMIN ADMIN CLIENT"`
+	Locale   string `json:"locale,omitempty" validate:"omitempty,oneof=en id"`
+	Phone    string `json:"phone,omitempty" validate:"omitempty,e164"`
 }
 
 type UpdateUserRequest struct {
 	Name     *string `json:"name,omitempty" validate:"omitempty,min=2,max=50"`
 	Email    *string `json:"email,omitempty" validate:"omitempty,email"`
-	Password *string `json:"password,omitempty" validate:"omitempty,min=6"`
+	Password *string `json:"password,omitempty"`
 	Role     *string `json:"role,omitempty" validate:"omitempty,oneof=SUPER_ADMIN ADMIN CLIENT"`
+	Locale   *string `json:"locale,omitempty" validate:"omitempty,oneof=en id"`
+	Phone    *string `json:"phone,omitempty" validate:"omitempty,e164"`
 }
 
 type UpdateRoleRequest struct {
@@ -25,20 +30,121 @@ type UpdateRoleRequest struct {
 	NewRole string `json:"newRole" validate:"required,oneof=SUPER_ADMIN ADMIN CLIENT"`
 }
 
+// UpdateRolesRequest assigns roles to many users at once: all of them, or none when one of
+// the assignments is invalid.
+type UpdateRolesRequest struct {
+	Assignments []RoleAssignment `json:"assignments" validate:"required,min=1,max=500,dive"`
+}
+
+type RoleAssignment struct {
+	UserID string `json:"userId" validate:"required"`
+	Role   string `json:"role" validate:"required,oneof=SUPER_ADMIN ADMIN CLIENT"`
+}
+
 type ChangePasswordRequest struct {
-	NewPassword     string `json:"newPassword" validate:"required,min=6"`
-	ConfirmPassword string `json:"confirmPassword" validate:"required,min=6"`
+	NewPassword     string `json:"newPassword" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required"`
+}
+
+// UpdatePreferencesRequest changes the preferences present; an empty timezone or currency
+// goes back to the default.
+type UpdatePreferencesRequest struct {
+	Language      *string                         `json:"language,omitempty" validate:"omitempty,oneof=en id"`
+	Timezone      *string                         `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Currency      *string                         `json:"currency,omitempty" validate:"omitempty,len=3,uppercase"`
+	Notifications *NotificationPreferencesRequest `json:"notifications,omitempty"`
+}
+
+type NotificationPreferencesRequest struct {
+	WeeklyDigest *bool   `json:"weeklyDigest,omitempty"`
+	Channel      *string `json:"channel,omitempty" validate:"omitempty,oneof=email sms whatsapp"`
 }
 
 // Response DTOs
 type UserResponse struct {
-	ID        string    `json:"_id"` // ✅ Changed to "_id" like legacy
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	Company   []string  `json:"company"`
-	CreatedAt time.Time `json:"createdAt"` // ✅ Added missing field
-	UpdatedAt time.Time `json:"updatedAt"` // ✅ Added missing field
+	ID          string          `json:"_id"` // ✅ Changed to "_id" like legacy
+	Name        string          `json:"name"`
+	Email       string          `json:"email"`
+	Role        domain.UserRole `json:"role"`
+	Company     []string        `json:"company"`
+	Locale      string          `json:"locale,omitempty"`
+	Phone       string          `json:"phone,omitempty"`
+	Avatar      string          `json:"avatar,omitempty"`
+	AvatarThumb string          `json:"avatarThumb,omitempty"`
+	Active      bool            `json:"active"`
+	CreatedAt   time.Time       `json:"createdAt"` // ✅ Added missing field
+	UpdatedAt   time.Time       `json:"updatedAt"` // ✅ Added missing field
+
+	// Organization is the ID of the user's organization, omitted for users of the instance
+	Organization string `json:"organization,omitempty"`
+	// InvitedAt is set while the user has not accepted their invitation yet
+	InvitedAt *time.Time `json:"invitedAt,omitempty"`
+}
+
+// Role assignment statuses
+const (
+	RoleUpdated   = "updated"
+	RoleUnchanged = "unchanged" // the user already had the role
+	RoleInvalid   = "invalid"
+	RoleSkipped   = "skipped" // valid, but not applied because another assignment is invalid
+)
+
+// UpdateRolesResponse reports each assignment in the order of the request.
+type UpdateRolesResponse struct {
+	Applied bool                   `json:"applied"`
+	Results []RoleAssignmentResult `json:"results"`
+}
+
+type RoleAssignmentResult struct {
+	UserID string               `json:"userId"`
+	Role   string               `json:"role"`
+	Status string               `json:"status"`          // updated, unchanged, invalid or skipped
+	Error  *utils.ErrorResponse `json:"error,omitempty"` // when the assignment is invalid
+	User   *UserResponse        `json:"user,omitempty"`  // once applied
+}
+
+// ImpersonationResponse is a token acting as the user, for a super admin to see what they see.
+type ImpersonationResponse struct {
+	AccessToken string       `json:"access_token"`
+	ExpiresIn   int          `json:"expires_in"` // seconds
+	User        UserResponse `json:"user"`
+}
+
+// ImpersonationEvent is the payload of user.impersonated events.
+type ImpersonationEvent struct {
+	UserID       string    `json:"userId"`
+	Impersonator string    `json:"impersonator"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+type PreferencesResponse struct {
+	Language      string                          `json:"language"`
+	Timezone      string                          `json:"timezone"`
+	Currency      string                          `json:"currency"` // empty when reports without a currency show none
+	Notifications NotificationPreferencesResponse `json:"notifications"`
+}
+
+type NotificationPreferencesResponse struct {
+	WeeklyDigest bool                       `json:"weeklyDigest"`
+	Channel      domain.NotificationChannel `json:"channel"`
+}
+
+func ToPreferencesResponse(user *domain.User) PreferencesResponse {
+	preferences := user.Preferences
+	channel := preferences.Notifications.Channel
+	if channel == "" {
+		channel = domain.ChannelEmail
+	}
+
+	return PreferencesResponse{
+		Language: utils.NormalizeLocale(user.Locale),
+		Timezone: preferences.Location().String(),
+		Currency: preferences.Currency,
+		Notifications: NotificationPreferencesResponse{
+			WeeklyDigest: !preferences.Notifications.WeeklyDigestDisabled,
+			Channel:      channel,
+		},
+	}
 }
 
 // Helper to convert domain.User to UserResponse
@@ -48,13 +154,23 @@ func ToUserResponse(user *domain.User) UserResponse {
 		companyIDs[i] = id.Hex()
 	}
 
-	return UserResponse{
-		ID:        user.ID.Hex(),
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      string(user.Role),
-		Company:   companyIDs,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	response := UserResponse{
+		ID:          user.ID.Hex(),
+		Name:        user.Name,
+		Email:       user.Email,
+		Role:        user.Role,
+		Company:     companyIDs,
+		Locale:      user.Locale,
+		Active:      user.Active(),
+		InvitedAt:   user.InvitedAt,
+		Phone:       user.Phone,
+		Avatar:      user.Avatar,
+		AvatarThumb: user.AvatarThumb,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+	if user.Organization != nil {
+		response.Organization = user.Organization.Hex()
 	}
+	return response
 }
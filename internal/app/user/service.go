@@ -2,33 +2,62 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/storage"
 	"finsolvz-backend/internal/utils"
 	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
 )
 
 type Service interface {
 	CreateUser(ctx context.Context, req CreateUserRequest) (*UserResponse, error)
-	GetUsers(ctx context.Context) ([]*UserResponse, error)
+	// GetUsers returns a page of the users matching query and how many match in all
+	GetUsers(ctx context.Context, query domain.UserQuery) ([]*UserResponse, int, error)
+	// EachUser calls fn with the users of GetUsers one at a time as they are read
+	EachUser(ctx context.Context, fn func(*UserResponse) error) error
 	GetUserByID(ctx context.Context, id string) (*UserResponse, error)
 	GetLoginUser(ctx context.Context) (*UserResponse, error)
 	UpdateUser(ctx context.Context, id string, req UpdateUserRequest) (*UserResponse, error)
 	DeleteUser(ctx context.Context, id string) (*UserResponse, error)
+	// DeactivateUser keeps the user, and the reports they created, but signs them out and
+	// rejects their logins and tokens until ActivateUser.
+	DeactivateUser(ctx context.Context, id string) (*UserResponse, error)
+	ActivateUser(ctx context.Context, id string) (*UserResponse, error)
 	UpdateRole(ctx context.Context, req UpdateRoleRequest) (*UserResponse, error)
+	// UpdateRoles applies every assignment in one transaction, or none of them when one is
+	// invalid, and reports on each
+	UpdateRoles(ctx context.Context, req UpdateRolesRequest) (*UpdateRolesResponse, error)
 	ChangePassword(ctx context.Context, req ChangePasswordRequest) error
+	GetPreferences(ctx context.Context) (*PreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, req UpdatePreferencesRequest) (*PreferencesResponse, error)
+	// UploadAvatar processes an image upload into the logged-in user's avatar
+	UploadAvatar(ctx context.Context, r io.Reader, contentType string) (*UserResponse, error)
+	DeleteAvatar(ctx context.Context) (*UserResponse, error)
+	// Impersonate issues the logged-in super admin a short-lived, read-only token acting as
+	// the user, recorded as a user.impersonated event.
+	Impersonate(ctx context.Context, id string) (*ImpersonationResponse, error)
 }
 
 type service struct {
-	userRepo domain.UserRepository
+	userRepo   domain.UserRepository
+	outboxRepo domain.OutboxRepository
+	tx         domain.Transactor
+	store      storage.ObjectStore
 }
 
-func NewService(userRepo domain.UserRepository) Service {
+func NewService(userRepo domain.UserRepository, outboxRepo domain.OutboxRepository, tx domain.Transactor, store storage.ObjectStore) Service {
 	return &service{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		outboxRepo: outboxRepo,
+		tx:         tx,
+		store:      store,
 	}
 }
 
@@ -38,6 +67,9 @@ func (s *service) CreateUser(ctx context.Context, req CreateUserRequest) (*UserR
 		return nil, errors.New("USER_ALREADY_EXISTS", "Email already registered", 409, nil, nil)
 	}
 
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, err
@@ -49,6 +81,8 @@ func (s *service) CreateUser(ctx context.Context, req CreateUserRequest) (*UserR
 		Password: hashedPassword,
 		Role:     domain.UserRole(req.Role),
 		Company:  []primitive.ObjectID{},
+		Locale:   req.Locale,
+		Phone:    req.Phone,
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
@@ -59,10 +93,10 @@ func (s *service) CreateUser(ctx context.Context, req CreateUserRequest) (*UserR
 	return &response, nil
 }
 
-func (s *service) GetUsers(ctx context.Context) ([]*UserResponse, error) {
-	users, err := s.userRepo.GetAll(ctx)
+func (s *service) GetUsers(ctx context.Context, query domain.UserQuery) ([]*UserResponse, int, error) {
+	users, total, err := s.userRepo.GetPage(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	responses := make([]*UserResponse, len(users))
@@ -71,7 +105,14 @@ func (s *service) GetUsers(ctx context.Context) ([]*UserResponse, error) {
 		responses[i] = &response
 	}
 
-	return responses, nil
+	return responses, total, nil
+}
+
+func (s *service) EachUser(ctx context.Context, fn func(*UserResponse) error) error {
+	return s.userRepo.Each(ctx, func(user *domain.User) error {
+		response := ToUserResponse(user)
+		return fn(&response)
+	})
 }
 
 func (s *service) GetUserByID(ctx context.Context, id string) (*UserResponse, error) {
@@ -137,7 +178,16 @@ func (s *service) UpdateUser(ctx context.Context, id string, req UpdateUserReque
 	if req.Role != nil {
 		user.Role = domain.UserRole(*req.Role)
 	}
+	if req.Locale != nil {
+		user.Locale = *req.Locale
+	}
+	if req.Phone != nil {
+		user.Phone = *req.Phone
+	}
 	if req.Password != nil {
+		if err := utils.ValidatePasswordChange(*req.Password, user.Password); err != nil {
+			return nil, err
+		}
 		hashedPassword, err := utils.HashPassword(*req.Password)
 		if err != nil {
 			return nil, err
@@ -145,7 +195,7 @@ func (s *service) UpdateUser(ctx context.Context, id string, req UpdateUserReque
 		user.Password = hashedPassword
 	}
 
-	if err := s.userRepo.Update(ctx, objectID, user); err != nil {
+	if err := s.updateWithEvent(ctx, objectID, user); err != nil {
 		return nil, err
 	}
 
@@ -167,6 +217,57 @@ func (s *service) DeleteUser(ctx context.Context, id string) (*UserResponse, err
 	if err := s.userRepo.Delete(ctx, objectID); err != nil {
 		return nil, err
 	}
+	middleware.InvalidateResponses(middleware.ResponseCacheCompanies, middleware.ResponseCacheReports)
+
+	response := ToUserResponse(user)
+	return &response, nil
+}
+
+func (s *service) DeactivateUser(ctx context.Context, id string) (*UserResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_USER_ID", "Invalid user ID format", 400, err, nil)
+	}
+	if userCtx, ok := middleware.GetUserFromContext(ctx); ok && userCtx.UserID == id {
+		return nil, ErrDeactivateSelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeactivatedAt == nil {
+		// Tokens issued before deactivation stay rejected once the user is activated again
+		now := time.Now()
+		user.DeactivatedAt = &now
+		user.SessionsRevokedAt = &now
+		if err := s.updateWithEvent(ctx, objectID, user); err != nil {
+			return nil, err
+		}
+	}
+
+	response := ToUserResponse(user)
+	return &response, nil
+}
+
+func (s *service) ActivateUser(ctx context.Context, id string) (*UserResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_USER_ID", "Invalid user ID format", 400, err, nil)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeactivatedAt != nil {
+		user.DeactivatedAt = nil
+		if err := s.updateWithEvent(ctx, objectID, user); err != nil {
+			return nil, err
+		}
+	}
 
 	response := ToUserResponse(user)
 	return &response, nil
@@ -184,8 +285,12 @@ func (s *service) UpdateRole(ctx context.Context, req UpdateRoleRequest) (*UserR
 	}
 
 	user.Role = domain.UserRole(req.NewRole)
+	// Super admins administer the whole instance, so they can't stay in an organization
+	if user.Role == domain.RoleSuperAdmin && user.Organization != nil {
+		return nil, ErrSuperAdminInOrganization
+	}
 
-	if err := s.userRepo.Update(ctx, objectID, user); err != nil {
+	if err := s.updateWithEvent(ctx, objectID, user); err != nil {
 		return nil, err
 	}
 
@@ -193,6 +298,110 @@ func (s *service) UpdateRole(ctx context.Context, req UpdateRoleRequest) (*UserR
 	return &response, nil
 }
 
+func (s *service) UpdateRoles(ctx context.Context, req UpdateRolesRequest) (*UpdateRolesResponse, error) {
+	results := make([]RoleAssignmentResult, len(req.Assignments))
+	ids := make([]primitive.ObjectID, len(req.Assignments))
+	unique := make([]primitive.ObjectID, 0, len(req.Assignments))
+	seen := make(map[primitive.ObjectID]bool, len(req.Assignments))
+	for i, assignment := range req.Assignments {
+		results[i] = RoleAssignmentResult{UserID: assignment.UserID, Role: assignment.Role}
+		id, err := primitive.ObjectIDFromHex(assignment.UserID)
+		switch {
+		case err != nil:
+			results[i].fail(errors.New("INVALID_USER_ID", "Invalid user ID format", 400, err, nil))
+		case seen[id]:
+			results[i].fail(ErrDuplicateAssignment)
+		default:
+			ids[i] = id
+			unique = append(unique, id)
+			seen[id] = true
+		}
+	}
+
+	found, err := s.userRepo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[primitive.ObjectID]*domain.User, len(found))
+	for _, user := range found {
+		users[user.ID] = user
+	}
+
+	valid := true
+	for i, assignment := range req.Assignments {
+		if results[i].Status == RoleInvalid {
+			valid = false
+			continue
+		}
+		user, ok := users[ids[i]]
+		switch {
+		case !ok:
+			results[i].fail(ErrUserNotFound)
+		case domain.UserRole(assignment.Role) == domain.RoleSuperAdmin && user.Organization != nil:
+			results[i].fail(ErrSuperAdminInOrganization)
+		default:
+			continue
+		}
+		valid = false
+	}
+
+	if !valid {
+		for i := range results {
+			if results[i].Status != RoleInvalid {
+				results[i].Status = RoleSkipped
+			}
+		}
+		return &UpdateRolesResponse{Applied: false, Results: results}, nil
+	}
+
+	for i, assignment := range req.Assignments {
+		user := users[ids[i]]
+		results[i].Status = RoleUnchanged
+		if user.Role != domain.UserRole(assignment.Role) {
+			user.Role = domain.UserRole(assignment.Role)
+			results[i].Status = RoleUpdated
+		}
+	}
+
+	// The function may run again when the transaction is retried, so it only writes
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		for i := range results {
+			if results[i].Status != RoleUpdated {
+				continue
+			}
+			user := users[ids[i]]
+			if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+				return err
+			}
+
+			event, err := domain.NewEvent(domain.EventUserUpdated, user.ID, ToUserResponse(user))
+			if err != nil {
+				return errors.New("EVENT_ENCODING_ERROR", "Failed to encode user event", 500, err, nil)
+			}
+			if err := s.outboxRepo.Append(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	middleware.InvalidateResponses(middleware.ResponseCacheCompanies, middleware.ResponseCacheReports)
+
+	for i := range results {
+		response := ToUserResponse(users[ids[i]])
+		results[i].User = &response
+	}
+	return &UpdateRolesResponse{Applied: true, Results: results}, nil
+}
+
+// fail marks the assignment invalid because of err.
+func (r *RoleAssignmentResult) fail(err errors.AppError) {
+	r.Status = RoleInvalid
+	r.Error = &utils.ErrorResponse{Code: err.Code(), Message: err.Message()}
+}
+
 func (s *service) ChangePassword(ctx context.Context, req ChangePasswordRequest) error {
 	if req.NewPassword != req.ConfirmPassword {
 		return ErrPasswordMismatch
@@ -212,6 +421,9 @@ func (s *service) ChangePassword(ctx context.Context, req ChangePasswordRequest)
 	if err != nil {
 		return err
 	}
+	if err := utils.ValidatePasswordChange(req.NewPassword, user.Password); err != nil {
+		return err
+	}
 
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
@@ -221,3 +433,183 @@ func (s *service) ChangePassword(ctx context.Context, req ChangePasswordRequest)
 	user.Password = hashedPassword
 	return s.userRepo.Update(ctx, objectID, user)
 }
+
+// GetPreferences returns the logged-in user's preferences
+func (s *service) GetPreferences(ctx context.Context) (*PreferencesResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ToPreferencesResponse(user)
+	return &response, nil
+}
+
+// UpdatePreferences changes only the preferences present in req
+func (s *service) UpdatePreferences(ctx context.Context, req UpdatePreferencesRequest) (*PreferencesResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Language != nil {
+		user.Locale = *req.Language
+	}
+	if req.Timezone != nil {
+		user.Preferences.Timezone = *req.Timezone
+	}
+	if req.Currency != nil {
+		user.Preferences.Currency = *req.Currency
+	}
+	if n := req.Notifications; n != nil {
+		if n.WeeklyDigest != nil {
+			user.Preferences.Notifications.WeeklyDigestDisabled = !*n.WeeklyDigest
+		}
+		if n.Channel != nil {
+			user.Preferences.Notifications.Channel = domain.NotificationChannel(*n.Channel)
+		}
+	}
+
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return nil, err
+	}
+
+	response := ToPreferencesResponse(user)
+	return &response, nil
+}
+
+func (s *service) UploadAvatar(ctx context.Context, r io.Reader, contentType string) (*UserResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new key per upload lets the image handler cache responses forever
+	prefix := fmt.Sprintf("images/users/%s/avatar-%d", user.ID.Hex(), time.Now().UnixNano())
+	avatar, err := storage.UploadImage(ctx, s.store, prefix, r, contentType, storage.AvatarPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := []string{user.Avatar, user.AvatarThumb}
+	user.Avatar = storage.ImagePath(avatar.Full.Key)
+	user.AvatarThumb = storage.ImagePath(avatar.Thumb.Key)
+
+	if err := s.updateWithEvent(ctx, user.ID, user); err != nil {
+		s.deleteImages(ctx, user.Avatar, user.AvatarThumb)
+		return nil, err
+	}
+	s.deleteImages(ctx, previous...)
+
+	response := ToUserResponse(user)
+	return &response, nil
+}
+
+func (s *service) DeleteAvatar(ctx context.Context) (*UserResponse, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := []string{user.Avatar, user.AvatarThumb}
+	user.Avatar, user.AvatarThumb = "", ""
+
+	if err := s.updateWithEvent(ctx, user.ID, user); err != nil {
+		return nil, err
+	}
+	s.deleteImages(ctx, previous...)
+
+	response := ToUserResponse(user)
+	return &response, nil
+}
+
+// deleteImages removes replaced avatar variants. Failures only leave orphaned objects,
+// so they are logged and ignored.
+func (s *service) deleteImages(ctx context.Context, paths ...string) {
+	for _, p := range paths {
+		if key, ok := storage.ImageKey(p); ok {
+			if err := s.store.Delete(ctx, key); err != nil {
+				log.Warnf(ctx, "Failed to delete image %s: %v", key, err)
+			}
+		}
+	}
+}
+
+func (s *service) Impersonate(ctx context.Context, id string) (*ImpersonationResponse, error) {
+	impersonator, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("INVALID_USER_ID", "Invalid user ID format", 400, err, nil)
+	}
+	user, err := s.userRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	// Acting as another super admin would be no less powerful than being one
+	if user.ID == impersonator.ID || user.Role == domain.RoleSuperAdmin {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	token, claims, err := utils.IssueImpersonationJWT(user.ID.Hex(), string(user.Role), user.OrganizationClaim(), impersonator.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := domain.NewEvent(domain.EventUserImpersonated, user.ID, ImpersonationEvent{
+		UserID:       user.ID.Hex(),
+		Impersonator: impersonator.ID.Hex(),
+		ExpiresAt:    claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return nil, errors.New("EVENT_ENCODING_ERROR", "Failed to encode user event", 500, err, nil)
+	}
+	event.Impersonator = &impersonator.ID
+	if err := s.outboxRepo.Append(ctx, event); err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "User %s impersonates user %s until %s", impersonator.ID.Hex(), user.ID.Hex(), claims.ExpiresAt.Time.Format(time.RFC3339))
+
+	return &ImpersonationResponse{
+		AccessToken: token,
+		ExpiresIn:   int(time.Until(claims.ExpiresAt.Time).Seconds()),
+		User:        ToUserResponse(user),
+	}, nil
+}
+
+func (s *service) currentUser(ctx context.Context) (*domain.User, error) {
+	userCtx, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("USER_CONTEXT_MISSING", "User context not found", 401, nil, nil)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userCtx.UserID)
+	if err != nil {
+		return nil, errors.New("INVALID_USER_ID", "Invalid user ID in context", 400, err, nil)
+	}
+
+	return s.userRepo.GetByID(ctx, objectID)
+}
+
+// updateWithEvent saves the user and records a user.updated event in the same transaction.
+// Companies and reports render their users, so their cached responses are dropped.
+func (s *service) updateWithEvent(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, id, user); err != nil {
+			return err
+		}
+
+		event, err := domain.NewEvent(domain.EventUserUpdated, id, ToUserResponse(user))
+		if err != nil {
+			return errors.New("EVENT_ENCODING_ERROR", "Failed to encode user event", 500, err, nil)
+		}
+		return s.outboxRepo.Append(ctx, event)
+	})
+	if err == nil {
+		middleware.InvalidateResponses(middleware.ResponseCacheCompanies, middleware.ResponseCacheReports)
+	}
+	return err
+}
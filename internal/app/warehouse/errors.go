@@ -0,0 +1,10 @@
+package warehouse
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrSyncRunning = errors.New("WAREHOUSE_SYNC_RUNNING", "A warehouse export is already running", http.StatusConflict, nil, nil)
+)
@@ -0,0 +1,55 @@
+package warehouse
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers data warehouse export routes
+// @Tags Administration
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "warehouse"))
+
+	adminOnly.HandleFunc("/api/admin/warehouse", h.GetStatus).Methods("GET")
+	adminOnly.HandleFunc("/api/admin/warehouse/sync", h.Sync).Methods("POST")
+}
+
+// GetStatus returns how far companies and reports have been exported to the warehouse, and
+// whether an export is running
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.Status(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, status)
+}
+
+// Sync exports the companies and reports changed since the last export now, without waiting
+// for the next scheduled one
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.Sync(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, result)
+}
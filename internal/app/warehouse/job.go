@@ -0,0 +1,48 @@
+package warehouse
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Job exports the changes to the warehouse on a fixed interval.
+type Job struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewJob(service Service, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run exports once at start, catching up on changes made while stopped, and then until ctx
+// is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := j.service.Sync(ctx)
+		if err != nil && err != ErrSyncRunning {
+			log.Errorf(ctx, "Warehouse: export failed: %v", err)
+		}
+		if result != nil {
+			for _, source := range result.Sources {
+				if source.Documents > 0 {
+					log.Infof(ctx, "Warehouse: exported %d %s as %d rows to %s", source.Documents, source.Source, source.Rows, result.Sink)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package warehouse
+
+import (
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Response DTOs
+type SyncResult struct {
+	Sink       string         `json:"sink"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	Sources    []SourceResult `json:"sources"`
+}
+
+// SourceResult counts the rows one run exported from a source.
+type SourceResult struct {
+	Source    string                    `json:"source"`
+	Documents int                       `json:"documents"`
+	Rows      int                       `json:"rows"` // line items included
+	Watermark domain.WarehouseWatermark `json:"watermark"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+type StatusResponse struct {
+	Sink    string                   `json:"sink"`
+	Running bool                     `json:"running"`
+	Sources []*domain.WarehouseState `json:"sources"`
+}
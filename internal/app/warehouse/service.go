@@ -0,0 +1,254 @@
+package warehouse
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/warehouse"
+)
+
+// batchSize is how many documents are read and written at a time; progress is saved after each
+// batch, so a failed run resumes where it stopped.
+const batchSize = 500
+
+var (
+	companiesTable = warehouse.Table{Name: "companies", Columns: []warehouse.Column{
+		{Name: "id", Type: warehouse.TypeString},
+		{Name: "name", Type: warehouse.TypeString},
+		{Name: "organization", Type: warehouse.TypeString},
+		{Name: "jurisdiction", Type: warehouse.TypeString},
+		{Name: "created_at", Type: warehouse.TypeTimestamp},
+		{Name: "updated_at", Type: warehouse.TypeTimestamp},
+		{Name: "deleted", Type: warehouse.TypeBoolean},
+		{Name: "deleted_at", Type: warehouse.TypeTimestamp},
+		{Name: "exported_at", Type: warehouse.TypeTimestamp},
+	}}
+	reportsTable = warehouse.Table{Name: "reports", Columns: []warehouse.Column{
+		{Name: "id", Type: warehouse.TypeString},
+		{Name: "name", Type: warehouse.TypeString},
+		{Name: "company", Type: warehouse.TypeString},
+		{Name: "report_type", Type: warehouse.TypeString},
+		{Name: "year", Type: warehouse.TypeInteger},
+		{Name: "currency", Type: warehouse.TypeString},
+		{Name: "created_by", Type: warehouse.TypeString},
+		{Name: "created_at", Type: warehouse.TypeTimestamp},
+		{Name: "updated_at", Type: warehouse.TypeTimestamp},
+		{Name: "deleted", Type: warehouse.TypeBoolean},
+		{Name: "deleted_at", Type: warehouse.TypeTimestamp},
+		{Name: "exported_at", Type: warehouse.TypeTimestamp},
+	}}
+	// Line items of a report are exported whole whenever it changes; those sharing the latest
+	// report_updated_at of a report are current
+	lineItemsTable = warehouse.Table{Name: "report_line_items", Columns: []warehouse.Column{
+		{Name: "report_id", Type: warehouse.TypeString},
+		{Name: "report_updated_at", Type: warehouse.TypeTimestamp},
+		{Name: "company", Type: warehouse.TypeString},
+		{Name: "year", Type: warehouse.TypeInteger},
+		{Name: "line_item", Type: warehouse.TypeString},
+		{Name: "amount", Type: warehouse.TypeFloat},
+		{Name: "exported_at", Type: warehouse.TypeTimestamp},
+	}}
+)
+
+type Service interface {
+	// Sync exports the companies and reports changed since the last export.
+	Sync(ctx context.Context) (*SyncResult, error)
+	Status(ctx context.Context) (*StatusResponse, error)
+}
+
+type service struct {
+	warehouseRepo domain.WarehouseRepository
+	sink          warehouse.Sink
+
+	running sync.Mutex
+}
+
+func NewService(warehouseRepo domain.WarehouseRepository, sink warehouse.Sink) Service {
+	return &service{
+		warehouseRepo: warehouseRepo,
+		sink:          sink,
+	}
+}
+
+func (s *service) Sync(ctx context.Context) (*SyncResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrSyncRunning
+	}
+	defer s.running.Unlock()
+
+	result := &SyncResult{Sink: s.sink.Name(), StartedAt: time.Now(), Sources: []SourceResult{}}
+	var firstErr error
+	for _, source := range []string{domain.WarehouseSourceCompanies, domain.WarehouseSourceReports} {
+		sourceResult, err := s.syncSource(ctx, source)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		result.Sources = append(result.Sources, *sourceResult)
+	}
+	result.FinishedAt = time.Now()
+
+	return result, firstErr
+}
+
+// syncSource exports one source batch by batch, saving progress after each.
+func (s *service) syncSource(ctx context.Context, source string) (*SourceResult, error) {
+	state, err := s.warehouseRepo.GetState(ctx, source)
+	if err != nil {
+		return &SourceResult{Source: source, Error: err.Error()}, err
+	}
+	result := &SourceResult{Source: source, Watermark: state.Watermark}
+
+	for {
+		documents, rows, next, err := s.exportBatch(ctx, source, state.Watermark)
+		now := time.Now()
+		state.LastRunAt = &now
+		state.LastError = ""
+		if err != nil {
+			state.LastError = err.Error()
+			result.Error = err.Error()
+			if saveErr := s.warehouseRepo.SaveState(ctx, state); saveErr != nil {
+				return result, saveErr
+			}
+			return result, err
+		}
+		if documents == 0 {
+			return result, s.warehouseRepo.SaveState(ctx, state)
+		}
+
+		state.Watermark, result.Watermark = next, next
+		state.Rows += int64(rows)
+		result.Documents += documents
+		result.Rows += rows
+		if err := s.warehouseRepo.SaveState(ctx, state); err != nil {
+			return result, err
+		}
+	}
+}
+
+// exportBatch writes the batch of a source following after, and returns the watermark past it.
+func (s *service) exportBatch(ctx context.Context, source string, after domain.WarehouseWatermark) (documents, rows int, next domain.WarehouseWatermark, err error) {
+	exportedAt := timestamp(time.Now())
+
+	switch source {
+	case domain.WarehouseSourceCompanies:
+		companies, err := s.warehouseRepo.CompaniesChangedAfter(ctx, after, batchSize)
+		if err != nil || len(companies) == 0 {
+			return 0, 0, after, err
+		}
+		companyRows := make([]warehouse.Row, len(companies))
+		for i, company := range companies {
+			companyRows[i] = warehouse.Row{
+				"id":           company.ID.Hex(),
+				"name":         company.Name,
+				"organization": hexOrNil(company.Organization),
+				"jurisdiction": company.Jurisdiction,
+				"created_at":   timestamp(company.CreatedAt),
+				"updated_at":   timestamp(company.UpdatedAt),
+				"deleted":      company.DeletedAt != nil,
+				"deleted_at":   optionalTimestamp(company.DeletedAt),
+				"exported_at":  exportedAt,
+			}
+		}
+		if err := s.sink.Write(ctx, companiesTable, companyRows); err != nil {
+			return 0, 0, after, err
+		}
+		last := companies[len(companies)-1]
+		next := domain.WarehouseWatermark{ChangedAt: domain.ChangedAt(last.UpdatedAt, last.DeletedAt), ID: last.ID}
+		return len(companies), len(companyRows), next, nil
+
+	case domain.WarehouseSourceReports:
+		reports, err := s.warehouseRepo.ReportsChangedAfter(ctx, after, batchSize)
+		if err != nil || len(reports) == 0 {
+			return 0, 0, after, err
+		}
+		reportRows := make([]warehouse.Row, len(reports))
+		lineItemRows := []warehouse.Row{}
+		for i, report := range reports {
+			reportRows[i] = warehouse.Row{
+				"id":          report.ID.Hex(),
+				"name":        report.ReportName,
+				"company":     report.Company.Hex(),
+				"report_type": report.ReportType.Hex(),
+				"year":        report.Year,
+				"currency":    report.Currency,
+				"created_by":  report.CreatedBy.Hex(),
+				"created_at":  timestamp(report.CreatedAt),
+				"updated_at":  timestamp(report.UpdatedAt),
+				"deleted":     report.DeletedAt != nil,
+				"deleted_at":  optionalTimestamp(report.DeletedAt),
+				"exported_at": exportedAt,
+			}
+			if report.DeletedAt != nil {
+				continue
+			}
+
+			items := domain.LineItems(report.ReportData)
+			names := make([]string, 0, len(items))
+			for name := range items {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				lineItemRows = append(lineItemRows, warehouse.Row{
+					"report_id":         report.ID.Hex(),
+					"report_updated_at": timestamp(report.UpdatedAt),
+					"company":           report.Company.Hex(),
+					"year":              report.Year,
+					"line_item":         name,
+					"amount":            items[name],
+					"exported_at":       exportedAt,
+				})
+			}
+		}
+		// Line items go first: should the reports fail, the batch is exported again whole
+		if len(lineItemRows) > 0 {
+			if err := s.sink.Write(ctx, lineItemsTable, lineItemRows); err != nil {
+				return 0, 0, after, err
+			}
+		}
+		if err := s.sink.Write(ctx, reportsTable, reportRows); err != nil {
+			return 0, 0, after, err
+		}
+		last := reports[len(reports)-1]
+		next := domain.WarehouseWatermark{ChangedAt: domain.ChangedAt(last.UpdatedAt, last.DeletedAt), ID: last.ID}
+		return len(reports), len(reportRows) + len(lineItemRows), next, nil
+	}
+
+	return 0, 0, after, nil
+}
+
+func (s *service) Status(ctx context.Context) (*StatusResponse, error) {
+	states, err := s.warehouseRepo.GetStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	running := !s.running.TryLock()
+	if !running {
+		s.running.Unlock()
+	}
+	return &StatusResponse{Sink: s.sink.Name(), Running: running, Sources: states}, nil
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func hexOrNil(id *primitive.ObjectID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.Hex()
+}
+
+func optionalTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return timestamp(*t)
+}
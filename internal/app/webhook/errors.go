@@ -0,0 +1,11 @@
+package webhook
+
+import (
+	"finsolvz-backend/internal/utils/errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidWebhookID = errors.New("INVALID_WEBHOOK_ID", "Invalid webhook ID format", http.StatusBadRequest, nil, nil)
+	ErrInvalidURL       = errors.New("INVALID_WEBHOOK_URL", "Webhook URL must be an absolute http or https URL", http.StatusBadRequest, nil, nil)
+)
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils"
+)
+
+type Handler struct {
+	service   Service
+	validator *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// RegisterRoutes registers webhook subscription routes
+// @Tags Webhooks
+func (h *Handler) RegisterRoutes(router *mux.Router, authMiddleware func(http.Handler) http.Handler) {
+	adminOnly := router.PathPrefix("").Subrouter()
+	adminOnly.Use(authMiddleware)
+	adminOnly.Use(middleware.RequirePermission("manage", "webhook"))
+
+	adminOnly.HandleFunc("/api/webhooks", h.GetWebhooks).Methods("GET")
+	adminOnly.HandleFunc("/api/webhooks", h.CreateWebhook).Methods("POST")
+	adminOnly.HandleFunc("/api/webhooks/{id}", h.GetWebhookByID).Methods("GET")
+	adminOnly.HandleFunc("/api/webhooks/{id}", h.UpdateWebhook).Methods("PUT")
+	adminOnly.HandleFunc("/api/webhooks/{id}", h.DeleteWebhook).Methods("DELETE")
+	adminOnly.HandleFunc("/api/webhooks/{id}/deliveries", h.GetDeliveries).Methods("GET")
+}
+
+// @Summary List webhook subscriptions
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetWebhooks(r.Context())
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers a subscription. The signing secret is only included in this response
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "Webhook created successfully",
+		"webhook": webhook,
+	})
+}
+
+// @Summary Get webhook subscription by ID
+func (h *Handler) GetWebhookByID(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.service.GetWebhookByID(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, webhook)
+}
+
+// @Summary Update webhook subscription
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req UpdateWebhookRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.HandleValidationError(w, err, r)
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Webhook updated successfully",
+		"webhook": webhook,
+	})
+}
+
+// @Summary Delete webhook subscription
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteWebhook(r.Context(), mux.Vars(r)["id"]); err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries returns the delivery log for a webhook
+func (h *Handler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.service.GetDeliveries(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		utils.HandleHTTPError(w, err, r)
+		return
+	}
+
+	utils.RespondJSON(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// Request DTOs
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string   `json:"url,omitempty" validate:"omitempty,url"`
+	Secret *string   `json:"secret,omitempty" validate:"omitempty,min=16"`
+	Events *[]string `json:"events,omitempty"`
+	Active *bool     `json:"active,omitempty"`
+}
+
+// Response DTOs
+type WebhookResponse struct {
+	ID        string    `json:"_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+type DeliveryResponse struct {
+	ID             string                `json:"_id"`
+	EventID        string                `json:"eventId"`
+	EventType      string                `json:"eventType"`
+	Status         domain.DeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastError      *string               `json:"lastError,omitempty"`
+	ResponseStatus int                   `json:"responseStatus,omitempty"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	Payload        json.RawMessage       `json:"payload"`
+}
+
+func ToWebhookResponse(subscription *domain.WebhookSubscription) WebhookResponse {
+	events := make([]string, len(subscription.Events))
+	for i, event := range subscription.Events {
+		events[i] = string(event)
+	}
+
+	return WebhookResponse{
+		ID:        subscription.ID.Hex(),
+		URL:       subscription.URL,
+		Events:    events,
+		Active:    subscription.Active,
+		CreatedBy: subscription.CreatedBy.Hex(),
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
+
+func ToDeliveryResponse(delivery *domain.WebhookDelivery) DeliveryResponse {
+	return DeliveryResponse{
+		ID:             delivery.ID.Hex(),
+		EventID:        delivery.EventID.Hex(),
+		EventType:      string(delivery.EventType),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		ResponseStatus: delivery.ResponseStatus,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        json.RawMessage(delivery.Payload),
+	}
+}
@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const deliveryLogLimit = 100
+
+type Service interface {
+	CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*WebhookResponse, error)
+	GetWebhooks(ctx context.Context) ([]*WebhookResponse, error)
+	GetWebhookByID(ctx context.Context, id string) (*WebhookResponse, error)
+	UpdateWebhook(ctx context.Context, id string, req UpdateWebhookRequest) (*WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, id string) ([]*DeliveryResponse, error)
+}
+
+type service struct {
+	webhookRepo  domain.WebhookRepository
+	deliveryRepo domain.WebhookDeliveryRepository
+}
+
+func NewService(webhookRepo domain.WebhookRepository, deliveryRepo domain.WebhookDeliveryRepository) Service {
+	return &service{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+	}
+}
+
+func (s *service) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*WebhookResponse, error) {
+	target, err := validateURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := parseEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	subscription := &domain.WebhookSubscription{
+		URL:    target,
+		Secret: secret,
+		Events: events,
+		Active: req.Active == nil || *req.Active,
+	}
+
+	if userCtx, ok := middleware.GetUserFromContext(ctx); ok {
+		if userID, err := primitive.ObjectIDFromHex(userCtx.UserID); err == nil {
+			subscription.CreatedBy = userID
+		}
+	}
+
+	if err := s.webhookRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	response := ToWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	return &response, nil
+}
+
+func (s *service) GetWebhooks(ctx context.Context) ([]*WebhookResponse, error) {
+	subscriptions, err := s.webhookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*WebhookResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		response := ToWebhookResponse(subscription)
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+func (s *service) GetWebhookByID(ctx context.Context, id string) (*WebhookResponse, error) {
+	subscription, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ToWebhookResponse(subscription)
+	return &response, nil
+}
+
+func (s *service) UpdateWebhook(ctx context.Context, id string, req UpdateWebhookRequest) (*WebhookResponse, error) {
+	subscription, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if subscription.URL, err = validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != nil {
+		subscription.Secret = *req.Secret
+	}
+	if req.Events != nil {
+		if subscription.Events, err = parseEvents(*req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.Update(ctx, subscription.ID, subscription); err != nil {
+		return nil, err
+	}
+
+	response := ToWebhookResponse(subscription)
+	return &response, nil
+}
+
+func (s *service) DeleteWebhook(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidWebhookID
+	}
+
+	return s.webhookRepo.Delete(ctx, objectID)
+}
+
+// GetDeliveries returns the most recent deliveries for a webhook, newest first
+func (s *service) GetDeliveries(ctx context.Context, id string) ([]*DeliveryResponse, error) {
+	subscription, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.deliveryRepo.ListBySubscription(ctx, subscription.ID, deliveryLogLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*DeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		response := ToDeliveryResponse(delivery)
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+func (s *service) getSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidWebhookID
+	}
+
+	return s.webhookRepo.GetByID(ctx, objectID)
+}
+
+func validateURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", ErrInvalidURL
+	}
+	return raw, nil
+}
+
+// parseEvents validates event filters against the known event types. An empty list means all events.
+func parseEvents(names []string) ([]domain.EventType, error) {
+	events := make([]domain.EventType, 0, len(names))
+	for _, name := range names {
+		event, ok := knownEvent(strings.TrimSpace(name))
+		if !ok {
+			return nil, errors.New("UNKNOWN_EVENT_TYPE", "Unknown event type", 400, nil, map[string]interface{}{
+				"event":   name,
+				"allowed": domain.EventTypes,
+			})
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func knownEvent(name string) (domain.EventType, bool) {
+	for _, event := range domain.EventTypes {
+		if string(event) == name {
+			return event, true
+		}
+	}
+	return "", false
+}
+
+func generateSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.New("RANDOM_GENERATION_ERROR", "Failed to generate webhook secret", 500, err, nil)
+	}
+	return hex.EncodeToString(bytes), nil
+}
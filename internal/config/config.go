@@ -0,0 +1,726 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/ai"
+	"finsolvz-backend/internal/platform/fx"
+	"finsolvz-backend/internal/platform/oauth"
+	"finsolvz-backend/internal/platform/policy"
+	"finsolvz-backend/internal/platform/search"
+	"finsolvz-backend/internal/platform/secrets"
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/platform/warehouse"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Config is every setting the server reads from the environment, loaded once at startup.
+type Config struct {
+	Env          string // APP_ENV: development, staging or production; see Profile
+	Port         string
+	Greeting     string
+	AppURL       string // base URL of the web app, used for links in emails
+	MetricsToken string
+	// JWT holds the keys tokens are signed and verified with
+	JWT utils.JWTConfig
+	// TokenLifetimes is how long tokens stay valid, and whether activity extends them
+	TokenLifetimes utils.TokenLifetimes
+	// RoleFromDatabase reads the role of token requests from the user rather than the token,
+	// so demotions and deletions take effect right away
+	RoleFromDatabase bool
+	Profiling        bool // PPROF_ENABLED: serve /debug/pprof and /debug/vars to super admins
+	LogLevel         log.LogLevel
+	Profile          Profile
+
+	// CORSAllowedOrigins is ["*"] unless set; staging and production require explicit origins
+	CORSAllowedOrigins []string
+
+	// Cookies configures the optional cookie sessions of the web dashboard
+	Cookies utils.CookieConfig
+	// PasswordPolicy is what new passwords must satisfy
+	PasswordPolicy utils.PasswordPolicy
+	// GoogleOAuth lets users sign in with their Google accounts; off without a client ID
+	GoogleOAuth oauth.GoogleConfig
+
+	// Policy holds the access rules of POLICY_FILE, or the built-in policy.csv when unset
+	Policy []policy.Rule
+
+	// RedisURL is the Redis server the caches and rate limits are shared through, so every
+	// instance sees the same state; each instance keeps its own when empty
+	RedisURL string
+
+	// RateLimitPerMinute is how many requests each client IP may send per minute
+	RateLimitPerMinute int
+	// APIKeys are the limits of API keys without limits of their own
+	APIKeys APIKeyConfig
+
+	Database  DatabaseConfig
+	Storage   storage.Config
+	Email     utils.EmailConfig
+	SMS       utils.SMSConfig
+	AI        ai.Config
+	FX        fx.Config
+	Search    search.Config
+	Warehouse warehouse.Config
+	Outbox    OutboxConfig
+	Jobs      JobsConfig
+	Anomaly   AnomalyConfig
+	HTTP      HTTPConfig
+
+	// Legal lists the current versions of the documents users must accept; none when empty
+	Legal []domain.LegalDocument
+
+	// Retention is the default retention policy; companies can override parts of it
+	Retention domain.RetentionPolicy
+	// Backups schedules automatic backups to the object store; a zero interval disables them
+	Backups domain.BackupSchedule
+	// Sandbox serves requests sent with the X-Sandbox header from a separate seeded database
+	Sandbox domain.SandboxConfig
+
+	secrets secrets.Provider // nil when every setting comes from the environment
+}
+
+type DatabaseConfig struct {
+	Driver               string
+	MongoURI             string
+	MongoDBName          string
+	CollectionPrefix     string
+	PostgresDSN          string
+	ReportReadPreference *readpref.ReadPref
+}
+
+type OutboxConfig struct {
+	WebhookURLs   []string
+	WebhookSecret string
+}
+
+// AnomalyConfig holds the thresholds of the report anomaly alerts.
+type AnomalyConfig struct {
+	MassDeleteThreshold int // reports one user may delete within MassDeleteWindow before admins are alerted; 0 disables
+	MassDeleteWindow    time.Duration
+}
+
+// APIKeyConfig holds the default limits of API keys.
+type APIKeyConfig struct {
+	RateLimitPerMinute int
+	DailyQuota         int // 0 is unlimited
+}
+
+// HTTPConfig tunes the HTTP server for the load balancer in front of it. The server must keep
+// idle connections open longer than the load balancer does, or the balancer may reuse a
+// connection the server is closing.
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration // how long idle keep-alive connections stay open
+	KeepAlives        bool
+	MaxHeaderBytes    int
+	// H2C serves HTTP/2 over cleartext, for load balancers that speak HTTP/2 to their backends
+	H2C                  bool
+	MaxConcurrentStreams int // per HTTP/2 connection
+	// ShutdownTimeout bounds how long shutdown waits for requests and running tasks to finish
+	ShutdownTimeout time.Duration
+}
+
+// JobsConfig holds the background job schedules. A zero interval disables the job.
+type JobsConfig struct {
+	IntegrityInterval        time.Duration
+	IntegrityAutoRepair      bool
+	DigestInterval           time.Duration
+	AccessEmailWindow        time.Duration
+	TaskWorkers              int // concurrent background task workers
+	RetentionInterval        time.Duration
+	RetentionDryRun          bool          // only report what the retention job would purge
+	ExportTTL                time.Duration // how long rendered exports can be downloaded
+	ExportExpiryInterval     time.Duration
+	DeadlineReminderInterval time.Duration
+	RateSyncInterval         time.Duration // exchange rates from FX_PROVIDER
+	WarehouseInterval        time.Duration // exports to WAREHOUSE_SINK
+}
+
+// IsDevelopment reports whether the server runs with APP_ENV=development.
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// Warnings lists settings that are valid but leave features degraded. Invalid settings
+// never get this far, since Load refuses them.
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if c.Env == "" {
+		warnings = append(warnings, "APP_ENV is not set, so no environment profile applies")
+	}
+	if c.AppURL == "" {
+		warnings = append(warnings, "APP_URL is not set, so emails contain no links to the app")
+	}
+	if c.Storage.Driver == storage.DriverLocal && c.Storage.BaseURL == "" {
+		warnings = append(warnings, "STORAGE_PUBLIC_URL is not set, so backup download links are unavailable")
+	}
+	if c.MetricsToken == "" {
+		warnings = append(warnings, "METRICS_TOKEN is not set, so /metrics is public")
+	}
+	return warnings
+}
+
+// Load reads and validates the configuration. Every missing or invalid setting is reported
+// at once, so a broken deployment can be fixed in a single pass.
+//
+// With SECRETS_PROVIDER set, credentials are read from the secret manager first and fall
+// back to the environment when the secret does not exist.
+func Load(ctx context.Context) (*Config, error) {
+	provider, err := secrets.NewProviderFromEnv()
+	if err != nil {
+		return nil, errors.New("CONFIG_INVALID", "Invalid configuration: SECRETS_PROVIDER "+message(err), 500, err, nil)
+	}
+	return load(ctx, provider)
+}
+
+// ReloadSecrets re-reads the configuration from the same sources and returns it along with
+// the names of the secrets whose values changed. The receiver is left untouched.
+func (c *Config) ReloadSecrets(ctx context.Context) (*Config, []string, error) {
+	next, err := load(ctx, c.secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changed []string
+	for key, values := range map[string][2]string{
+		"JWT_SECRET":                 {c.JWT.Secret, next.JWT.Secret},
+		"JWT_PREVIOUS_SECRETS":       {strings.Join(c.JWT.PreviousSecrets, ","), strings.Join(next.JWT.PreviousSecrets, ",")},
+		"JWT_PRIVATE_KEY":            {c.JWT.PrivateKey, next.JWT.PrivateKey},
+		"JWT_PREVIOUS_PUBLIC_KEYS":   {c.JWT.PreviousPublicKeys, next.JWT.PreviousPublicKeys},
+		"MONGO_URI":                  {c.Database.MongoURI, next.Database.MongoURI},
+		"POSTGRES_DSN":               {c.Database.PostgresDSN, next.Database.PostgresDSN},
+		"REDIS_URL":                  {c.RedisURL, next.RedisURL},
+		"NODEMAILER_EMAIL":           {c.Email.SMTPUsername, next.Email.SMTPUsername},
+		"NODEMAILER_PASS":            {c.Email.SMTPPassword, next.Email.SMTPPassword},
+		"SENDGRID_API_KEY":           {c.Email.SendGridAPIKey, next.Email.SendGridAPIKey},
+		"MAILGUN_API_KEY":            {c.Email.MailgunAPIKey, next.Email.MailgunAPIKey},
+		"TWILIO_AUTH_TOKEN":          {c.SMS.TwilioAuthToken, next.SMS.TwilioAuthToken},
+		"GEMINI_API_KEY":             {c.AI.APIKey, next.AI.APIKey},
+		"GOOGLE_OAUTH_CLIENT_SECRET": {c.GoogleOAuth.ClientSecret, next.GoogleOAuth.ClientSecret},
+		"OPENEXCHANGERATES_APP_ID":   {c.FX.OpenExchangeRatesAppID, next.FX.OpenExchangeRatesAppID},
+		"ELASTICSEARCH_API_KEY":      {c.Search.ElasticsearchAPIKey, next.Search.ElasticsearchAPIKey},
+		"BIGQUERY_CREDENTIALS":       {c.Warehouse.BigQueryCredentials, next.Warehouse.BigQueryCredentials},
+		"OUTBOX_WEBHOOK_SECRET":      {c.Outbox.WebhookSecret, next.Outbox.WebhookSecret},
+		"METRICS_TOKEN":              {c.MetricsToken, next.MetricsToken},
+		"STORAGE_ACCESS_KEY_ID":      {c.Storage.AccessKeyID, next.Storage.AccessKeyID},
+		"STORAGE_SECRET_ACCESS_KEY":  {c.Storage.SecretAccessKey, next.Storage.SecretAccessKey},
+		"STORAGE_SIGNING_SECRET":     {c.Storage.SigningSecret, next.Storage.SigningSecret},
+	} {
+		if values[0] != values[1] {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+
+	return next, changed, nil
+}
+
+// SecretsProvider names the secret manager in use, or "env" when there is none.
+func (c *Config) SecretsProvider() string {
+	if c.secrets == nil {
+		return "env"
+	}
+	return c.secrets.Name()
+}
+
+func load(ctx context.Context, provider secrets.Provider) (*Config, error) {
+	l := &loader{ctx: ctx, secrets: provider}
+
+	cfg := &Config{
+		Env:          l.str("APP_ENV", ""),
+		Port:         l.str("PORT", "8787"),
+		Greeting:     l.str("GREETING", "✨ Finsolvz Backend API ✨"),
+		AppURL:       l.str("APP_URL", ""),
+		MetricsToken: l.secret("METRICS_TOKEN"),
+		Profiling:    l.bool("PPROF_ENABLED", false),
+		secrets:      provider,
+	}
+
+	profile, ok := profiles[cfg.Env]
+	if !ok {
+		l.invalid("APP_ENV", fmt.Sprintf("must be %s, %s or %s, got %q", EnvDevelopment, EnvStaging, EnvProduction, cfg.Env))
+	}
+	cfg.Profile = profile
+
+	// Previous keys keep verifying the tokens they signed, so keys rotate without logging
+	// everyone out
+	cfg.JWT = utils.JWTConfig{
+		Secret:             l.requiredSecret("JWT_SECRET"),
+		PreviousSecrets:    l.secretList("JWT_PREVIOUS_SECRETS"),
+		Algorithm:          strings.ToUpper(l.str("JWT_ALGORITHM", utils.JWTAlgorithmHS256)),
+		PrivateKey:         l.secret("JWT_PRIVATE_KEY"),
+		PreviousPublicKeys: l.secret("JWT_PREVIOUS_PUBLIC_KEYS"),
+	}
+	if profile.StrictJWTSecret && cfg.JWT.Secret != "" {
+		if reason := weakJWTSecret(cfg.JWT.Secret); reason != "" {
+			l.invalid("JWT_SECRET", reason)
+		}
+	}
+	if err := utils.CheckJWTConfig(cfg.JWT); err != nil {
+		l.invalid("JWT_ALGORITHM", "is not usable: "+err.Error())
+	}
+	cfg.TokenLifetimes = utils.TokenLifetimes{
+		Access:        l.duration("JWT_ACCESS_TTL", utils.DefaultTokenLifetimes.Access),
+		Refresh:       l.duration("JWT_REFRESH_TTL", 0),
+		Sliding:       l.bool("JWT_SLIDING_EXPIRATION", false),
+		MaxAge:        l.duration("JWT_MAX_AGE", utils.DefaultTokenLifetimes.MaxAge),
+		Impersonation: l.duration("JWT_IMPERSONATION_TTL", utils.DefaultTokenLifetimes.Impersonation),
+	}
+	cfg.RoleFromDatabase = l.bool("AUTH_ROLE_FROM_DATABASE", false)
+	if cfg.TokenLifetimes.MaxAge < cfg.TokenLifetimes.Access || cfg.TokenLifetimes.MaxAge < cfg.TokenLifetimes.Refresh {
+		l.invalid("JWT_MAX_AGE", "must not be shorter than JWT_ACCESS_TTL or JWT_REFRESH_TTL")
+	}
+
+	cfg.CORSAllowedOrigins = l.list("CORS_ALLOWED_ORIGINS")
+	if len(cfg.CORSAllowedOrigins) == 0 && profile.WildcardCORS {
+		cfg.CORSAllowedOrigins = []string{"*"}
+	}
+	if !profile.WildcardCORS {
+		if len(cfg.CORSAllowedOrigins) == 0 {
+			l.invalid("CORS_ALLOWED_ORIGINS", "is required in "+cfg.Env)
+		}
+		for _, origin := range cfg.CORSAllowedOrigins {
+			if strings.Contains(origin, "*") {
+				l.invalid("CORS_ALLOWED_ORIGINS", "must not contain wildcards in "+cfg.Env)
+				break
+			}
+		}
+	}
+
+	rules, err := policy.LoadFile(l.str("POLICY_FILE", ""))
+	if err != nil {
+		l.invalid("POLICY_FILE", "is not a usable policy: "+message(err))
+	}
+	cfg.Policy = rules
+
+	cfg.RateLimitPerMinute = l.positiveInt("RATE_LIMIT_PER_MINUTE", 100)
+	cfg.APIKeys = APIKeyConfig{
+		RateLimitPerMinute: l.positiveInt("API_KEY_RATE_LIMIT_PER_MINUTE", 60),
+		DailyQuota:         l.nonNegativeInt("API_KEY_DAILY_QUOTA", 10000),
+	}
+
+	if _, err := strconv.Atoi(cfg.Port); err != nil {
+		l.invalid("PORT", "must be a port number")
+	}
+
+	defaultLevel := "info"
+	if cfg.IsDevelopment() {
+		defaultLevel = "debug"
+	}
+	level, err := log.ParseLevel(l.str("LOG_LEVEL", defaultLevel))
+	if err != nil {
+		l.invalid("LOG_LEVEL", "must be debug, info, warn or error")
+	}
+	cfg.LogLevel = level
+
+	driver, err := parseDriver(l.str("DB_DRIVER", ""))
+	if err != nil {
+		l.invalid("DB_DRIVER", "must be mongo or postgres")
+	}
+	cfg.Database = DatabaseConfig{
+		Driver:           driver,
+		MongoDBName:      l.str("MONGO_DB_NAME", defaultDatabaseName),
+		CollectionPrefix: l.str("MONGO_COLLECTION_PREFIX", ""),
+	}
+	switch driver {
+	case DriverPostgres:
+		cfg.Database.PostgresDSN = l.requiredSecret("POSTGRES_DSN")
+	case DriverMongo:
+		cfg.Database.MongoURI = l.requiredSecret("MONGO_URI")
+		rp, err := parseReportReadPreference(l.str("MONGO_REPORT_READ_PREFERENCE", ""), l.str("MONGO_REPORT_MAX_STALENESS", ""))
+		if err != nil {
+			l.problems = append(l.problems, message(err))
+		}
+		cfg.Database.ReportReadPreference = rp
+	}
+
+	cfg.Storage = storage.Config{
+		Driver:          l.str("STORAGE_DRIVER", storage.DriverLocal),
+		Dir:             l.str("STORAGE_DIR", "./storage"),
+		BaseURL:         l.str("STORAGE_PUBLIC_URL", ""),
+		SigningSecret:   l.secret("STORAGE_SIGNING_SECRET"),
+		LinkExpiry:      l.duration("STORAGE_URL_TTL", storage.DefaultLinkExpiry),
+		Bucket:          l.str("STORAGE_BUCKET", ""),
+		Region:          l.str("STORAGE_REGION", ""),
+		Endpoint:        l.str("STORAGE_ENDPOINT", ""),
+		AccessKeyID:     l.secret("STORAGE_ACCESS_KEY_ID"),
+		SecretAccessKey: l.secret("STORAGE_SECRET_ACCESS_KEY"),
+	}
+	if cfg.Storage.SigningSecret == "" {
+		cfg.Storage.SigningSecret = cfg.JWT.Secret
+	}
+	if _, err := storage.New(cfg.Storage); err != nil {
+		l.invalid("STORAGE_DRIVER", fmt.Sprintf("%q is not usable: %s", cfg.Storage.Driver, message(err)))
+	}
+
+	// Messaging only logs in development unless explicitly enabled
+	cfg.Email = utils.EmailConfig{
+		Provider:           l.str("EMAIL_PROVIDER", "smtp"),
+		DryRun:             l.bool("EMAIL_DRY_RUN", profile.DryRunMessaging),
+		From:               l.str("EMAIL_FROM", ""),
+		TemplateDir:        l.str("EMAIL_TEMPLATE_DIR", ""),
+		SMTPHost:           l.str("SMTP_HOST", "smtp.gmail.com"),
+		SMTPPort:           l.str("SMTP_PORT", "587"),
+		SMTPUsername:       l.secret("NODEMAILER_EMAIL"),
+		SMTPPassword:       l.secret("NODEMAILER_PASS"),
+		SendGridAPIKey:     l.secret("SENDGRID_API_KEY"),
+		MailgunDomain:      l.str("MAILGUN_DOMAIN", ""),
+		MailgunAPIKey:      l.secret("MAILGUN_API_KEY"),
+		MailgunBaseURL:     l.str("MAILGUN_BASE_URL", ""),
+		AWSRegion:          l.str("AWS_REGION", ""),
+		AWSAccessKeyID:     l.str("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey: l.str("AWS_SECRET_ACCESS_KEY", ""),
+	}
+	if _, err := utils.NewEmailProvider(cfg.Email); err != nil {
+		l.invalid("EMAIL_PROVIDER", fmt.Sprintf("%q is not usable: %s", cfg.Email.Provider, message(err)))
+	}
+
+	cfg.SMS = utils.SMSConfig{
+		Provider:           l.str("SMS_PROVIDER", "log"),
+		DryRun:             l.bool("SMS_DRY_RUN", profile.DryRunMessaging),
+		TwilioAccountSID:   l.str("TWILIO_ACCOUNT_SID", ""),
+		TwilioAuthToken:    l.secret("TWILIO_AUTH_TOKEN"),
+		TwilioSMSFrom:      l.str("TWILIO_SMS_FROM", ""),
+		TwilioWhatsAppFrom: l.str("TWILIO_WHATSAPP_FROM", ""),
+	}
+	if _, err := utils.NewMessageSender(cfg.SMS); err != nil {
+		l.invalid("SMS_PROVIDER", fmt.Sprintf("%q is not usable: %s", cfg.SMS.Provider, message(err)))
+	}
+
+	cfg.AI = ai.Config{
+		Provider: l.str("AI_PROVIDER", ""),
+		APIKey:   l.secret("GEMINI_API_KEY"),
+		Model:    l.str("GEMINI_MODEL", ""),
+	}
+	if _, err := ai.New(cfg.AI); err != nil {
+		l.invalid("AI_PROVIDER", fmt.Sprintf("%q is not usable: %s", cfg.AI.Provider, message(err)))
+	}
+
+	cfg.PasswordPolicy = utils.PasswordPolicy{
+		MinLength:     l.positiveInt("PASSWORD_MIN_LENGTH", utils.DefaultPasswordPolicy.MinLength),
+		RequireUpper:  l.bool("PASSWORD_REQUIRE_UPPERCASE", utils.DefaultPasswordPolicy.RequireUpper),
+		RequireLower:  l.bool("PASSWORD_REQUIRE_LOWERCASE", utils.DefaultPasswordPolicy.RequireLower),
+		RequireDigit:  l.bool("PASSWORD_REQUIRE_DIGIT", utils.DefaultPasswordPolicy.RequireDigit),
+		RequireSymbol: l.bool("PASSWORD_REQUIRE_SYMBOL", utils.DefaultPasswordPolicy.RequireSymbol),
+		RejectCommon:  l.bool("PASSWORD_REJECT_COMMON", utils.DefaultPasswordPolicy.RejectCommon),
+	}
+	if path := l.str("PASSWORD_BANNED_FILE", ""); path != "" {
+		if data, err := os.ReadFile(path); err != nil {
+			l.invalid("PASSWORD_BANNED_FILE", fmt.Sprintf("is not readable: %v", err))
+		} else {
+			cfg.PasswordPolicy.Banned = utils.ParsePasswordList(string(data))
+		}
+	}
+
+	cfg.GoogleOAuth = oauth.GoogleConfig{
+		ClientID:     l.str("GOOGLE_OAUTH_CLIENT_ID", ""),
+		ClientSecret: l.secret("GOOGLE_OAUTH_CLIENT_SECRET"),
+		RedirectURL:  l.str("GOOGLE_OAUTH_REDIRECT_URL", ""),
+	}
+	for _, hd := range l.list("GOOGLE_OAUTH_ALLOWED_DOMAINS") {
+		cfg.GoogleOAuth.HostedDomains = append(cfg.GoogleOAuth.HostedDomains, strings.ToLower(hd))
+	}
+	if _, err := oauth.NewGoogle(cfg.GoogleOAuth); err != nil {
+		l.invalid("GOOGLE_OAUTH_CLIENT_ID", "needs GOOGLE_OAUTH_CLIENT_SECRET and GOOGLE_OAUTH_REDIRECT_URL")
+	}
+
+	cfg.FX = fx.Config{
+		Provider:               l.str("FX_PROVIDER", ""),
+		OpenExchangeRatesAppID: l.secret("OPENEXCHANGERATES_APP_ID"),
+	}
+	if _, err := fx.New(cfg.FX); err != nil {
+		l.invalid("FX_PROVIDER", fmt.Sprintf("%q is not usable: %s", cfg.FX.Provider, message(err)))
+	}
+
+	cfg.Search = search.Config{
+		Backend:             l.str("SEARCH_BACKEND", ""),
+		AtlasIndex:          l.str("ATLAS_SEARCH_INDEX", search.DefaultAtlasIndex),
+		ElasticsearchURL:    l.str("ELASTICSEARCH_URL", ""),
+		ElasticsearchIndex:  l.str("ELASTICSEARCH_INDEX", search.DefaultElasticsearchIndex),
+		ElasticsearchAPIKey: l.secret("ELASTICSEARCH_API_KEY"),
+	}
+	if _, err := search.New(cfg.Search); err != nil {
+		l.invalid("SEARCH_BACKEND", fmt.Sprintf("%q is not usable: %s", cfg.Search.Backend, message(err)))
+	}
+
+	cfg.Warehouse = warehouse.Config{
+		Sink:                l.str("WAREHOUSE_SINK", ""),
+		Prefix:              l.str("WAREHOUSE_PREFIX", warehouse.DefaultPrefix),
+		BigQueryProject:     l.str("BIGQUERY_PROJECT", ""),
+		BigQueryDataset:     l.str("BIGQUERY_DATASET", ""),
+		BigQueryCredentials: l.secret("BIGQUERY_CREDENTIALS"),
+	}
+	if _, err := warehouse.New(cfg.Warehouse, nil); err != nil {
+		l.invalid("WAREHOUSE_SINK", fmt.Sprintf("%q is not usable: %s", cfg.Warehouse.Sink, message(err)))
+	}
+
+	cfg.Outbox = OutboxConfig{
+		WebhookURLs:   l.list("OUTBOX_WEBHOOK_URLS"),
+		WebhookSecret: l.secret("OUTBOX_WEBHOOK_SECRET"),
+	}
+
+	cfg.Cookies = utils.CookieConfig{
+		Enabled: l.bool("AUTH_COOKIES", false),
+		Domain:  l.str("AUTH_COOKIE_DOMAIN", ""),
+		Secure:  l.bool("AUTH_COOKIE_SECURE", cfg.Env != EnvDevelopment),
+	}
+	switch sameSite := strings.ToLower(l.str("AUTH_COOKIE_SAMESITE", "lax")); sameSite {
+	case "lax":
+		cfg.Cookies.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cfg.Cookies.SameSite = http.SameSiteStrictMode
+	case "none":
+		cfg.Cookies.SameSite = http.SameSiteNoneMode
+		if !cfg.Cookies.Secure {
+			l.invalid("AUTH_COOKIE_SAMESITE", "none requires AUTH_COOKIE_SECURE=true")
+		}
+	default:
+		l.invalid("AUTH_COOKIE_SAMESITE", fmt.Sprintf("must be lax, strict or none, got %q", sameSite))
+	}
+	if cfg.Cookies.Enabled && len(cfg.CORSAllowedOrigins) == 1 && cfg.CORSAllowedOrigins[0] == "*" {
+		l.invalid("AUTH_COOKIES", "requires explicit CORS_ALLOWED_ORIGINS, since browsers only send cookies to listed origins")
+	}
+
+	cfg.Jobs = JobsConfig{
+		IntegrityInterval:        l.duration("INTEGRITY_CHECK_INTERVAL", 0),
+		IntegrityAutoRepair:      l.bool("INTEGRITY_AUTO_REPAIR", false),
+		DigestInterval:           l.duration("WEEKLY_DIGEST_INTERVAL", 0),
+		AccessEmailWindow:        l.duration("REPORT_ACCESS_EMAIL_WINDOW", time.Minute),
+		TaskWorkers:              l.positiveInt("TASK_WORKERS", 2),
+		RetentionInterval:        l.duration("RETENTION_PURGE_INTERVAL", 0),
+		RetentionDryRun:          l.bool("RETENTION_DRY_RUN", false),
+		ExportTTL:                l.duration("EXPORT_TTL", 24*time.Hour),
+		ExportExpiryInterval:     l.duration("EXPORT_EXPIRY_INTERVAL", time.Hour),
+		DeadlineReminderInterval: l.duration("DEADLINE_REMINDER_INTERVAL", 0),
+		RateSyncInterval:         l.duration("RATE_SYNC_INTERVAL", 0),
+		WarehouseInterval:        l.duration("WAREHOUSE_EXPORT_INTERVAL", time.Hour),
+	}
+	if cfg.Jobs.ExportTTL <= 0 {
+		l.invalid("EXPORT_TTL", "must be positive")
+	}
+
+	cfg.RedisURL = l.secret("REDIS_URL")
+	if cfg.RedisURL != "" && !strings.HasPrefix(cfg.RedisURL, "redis://") && !strings.HasPrefix(cfg.RedisURL, "rediss://") {
+		l.invalid("REDIS_URL", "must start with redis:// or rediss://")
+	}
+
+	cfg.HTTP = HTTPConfig{
+		ReadHeaderTimeout:    l.duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:          l.duration("HTTP_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:         l.duration("HTTP_WRITE_TIMEOUT", 15*time.Second),
+		IdleTimeout:          l.duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+		KeepAlives:           l.bool("HTTP_KEEP_ALIVES", true),
+		MaxHeaderBytes:       l.positiveInt("HTTP_MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes),
+		H2C:                  l.bool("HTTP_H2C", false),
+		MaxConcurrentStreams: l.positiveInt("HTTP_MAX_CONCURRENT_STREAMS", 250),
+		ShutdownTimeout:      l.duration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second),
+	}
+	if cfg.HTTP.ReadTimeout > 0 && cfg.HTTP.ReadHeaderTimeout > cfg.HTTP.ReadTimeout {
+		l.invalid("HTTP_READ_HEADER_TIMEOUT", "must not exceed HTTP_READ_TIMEOUT")
+	}
+
+	cfg.Anomaly = AnomalyConfig{
+		MassDeleteThreshold: l.nonNegativeInt("ANOMALY_MASS_DELETE_THRESHOLD", 10),
+		MassDeleteWindow:    l.duration("ANOMALY_MASS_DELETE_WINDOW", 10*time.Minute),
+	}
+
+	for _, doc := range []struct{ name, key string }{{domain.DocumentTerms, "TERMS"}, {domain.DocumentPrivacy, "PRIVACY"}} {
+		if version := l.str(doc.key+"_VERSION", ""); version != "" {
+			cfg.Legal = append(cfg.Legal, domain.LegalDocument{Name: doc.name, Version: version, URL: l.str(doc.key+"_URL", "")})
+		}
+	}
+
+	cfg.Retention = domain.RetentionPolicy{
+		AuditLogYears:     l.nonNegativeInt("RETENTION_AUDIT_LOG_YEARS", 7),
+		TrashedReportDays: l.nonNegativeInt("RETENTION_TRASHED_REPORT_DAYS", 30),
+		AuthEventMonths:   l.nonNegativeInt("RETENTION_AUTH_EVENT_MONTHS", 3),
+	}
+
+	cfg.Backups = domain.BackupSchedule{
+		Interval:   l.duration("BACKUP_INTERVAL", 0),
+		Keep:       l.positiveInt("BACKUP_KEEP", 7),
+		MaxAgeDays: l.nonNegativeInt("BACKUP_MAX_AGE_DAYS", 0),
+	}
+
+	cfg.Sandbox = domain.SandboxConfig{
+		Enabled:       l.bool("SANDBOX_ENABLED", false),
+		Database:      l.str("SANDBOX_DB_NAME", cfg.Database.MongoDBName+"_sandbox"),
+		SeedFile:      l.str("SANDBOX_SEED_FILE", ""),
+		ResetInterval: l.duration("SANDBOX_RESET_INTERVAL", 24*time.Hour),
+	}
+	if cfg.Sandbox.Enabled {
+		if driver != DriverMongo {
+			l.invalid("SANDBOX_ENABLED", "requires DB_DRIVER=mongo")
+		}
+		if cfg.Sandbox.Database == cfg.Database.MongoDBName {
+			l.invalid("SANDBOX_DB_NAME", "must differ from MONGO_DB_NAME, since resets wipe it")
+		}
+	}
+
+	if profile.RequireHTTPS {
+		urls := map[string][]string{
+			"APP_URL":                   {cfg.AppURL},
+			"STORAGE_PUBLIC_URL":        {cfg.Storage.BaseURL},
+			"OUTBOX_WEBHOOK_URLS":       cfg.Outbox.WebhookURLs,
+			"GOOGLE_OAUTH_REDIRECT_URL": {cfg.GoogleOAuth.RedirectURL},
+		}
+		for _, key := range []string{"APP_URL", "STORAGE_PUBLIC_URL", "OUTBOX_WEBHOOK_URLS", "GOOGLE_OAUTH_REDIRECT_URL"} {
+			for _, u := range urls[key] {
+				if u != "" && !isHTTPS(u) {
+					l.invalid(key, fmt.Sprintf("must be an https URL in %s, got %q", cfg.Env, u))
+				}
+			}
+		}
+	}
+
+	if len(l.problems) > 0 {
+		return nil, errors.New("CONFIG_INVALID", "Invalid configuration: "+strings.Join(l.problems, "; "), 500, nil,
+			map[string]interface{}{"problems": l.problems})
+	}
+
+	databaseName = cfg.Database.MongoDBName
+	collectionPrefix = cfg.Database.CollectionPrefix
+
+	return cfg, nil
+}
+
+// message returns the user-facing part of an AppError, without its code and cause.
+func message(err error) string {
+	if appErr, ok := err.(errors.AppError); ok {
+		return appErr.Message()
+	}
+	return err.Error()
+}
+
+// loader reads environment variables and collects every problem instead of stopping at the first.
+type loader struct {
+	ctx      context.Context
+	secrets  secrets.Provider
+	problems []string
+}
+
+func (l *loader) invalid(key, reason string) {
+	l.problems = append(l.problems, key+" "+reason)
+}
+
+func (l *loader) str(key, def string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return def
+}
+
+// secret reads key from the secret manager, falling back to the environment when the secret
+// does not exist. Any other secret manager failure is a problem rather than a silent fallback.
+func (l *loader) secret(key string) string {
+	if l.secrets != nil {
+		value, err := l.secrets.Get(l.ctx, key)
+		if err == nil {
+			return strings.TrimSpace(value)
+		}
+		if !secrets.IsNotFound(err) {
+			l.invalid(key, fmt.Sprintf("could not be read from the %s secret manager: %v", l.secrets.Name(), err))
+			return ""
+		}
+	}
+	return l.str(key, "")
+}
+
+func (l *loader) requiredSecret(key string) string {
+	value := l.secret(key)
+	if value == "" {
+		l.invalid(key, "is required")
+	}
+	return value
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	value := l.str(key, "")
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("must be true or false, got %q", value))
+		return def
+	}
+	return b
+}
+
+func (l *loader) positiveInt(key string, def int) int {
+	value := l.str(key, "")
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		l.invalid(key, fmt.Sprintf("must be a positive number, got %q", value))
+		return def
+	}
+	return n
+}
+
+// nonNegativeInt is positiveInt that also accepts zero, for settings where zero means "no limit".
+func (l *loader) nonNegativeInt(key string, def int) int {
+	value := l.str(key, "")
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		l.invalid(key, fmt.Sprintf("must be zero or a positive number, got %q", value))
+		return def
+	}
+	return n
+}
+
+// duration parses a positive Go duration such as "15m" or "168h".
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	value := l.str(key, "")
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		l.invalid(key, fmt.Sprintf("must be a positive duration such as 15m, got %q", value))
+		return def
+	}
+	return d
+}
+
+// secretList is list for a secret.
+func (l *loader) secretList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(l.secret(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// list splits a comma-separated value, dropping empty entries.
+func (l *loader) list(key string) []string {
+	var items []string
+	for _, item := range strings.Split(l.str(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -2,9 +2,9 @@ package config
 
 import (
 	"context"
-	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -12,8 +12,28 @@ import (
 	"finsolvz-backend/internal/utils/log"
 )
 
-func ConnectMongoDB(ctx context.Context) (*mongo.Database, error) {
-	mongoURI := os.Getenv("MONGO_URI")
+const defaultDatabaseName = "Finsolvz"
+
+// Set by Load so repositories can resolve collection names without threading the config through.
+var (
+	databaseName     = defaultDatabaseName
+	collectionPrefix string
+)
+
+// DatabaseName returns the MongoDB database to use, from MONGO_DB_NAME.
+func DatabaseName() string {
+	return databaseName
+}
+
+// CollectionName maps a logical collection name to its physical name by applying
+// MONGO_COLLECTION_PREFIX, so several deployments can share one database.
+func CollectionName(name string) string {
+	return collectionPrefix + name
+}
+
+// ConnectMongoDB connects to mongoURI. extra options are applied on top of the defaults,
+// e.g. to install driver monitors.
+func ConnectMongoDB(ctx context.Context, mongoURI string, extra ...*options.ClientOptions) (*mongo.Database, error) {
 	if mongoURI == "" {
 		return nil, errors.New("MONGO_URI_MISSING", "MongoDB URI not configured", 500, nil, nil)
 	}
@@ -27,7 +47,7 @@ func ConnectMongoDB(ctx context.Context) (*mongo.Database, error) {
 	clientOptions.SetMaxConnecting(10)                 // Limit concurrent connections
 
 	// Connect to MongoDB
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, append([]*options.ClientOptions{clientOptions}, extra...)...)
 	if err != nil {
 		return nil, errors.New("MONGO_CONNECTION_ERROR", "Failed to connect to MongoDB", 500, err, nil)
 	}
@@ -40,7 +60,7 @@ func ConnectMongoDB(ctx context.Context) (*mongo.Database, error) {
 	log.Infof(ctx, "Connected to MongoDB successfully")
 
 	// Return the database instance
-	database := client.Database("Finsolvz")
+	database := client.Database(DatabaseName())
 
 	// Create indexes for optimal performance (async, don't block startup)
 	go func() {
@@ -51,3 +71,18 @@ func ConnectMongoDB(ctx context.Context) (*mongo.Database, error) {
 
 	return database, nil
 }
+
+// SupportsTransactions reports whether the connected deployment is a replica set or sharded
+// cluster, which multi-document transactions require.
+func SupportsTransactions(ctx context.Context, db *mongo.Database) bool {
+	var hello bson.M
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		log.Warnf(ctx, "Failed to detect MongoDB topology, transactions disabled: %v", err)
+		return false
+	}
+
+	if _, ok := hello["setName"]; ok {
+		return true
+	}
+	return hello["msg"] == "isdbgrid"
+}
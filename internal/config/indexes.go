@@ -2,6 +2,9 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,23 +14,32 @@ import (
 	"finsolvz-backend/internal/utils/log"
 )
 
-// CreateIndexes creates all necessary indexes for optimal performance
-func CreateIndexes(db *mongo.Database) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+type collectionIndexes struct {
+	name    string
+	indexes []mongo.IndexModel
+}
 
+// indexSpecs lists the indexes every collection should have
+func indexSpecs() []collectionIndexes {
 	// Users collection indexes
 	userIndexes := []mongo.IndexModel{
+		// deletedAt is part of the key so that a soft-deleted user doesn't keep their email
+		// taken: live users all share a missing deletedAt, deleted ones each have their own.
+		// Partial indexes can't select documents without the field.
 		{
-			Keys:    bson.D{{Key: "email", Value: 1}},
+			Keys:    bson.D{{Key: "email", Value: 1}, {Key: "deletedAt", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
-			Keys:    bson.D{{Key: "resetPasswordToken", Value: 1}},
-			Options: options.Index().SetSparse(true),
+			Keys: bson.D{{Key: "company", Value: 1}},
 		},
 		{
-			Keys: bson.D{{Key: "company", Value: 1}},
+			Keys: bson.D{{Key: "createdAt", Value: -1}},
+		},
+		// Every read of a tenant starts with its organization, null for the instance, so the
+		// index can't be sparse
+		{
+			Keys: bson.D{{Key: "organization", Value: 1}, {Key: "name", Value: 1}},
 		},
 	}
 
@@ -54,6 +66,10 @@ func CreateIndexes(db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "createdAt", Value: -1}},
 		},
+		// The activity feed lists reports by their latest change
+		{
+			Keys: bson.D{{Key: "updatedAt", Value: -1}},
+		},
 		// Compound indexes for common queries
 		{
 			Keys: bson.D{{Key: "company", Value: 1}, {Key: "reportType", Value: 1}},
@@ -61,6 +77,10 @@ func CreateIndexes(db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "company", Value: 1}, {Key: "year", Value: 1}},
 		},
+		// Digests read the reports of companies changed since the last one
+		{
+			Keys: bson.D{{Key: "company", Value: 1}, {Key: "updatedAt", Value: 1}},
+		},
 	}
 
 	// Companies collection indexes
@@ -71,30 +91,320 @@ func CreateIndexes(db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "createdAt", Value: -1}},
 		},
+		{
+			Keys: bson.D{{Key: "organization", Value: 1}, {Key: "name", Value: 1}},
+		},
 	}
 
 	// ReportTypes collection indexes
 	reportTypeIndexes := []mongo.IndexModel{
+		// Unique among live report types, as users' emails are
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Outbox collection indexes
+	outboxIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+		},
+		// Claimed events whose dispatcher stopped are found by their expired lock
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "lockedUntil", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "createdAt", Value: 1}},
+		},
+	}
+
+	// Security tokens: lookups by token, revocation by user, and a TTL index so Mongo
+	// purges expired resets, invitations, share links and sessions on its own
+	securityTokenIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "kind", Value: 1}, {Key: "userId", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Webhook subscriptions are matched by event type on every dispatch
+	webhookIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "active", Value: 1}, {Key: "events", Value: 1}},
+		},
+	}
+
+	// Webhook deliveries: one per subscription and event, polled by status, listed per subscription
+	webhookDeliveryIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "subscriptionId", Value: 1}, {Key: "eventId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+		},
+		// Claimed deliveries whose worker stopped are found by their expired lock
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "lockedUntil", Value: 1}},
+		},
 		{
-			Keys:    bson.D{{Key: "name", Value: 1}},
+			Keys: bson.D{{Key: "subscriptionId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+
+	// Tasks: claimed by status and due time, polled per user
+	taskIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "type", Value: 1}, {Key: "runAt", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "createdBy", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+
+	// Logins: the recent ones of a user, compared with each new login, and expired ones
+	// removed by the retention job
+	loginIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "createdAt", Value: 1}},
+		},
+	}
+
+	// Sessions are listed per user and found by their token's ID on every request, and
+	// purged once their token expires
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "lastSeenAt", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "tokenId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	// Report summaries: one per company and year
+	reportSummaryIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "company", Value: 1}, {Key: "year", Value: -1}},
 			Options: options.Index().SetUnique(true),
 		},
 	}
 
-	// Create indexes
-	collections := []struct {
-		name    string
-		indexes []mongo.IndexModel
-	}{
+	// Organizations: listed by name
+	organizationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "name", Value: 1}},
+		},
+	}
+
+	// Exports: the expiry job finds rendered files by expiry
+	exportIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+
+	// Report deadlines: listed per company
+	deadlineIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "company", Value: 1}, {Key: "reportType", Value: 1}},
+		},
+	}
+
+	// Charts of accounts: one per company
+	chartIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "company", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Trial balances: listed per company, newest first
+	trialBalanceIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "company", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+
+	// KPIs: listed per organization by name
+	kpiIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "organization", Value: 1}, {Key: "name", Value: 1}},
+		},
+	}
+
+	// KPI values: one per KPI and report, read as trends per company and removed with reports
+	kpiValueIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "kpi", Value: 1}, {Key: "report", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "company", Value: 1}, {Key: "year", Value: 1}, {Key: "computedAt", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "report", Value: 1}},
+		},
+	}
+
+	// Budgets: one per company, year and name
+	budgetIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "company", Value: 1}, {Key: "year", Value: -1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Budget versions: one per budget and version
+	budgetVersionIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "budget", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Report insights: the latest one per report
+	insightIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "report", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Rates: one per pair and day, looked up by the latest effective date
+	rateIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "base", Value: 1}, {Key: "quote", Value: 1}, {Key: "effectiveDate", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	taxRateIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "jurisdiction", Value: 1}, {Key: "name", Value: 1}, {Key: "effectiveFrom", Value: -1}}},
+	}
+
+	// Report templates: names are unique in an organization's library
+	templateIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "organization", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// Report template versions: one per template and version
+	templateVersionIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "template", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	// API keys: looked up by hash on every request they make
+	apiKeyIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+
+	// Backup records: the scheduled ones are listed newest first for rotation
+	backupIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "scheduled", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+
+	// SSO connections are found by the domain of the user's email, which one company owns
+	// Invitations are found by their user on acceptance and listed newest first
+	invitationIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "createdAt", Value: -1}},
+		},
+	}
+
+	ssoConnectionIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "domains", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	return []collectionIndexes{
 		{"users", userIndexes},
 		{"reports", reportIndexes},
 		{"companies", companyIndexes},
 		{"reporttypes", reportTypeIndexes},
+		{"outbox", outboxIndexes},
+		{"securitytokens", securityTokenIndexes},
+		{"webhooks", webhookIndexes},
+		{"webhookdeliveries", webhookDeliveryIndexes},
+		{"tasks", taskIndexes},
+		{"logins", loginIndexes},
+		{"sessions", sessionIndexes},
+		{"report_summaries", reportSummaryIndexes},
+		{"organizations", organizationIndexes},
+		{"exports", exportIndexes},
+		{"report_deadlines", deadlineIndexes},
+		{"charts_of_accounts", chartIndexes},
+		{"trial_balances", trialBalanceIndexes},
+		{"kpis", kpiIndexes},
+		{"kpi_values", kpiValueIndexes},
+		{"budgets", budgetIndexes},
+		{"budget_versions", budgetVersionIndexes},
+		{"report_insights", insightIndexes},
+		{"rates", rateIndexes},
+		{"tax_rates", taxRateIndexes},
+		{"report_templates", templateIndexes},
+		{"report_template_versions", templateVersionIndexes},
+		{"apikeys", apiKeyIndexes},
+		{"backups", backupIndexes},
+		{"sso_connections", ssoConnectionIndexes},
+		{"invitations", invitationIndexes},
+	}
+}
+
+// obsoleteIndexes lists, per collection, indexes that were replaced by one in indexSpecs and
+// are dropped once it exists.
+func obsoleteIndexes() map[string][]string {
+	return map[string][]string{
+		// Unique on every document, soft-deleted ones included
+		"users":       {"email_1"},
+		"reporttypes": {"name_1"},
 	}
+}
 
-	for _, col := range collections {
+// CreateIndexes creates all necessary indexes for optimal performance, then drops the
+// obsolete ones
+func CreateIndexes(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, col := range indexSpecs() {
 		if len(col.indexes) > 0 {
-			_, err := db.Collection(col.name).Indexes().CreateMany(ctx, col.indexes)
+			_, err := db.Collection(CollectionName(col.name)).Indexes().CreateMany(ctx, col.indexes)
 			if err != nil {
 				log.Errorf(ctx, "Failed to create indexes for %s: %v", col.name, err)
 				return err
@@ -103,5 +413,58 @@ func CreateIndexes(db *mongo.Database) error {
 		}
 	}
 
+	for name, indexes := range obsoleteIndexes() {
+		for _, index := range indexes {
+			_, err := db.Collection(CollectionName(name)).Indexes().DropOne(ctx, index)
+			var cmdErr mongo.CommandError
+			if errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+				continue
+			}
+			if err != nil {
+				log.Errorf(ctx, "Failed to drop index %s of %s: %v", index, name, err)
+				return err
+			}
+			log.Infof(ctx, "Dropped obsolete index %s of %s collection", index, name)
+		}
+	}
+
 	return nil
 }
+
+// MissingIndexes returns, per collection, the names of expected indexes that do not exist,
+// e.g. because the reindex command was never run against this database.
+func MissingIndexes(ctx context.Context, db *mongo.Database) (map[string][]string, error) {
+	missing := make(map[string][]string)
+	for _, col := range indexSpecs() {
+		cursor, err := db.Collection(CollectionName(col.name)).Indexes().List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var existing []struct {
+			Name string `bson:"name"`
+		}
+		if err := cursor.All(ctx, &existing); err != nil {
+			return nil, err
+		}
+
+		names := make(map[string]bool, len(existing))
+		for _, index := range existing {
+			names[index.Name] = true
+		}
+		for _, index := range col.indexes {
+			if name := indexName(index.Keys.(bson.D)); !names[name] {
+				missing[col.name] = append(missing[col.name], name)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// indexName is the name MongoDB generates for an index without an explicit one, e.g. "company_1_year_1"
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
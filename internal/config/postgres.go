@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	DriverMongo    = "mongo"
+	DriverPostgres = "postgres"
+)
+
+// parseDriver returns the storage backend selected by DB_DRIVER, defaulting to MongoDB.
+func parseDriver(value string) (string, error) {
+	driver := strings.ToLower(strings.TrimSpace(value))
+	switch driver {
+	case "", DriverMongo:
+		return DriverMongo, nil
+	case DriverPostgres:
+		return DriverPostgres, nil
+	}
+	return "", errors.New("INVALID_DB_DRIVER", "DB_DRIVER must be mongo or postgres", 500, nil, map[string]interface{}{"driver": driver})
+}
+
+// ConnectPostgres opens the database at dsn. The pgx driver is only
+// linked into binaries built with the "postgres" build tag.
+func ConnectPostgres(ctx context.Context, dsn string) (*sql.DB, error) {
+	if dsn == "" {
+		return nil, errors.New("POSTGRES_DSN_MISSING", "PostgreSQL DSN not configured", 500, nil, nil)
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, errors.New("POSTGRES_CONNECTION_ERROR", "Failed to open PostgreSQL (build with -tags postgres)", 500, err, nil)
+	}
+
+	db.SetMaxOpenConns(50)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxIdleTime(10 * time.Minute)
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, errors.New("POSTGRES_PING_ERROR", "Failed to ping PostgreSQL", 500, err, nil)
+	}
+
+	log.Infof(ctx, "Connected to PostgreSQL successfully")
+	return db, nil
+}
@@ -0,0 +1,8 @@
+//go:build postgres
+
+package config
+
+import (
+	// Registers the "pgx" database/sql driver used by ConnectPostgres.
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Profile is the behavior tied to APP_ENV. Staging and production tighten what development
+// allows, so a deployment cannot accidentally run with development conveniences.
+type Profile struct {
+	DebugEndpoints  bool // serve /debug/* diagnostics
+	WildcardCORS    bool // allow CORS_ALLOWED_ORIGINS to be "*" (the default when allowed)
+	RequireHTTPS    bool // APP_URL, STORAGE_PUBLIC_URL and webhook URLs must use https
+	StrictJWTSecret bool // refuse example or short JWT secrets
+	DryRunMessaging bool // default for EMAIL_DRY_RUN and SMS_DRY_RUN
+	ErrorDetails    bool // include internal error causes in API responses
+}
+
+// profiles maps APP_ENV to its profile. An unset APP_ENV keeps the permissive behavior from
+// before profiles existed, but still sends email and hides error details.
+var profiles = map[string]Profile{
+	"": {
+		DebugEndpoints: true,
+		WildcardCORS:   true,
+	},
+	EnvDevelopment: {
+		DebugEndpoints:  true,
+		WildcardCORS:    true,
+		DryRunMessaging: true,
+		ErrorDetails:    true,
+	},
+	EnvStaging: {
+		RequireHTTPS: true,
+	},
+	EnvProduction: {
+		RequireHTTPS:    true,
+		StrictJWTSecret: true,
+	},
+}
+
+// minJWTSecretLength is 256 bits of hex or base64-ish text
+const minJWTSecretLength = 32
+
+// exampleJWTSecrets are the placeholder values from the docs and Makefile.
+var exampleJWTSecrets = []string{
+	"your-super-secret-jwt-key-change-this-in-production",
+	"your-production-secret",
+	"docker-secret-key",
+	"integration-test-jwt-secret",
+	"secret",
+	"changeme",
+}
+
+// weakJWTSecret explains why secret must not be used in production, or returns "".
+func weakJWTSecret(secret string) string {
+	for _, example := range exampleJWTSecrets {
+		if strings.EqualFold(secret, example) {
+			return "must not be an example value in production"
+		}
+	}
+	if len(secret) < minJWTSecretLength {
+		return "must be at least 32 characters in production"
+	}
+	return ""
+}
+
+func isHTTPS(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
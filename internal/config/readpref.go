@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// parseReportReadPreference builds the read preference used for heavy report list/aggregation queries.
+// Configured via MONGO_REPORT_READ_PREFERENCE (e.g. "secondaryPreferred") and an optional
+// MONGO_REPORT_MAX_STALENESS in seconds. Writes always go to the primary.
+func parseReportReadPreference(modeStr, staleness string) (*readpref.ReadPref, error) {
+	if modeStr == "" {
+		return readpref.Primary(), nil
+	}
+
+	mode, err := readpref.ModeFromString(modeStr)
+	if err != nil {
+		return nil, errors.New("INVALID_READ_PREFERENCE", "Invalid MONGO_REPORT_READ_PREFERENCE value", 500, err, nil)
+	}
+
+	var opts []readpref.Option
+	if staleness != "" && mode != readpref.PrimaryMode {
+		seconds, err := strconv.Atoi(staleness)
+		if err != nil || seconds <= 0 {
+			return nil, errors.New("INVALID_READ_PREFERENCE", "MONGO_REPORT_MAX_STALENESS must be a positive number of seconds", 500, err, nil)
+		}
+		opts = append(opts, readpref.WithMaxStaleness(time.Duration(seconds)*time.Second))
+	}
+
+	rp, err := readpref.New(mode, opts...)
+	if err != nil {
+		return nil, errors.New("INVALID_READ_PREFERENCE", "Failed to build report read preference", 500, err, nil)
+	}
+
+	return rp, nil
+}
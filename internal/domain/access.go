@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessScope limits repository reads of companies and reports to the rows a user may see:
+// the user's companies, and reports of those companies or that the user created or was
+// given access to. The tenant of the request, if any, limits reads further.
+type AccessScope struct {
+	UserID    primitive.ObjectID   `bson:"userId"`
+	Companies []primitive.ObjectID `bson:"companies"`
+}
+
+type accessScopeKey struct{}
+
+// WithAccessScope returns a context whose company and report reads are limited to scope.
+func WithAccessScope(ctx context.Context, scope *AccessScope) context.Context {
+	if scope.Companies == nil {
+		scope.Companies = []primitive.ObjectID{}
+	}
+	return context.WithValue(ctx, accessScopeKey{}, scope)
+}
+
+// AccessScopeOf returns the scope set with WithAccessScope, or nil when reads are
+// unrestricted, as for super admins and background jobs.
+func AccessScopeOf(ctx context.Context) *AccessScope {
+	scope, _ := ctx.Value(accessScopeKey{}).(*AccessScope)
+	return scope
+}
+
+// AllowsCompany reports whether the company is one of the user's.
+func (s *AccessScope) AllowsCompany(company *Company) bool {
+	for _, id := range s.Companies {
+		if id == company.ID {
+			return true
+		}
+	}
+	for _, id := range company.User {
+		if id == s.UserID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ActivityKind says what happened in an activity feed entry.
+type ActivityKind string
+
+const (
+	ActivityReportCreated  ActivityKind = "report.created"
+	ActivityReportUpdated  ActivityKind = "report.updated"
+	ActivityCompanyCreated ActivityKind = "company.created"
+	ActivityUserJoined     ActivityKind = "user.joined"
+)
+
+// Activity is an entry of the activity feed. Entries are read from the reports, companies
+// and users themselves, so each one appears once, with its latest change.
+type Activity struct {
+	Kind    ActivityKind        `bson:"kind" json:"kind"`
+	At      time.Time           `bson:"at" json:"at"`
+	Subject primitive.ObjectID  `bson:"subject" json:"subject"` // the report, company or user
+	Name    string              `bson:"name" json:"name"`
+	Company *primitive.ObjectID `bson:"company,omitempty" json:"company,omitempty"` // company of a report
+	Actor   *primitive.ObjectID `bson:"actor,omitempty" json:"actor,omitempty"`     // creator of a report
+}
+
+// ActivityRepository reads the activity feed. Reads are limited by the tenant and access
+// scope of the context, like reads of the reports, companies and users themselves.
+type ActivityRepository interface {
+	// Recent returns a page of the newest activity, newest first, and the number of entries
+	// there are. Users joining are only included with includeUsers.
+	Recent(ctx context.Context, includeUsers bool, skip, limit int) ([]*Activity, int, error)
+}
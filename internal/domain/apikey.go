@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey authenticates scripts and integrations as the user who created it, through the
+// X-API-Key header. Only a hash of the key is stored; the key itself is shown once.
+type APIKey struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	User primitive.ObjectID `bson:"user" json:"user"`
+	Name string             `bson:"name" json:"name"`
+	// Prefix is the start of the key, shown so users can tell their keys apart
+	Prefix string `bson:"prefix" json:"prefix"`
+	Hash   string `bson:"hash" json:"-"`
+	// RateLimitPerMinute and DailyQuota bound the requests made with the key; nil uses the
+	// server defaults, and a daily quota of 0 is unlimited
+	RateLimitPerMinute *int       `bson:"rateLimitPerMinute,omitempty" json:"rateLimitPerMinute,omitempty"`
+	DailyQuota         *int       `bson:"dailyQuota,omitempty" json:"dailyQuota,omitempty"`
+	ExpiresAt          *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	LastUsedAt         *time.Time `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	CreatedAt          time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt          time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Expired reports whether the key no longer authenticates at t.
+func (k *APIKey) Expired(t time.Time) bool {
+	return k.ExpiresAt != nil && !t.Before(*k.ExpiresAt)
+}
+
+// APIKeyRepository stores API keys.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+	// GetAll lists the keys of a user, or of every user when user is nil, newest first
+	GetAll(ctx context.Context, user *primitive.ObjectID) ([]*APIKey, error)
+	// Update changes the name and limits of a key
+	Update(ctx context.Context, key *APIKey) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// Touch records that the key was used at t
+	Touch(ctx context.Context, id primitive.ObjectID, t time.Time) error
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BackupCollections lists the collections that can be exported and restored.
+var BackupCollections = []string{"users", "companies", "reports", "reporttypes"}
+
+const (
+	BackupStatusCompleted = "COMPLETED"
+	BackupStatusFailed    = "FAILED"
+)
+
+// BackupSchedule sets how often backups are taken automatically and how many are kept.
+// Scheduled backups beyond the newest Keep, or older than MaxAgeDays when set, are deleted.
+type BackupSchedule struct {
+	Interval   time.Duration `json:"-"`
+	Keep       int           `json:"keep"`
+	MaxAgeDays int           `json:"maxAgeDays,omitempty"`
+}
+
+// Backup records a backup written to the object store, or a scheduled one that failed.
+// Scheduled backups are rotated by the retention policy; those requested by hand are kept.
+type Backup struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Key         string              `bson:"key,omitempty" json:"key,omitempty"`
+	Status      string              `bson:"status" json:"status"`
+	Scheduled   bool                `bson:"scheduled" json:"scheduled"`
+	Collections map[string]int      `bson:"collections,omitempty" json:"collections,omitempty"`
+	Size        int64               `bson:"size" json:"size"`
+	Error       string              `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedBy   *primitive.ObjectID `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt   time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// BackupRepository dumps and restores raw collection data as newline-delimited Extended JSON,
+// and keeps the record of the backups written.
+type BackupRepository interface {
+	Export(ctx context.Context, collections []string, w io.Writer) (map[string]int, error)
+	Import(ctx context.Context, r io.Reader) (map[string]int, error)
+
+	CreateRecord(ctx context.Context, backup *Backup) error
+	// GetRecords returns the records of scheduled or of manual backups, newest first.
+	GetRecords(ctx context.Context, scheduled bool) ([]*Backup, error)
+	DeleteRecord(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// Where the company logo goes on branded documents
+const (
+	LogoLeft  = "left"
+	LogoRight = "right"
+	LogoNone  = "none"
+)
+
+var brandingColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// maxFooterText keeps footers on one line of a landscape page.
+const maxFooterText = 150
+
+// Branding styles the documents rendered for a company, so exported statements look like its
+// own. Empty fields keep the default look.
+type Branding struct {
+	LogoPlacement string `bson:"logoPlacement,omitempty" json:"logoPlacement,omitempty"` // left (default), right or none
+	// PrimaryColor colors titles and header rows, AccentColor the rules and footer; both "#RRGGBB"
+	PrimaryColor string `bson:"primaryColor,omitempty" json:"primaryColor,omitempty"`
+	AccentColor  string `bson:"accentColor,omitempty" json:"accentColor,omitempty"`
+	FooterText   string `bson:"footerText,omitempty" json:"footerText,omitempty"`
+	// Locale is a BCP 47 tag, e.g. "id-ID", setting how dates and numbers are written
+	Locale string `bson:"locale,omitempty" json:"locale,omitempty"`
+	// Numbers overrides how the locale and currencies write amounts
+	Numbers *NumberFormat `bson:"numbers,omitempty" json:"numbers,omitempty"`
+}
+
+// How amounts name their currency
+const (
+	CurrencySymbol = "symbol" // Rp 1.234, the default
+	CurrencyCode   = "code"   // IDR 1.234
+	CurrencyNone   = "none"   // 1.234
+)
+
+// maxNumberDecimals bounds the decimals a currency can be written with.
+const maxNumberDecimals = 4
+
+// NumberFormat overrides how a company's amounts are written. Empty fields follow the
+// branding's locale and each currency's minor units.
+type NumberFormat struct {
+	// DecimalSeparator and GroupSeparator replace the locale's, e.g. "," and "." for 1.234,56
+	DecimalSeparator string `bson:"decimalSeparator,omitempty" json:"decimalSeparator,omitempty"`
+	GroupSeparator   string `bson:"groupSeparator,omitempty" json:"groupSeparator,omitempty"`
+	// Decimals rounds amounts in a currency to other than its minor units, by ISO 4217 code,
+	// e.g. {"IDR": 0} for whole rupiah or {"USD": 0} for whole dollars
+	Decimals map[string]int `bson:"decimals,omitempty" json:"decimals,omitempty"`
+	// CurrencyDisplay is symbol (default), code or none
+	CurrencyDisplay string `bson:"currencyDisplay,omitempty" json:"currencyDisplay,omitempty"`
+	// NegativeParentheses writes negative amounts as (1,234.00), as accountants do
+	NegativeParentheses bool `bson:"negativeParentheses,omitempty" json:"negativeParentheses,omitempty"`
+}
+
+// Validate checks the number format and upper-cases its currency codes.
+func (n *NumberFormat) Validate() error {
+	if len([]rune(n.DecimalSeparator)) > 1 || len([]rune(n.GroupSeparator)) > 1 {
+		return fmt.Errorf("decimalSeparator and groupSeparator must be a single character")
+	}
+	if n.DecimalSeparator != "" && n.DecimalSeparator == n.GroupSeparator {
+		return fmt.Errorf("decimalSeparator and groupSeparator must differ")
+	}
+	switch n.CurrencyDisplay {
+	case "", CurrencySymbol, CurrencyCode, CurrencyNone:
+	default:
+		return fmt.Errorf("currencyDisplay must be symbol, code or none, got %q", n.CurrencyDisplay)
+	}
+	decimals := make(map[string]int, len(n.Decimals))
+	for code, places := range n.Decimals {
+		unit, err := currency.ParseISO(code)
+		if err != nil {
+			return fmt.Errorf("decimals must be keyed by ISO 4217 currency codes, got %q", code)
+		}
+		if places < 0 || places > maxNumberDecimals {
+			return fmt.Errorf("decimals of %s must be between 0 and %d", unit, maxNumberDecimals)
+		}
+		decimals[unit.String()] = places
+	}
+	if len(decimals) > 0 {
+		n.Decimals = decimals
+	} else {
+		n.Decimals = nil
+	}
+	return nil
+}
+
+// IsZero reports whether the number format overrides nothing.
+func (n NumberFormat) IsZero() bool {
+	return n.DecimalSeparator == "" && n.GroupSeparator == "" && len(n.Decimals) == 0 &&
+		n.CurrencyDisplay == "" && !n.NegativeParentheses
+}
+
+// Validate checks the branding and canonicalizes its locale.
+func (b *Branding) Validate() error {
+	switch b.LogoPlacement {
+	case "", LogoLeft, LogoRight, LogoNone:
+	default:
+		return fmt.Errorf("logoPlacement must be left, right or none, got %q", b.LogoPlacement)
+	}
+	for name, color := range map[string]string{"primaryColor": b.PrimaryColor, "accentColor": b.AccentColor} {
+		if color != "" && !brandingColor.MatchString(color) {
+			return fmt.Errorf("%s must be a #RRGGBB color, got %q", name, color)
+		}
+	}
+	if len([]rune(b.FooterText)) > maxFooterText {
+		return fmt.Errorf("footerText must be at most %d characters", maxFooterText)
+	}
+	if b.Locale != "" {
+		tag, err := language.Parse(b.Locale)
+		if err != nil {
+			return fmt.Errorf("locale must be a BCP 47 tag such as id-ID, got %q", b.Locale)
+		}
+		b.Locale = tag.String()
+	}
+	if b.Numbers != nil {
+		if err := b.Numbers.Validate(); err != nil {
+			return err
+		}
+		if b.Numbers.IsZero() {
+			b.Numbers = nil
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the branding sets nothing.
+func (b Branding) IsZero() bool {
+	return b == Branding{}
+}
+
+// DateLayout returns the time layout of dates written for locale: month first in the US,
+// year first in East Asia and Sweden, day first with dots in Central and Eastern Europe, and
+// day first with slashes elsewhere. An empty locale writes RFC 3339 timestamps.
+func DateLayout(locale string) string {
+	tag, err := language.Parse(locale)
+	if locale == "" || err != nil {
+		return time.RFC3339
+	}
+
+	// Regions are inferred when the tag has none, e.g. "en" is en-US
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	if region.String() == "US" || region.String() == "PH" {
+		return "01/02/2006"
+	}
+	switch base.String() {
+	case "ja", "zh", "ko", "sv", "lt":
+		return "2006-01-02"
+	case "de", "ru", "pl", "tr", "fi", "nb", "cs", "uk":
+		return "02.01.2006"
+	}
+	return "02/01/2006"
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Budget is what a company plans for a fiscal year, kept apart from its reports. Each change
+// makes a new version; earlier versions stay readable.
+type Budget struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Company primitive.ObjectID `bson:"company" json:"company"`
+	Name    string             `bson:"name" json:"name"`
+	Year    int                `bson:"year" json:"year"`
+	// ReportType is the type of the reports holding the actuals, e.g. the P&L; any report of
+	// the company and year when nil
+	ReportType *primitive.ObjectID `bson:"reportType,omitempty" json:"reportType,omitempty"`
+	Currency   *string             `bson:"currency,omitempty" json:"currency,omitempty"`
+	Version    int                 `bson:"version" json:"version"`
+	Lines      []BudgetLine        `bson:"lines" json:"lines"`
+	CreatedBy  primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	UpdatedBy  primitive.ObjectID  `bson:"updatedBy" json:"updatedBy"`
+	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// BudgetLine is the amount budgeted for a line item, named as in reports (see LineItems).
+type BudgetLine struct {
+	Item   string  `bson:"item" json:"item"`
+	Amount float64 `bson:"amount" json:"amount"`
+}
+
+// BudgetVersion is a budget's lines as one change left them.
+type BudgetVersion struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Budget    primitive.ObjectID `bson:"budget" json:"budget"`
+	Company   primitive.ObjectID `bson:"company" json:"company"`
+	Version   int                `bson:"version" json:"version"`
+	Lines     []BudgetLine       `bson:"lines" json:"lines"`
+	Note      string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedBy primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// BudgetRepository stores budgets and their versions.
+type BudgetRepository interface {
+	// Create stores a budget as version 1
+	Create(ctx context.Context, budget *Budget, note string) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Budget, error)
+	// GetAll lists the budgets of a company, or of every company the caller can see when
+	// company is nil, of a year or of all when year is 0, without their lines
+	GetAll(ctx context.Context, company *primitive.ObjectID, year int) ([]*Budget, error)
+	// Update stores the budget as its next version, unless it changed since version was read
+	Update(ctx context.Context, budget *Budget, note string) error
+	// Delete removes a budget and its versions
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// GetVersions lists the versions of a budget, newest first, without their lines
+	GetVersions(ctx context.Context, id primitive.ObjectID) ([]*BudgetVersion, error)
+	GetVersion(ctx context.Context, id primitive.ObjectID, version int) (*BudgetVersion, error)
+}
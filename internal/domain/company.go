@@ -8,20 +8,33 @@ import (
 )
 
 type Company struct {
-	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	Name           string               `bson:"name" json:"name"`
-	ProfilePicture *string              `bson:"profilePicture,omitempty" json:"profilePicture"`
-	User           []primitive.ObjectID `bson:"user" json:"user"`
-	CreatedAt      time.Time            `bson:"createdAt" json:"createdAt"`
-	UpdatedAt      time.Time            `bson:"updatedAt" json:"updatedAt"`
+	ID                  primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name                string               `bson:"name" json:"name"`
+	ProfilePicture      *string              `bson:"profilePicture,omitempty" json:"profilePicture"` // path of the uploaded logo
+	ProfilePictureThumb *string              `bson:"profilePictureThumb,omitempty" json:"profilePictureThumb,omitempty"`
+	User                []primitive.ObjectID `bson:"user" json:"user"`
+	Organization        *primitive.ObjectID  `bson:"organization,omitempty" json:"organization,omitempty"`
+	FiscalCalendar      *FiscalCalendar      `bson:"fiscalCalendar,omitempty" json:"fiscalCalendar,omitempty"` // nil for calendar years
+	// Jurisdiction is where the company is taxed, an ISO 3166 country code with an optional
+	// subdivision, e.g. "ID" or "US-CA"
+	Jurisdiction *string    `bson:"jurisdiction,omitempty" json:"jurisdiction,omitempty"`
+	Branding     *Branding  `bson:"branding,omitempty" json:"branding,omitempty"` // nil for the default look
+	CreatedAt    time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time  `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt    *time.Time `bson:"deletedAt,omitempty" json:"-"`
 }
 
 type CompanyRepository interface {
 	Create(ctx context.Context, company *Company) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*Company, error)
 	GetByName(ctx context.Context, name string) (*Company, error)
+	// GetByIDs returns the companies among ids in no particular order, skipping missing ones
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*Company, error)
 	SearchByName(ctx context.Context, name string) ([]*Company, error)
 	GetAll(ctx context.Context) ([]*Company, error)
+	// Each calls fn with every company one at a time as they are read, stopping at the
+	// first error fn returns. Unlike GetAll it is not capped.
+	Each(ctx context.Context, fn func(*Company) error) error
 	GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*Company, error)
 	Update(ctx context.Context, id primitive.ObjectID, company *Company) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Legal documents users must accept before using the API
+const (
+	DocumentTerms   = "terms"
+	DocumentPrivacy = "privacy"
+)
+
+// LegalDocument is the current version of a document users must accept.
+type LegalDocument struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Consent records that a user accepted a version of a legal document. Users keep every
+// consent they gave, so earlier acceptances stay on record after a new version.
+type Consent struct {
+	Document   string    `bson:"document" json:"document"`
+	Version    string    `bson:"version" json:"version"`
+	AcceptedAt time.Time `bson:"acceptedAt" json:"acceptedAt"`
+	IP         string    `bson:"ip,omitempty" json:"ip,omitempty"`
+}
+
+// LatestConsent returns the user's most recent consent to document, or nil when they never
+// accepted any version of it.
+func (u *User) LatestConsent(document string) *Consent {
+	var latest *Consent
+	for i := range u.Consents {
+		consent := &u.Consents[i]
+		if consent.Document == document && (latest == nil || consent.AcceptedAt.After(latest.AcceptedAt)) {
+			latest = consent
+		}
+	}
+	return latest
+}
+
+// HasAccepted reports whether the user accepted the given version of the document.
+func (u *User) HasAccepted(document LegalDocument) bool {
+	for _, consent := range u.Consents {
+		if consent.Document == document.Name && consent.Version == document.Version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeadlineFrequency is how often a company owes a report.
+type DeadlineFrequency string
+
+const (
+	DeadlineMonthly   DeadlineFrequency = "MONTHLY"
+	DeadlineQuarterly DeadlineFrequency = "QUARTERLY"
+	DeadlineYearly    DeadlineFrequency = "YEARLY"
+)
+
+// ReportDeadline says a company owes a report of a type for every period, due DueDays after
+// the period closes; a monthly P&L due by the 10th is MONTHLY with DueDays 10.
+type ReportDeadline struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Company    primitive.ObjectID `bson:"company" json:"company"`
+	ReportType primitive.ObjectID `bson:"reportType" json:"reportType"`
+	Frequency  DeadlineFrequency  `bson:"frequency" json:"frequency"`
+	DueDays    int                `bson:"dueDays" json:"dueDays"`
+	// RemindDays is how many days before the due date the company's users are reminded of a
+	// missing report; they are reminded again once it is overdue
+	RemindDays   int                `bson:"remindDays" json:"remindDays"`
+	LastReminder *DeadlineReminder  `bson:"lastReminder,omitempty" json:"lastReminder,omitempty"`
+	CreatedBy    primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// DeadlineReminder records the last reminder sent for a deadline, so each is sent once.
+type DeadlineReminder struct {
+	Period  string    `bson:"period" json:"period"`
+	Overdue bool      `bson:"overdue" json:"overdue"`
+	At      time.Time `bson:"at" json:"at"`
+}
+
+// ReportPeriod is one period of a deadline, from Start up to End, in the fiscal calendar of
+// its company. Reports carry no period of their own, so its report is the one for the fiscal
+// Year submitted during the following period.
+type ReportPeriod struct {
+	Label string    `json:"label"` // 2024-03, 2024-Q1 or 2024; FY2025-P03, FY2025-Q1 or FY2025 for fiscal calendars
+	Year  int       `json:"year"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	DueAt time.Time `json:"dueAt"`
+}
+
+// PeriodOf returns the period of the deadline containing t in calendar, the fiscal calendar
+// of the deadline's company.
+func (d *ReportDeadline) PeriodOf(calendar *FiscalCalendar, t time.Time) ReportPeriod {
+	period := calendar.PeriodOf(t)
+	fiscal := !calendar.IsCalendarYear()
+
+	var result ReportPeriod
+	switch d.Frequency {
+	case DeadlineYearly:
+		year := calendar.Year(period.Year)
+		result = ReportPeriod{Label: fmt.Sprintf("%d", year.Year), Start: year.Start, End: year.End}
+	case DeadlineQuarterly:
+		periods := calendar.Periods(period.Year)
+		first, last := periods[3*(period.Quarter-1)], periods[3*period.Quarter-1]
+		result = ReportPeriod{Label: fmt.Sprintf("%d-Q%d", period.Year, period.Quarter), Start: first.Start, End: last.End}
+	default:
+		label := fmt.Sprintf("%d-%02d", period.Year, period.Number)
+		if fiscal {
+			label = fmt.Sprintf("%d-P%02d", period.Year, period.Number)
+		}
+		result = ReportPeriod{Label: label, Start: period.Start, End: period.End}
+	}
+	if fiscal {
+		result.Label = "FY" + result.Label
+	}
+	result.Year = period.Year
+	result.DueAt = result.End.AddDate(0, 0, d.DueDays)
+	return result
+}
+
+// ClosedPeriods returns the periods of the deadline that closed by now, newest first, back
+// to the one in progress when the deadline was created and at most limit of them.
+func (d *ReportDeadline) ClosedPeriods(calendar *FiscalCalendar, now time.Time, limit int) []ReportPeriod {
+	var periods []ReportPeriod
+	period := d.PeriodOf(calendar, now)
+	for len(periods) < limit {
+		period = d.PeriodOf(calendar, period.Start.Add(-time.Nanosecond))
+		if !period.End.After(d.CreatedAt) {
+			break
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// SubmittedFor reports whether report is the deadline's report for period.
+func (d *ReportDeadline) SubmittedFor(calendar *FiscalCalendar, period ReportPeriod, report *PopulatedReport) bool {
+	next := d.PeriodOf(calendar, period.End)
+	return report.Company != nil && report.Company.ID == d.Company &&
+		report.ReportType != nil && report.ReportType.ID == d.ReportType &&
+		report.Year == period.Year && !report.CreatedAt.Before(period.End) && report.CreatedAt.Before(next.End)
+}
+
+// DeadlineRepository stores deadlines. Reads and writes are limited to the companies of the
+// tenant and access scope of the context.
+type DeadlineRepository interface {
+	Create(ctx context.Context, deadline *ReportDeadline) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*ReportDeadline, error)
+	// GetAll returns the deadlines of company, or of every company when it is nil
+	GetAll(ctx context.Context, company *primitive.ObjectID) ([]*ReportDeadline, error)
+	Update(ctx context.Context, id primitive.ObjectID, deadline *ReportDeadline) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// SetReminder records the last reminder sent for a deadline
+	SetReminder(ctx context.Context, id primitive.ObjectID, reminder DeadlineReminder) error
+}
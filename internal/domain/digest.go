@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DigestRun is the schedule of the digest email, shared by every instance: when the last run
+// started, and until when an instance holds the lease of the run in progress.
+type DigestRun struct {
+	LastRunAt   *time.Time `bson:"lastRunAt,omitempty" json:"lastRunAt"`
+	LockedUntil *time.Time `bson:"lockedUntil,omitempty" json:"-"`
+}
+
+// DigestRepository stores the digest schedule.
+type DigestRepository interface {
+	// Claim takes the lease of a run until lockedUntil when the last run started at or before
+	// due, or never did, and no other instance holds the lease. It returns the schedule as it
+	// was before the claim, or nil when the digest is not due or another instance is sending it.
+	Claim(ctx context.Context, due, lockedUntil time.Time) (*DigestRun, error)
+	// Complete records a run that started at startedAt and releases its lease
+	Complete(ctx context.Context, startedAt time.Time) error
+	// Release gives up the lease without recording a run, so that the next check retries it
+	Release(ctx context.Context) error
+}
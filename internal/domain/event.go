@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventType string
+
+const (
+	EventReportCreated       EventType = "report.created"
+	EventReportUpdated       EventType = "report.updated"
+	EventReportDeleted       EventType = "report.deleted"
+	EventReportAccessGranted EventType = "report.access_granted"
+	EventReportApproved      EventType = "report.approved"
+	EventReportAnomaly       EventType = "report.anomaly"
+	EventReportOverdue       EventType = "report.overdue"
+	EventUserUpdated         EventType = "user.updated"
+	EventUserSuspiciousLogin EventType = "user.suspicious_login"
+	EventUserImpersonated    EventType = "user.impersonated"
+	EventCompanyCreated      EventType = "company.created"
+	EventCompanyUpdated      EventType = "company.updated"
+	EventCompanyDeleted      EventType = "company.deleted"
+	EventFileDownloadIssued  EventType = "file.download_issued"
+)
+
+// EventTypes lists every event type that can be subscribed to.
+var EventTypes = []EventType{
+	EventReportCreated,
+	EventReportUpdated,
+	EventReportDeleted,
+	EventReportAccessGranted,
+	EventReportApproved,
+	EventReportAnomaly,
+	EventReportOverdue,
+	EventUserUpdated,
+	EventUserSuspiciousLogin,
+	EventUserImpersonated,
+	EventCompanyCreated,
+	EventCompanyUpdated,
+	EventCompanyDeleted,
+	EventFileDownloadIssued,
+}
+
+type EventStatus string
+
+const (
+	EventStatusPending EventStatus = "PENDING"
+	// EventStatusProcessing marks an event a dispatcher claimed, until its lock runs out
+	EventStatusProcessing EventStatus = "PROCESSING"
+	EventStatusDispatched EventStatus = "DISPATCHED"
+	EventStatusFailed     EventStatus = "FAILED"
+)
+
+// Event is a domain event persisted to the outbox and delivered asynchronously.
+// Payload holds the JSON-encoded event data.
+type Event struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type          EventType          `bson:"type" json:"type"`
+	AggregateID   primitive.ObjectID `bson:"aggregateId" json:"aggregateId"`
+	Payload       []byte             `bson:"payload" json:"-"`
+	Status        EventStatus        `bson:"status" json:"status"`
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	LastError     *string            `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	NextAttemptAt time.Time          `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	DispatchedAt  *time.Time         `bson:"dispatchedAt,omitempty" json:"dispatchedAt,omitempty"`
+	// LockedUntil is when a PROCESSING event's dispatcher is presumed gone and another may claim it
+	LockedUntil *time.Time `bson:"lockedUntil,omitempty" json:"-"`
+	// DeliveredTo lists the consumers, and the webhook URLs, that received the event, which
+	// retries skip
+	DeliveredTo []string `bson:"deliveredTo,omitempty" json:"-"`
+	// Impersonator is the super admin whose impersonated request caused the event
+	Impersonator *primitive.ObjectID `bson:"impersonator,omitempty" json:"impersonator,omitempty"`
+	// Company is the company whose audit log retention applies to the event, nil for events of
+	// no single company, such as user updates. It is stored in MongoDB only, where retention runs.
+	Company   *primitive.ObjectID `bson:"company,omitempty" json:"company,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+type impersonatorKey struct{}
+
+// WithImpersonator returns a context of a request a super admin makes as another user.
+func WithImpersonator(ctx context.Context, impersonator primitive.ObjectID) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, impersonator)
+}
+
+// ImpersonatorOf returns the super admin set with WithImpersonator, or nil.
+func ImpersonatorOf(ctx context.Context) *primitive.ObjectID {
+	if impersonator, ok := ctx.Value(impersonatorKey{}).(primitive.ObjectID); ok {
+		return &impersonator
+	}
+	return nil
+}
+
+// NewEvent builds a pending event with its payload encoded as JSON.
+func NewEvent(eventType EventType, aggregateID primitive.ObjectID, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Event{
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		Status:        EventStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+type OutboxRepository interface {
+	Append(ctx context.Context, event *Event) error
+	// Claim marks the oldest event due for delivery PROCESSING until lockedUntil and returns
+	// it, or nil when none is due. Events whose lock ran out are due again, so that each event
+	// has one dispatcher at a time even with several instances.
+	Claim(ctx context.Context, lockedUntil time.Time) (*Event, error)
+	// CountPending counts events not yet dispatched, including ones waiting for a retry.
+	CountPending(ctx context.Context) (int64, error)
+	MarkDispatched(ctx context.Context, id primitive.ObjectID) error
+	// MarkFailed records a failed attempt and the webhook URLs that received the event anyway
+	MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, deliveredTo []string, nextAttemptAt time.Time, final bool) error
+}
+
+// Transactor runs fn atomically. Repository calls made with the ctx passed to fn join the transaction.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExportFilter selects the reports of an export; empty fields select every one.
+type ExportFilter struct {
+	Company    *primitive.ObjectID `bson:"company,omitempty" json:"company,omitempty"`
+	ReportType *primitive.ObjectID `bson:"reportType,omitempty" json:"reportType,omitempty"`
+	Year       int                 `bson:"year,omitempty" json:"year,omitempty"`
+}
+
+// Export is a report listing rendered to a file by a background task. The file is removed
+// once it expires; the export stays behind as a record of what was downloaded.
+type Export struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Format string             `bson:"format" json:"format"` // xlsx or pdf
+	Filter ExportFilter       `bson:"filter" json:"filter"`
+	TaskID primitive.ObjectID `bson:"taskId" json:"taskId"`
+
+	// Tenant and Scope are those of the request, so the task reads what the requester could
+	Tenant *Tenant      `bson:"tenant,omitempty" json:"-"`
+	Scope  *AccessScope `bson:"scope,omitempty" json:"-"`
+
+	// Key is the object store key of the rendered file, empty until it is rendered and
+	// again once it expired
+	Key       string     `bson:"key,omitempty" json:"-"`
+	Rows      int        `bson:"rows" json:"rows"`
+	Size      int64      `bson:"size" json:"size"`
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	ExpiredAt *time.Time `bson:"expiredAt,omitempty" json:"expiredAt,omitempty"`
+
+	CreatedBy primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+type ExportRepository interface {
+	Create(ctx context.Context, export *Export) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Export, error)
+	// SetTask records the task rendering the export
+	SetTask(ctx context.Context, id, taskID primitive.ObjectID) error
+	// SetRendered records the file of an export and when it expires
+	SetRendered(ctx context.Context, id primitive.ObjectID, key string, rows int, size int64, expiresAt time.Time) error
+	// Expired returns up to limit exports whose files expired before before, oldest first
+	Expired(ctx context.Context, before time.Time, limit int) ([]*Export, error)
+	// MarkExpired forgets the file of an export once it was removed
+	MarkExpired(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// FiscalPattern is how a fiscal calendar divides its year into twelve periods.
+type FiscalPattern string
+
+const (
+	// FiscalMonths uses calendar months
+	FiscalMonths FiscalPattern = "MONTHS"
+	// The week-based patterns give the periods of each quarter 4, 4 and 5 weeks (or 4-5-4,
+	// 5-4-4). Years start on WeekStart and have 52 weeks, or 53 with the extra week in the
+	// last period.
+	Fiscal445 FiscalPattern = "4-4-5"
+	Fiscal454 FiscalPattern = "4-5-4"
+	Fiscal544 FiscalPattern = "5-4-4"
+)
+
+// FiscalPatterns lists the supported patterns.
+var FiscalPatterns = []FiscalPattern{FiscalMonths, Fiscal445, Fiscal454, Fiscal544}
+
+// FiscalCalendar is how a company divides time into fiscal years and periods. Fiscal years are
+// named after the calendar year they end in, so with the default calendar (January, MONTHS)
+// they are calendar years. A nil calendar is the default one.
+type FiscalCalendar struct {
+	StartMonth time.Month    `bson:"startMonth" json:"startMonth"` // 1-12
+	Pattern    FiscalPattern `bson:"pattern" json:"pattern"`
+	// WeekStart is the weekday week-based years start on, the one nearest the first day of
+	// StartMonth; 0 is Sunday
+	WeekStart time.Weekday `bson:"weekStart" json:"weekStart"`
+}
+
+// FiscalYear is a fiscal year, from Start up to End.
+type FiscalYear struct {
+	Year  int       `json:"year"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FiscalPeriod is one of the twelve periods of a fiscal year, from Start up to End.
+type FiscalPeriod struct {
+	Year    int       `json:"year"`
+	Number  int       `json:"number"`  // 1-12
+	Quarter int       `json:"quarter"` // 1-4
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+var defaultFiscalCalendar = FiscalCalendar{StartMonth: time.January, Pattern: FiscalMonths}
+
+// Validate reports whether the calendar's settings are in range.
+func (c *FiscalCalendar) Validate() error {
+	if c.StartMonth < time.January || c.StartMonth > time.December {
+		return fmt.Errorf("startMonth must be 1-12")
+	}
+	if c.WeekStart < time.Sunday || c.WeekStart > time.Saturday {
+		return fmt.Errorf("weekStart must be 0-6")
+	}
+	for _, pattern := range FiscalPatterns {
+		if c.Pattern == pattern {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown pattern %q", c.Pattern)
+}
+
+func (c *FiscalCalendar) orDefault() *FiscalCalendar {
+	if c == nil {
+		return &defaultFiscalCalendar
+	}
+	return c
+}
+
+// IsCalendarYear reports whether the fiscal years are calendar years divided into months.
+func (c *FiscalCalendar) IsCalendarYear() bool {
+	c = c.orDefault()
+	return c.StartMonth == time.January && c.Pattern == FiscalMonths
+}
+
+// weeks returns the weeks of the periods of each quarter, or nil for calendar months.
+func (c *FiscalCalendar) weeks() []int {
+	switch c.Pattern {
+	case Fiscal445:
+		return []int{4, 4, 5}
+	case Fiscal454:
+		return []int{4, 5, 4}
+	case Fiscal544:
+		return []int{5, 4, 4}
+	}
+	return nil
+}
+
+// yearStart returns when fiscal year starts, in UTC.
+func (c *FiscalCalendar) yearStart(year int) time.Time {
+	c = c.orDefault()
+	if c.StartMonth != time.January {
+		year--
+	}
+	start := time.Date(year, c.StartMonth, 1, 0, 0, 0, 0, time.UTC)
+	if c.weeks() == nil {
+		return start
+	}
+	// The WeekStart nearest the first of the month, at most three days away
+	offset := (int(c.WeekStart) - int(start.Weekday()) + 7) % 7
+	if offset > 3 {
+		offset -= 7
+	}
+	return start.AddDate(0, 0, offset)
+}
+
+// Year returns the bounds of a fiscal year.
+func (c *FiscalCalendar) Year(year int) FiscalYear {
+	return FiscalYear{Year: year, Start: c.yearStart(year), End: c.yearStart(year + 1)}
+}
+
+// YearOf returns the fiscal year containing t.
+func (c *FiscalCalendar) YearOf(t time.Time) FiscalYear {
+	year := t.UTC().Year()
+	if !t.Before(c.yearStart(year + 1)) {
+		year++
+	} else if t.Before(c.yearStart(year)) {
+		year--
+	}
+	return c.Year(year)
+}
+
+// Periods returns the twelve periods of a fiscal year.
+func (c *FiscalCalendar) Periods(year int) []FiscalPeriod {
+	bounds := c.Year(year)
+	weeks := c.orDefault().weeks()
+
+	periods := make([]FiscalPeriod, 12)
+	start := bounds.Start
+	for i := range periods {
+		var end time.Time
+		switch {
+		case i == 11:
+			end = bounds.End // takes the 53rd week of long years
+		case weeks == nil:
+			end = bounds.Start.AddDate(0, i+1, 0)
+		default:
+			end = start.AddDate(0, 0, 7*weeks[i%3])
+		}
+		periods[i] = FiscalPeriod{Year: year, Number: i + 1, Quarter: i/3 + 1, Start: start, End: end}
+		start = end
+	}
+	return periods
+}
+
+// PeriodOf returns the fiscal period containing t.
+func (c *FiscalCalendar) PeriodOf(t time.Time) FiscalPeriod {
+	periods := c.Periods(c.YearOf(t).Year)
+	for _, period := range periods[:11] {
+		if t.Before(period.End) {
+			return period
+		}
+	}
+	return periods[11]
+}
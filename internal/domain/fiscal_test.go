@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func utcDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFiscalCalendar_Year(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar *FiscalCalendar
+		year     int
+		start    time.Time
+		end      time.Time
+	}{
+		{name: "default calendar", calendar: nil, year: 2024, start: utcDate(2024, time.January, 1), end: utcDate(2025, time.January, 1)},
+		{name: "months from July", calendar: &FiscalCalendar{StartMonth: time.July, Pattern: FiscalMonths}, year: 2024, start: utcDate(2023, time.July, 1), end: utcDate(2024, time.July, 1)},
+		// January 1st 2023 is a Sunday
+		{name: "4-4-5 starting on the first", calendar: &FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445, WeekStart: time.Sunday}, year: 2023, start: utcDate(2023, time.January, 1), end: utcDate(2023, time.December, 31)},
+		// The Sunday nearest January 1st 2025, a Wednesday, is three days before it
+		{name: "4-4-5 starting in December", calendar: &FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445, WeekStart: time.Sunday}, year: 2024, start: utcDate(2023, time.December, 31), end: utcDate(2024, time.December, 29)},
+		// and the one nearest January 1st 2026, a Thursday, three days after it
+		{name: "4-4-5 53-week year", calendar: &FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445, WeekStart: time.Sunday}, year: 2025, start: utcDate(2024, time.December, 29), end: utcDate(2026, time.January, 4)},
+		// April 1st 2024 is a Monday and April 1st 2025 a Tuesday
+		{name: "4-5-4 from April on Saturdays", calendar: &FiscalCalendar{StartMonth: time.April, Pattern: Fiscal454, WeekStart: time.Saturday}, year: 2025, start: utcDate(2024, time.March, 30), end: utcDate(2025, time.March, 29)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			year := tt.calendar.Year(tt.year)
+			if !year.Start.Equal(tt.start) || !year.End.Equal(tt.end) {
+				t.Fatalf("Expected FY%d to run from %s to %s, got %s to %s", tt.year, tt.start.Format(time.DateOnly), tt.end.Format(time.DateOnly), year.Start.Format(time.DateOnly), year.End.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestFiscalCalendar_Periods(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar *FiscalCalendar
+		year     int
+		weeks    []int // of each period; nil for calendar months
+	}{
+		{name: "4-4-5 52-week year", calendar: &FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445}, year: 2024, weeks: []int{4, 4, 5, 4, 4, 5, 4, 4, 5, 4, 4, 5}},
+		{name: "4-4-5 53-week year", calendar: &FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445}, year: 2025, weeks: []int{4, 4, 5, 4, 4, 5, 4, 4, 5, 4, 4, 6}},
+		{name: "4-5-4 from April", calendar: &FiscalCalendar{StartMonth: time.April, Pattern: Fiscal454, WeekStart: time.Saturday}, year: 2025, weeks: []int{4, 5, 4, 4, 5, 4, 4, 5, 4, 4, 5, 4}},
+		{name: "5-4-4 from October", calendar: &FiscalCalendar{StartMonth: time.October, Pattern: Fiscal544, WeekStart: time.Monday}, year: 2025, weeks: []int{5, 4, 4, 5, 4, 4, 5, 4, 4, 5, 4, 4}},
+		{name: "months from July", calendar: &FiscalCalendar{StartMonth: time.July, Pattern: FiscalMonths}, year: 2024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			year := tt.calendar.Year(tt.year)
+			periods := tt.calendar.Periods(tt.year)
+			if len(periods) != 12 {
+				t.Fatalf("Expected 12 periods, got %d", len(periods))
+			}
+			if !periods[0].Start.Equal(year.Start) || !periods[11].End.Equal(year.End) {
+				t.Fatalf("Expected the periods to cover FY%d exactly, got %s to %s", tt.year, periods[0].Start, periods[11].End)
+			}
+
+			for i, period := range periods {
+				if period.Year != tt.year || period.Number != i+1 || period.Quarter != i/3+1 {
+					t.Fatalf("Expected period %d of Q%d FY%d, got %+v", i+1, i/3+1, tt.year, period)
+				}
+				if i > 0 && !period.Start.Equal(periods[i-1].End) {
+					t.Fatalf("Expected period %d to start when period %d ends, got %s and %s", i+1, i, period.Start, periods[i-1].End)
+				}
+				if tt.weeks == nil {
+					if want := year.Start.AddDate(0, i, 0); !period.Start.Equal(want) || period.Start.Day() != 1 {
+						t.Fatalf("Expected period %d to start on %s, got %s", i+1, want, period.Start)
+					}
+					continue
+				}
+				if weeks := period.End.Sub(period.Start) / (7 * 24 * time.Hour); int(weeks) != tt.weeks[i] {
+					t.Fatalf("Expected period %d to have %d weeks, got %d", i+1, tt.weeks[i], weeks)
+				}
+				if period.Start.Weekday() != tt.calendar.WeekStart {
+					t.Fatalf("Expected period %d to start on a %s, got %s", i+1, tt.calendar.WeekStart, period.Start.Weekday())
+				}
+			}
+		})
+	}
+}
+
+func TestFiscalCalendar_PeriodOf(t *testing.T) {
+	calendar445 := &FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445, WeekStart: time.Sunday}
+	fromJuly := &FiscalCalendar{StartMonth: time.July, Pattern: FiscalMonths}
+	fromApril := &FiscalCalendar{StartMonth: time.April, Pattern: Fiscal454, WeekStart: time.Saturday}
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name     string
+		calendar *FiscalCalendar
+		at       time.Time
+		year     int
+		period   int
+	}{
+		// FY2024 of the 4-4-5 calendar starts on December 31st 2023 and its first period ends
+		// four weeks later, on January 28th
+		{name: "first day of a year starting in December", calendar: calendar445, at: utcDate(2023, time.December, 31), year: 2024, period: 1},
+		{name: "last instant of the previous year", calendar: calendar445, at: utcDate(2023, time.December, 31).Add(-time.Nanosecond), year: 2023, period: 12},
+		{name: "last instant of a period", calendar: calendar445, at: utcDate(2024, time.January, 28).Add(-time.Nanosecond), year: 2024, period: 1},
+		{name: "first instant of the next period", calendar: calendar445, at: utcDate(2024, time.January, 28), year: 2024, period: 2},
+		{name: "the 53rd week", calendar: calendar445, at: utcDate(2026, time.January, 1), year: 2025, period: 12},
+		{name: "first day after the 53rd week", calendar: calendar445, at: utcDate(2026, time.January, 4), year: 2026, period: 1},
+		{name: "local time of the next UTC day", calendar: calendar445, at: time.Date(2024, time.January, 28, 6, 0, 0, 0, jakarta), year: 2024, period: 1},
+
+		{name: "last day of a year starting in July", calendar: fromJuly, at: utcDate(2024, time.June, 30), year: 2024, period: 12},
+		{name: "first day of a year starting in July", calendar: fromJuly, at: utcDate(2024, time.July, 1), year: 2025, period: 1},
+		{name: "January of a year starting in July", calendar: fromJuly, at: utcDate(2025, time.January, 15), year: 2025, period: 7},
+
+		{name: "first day of a year starting in March", calendar: fromApril, at: utcDate(2024, time.March, 30), year: 2025, period: 1},
+		{name: "day before a year starting in March", calendar: fromApril, at: utcDate(2024, time.March, 29), year: 2024, period: 12},
+		{name: "first day of a five-week period", calendar: fromApril, at: utcDate(2024, time.April, 27), year: 2025, period: 2},
+		{name: "last day of a five-week period", calendar: fromApril, at: utcDate(2024, time.May, 31), year: 2025, period: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period := tt.calendar.PeriodOf(tt.at)
+			if period.Year != tt.year || period.Number != tt.period {
+				t.Fatalf("Expected %s to be in period %d of FY%d, got period %d of FY%d", tt.at, tt.period, tt.year, period.Number, period.Year)
+			}
+			if tt.at.Before(period.Start) || !tt.at.Before(period.End) {
+				t.Fatalf("Expected %s to be within %s and %s", tt.at, period.Start, period.End)
+			}
+			if year := tt.calendar.YearOf(tt.at); year.Year != tt.year {
+				t.Fatalf("Expected %s to be in FY%d, got FY%d", tt.at, tt.year, year.Year)
+			}
+		})
+	}
+}
+
+func TestFiscalCalendar_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar FiscalCalendar
+		valid    bool
+	}{
+		{name: "months", calendar: FiscalCalendar{StartMonth: time.July, Pattern: FiscalMonths}, valid: true},
+		{name: "weeks", calendar: FiscalCalendar{StartMonth: time.April, Pattern: Fiscal544, WeekStart: time.Saturday}, valid: true},
+		{name: "month zero", calendar: FiscalCalendar{StartMonth: 0, Pattern: FiscalMonths}},
+		{name: "month 13", calendar: FiscalCalendar{StartMonth: 13, Pattern: FiscalMonths}},
+		{name: "weekday 7", calendar: FiscalCalendar{StartMonth: time.January, Pattern: Fiscal445, WeekStart: 7}},
+		{name: "unknown pattern", calendar: FiscalCalendar{StartMonth: time.January, Pattern: "5-5-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.calendar.Validate(); (err == nil) != tt.valid {
+				t.Fatalf("Expected valid to be %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
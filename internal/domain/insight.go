@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportInsight is what a language model made of a report: a narrative summary, anomalies and
+// forecasts. It is AI-generated and kept for the version of the report it was generated from.
+type ReportInsight struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Report  primitive.ObjectID `bson:"report" json:"report"`
+	Company primitive.ObjectID `bson:"company" json:"company"`
+	// Version fingerprints the data the insight was generated from; a changed report gets a
+	// new insight
+	Version     string             `bson:"version" json:"version"`
+	Model       string             `bson:"model" json:"model"`
+	Summary     string             `bson:"summary" json:"summary"`
+	Anomalies   []InsightAnomaly   `bson:"anomalies" json:"anomalies"`
+	Forecasts   []InsightForecast  `bson:"forecasts" json:"forecasts"`
+	GeneratedBy primitive.ObjectID `bson:"generatedBy" json:"generatedBy"`
+	GeneratedAt time.Time          `bson:"generatedAt" json:"generatedAt"`
+}
+
+// InsightAnomaly is a line item that stands out, and why.
+type InsightAnomaly struct {
+	Item        string `bson:"item" json:"item"`
+	Description string `bson:"description" json:"description"`
+}
+
+// InsightForecast is a line item projected to a later year.
+type InsightForecast struct {
+	Item      string  `bson:"item" json:"item"`
+	Year      int     `bson:"year" json:"year"`
+	Value     float64 `bson:"value" json:"value"`
+	Rationale string  `bson:"rationale,omitempty" json:"rationale,omitempty"`
+}
+
+// InsightRepository keeps the latest insight of each report.
+type InsightRepository interface {
+	// Get returns the insight of a report if it was generated from version
+	Get(ctx context.Context, report primitive.ObjectID, version string) (*ReportInsight, error)
+	// Save replaces the insight of the report
+	Save(ctx context.Context, insight *ReportInsight) error
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReferenceCheck names a foreign reference between two collections, e.g. "reports.company".
+type ReferenceCheck string
+
+const (
+	CheckReportCompany    ReferenceCheck = "reports.company"
+	CheckReportType       ReferenceCheck = "reports.reportType"
+	CheckReportCreatedBy  ReferenceCheck = "reports.createdBy"
+	CheckReportUserAccess ReferenceCheck = "reports.userAccess"
+	CheckUserCompany      ReferenceCheck = "users.company"
+	CheckCompanyUser      ReferenceCheck = "companies.user"
+)
+
+// ReferenceChecks lists every reference the integrity checker scans.
+var ReferenceChecks = []ReferenceCheck{
+	CheckReportCompany,
+	CheckReportType,
+	CheckReportCreatedBy,
+	CheckReportUserAccess,
+	CheckUserCompany,
+	CheckCompanyUser,
+}
+
+// Repairable reports whether an orphan can be fixed automatically. Only list references
+// are repairable, by dropping the dangling entry; required single references need a human decision.
+func (c ReferenceCheck) Repairable() bool {
+	switch c {
+	case CheckReportUserAccess, CheckUserCompany, CheckCompanyUser:
+		return true
+	}
+	return false
+}
+
+// OrphanReference is a live document pointing at a missing or soft-deleted document.
+type OrphanReference struct {
+	Check       ReferenceCheck     `json:"check"`
+	DocumentID  primitive.ObjectID `json:"documentId"`
+	ReferenceID primitive.ObjectID `json:"referenceId"`
+	Deleted     bool               `json:"deleted"` // target exists but is soft-deleted
+}
+
+type IntegrityRepository interface {
+	FindOrphans(ctx context.Context, check ReferenceCheck) ([]OrphanReference, error)
+	// RemoveReference drops ReferenceID from the list reference named by orphan.Check.
+	RemoveReference(ctx context.Context, orphan OrphanReference) error
+}
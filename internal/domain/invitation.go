@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invitation invites a pending user to set their own password. The emailed link carries a
+// TokenInvitation security token; resending replaces the token and moves ExpiresAt.
+type Invitation struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	User primitive.ObjectID `bson:"user" json:"user"`
+	// Name, Email and Role are those the user was invited with
+	Name      string             `bson:"name" json:"name"`
+	Email     string             `bson:"email" json:"email"`
+	Role      UserRole           `bson:"role" json:"role"`
+	InvitedBy primitive.ObjectID `bson:"invitedBy" json:"invitedBy"`
+	// Sends counts the invitation emails, the first one included
+	Sends      int        `bson:"sends" json:"sends"`
+	ExpiresAt  time.Time  `bson:"expiresAt" json:"expiresAt"` // of the latest link
+	AcceptedAt *time.Time `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
+	CreatedAt  time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Expired reports whether the latest link no longer works at t.
+func (i *Invitation) Expired(t time.Time) bool {
+	return !t.Before(i.ExpiresAt)
+}
+
+// InvitationRepository stores invitations.
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *Invitation) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Invitation, error)
+	GetByUser(ctx context.Context, user primitive.ObjectID) (*Invitation, error)
+	// GetPage lists invitations newest first, only those not accepted yet when pending is set,
+	// and how many there are in all
+	GetPage(ctx context.Context, pending bool, skip, limit int) ([]*Invitation, int, error)
+	// Update records a resend or the acceptance
+	Update(ctx context.Context, invitation *Invitation) error
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// KPI is a key figure computed from the line items of every report of its organization, or
+// only of reports of ReportType when set. Formula is a formula over line item names, as
+// parsed by internal/platform/formula.
+type KPI struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Organization *primitive.ObjectID `bson:"organization,omitempty" json:"organization,omitempty"`
+	Name         string              `bson:"name" json:"name"`
+	Description  string              `bson:"description,omitempty" json:"description,omitempty"`
+	Formula      string              `bson:"formula" json:"formula"`
+	Unit         string              `bson:"unit,omitempty" json:"unit,omitempty"` // e.g. % or IDR
+	ReportType   *primitive.ObjectID `bson:"reportType,omitempty" json:"reportType,omitempty"`
+	CreatedBy    primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	CreatedAt    time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AppliesTo reports whether the KPI is computed for reports of reportType from companies of
+// organization.
+func (k *KPI) AppliesTo(organization *primitive.ObjectID, reportType primitive.ObjectID) bool {
+	if (k.Organization == nil) != (organization == nil) || (k.Organization != nil && *k.Organization != *organization) {
+		return false
+	}
+	return k.ReportType == nil || *k.ReportType == reportType
+}
+
+// KPIValue is a KPI computed from one report, kept until the report changes.
+type KPIValue struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	KPI        primitive.ObjectID `bson:"kpi" json:"kpi"`
+	Company    primitive.ObjectID `bson:"company" json:"company"`
+	Report     primitive.ObjectID `bson:"report" json:"report"`
+	Year       int                `bson:"year" json:"year"`
+	Value      float64            `bson:"value" json:"value"`
+	ComputedAt time.Time          `bson:"computedAt" json:"computedAt"`
+}
+
+// LineItems returns the amounts of the line items found in report data, by name, for KPI
+// formulas. Names are lower case with spaces collapsed, and the first item of a name wins.
+// Report data has no fixed shape, so line items are found wherever they look like one:
+//   - an object with an amount, value or total and an account, code, name, label or item
+//     naming it, as the lines and sections of generated statements, under each of its names
+//   - a number in an object under its key, as in {"Revenue": 1000}
+//   - a row whose first cell is a name and second a number, as in ["Revenue", 1000]
+func LineItems(reportData interface{}) map[string]float64 {
+	items := map[string]float64{}
+	collectLineItems(reportData, items)
+	return items
+}
+
+// LineItemName normalizes the name of a line item as LineItems does.
+func LineItemName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+var (
+	lineItemAmountKeys = []string{"amount", "value", "total"}
+	lineItemNameKeys   = []string{"account", "code", "name", "label", "item"}
+)
+
+func collectLineItems(data interface{}, items map[string]float64) {
+	add := func(name string, amount float64) {
+		if name = LineItemName(name); name != "" {
+			if _, ok := items[name]; !ok {
+				items[name] = amount
+			}
+		}
+	}
+
+	switch data := data.(type) {
+	case primitive.D:
+		collectLineItems(data.Map(), items)
+	case primitive.M:
+		collectLineItems(map[string]interface{}(data), items)
+	case primitive.A:
+		collectLineItems([]interface{}(data), items)
+
+	case map[string]interface{}:
+		for _, key := range lineItemAmountKeys {
+			amount, ok := lineItemAmount(data[key])
+			if !ok {
+				continue
+			}
+			for _, nameKey := range lineItemNameKeys {
+				if name, ok := data[nameKey].(string); ok {
+					add(name, amount)
+				}
+			}
+			break
+		}
+		// Sorted so duplicate names resolve the same way every time
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if amount, ok := lineItemAmount(data[key]); ok && !isLineItemKey(key) {
+				add(key, amount)
+			}
+		}
+		for _, key := range keys {
+			collectLineItems(data[key], items)
+		}
+
+	case []interface{}:
+		if len(data) >= 2 {
+			if name, ok := data[0].(string); ok {
+				if amount, ok := lineItemAmount(data[1]); ok {
+					add(name, amount)
+				}
+			}
+		}
+		for _, value := range data {
+			collectLineItems(value, items)
+		}
+	}
+}
+
+func lineItemAmount(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int32:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case string:
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+		return amount, err == nil
+	}
+	return 0, false
+}
+
+func isLineItemKey(key string) bool {
+	for _, keys := range [][]string{lineItemAmountKeys, lineItemNameKeys} {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// KPIRepository stores the KPIs of organizations and their computed values.
+type KPIRepository interface {
+	Create(ctx context.Context, kpi *KPI) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*KPI, error)
+	// GetAll lists the KPIs of the tenant of ctx by name
+	GetAll(ctx context.Context) ([]*KPI, error)
+	Update(ctx context.Context, id primitive.ObjectID, kpi *KPI) error
+	// Delete removes a KPI and its values
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// SaveValue replaces the value of the KPI computed from the report
+	SaveValue(ctx context.Context, value *KPIValue) error
+	// DeleteValues removes the values computed from a report, of one KPI or of all when kpi is nil
+	DeleteValues(ctx context.Context, report primitive.ObjectID, kpi *primitive.ObjectID) error
+	// GetValues lists the values of a company's KPIs, or of one KPI, by year and then computation
+	GetValues(ctx context.Context, company primitive.ObjectID, kpi *primitive.ObjectID) ([]*KPIValue, error)
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountCategory places an account in the financial statements. Assets and expenses have
+// debit balances; liabilities, equity and revenue credit balances.
+type AccountCategory string
+
+const (
+	AccountAsset     AccountCategory = "ASSET"
+	AccountLiability AccountCategory = "LIABILITY"
+	AccountEquity    AccountCategory = "EQUITY"
+	AccountRevenue   AccountCategory = "REVENUE"
+	AccountExpense   AccountCategory = "EXPENSE"
+)
+
+// Account is an account of a chart of accounts. Group heads the lines of the account in
+// generated statements, e.g. "Current Assets"; accounts without one are listed under their
+// category.
+type Account struct {
+	Code     string          `bson:"code" json:"code"`
+	Name     string          `bson:"name" json:"name"`
+	Category AccountCategory `bson:"category" json:"category"`
+	Group    string          `bson:"group,omitempty" json:"group,omitempty"`
+}
+
+// ChartOfAccounts is the accounts a company's trial balances are kept in, in statement order.
+type ChartOfAccounts struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Company  primitive.ObjectID `bson:"company" json:"company"`
+	Accounts []Account          `bson:"accounts" json:"accounts"`
+	// Template is the library template the accounts were last copied from; changes made by
+	// hand since keep it
+	Template  *TemplateOrigin    `bson:"template,omitempty" json:"template,omitempty"`
+	UpdatedBy primitive.ObjectID `bson:"updatedBy" json:"updatedBy"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Account returns the account with code, or nil.
+func (c *ChartOfAccounts) Account(code string) *Account {
+	for i := range c.Accounts {
+		if c.Accounts[i].Code == code {
+			return &c.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// TrialBalanceLine is the debit and credit total of one account.
+type TrialBalanceLine struct {
+	Account string  `bson:"account" json:"account"`
+	Debit   float64 `bson:"debit" json:"debit"`
+	Credit  float64 `bson:"credit" json:"credit"`
+}
+
+// TrialBalance is the closing balances of a company's accounts for a year, as ingested from
+// its accounting system, and the reports generated from it.
+type TrialBalance struct {
+	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Company   primitive.ObjectID   `bson:"company" json:"company"`
+	Year      int                  `bson:"year" json:"year"`
+	Currency  *string              `bson:"currency,omitempty" json:"currency,omitempty"`
+	Lines     []TrialBalanceLine   `bson:"lines" json:"lines"`
+	Debit     float64              `bson:"debit" json:"debit"`   // total of the lines, kept for lists
+	Credit    float64              `bson:"credit" json:"credit"` // total of the lines, kept for lists
+	Reports   []primitive.ObjectID `bson:"reports" json:"reports"`
+	CreatedBy primitive.ObjectID   `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
+}
+
+// Totals returns the sums of the debits and credits of the lines.
+func (t *TrialBalance) Totals() (debit, credit float64) {
+	for _, line := range t.Lines {
+		debit += line.Debit
+		credit += line.Credit
+	}
+	return roundCents(debit), roundCents(credit)
+}
+
+// Balanced reports whether the debits and credits agree to the cent.
+func (t *TrialBalance) Balanced() bool {
+	debit, credit := t.Totals()
+	return debit == credit
+}
+
+// Balance returns the balance of an account of category, positive when it is on the
+// account's normal side.
+func (l TrialBalanceLine) Balance(category AccountCategory) float64 {
+	if category == AccountAsset || category == AccountExpense {
+		return roundCents(l.Debit - l.Credit)
+	}
+	return roundCents(l.Credit - l.Debit)
+}
+
+// Statement is a financial statement generated from a trial balance: sections of account
+// lines with their totals, and the figure the statement ends in.
+type Statement struct {
+	Sections []StatementSection `bson:"sections" json:"sections"`
+	Total    StatementTotal     `bson:"total" json:"total"`
+}
+
+type StatementSection struct {
+	Name  string          `bson:"name" json:"name"`
+	Lines []StatementLine `bson:"lines" json:"lines"`
+	Total float64         `bson:"total" json:"total"`
+}
+
+type StatementLine struct {
+	Account string  `bson:"account,omitempty" json:"account,omitempty"` // empty for current year earnings
+	Name    string  `bson:"name" json:"name"`
+	Amount  float64 `bson:"amount" json:"amount"`
+}
+
+// StatementTotal is the figure a statement ends in: the net income of a P&L, or the total
+// assets of a balance sheet.
+type StatementTotal struct {
+	Name   string  `bson:"name" json:"name"`
+	Amount float64 `bson:"amount" json:"amount"`
+}
+
+// ReportTotal returns the figure report data ends in: its top-level total, a number or an
+// object with an amount and name like the StatementTotal of generated statements.
+func ReportTotal(reportData interface{}) (name string, amount float64, ok bool) {
+	data, isObject := reportData.(map[string]interface{})
+	switch reportData := reportData.(type) {
+	case primitive.M:
+		data, isObject = reportData, true
+	case primitive.D:
+		data, isObject = reportData.Map(), true
+	}
+	if !isObject {
+		return "", 0, false
+	}
+
+	total := data["total"]
+	switch value := total.(type) {
+	case primitive.M:
+		total = map[string]interface{}(value)
+	case primitive.D:
+		total = map[string]interface{}(value.Map())
+	}
+	if object, isObject := total.(map[string]interface{}); isObject {
+		name, _ = object["name"].(string)
+		amount, ok = lineItemAmount(object["amount"])
+		return name, amount, ok
+	}
+	amount, ok = lineItemAmount(total)
+	return "", amount, ok
+}
+
+// ProfitAndLoss returns the revenue and expenses of the trial balance and the net income.
+func (t *TrialBalance) ProfitAndLoss(chart *ChartOfAccounts) Statement {
+	sections := t.sections(chart, AccountRevenue, AccountExpense)
+	return Statement{
+		Sections: sections,
+		Total:    StatementTotal{Name: "Net Income", Amount: t.netIncome(chart)},
+	}
+}
+
+// BalanceSheet returns the assets, liabilities and equity of the trial balance. The year's
+// net income is added to equity as current year earnings, since revenue and expense accounts
+// are not closed yet in a trial balance.
+func (t *TrialBalance) BalanceSheet(chart *ChartOfAccounts) Statement {
+	sections := t.sections(chart, AccountAsset, AccountLiability, AccountEquity)
+	netIncome := t.netIncome(chart)
+
+	equity := &sections[len(sections)-1]
+	equity.Lines = append(equity.Lines, StatementLine{Name: "Current Year Earnings", Amount: netIncome})
+	equity.Total = roundCents(equity.Total + netIncome)
+
+	totalAssets := 0.0
+	for code, amount := range t.balances(chart) {
+		if chart.Account(code).Category == AccountAsset {
+			totalAssets += amount
+		}
+	}
+
+	return Statement{
+		Sections: sections,
+		Total:    StatementTotal{Name: "Total Assets", Amount: roundCents(totalAssets)},
+	}
+}
+
+// categoryTitles head the sections of accounts without a group
+var categoryTitles = map[AccountCategory]string{
+	AccountAsset:     "Assets",
+	AccountLiability: "Liabilities",
+	AccountEquity:    "Equity",
+	AccountRevenue:   "Revenue",
+	AccountExpense:   "Expenses",
+}
+
+// sections sums the balances of the trial balance into sections per category: one per group
+// of the category's accounts, in chart order, followed by one for its accounts without a
+// group. The last section, the equity of a balance sheet, is kept even when empty.
+func (t *TrialBalance) sections(chart *ChartOfAccounts, categories ...AccountCategory) []StatementSection {
+	balances := t.balances(chart)
+
+	sections := []StatementSection{}
+	for i, category := range categories {
+		index := map[string]int{}
+		ungrouped := StatementSection{Name: categoryTitles[category], Lines: []StatementLine{}}
+
+		for _, account := range chart.Accounts {
+			amount, ok := balances[account.Code]
+			if account.Category != category || !ok {
+				continue
+			}
+
+			section := &ungrouped
+			if account.Group != "" {
+				if _, ok := index[account.Group]; !ok {
+					index[account.Group] = len(sections)
+					sections = append(sections, StatementSection{Name: account.Group, Lines: []StatementLine{}})
+				}
+				section = &sections[index[account.Group]]
+			}
+			section.Lines = append(section.Lines, StatementLine{Account: account.Code, Name: account.Name, Amount: amount})
+			section.Total = roundCents(section.Total + amount)
+		}
+
+		if len(ungrouped.Lines) > 0 || i == len(categories)-1 {
+			sections = append(sections, ungrouped)
+		}
+	}
+	return sections
+}
+
+// balances returns the balance of each account of the trial balance on its normal side.
+// Accounts missing from the chart are left out; ingestion rejects them.
+func (t *TrialBalance) balances(chart *ChartOfAccounts) map[string]float64 {
+	balances := map[string]float64{}
+	for _, line := range t.Lines {
+		if account := chart.Account(line.Account); account != nil {
+			balances[line.Account] = roundCents(balances[line.Account] + line.Balance(account.Category))
+		}
+	}
+	return balances
+}
+
+func (t *TrialBalance) netIncome(chart *ChartOfAccounts) float64 {
+	total := 0.0
+	for code, amount := range t.balances(chart) {
+		switch chart.Account(code).Category {
+		case AccountRevenue:
+			total += amount
+		case AccountExpense:
+			total -= amount
+		}
+	}
+	return roundCents(total)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// LedgerRepository stores the charts of accounts and trial balances of companies.
+type LedgerRepository interface {
+	GetChart(ctx context.Context, company primitive.ObjectID) (*ChartOfAccounts, error)
+	SaveChart(ctx context.Context, chart *ChartOfAccounts) error
+	CreateTrialBalance(ctx context.Context, trialBalance *TrialBalance) error
+	GetTrialBalance(ctx context.Context, id primitive.ObjectID) (*TrialBalance, error)
+	// GetTrialBalances lists the trial balances of a company, or of every company the caller
+	// can see when company is nil, newest first
+	GetTrialBalances(ctx context.Context, company *primitive.ObjectID) ([]*TrialBalance, error)
+	SetReports(ctx context.Context, id primitive.ObjectID, reports []primitive.ObjectID) error
+}
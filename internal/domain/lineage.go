@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sources of report data recorded in ReportLineage
+const (
+	LineageManual       = "manual"        // entered through the API with a user's token
+	LineageAPIKey       = "api_key"       // sent by an integration authenticated with an API key
+	LineageTrialBalance = "trial_balance" // generated from an ingested trial balance
+	LineageImport       = "import"        // read from an uploaded XLSX file
+	LineageAI           = "ai"            // extracted by an AI ingestion task
+)
+
+// ReportLineage records where the data of a report came from, for auditors tracing its
+// numbers back to their origin. It is replaced whenever the report's data is.
+type ReportLineage struct {
+	Source string `bson:"source" json:"source"`
+	// Actor is the user whose request wrote the data; nil for background writes
+	Actor        *primitive.ObjectID `bson:"actor,omitempty" json:"actor,omitempty"`
+	APIKey       *primitive.ObjectID `bson:"apiKey,omitempty" json:"apiKey,omitempty"`
+	TrialBalance *primitive.ObjectID `bson:"trialBalance,omitempty" json:"trialBalance,omitempty"`
+	FileID       string              `bson:"fileId,omitempty" json:"fileId,omitempty"` // object store key of the imported file
+	Task         *primitive.ObjectID `bson:"task,omitempty" json:"task,omitempty"`
+	RecordedAt   time.Time           `bson:"recordedAt" json:"recordedAt"`
+}
+
+type lineageKey struct{}
+
+// WithLineage returns a context whose report writes record lineage as the source of their
+// data. Writes without one are recorded as manual entries, or as sent with the request's API
+// key.
+func WithLineage(ctx context.Context, lineage *ReportLineage) context.Context {
+	return context.WithValue(ctx, lineageKey{}, lineage)
+}
+
+// LineageOf returns the lineage set with WithLineage, or nil.
+func LineageOf(ctx context.Context) *ReportLineage {
+	lineage, _ := ctx.Value(lineageKey{}).(*ReportLineage)
+	return lineage
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reasons a login is flagged as suspicious
+const (
+	LoginNewCountry       = "new_country"       // no recent login came from the country
+	LoginNewIP            = "new_ip"            // no recent login came from the IP address
+	LoginImpossibleTravel = "impossible_travel" // too far from the previous login for the time between them
+)
+
+// Outcomes of a sign-in attempt
+const (
+	LoginSucceeded = "success"
+	LoginFailed    = "failure" // the password was wrong
+)
+
+// Login is a sign-in attempt of a user. New successful logins are compared with the recent ones
+// of the user to spot sign-ins from unusual places.
+type Login struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID primitive.ObjectID `bson:"userId" json:"userId"`
+	// Outcome is LoginSucceeded or LoginFailed; logins recorded before failures were have none
+	Outcome   string    `bson:"outcome,omitempty" json:"outcome"`
+	IP        string    `bson:"ip" json:"ip"`
+	Country   string    `bson:"country,omitempty" json:"country,omitempty"` // ISO 3166-1 alpha-2, when known
+	Location  *GeoPoint `bson:"location,omitempty" json:"location,omitempty"`
+	UserAgent string    `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	// Suspicious lists why the login looked unusual; empty for ordinary logins
+	Suspicious []string  `bson:"suspicious,omitempty" json:"suspicious,omitempty"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// GeoPoint is an approximate location, as reported by the load balancer for a client IP.
+type GeoPoint struct {
+	Latitude  float64 `bson:"lat" json:"lat"`
+	Longitude float64 `bson:"lng" json:"lng"`
+}
+
+type LoginRepository interface {
+	Create(ctx context.Context, login *Login) error
+	// GetRecent returns the latest successful logins of a user, newest first
+	GetRecent(ctx context.Context, userID primitive.ObjectID, limit int) ([]*Login, error)
+	// GetByUser returns a page of the sign-in attempts of a user, newest first, and how many
+	// there are
+	GetByUser(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]*Login, int, error)
+}
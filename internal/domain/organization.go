@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Organization is a firm using the instance, such as a consulting firm and its client
+// companies. Users and companies of an organization never see those of another one; users
+// and companies without an organization belong to the instance itself, as they did before
+// organizations existed.
+type Organization struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"`
+	// Admins are members who see every company of the organization and manage its settings
+	Admins    []primitive.ObjectID `bson:"admins" json:"admins"`
+	Settings  OrganizationSettings `bson:"settings" json:"settings"`
+	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time            `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt *time.Time           `bson:"deletedAt,omitempty" json:"-"`
+}
+
+// OrganizationSettings are the defaults the web app applies for the organization's members.
+type OrganizationSettings struct {
+	Currency string `bson:"currency,omitempty" json:"currency,omitempty"` // ISO 4217 code for new reports
+	Locale   string `bson:"locale,omitempty" json:"locale,omitempty"`     // language code, e.g. "id"
+	Timezone string `bson:"timezone,omitempty" json:"timezone,omitempty"` // IANA name, e.g. "Asia/Jakarta"
+}
+
+// IsAdmin reports whether the user is one of the organization's admins.
+func (o *Organization) IsAdmin(userID primitive.ObjectID) bool {
+	for _, id := range o.Admins {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// OrganizationMembership is the tenant of a request and whether the user asking is one of
+// the organization's admins.
+type OrganizationMembership struct {
+	Tenant
+	Admin bool
+}
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *Organization) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Organization, error)
+	GetAll(ctx context.Context) ([]*Organization, error)
+	Update(ctx context.Context, id primitive.ObjectID, organization *Organization) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// Companies and Users list the IDs of the organization's live companies and users, or of
+	// the instance's for primitive.NilObjectID
+	Companies(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error)
+	Users(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error)
+	// SetCompanyOrganization and SetUserOrganization move a company or user into an
+	// organization, or back to the instance when organizationID is nil
+	SetCompanyOrganization(ctx context.Context, companyID primitive.ObjectID, organizationID *primitive.ObjectID) error
+	SetUserOrganization(ctx context.Context, userID primitive.ObjectID, organizationID *primitive.ObjectID) error
+}
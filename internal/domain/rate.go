@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RateSourceManual marks a rate entered or changed by hand, which provider syncs leave alone.
+const RateSourceManual = "manual"
+
+// ExchangeRate is what one unit of Base buys in Quote from EffectiveDate until the next rate of
+// the pair. There is one rate per pair and day.
+type ExchangeRate struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Base          string              `bson:"base" json:"base"`
+	Quote         string              `bson:"quote" json:"quote"`
+	Rate          float64             `bson:"rate" json:"rate"`
+	EffectiveDate time.Time           `bson:"effectiveDate" json:"effectiveDate"` // midnight UTC
+	Source        string              `bson:"source" json:"source"`               // RateSourceManual or the provider
+	UpdatedBy     *primitive.ObjectID `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// RateFilter narrows a listing of rates; empty fields match every rate.
+type RateFilter struct {
+	Base  string
+	Quote string
+	From  *time.Time
+	To    *time.Time
+}
+
+// RateRepository stores exchange rates. They are shared by every organization.
+type RateRepository interface {
+	Create(ctx context.Context, rate *ExchangeRate) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*ExchangeRate, error)
+	// GetAll lists rates by pair and then effective date, newest first
+	GetAll(ctx context.Context, filter RateFilter, skip, limit int) ([]*ExchangeRate, int, error)
+	Update(ctx context.Context, id primitive.ObjectID, rate *ExchangeRate) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// SaveSynced stores rates fetched from a provider, replacing earlier fetches of the same
+	// pair and day but not manual rates, and returns how many were stored
+	SaveSynced(ctx context.Context, rates []*ExchangeRate) (int, error)
+	// Effective returns the rate of the pair in effect on date: the latest one effective on
+	// or before it
+	Effective(ctx context.Context, base, quote string, date time.Time) (*ExchangeRate, error)
+}
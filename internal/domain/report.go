@@ -16,9 +16,14 @@ type Report struct {
 	Currency   *string              `bson:"currency,omitempty" json:"currency"`
 	CreatedBy  primitive.ObjectID   `bson:"createdBy" json:"createdBy"`
 	UserAccess []primitive.ObjectID `bson:"userAccess" json:"userAccess"`
-	ReportData interface{}          `bson:"reportData" json:"reportData"`
-	CreatedAt  time.Time            `bson:"createdAt" json:"createdAt"`
-	UpdatedAt  time.Time            `bson:"updatedAt" json:"updatedAt"`
+	// AccessLevels are the levels of the users in UserAccess; users without one can view
+	AccessLevels []ReportAccess  `bson:"accessLevels,omitempty" json:"accessLevels,omitempty"`
+	ReportData   interface{}     `bson:"reportData" json:"reportData"`
+	Lineage      *ReportLineage  `bson:"lineage,omitempty" json:"lineage,omitempty"` // nil for reports written before it was recorded
+	Approval     *ReportApproval `bson:"approval,omitempty" json:"approval,omitempty"`
+	CreatedAt    time.Time       `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time       `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt    *time.Time      `bson:"deletedAt,omitempty" json:"-"`
 }
 
 type PopulatedReport struct {
@@ -30,19 +35,98 @@ type PopulatedReport struct {
 	Currency   *string            `bson:"currency,omitempty" json:"currency"`
 	CreatedBy  *User              `bson:"createdBy" json:"createdBy"`
 	UserAccess []*User            `bson:"userAccess" json:"userAccess"`
-	ReportData interface{}        `bson:"reportData" json:"reportData"`
-	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+	// AccessLevels are the levels of the users in UserAccess; users without one can view
+	AccessLevels []ReportAccess  `bson:"accessLevels,omitempty" json:"accessLevels,omitempty"`
+	ReportData   interface{}     `bson:"reportData" json:"reportData"`
+	Lineage      *ReportLineage  `bson:"lineage,omitempty" json:"lineage,omitempty"`
+	Approval     *ReportApproval `bson:"approval,omitempty" json:"approval,omitempty"`
+	CreatedAt    time.Time       `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time       `bson:"updatedAt" json:"updatedAt"`
 }
 
+// ReportApproval records who signed off a report's figures, and when. Edits made later keep
+// it; they are flagged as anomalies instead.
+type ReportApproval struct {
+	By primitive.ObjectID `bson:"by" json:"by"`
+	At time.Time          `bson:"at" json:"at"`
+}
+
+// ReportAccessLevel is what a user in a report's userAccess may do with it. Each level
+// includes the ones below it.
+type ReportAccessLevel string
+
+const (
+	ReportAccessView  ReportAccessLevel = "VIEW"
+	ReportAccessEdit  ReportAccessLevel = "EDIT"  // change the report
+	ReportAccessAdmin ReportAccessLevel = "ADMIN" // also delete it and manage who has access
+)
+
+var reportAccessRanks = map[ReportAccessLevel]int{ReportAccessView: 1, ReportAccessEdit: 2, ReportAccessAdmin: 3}
+
+func (l ReportAccessLevel) IsValid() bool {
+	return reportAccessRanks[l] > 0
+}
+
+// Includes reports whether users with level l may do what other allows.
+func (l ReportAccessLevel) Includes(other ReportAccessLevel) bool {
+	return reportAccessRanks[l] > 0 && reportAccessRanks[l] >= reportAccessRanks[other]
+}
+
+// ReportAccess is the level of a user given access to a report.
+type ReportAccess struct {
+	User  primitive.ObjectID `bson:"user" json:"user"`
+	Level ReportAccessLevel  `bson:"level" json:"level"`
+}
+
+// AccessLevel returns the level of the user on the report, or "" when they were not given
+// access. Users given access before levels existed can view.
+func (r *PopulatedReport) AccessLevel(userID primitive.ObjectID) ReportAccessLevel {
+	for _, user := range r.UserAccess {
+		if user != nil && user.ID == userID {
+			return accessLevelOf(r.AccessLevels, userID)
+		}
+	}
+	return ""
+}
+
+func accessLevelOf(levels []ReportAccess, userID primitive.ObjectID) ReportAccessLevel {
+	for _, access := range levels {
+		if access.User == userID && access.Level.IsValid() {
+			return access.Level
+		}
+	}
+	return ReportAccessView
+}
+
+type withReportDataKey struct{}
+
+// WithReportData returns a context that makes report list reads include reportData. Lists
+// leave it out by default since it dominates their size; single-report reads always include it.
+func WithReportData(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withReportDataKey{}, true)
+}
+
+// IncludesReportData reports whether the context was marked with WithReportData.
+func IncludesReportData(ctx context.Context) bool {
+	include, _ := ctx.Value(withReportDataKey{}).(bool)
+	return include
+}
+
+// ReportRepository reads and writes reports. The list reads leave reportData nil unless the
+// context was marked with WithReportData.
 type ReportRepository interface {
 	Create(ctx context.Context, report *Report) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*PopulatedReport, error)
 	GetByName(ctx context.Context, name string) (*PopulatedReport, error)
 	GetAll(ctx context.Context) ([]*PopulatedReport, error)
+	// Each calls fn with the reports of GetAll one at a time as they are read, stopping at
+	// the first error fn returns
+	Each(ctx context.Context, fn func(*PopulatedReport) error) error
 	GetAllPaginated(ctx context.Context, skip, limit int) ([]*PopulatedReport, int, error)
 	GetByCompany(ctx context.Context, companyID primitive.ObjectID) ([]*PopulatedReport, error)
 	GetByCompanies(ctx context.Context, companyIDs []primitive.ObjectID) ([]*PopulatedReport, error)
+	// GetUpdatedSince returns the reports of companyIDs created or updated at or after since
+	GetUpdatedSince(ctx context.Context, companyIDs []primitive.ObjectID, since time.Time) ([]*PopulatedReport, error)
 	GetByReportType(ctx context.Context, reportTypeID primitive.ObjectID) ([]*PopulatedReport, error)
 	GetByUserAccess(ctx context.Context, userID primitive.ObjectID) ([]*PopulatedReport, error)
 	GetByCreatedBy(ctx context.Context, userID primitive.ObjectID) ([]*PopulatedReport, error)
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportSummary holds the key figures of a company's live reports of one year. Summaries are
+// kept up to date on every report write, so dashboards read one small document instead of
+// aggregating the reports.
+type ReportSummary struct {
+	Company       primitive.ObjectID `bson:"company" json:"company"`
+	Year          int                `bson:"year" json:"year"`
+	ReportCount   int                `bson:"reportCount" json:"reportCount"`
+	ReportTypes   []ReportTypeCount  `bson:"reportTypes" json:"reportTypes"`
+	Currencies    []string           `bson:"currencies" json:"currencies"`
+	LastUpdatedAt time.Time          `bson:"lastUpdatedAt" json:"lastUpdatedAt"` // latest change to one of the reports
+	RefreshedAt   time.Time          `bson:"refreshedAt" json:"refreshedAt"`
+}
+
+// ReportTypeCount is the number of reports of one type in a summary.
+type ReportTypeCount struct {
+	ReportType primitive.ObjectID `bson:"reportType" json:"reportType"`
+	Count      int                `bson:"count" json:"count"`
+}
+
+// ReportSummaryRepository maintains the report summaries. Reads are limited to the companies
+// of the context's access scope.
+type ReportSummaryRepository interface {
+	// Refresh recomputes the summary of a company and year from its reports, removing it once
+	// no reports are left
+	Refresh(ctx context.Context, companyID primitive.ObjectID, year int) error
+	// Rebuild recomputes every summary, e.g. after reports were changed outside the API,
+	// and returns how many there are
+	Rebuild(ctx context.Context) (int, error)
+	// List returns the summaries of the given companies, or of every company when there are
+	// none, for one year or every year when year is 0, newest year first
+	List(ctx context.Context, companyIDs []primitive.ObjectID, year int) ([]*ReportSummary, error)
+}
@@ -2,13 +2,15 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReportType struct {
-	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name string             `bson:"name" json:"name"`
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty" json:"-"`
 }
 
 type ReportTypeRepository interface {
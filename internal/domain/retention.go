@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RetentionPolicy sets how long data is kept before the purge job removes it. Zero keeps
+// the data forever.
+type RetentionPolicy struct {
+	AuditLogYears     int `bson:"auditLogYears" json:"auditLogYears"`
+	TrashedReportDays int `bson:"trashedReportDays" json:"trashedReportDays"`
+	AuthEventMonths   int `bson:"authEventMonths" json:"authEventMonths"`
+}
+
+// CompanyRetention overrides the retention of a company's own data: the audit log events of
+// its reports and the company itself, its trashed reports and the logins of its users. Zero
+// fields fall back to the default policy. Events of no company, such as user updates, always
+// follow the default.
+type CompanyRetention struct {
+	CompanyID         primitive.ObjectID `bson:"_id" json:"companyId"`
+	AuditLogYears     int                `bson:"auditLogYears" json:"auditLogYears"`
+	TrashedReportDays int                `bson:"trashedReportDays" json:"trashedReportDays"`
+	AuthEventMonths   int                `bson:"authEventMonths" json:"authEventMonths"`
+	UpdatedAt         time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PurgeScope limits a purge to the documents of some owners (companies for reports, users
+// for logins). With Only set, just those owners; otherwise everyone but Except.
+type PurgeScope struct {
+	Only   []primitive.ObjectID
+	Except []primitive.ObjectID
+}
+
+// RetentionRepository stores the company overrides and removes expired data. With dryRun
+// set, the purge methods only count what they would remove.
+type RetentionRepository interface {
+	GetCompanyRetentions(ctx context.Context) ([]*CompanyRetention, error)
+	SaveCompanyRetention(ctx context.Context, retention *CompanyRetention) error
+	DeleteCompanyRetention(ctx context.Context, companyID primitive.ObjectID) error
+
+	// PurgeEvents removes dispatched and failed outbox events of the scope's companies created
+	// before before. Events of no company are only in scopes with Except.
+	PurgeEvents(ctx context.Context, before time.Time, scope PurgeScope, dryRun bool) (int64, error)
+	// PurgeTrashedReports removes reports of the scope's companies soft-deleted before deletedBefore.
+	PurgeTrashedReports(ctx context.Context, deletedBefore time.Time, scope PurgeScope, dryRun bool) (int64, error)
+	// PurgeLogins removes logins of the scope's users recorded before before.
+	PurgeLogins(ctx context.Context, before time.Time, scope PurgeScope, dryRun bool) (int64, error)
+}
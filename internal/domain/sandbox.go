@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SandboxConfig enables the sandbox: a separate MongoDB database that requests sent with the
+// X-Sandbox header read and write instead of the real one, so integrators can try report
+// submission without touching real financial data.
+type SandboxConfig struct {
+	Enabled  bool
+	Database string // defaults to the real database's name with a "_sandbox" suffix
+	SeedFile string // JSON SandboxSeed replacing the built-in one
+	// ResetInterval is how often the sandbox is wiped and seeded again
+	ResetInterval time.Duration
+}
+
+// SandboxSeed is the dataset a reset leaves in the sandbox, besides the real report types,
+// which are copied with their IDs so integrators can use the same ones in both.
+type SandboxSeed struct {
+	ReportTypes []string         `json:"reportTypes"` // created when no real type has the name
+	Companies   []SandboxCompany `json:"companies"`
+}
+
+// SandboxCompany is a company of the seed and its reports.
+type SandboxCompany struct {
+	Name    string          `json:"name"`
+	Reports []SandboxReport `json:"reports"`
+}
+
+// SandboxReport is a report of the seed. ReportType names a report type.
+type SandboxReport struct {
+	ReportName string      `json:"reportName"`
+	ReportType string      `json:"reportType"`
+	Year       int         `json:"year"`
+	Currency   string      `json:"currency,omitempty"`
+	ReportData interface{} `json:"reportData"`
+}
+
+// SandboxReset records the last reset of the sandbox and what it seeded.
+type SandboxReset struct {
+	ResetAt     time.Time `bson:"resetAt" json:"resetAt"`
+	ReportTypes int       `bson:"reportTypes" json:"reportTypes"`
+	Companies   int       `bson:"companies" json:"companies"`
+	Reports     int       `bson:"reports" json:"reports"`
+}
+
+// SandboxRepository manages the sandbox database. The sandbox's companies, reports and
+// other rows are read and written through the usual repositories opened on it.
+type SandboxRepository interface {
+	// Reset drops every collection of the sandbox, copies the real report types and loads seed
+	Reset(ctx context.Context, seed *SandboxSeed) (*SandboxReset, error)
+	// ClaimReset records a reset starting now unless one started after since, so only one
+	// instance runs a scheduled reset
+	ClaimReset(ctx context.Context, since time.Time) (bool, error)
+	// LastReset returns the last reset, or nil before the first one
+	LastReset(ctx context.Context) (*SandboxReset, error)
+	// Companies lists the IDs of the sandbox's live companies
+	Companies(ctx context.Context) ([]primitive.ObjectID, error)
+	// EnsureUser copies a real user into the sandbox without credentials, so the companies
+	// and reports of the sandbox can name them
+	EnsureUser(ctx context.Context, user *User) error
+}
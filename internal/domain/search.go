@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of documents searched.
+const (
+	SearchKindReport  = "report"
+	SearchKindCompany = "company"
+	SearchKindUser    = "user"
+)
+
+// Facets counted over the matches of a search.
+const (
+	SearchFacetKind       = "kind"
+	SearchFacetCompany    = "company"
+	SearchFacetReportType = "reportType"
+	SearchFacetYear       = "year"
+)
+
+// SearchQuery is a full-text search over reports, companies and users. Filters on report
+// fields exclude the other kinds.
+type SearchQuery struct {
+	Text       string
+	Kinds      []string // every kind when empty
+	Company    *primitive.ObjectID
+	ReportType *primitive.ObjectID
+	Year       *int
+	Limit      int
+}
+
+// SearchHit is a matching report, company or user.
+type SearchHit struct {
+	Kind       string
+	ID         primitive.ObjectID
+	Title      string // report, company or user name
+	Detail     string // user email
+	Company    *primitive.ObjectID
+	ReportType *primitive.ObjectID
+	Year       int
+	Score      float64 // relevance, higher first; 0 for backends that don't rank
+}
+
+// SearchFacetValue counts the matches sharing a value of a facet.
+type SearchFacetValue struct {
+	Value string
+	Count int64
+}
+
+// SearchResults are the best hits of a search, and counts over all its matches.
+type SearchResults struct {
+	Total  int64
+	Hits   []SearchHit
+	Facets map[string][]SearchFacetValue // by SearchFacet*, most frequent first
+}
+
+// SearchRepository searches the reports, companies and users the context may read.
+type SearchRepository interface {
+	// Backend names the search backend, e.g. "mongo", "atlas" or "elasticsearch"
+	Backend() string
+	Search(ctx context.Context, query SearchQuery) (*SearchResults, error)
+}
+
+// SearchDocument is a report, company or user as a search index holds it, with the fields
+// searches filter on and access is checked against.
+type SearchDocument struct {
+	Kind         string
+	ID           primitive.ObjectID
+	Title        string
+	Detail       string
+	Organization *primitive.ObjectID  // of companies and users
+	Company      *primitive.ObjectID  // of reports, and companies themselves
+	Companies    []primitive.ObjectID // of users
+	ReportType   *primitive.ObjectID
+	Year         int
+	CreatedBy    *primitive.ObjectID
+	Users        []primitive.ObjectID // with access to a report, or of a company
+}
+
+// SearchIndex is a search backend outside the database, kept in sync with it.
+type SearchIndex interface {
+	SearchRepository
+	// Index adds or replaces documents
+	Index(ctx context.Context, documents []SearchDocument) error
+	// Remove drops a document, if indexed
+	Remove(ctx context.Context, kind string, id primitive.ObjectID) error
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is a token issued at login, with the device it was issued to. Stores purge sessions
+// past ExpiresAt, when their token stops being accepted anyway.
+type Session struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID primitive.ObjectID `bson:"userId" json:"userId"`
+	// TokenID is the ID (jti claim) of the session's token
+	TokenID    string    `bson:"tokenId" json:"-"`
+	IP         string    `bson:"ip" json:"ip"`
+	Country    string    `bson:"country,omitempty" json:"country,omitempty"`
+	UserAgent  string    `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	LastSeenAt time.Time `bson:"lastSeenAt" json:"lastSeenAt"`
+	ExpiresAt  time.Time `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	// GetByUser returns the unexpired sessions of a user, most recently seen first
+	GetByUser(ctx context.Context, userID primitive.ObjectID) ([]*Session, error)
+	// GetByID returns the session of the user with the ID, or SESSION_NOT_FOUND
+	GetByID(ctx context.Context, userID, id primitive.ObjectID) (*Session, error)
+	// Touch records that the session's token was used at seenAt
+	Touch(ctx context.Context, tokenID string, seenAt time.Time) error
+	Delete(ctx context.Context, tokenID string) error
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID) error
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type withDeletedKey struct{}
+
+// WithDeleted returns a context that makes repository reads include soft-deleted documents.
+// Intended for admin queries (audits, restores) that need to see everything.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withDeletedKey{}, true)
+}
+
+// IncludesDeleted reports whether the context was marked with WithDeleted.
+func IncludesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(withDeletedKey{}).(bool)
+	return include
+}
+
+// SoftDeleteCollections lists the collections whose deletes only set deletedAt.
+var SoftDeleteCollections = []string{"users", "companies", "reports", "reporttypes"}
+
+// TrashRepository permanently removes soft-deleted documents.
+type TrashRepository interface {
+	// Purge deletes documents of collection that were soft-deleted before deletedBefore
+	// and returns how many were removed.
+	Purge(ctx context.Context, collection string, deletedBefore time.Time) (int64, error)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SSOConnection lets the users of a company sign in with the company's identity provider
+// through OpenID Connect. Each company has at most one.
+type SSOConnection struct {
+	CompanyID    primitive.ObjectID `bson:"_id" json:"companyId"`
+	Issuer       string             `bson:"issuer" json:"issuer"`
+	ClientID     string             `bson:"clientId" json:"clientId"`
+	ClientSecret string             `bson:"clientSecret" json:"-"`
+	// Domains are the email domains the provider signs users in for, e.g. "acme.com". No two
+	// connections share a domain
+	Domains []string `bson:"domains" json:"domains"`
+	// JITProvisioning creates users on their first sign-in, with DefaultRole and the company;
+	// without it only existing users of the company can sign in
+	JITProvisioning bool      `bson:"jitProvisioning" json:"jitProvisioning"`
+	DefaultRole     UserRole  `bson:"defaultRole" json:"defaultRole"`
+	Enabled         bool      `bson:"enabled" json:"enabled"`
+	CreatedAt       time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type SSOConnectionRepository interface {
+	// Get returns the connection of the company, or SSO_CONNECTION_NOT_FOUND.
+	Get(ctx context.Context, companyID primitive.ObjectID) (*SSOConnection, error)
+	// GetByDomain returns the connection signing in users of the email domain, or
+	// SSO_CONNECTION_NOT_FOUND.
+	GetByDomain(ctx context.Context, domain string) (*SSOConnection, error)
+	Save(ctx context.Context, connection *SSOConnection) error
+	Delete(ctx context.Context, companyID primitive.ObjectID) error
+}
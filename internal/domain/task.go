@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaskType names a kind of background task, e.g. "backup.create". Each type has one handler.
+type TaskType string
+
+type TaskStatus string
+
+const (
+	TaskStatusPending   TaskStatus = "PENDING"
+	TaskStatusRunning   TaskStatus = "RUNNING"
+	TaskStatusSucceeded TaskStatus = "SUCCEEDED"
+	TaskStatusFailed    TaskStatus = "FAILED"
+)
+
+// Task is a long-running operation executed by a worker. Payload and Result hold JSON.
+// A running task holds a lease until LockedUntil; if its worker dies the lease expires
+// and another worker picks the task up again.
+type Task struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type        TaskType           `bson:"type" json:"type"`
+	Status      TaskStatus         `bson:"status" json:"status"`
+	Progress    int                `bson:"progress" json:"progress"` // percent, 0-100
+	Payload     []byte             `bson:"payload,omitempty" json:"-"`
+	Result      []byte             `bson:"result,omitempty" json:"-"`
+	Error       *string            `bson:"error,omitempty" json:"error,omitempty"`
+	Attempts    int                `bson:"attempts" json:"attempts"`
+	CreatedBy   primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	RunAt       time.Time          `bson:"runAt" json:"runAt"`
+	LockedUntil *time.Time         `bson:"lockedUntil,omitempty" json:"-"`
+	StartedAt   *time.Time         `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	FinishedAt  *time.Time         `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+type TaskRepository interface {
+	Create(ctx context.Context, task *Task) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Task, error)
+	ListByUser(ctx context.Context, userID primitive.ObjectID, limit int) ([]*Task, error)
+	// CountByStatus counts tasks in the given status, e.g. the PENDING backlog.
+	CountByStatus(ctx context.Context, status TaskStatus) (int64, error)
+	// Claim atomically moves the oldest due task of the given types to RUNNING with a lease
+	// until lockedUntil, also reclaiming running tasks whose lease expired. It returns nil
+	// when there is nothing to do.
+	Claim(ctx context.Context, types []TaskType, lockedUntil time.Time) (*Task, error)
+	// UpdateProgress records progress and extends the lease of a running task.
+	UpdateProgress(ctx context.Context, id primitive.ObjectID, progress int, lockedUntil time.Time) error
+	Complete(ctx context.Context, id primitive.ObjectID, result []byte) error
+	// Fail records a failed attempt. Non-final failures go back to PENDING and run again at runAt.
+	Fail(ctx context.Context, id primitive.ObjectID, reason string, runAt time.Time, final bool) error
+	// Release puts a running task back to PENDING without counting an attempt, for tasks
+	// interrupted by a shutdown, so another instance runs it right away
+	Release(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ParseJurisdiction normalizes a tax jurisdiction: an ISO 3166-1 alpha-2 country code,
+// optionally followed by an ISO 3166-2 subdivision, e.g. "ID" or "US-CA".
+func ParseJurisdiction(value string) (string, error) {
+	jurisdiction := strings.ToUpper(strings.TrimSpace(value))
+	country, subdivision, hasSubdivision := strings.Cut(jurisdiction, "-")
+	if len(country) != 2 || !isAlphanumeric(country, false) {
+		return "", fmt.Errorf("%q does not start with a two-letter country code", value)
+	}
+	if hasSubdivision && (len(subdivision) < 1 || len(subdivision) > 3 || !isAlphanumeric(subdivision, true)) {
+		return "", fmt.Errorf("%q has an invalid subdivision", value)
+	}
+	return jurisdiction, nil
+}
+
+// JurisdictionScopes returns the jurisdictions whose taxes apply in jurisdiction: itself and,
+// for a subdivision, its country.
+func JurisdictionScopes(jurisdiction string) []string {
+	if country, _, ok := strings.Cut(jurisdiction, "-"); ok {
+		return []string{jurisdiction, country}
+	}
+	return []string{jurisdiction}
+}
+
+func isAlphanumeric(s string, digits bool) bool {
+	for _, c := range s {
+		if !(c >= 'A' && c <= 'Z') && !(digits && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// TaxRate is a statutory tax rate of a jurisdiction over a period, e.g. Indonesia's corporate
+// income tax from 2022. Rates of the same name and jurisdiction don't overlap.
+type TaxRate struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Jurisdiction string             `bson:"jurisdiction" json:"jurisdiction"`
+	Name         string             `bson:"name" json:"name"`
+	Rate         float64            `bson:"rate" json:"rate"` // percent
+	// EffectiveFrom and EffectiveTo bound the period the rate applies to; EffectiveTo is
+	// exclusive and nil while the rate is current
+	EffectiveFrom time.Time  `bson:"effectiveFrom" json:"effectiveFrom"`
+	EffectiveTo   *time.Time `bson:"effectiveTo,omitempty" json:"effectiveTo,omitempty"`
+	// Check is a formula over report line items giving the effective rate in percent, e.g.
+	// "[Income Tax Expense] / [Profit Before Tax] * 100". Reports whose effective rate is more
+	// than Tolerance points off Rate get a warning. No check when empty.
+	Check     string  `bson:"check,omitempty" json:"check,omitempty"`
+	Tolerance float64 `bson:"tolerance" json:"tolerance"`
+	// ReportType limits the check to reports of a type, e.g. the P&L; every report when nil
+	ReportType *primitive.ObjectID `bson:"reportType,omitempty" json:"reportType,omitempty"`
+	UpdatedBy  primitive.ObjectID  `bson:"updatedBy" json:"updatedBy"`
+	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// InEffect reports whether the rate applies on t.
+func (r *TaxRate) InEffect(t time.Time) bool {
+	return !t.Before(r.EffectiveFrom) && (r.EffectiveTo == nil || t.Before(*r.EffectiveTo))
+}
+
+// Overlaps reports whether the periods of two rates share a day.
+func (r *TaxRate) Overlaps(other *TaxRate) bool {
+	startsBeforeOtherEnds := other.EffectiveTo == nil || r.EffectiveFrom.Before(*other.EffectiveTo)
+	endsAfterOtherStarts := r.EffectiveTo == nil || other.EffectiveFrom.Before(*r.EffectiveTo)
+	return startsBeforeOtherEnds && endsAfterOtherStarts
+}
+
+// TaxRateRepository stores tax rates. They are shared by every organization.
+type TaxRateRepository interface {
+	Create(ctx context.Context, rate *TaxRate) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*TaxRate, error)
+	// GetAll lists the rates of the jurisdictions, or every rate when none are given, by
+	// jurisdiction, name and period, newest first
+	GetAll(ctx context.Context, jurisdictions []string) ([]*TaxRate, error)
+	Update(ctx context.Context, id primitive.ObjectID, rate *TaxRate) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportTemplate is a line-item taxonomy, the chart of accounts statements are generated
+// from, published by a company to the library of its organization for the others to copy.
+// Publishing again makes a new version; earlier versions stay readable.
+type ReportTemplate struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Organization *primitive.ObjectID `bson:"organization,omitempty" json:"organization,omitempty"`
+	Name         string              `bson:"name" json:"name"`
+	Description  string              `bson:"description,omitempty" json:"description,omitempty"`
+	// Company is the company that published the template, from whose chart every version is
+	// taken
+	Company primitive.ObjectID `bson:"company" json:"company"`
+	// ReportType is the type of the reports the template suits, e.g. the P&L; any when nil
+	ReportType  *primitive.ObjectID `bson:"reportType,omitempty" json:"reportType,omitempty"`
+	Version     int                 `bson:"version" json:"version"`
+	Accounts    []Account           `bson:"accounts" json:"accounts"`
+	Copies      int                 `bson:"copies" json:"copies"`
+	PublishedBy primitive.ObjectID  `bson:"publishedBy" json:"publishedBy"`
+	CreatedAt   time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ReportTemplateVersion is the accounts of a template as one publication left them.
+type ReportTemplateVersion struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Template     primitive.ObjectID  `bson:"template" json:"template"`
+	Organization *primitive.ObjectID `bson:"organization,omitempty" json:"organization,omitempty"`
+	Version      int                 `bson:"version" json:"version"`
+	Accounts     []Account           `bson:"accounts" json:"accounts"`
+	Note         string              `bson:"note,omitempty" json:"note,omitempty"`
+	PublishedBy  primitive.ObjectID  `bson:"publishedBy" json:"publishedBy"`
+	PublishedAt  time.Time           `bson:"publishedAt" json:"publishedAt"`
+}
+
+// TemplateOrigin attributes a chart of accounts to the template version it was copied from.
+type TemplateOrigin struct {
+	Template primitive.ObjectID `bson:"template" json:"template"`
+	Name     string             `bson:"name" json:"name"`
+	Company  primitive.ObjectID `bson:"company" json:"company"` // that published the template
+	Version  int                `bson:"version" json:"version"`
+	CopiedBy primitive.ObjectID `bson:"copiedBy" json:"copiedBy"`
+	CopiedAt time.Time          `bson:"copiedAt" json:"copiedAt"`
+}
+
+// ReportTemplateRepository stores the templates of organizations and their versions.
+type ReportTemplateRepository interface {
+	// Create stores a template in the organization it is stamped with as version 1
+	Create(ctx context.Context, template *ReportTemplate, note string) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*ReportTemplate, error)
+	// GetAll lists the templates of the organization, without their accounts
+	GetAll(ctx context.Context) ([]*ReportTemplate, error)
+	// Update changes the name, description and report type of a template
+	Update(ctx context.Context, template *ReportTemplate) error
+	// Publish stores the template's accounts as its next version, unless it was published
+	// since version was read
+	Publish(ctx context.Context, template *ReportTemplate, note string) error
+	// Delete removes a template and its versions; copies keep their accounts
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// GetVersions lists the versions of a template, newest first, without their accounts
+	GetVersions(ctx context.Context, id primitive.ObjectID) ([]*ReportTemplateVersion, error)
+	GetVersion(ctx context.Context, id primitive.ObjectID, version int) (*ReportTemplateVersion, error)
+	// CountCopy records that a company copied the template
+	CountCopy(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tenant is the organization a request acts within, taken from the org claim of its token.
+// Repository reads and writes of a request with a tenant only reach the organization's
+// users, companies and reports. Users outside any organization have the instance itself as
+// their tenant, with a zero Organization; only super admins work across tenants.
+type Tenant struct {
+	Organization primitive.ObjectID   `bson:"organization"` // primitive.NilObjectID for the instance
+	Companies    []primitive.ObjectID `bson:"companies"`
+	// Sandbox marks requests served from the sandbox database; its companies are the sandbox's
+	Sandbox bool `bson:"sandbox,omitempty"`
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose repository reads and writes are limited to tenant.
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	if tenant.Companies == nil {
+		tenant.Companies = []primitive.ObjectID{}
+	}
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantOf returns the tenant set with WithTenant, or nil for super admins and background
+// jobs, which work across tenants.
+func TenantOf(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return tenant
+}
+
+// ReadsEverything reports whether the repository reads of ctx are limited by neither a tenant
+// nor an access scope, so caches shared by every caller may serve them.
+func ReadsEverything(ctx context.Context) bool {
+	return TenantOf(ctx) == nil && AccessScopeOf(ctx) == nil
+}
+
+// IsInstance reports whether the tenant is the instance rather than an organization.
+func (t *Tenant) IsInstance() bool {
+	return t.Organization.IsZero()
+}
+
+// OwnsCompany reports whether the company is one of the tenant's.
+func (t *Tenant) OwnsCompany(companyID primitive.ObjectID) bool {
+	for _, id := range t.Companies {
+		if id == companyID {
+			return true
+		}
+	}
+	return false
+}
+
+// Owns reports whether a user or company stamped with organization belongs to the tenant.
+func (t *Tenant) Owns(organization *primitive.ObjectID) bool {
+	if organization == nil {
+		return t.IsInstance()
+	}
+	return *organization == t.Organization
+}
+
+// Stamp returns the organization to store on users and companies created by the tenant.
+func (t *Tenant) Stamp() *primitive.ObjectID {
+	if t.IsInstance() {
+		return nil
+	}
+	organization := t.Organization
+	return &organization
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenKind distinguishes the short-lived security artifacts kept in the token store.
+type TokenKind string
+
+const (
+	TokenPasswordReset TokenKind = "password_reset"
+	TokenInvitation    TokenKind = "invitation"
+	TokenShareLink     TokenKind = "share_link"
+	TokenSession       TokenKind = "session"
+	TokenLoginRevoke   TokenKind = "login_revoke" // "this wasn't me" link of a suspicious login alert
+	TokenOAuthState    TokenKind = "oauth_state"  // state of a sign-in with an identity provider, not tied to a user
+	TokenRevoked       TokenKind = "revoked"      // ID of a JWT revoked before it expires, e.g. on logout
+	TokenMagicLink     TokenKind = "magic_link"   // one-time passwordless login link
+	TokenSSOState      TokenKind = "sso_state"    // state of a sign-in with a company's identity provider, prefixed with the company ID
+)
+
+// SecurityToken is an expiring credential. Stores purge tokens past ExpiresAt automatically
+// (a TTL index on MongoDB), so expired tokens must never be relied upon to stay readable.
+type SecurityToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind      TokenKind          `bson:"kind" json:"kind"`
+	Token     string             `bson:"token" json:"-"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+type SecurityTokenRepository interface {
+	Create(ctx context.Context, token *SecurityToken) error
+	// GetValid returns the unexpired token of the given kind, or INVALID_TOKEN.
+	GetValid(ctx context.Context, kind TokenKind, token string) (*SecurityToken, error)
+	Delete(ctx context.Context, kind TokenKind, token string) error
+	DeleteByUser(ctx context.Context, kind TokenKind, userID primitive.ObjectID) error
+}
@@ -8,16 +8,108 @@ import (
 )
 
 type User struct {
-	ID                   primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	Name                 string               `bson:"name" json:"name"`
-	Email                string               `bson:"email" json:"email"`
-	Password             string               `bson:"password" json:"-"`
-	Role                 UserRole             `bson:"role" json:"role"`
-	Company              []primitive.ObjectID `bson:"company" json:"company"`
-	CreatedAt            time.Time            `bson:"createdAt" json:"createdAt"`
-	UpdatedAt            time.Time            `bson:"updatedAt" json:"updatedAt"`
-	ResetPasswordToken   *string              `bson:"resetPasswordToken,omitempty" json:"-"`
-	ResetPasswordExpires *time.Time           `bson:"resetPasswordExpires,omitempty" json:"-"`
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	Email       string               `bson:"email" json:"email"`
+	Password    string               `bson:"password" json:"-"`
+	Role        UserRole             `bson:"role" json:"role"`
+	Company     []primitive.ObjectID `bson:"company" json:"company"`
+	Locale      string               `bson:"locale,omitempty" json:"locale,omitempty"` // language code for emails, e.g. "id"
+	Phone       string               `bson:"phone,omitempty" json:"phone,omitempty"`   // E.164, used by the SMS and WhatsApp channels
+	Preferences UserPreferences      `bson:"preferences" json:"preferences"`
+	Avatar      string               `bson:"avatar,omitempty" json:"avatar,omitempty"` // path of the uploaded avatar
+	AvatarThumb string               `bson:"avatarThumb,omitempty" json:"avatarThumb,omitempty"`
+	Consents    []Consent            `bson:"consents,omitempty" json:"-"`
+	// Identities are the accounts of identity providers the user signs in with
+	Identities []ExternalIdentity `bson:"identities,omitempty" json:"identities,omitempty"`
+	// Organization is nil for users of the instance itself, such as super admins
+	Organization *primitive.ObjectID `bson:"organization,omitempty" json:"organization,omitempty"`
+	// SessionsRevokedAt invalidates every token issued before it, e.g. after a "this wasn't me" login alert
+	SessionsRevokedAt *time.Time `bson:"sessionsRevokedAt,omitempty" json:"-"`
+	// DeactivatedAt is when the user lost access without being deleted, nil for active users
+	DeactivatedAt *time.Time `bson:"deactivatedAt,omitempty" json:"deactivatedAt,omitempty"`
+	// InvitedAt is when the user was invited, until they accept the invitation and set a
+	// password. Invitations are stored in MongoDB only.
+	InvitedAt *time.Time `bson:"invitedAt,omitempty" json:"invitedAt,omitempty"`
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"-"`
+}
+
+// UserPreferences holds per-user settings. Zero values are the defaults, so documents
+// written before a setting existed behave as if the user never changed it. The user's
+// language is their Locale.
+type UserPreferences struct {
+	Timezone      string                  `bson:"timezone,omitempty" json:"timezone,omitempty"` // IANA name, e.g. "Asia/Jakarta"; UTC when empty
+	Currency      string                  `bson:"currency,omitempty" json:"currency,omitempty"` // ISO 4217 code of amounts whose report has none
+	Notifications NotificationPreferences `bson:"notifications" json:"notifications"`
+}
+
+// Location is the zone of the user's timezone, UTC when it is unset or unknown.
+func (p UserPreferences) Location() *time.Location {
+	if p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+type NotificationPreferences struct {
+	WeeklyDigestDisabled bool                `bson:"weeklyDigestDisabled,omitempty" json:"weeklyDigestDisabled,omitempty"`
+	Channel              NotificationChannel `bson:"channel,omitempty" json:"channel,omitempty"`
+}
+
+// NotificationChannel is how one-time passwords and critical alerts reach a user.
+// Other notifications are always emailed.
+type NotificationChannel string
+
+const (
+	ChannelEmail    NotificationChannel = "email"
+	ChannelSMS      NotificationChannel = "sms"
+	ChannelWhatsApp NotificationChannel = "whatsapp"
+)
+
+// ExternalIdentity links a user to their account at an identity provider, e.g. Google. Users
+// are linked the first time they sign in with an account of the same verified email.
+type ExternalIdentity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"subject"` // the provider's stable ID of the account
+	Email    string    `bson:"email" json:"email"`
+	LinkedAt time.Time `bson:"linkedAt" json:"linkedAt"`
+}
+
+// Identity returns the user's account at provider, or nil.
+func (u *User) Identity(provider string) *ExternalIdentity {
+	for i := range u.Identities {
+		if u.Identities[i].Provider == provider {
+			return &u.Identities[i]
+		}
+	}
+	return nil
+}
+
+// SessionRevoked reports whether a token issued at issuedAt was revoked. Tokens carry whole
+// seconds, so one issued in the second of the revocation still counts as newer.
+func (u *User) SessionRevoked(issuedAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && issuedAt.Before(u.SessionsRevokedAt.Truncate(time.Second))
+}
+
+// Active reports whether the user may log in and use their tokens: they are neither
+// deactivated nor still invited.
+func (u *User) Active() bool {
+	return u.DeactivatedAt == nil && u.InvitedAt == nil
+}
+
+// OrganizationClaim is the org claim of the user's tokens: the organization ID, or empty for
+// users of the instance.
+func (u *User) OrganizationClaim() string {
+	if u.Organization == nil {
+		return ""
+	}
+	return u.Organization.Hex()
 }
 
 type UserRole string
@@ -36,13 +128,35 @@ func (r UserRole) IsValid() bool {
 	return false
 }
 
+// UserSortFields are the fields user lists can be sorted by.
+var UserSortFields = []string{"name", "email", "role", "createdAt", "updatedAt"}
+
+// UserQuery filters, sorts and pages a list of users. The zero value lists every user, oldest
+// first.
+type UserQuery struct {
+	// Search matches users whose name or email contains it, ignoring case
+	Search string
+	Role   UserRole
+	// Sort is one of UserSortFields, createdAt when empty
+	Sort string
+	Desc bool
+	Skip int
+	// Limit is the page size; zero returns every user from Skip on
+	Limit int
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
+	// GetByIDs returns the users among ids in no particular order, skipping missing ones
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*User, error)
 	GetAll(ctx context.Context) ([]*User, error)
+	// GetPage returns the users matching query and how many match in all
+	GetPage(ctx context.Context, query UserQuery) ([]*User, int, error)
+	// Each calls fn with the users of GetAll one at a time as they are read, stopping at
+	// the first error fn returns
+	Each(ctx context.Context, fn func(*User) error) error
 	Update(ctx context.Context, id primitive.ObjectID, user *User) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
-	SetResetToken(ctx context.Context, email, token string, expires time.Time) error
-	GetByResetToken(ctx context.Context, token string) (*User, error)
 }
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sources exported to the data warehouse, each with its own progress.
+const (
+	WarehouseSourceCompanies = "companies"
+	WarehouseSourceReports   = "reports"
+)
+
+// WarehouseWatermark marks how far a source has been exported: documents changed after
+// ChangedAt, or at ChangedAt with a greater ID, are still to go.
+type WarehouseWatermark struct {
+	ChangedAt time.Time          `bson:"changedAt" json:"changedAt"`
+	ID        primitive.ObjectID `bson:"id" json:"id"`
+}
+
+// WarehouseState is the progress of the export of a source to the data warehouse.
+type WarehouseState struct {
+	Source    string             `bson:"_id" json:"source"`
+	Watermark WarehouseWatermark `bson:"watermark" json:"watermark"`
+	Rows      int64              `bson:"rows" json:"rows"` // exported in total, line items included
+	LastRunAt *time.Time         `bson:"lastRunAt,omitempty" json:"lastRunAt"`
+	LastError string             `bson:"lastError,omitempty" json:"lastError,omitempty"`
+}
+
+// ChangedAt is when a document last changed for the warehouse: when it was updated or, if
+// later, soft deleted.
+func ChangedAt(updatedAt time.Time, deletedAt *time.Time) time.Time {
+	if deletedAt != nil && deletedAt.After(updatedAt) {
+		return *deletedAt
+	}
+	return updatedAt
+}
+
+// WarehouseRepository tracks the warehouse export and reads the documents it exports. The
+// reads include soft-deleted documents, so the warehouse learns of deletions, and are
+// ordered by ChangedAt and then ID.
+type WarehouseRepository interface {
+	GetStates(ctx context.Context) ([]*WarehouseState, error)
+	// GetState returns the progress of source, starting from the beginning if never exported
+	GetState(ctx context.Context, source string) (*WarehouseState, error)
+	SaveState(ctx context.Context, state *WarehouseState) error
+	ReportsChangedAfter(ctx context.Context, after WarehouseWatermark, limit int) ([]*Report, error)
+	CompaniesChangedAfter(ctx context.Context, after WarehouseWatermark, limit int) ([]*Company, error)
+}
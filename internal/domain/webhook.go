@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookSubscription is an external endpoint that receives signed copies of outbox events.
+// An empty Events list subscribes to every event type.
+type WebhookSubscription struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	URL       string             `bson:"url" json:"url"`
+	Secret    string             `bson:"secret" json:"-"`
+	Events    []EventType        `bson:"events" json:"events"`
+	Active    bool               `bson:"active" json:"active"`
+	CreatedBy primitive.ObjectID `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Accepts reports whether the subscription wants events of the given type.
+func (s *WebhookSubscription) Accepts(eventType EventType) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, t := range s.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "PENDING"
+	// DeliveryStatusProcessing marks a delivery a worker claimed, until its lock runs out
+	DeliveryStatusProcessing DeliveryStatus = "PROCESSING"
+	DeliveryStatusDelivered  DeliveryStatus = "DELIVERED"
+	DeliveryStatusFailed     DeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is one event queued for one subscription. Payload holds the exact
+// JSON body that is signed and sent, so retries are byte-identical.
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SubscriptionID primitive.ObjectID `bson:"subscriptionId" json:"subscriptionId"`
+	EventID        primitive.ObjectID `bson:"eventId" json:"eventId"`
+	EventType      EventType          `bson:"eventType" json:"eventType"`
+	Payload        []byte             `bson:"payload" json:"-"`
+	Status         DeliveryStatus     `bson:"status" json:"status"`
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	LastError      *string            `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	ResponseStatus int                `bson:"responseStatus,omitempty" json:"responseStatus,omitempty"`
+	NextAttemptAt  time.Time          `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	// LockedUntil is when a PROCESSING delivery's worker is presumed gone and another may claim it
+	LockedUntil *time.Time `bson:"lockedUntil,omitempty" json:"-"`
+	DeliveredAt *time.Time `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, subscription *WebhookSubscription) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*WebhookSubscription, error)
+	GetAll(ctx context.Context) ([]*WebhookSubscription, error)
+	GetActiveForEvent(ctx context.Context, eventType EventType) ([]*WebhookSubscription, error)
+	Update(ctx context.Context, id primitive.ObjectID, subscription *WebhookSubscription) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type WebhookDeliveryRepository interface {
+	// Enqueue is idempotent per subscription and event, so a redispatched outbox event
+	// does not produce duplicate deliveries.
+	Enqueue(ctx context.Context, delivery *WebhookDelivery) error
+	// Claim marks the oldest delivery due for an attempt PROCESSING until lockedUntil and
+	// returns it, or nil when none is due. Deliveries whose lock ran out are due again, so that
+	// each delivery is sent by one instance at a time.
+	Claim(ctx context.Context, lockedUntil time.Time) (*WebhookDelivery, error)
+	// CountPending counts deliveries not yet delivered, including ones waiting for a retry.
+	CountPending(ctx context.Context) (int64, error)
+	MarkDelivered(ctx context.Context, id primitive.ObjectID, responseStatus int) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, responseStatus int, nextAttemptAt time.Time, final bool) error
+	ListBySubscription(ctx context.Context, subscriptionID primitive.ObjectID, limit int) ([]*WebhookDelivery, error)
+}
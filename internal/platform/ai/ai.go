@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"context"
+	"strings"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Model generates text from a prompt with a hosted language model.
+type Model interface {
+	// Name identifies the provider and model, e.g. "gemini/gemini-1.5-flash"
+	Name() string
+	// GenerateJSON returns the model's answer to prompt, instructed by system, as a JSON document
+	GenerateJSON(ctx context.Context, system, prompt string) (string, error)
+}
+
+const (
+	ProviderGemini = "gemini"
+
+	DefaultGeminiModel = "gemini-1.5-flash"
+)
+
+// Config selects and configures the language model.
+type Config struct {
+	Provider string // gemini; AI features are off when empty
+
+	APIKey string
+	Model  string // DefaultGeminiModel when empty
+}
+
+// New builds the configured model, or returns nil when no provider is configured.
+func New(cfg Config) (Model, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, nil
+	case ProviderGemini:
+		return NewGemini(cfg.APIKey, cfg.Model)
+	}
+
+	return nil, errors.New("AI_CONFIG_INVALID", "Unknown AI_PROVIDER", 500, nil, map[string]interface{}{"provider": cfg.Provider})
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Generating takes far longer than the other provider calls
+var geminiHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+type gemini struct {
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+// NewGemini calls the Gemini API of Google AI Studio with an API key.
+func NewGemini(apiKey, model string) (Model, error) {
+	if apiKey == "" {
+		return nil, errors.New("AI_CONFIG_MISSING", "AI configuration not found", 500, nil, map[string]interface{}{"provider": ProviderGemini})
+	}
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+	return &gemini{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: "https://generativelanguage.googleapis.com",
+	}, nil
+}
+
+func (g *gemini) Name() string { return ProviderGemini + "/" + g.model }
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+func (g *gemini) GenerateJSON(ctx context.Context, system, prompt string) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"systemInstruction": geminiContent{Parts: []geminiPart{{Text: system}}},
+		"contents":          []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}},
+		"generationConfig": map[string]interface{}{
+			"responseMimeType": "application/json",
+			"temperature":      0.2,
+		},
+	})
+	if err != nil {
+		return "", errors.New("AI_REQUEST_FAILED", "Failed to build model request", 500, err, nil)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:generateContent", g.baseURL, g.model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", errors.New("AI_REQUEST_FAILED", "Failed to build model request", 500, err, nil)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", g.apiKey)
+
+	resp, err := geminiHTTPClient.Do(req)
+	if err != nil {
+		return "", errors.New("AI_REQUEST_FAILED", "The language model could not be reached", 502, err, nil)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", errors.New("AI_REQUEST_FAILED", "The language model returned an error", 502,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), nil)
+	}
+
+	var result struct {
+		Candidates []struct {
+			Content      geminiContent `json:"content"`
+			FinishReason string        `json:"finishReason"`
+		} `json:"candidates"`
+		PromptFeedback struct {
+			BlockReason string `json:"blockReason"`
+		} `json:"promptFeedback"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errors.New("AI_REQUEST_FAILED", "The language model returned an unreadable answer", 502, err, nil)
+	}
+	if result.PromptFeedback.BlockReason != "" || len(result.Candidates) == 0 {
+		return "", errors.New("AI_REQUEST_FAILED", "The language model declined to answer", 502, nil,
+			map[string]interface{}{"reason": result.PromptFeedback.BlockReason})
+	}
+
+	var text strings.Builder
+	for _, part := range result.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,76 @@
+// Package buildinfo identifies the running binary and the instance it runs on.
+package buildinfo
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X finsolvz-backend/internal/platform/buildinfo.Version=v2.1.0 -X finsolvz-backend/internal/platform/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// Without them the commit is taken from the VCS stamp Go embeds when building inside a git checkout.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// startedAt approximates the process start, since the package is initialised on startup
+var startedAt = time.Now()
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuiltAt   string `json:"builtAt,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a checkout with uncommitted changes
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				info.BuiltAt = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+// Instance describes where this process runs. Cloud Run sets K_SERVICE and K_REVISION;
+// elsewhere they are empty.
+type Instance struct {
+	Hostname  string    `json:"hostname"`
+	PID       int       `json:"pid"`
+	Service   string    `json:"service,omitempty"`
+	Revision  string    `json:"revision,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+func CurrentInstance() Instance {
+	hostname, _ := os.Hostname()
+	return Instance{
+		Hostname:  hostname,
+		PID:       os.Getpid(),
+		Service:   os.Getenv("K_SERVICE"),
+		Revision:  os.Getenv("K_REVISION"),
+		StartedAt: startedAt,
+	}
+}
+
+// Uptime is the time since the process started.
+func Uptime() time.Duration {
+	return time.Since(startedAt)
+}
@@ -0,0 +1,157 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// checkTimeout bounds each check, so one unreachable dependency cannot stall the report
+const checkTimeout = 10 * time.Second
+
+// Check verifies one dependency. Run returns a short description of what it found; an error
+// fails the check unless it was created with Warning.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+type warning struct{ msg string }
+
+func (w warning) Error() string { return w.msg }
+
+// Warning reports a finding that degrades the service without making it unusable.
+func Warning(format string, args ...interface{}) error {
+	return warning{msg: fmt.Sprintf(format, args...)}
+}
+
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the outcome of one diagnostics run. Its status is the worst of its checks.
+type Report struct {
+	Status    Status    `json:"status"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Runner runs the checks and keeps the latest report for the readiness endpoints.
+type Runner struct {
+	checks []Check
+
+	mu   sync.RWMutex
+	last *Report
+}
+
+func NewRunner(checks ...Check) *Runner {
+	return &Runner{checks: checks}
+}
+
+// Run executes every check concurrently, logs each result and stores the report.
+func (r *Runner) Run(ctx context.Context) *Report {
+	results := make([]Result, len(r.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, CheckedAt: time.Now(), Checks: results}
+	for _, result := range results {
+		switch result.Status {
+		case StatusFail:
+			report.Status = StatusFail
+			log.Errorf(ctx, "Diagnostics: %s failed: %s", result.Name, result.Error)
+		case StatusWarn:
+			if report.Status == StatusOK {
+				report.Status = StatusWarn
+			}
+			log.Warnf(ctx, "Diagnostics: %s: %s", result.Name, result.Error)
+		default:
+			log.Infof(ctx, "Diagnostics: %s ok: %s", result.Name, result.Detail)
+		}
+	}
+
+	r.mu.Lock()
+	r.last = report
+	r.mu.Unlock()
+
+	return report
+}
+
+func run(ctx context.Context, check Check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			result = Result{Name: check.Name, Status: StatusFail, Error: fmt.Sprintf("panic: %v", p)}
+		}
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	detail, err := check.Run(ctx)
+	result = Result{Name: check.Name, Status: StatusOK, Detail: detail}
+	if w, ok := err.(warning); ok {
+		result.Status, result.Error = StatusWarn, w.msg
+	} else if err != nil {
+		result.Status, result.Error = StatusFail, err.Error()
+	}
+	return result
+}
+
+// Last returns the most recent report, or nil before the first run.
+func (r *Runner) Last() *Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.last
+}
+
+// ReadyHandler answers load balancer probes with 503 while the last run has failing checks.
+// It does not re-run them, so probes stay cheap.
+func (r *Runner) ReadyHandler(w http.ResponseWriter, req *http.Request) {
+	report := r.Last()
+	if report == nil {
+		utils.RespondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "starting"})
+		return
+	}
+
+	status := http.StatusOK
+	if report.Status == StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+	utils.RespondJSON(w, status, map[string]interface{}{"status": report.Status, "checkedAt": report.CheckedAt})
+}
+
+// DetailsHandler returns the full last report, re-running the checks with ?refresh=true.
+func (r *Runner) DetailsHandler(w http.ResponseWriter, req *http.Request) {
+	report := r.Last()
+	if report == nil || req.URL.Query().Get("refresh") == "true" {
+		report = r.Run(req.Context())
+	}
+	utils.RespondJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,217 @@
+// Package format writes numbers and amounts the way a locale does, with the overrides of a
+// company's branding, so exports, emails and API responses agree on how figures look.
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// symbolAfter lists the languages writing the currency after the amount, as in 1.234,56 €.
+var symbolAfter = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "sv": true, "fi": true, "nb": true,
+	"da": true, "pl": true, "cs": true, "ru": true, "uk": true, "vi": true,
+}
+
+// nbsp separates currencies from amounts so they are never wrapped apart.
+const nbsp = "\u00a0"
+
+// Formatter writes numbers for a locale. The zero locale writes them as in English.
+type Formatter struct {
+	locale    string
+	printer   *message.Printer
+	decimal   string
+	group     string
+	after     bool
+	overrides domain.NumberFormat
+}
+
+// New returns the formatter of a BCP 47 locale, e.g. "id-ID", with overrides, which may be
+// nil. Unknown locales format as English.
+func New(locale string, overrides *domain.NumberFormat) *Formatter {
+	tag, err := language.Parse(locale)
+	if locale == "" || err != nil {
+		tag, locale = language.English, ""
+	}
+	f := &Formatter{locale: locale, printer: message.NewPrinter(tag), decimal: ".", group: ","}
+
+	// The separators are read from how the locale writes 1234.5; locales with other digits
+	// keep theirs
+	if group, decimal, ok := separators(f.printer.Sprint(number.Decimal(1234.5, number.Scale(1)))); ok {
+		f.group, f.decimal = group, decimal
+	}
+	base, _ := tag.Base()
+	f.after = symbolAfter[base.String()]
+
+	if overrides != nil {
+		f.overrides = *overrides
+		if overrides.DecimalSeparator != "" {
+			f.decimal = overrides.DecimalSeparator
+		}
+		if overrides.GroupSeparator != "" {
+			f.group = overrides.GroupSeparator
+		}
+	}
+	return f
+}
+
+// ForBranding returns the formatter of a company's branding, which may be nil, falling back
+// to locale when the branding sets none.
+func ForBranding(branding *domain.Branding, locale string) *Formatter {
+	if branding == nil {
+		return New(locale, nil)
+	}
+	if branding.Locale != "" {
+		locale = branding.Locale
+	}
+	return New(locale, branding.Numbers)
+}
+
+// separators splits a number written as 1234.5 into its group and decimal separators.
+func separators(written string) (group, decimal string, ok bool) {
+	var parts []string
+	var digits int
+	current := ""
+	for _, r := range written {
+		if unicode.IsDigit(r) {
+			digits++
+			parts = append(parts, current)
+			current = ""
+			continue
+		}
+		current += string(r)
+	}
+	// parts holds what preceded each of the five digits
+	if digits != 5 || parts[0] != "" || parts[2] != "" || parts[3] != "" || parts[4] == "" || current != "" {
+		return "", "", false
+	}
+	return parts[1], parts[4], true
+}
+
+// Number writes v rounded to decimals places, e.g. 1.234,5 for id-ID.
+func (f *Formatter) Number(v float64, decimals int) string {
+	written := f.unsigned(math.Abs(v), decimals)
+	if v < 0 && strings.Trim(written, "0"+f.decimal+f.group) != "" {
+		return "-" + written
+	}
+	return written
+}
+
+// Percent writes v, a percentage, with one decimal, e.g. 12.5%.
+func (f *Formatter) Percent(v float64) string {
+	return f.Number(v, 1) + "%"
+}
+
+// Decimals returns the places amounts in currency are rounded to: its override, or its minor
+// units. Unknown or empty currencies have two.
+func (f *Formatter) Decimals(code string) int {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 2
+	}
+	if places, ok := f.overrides.Decimals[unit.String()]; ok {
+		return places
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale
+}
+
+// Round rounds v as amounts in currency are written.
+func (f *Formatter) Round(v float64, code string) float64 {
+	pow := math.Pow(10, float64(f.Decimals(code)))
+	return math.Round(v*pow) / pow
+}
+
+// Symbol returns what amounts in currency are labeled with: its symbol in the locale, its
+// code, or nothing, as the overrides say. Unknown currencies are labeled with code as given.
+func (f *Formatter) Symbol(code string) string {
+	if f.overrides.CurrencyDisplay == domain.CurrencyNone || code == "" {
+		return ""
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return code
+	}
+	if f.overrides.CurrencyDisplay == domain.CurrencyCode {
+		return unit.String()
+	}
+	return f.printer.Sprint(currency.Symbol(unit))
+}
+
+// Amount writes v in currency, e.g. Rp 1.234 or -$1,234.00, or (1.234,56 €) with negative
+// parentheses. An empty currency writes the number alone, with two decimals.
+func (f *Formatter) Amount(v float64, code string) string {
+	written := f.unsigned(math.Abs(v), f.Decimals(code))
+	if symbol := f.Symbol(code); symbol != "" {
+		switch {
+		case f.after:
+			written += nbsp + symbol
+		case unicode.IsLetter([]rune(symbol)[len([]rune(symbol))-1]):
+			written = symbol + nbsp + written
+		default:
+			written = symbol + written
+		}
+	}
+	if v >= 0 || f.Round(v, code) == 0 {
+		return written
+	}
+	if f.overrides.NegativeParentheses {
+		return "(" + written + ")"
+	}
+	return "-" + written
+}
+
+func (f *Formatter) unsigned(v float64, decimals int) string {
+	digits := strconv.FormatFloat(v, 'f', max(decimals, 0), 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString(f.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Spec describes how amounts in a currency are written, for clients formatting figures
+// themselves the same way.
+type Spec struct {
+	Locale           string `json:"locale,omitempty"` // empty for the default, English
+	DecimalSeparator string `json:"decimalSeparator"`
+	GroupSeparator   string `json:"groupSeparator"`
+	Decimals         int    `json:"decimals"`
+	Currency         string `json:"currency,omitempty"`
+	// Symbol labels amounts, before them unless SymbolAfter; empty for none
+	Symbol              string `json:"symbol,omitempty"`
+	SymbolAfter         bool   `json:"symbolAfter,omitempty"`
+	NegativeParentheses bool   `json:"negativeParentheses,omitempty"`
+}
+
+// Spec returns how amounts in currency are written, which may be empty.
+func (f *Formatter) Spec(code string) *Spec {
+	return &Spec{
+		Locale:              f.locale,
+		DecimalSeparator:    f.decimal,
+		GroupSeparator:      f.group,
+		Decimals:            f.Decimals(code),
+		Currency:            strings.ToUpper(code),
+		Symbol:              f.Symbol(code),
+		SymbolAfter:         f.after,
+		NegativeParentheses: f.overrides.NegativeParentheses,
+	}
+}
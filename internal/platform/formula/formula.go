@@ -0,0 +1,268 @@
+// Package formula evaluates arithmetic over named values, such as KPIs over the line items of
+// a report:
+//
+//	([Operating Income] + [Depreciation]) / [Revenue] * 100
+//
+// Names in brackets may hold any character but ]; names of letters, digits, _ and . may be
+// written bare. Formulas support + - * /, unary minus, parentheses and decimal numbers.
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrDivisionByZero is returned by Eval when a divisor is zero, as for a margin of a report
+// without revenue.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// MissingError is returned by Eval when a value the formula names is missing.
+type MissingError struct {
+	Name string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("missing value %q", e.Name)
+}
+
+// maxDepth bounds how deeply parentheses and unary minuses nest, so that a hostile formula
+// can't exhaust the stack of the recursive descent.
+const maxDepth = 100
+
+// Formula is a parsed formula.
+type Formula struct {
+	root node
+	refs []string
+}
+
+// Parse parses src, reporting the position of the first syntax error.
+func Parse(src string) (*Formula, error) {
+	p := &parser{src: src}
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return nil, errors.New("formula is empty")
+	}
+
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return &Formula{root: root, refs: p.refs}, nil
+}
+
+// Refs returns the names the formula refers to, once each, in order of appearance.
+func (f *Formula) Refs() []string {
+	return f.refs
+}
+
+// Eval computes the formula with the values returned by lookup.
+func (f *Formula) Eval(lookup func(name string) (float64, bool)) (float64, error) {
+	return f.root.eval(lookup)
+}
+
+type node interface {
+	eval(lookup func(string) (float64, bool)) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(func(string) (float64, bool)) (float64, error) {
+	return float64(n), nil
+}
+
+type ref string
+
+func (r ref) eval(lookup func(string) (float64, bool)) (float64, error) {
+	value, ok := lookup(string(r))
+	if !ok {
+		return 0, &MissingError{Name: string(r)}
+	}
+	return value, nil
+}
+
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(lookup func(string) (float64, bool)) (float64, error) {
+	value, err := n.operand.eval(lookup)
+	return -value, err
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(lookup func(string) (float64, bool)) (float64, error) {
+	left, err := b.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	if right == 0 {
+		return 0, ErrDivisionByZero
+	}
+	return left / right, nil
+}
+
+// parser is a recursive descent parser of
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = "-" factor | "(" expr ")" | number | name
+type parser struct {
+	src   string
+	pos   int
+	refs  []string
+	depth int
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	for err == nil && p.peek("+-") {
+		op := p.src[p.pos]
+		p.advance()
+		var right node
+		if right, err = p.term(); err == nil {
+			left = binary{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.factor()
+	for err == nil && p.peek("*/") {
+		op := p.src[p.pos]
+		p.advance()
+		var right node
+		if right, err = p.factor(); err == nil {
+			left = binary{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) factor() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, p.errorf("formula nests deeper than %d levels", maxDepth)
+	}
+
+	if p.pos == len(p.src) {
+		return nil, p.errorf("unexpected end of formula")
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c == '-':
+		p.advance()
+		operand, err := p.factor()
+		return negate{operand: operand}, err
+
+	case c == '(':
+		p.advance()
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, p.errorf("missing )")
+		}
+		p.advance()
+		return inner, nil
+
+	case c == '[':
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return nil, p.errorf("missing ]")
+		}
+		name := strings.TrimSpace(p.src[p.pos+1 : p.pos+end])
+		if name == "" {
+			return nil, p.errorf("empty name")
+		}
+		p.pos += end
+		p.advance()
+		return p.ref(name), nil
+
+	case isDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number")
+		}
+		p.skipSpace()
+		return number(value), nil
+
+	case isNameByte(c):
+		start := p.pos
+		for p.pos < len(p.src) && (isNameByte(p.src[p.pos]) || isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		p.skipSpace()
+		return p.ref(name), nil
+	}
+	return nil, p.errorf("unexpected %q", c)
+}
+
+func (p *parser) ref(name string) ref {
+	for _, existing := range p.refs {
+		if existing == name {
+			return ref(name)
+		}
+	}
+	p.refs = append(p.refs, name)
+	return ref(name)
+}
+
+// peek reports whether the next character is one of chars.
+func (p *parser) peek(chars string) bool {
+	return p.pos < len(p.src) && strings.IndexByte(chars, p.src[p.pos]) >= 0
+}
+
+func (p *parser) advance() {
+	p.pos++
+	p.skipSpace()
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos+1)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
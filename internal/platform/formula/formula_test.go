@@ -0,0 +1,151 @@
+package formula
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	values := map[string]float64{
+		"Revenue":          200,
+		"Operating Income": 30,
+		"Depreciation":     10,
+		"cogs":             120,
+		"net.income":       -5,
+		"Zero":             0,
+	}
+	lookup := func(name string) (float64, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    float64
+		wantErr error
+		missing string
+	}{
+		// Precedence and associativity
+		{name: "multiplication before addition", src: "2 + 3 * 4", want: 14},
+		{name: "division before subtraction", src: "10 - 6 / 2", want: 7},
+		{name: "parentheses first", src: "(2 + 3) * 4", want: 20},
+		{name: "subtraction is left associative", src: "10 - 4 - 3", want: 3},
+		{name: "division is left associative", src: "100 / 10 / 5", want: 2},
+		{name: "unary minus binds tighter than multiplication", src: "-2 * 3", want: -6},
+		{name: "double negation", src: "--4", want: 4},
+		{name: "minus of a group", src: "-(1 - 3)", want: 2},
+		{name: "decimals", src: "0.5 * .5", want: 0.25},
+
+		// Names
+		{name: "bracketed names", src: "([Operating Income] + [Depreciation]) / [Revenue] * 100", want: 20},
+		{name: "bare names", src: "(Revenue - cogs) / Revenue", want: 0.4},
+		{name: "bare name with a dot", src: "net.income * 2", want: -10},
+		{name: "spaces inside brackets are trimmed", src: "[ Revenue ]", want: 200},
+
+		// Division by zero
+		{name: "division by a zero literal", src: "1 / 0", wantErr: ErrDivisionByZero},
+		{name: "division by a zero value", src: "[Revenue] / [Zero]", wantErr: ErrDivisionByZero},
+		{name: "division by an expression that is zero", src: "1 / (Revenue - 200)", wantErr: ErrDivisionByZero},
+		{name: "zero divided", src: "0 / Revenue", want: 0},
+
+		// Unknown identifiers
+		{name: "unknown bare name", src: "Revenue / Assets", missing: "Assets"},
+		{name: "unknown bracketed name", src: "[Gross Margin] * 100", missing: "Gross Margin"},
+		{name: "names are case sensitive", src: "revenue", missing: "revenue"},
+		{name: "unknown name inside a group", src: "-(1 + Cash)", missing: "Cash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Expected %q to parse, got %v", tt.src, err)
+			}
+
+			got, err := f.Eval(lookup)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v, %v", tt.wantErr, got, err)
+				}
+			case tt.missing != "":
+				var missing *MissingError
+				if !errors.As(err, &missing) || missing.Name != tt.missing {
+					t.Fatalf("Expected missing value %q, got %v, %v", tt.missing, got, err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "empty", src: "", wantErr: "formula is empty"},
+		{name: "blank", src: "  \t", wantErr: "formula is empty"},
+		{name: "unclosed parenthesis", src: "(1 + 2", wantErr: "missing ) at position 7"},
+		{name: "unopened parenthesis", src: "1 + 2)", wantErr: `unexpected ')' at position 6`},
+		{name: "unbalanced nesting", src: "((1 + 2) * 3", wantErr: "missing )"},
+		{name: "empty parentheses", src: "()", wantErr: `unexpected ')' at position 2`},
+		{name: "unclosed bracket", src: "[Revenue + 1", wantErr: "missing ] at position 1"},
+		{name: "empty bracket", src: "[ ] + 1", wantErr: "empty name at position 1"},
+		{name: "trailing operator", src: "1 +", wantErr: "unexpected end of formula at position 4"},
+		{name: "doubled operator", src: "1 * / 2", wantErr: `unexpected '/' at position 5`},
+		{name: "missing operator", src: "Revenue 2", wantErr: `unexpected '2' at position 9`},
+		{name: "invalid number", src: "1.2.3", wantErr: "invalid number at position 1"},
+		{name: "unknown character", src: "Revenue % 2", wantErr: `unexpected '%' at position 9`},
+		{name: "deep parentheses", src: strings.Repeat("(", 10000) + "1" + strings.Repeat(")", 10000), wantErr: "formula nests deeper than 100 levels"},
+		{name: "deep negation", src: strings.Repeat("-", 10000) + "1", wantErr: "formula nests deeper than 100 levels"},
+		{name: "deep unbalanced parentheses", src: strings.Repeat("(", 1000000), wantErr: "formula nests deeper than 100 levels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v, %v", tt.wantErr, f, err)
+			}
+		})
+	}
+}
+
+func TestParse_NestingWithinLimit(t *testing.T) {
+	src := strings.Repeat("(", 99) + "1" + strings.Repeat(")", 99)
+	f, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Expected 99 levels to parse, got %v", err)
+	}
+	if got, err := f.Eval(func(string) (float64, bool) { return 0, false }); err != nil || got != 1 {
+		t.Fatalf("Expected 1, got %v, %v", got, err)
+	}
+}
+
+func TestRefs(t *testing.T) {
+	f, err := Parse("([Revenue] - cogs) / Revenue + [Other Income] * 0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []string{"Revenue", "cogs", "Other Income"}
+	refs := f.Refs()
+	if len(refs) != len(want) {
+		t.Fatalf("Expected refs %v, got %v", want, refs)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Fatalf("Expected refs %v, got %v", want, refs)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package fx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type ecb struct {
+	url string
+}
+
+// NewECB reads the euro foreign exchange reference rates the European Central Bank publishes
+// every working day. It needs no credentials.
+func NewECB() Provider {
+	return &ecb{url: "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"}
+}
+
+func (p *ecb) Name() string { return ProviderECB }
+
+func (p *ecb) Latest(ctx context.Context) (*Quotes, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Failed to build rate request", 500, err, nil)
+	}
+
+	resp, err := fxHTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Failed to fetch exchange rates", 502, err, map[string]interface{}{"provider": ProviderECB})
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("FX_FETCH_ERROR", "Failed to fetch exchange rates", 502, fmt.Errorf("%s", resp.Status), map[string]interface{}{"provider": ProviderECB})
+	}
+
+	// <Cube><Cube time="2024-01-02"><Cube currency="USD" rate="1.0956"/>...</Cube></Cube>
+	var envelope struct {
+		Day struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube>Cube"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Exchange rates could not be read", 502, err, map[string]interface{}{"provider": ProviderECB})
+	}
+
+	date, err := time.Parse("2006-01-02", envelope.Day.Time)
+	if err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Exchange rates could not be read", 502, err, map[string]interface{}{"provider": ProviderECB})
+	}
+
+	quotes := &Quotes{Base: "EUR", Date: date, Rates: make(map[string]float64, len(envelope.Day.Rates))}
+	for _, rate := range envelope.Day.Rates {
+		value, err := strconv.ParseFloat(rate.Rate, 64)
+		if err != nil || value <= 0 {
+			continue
+		}
+		quotes.Rates[rate.Currency] = value
+	}
+	return quotes, nil
+}
@@ -0,0 +1,58 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Quotes are the rates of one day against a base currency: one unit of Base buys Rates[quote]
+// units of each quote currency.
+type Quotes struct {
+	Base  string
+	Date  time.Time // the day the rates apply to, at midnight UTC
+	Rates map[string]float64
+}
+
+// Provider fetches the latest exchange rates from a published source.
+type Provider interface {
+	Name() string
+	Latest(ctx context.Context) (*Quotes, error)
+}
+
+const (
+	ProviderECB               = "ecb"
+	ProviderOpenExchangeRates = "openexchangerates"
+)
+
+// Config selects and configures the rate provider.
+type Config struct {
+	Provider string // ecb or openexchangerates; rates are only entered by hand when empty
+
+	OpenExchangeRatesAppID string
+}
+
+var fxHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// New builds the configured provider, or returns nil when none is configured.
+func New(cfg Config) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, nil
+	case ProviderECB:
+		return NewECB(), nil
+	case ProviderOpenExchangeRates:
+		return NewOpenExchangeRates(cfg.OpenExchangeRatesAppID)
+	}
+
+	return nil, errors.New("FX_CONFIG_INVALID", "Unknown FX_PROVIDER", 500, nil, map[string]interface{}{"provider": cfg.Provider})
+}
+
+// Day is t's date at midnight UTC, the form effective dates are kept in.
+func Day(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
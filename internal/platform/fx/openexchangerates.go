@@ -0,0 +1,70 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type openExchangeRates struct {
+	appID   string
+	baseURL string
+}
+
+// NewOpenExchangeRates reads the latest rates of Open Exchange Rates with an app ID. Free
+// plans quote against USD only.
+func NewOpenExchangeRates(appID string) (Provider, error) {
+	if appID == "" {
+		return nil, errors.New("FX_CONFIG_MISSING", "Exchange rate provider configuration not found", 500, nil,
+			map[string]interface{}{"provider": ProviderOpenExchangeRates})
+	}
+	return &openExchangeRates{
+		appID:   appID,
+		baseURL: "https://openexchangerates.org",
+	}, nil
+}
+
+func (p *openExchangeRates) Name() string { return ProviderOpenExchangeRates }
+
+func (p *openExchangeRates) Latest(ctx context.Context) (*Quotes, error) {
+	endpoint := p.baseURL + "/api/latest.json?app_id=" + url.QueryEscape(p.appID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Failed to build rate request", 500, err, nil)
+	}
+
+	resp, err := fxHTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Failed to fetch exchange rates", 502, err, map[string]interface{}{"provider": ProviderOpenExchangeRates})
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.New("FX_FETCH_ERROR", "Failed to fetch exchange rates", 502,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), map[string]interface{}{"provider": ProviderOpenExchangeRates})
+	}
+
+	var latest struct {
+		Timestamp int64              `json:"timestamp"`
+		Base      string             `json:"base"`
+		Rates     map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, errors.New("FX_FETCH_ERROR", "Exchange rates could not be read", 502, err, map[string]interface{}{"provider": ProviderOpenExchangeRates})
+	}
+
+	quotes := &Quotes{Base: latest.Base, Date: Day(time.Unix(latest.Timestamp, 0)), Rates: map[string]float64{}}
+	for currency, rate := range latest.Rates {
+		if rate > 0 && currency != latest.Base {
+			quotes.Rates[currency] = rate
+		}
+	}
+	return quotes, nil
+}
@@ -0,0 +1,136 @@
+// Package graphql executes GraphQL queries against a schema declared in Go.
+//
+// It implements the query subset clients need to fetch nested data in one round trip:
+// operations with variables, aliases, arguments, fragments, inline fragments and the
+// @skip/@include directives. Mutations, subscriptions, interfaces, unions and
+// introspection are not supported; Schema.SDL prints the schema for client tooling.
+//
+// Fields are resolved breadth-first, one level of the response at a time. A resolver
+// may return a Thunk instead of a value; thunks run after every other field of the
+// level, so a Loader can fetch all keys requested on that level in one batch.
+package graphql
+
+// Document is a parsed GraphQL request.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	Type       string // "query", "mutation" or "subscription"
+	Name       string
+	Variables  []*VariableDefinition
+	Selections []Selection
+	Location   Location
+}
+
+type VariableDefinition struct {
+	Name     string
+	Type     *TypeRef
+	Default  *Value
+	Location Location
+}
+
+// TypeRef is a type as written in a variable definition, e.g. [ID!]!.
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef // set for lists
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a *FieldNode, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	location() Location
+}
+
+type FieldNode struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+// Key is the name of the field in the response.
+func (f *FieldNode) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Location      Location
+}
+
+func (f *FieldNode) location() Location      { return f.Location }
+func (f *FragmentSpread) location() Location { return f.Location }
+func (f *InlineFragment) location() Location { return f.Location }
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Selections    []Selection
+	Location      Location
+}
+
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Location  Location
+}
+
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is a literal or variable in a query. Raw holds the variable name or the literal
+// as written (unquoted for strings); List and Fields hold the items of lists and objects.
+type Value struct {
+	Kind     ValueKind
+	Raw      string
+	List     []*Value
+	Fields   []*Argument
+	Location Location
+}
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
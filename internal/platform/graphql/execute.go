@@ -0,0 +1,745 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Request is a GraphQL request as posted by clients.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"` // accepted for client compatibility, ignored
+}
+
+// Response carries the data and the errors of a request. Data is absent when the
+// request failed before execution, e.g. on a syntax error.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func validationError(loc Location, format string, args ...interface{}) *Error {
+	return &Error{
+		Message:    fmt.Sprintf(format, args...),
+		Locations:  []Location{loc},
+		Extensions: map[string]interface{}{"code": "GRAPHQL_VALIDATION_FAILED"},
+	}
+}
+
+// Execute parses, validates and runs a query. Field errors null the field and are
+// reported next to the partial data; a null non-null field is reported but, unlike the
+// specification, not propagated to its parent.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	if op.Type != "query" {
+		return &Response{Errors: []*Error{validationError(op.Location, "%s operations are not supported", op.Type)}}
+	}
+
+	v := &validator{schema: s, doc: doc, variables: map[string]*VariableDefinition{}}
+	for _, def := range op.Variables {
+		v.variables[def.Name] = def
+	}
+	v.selections(s.Query, op.Selections, 1, map[string]bool{})
+	if len(v.errors) > 0 {
+		return &Response{Errors: v.errors}
+	}
+
+	variables, errs := coerceVariables(op.Variables, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	e := &executor{ctx: ctx, schema: s, doc: doc, variables: variables}
+	data := e.run(s.Query, op.Selections)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func (d *Document) operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) > 1 {
+			return nil, validationError(d.Operations[1].Location, "operationName is required when the document contains several operations")
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, validationError(Location{Line: 1, Column: 1}, "unknown operation %q", name)
+}
+
+// validator checks a query against the schema before anything is resolved.
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]*VariableDefinition
+	errors    []*Error
+
+	// fields counts the fields selected so far, against the schema's MaxComplexity
+	fields     int
+	tooComplex bool
+}
+
+func (v *validator) fail(loc Location, format string, args ...interface{}) {
+	v.errors = append(v.errors, validationError(loc, format, args...))
+}
+
+// selections validates a selection set on object, following fragments; spreading
+// tracks the fragments being expanded, to reject cycles.
+func (v *validator) selections(object *Object, selections []Selection, depth int, spreading map[string]bool) {
+	maxDepth := v.schema.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = 10
+	}
+	maxComplexity := v.schema.MaxComplexity
+	if maxComplexity == 0 {
+		maxComplexity = 1000
+	}
+
+	for _, selection := range selections {
+		// Fragments spread several times multiply their fields, so stop walking at the limit
+		if v.tooComplex {
+			return
+		}
+
+		switch sel := selection.(type) {
+		case *FieldNode:
+			if v.fields++; v.fields > maxComplexity {
+				v.fail(sel.Location, "query selects more than %d fields", maxComplexity)
+				v.tooComplex = true
+				return
+			}
+			v.directives(sel.Directives)
+			if sel.Name == "__typename" {
+				if sel.Selections != nil {
+					v.fail(sel.Location, "field \"__typename\" must not have a selection")
+				}
+				continue
+			}
+			field := object.Field(sel.Name)
+			if field == nil {
+				v.fail(sel.Location, "cannot query field %q on type %q", sel.Name, object.Name)
+				continue
+			}
+			v.arguments(sel, field)
+
+			inner := namedType(field.Type)
+			if child, ok := inner.(*Object); ok {
+				if sel.Selections == nil {
+					v.fail(sel.Location, "field %q of type %q must have a selection of subfields", sel.Name, field.Type)
+					continue
+				}
+				if depth >= maxDepth {
+					v.fail(sel.Location, "query is nested deeper than %d levels", maxDepth)
+					continue
+				}
+				v.selections(child, sel.Selections, depth+1, spreading)
+			} else if sel.Selections != nil {
+				v.fail(sel.Location, "field %q of type %q must not have a selection", sel.Name, field.Type)
+			}
+
+		case *FragmentSpread:
+			v.directives(sel.Directives)
+			fragment, ok := v.doc.Fragments[sel.Name]
+			if !ok {
+				v.fail(sel.Location, "unknown fragment %q", sel.Name)
+				continue
+			}
+			if spreading[sel.Name] {
+				v.fail(sel.Location, "fragment %q spreads itself", sel.Name)
+				continue
+			}
+			if fragment.TypeCondition != object.Name {
+				v.fail(sel.Location, "fragment %q on %q cannot be spread on type %q", sel.Name, fragment.TypeCondition, object.Name)
+				continue
+			}
+			spreading[sel.Name] = true
+			v.selections(object, fragment.Selections, depth, spreading)
+			delete(spreading, sel.Name)
+
+		case *InlineFragment:
+			v.directives(sel.Directives)
+			if sel.TypeCondition != "" && sel.TypeCondition != object.Name {
+				v.fail(sel.Location, "fragment on %q cannot be spread on type %q", sel.TypeCondition, object.Name)
+				continue
+			}
+			v.selections(object, sel.Selections, depth, spreading)
+		}
+	}
+}
+
+func (v *validator) arguments(sel *FieldNode, field *Field) {
+	given := map[string]bool{}
+	for _, arg := range sel.Arguments {
+		given[arg.Name] = true
+		if fieldArg(field, arg.Name) == nil {
+			v.fail(sel.Location, "unknown argument %q on field %q", arg.Name, sel.Name)
+		}
+		v.value(arg.Value)
+	}
+	for _, arg := range field.Args {
+		if _, required := arg.Type.(*NonNull); required && arg.Default == nil && !given[arg.Name] {
+			v.fail(sel.Location, "field %q argument %q of type %q is required", sel.Name, arg.Name, arg.Type)
+		}
+	}
+}
+
+func (v *validator) directives(directives []*Directive) {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			v.fail(d.Location, "unknown directive @%s", d.Name)
+			continue
+		}
+		for _, arg := range d.Arguments {
+			v.value(arg.Value)
+		}
+	}
+}
+
+func (v *validator) value(value *Value) {
+	switch value.Kind {
+	case VariableValue:
+		if _, ok := v.variables[value.Raw]; !ok {
+			v.fail(value.Location, "variable $%s is not defined", value.Raw)
+		}
+	case ListValue:
+		for _, item := range value.List {
+			v.value(item)
+		}
+	case ObjectValue:
+		for _, field := range value.Fields {
+			v.value(field.Value)
+		}
+	}
+}
+
+func fieldArg(field *Field, name string) *Arg {
+	for _, arg := range field.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case *NonNull:
+			t = wrapped.Of
+		case *List:
+			t = wrapped.Of
+		default:
+			return t
+		}
+	}
+}
+
+// coerceVariables checks the JSON variables against their definitions. Variable types
+// may only name built-in scalars.
+func coerceVariables(defs []*VariableDefinition, values map[string]interface{}) (map[string]interface{}, []*Error) {
+	coerced := map[string]interface{}{}
+	var errs []*Error
+	for _, def := range defs {
+		t, err := inputType(def.Type)
+		if err != nil {
+			errs = append(errs, validationError(def.Location, "variable $%s: %v", def.Name, err))
+			continue
+		}
+
+		value, given := values[def.Name]
+		if !given && def.Default != nil {
+			value, err = coerceLiteral(def.Default, t, nil)
+			if err != nil {
+				errs = append(errs, validationError(def.Location, "variable $%s: %v", def.Name, err))
+			}
+			coerced[def.Name] = value
+			continue
+		}
+
+		value, err = coerceInput(value, t)
+		if err != nil {
+			errs = append(errs, validationError(def.Location, "variable $%s: %v", def.Name, err))
+			continue
+		}
+		if given {
+			coerced[def.Name] = value
+		}
+	}
+	return coerced, errs
+}
+
+func inputType(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := inputType(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = ListOf(elem)
+	} else if scalar, ok := builtinScalars[ref.Name]; ok {
+		t = scalar
+	} else {
+		return nil, fmt.Errorf("unsupported input type %q", ref.Name)
+	}
+	if ref.NonNull {
+		t = NonNullOf(t)
+	}
+	return t, nil
+}
+
+// coerceInput checks a value decoded from JSON against t.
+func coerceInput(value interface{}, t Type) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected a non-null %s", nonNull.Of)
+		}
+		return coerceInput(value, nonNull.Of)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := coerceInput(item, t.Of)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	case *Scalar:
+		if n, ok := value.(json.Number); ok {
+			value = jsonNumber(n)
+		}
+		return t.Parse(value)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// coerceLiteral evaluates a literal or variable in a query as a value of type t.
+func coerceLiteral(value *Value, t Type, variables map[string]interface{}) (interface{}, error) {
+	if value.Kind == VariableValue {
+		v := variables[value.Raw]
+		if _, ok := t.(*NonNull); ok && v == nil {
+			return nil, fmt.Errorf("expected a non-null %s, variable $%s is null", t.(*NonNull).Of, value.Raw)
+		}
+		return v, nil
+	}
+
+	if nonNull, ok := t.(*NonNull); ok {
+		if value.Kind == NullValue {
+			return nil, fmt.Errorf("expected a non-null %s", nonNull.Of)
+		}
+		return coerceLiteral(value, nonNull.Of, variables)
+	}
+	if value.Kind == NullValue {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items := value.List
+		if value.Kind != ListValue {
+			items = []*Value{value}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := coerceLiteral(item, t.Of, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	case *Scalar:
+		var raw interface{}
+		switch value.Kind {
+		case IntValue:
+			n, err := strconv.Atoi(value.Raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s is out of range", value.Raw)
+			}
+			raw = n
+		case FloatValue:
+			raw, _ = strconv.ParseFloat(value.Raw, 64)
+		case BooleanValue:
+			raw = value.Raw == "true"
+		case StringValue, EnumValue:
+			raw = value.Raw
+		default:
+			return nil, fmt.Errorf("%s cannot represent a list or object", t.Name)
+		}
+		return t.Parse(raw)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+func jsonNumber(n json.Number) interface{} {
+	if i, err := strconv.Atoi(n.String()); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// object is a response object whose keys keep the order of the query.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// collectedField is a response key and the field nodes merged into it.
+type collectedField struct {
+	key   string
+	nodes []*FieldNode
+}
+
+// pendingObject is an object whose fields are resolved on the next level.
+type pendingObject struct {
+	object *Object
+	source interface{}
+	fields []collectedField
+	out    *object
+	path   []interface{}
+}
+
+type deferredField struct {
+	thunk Thunk
+	field *Field
+	cf    collectedField
+	out   *object
+	index int
+	path  []interface{}
+}
+
+type executor struct {
+	ctx       context.Context
+	schema    *Schema
+	doc       *Document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// run resolves the query level by level. Thunks returned on a level are called once
+// every other field of the level is resolved, so loaders see all keys of the level.
+func (e *executor) run(query *Object, selections []Selection) *object {
+	root := e.pending(query, nil, selections, nil)
+	level := []*pendingObject{root}
+
+	for len(level) > 0 {
+		var next []*pendingObject
+		var deferred []deferredField
+
+		for _, p := range level {
+			for i, cf := range p.fields {
+				path := appendPath(p.path, cf.key)
+				node := cf.nodes[0]
+				if node.Name == "__typename" {
+					p.out.values[i] = p.object.Name
+					continue
+				}
+
+				field := p.object.Field(node.Name)
+				value, err := e.resolve(field, node, p.source)
+				if err != nil {
+					e.fieldError(err, node, path)
+					continue
+				}
+				if thunk, ok := value.(Thunk); ok {
+					deferred = append(deferred, deferredField{thunk: thunk, field: field, cf: cf, out: p.out, index: i, path: path})
+					continue
+				}
+				p.out.values[i] = e.complete(field.Type, value, cf, path, &next)
+			}
+		}
+
+		for _, d := range deferred {
+			value, err := d.thunk()
+			if err != nil {
+				e.fieldError(err, d.cf.nodes[0], d.path)
+				continue
+			}
+			d.out.values[d.index] = e.complete(d.field.Type, value, d.cf, d.path, &next)
+		}
+
+		level = next
+	}
+	return root.out
+}
+
+func (e *executor) resolve(field *Field, node *FieldNode, source interface{}) (value interface{}, err error) {
+	args := map[string]interface{}{}
+	for _, arg := range field.Args {
+		if arg.Default != nil {
+			args[arg.Name] = arg.Default
+		}
+	}
+	for _, a := range node.Arguments {
+		value, err := coerceLiteral(a.Value, fieldArg(field, a.Name).Type, e.variables)
+		if err != nil {
+			return nil, &Error{
+				Message:    fmt.Sprintf("argument %q: %v", a.Name, err),
+				Extensions: map[string]interface{}{"code": "BAD_USER_INPUT"},
+			}
+		}
+		if value != nil || a.Value.Kind == NullValue {
+			args[a.Name] = value
+		}
+	}
+
+	if field.Resolve == nil {
+		return defaultResolve(source, field.Name), nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic resolving %s: %v", field.Name, r)
+		}
+	}()
+	return field.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+}
+
+// defaultResolve reads name from a map or from the struct field with that json name.
+func defaultResolve(source interface{}, name string) interface{} {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name]
+	}
+
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if jsonName == name || jsonName == "" && strings.EqualFold(f.Name, name) {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
+// complete converts a resolved value to its response form, queueing objects on next.
+func (e *executor) complete(t Type, value interface{}, cf collectedField, path []interface{}, next *[]*pendingObject) interface{} {
+	if nonNull, ok := t.(*NonNull); ok {
+		result := e.complete(nonNull.Of, value, cf, path, next)
+		if result == nil && !e.failedAt(path) {
+			e.fieldError(fmt.Errorf("cannot return null for non-nullable field %q", cf.nodes[0].Name), cf.nodes[0], path)
+		}
+		return result
+	}
+	if isNil(value) {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.fieldError(fmt.Errorf("expected a list for field %q", cf.nodes[0].Name), cf.nodes[0], path)
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = e.complete(t.Of, v.Index(i).Interface(), cf, appendPath(path, i), next)
+		}
+		return list
+
+	case *Scalar:
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Ptr {
+			value = v.Elem().Interface()
+		}
+		result, err := t.Serialize(value)
+		if err != nil {
+			e.fieldError(err, cf.nodes[0], path)
+			return nil
+		}
+		return result
+
+	case *Object:
+		var selections []Selection
+		for _, node := range cf.nodes {
+			selections = append(selections, node.Selections...)
+		}
+		p := e.pending(t, value, selections, path)
+		*next = append(*next, p)
+		return p.out
+	}
+	return nil
+}
+
+func (e *executor) pending(t *Object, source interface{}, selections []Selection, path []interface{}) *pendingObject {
+	var fields []collectedField
+	e.collect(selections, &fields)
+
+	out := &object{keys: make([]string, len(fields)), values: make([]interface{}, len(fields))}
+	for i, f := range fields {
+		out.keys[i] = f.key
+	}
+	return &pendingObject{object: t, source: source, fields: fields, out: out, path: path}
+}
+
+// collect flattens fragments and merges fields requested under the same response key.
+func (e *executor) collect(selections []Selection, fields *[]collectedField) {
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *FieldNode:
+			if !e.included(sel.Directives) {
+				continue
+			}
+			merged := false
+			for i := range *fields {
+				if (*fields)[i].key == sel.Key() {
+					(*fields)[i].nodes = append((*fields)[i].nodes, sel)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				*fields = append(*fields, collectedField{key: sel.Key(), nodes: []*FieldNode{sel}})
+			}
+		case *FragmentSpread:
+			if e.included(sel.Directives) {
+				e.collect(e.doc.Fragments[sel.Name].Selections, fields)
+			}
+		case *InlineFragment:
+			if e.included(sel.Directives) {
+				e.collect(sel.Selections, fields)
+			}
+		}
+	}
+}
+
+// included evaluates @skip(if:) and @include(if:).
+func (e *executor) included(directives []*Directive) bool {
+	for _, d := range directives {
+		for _, arg := range d.Arguments {
+			if arg.Name != "if" {
+				continue
+			}
+			value, _ := coerceLiteral(arg.Value, Boolean, e.variables)
+			condition, _ := value.(bool)
+			if d.Name == "skip" && condition || d.Name == "include" && !condition {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (e *executor) fieldError(err error, node *FieldNode, path []interface{}) {
+	var gqlErr *Error
+	if known, ok := err.(*Error); ok {
+		copied := *known
+		gqlErr = &copied
+	} else if e.schema.PresentError != nil {
+		gqlErr = e.schema.PresentError(e.ctx, err)
+	} else {
+		gqlErr = presentError(err)
+	}
+	gqlErr.Locations = []Location{node.Location}
+	gqlErr.Path = path
+	e.errors = append(e.errors, gqlErr)
+}
+
+// failedAt reports whether an error was already recorded for path, so a field that
+// failed is not reported again for being null.
+func (e *executor) failedAt(path []interface{}) bool {
+	for _, err := range e.errors {
+		if reflect.DeepEqual(err.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func presentError(err error) *Error {
+	gqlErr := &Error{Message: err.Error()}
+	if coded, ok := err.(interface {
+		Code() string
+		Message() string
+	}); ok {
+		gqlErr.Message = coded.Message()
+		gqlErr.Extensions = map[string]interface{}{"code": coded.Code()}
+	}
+	return gqlErr
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	result := make([]interface{}, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, key)
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSchema is a tree of nodes, each with a child, so queries can nest as deep as they like.
+func testSchema() *Schema {
+	node := &Object{Name: "Node"}
+	node.Fields = []*Field{
+		{Name: "id", Type: NonNullOf(ID)},
+		{Name: "name", Type: String},
+		{Name: "child", Type: node, Resolve: func(p ResolveParams) (interface{}, error) {
+			source := p.Source.(map[string]interface{})
+			return map[string]interface{}{"id": source["id"].(string) + ".1", "name": "child"}, nil
+		}},
+	}
+
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "node", Type: node, Args: []*Arg{{Name: "id", Type: NonNullOf(ID)}}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return map[string]interface{}{"id": p.Args["id"], "name": "node " + p.Args["id"].(string)}, nil
+		}},
+		{Name: "nodes", Type: NonNullOf(ListOf(NonNullOf(node))), Resolve: func(p ResolveParams) (interface{}, error) {
+			return []interface{}{
+				map[string]interface{}{"id": "1", "name": "one"},
+				map[string]interface{}{"id": "2", "name": "two"},
+			}, nil
+		}},
+		{Name: "failing", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, errors.New("resolver failed")
+		}},
+		{Name: "panicking", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			panic("resolver panicked")
+		}},
+	}}
+	return &Schema{Query: query, MaxDepth: 4, MaxComplexity: 20}
+}
+
+func execute(t *testing.T, query string, variables map[string]interface{}) (string, *Response) {
+	t.Helper()
+	response := testSchema().Execute(context.Background(), Request{Query: query, Variables: variables})
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+	return string(encoded), response
+}
+
+func TestExecute_Data(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      string
+	}{
+		{
+			name:  "nested fields",
+			query: `{ node(id: "7") { id name child { id } } }`,
+			want:  `{"data":{"node":{"id":"7","name":"node 7","child":{"id":"7.1"}}}}`,
+		},
+		{
+			name:  "aliases and lists",
+			query: `query Both { first: node(id: 1) { id } nodes { name } }`,
+			want:  `{"data":{"first":{"id":"1"},"nodes":[{"name":"one"},{"name":"two"}]}}`,
+		},
+		{
+			name:      "variables",
+			query:     `query Node($id: ID!) { node(id: $id) { name } }`,
+			variables: map[string]interface{}{"id": "9"},
+			want:      `{"data":{"node":{"name":"node 9"}}}`,
+		},
+		{
+			name:  "fragments and directives",
+			query: `{ nodes { ...Named id @skip(if: true) } } fragment Named on Node { name }`,
+			want:  `{"data":{"nodes":[{"name":"one"},{"name":"two"}]}}`,
+		},
+		{
+			name:  "failing resolver nulls its field only",
+			query: `{ failing node(id: "1") { id } }`,
+			want:  `{"data":{"failing":null,"node":{"id":"1"}},"errors":[{"message":"resolver failed","locations":[{"line":1,"column":3}],"path":["failing"]}]}`,
+		},
+		{
+			name:  "panicking resolver is reported as an error",
+			query: `{ panicking }`,
+			want:  `{"data":{"panicking":null},"errors":[{"message":"panic resolving panicking: resolver panicked","locations":[{"line":1,"column":3}],"path":["panicking"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := execute(t, tt.query, tt.variables)
+			if got != tt.want {
+				t.Fatalf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecute_RejectedQueries(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		code      string
+		message   string
+	}{
+		// Malformed
+		{name: "empty query", query: ``, code: "GRAPHQL_PARSE_FAILED", message: "document contains no operation"},
+		{name: "unclosed selection", query: `{ nodes { id }`, code: "GRAPHQL_PARSE_FAILED", message: "unexpected end of query"},
+		{name: "empty selection", query: `{ nodes { } }`, code: "GRAPHQL_PARSE_FAILED", message: "selection set cannot be empty"},
+		{name: "stray character", query: `{ nodes { id } } %`, code: "GRAPHQL_PARSE_FAILED"},
+		{name: "unterminated string", query: `{ node(id: "1) { id } }`, code: "GRAPHQL_PARSE_FAILED"},
+		{name: "missing argument value", query: `{ node(id:) { id } }`, code: "GRAPHQL_PARSE_FAILED", message: `unexpected ")"`},
+		{name: "duplicate fragment", query: `{ nodes { ...F } } fragment F on Node { id } fragment F on Node { name }`, code: "GRAPHQL_PARSE_FAILED", message: `duplicate fragment "F"`},
+		{name: "too many tokens", query: "{" + strings.Repeat(" id", maxTokens) + " }", code: "GRAPHQL_PARSE_FAILED", message: "query is too large"},
+		{name: "deeply nested braces", query: strings.Repeat("{ a ", maxTokens) + strings.Repeat("}", maxTokens), code: "GRAPHQL_PARSE_FAILED", message: "query is too large"},
+		{name: "mutation", query: `mutation { nodes { id } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "mutation operations are not supported"},
+		{name: "several operations without a name", query: `query A { nodes { id } } query B { nodes { name } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "operationName is required"},
+
+		// Against the schema
+		{name: "nonexistent field", query: `{ nodes { id secret } }`, code: "GRAPHQL_VALIDATION_FAILED", message: `cannot query field "secret" on type "Node"`},
+		{name: "nonexistent root field", query: `{ users { id } }`, code: "GRAPHQL_VALIDATION_FAILED", message: `cannot query field "users" on type "Query"`},
+		{name: "unknown argument", query: `{ nodes(limit: 1) { id } }`, code: "GRAPHQL_VALIDATION_FAILED", message: `unknown argument "limit"`},
+		{name: "missing required argument", query: `{ node { id } }`, code: "GRAPHQL_VALIDATION_FAILED", message: `argument "id" of type "ID!" is required`},
+		{name: "object without subfields", query: `{ nodes }`, code: "GRAPHQL_VALIDATION_FAILED", message: "must have a selection of subfields"},
+		{name: "leaf with subfields", query: `{ nodes { id { value } } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "must not have a selection"},
+		{name: "unknown fragment", query: `{ nodes { ...Missing } }`, code: "GRAPHQL_VALIDATION_FAILED", message: `unknown fragment "Missing"`},
+		{name: "fragment cycle", query: `{ nodes { ...A } } fragment A on Node { ...B } fragment B on Node { ...A }`, code: "GRAPHQL_VALIDATION_FAILED", message: "spreads itself"},
+		{name: "fragment on the wrong type", query: `{ nodes { ...Q } } fragment Q on Query { failing }`, code: "GRAPHQL_VALIDATION_FAILED", message: `cannot be spread on type "Node"`},
+		{name: "unknown directive", query: `{ nodes { id @cached } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "unknown directive @cached"},
+		{name: "undefined variable", query: `{ node(id: $id) { id } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "variable $id is not defined"},
+		{name: "missing variable", query: `query Node($id: ID!) { node(id: $id) { id } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "variable $id: expected a non-null ID"},
+
+		// Limits
+		{name: "deeper than MaxDepth", query: `{ node(id: "1") { child { child { child { child { id } } } } } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "query is nested deeper than 4 levels"},
+		{name: "deeper than MaxDepth through fragments", query: `{ node(id: "1") { ...C } } fragment C on Node { child { child { child { child { id } } } } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "query is nested deeper than 4 levels"},
+		{name: "more fields than MaxComplexity", query: "{ nodes {" + strings.Repeat(" id", 20) + " } }", code: "GRAPHQL_VALIDATION_FAILED", message: "query selects more than 20 fields"},
+		{name: "more fields than MaxComplexity through aliases", query: "{" + aliases(21) + " }", code: "GRAPHQL_VALIDATION_FAILED", message: "query selects more than 20 fields"},
+		{name: "more fields than MaxComplexity through fragments", query: `{ nodes { ...A ...A } } fragment A on Node { ...B ...B } fragment B on Node { id name child { id name } }`, code: "GRAPHQL_VALIDATION_FAILED", message: "query selects more than 20 fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, response := execute(t, tt.query, tt.variables)
+			if response.Data != nil {
+				t.Fatalf("Expected the query not to run, got %s", got)
+			}
+			if len(response.Errors) == 0 {
+				t.Fatalf("Expected errors, got %s", got)
+			}
+			err := response.Errors[0]
+			if err.Extensions["code"] != tt.code || !strings.Contains(err.Message, tt.message) {
+				t.Fatalf("Expected a %s error containing %q, got %s", tt.code, tt.message, got)
+			}
+		})
+	}
+}
+
+func TestExecute_FragmentExpansionStopsAtMaxComplexity(t *testing.T) {
+	// Each fragment spreads the next twice: expanded, the query would select 2^40 fields
+	var query strings.Builder
+	query.WriteString("{ nodes { ...F0 } }")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&query, " fragment F%d on Node { ...F%d ...F%d }", i, i+1, i+1)
+	}
+	query.WriteString(" fragment F40 on Node { id }")
+
+	done := make(chan *Response, 1)
+	go func() {
+		done <- testSchema().Execute(context.Background(), Request{Query: query.String()})
+	}()
+
+	select {
+	case response := <-done:
+		if response.Data != nil || len(response.Errors) != 1 || !strings.Contains(response.Errors[0].Message, "more than 20 fields") {
+			t.Fatalf("Expected the query to be rejected for its fields, got %+v", response.Errors)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected validation to stop once the query selects too many fields")
+	}
+}
+
+func aliases(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, " a%d: failing", i)
+	}
+	return b.String()
+}
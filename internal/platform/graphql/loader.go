@@ -0,0 +1,99 @@
+package graphql
+
+import "context"
+
+// BatchFunc fetches the values of keys in one call. Keys missing from the result
+// resolve to the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader batches and caches lookups by key for one request. Load queues a key and
+// returns a Thunk; the first thunk called fetches every key queued so far in one batch.
+// Loaders are not safe for concurrent use, which matches the single goroutine a query
+// is executed on.
+type Loader[K comparable, V any] struct {
+	fetch   BatchFunc[K, V]
+	queued  []K
+	results map[K]V
+	errs    map[K]error
+}
+
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		results: map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+// Load resolves to the value of key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	l.queue(key)
+	return func() (interface{}, error) {
+		return l.get(ctx, key)
+	}
+}
+
+// LoadMany resolves to the values of keys, in order, skipping keys without a value.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) Thunk {
+	for _, key := range keys {
+		l.queue(key)
+	}
+	return func() (interface{}, error) {
+		values := make([]V, 0, len(keys))
+		for _, key := range keys {
+			value, err := l.get(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			if !isNil(value) {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	}
+}
+
+func (l *Loader[K, V]) queue(key K) {
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	if _, ok := l.errs[key]; ok {
+		return
+	}
+	for _, queued := range l.queued {
+		if queued == key {
+			return
+		}
+	}
+	l.queued = append(l.queued, key)
+}
+
+func (l *Loader[K, V]) get(ctx context.Context, key K) (V, error) {
+	if err, ok := l.errs[key]; ok {
+		var zero V
+		return zero, err
+	}
+	if value, ok := l.results[key]; ok {
+		return value, nil
+	}
+
+	keys := l.queued
+	l.queued = nil
+	if len(keys) == 0 {
+		keys = []K{key}
+	}
+	values, err := l.fetch(ctx, keys)
+	for _, k := range keys {
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.results[k] = values[k]
+	}
+
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return values[key], nil
+}
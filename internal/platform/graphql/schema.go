@@ -0,0 +1,295 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Type is a *Scalar, *Object, *List or *NonNull.
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type. Serialize turns a resolved value into its JSON form; Parse
+// turns an argument, decoded from a literal or from JSON variables, into the Go value
+// resolvers receive.
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(value interface{}) (interface{}, error)
+	Parse       func(value interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// Field looks a field up by name.
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+type List struct{ Of Type }
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+// NonNull marks a type that is never null.
+type NonNull struct{ Of Type }
+
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// ListOf and NonNullOf shorten schema declarations.
+func ListOf(t Type) *List       { return &List{Of: t} }
+func NonNullOf(t Type) *NonNull { return &NonNull{Of: t} }
+
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Arg
+
+	// Resolve returns the field's value or a Thunk. Without it the field is read from the
+	// source: a map key, or the struct field whose json name matches.
+	Resolve ResolveFunc
+
+	// Deprecated is the reason the field is deprecated, if it is
+	Deprecated string
+}
+
+type Arg struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     interface{}
+}
+
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{} // value of the parent object
+	Args    map[string]interface{}
+}
+
+// Thunk defers resolving a field until the rest of its level has been resolved.
+type Thunk func() (interface{}, error)
+
+// Schema is the entry point of queries.
+type Schema struct {
+	Query *Object
+
+	// MaxDepth limits how deeply selections may nest; 0 means 10
+	MaxDepth int
+	// MaxComplexity limits how many fields a query may select, counting the fields of a
+	// fragment each time it is spread; 0 means 1000
+	MaxComplexity int
+
+	// PresentError turns a resolver error into the error reported to the client. The
+	// default reports the error's Code() and Message() when it has them, and err.Error()
+	// otherwise.
+	PresentError func(ctx context.Context, err error) *Error
+}
+
+// Built-in scalars
+var (
+	String = &Scalar{
+		Name:        "String",
+		Description: "UTF-8 text.",
+		Serialize:   serializeString,
+		Parse:       parseAs[string]("String"),
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "Unique identifier, serialized as a string.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if id, ok := value.(interface{ Hex() string }); ok {
+				return id.Hex(), nil
+			}
+			return serializeString(value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case int:
+				return strconv.Itoa(v), nil
+			}
+			return nil, fmt.Errorf("ID cannot represent %v", value)
+		},
+	}
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "Signed 32-bit integer.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			v := reflect.ValueOf(value)
+			switch v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return v.Int(), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return v.Uint(), nil
+			}
+			return nil, fmt.Errorf("Int cannot represent %v", value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case int:
+				return v, nil
+			case float64:
+				if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+					return int(v), nil
+				}
+			}
+			return nil, fmt.Errorf("Int cannot represent %v", value)
+		},
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "Double-precision floating point number.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			v := reflect.ValueOf(value)
+			switch v.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return v.Float(), nil
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return float64(v.Int()), nil
+			}
+			return nil, fmt.Errorf("Float cannot represent %v", value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case int:
+				return float64(v), nil
+			case float64:
+				return v, nil
+			}
+			return nil, fmt.Errorf("Float cannot represent %v", value)
+		},
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true or false.",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v := reflect.ValueOf(value); v.Kind() == reflect.Bool {
+				return v.Bool(), nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent %v", value)
+		},
+		Parse: parseAs[bool]("Boolean"),
+	}
+)
+
+var builtinScalars = map[string]*Scalar{"String": String, "ID": ID, "Int": Int, "Float": Float, "Boolean": Boolean}
+
+func serializeString(value interface{}) (interface{}, error) {
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	if s, ok := value.(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	return nil, fmt.Errorf("String cannot represent %v", value)
+}
+
+func parseAs[T any](name string) func(interface{}) (interface{}, error) {
+	return func(value interface{}) (interface{}, error) {
+		if v, ok := value.(T); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("%s cannot represent %v", name, value)
+	}
+}
+
+// SDL prints the schema in the GraphQL schema definition language, for client tooling.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	seen := map[string]bool{}
+	var objects []*Object
+	var scalars []*Scalar
+
+	var visit func(t Type)
+	visit = func(t Type) {
+		switch t := t.(type) {
+		case *NonNull:
+			visit(t.Of)
+		case *List:
+			visit(t.Of)
+		case *Scalar:
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				if builtinScalars[t.Name] == nil {
+					scalars = append(scalars, t)
+				}
+			}
+		case *Object:
+			if seen[t.Name] {
+				return
+			}
+			seen[t.Name] = true
+			objects = append(objects, t)
+			for _, f := range t.Fields {
+				visit(f.Type)
+				for _, arg := range f.Args {
+					visit(arg.Type)
+				}
+			}
+		}
+	}
+	visit(s.Query)
+
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	for _, scalar := range scalars {
+		b.WriteString("\n" + description(scalar.Description, "") + "scalar " + scalar.Name + "\n")
+	}
+	for _, object := range objects {
+		b.WriteString("\n" + description(object.Description, "") + "type " + object.Name + " {\n")
+		for _, f := range object.Fields {
+			b.WriteString(description(f.Description, "  ") + "  " + f.Name)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for i, arg := range f.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						args[i] += " = " + literal(arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String())
+			if f.Deprecated != "" {
+				b.WriteString(" @deprecated(reason: " + strconv.Quote(f.Deprecated) + ")")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func description(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	return indent + strconv.Quote(text) + "\n"
+}
+
+func literal(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
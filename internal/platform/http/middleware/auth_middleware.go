@@ -2,77 +2,355 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/policy"
 	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
 	"finsolvz-backend/internal/utils/log"
 )
 
 type UserContext struct {
 	UserID string
 	Role   string
+	// Companies are the user's company IDs, read along with the access scope; nil for roles
+	// that see every company
+	Companies []string
+	// Organization is the ID of the user's organization, empty for users of the instance
+	Organization      string
+	OrganizationAdmin bool
+	// APIKeyID is the ID of the API key the request authenticated with, empty for tokens
+	APIKeyID string
+	// TokenID is the ID of the token the request authenticated with, empty for API keys
+	TokenID string
+	// Impersonator is the ID of the super admin acting as the user, empty for their own requests
+	Impersonator string
+}
+
+// RoleLookup returns the current role of a user, or an error rejecting their request, such as
+// ErrAccountDisabled. It is set once at startup when roles are read from the database; nil
+// trusts the role in the token until it expires.
+type RoleLookup func(ctx context.Context, userID string) (string, error)
+
+var roleLookup RoleLookup
+
+// SetRoleLookup configures the lookup of the role of token requests.
+func SetRoleLookup(lookup RoleLookup) {
+	roleLookup = lookup
+}
+
+// ErrAccountDisabled rejects a valid token of a user who no longer has access, e.g. was deleted.
+var ErrAccountDisabled = errors.New("ACCOUNT_DISABLED", "This account is disabled", http.StatusUnauthorized, nil, nil)
+
+// ErrImpersonationReadOnly rejects changes made with an impersonation token, which only shows
+// what the user sees.
+var ErrImpersonationReadOnly = errors.New("IMPERSONATION_READ_ONLY", "Impersonation tokens can only read", http.StatusForbidden, nil, nil)
+
+// SessionCheck rejects tokens that are valid but no longer accepted, e.g. issued before the
+// user revoked their sessions. It is set once at startup; nil skips the check.
+type SessionCheck func(ctx context.Context, claims *utils.Claims) error
+
+var sessionCheck SessionCheck
+
+// SetSessionCheck configures the check AuthMiddleware runs on every token.
+func SetSessionCheck(check SessionCheck) {
+	sessionCheck = check
 }
 
-// AuthMiddleware validates JWT tokens and adds user context
+// ConsentCheck rejects users who haven't accepted the current terms of service and privacy
+// policy. It is set once at startup; nil skips the check.
+type ConsentCheck func(ctx context.Context, userID string) error
+
+var consentCheck ConsentCheck
+
+// SetConsentCheck configures the check AuthMiddleware runs after authenticating the user.
+func SetConsentCheck(check ConsentCheck) {
+	consentCheck = check
+}
+
+// TenantLookup returns the tenant of a request from the org claim of its token: the
+// organization with that ID, or the instance when organizationID is empty. It is set once at
+// startup; nil leaves every request without a tenant.
+type TenantLookup func(ctx context.Context, organizationID, userID string) (*domain.OrganizationMembership, error)
+
+var tenantLookup TenantLookup
+
+// SetTenantLookup configures how AuthMiddleware finds the tenant of a request.
+func SetTenantLookup(lookup TenantLookup) {
+	tenantLookup = lookup
+}
+
+// APIKeyHeader carries the API keys scripts and integrations authenticate with instead of a
+// token.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyIdentity is the user an API key authenticates as, and what is left of the key's
+// rate limit.
+type APIKeyIdentity struct {
+	KeyID         string
+	Claims        *utils.Claims
+	RateLimit     int // requests per minute
+	RateRemaining int
+	// RetryAfter is how long until the key is accepted again, when it reached a limit
+	RetryAfter time.Duration
+}
+
+// APIKeyAuth authenticates a request by its API key and counts it against the key's limits.
+// It returns the identity along with the error of a key over its limits, for the rate limit
+// headers. It is set once at startup; nil leaves API keys unaccepted.
+type APIKeyAuth func(r *http.Request, key string) (*APIKeyIdentity, error)
+
+var apiKeyAuth APIKeyAuth
+
+// SetAPIKeyAuth configures how AuthMiddleware authenticates requests with an X-API-Key header.
+func SetAPIKeyAuth(auth APIKeyAuth) {
+	apiKeyAuth = auth
+}
+
+// AuthMiddleware validates JWT tokens, or API keys, and adds user context
 func AuthMiddleware(next http.Handler) http.Handler {
+	return authenticate(next, true)
+}
+
+// AuthMiddlewareWithoutConsent is AuthMiddleware for the routes users need before they have
+// accepted the current legal documents: reading and accepting them.
+func AuthMiddlewareWithoutConsent(next http.Handler) http.Handler {
+	return authenticate(next, false)
+}
+
+func authenticate(next http.Handler, requireConsent bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract Bearer token
-		token, err := utils.ExtractBearerToken(r)
+		var claims *utils.Claims
+		var apiKeyID string
+		if key := r.Header.Get(APIKeyHeader); key != "" && apiKeyAuth != nil {
+			identity, err := apiKeyAuth(r, key)
+			if identity != nil {
+				setRateLimitHeaders(w, identity)
+			}
+			if err != nil {
+				log.Warnf(r.Context(), "API key rejected: %v", err)
+				utils.HandleHTTPError(w, err, r)
+				return
+			}
+			claims, apiKeyID = identity.Claims, identity.KeyID
+		} else {
+			// Extract Bearer token, or the session cookie of cookie-based clients
+			token, err := utils.ExtractToken(r)
+			if err != nil {
+				log.Warnf(r.Context(), "Authentication failed: %v", err)
+				utils.HandleHTTPError(w, err, r)
+				return
+			}
+
+			// Validate JWT token
+			if claims, err = utils.ValidateJWT(r.Context(), token); err != nil {
+				log.Warnf(r.Context(), "Token validation failed: %v", err)
+				utils.HandleHTTPError(w, err, r)
+				return
+			}
+
+			if sessionCheck != nil {
+				if err := sessionCheck(r.Context(), claims); err != nil {
+					log.Warnf(r.Context(), "Session rejected: %v", err)
+					utils.HandleHTTPError(w, err, r)
+					return
+				}
+			}
+
+			// Role changes take effect right away, not when the token expires
+			if roleLookup != nil {
+				role, err := roleLookup(r.Context(), claims.UserID)
+				if err != nil {
+					log.Warnf(r.Context(), "Role lookup rejected user %s: %v", claims.UserID, err)
+					utils.HandleHTTPError(w, err, r)
+					return
+				}
+				claims.Role = role
+			}
+
+			if claims.Impersonator != "" {
+				if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions {
+					utils.HandleHTTPError(w, ErrImpersonationReadOnly, r)
+					return
+				}
+				log.Infof(r.Context(), "Impersonated request %s %s: user %s impersonated by %s", r.Method, r.URL.Path, claims.UserID, claims.Impersonator)
+			}
+
+			// Active sessions get a renewed token before theirs expires, in the session cookie
+			// when they authenticated with it
+			if renewed, renewedClaims := utils.SlideJWT(claims); renewed != "" {
+				if token == utils.SessionCookieToken(r) {
+					utils.SetSessionCookies(w, renewed, renewedClaims.ExpiresAt.Time)
+				} else {
+					w.Header().Set(utils.RenewedTokenHeader, renewed)
+				}
+			}
+		}
+
+		if requireConsent && consentCheck != nil {
+			if err := consentCheck(r.Context(), claims.UserID); err != nil {
+				utils.HandleHTTPError(w, err, r)
+				return
+			}
+		}
+
+		// Add user context to request
+		userCtx := &UserContext{
+			UserID:   claims.UserID,
+			Role:     claims.Role,
+			APIKeyID: apiKeyID,
+		}
+		if apiKeyID == "" {
+			userCtx.TokenID = claims.ID
+			userCtx.Impersonator = claims.Impersonator
+		}
+
+		ctx, err := withTenant(r.Context(), userCtx, claims.Organization)
 		if err != nil {
-			log.Warnf(r.Context(), "Authentication failed: %v", err)
 			utils.HandleHTTPError(w, err, r)
 			return
 		}
-
-		// Validate JWT token
-		claims, err := utils.ValidateJWT(token)
+		// Events of impersonated requests name the impersonator in the audit log
+		if impersonator, err := primitive.ObjectIDFromHex(userCtx.Impersonator); err == nil {
+			ctx = domain.WithImpersonator(ctx, impersonator)
+		}
+		ctx, err = withAccessScope(ctx, userCtx)
 		if err != nil {
-			log.Warnf(r.Context(), "Token validation failed: %v", err)
 			utils.HandleHTTPError(w, err, r)
 			return
 		}
 
-		// Add user context to request
-		userCtx := &UserContext{
-			UserID: claims.UserID,
-			Role:   claims.Role,
-		}
-
-		ctx := context.WithValue(r.Context(), "user", userCtx)
+		ctx = context.WithValue(ctx, "user", userCtx)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// setRateLimitHeaders reports the key's rate limit in place of the client IP's, which
+// RateLimitMiddleware reported.
+func setRateLimitHeaders(w http.ResponseWriter, identity *APIKeyIdentity) {
+	w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", identity.RateLimit))
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", identity.RateRemaining))
+	if identity.RetryAfter > 0 {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(identity.RetryAfter.Seconds()))))
+	}
+}
+
+// withTenant limits the repository reads and writes of the request to the organization
+// named by the token, or to the users and companies outside any organization for users of
+// the instance. Roles allowed to manage every organization, super admins by default, work
+// across them and get no tenant.
+func withTenant(ctx context.Context, user *UserContext, organization string) (context.Context, error) {
+	if tenantLookup == nil {
+		return ctx, nil
+	}
+	crossTenant, err := policy.Allowed(ctx, policy.Subject{Role: user.Role}, "manage", policy.Resource{Type: "organization"})
+	if err != nil {
+		return nil, errors.New("POLICY_CHECK_FAILED", "Failed to check permissions", http.StatusInternalServerError, err, nil)
+	}
+	if crossTenant {
+		return ctx, nil
+	}
+
+	membership, err := tenantLookup(ctx, organization, user.UserID)
+	if err != nil {
+		return nil, errors.New("POLICY_CHECK_FAILED", "Failed to check permissions", http.StatusInternalServerError, err, nil)
+	}
+	user.Organization = organization
+	user.OrganizationAdmin = membership.Admin
+	return domain.WithTenant(ctx, &membership.Tenant), nil
+}
+
+// withAccessScope limits the company and report reads of the request to the rows the user
+// may see, unless the policy lets the role read every company and report. The user's
+// companies are read once here and reused by later policy checks. The tenant already limits
+// both to the user's organization, whose admins see every company of it.
+func withAccessScope(ctx context.Context, user *UserContext) (context.Context, error) {
+	unrestricted := true
+	for _, resource := range []string{"company", "report"} {
+		allowed, err := policy.Allowed(ctx, policy.Subject{Role: user.Role}, "read", policy.Resource{Type: resource})
+		if err != nil {
+			return nil, errors.New("POLICY_CHECK_FAILED", "Failed to check permissions", http.StatusInternalServerError, err, nil)
+		}
+		unrestricted = unrestricted && allowed
+	}
+	if unrestricted {
+		return ctx, nil
+	}
+
+	if tenant := domain.TenantOf(ctx); tenant != nil && user.OrganizationAdmin {
+		user.Companies = make([]string, len(tenant.Companies))
+		for i, id := range tenant.Companies {
+			user.Companies[i] = id.Hex()
+		}
+		return ctx, nil
+	}
+
+	userID, err := primitive.ObjectIDFromHex(user.UserID)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+	companies, err := policy.Companies(ctx, user.UserID)
+	if err != nil {
+		return nil, errors.New("POLICY_CHECK_FAILED", "Failed to check permissions", http.StatusInternalServerError, err, nil)
+	}
+	user.Companies = companies
+	if user.Companies == nil {
+		user.Companies = []string{}
+	}
+
+	scope := &domain.AccessScope{UserID: userID}
+	for _, id := range companies {
+		if companyID, err := primitive.ObjectIDFromHex(id); err == nil {
+			scope.Companies = append(scope.Companies, companyID)
+		}
+	}
+	return domain.WithAccessScope(ctx, scope), nil
+}
+
 // GetUserFromContext extracts user context from request
 func GetUserFromContext(ctx context.Context) (*UserContext, bool) {
 	user, ok := ctx.Value("user").(*UserContext)
 	return user, ok
 }
 
-// RequireRole creates middleware that requires specific roles
-func RequireRole(roles ...string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			user, ok := GetUserFromContext(r.Context())
-			if !ok {
-				utils.HandleHTTPError(w, utils.ErrUnauthorized, r)
-				return
-			}
+// Subject returns the user as a policy subject
+func (u *UserContext) Subject() policy.Subject {
+	return policy.Subject{UserID: u.UserID, Role: u.Role, Companies: u.Companies}
+}
 
-			// Check if user has required role
-			hasRole := false
-			for _, role := range roles {
-				if user.Role == role {
-					hasRole = true
-					break
-				}
-			}
+// Authorize checks that the user in ctx may perform action on res according to the access
+// policy. It returns ErrUnauthorized without a user and ErrForbidden when the policy denies it.
+func Authorize(ctx context.Context, action string, res policy.Resource) error {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return utils.ErrUnauthorized
+	}
+	allowed, err := policy.Allowed(ctx, user.Subject(), action, res)
+	if err != nil {
+		return errors.New("POLICY_CHECK_FAILED", "Failed to check permissions", http.StatusInternalServerError, err, nil)
+	}
+	if !allowed {
+		return utils.ErrForbidden
+	}
+	return nil
+}
 
-			if !hasRole {
-				utils.HandleHTTPError(w, utils.ErrForbidden, r)
+// RequirePermission creates middleware that requires the policy to allow action on every
+// resource of the type, e.g. RequirePermission("manage", "webhook"). Rules with a condition
+// need the resource itself, so they are checked with Authorize in the service instead.
+func RequirePermission(action, resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := Authorize(r.Context(), action, policy.Resource{Type: resource}); err != nil {
+				utils.HandleHTTPError(w, err, r)
 				return
 			}
-
 			next.ServeHTTP(w, r)
 		})
 	}
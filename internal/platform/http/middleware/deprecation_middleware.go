@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"finsolvz-backend/internal/platform/metrics"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Deprecation describes a route being retired. Dates are YYYY-MM-DD; cmd/openapi-gen reads
+// them too, so they must be literals.
+type Deprecation struct {
+	Since     string // when the route was deprecated
+	Sunset    string // when it may be removed, if decided
+	Successor string // path of the route replacing it, if any
+}
+
+// DeprecatedRequests counts calls to deprecated routes per consumer, so we know who still
+// has to migrate before a sunset. Consumers are user IDs, or "anonymous".
+var DeprecatedRequests = metrics.NewCounterVec("http_deprecated_requests_total",
+	"Requests to deprecated routes by route and consumer.",
+	[]string{"method", "route", "consumer"})
+
+// Deprecated marks a route as deprecated: its responses carry the Deprecation header
+// (RFC 9745), plus Sunset (RFC 8594) and a successor-version Link when set, and every call
+// is counted in DeprecatedRequests. The first call of each consumer per route and day is
+// also logged. Apply it inside AuthMiddleware so consumers are known:
+//
+//	protected.Handle("/api/old", middleware.Deprecated(middleware.Deprecation{
+//		Since: "2026-10-16", Sunset: "2027-04-30", Successor: "/api/new",
+//	})(http.HandlerFunc(h.Old))).Methods("GET")
+//
+// It panics on malformed dates, which are a programming error caught at startup.
+func Deprecated(d Deprecation) func(http.Handler) http.Handler {
+	since := mustParseDate(d.Since)
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	sunset := ""
+	if d.Sunset != "" {
+		sunset = mustParseDate(d.Sunset).Format(http.TimeFormat)
+	}
+	link := ""
+	if d.Successor != "" {
+		link = fmt.Sprintf(`<%s>; rel="successor-version"`, d.Successor)
+	}
+
+	var mu sync.Mutex
+	logged := make(map[string]string) // method route consumer -> day last logged
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if sunset != "" {
+				w.Header().Set("Sunset", sunset)
+			}
+			if link != "" {
+				w.Header().Add("Link", link)
+			}
+
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+			consumer := "anonymous"
+			if user, ok := GetUserFromContext(r.Context()); ok {
+				consumer = user.UserID
+			}
+			DeprecatedRequests.Inc(r.Method, route, consumer)
+
+			key := r.Method + " " + route + " " + consumer
+			today := time.Now().UTC().Format(time.DateOnly)
+			mu.Lock()
+			first := logged[key] != today
+			logged[key] = today
+			mu.Unlock()
+			if first {
+				log.Warnf(r.Context(), "Deprecated route %s %s called by %s (sunset: %s)", r.Method, route, consumer, orNone(d.Sunset))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func mustParseDate(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(fmt.Sprintf("middleware: invalid deprecation date %q: %v", s, err))
+	}
+	return t
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
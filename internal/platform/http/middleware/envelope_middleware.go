@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"finsolvz-backend/internal/utils"
+)
+
+// EnvelopeVersionHeader opts a request into enveloped responses when set to "2".
+const EnvelopeVersionHeader = "X-API-Version"
+
+const v2Prefix = "/api/v2/"
+
+// EnvelopeMiddleware serves /api/v2/... as /api/... and wraps the JSON responses of those
+// requests, and of requests sending X-API-Version: 2, in utils.Envelope, with _links. It must wrap the
+// router rather than be added with router.Use, since routing happens on the rewritten path.
+func EnvelopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v2 := r.Header.Get(EnvelopeVersionHeader) == "2"
+		apiPrefix := "/api/"
+
+		if strings.HasPrefix(r.URL.Path, v2Prefix) {
+			v2, apiPrefix = true, v2Prefix
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = "/api/" + strings.TrimPrefix(r.URL.Path, v2Prefix)
+			if r.URL.RawPath != "" {
+				r2.URL.RawPath = "/api/" + strings.TrimPrefix(r.URL.RawPath, v2Prefix)
+			}
+			r = r2
+		}
+
+		if v2 {
+			w = utils.WithEnvelope(w, apiPrefix)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -15,6 +15,8 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		// Create a custom ResponseWriter to capture status code
 		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
+		log.Debugf(r.Context(), "%s %s started: user-agent=%q content-length=%d", r.Method, r.RequestURI, r.UserAgent(), r.ContentLength)
+
 		// Process request
 		next.ServeHTTP(rw, r)
 
@@ -40,3 +42,8 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to hijack it
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -13,6 +13,11 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// Deliberate aborts of a response already under way, e.g. utils.RowWriter.Fail
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				log.Errorf(r.Context(), "Panic recovered: %v\nStack trace:\n%s", err, debug.Stack())
 
 				// Return internal server error
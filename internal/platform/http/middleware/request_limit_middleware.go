@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
 
 	"finsolvz-backend/internal/utils"
@@ -16,6 +16,12 @@ func RequestLimitMiddleware(next http.Handler) http.Handler {
 		// Limit request body size to 10MB
 		r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
 
+		// Event streams stay open by design and bound their own lifetime
+		if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Set request timeout context
 		ctx := r.Context()
 		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -28,36 +34,10 @@ func RequestLimitMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// RateLimitMiddleware implements basic rate limiting (in-memory)
-func RateLimitMiddleware(requestsPerMinute int) func(http.Handler) http.Handler {
-	type client struct {
-		requests int
-		window   time.Time
-	}
-
-	clients := make(map[string]*client)
-	var mutex sync.RWMutex
-
-	// Cleanup old entries every minute
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				mutex.Lock()
-				now := time.Now()
-				for ip, c := range clients {
-					if now.Sub(c.window) > time.Minute {
-						delete(clients, ip)
-					}
-				}
-				mutex.Unlock()
-			}
-		}
-	}()
-
+// RateLimitMiddleware limits every client IP to requestsPerMinute requests in a fixed
+// one-minute window. The counters live in cache, so a shared cache limits clients across
+// all instances rather than per instance.
+func RateLimitMiddleware(cache utils.Cache, requestsPerMinute int) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ip := r.RemoteAddr
@@ -65,25 +45,8 @@ func RateLimitMiddleware(requestsPerMinute int) func(http.Handler) http.Handler
 				ip = forwarded
 			}
 
-			mutex.Lock()
-			c, exists := clients[ip]
-			if !exists {
-				c = &client{
-					requests: 0,
-					window:   time.Now(),
-				}
-				clients[ip] = c
-			}
-
-			// Reset window if it's been more than a minute
-			if time.Since(c.window) > time.Minute {
-				c.requests = 0
-				c.window = time.Now()
-			}
-
-			c.requests++
-			currentRequests := c.requests
-			mutex.Unlock()
+			// Zero means the cache is unavailable; requests are let through rather than refused
+			currentRequests := int(cache.Increment("ratelimit:"+ip, time.Minute))
 
 			if currentRequests > requestsPerMinute {
 				w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", requestsPerMinute))
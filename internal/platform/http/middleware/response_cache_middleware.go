@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/platform/metrics"
+	"finsolvz-backend/internal/utils"
+)
+
+// Response caches, named after the data their responses render. Services invalidate them
+// with InvalidateResponses when that data changes.
+const (
+	ResponseCacheReportTypes = "reporttypes"
+	ResponseCacheCompanies   = "companies"
+	ResponseCacheReports     = "reports"
+)
+
+const responseCacheKeyPrefix = "response:"
+
+// ResponseCacheRequests counts the requests of cached routes by cache and result: hit, miss,
+// or bypass for streamed CSV and NDJSON responses, which are never cached.
+var ResponseCacheRequests = metrics.NewCounterVec("http_response_cache_requests_total",
+	"Requests to routes with cached responses by cache and result (hit, miss, bypass).",
+	[]string{"cache", "result"})
+
+// cachedResponseHeaders are the headers a cached response is replayed with. Others, such as
+// the rate limit headers, belong to the request that rendered it.
+var cachedResponseHeaders = []string{"Content-Type", "Link", "X-Total-Count"}
+
+// CachedResponse is a rendered response stored by CacheResponses.
+type CachedResponse struct {
+	Header http.Header
+	Body   []byte
+}
+
+// CacheResponses serves successful GET responses of a route from the global cache for ttl,
+// rendered once per URL, Accept header and caller. Callers limited by an access scope see
+// their own rows, so they get their own entries. Apply it inside AuthMiddleware:
+//
+//	protected.Handle("/api/company", middleware.CacheResponses(middleware.ResponseCacheCompanies, 3*time.Minute)(
+//		http.HandlerFunc(h.GetCompanies))).Methods("GET")
+//
+// A read that started before a write may store its response after the write invalidated
+// the cache, so ttl also bounds how long such a stale response is served.
+func CacheResponses(name string, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || utils.RowFormat(r) != "" {
+				ResponseCacheRequests.Inc(name, "bypass")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cache := utils.GetCache()
+			key := responseCacheKey(name, r)
+
+			var cached *CachedResponse
+			if cache.Get(key, &cached) {
+				ResponseCacheRequests.Inc(name, "hit")
+				for header, values := range cached.Header {
+					w.Header()[header] = values
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(http.StatusOK)
+				w.Write(cached.Body)
+				return
+			}
+
+			ResponseCacheRequests.Inc(name, "miss")
+			w.Header().Set("X-Cache", "MISS")
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == http.StatusOK {
+				response := &CachedResponse{Header: make(http.Header), Body: rec.body.Bytes()}
+				for _, header := range cachedResponseHeaders {
+					if values := w.Header().Values(header); len(values) > 0 {
+						response.Header[header] = values
+					}
+				}
+				cache.Set(key, response, ttl)
+			}
+		})
+	}
+}
+
+// InvalidateResponses drops every cached response of the named caches.
+func InvalidateResponses(names ...string) {
+	for _, name := range names {
+		utils.GetCache().DeletePrefix(responseCacheKeyPrefix + name + ":")
+	}
+}
+
+func responseCacheKey(name string, r *http.Request) string {
+	// Callers with an access scope see their own rows, those with only a tenant its rows
+	caller := "all"
+	if scope := domain.AccessScopeOf(r.Context()); scope != nil {
+		caller = "user:" + scope.UserID.Hex()
+	} else if tenant := domain.TenantOf(r.Context()); tenant != nil {
+		caller = "tenant:" + tenant.Organization.Hex()
+	}
+	if tenant := domain.TenantOf(r.Context()); tenant != nil && tenant.Sandbox {
+		caller = "sandbox:" + caller
+	}
+	// RequestURI is the path the client called, before any version prefix is rewritten
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	return responseCacheKeyPrefix + name + ":" + caller + ":" + r.Header.Get("Accept") + ":" + uri
+}
+
+// responseRecorder passes the response through while keeping a copy of its body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
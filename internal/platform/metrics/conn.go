@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnCollector counts the server's client connections by state, from http.Server.ConnState.
+// Hijacked connections, such as WebSockets and HTTP/2 over cleartext, leave the server's
+// management and are only counted when they are handed over.
+type ConnCollector struct {
+	mu       sync.Mutex
+	states   map[net.Conn]http.ConnState
+	open     map[http.ConnState]int
+	accepted uint64
+	hijacked uint64
+}
+
+// openStates are the states reported by http_connections_open, in output order
+var openStates = []http.ConnState{http.StateNew, http.StateActive, http.StateIdle}
+
+func NewConnCollector() *ConnCollector {
+	return &ConnCollector{
+		states: make(map[net.Conn]http.ConnState),
+		open:   make(map[http.ConnState]int),
+	}
+}
+
+// ConnState is the hook to install as http.Server.ConnState.
+func (c *ConnCollector) ConnState(conn net.Conn, state http.ConnState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.states[conn]; ok {
+		c.open[previous]--
+	}
+	switch state {
+	case http.StateNew:
+		c.accepted++
+	case http.StateHijacked:
+		c.hijacked++
+	}
+	if state == http.StateHijacked || state == http.StateClosed {
+		delete(c.states, conn)
+		return
+	}
+	c.states[conn] = state
+	c.open[state]++
+}
+
+func (c *ConnCollector) WritePrometheus(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	const name = "http_connections_open"
+	fmt.Fprintf(w, "# HELP %s Client connections the server holds open, by state.\n# TYPE %s gauge\n", name, name)
+	for _, state := range openStates {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, formatLabels([]string{"state"}, []string{state.String()}), c.open[state])
+	}
+	WriteCounter(w, "http_connections_accepted_total", "Client connections the server accepted.", c.accepted)
+	WriteCounter(w, "http_connections_hijacked_total", "Client connections handed over to WebSockets or HTTP/2.", c.hijacked)
+}
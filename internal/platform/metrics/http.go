@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// recentMinutes is how far back HTTPCollector keeps per-minute request counts
+const recentMinutes = 15
+
+// HTTPCollector counts requests by method and status class, and keeps per-minute totals
+// of the last 15 minutes for recent error rates.
+type HTTPCollector struct {
+	requests *CounterVec
+
+	mu      sync.Mutex
+	minutes [recentMinutes]minuteCounts
+}
+
+type minuteCounts struct {
+	minute       int64 // Unix minute the counts belong to
+	requests     uint64
+	clientErrors uint64
+	serverErrors uint64
+}
+
+// RequestRates summarises the requests of a recent window.
+type RequestRates struct {
+	Window       string  `json:"window"`
+	Requests     uint64  `json:"requests"`
+	ClientErrors uint64  `json:"clientErrors"`
+	ServerErrors uint64  `json:"serverErrors"`
+	ErrorRate    float64 `json:"errorRate"` // share of requests that failed with a 5xx
+}
+
+func NewHTTPCollector() *HTTPCollector {
+	return &HTTPCollector{
+		requests: NewCounterVec("http_requests_total",
+			"HTTP requests by method and status class (2xx, 4xx, 5xx, ...).",
+			[]string{"method", "status"}),
+	}
+}
+
+// Middleware records the status of every request passing through it.
+func (c *HTTPCollector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		c.observe(r.Method, rec.status, time.Now())
+	})
+}
+
+func (c *HTTPCollector) observe(method string, status int, at time.Time) {
+	c.requests.Inc(method, fmt.Sprintf("%dxx", status/100))
+
+	minute := at.Unix() / 60
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := &c.minutes[minute%recentMinutes]
+	if m.minute != minute {
+		*m = minuteCounts{minute: minute}
+	}
+	m.requests++
+	switch {
+	case status >= 500:
+		m.serverErrors++
+	case status >= 400:
+		m.clientErrors++
+	}
+}
+
+// Recent sums the requests of the last window, which is rounded to whole minutes and
+// capped at 15 minutes. The current, partial minute is included.
+func (c *HTTPCollector) Recent(window time.Duration) RequestRates {
+	minutes := int64(window / time.Minute)
+	minutes = max(1, min(minutes, recentMinutes))
+	now := time.Now().Unix() / 60
+
+	rates := RequestRates{Window: (time.Duration(minutes) * time.Minute).String()}
+
+	c.mu.Lock()
+	for _, m := range c.minutes {
+		if m.minute > now-minutes && m.minute <= now {
+			rates.Requests += m.requests
+			rates.ClientErrors += m.clientErrors
+			rates.ServerErrors += m.serverErrors
+		}
+	}
+	c.mu.Unlock()
+
+	if rates.Requests > 0 {
+		rates.ErrorRate = float64(rates.ServerErrors) / float64(rates.Requests)
+	}
+	return rates
+}
+
+func (c *HTTPCollector) WritePrometheus(w io.Writer) {
+	c.requests.WritePrometheus(w)
+}
+
+// statusRecorder captures the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
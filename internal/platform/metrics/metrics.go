@@ -0,0 +1,189 @@
+// Package metrics exposes in-process metrics in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Collector writes its current samples in Prometheus text format.
+type Collector interface {
+	WritePrometheus(w io.Writer)
+}
+
+// Handler serves the samples of all collectors. When token is set, requests must send it as a bearer token.
+func Handler(token string, collectors ...Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		for _, c := range collectors {
+			c.WritePrometheus(w)
+		}
+	})
+}
+
+// DefaultBuckets are latency buckets in seconds, tuned for database round trips.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramVec is a latency histogram partitioned by label values.
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	labelValues []string
+	counts      []uint64 // cumulative per bucket
+	count       uint64
+	sum         float64
+}
+
+func NewHistogramVec(name, help string, labels []string, buckets []float64) *HistogramVec {
+	return &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+}
+
+// Observe records d for the given label values, which must match the vector's labels in order.
+func (h *HistogramVec) Observe(d time.Duration, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	seconds := d.Seconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, upper := range h.buckets {
+		if seconds <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += seconds
+}
+
+func (h *HistogramVec) WritePrometheus(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		labels := formatLabels(h.labels, s.labelValues)
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket{%sle=\"%g\"} %d\n", h.name, withComma(labels), upper, s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", h.name, withComma(labels), s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", h.name, labels, s.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", h.name, labels, s.count)
+	}
+}
+
+// CounterVec is a monotonically increasing count partitioned by label values.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*counter
+}
+
+type counter struct {
+	labelValues []string
+	value       uint64
+}
+
+func NewCounterVec(name, help string, labels []string) *CounterVec {
+	return &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*counter),
+	}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counter{labelValues: labelValues}
+		c.series[key] = s
+	}
+	s.value++
+}
+
+func (c *CounterVec) WritePrometheus(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		fmt.Fprintf(w, "%s{%s} %d\n", c.name, formatLabels(c.labels, s.labelValues), s.value)
+	}
+}
+
+// WriteGauge writes a single unlabelled gauge sample.
+func WriteGauge(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
+
+// WriteCounter writes a single unlabelled counter sample.
+func WriteCounter(w io.Writer, name, help string, value uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = fmt.Sprintf("%s=%q", name, value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func withComma(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return labels + ","
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
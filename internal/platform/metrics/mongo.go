@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoCollector records command latencies and connection pool usage from the Mongo driver's monitors.
+type MongoCollector struct {
+	commands *HistogramVec
+	failures *CounterVec
+	poolWait *HistogramVec
+
+	// in-flight commands keyed by request ID, so finished events can be labelled
+	inflight sync.Map
+
+	maxPoolSize      atomic.Int64
+	openConnections  atomic.Int64
+	inUseConnections atomic.Int64
+	checkoutFailures atomic.Uint64
+	poolClears       atomic.Uint64
+}
+
+type commandLabels struct {
+	collection string
+	pipeline   string
+}
+
+func NewMongoCollector() *MongoCollector {
+	return &MongoCollector{
+		commands: NewHistogramVec("mongo_command_duration_seconds",
+			"Duration of MongoDB commands by collection and command; aggregates are labelled by pipeline stages.",
+			[]string{"collection", "command", "pipeline"}, DefaultBuckets),
+		failures: NewCounterVec("mongo_command_failures_total",
+			"MongoDB commands that returned an error.",
+			[]string{"collection", "command"}),
+		poolWait: NewHistogramVec("mongo_pool_checkout_duration_seconds",
+			"Time spent waiting to check a connection out of the pool.",
+			nil, DefaultBuckets),
+	}
+}
+
+// CommandMonitor returns the monitor to install with options.Client().SetMonitor.
+func (m *MongoCollector) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			m.inflight.Store(e.RequestID, labelCommand(e))
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			labels := m.finish(e.RequestID)
+			m.commands.Observe(e.Duration, labels.collection, e.CommandName, labels.pipeline)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			labels := m.finish(e.RequestID)
+			m.commands.Observe(e.Duration, labels.collection, e.CommandName, labels.pipeline)
+			m.failures.Inc(labels.collection, e.CommandName)
+		},
+	}
+}
+
+// PoolMonitor returns the monitor to install with options.Client().SetPoolMonitor.
+func (m *MongoCollector) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.PoolCreated:
+				if e.PoolOptions != nil {
+					m.maxPoolSize.Store(int64(e.PoolOptions.MaxPoolSize))
+				}
+			case event.ConnectionCreated:
+				m.openConnections.Add(1)
+			case event.ConnectionClosed:
+				m.openConnections.Add(-1)
+			case event.GetSucceeded:
+				m.inUseConnections.Add(1)
+				m.poolWait.Observe(e.Duration)
+			case event.ConnectionReturned:
+				m.inUseConnections.Add(-1)
+			case event.GetFailed:
+				m.checkoutFailures.Add(1)
+				m.poolWait.Observe(e.Duration)
+			case event.PoolCleared:
+				m.poolClears.Add(1)
+			}
+		},
+	}
+}
+
+// PoolStats is a snapshot of a connection pool.
+type PoolStats struct {
+	MaxSize          int64  `json:"maxSize"`
+	Open             int64  `json:"open"`
+	InUse            int64  `json:"inUse"`
+	Waits            int64  `json:"waits,omitempty"`
+	CheckoutFailures uint64 `json:"checkoutFailures,omitempty"`
+	Clears           uint64 `json:"clears,omitempty"`
+}
+
+// PoolStats returns the current connection pool usage.
+func (m *MongoCollector) PoolStats() PoolStats {
+	return PoolStats{
+		MaxSize:          m.maxPoolSize.Load(),
+		Open:             m.openConnections.Load(),
+		InUse:            m.inUseConnections.Load(),
+		CheckoutFailures: m.checkoutFailures.Load(),
+		Clears:           m.poolClears.Load(),
+	}
+}
+
+func (m *MongoCollector) finish(requestID int64) commandLabels {
+	if v, ok := m.inflight.LoadAndDelete(requestID); ok {
+		return v.(commandLabels)
+	}
+	return commandLabels{}
+}
+
+// labelCommand extracts the target collection and, for aggregates, the ordered stage names.
+func labelCommand(e *event.CommandStartedEvent) commandLabels {
+	var labels commandLabels
+
+	switch e.CommandName {
+	case "getMore":
+		labels.collection, _ = e.Command.Lookup("collection").StringValueOK()
+	default:
+		labels.collection, _ = e.Command.Lookup(e.CommandName).StringValueOK()
+	}
+
+	if e.CommandName == "aggregate" {
+		if stages, ok := e.Command.Lookup("pipeline").ArrayOK(); ok {
+			labels.pipeline = pipelineShape(stages)
+		}
+	}
+
+	return labels
+}
+
+func pipelineShape(stages bson.Raw) string {
+	values, err := stages.Values()
+	if err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		stage, ok := v.DocumentOK()
+		if !ok {
+			continue
+		}
+		if elem, err := stage.IndexErr(0); err == nil {
+			name := elem.Key()
+			if name == "$lookup" {
+				if from, ok := stage.Lookup("$lookup", "from").StringValueOK(); ok {
+					name += ":" + from
+				}
+			}
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (m *MongoCollector) WritePrometheus(w io.Writer) {
+	m.commands.WritePrometheus(w)
+	m.failures.WritePrometheus(w)
+	m.poolWait.WritePrometheus(w)
+
+	maxSize := m.maxPoolSize.Load()
+	inUse := m.inUseConnections.Load()
+	WriteGauge(w, "mongo_pool_max_size", "Configured maximum connections per server pool.", float64(maxSize))
+	WriteGauge(w, "mongo_pool_open_connections", "Connections currently open.", float64(m.openConnections.Load()))
+	WriteGauge(w, "mongo_pool_in_use_connections", "Connections currently checked out.", float64(inUse))
+
+	saturation := 0.0
+	if maxSize > 0 {
+		saturation = float64(inUse) / float64(maxSize)
+	}
+	WriteGauge(w, "mongo_pool_saturation_ratio", "In-use connections as a fraction of the maximum pool size.", saturation)
+	WriteCounter(w, "mongo_pool_checkout_failures_total", "Connection checkouts that failed.", m.checkoutFailures.Load())
+	WriteCounter(w, "mongo_pool_clears_total", "Times a connection pool was cleared.", m.poolClears.Load())
+}
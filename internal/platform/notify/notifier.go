@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Alert is a critical notification such as a security warning. Action, when set, is the
+// link the user should follow, e.g. to revoke a login they don't recognise.
+type Alert struct {
+	Subject string
+	Message string
+	Action  *utils.EmailAction
+}
+
+// Notifier delivers one-time passwords and critical alerts over the channel the user prefers.
+type Notifier interface {
+	SendPassword(ctx context.Context, user *domain.User, password string) error
+	SendAlert(ctx context.Context, user *domain.User, alert Alert) error
+	// SendLoginLink delivers a one-time login link, always by email: the link logs in whoever
+	// receives it, and the email address is what the user registered with.
+	SendLoginLink(ctx context.Context, user *domain.User, link string, expiresIn time.Duration) error
+	// SendInvitation delivers the link a pending user sets their password with, by email for
+	// the same reasons.
+	SendInvitation(ctx context.Context, user *domain.User, inviter, link string, expiresIn time.Duration) error
+}
+
+type notifier struct {
+	email utils.EmailService
+	text  utils.MessageSender
+}
+
+// NewNotifier routes to text when the user chose SMS or WhatsApp and has a phone number,
+// and to email otherwise. A failed text message falls back to email so the user still gets it.
+func NewNotifier(email utils.EmailService, text utils.MessageSender) Notifier {
+	return &notifier{email: email, text: text}
+}
+
+func (n *notifier) SendPassword(ctx context.Context, user *domain.User, password string) error {
+	body := fmt.Sprintf("Finsolvz: your new password is %s. Please change it after logging in.", password)
+	if n.sendText(ctx, user, body) {
+		return nil
+	}
+	return n.email.SendForgotPasswordEmail(user.Email, user.Name, user.Locale, password)
+}
+
+func (n *notifier) SendAlert(ctx context.Context, user *domain.User, alert Alert) error {
+	body := "Finsolvz: " + alert.Subject + ". " + alert.Message
+	if alert.Action != nil {
+		body += " " + alert.Action.Label + ": " + alert.Action.URL
+	}
+	if n.sendText(ctx, user, body) {
+		return nil
+	}
+	return n.email.SendAlertEmail(user.Email, user.Name, user.Locale, alert.Subject, alert.Message, alert.Action)
+}
+
+func (n *notifier) SendLoginLink(ctx context.Context, user *domain.User, link string, expiresIn time.Duration) error {
+	return n.email.SendMagicLinkEmail(user.Email, user.Name, user.Locale, link, int(expiresIn.Minutes()))
+}
+
+func (n *notifier) SendInvitation(ctx context.Context, user *domain.User, inviter, link string, expiresIn time.Duration) error {
+	return n.email.SendInvitationEmail(user.Email, user.Name, user.Locale, inviter, link, int(expiresIn.Hours()/24))
+}
+
+// sendText reports whether the message was delivered over the user's text channel.
+func (n *notifier) sendText(ctx context.Context, user *domain.User, body string) bool {
+	channel := user.Preferences.Notifications.Channel
+	if n.text == nil || user.Phone == "" || (channel != domain.ChannelSMS && channel != domain.ChannelWhatsApp) {
+		return false
+	}
+
+	err := n.text.Send(ctx, utils.TextMessage{
+		To:       user.Phone,
+		Body:     body,
+		WhatsApp: channel == domain.ChannelWhatsApp,
+	})
+	if err != nil {
+		log.Warnf(ctx, "Notify: %s to user %s failed, falling back to email: %v", channel, user.ID.Hex(), err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+// Package oauth signs users in with the accounts of external identity providers through the
+// OAuth 2.0 authorization code flow and OpenID Connect.
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const ProviderGoogle = "google"
+
+var oauthHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// Identity is the account a user signed in with at a provider.
+type Identity struct {
+	Provider string
+	Subject  string // the provider's stable ID of the account; emails can change hands
+	Email    string
+	// EmailVerified reports whether the provider confirmed the user owns Email
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is an identity provider users are sent to for signing in.
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns where to send the user's browser. The provider redirects back to
+	// the configured redirect URL with a code and state.
+	AuthCodeURL(state string) string
+	// Exchange redeems the code of the redirect for the identity of the signed-in account
+	Exchange(ctx context.Context, code string) (*Identity, error)
+}
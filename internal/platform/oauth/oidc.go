@@ -0,0 +1,207 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// discoveryTTL is how long the discovery document of an issuer is reused.
+const discoveryTTL = time.Hour
+
+// OIDCConfig configures sign-in with a generic OpenID Connect provider, such as the identity
+// provider of an enterprise (Microsoft Entra ID, Okta, Keycloak...).
+type OIDCConfig struct {
+	// Name is the provider name of the identities it returns
+	Name string
+	// Issuer is the provider's issuer URL; its endpoints are read from
+	// <Issuer>/.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Discovery holds the endpoints of an OpenID Connect provider's discovery document.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+type cachedDiscovery struct {
+	doc       *Discovery
+	fetchedAt time.Time
+}
+
+var (
+	discoveryMu    sync.Mutex
+	discoveryCache = map[string]cachedDiscovery{}
+)
+
+type oidc struct {
+	cfg       OIDCConfig
+	discovery *Discovery
+}
+
+// NewOIDC signs users in with the provider at cfg.Issuer, reading its endpoints from its
+// discovery document.
+func NewOIDC(ctx context.Context, cfg OIDCConfig) (Provider, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("OAUTH_CONFIG_MISSING", "OAuth client secret or redirect URL not configured", 500, nil, map[string]interface{}{"provider": cfg.Name})
+	}
+	discovery, err := Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	return &oidc{cfg: cfg, discovery: discovery}, nil
+}
+
+// Discover reads the discovery document of issuer, which must name the same issuer.
+func Discover(ctx context.Context, issuer string) (*Discovery, error) {
+	issuer = strings.TrimRight(issuer, "/")
+
+	discoveryMu.Lock()
+	cached, ok := discoveryCache[issuer]
+	discoveryMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < discoveryTTL {
+		return cached.doc, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, errors.New("OIDC_DISCOVERY_FAILED", "Identity provider issuer URL is invalid", 400, err, map[string]interface{}{"issuer": issuer})
+	}
+	resp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.New("OIDC_DISCOVERY_FAILED", "Identity provider is unreachable", 502, err, map[string]interface{}{"issuer": issuer})
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.New("OIDC_DISCOVERY_FAILED", "Identity provider has no OpenID Connect discovery document", 502,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), map[string]interface{}{"issuer": issuer})
+	}
+
+	var doc Discovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, errors.New("OIDC_DISCOVERY_FAILED", "Identity provider discovery document could not be read", 502, err, map[string]interface{}{"issuer": issuer})
+	}
+	if strings.TrimRight(doc.Issuer, "/") != issuer || doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, errors.New("OIDC_DISCOVERY_FAILED", "Identity provider discovery document does not match the issuer", 502, nil,
+			map[string]interface{}{"issuer": issuer, "documentIssuer": doc.Issuer})
+	}
+
+	discoveryMu.Lock()
+	discoveryCache[issuer] = cachedDiscovery{doc: &doc, fetchedAt: time.Now()}
+	discoveryMu.Unlock()
+	return &doc, nil
+}
+
+func (o *oidc) Name() string { return o.cfg.Name }
+
+func (o *oidc) AuthCodeURL(state string) string {
+	query := url.Values{
+		"client_id":     {o.cfg.ClientID},
+		"redirect_uri":  {o.cfg.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+	}
+	separator := "?"
+	if strings.Contains(o.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return o.discovery.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// oidcClaims are the claims of ID tokens read by the generic provider.
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     *bool  `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+func (o *oidc) Exchange(ctx context.Context, code string) (*Identity, error) {
+	form := url.Values{
+		"code":          {code},
+		"client_id":     {o.cfg.ClientID},
+		"client_secret": {o.cfg.ClientSecret},
+		"redirect_uri":  {o.cfg.RedirectURL},
+		"grant_type":    {"authorization_code"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.New("OAUTH_ERROR", "Failed to build token request", 500, err, nil)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.New("OAUTH_ERROR", "The identity provider is unavailable", 502, err, nil)
+	}
+	defer resp.Body.Close()
+
+	// Providers answer 400 invalid_grant for expired, reused or forged codes
+	if resp.StatusCode == http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.New("OAUTH_CODE_INVALID", "The sign-in code is invalid or expired", 401,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), nil)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.New("OAUTH_ERROR", "The identity provider rejected the sign-in", 502,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), nil)
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.IDToken == "" {
+		return nil, errors.New("OAUTH_ERROR", "Identity provider token response could not be read", 502, err, nil)
+	}
+
+	// As with Google, the ID token came straight from the token endpoint over TLS
+	var claims oidcClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token.IDToken, &claims); err != nil {
+		return nil, errors.New("OAUTH_ERROR", "Identity provider ID token could not be read", 502, err, nil)
+	}
+	if strings.TrimRight(claims.Issuer, "/") != strings.TrimRight(o.cfg.Issuer, "/") {
+		return nil, errors.New("OAUTH_ERROR", "ID token has an unexpected issuer", 502, nil, map[string]interface{}{"issuer": claims.Issuer})
+	}
+	if !slices.Contains(claims.Audience, o.cfg.ClientID) {
+		return nil, errors.New("OAUTH_ERROR", "ID token was issued to another client", 502, nil, nil)
+	}
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("OAUTH_ERROR", "ID token has expired", 502, nil, nil)
+	}
+
+	// Some enterprise providers only send the sign-in name, which is the user's email there
+	email := claims.Email
+	if email == "" && strings.Contains(claims.PreferredUsername, "@") {
+		email = claims.PreferredUsername
+	}
+
+	return &Identity{
+		Provider:      o.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(email),
+		EmailVerified: claims.EmailVerified != nil && *claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	batchSize   = 50
+	maxAttempts = 10
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+	// lease is how long a claimed event or webhook delivery is left to its instance before
+	// another may claim it, comfortably longer than publishing or sending one takes
+	lease = 5 * time.Minute
+)
+
+// Dispatcher polls the outbox and hands pending events to a Publisher.
+// Events are marked dispatched only after a successful publish, so delivery is at-least-once.
+// Each event is claimed before it is published, so instances sharing the outbox don't publish
+// it at the same time; one that stops mid-publish leaves it to the others once its claim lapses.
+type Dispatcher struct {
+	repo      domain.OutboxRepository
+	publisher Publisher
+	interval  time.Duration
+}
+
+func NewDispatcher(repo domain.OutboxRepository, publisher Publisher, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		repo:      repo,
+		publisher: publisher,
+		interval:  interval,
+	}
+}
+
+// Run dispatches events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatchBatch(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchBatch(ctx context.Context) {
+	for i := 0; i < batchSize && ctx.Err() == nil; i++ {
+		event, err := d.repo.Claim(ctx, time.Now().Add(lease))
+		if err != nil {
+			log.Errorf(ctx, "Outbox: failed to claim a pending event: %v", err)
+			return
+		}
+		if event == nil {
+			return
+		}
+
+		if err := d.publisher.Publish(ctx, event); err != nil {
+			attempts := event.Attempts + 1
+			final := attempts >= maxAttempts
+			if final {
+				log.Errorf(ctx, "Outbox: giving up on event %s after %d attempts: %v", event.ID.Hex(), attempts, err)
+			} else {
+				log.Warnf(ctx, "Outbox: failed to publish event %s (attempt %d): %v", event.ID.Hex(), attempts, err)
+			}
+
+			if markErr := d.repo.MarkFailed(ctx, event.ID, err.Error(), event.DeliveredTo, time.Now().Add(backoff(attempts)), final); markErr != nil {
+				log.Errorf(ctx, "Outbox: failed to record failure for event %s: %v", event.ID.Hex(), markErr)
+			}
+			continue
+		}
+
+		if err := d.repo.MarkDispatched(ctx, event.ID); err != nil {
+			log.Errorf(ctx, "Outbox: failed to mark event %s dispatched: %v", event.ID.Hex(), err)
+		}
+	}
+}
+
+// backoff doubles the retry delay per attempt, capped at maxBackoff.
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
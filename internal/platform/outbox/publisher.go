@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Publisher delivers a single outbox event to an external system.
+type Publisher interface {
+	Publish(ctx context.Context, event *domain.Event) error
+}
+
+// Envelope is the JSON body delivered to subscribers.
+type Envelope struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregateId"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func NewEnvelope(event *domain.Event) Envelope {
+	return Envelope{
+		ID:          event.ID.Hex(),
+		Type:        string(event.Type),
+		AggregateID: event.AggregateID.Hex(),
+		OccurredAt:  event.CreatedAt,
+		Data:        json.RawMessage(event.Payload),
+	}
+}
+
+// webhookPublisher POSTs events to a fixed list of URLs, signing the body when a secret is set.
+type webhookPublisher struct {
+	urls   []string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookPublisher(urls []string, secret string) Publisher {
+	return &webhookPublisher{
+		urls:   urls,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish POSTs the event to each URL it hasn't reached yet and records those it reaches in
+// event.DeliveredTo, so that a retry after a failure only goes to the URLs that failed.
+func (p *webhookPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, url := range p.urls {
+		if delivered(event, url) {
+			continue
+		}
+		if err := p.deliver(ctx, url, event, body); err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		event.DeliveredTo = append(event.DeliveredTo, url)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d webhook deliveries failed: %s", len(failures), len(p.urls), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (p *webhookPublisher) deliver(ctx context.Context, url string, event *domain.Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("deliver to %s: %w", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Finsolvz-Event", string(event.Type))
+	req.Header.Set("X-Finsolvz-Event-ID", event.ID.Hex())
+	if p.secret != "" {
+		req.Header.Set("X-Finsolvz-Signature", "sha256="+Sign(p.secret, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("deliver to %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("deliver to %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func delivered(event *domain.Event, url string) bool {
+	for _, done := range event.DeliveredTo {
+		if done == url {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the hex HMAC-SHA256 of body using secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// logPublisher only logs events. Used when no delivery target is configured.
+type logPublisher struct{}
+
+func NewLogPublisher() Publisher {
+	return logPublisher{}
+}
+
+func (logPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	log.Infof(ctx, "Outbox event %s (%s) for %s", event.ID.Hex(), event.Type, event.AggregateID.Hex())
+	return nil
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		want   string
+	}{
+		// RFC 4231, test case 2
+		{name: "RFC 4231 vector", secret: "Jefe", body: "what do ya want for nothing?", want: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{name: "empty body", secret: "key", body: "", want: "5d5d139563c95b5967b9bd9a8c9b233a9dedb45072794cd232dc1b74832607d0"},
+		{name: "envelope", secret: "whsec_test", body: `{"id":"1","type":"report.created"}`, want: "7a2bf8501b47bf475c7e43fdb01a31e4b5d278608d15c19da3e85b30fa0babb8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, []byte(tt.body)); got != tt.want {
+				t.Fatalf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
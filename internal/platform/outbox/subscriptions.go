@@ -0,0 +1,204 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// subscriptionPublisher fans an event out into one queued delivery per matching subscription.
+// The actual HTTP calls are made by the DeliveryWorker so each subscriber retries independently.
+type subscriptionPublisher struct {
+	subscriptions domain.WebhookRepository
+	deliveries    domain.WebhookDeliveryRepository
+}
+
+func NewSubscriptionPublisher(subscriptions domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository) Publisher {
+	return &subscriptionPublisher{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+	}
+}
+
+func (p *subscriptionPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	subscriptions, err := p.subscriptions.GetActiveForEvent(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, subscription := range subscriptions {
+		delivery := &domain.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        body,
+			Status:         domain.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		if err := p.deliveries.Enqueue(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Consumer is a publisher taking part in a multi publisher. Its Name is recorded on the events
+// it takes, so it must stay the same across releases and differ from the others'.
+type Consumer struct {
+	Name      string
+	Publisher Publisher
+}
+
+// multiPublisher hands each event to every consumer that hasn't taken it yet and records those
+// that do in event.DeliveredTo. A failing consumer doesn't stop the others, and the retries of
+// the event only go to the consumers that failed.
+type multiPublisher []Consumer
+
+func NewMultiPublisher(consumers ...Consumer) Publisher {
+	return multiPublisher(consumers)
+}
+
+func (m multiPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	var failures []string
+	for _, consumer := range m {
+		if delivered(event, consumer.Name) {
+			continue
+		}
+		if err := consumer.Publisher.Publish(ctx, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", consumer.Name, err))
+			continue
+		}
+		event.DeliveredTo = append(event.DeliveredTo, consumer.Name)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d consumers failed: %s", len(failures), len(m), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// DeliveryWorker sends queued webhook deliveries, signing each body with its subscription's secret
+// and retrying failures with the same backoff as the outbox. Deliveries the subscriber rejects
+// are not retried. Each delivery is claimed before it is sent, so instances sharing the queue
+// don't send it twice.
+type DeliveryWorker struct {
+	subscriptions domain.WebhookRepository
+	deliveries    domain.WebhookDeliveryRepository
+	client        *http.Client
+	interval      time.Duration
+}
+
+func NewDeliveryWorker(subscriptions domain.WebhookRepository, deliveries domain.WebhookDeliveryRepository, interval time.Duration) *DeliveryWorker {
+	return &DeliveryWorker{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		interval:      interval,
+	}
+}
+
+// Run delivers webhooks until ctx is cancelled.
+func (w *DeliveryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.deliverBatch(ctx)
+		}
+	}
+}
+
+func (w *DeliveryWorker) deliverBatch(ctx context.Context) {
+	for i := 0; i < batchSize && ctx.Err() == nil; i++ {
+		delivery, err := w.deliveries.Claim(ctx, time.Now().Add(lease))
+		if err != nil {
+			log.Errorf(ctx, "Webhooks: failed to claim a pending delivery: %v", err)
+			return
+		}
+		if delivery == nil {
+			return
+		}
+
+		status, err := w.deliver(ctx, delivery)
+		if err != nil {
+			attempts := delivery.Attempts + 1
+			final := attempts >= maxAttempts || rejected(status)
+			if final {
+				log.Errorf(ctx, "Webhooks: giving up on delivery %s after %d attempts: %v", delivery.ID.Hex(), attempts, err)
+			} else {
+				log.Warnf(ctx, "Webhooks: delivery %s failed (attempt %d): %v", delivery.ID.Hex(), attempts, err)
+			}
+
+			if markErr := w.deliveries.MarkFailed(ctx, delivery.ID, err.Error(), status, time.Now().Add(backoff(attempts)), final); markErr != nil {
+				log.Errorf(ctx, "Webhooks: failed to record failure for delivery %s: %v", delivery.ID.Hex(), markErr)
+			}
+			continue
+		}
+
+		if err := w.deliveries.MarkDelivered(ctx, delivery.ID, status); err != nil {
+			log.Errorf(ctx, "Webhooks: failed to mark delivery %s delivered: %v", delivery.ID.Hex(), err)
+		}
+	}
+}
+
+// deliver POSTs a single delivery. The subscription is reloaded so a changed URL or secret,
+// or a deactivation, applies to retries already in the queue.
+func (w *DeliveryWorker) deliver(ctx context.Context, delivery *domain.WebhookDelivery) (int, error) {
+	subscription, err := w.subscriptions.GetByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	if !subscription.Active {
+		return 0, fmt.Errorf("subscription %s is inactive", subscription.ID.Hex())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Finsolvz-Event", string(delivery.EventType))
+	req.Header.Set("X-Finsolvz-Event-ID", delivery.EventID.Hex())
+	req.Header.Set("X-Finsolvz-Delivery-ID", delivery.ID.Hex())
+	req.Header.Set("X-Finsolvz-Signature", "sha256="+Sign(subscription.Secret, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("deliver to %s: %w", subscription.URL, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("deliver to %s: unexpected status %d", subscription.URL, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// rejected reports whether a response status means the subscriber refused the delivery, so
+// sending it again would get the same answer: a 4xx other than a timeout or rate limit.
+func rejected(status int) bool {
+	return status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests
+}
@@ -0,0 +1,336 @@
+package outbox
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+)
+
+type mockWebhookRepository struct {
+	subscription *domain.WebhookSubscription
+}
+
+func (m *mockWebhookRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WebhookSubscription, error) {
+	return m.subscription, nil
+}
+
+func (m *mockWebhookRepository) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return []*domain.WebhookSubscription{m.subscription}, nil
+}
+
+func (m *mockWebhookRepository) GetActiveForEvent(ctx context.Context, eventType domain.EventType) ([]*domain.WebhookSubscription, error) {
+	return []*domain.WebhookSubscription{m.subscription}, nil
+}
+
+func (m *mockWebhookRepository) Update(ctx context.Context, id primitive.ObjectID, subscription *domain.WebhookSubscription) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return nil
+}
+
+// mockDeliveryRepository holds one delivery and records it like the real repository. It
+// lets the delivery be claimed whatever its next attempt, so tests needn't wait out the backoff.
+type mockDeliveryRepository struct {
+	mu       sync.Mutex
+	delivery *domain.WebhookDelivery
+	backoffs []time.Duration
+}
+
+func (m *mockDeliveryRepository) Enqueue(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.delivery = delivery
+	return nil
+}
+
+func (m *mockDeliveryRepository) Claim(ctx context.Context, lockedUntil time.Time) (*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.delivery == nil {
+		return nil, nil
+	}
+	switch m.delivery.Status {
+	case domain.DeliveryStatusPending:
+	case domain.DeliveryStatusProcessing:
+		if m.delivery.LockedUntil.After(time.Now()) {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	m.delivery.Status = domain.DeliveryStatusProcessing
+	m.delivery.LockedUntil = &lockedUntil
+	claimed := *m.delivery
+	return &claimed, nil
+}
+
+func (m *mockDeliveryRepository) CountPending(ctx context.Context) (int64, error) {
+	if m.delivery == nil || m.delivery.Status == domain.DeliveryStatusDelivered || m.delivery.Status == domain.DeliveryStatusFailed {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (m *mockDeliveryRepository) MarkDelivered(ctx context.Context, id primitive.ObjectID, responseStatus int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.delivery.LockedUntil = nil
+	m.delivery.Attempts++
+	m.delivery.Status = domain.DeliveryStatusDelivered
+	m.delivery.ResponseStatus = responseStatus
+	m.delivery.DeliveredAt = &now
+	return nil
+}
+
+func (m *mockDeliveryRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, responseStatus int, nextAttemptAt time.Time, final bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.delivery.Status = domain.DeliveryStatusPending
+	m.delivery.LockedUntil = nil
+	m.delivery.Attempts++
+	m.delivery.LastError = &reason
+	m.delivery.ResponseStatus = responseStatus
+	m.delivery.NextAttemptAt = nextAttemptAt
+	m.backoffs = append(m.backoffs, time.Until(nextAttemptAt).Round(time.Second))
+	if final {
+		m.delivery.Status = domain.DeliveryStatusFailed
+	}
+	return nil
+}
+
+func (m *mockDeliveryRepository) ListBySubscription(ctx context.Context, subscriptionID primitive.ObjectID, limit int) ([]*domain.WebhookDelivery, error) {
+	return []*domain.WebhookDelivery{m.delivery}, nil
+}
+
+// subscriber is a webhook endpoint answering each request with the next of its statuses, and
+// then with the last one. It records the requests it receives.
+type subscriber struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (s *subscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, body)
+	w.WriteHeader(s.statuses[min(len(s.requests), len(s.statuses))-1])
+}
+
+const testSecret = "whsec_test"
+
+// newDelivery queues an event for a subscription to server and returns a worker for it.
+func newDelivery(t *testing.T, server *httptest.Server) (*DeliveryWorker, *mockDeliveryRepository) {
+	t.Helper()
+	subscriptions := &mockWebhookRepository{subscription: &domain.WebhookSubscription{
+		ID:     primitive.NewObjectID(),
+		URL:    server.URL,
+		Secret: testSecret,
+		Active: true,
+	}}
+	deliveries := &mockDeliveryRepository{}
+
+	event, err := domain.NewEvent(domain.EventReportCreated, primitive.NewObjectID(), map[string]string{"reportName": "Q1"})
+	if err != nil {
+		t.Fatalf("Failed to create the event: %v", err)
+	}
+	event.ID = primitive.NewObjectID()
+	if err := NewSubscriptionPublisher(subscriptions, deliveries).Publish(context.Background(), event); err != nil {
+		t.Fatalf("Failed to queue the delivery: %v", err)
+	}
+	deliveries.delivery.ID = primitive.NewObjectID()
+
+	return NewDeliveryWorker(subscriptions, deliveries, time.Second), deliveries
+}
+
+func TestDeliveryWorker_SignsDeliveries(t *testing.T) {
+	endpoint := &subscriber{statuses: []int{http.StatusNoContent}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+	worker, deliveries := newDelivery(t, server)
+
+	worker.deliverBatch(context.Background())
+
+	if len(endpoint.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(endpoint.requests))
+	}
+	request, body := endpoint.requests[0], endpoint.bodies[0]
+	if string(body) != string(deliveries.delivery.Payload) {
+		t.Fatalf("Expected the queued payload, got %s", body)
+	}
+
+	// Checked the way a subscriber would, without Sign
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); request.Header.Get("X-Finsolvz-Signature") != want {
+		t.Fatalf("Expected signature %s, got %s", want, request.Header.Get("X-Finsolvz-Signature"))
+	}
+	if request.Header.Get("X-Finsolvz-Event") != string(domain.EventReportCreated) || request.Header.Get("X-Finsolvz-Delivery-ID") != deliveries.delivery.ID.Hex() {
+		t.Fatalf("Expected the event and delivery headers, got %v", request.Header)
+	}
+	if deliveries.delivery.Status != domain.DeliveryStatusDelivered || deliveries.delivery.ResponseStatus != http.StatusNoContent {
+		t.Fatalf("Expected the delivery to be delivered with 204, got %s with %d", deliveries.delivery.Status, deliveries.delivery.ResponseStatus)
+	}
+}
+
+func TestDeliveryWorker_Retries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // answered in turn, then the last one again
+		requests int
+		status   domain.DeliveryStatus
+	}{
+		{name: "5xx until delivered", statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, requests: 4, status: domain.DeliveryStatusDelivered},
+		{name: "5xx until the last attempt", statuses: []int{http.StatusServiceUnavailable}, requests: maxAttempts, status: domain.DeliveryStatusFailed},
+		{name: "timeout then delivered", statuses: []int{http.StatusRequestTimeout, http.StatusOK}, requests: 2, status: domain.DeliveryStatusDelivered},
+		{name: "rate limited then delivered", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, requests: 2, status: domain.DeliveryStatusDelivered},
+		{name: "400 is not retried", statuses: []int{http.StatusBadRequest, http.StatusOK}, requests: 1, status: domain.DeliveryStatusFailed},
+		{name: "401 is not retried", statuses: []int{http.StatusUnauthorized, http.StatusOK}, requests: 1, status: domain.DeliveryStatusFailed},
+		{name: "410 is not retried", statuses: []int{http.StatusGone, http.StatusOK}, requests: 1, status: domain.DeliveryStatusFailed},
+		{name: "4xx after a 5xx ends the retries", statuses: []int{http.StatusBadGateway, http.StatusNotFound, http.StatusOK}, requests: 2, status: domain.DeliveryStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &subscriber{statuses: tt.statuses}
+			server := httptest.NewServer(endpoint)
+			defer server.Close()
+			worker, deliveries := newDelivery(t, server)
+
+			for i := 0; i < maxAttempts+2; i++ {
+				worker.deliverBatch(context.Background())
+			}
+
+			if len(endpoint.requests) != tt.requests {
+				t.Fatalf("Expected %d requests, got %d", tt.requests, len(endpoint.requests))
+			}
+			if deliveries.delivery.Status != tt.status || deliveries.delivery.Attempts != tt.requests {
+				t.Fatalf("Expected %s after %d attempts, got %s after %d", tt.status, tt.requests, deliveries.delivery.Status, deliveries.delivery.Attempts)
+			}
+			for i := 1; i < len(endpoint.bodies); i++ {
+				if string(endpoint.bodies[i]) != string(endpoint.bodies[0]) || endpoint.requests[i].Header.Get("X-Finsolvz-Signature") != endpoint.requests[0].Header.Get("X-Finsolvz-Signature") {
+					t.Fatalf("Expected retries to be byte-identical, attempt %d differs", i+1)
+				}
+			}
+			for i, delay := range deliveries.backoffs {
+				if want := backoff(i + 1); delay != want {
+					t.Fatalf("Expected attempt %d to be retried after %s, got %s", i+1, want, delay)
+				}
+			}
+		})
+	}
+}
+
+// countingPublisher fails its first failures calls and counts every call.
+type countingPublisher struct {
+	calls    int
+	failures int
+}
+
+func (p *countingPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestMultiPublisher_RetriesOnlyFailedConsumers(t *testing.T) {
+	first, failing, last := &countingPublisher{}, &countingPublisher{failures: 2}, &countingPublisher{}
+	publisher := NewMultiPublisher(
+		Consumer{Name: "first", Publisher: first},
+		Consumer{Name: "failing", Publisher: failing},
+		Consumer{Name: "last", Publisher: last},
+	)
+	event := &domain.Event{ID: primitive.NewObjectID(), Type: domain.EventReportDeleted}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := publisher.Publish(context.Background(), event); err == nil || !strings.Contains(err.Error(), "failing: unavailable") {
+			t.Fatalf("Expected attempt %d to fail for the failing consumer, got %v", attempt, err)
+		}
+	}
+	if first.calls != 1 || last.calls != 1 {
+		t.Fatalf("Expected the consumers after and before the failing one to be called once, got %d and %d", first.calls, last.calls)
+	}
+
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	if first.calls != 1 || failing.calls != 3 || last.calls != 1 {
+		t.Fatalf("Expected only the failing consumer to be retried, got %d, %d and %d calls", first.calls, failing.calls, last.calls)
+	}
+	if got := strings.Join(event.DeliveredTo, ", "); got != "first, last, failing" {
+		t.Fatalf("Expected every consumer to be recorded, got %s", got)
+	}
+}
+
+func TestDeliveryWorker_InstancesSendEachDeliveryOnce(t *testing.T) {
+	endpoint := &subscriber{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+	worker, deliveries := newDelivery(t, server)
+
+	// Instances share the queue through the repository
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		instance := NewDeliveryWorker(worker.subscriptions, deliveries, time.Second)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance.deliverBatch(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if len(endpoint.requests) != 1 || deliveries.delivery.Attempts != 1 {
+		t.Fatalf("Expected 1 request, got %d after %d attempts", len(endpoint.requests), deliveries.delivery.Attempts)
+	}
+}
+
+func TestDeliveryWorker_ClaimsDeliveriesLeftByStoppedInstances(t *testing.T) {
+	endpoint := &subscriber{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+	worker, deliveries := newDelivery(t, server)
+
+	// Another instance claimed the delivery and stopped before sending it
+	if claimed, _ := deliveries.Claim(context.Background(), time.Now().Add(time.Minute)); claimed == nil {
+		t.Fatal("Expected the delivery to be claimed")
+	}
+	worker.deliverBatch(context.Background())
+	if len(endpoint.requests) != 0 {
+		t.Fatalf("Expected a claimed delivery to be left alone, got %d requests", len(endpoint.requests))
+	}
+
+	expired := time.Now().Add(-time.Second)
+	deliveries.delivery.LockedUntil = &expired
+	worker.deliverBatch(context.Background())
+	if len(endpoint.requests) != 1 || deliveries.delivery.Status != domain.DeliveryStatusDelivered {
+		t.Fatalf("Expected the delivery to be sent once its claim ran out, got %d requests and %s", len(endpoint.requests), deliveries.delivery.Status)
+	}
+}
@@ -0,0 +1,235 @@
+// Package policy decides who may do what. Rules are data, read from policy.csv (or the file
+// set in POLICY_FILE), so access changes such as "admins may edit reports only for their
+// companies" need no code change.
+//
+// A decision takes a subject (the user and role), an action (read, update...) and a
+// resource (its type plus, for conditional rules, its owners and companies):
+//
+//	policy.Allowed(ctx, subject, "update", policy.Resource{
+//		Type:      "report",
+//		Owners:    []string{report.CreatedBy.Hex()},
+//		Companies: []string{report.Company.Hex()},
+//	})
+package policy
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Conditions a rule may carry.
+const (
+	Owner   = "owner"   // the subject is one of the resource's owners
+	Company = "company" // the resource belongs to one of the subject's companies
+)
+
+// Any matches every role, action or resource in a rule.
+const Any = "*"
+
+// Rule allows Role to perform Action on Resource, when Condition holds if set.
+type Rule struct {
+	Role      string
+	Action    string
+	Resource  string
+	Condition string
+}
+
+// Subject is the user asking. Companies may be left nil; they are then looked up through
+// the engine's Membership when a company rule needs them.
+type Subject struct {
+	UserID    string
+	Role      string
+	Companies []string
+}
+
+// Resource is what the action applies to. Owners and Companies only matter to rules with a
+// condition; route-level checks leave them empty, so only unconditional rules apply.
+type Resource struct {
+	Type      string
+	Owners    []string
+	Companies []string
+}
+
+// Membership returns the IDs of the companies a user belongs to.
+type Membership func(ctx context.Context, userID string) ([]string, error)
+
+// Engine evaluates a set of rules.
+type Engine struct {
+	rules      []Rule
+	membership Membership
+}
+
+//go:embed policy.csv
+var builtin string
+
+// New returns an engine for rules; membership may be nil when no rule has the company
+// condition or subjects always carry their companies.
+func New(rules []Rule, membership Membership) *Engine {
+	return &Engine{rules: rules, membership: membership}
+}
+
+// Builtin returns the rules of the policy.csv shipped with the server.
+func Builtin() []Rule {
+	rules, err := Parse(strings.NewReader(builtin))
+	if err != nil {
+		panic(fmt.Sprintf("policy: built-in policy.csv: %v", err))
+	}
+	return rules
+}
+
+// LoadFile reads the rules of a policy file, or the built-in ones when path is empty.
+func LoadFile(path string) ([]Rule, error) {
+	if path == "" {
+		return Builtin(), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rules, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse reads rules in the policy.csv format: "role, action, resource[, condition]" per
+// line, with # comments and blank lines ignored.
+func Parse(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("line %d: want role, action, resource[, condition], got %q", n, line)
+		}
+		rule := Rule{Role: fields[0], Action: fields[1], Resource: fields[2]}
+		if len(fields) == 4 {
+			rule.Condition = fields[3]
+		}
+		if rule.Role == "" || rule.Action == "" || rule.Resource == "" {
+			return nil, fmt.Errorf("line %d: empty role, action or resource in %q", n, line)
+		}
+		if rule.Condition != "" && rule.Condition != Owner && rule.Condition != Company {
+			return nil, fmt.Errorf("line %d: unknown condition %q, want %s or %s", n, rule.Condition, Owner, Company)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// Allowed reports whether a rule lets sub perform action on res. The error is that of
+// Membership, when a company rule needed the subject's companies and they couldn't be read.
+func (e *Engine) Allowed(ctx context.Context, sub Subject, action string, res Resource) (bool, error) {
+	for _, rule := range e.rules {
+		if !matches(rule.Role, sub.Role) || !matches(rule.Action, action) || !matches(rule.Resource, res.Type) {
+			continue
+		}
+		switch rule.Condition {
+		case "":
+			return true, nil
+		case Owner:
+			if contains(res.Owners, sub.UserID) {
+				return true, nil
+			}
+		case Company:
+			if len(res.Companies) == 0 {
+				continue
+			}
+			if sub.Companies == nil && e.membership != nil && sub.UserID != "" {
+				companies, err := e.membership(ctx, sub.UserID)
+				if err != nil {
+					return false, err
+				}
+				sub.Companies = companies
+			}
+			for _, company := range res.Companies {
+				if contains(sub.Companies, company) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// Roles lists the roles an unconditional rule allows to perform action on resource, or
+// [*] when every role is; cmd/openapi-gen documents routes with them.
+func (e *Engine) Roles(action, resource string) []string {
+	var roles []string
+	for _, rule := range e.rules {
+		if rule.Condition != "" || !matches(rule.Action, action) || !matches(rule.Resource, resource) {
+			continue
+		}
+		if rule.Role == Any {
+			return []string{Any}
+		}
+		if !contains(roles, rule.Role) {
+			roles = append(roles, rule.Role)
+		}
+	}
+	return roles
+}
+
+// Companies returns the companies of a user through the engine's Membership, or nil when
+// it has none.
+func (e *Engine) Companies(ctx context.Context, userID string) ([]string, error) {
+	if e.membership == nil {
+		return nil, nil
+	}
+	return e.membership(ctx, userID)
+}
+
+func matches(pattern, value string) bool {
+	return pattern == Any || pattern == value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var defaultEngine atomic.Pointer[Engine]
+
+func init() {
+	defaultEngine.Store(New(Builtin(), nil))
+}
+
+// SetDefault replaces the engine used by Allowed, at startup once the policy file and the
+// user repository are available.
+func SetDefault(e *Engine) {
+	defaultEngine.Store(e)
+}
+
+// Default returns the engine used by Allowed.
+func Default() *Engine {
+	return defaultEngine.Load()
+}
+
+// Allowed asks the default engine; see Engine.Allowed.
+func Allowed(ctx context.Context, sub Subject, action string, res Resource) (bool, error) {
+	return Default().Allowed(ctx, sub, action, res)
+}
+
+// Companies asks the default engine; see Engine.Companies.
+func Companies(ctx context.Context, userID string) ([]string, error) {
+	return Default().Companies(ctx, userID)
+}
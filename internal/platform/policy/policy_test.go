@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEngine_Allowed_BuiltinPolicy(t *testing.T) {
+	memberships := map[string][]string{
+		"admin":  {"company-a"},
+		"client": {"company-a"},
+	}
+	engine := New(Builtin(), func(ctx context.Context, userID string) ([]string, error) {
+		return memberships[userID], nil
+	})
+
+	report := func(owner, company string) Resource {
+		return Resource{Type: "report", Owners: []string{owner}, Companies: []string{company}}
+	}
+
+	tests := []struct {
+		name   string
+		sub    Subject
+		action string
+		res    Resource
+		want   bool
+	}{
+		// SUPER_ADMIN, *, *
+		{"super admin updates any report", Subject{UserID: "root", Role: "SUPER_ADMIN"}, "update", report("someone", "company-b"), true},
+		{"super admin manages the system", Subject{UserID: "root", Role: "SUPER_ADMIN"}, "manage", Resource{Type: "system"}, true},
+		{"super admin performs an action no other rule names", Subject{UserID: "root", Role: "SUPER_ADMIN"}, "impersonate", Resource{Type: "user"}, true},
+
+		// Company condition
+		{"admin updates a report of their company", Subject{UserID: "admin", Role: "ADMIN"}, "update", report("someone", "company-a"), true},
+		{"admin updates a report of another company", Subject{UserID: "admin", Role: "ADMIN"}, "update", report("someone", "company-b"), false},
+		{"admin approves a report of their company", Subject{UserID: "admin", Role: "ADMIN"}, "approve", report("someone", "company-a"), true},
+		{"admin approves a report of another company", Subject{UserID: "admin", Role: "ADMIN"}, "approve", report("admin", "company-b"), false},
+		{"admin company rule without the resource's companies", Subject{UserID: "admin", Role: "ADMIN"}, "manage", Resource{Type: "budget"}, false},
+		{"subject's own companies are used over membership", Subject{UserID: "admin", Role: "ADMIN", Companies: []string{"company-b"}}, "update", report("someone", "company-b"), true},
+
+		// Owner condition
+		{"admin updates a report they created elsewhere", Subject{UserID: "admin", Role: "ADMIN"}, "update", report("admin", "company-b"), true},
+		{"client updates a report they created", Subject{UserID: "client", Role: "CLIENT"}, "update", report("client", "company-b"), true},
+		{"client deletes a report they created", Subject{UserID: "client", Role: "CLIENT"}, "delete", report("client", "company-b"), true},
+		{"client updates a report of their company created by someone else", Subject{UserID: "client", Role: "CLIENT"}, "update", report("someone", "company-a"), false},
+		{"client approves a report they created", Subject{UserID: "client", Role: "CLIENT"}, "approve", report("client", "company-a"), false},
+		{"any role reads their own task", Subject{UserID: "client", Role: "CLIENT"}, "read", Resource{Type: "task", Owners: []string{"client"}}, true},
+		{"any role reads someone else's task", Subject{UserID: "client", Role: "CLIENT"}, "read", Resource{Type: "task", Owners: []string{"admin"}}, false},
+
+		// Unconditional rules
+		{"admin lists users", Subject{UserID: "admin", Role: "ADMIN"}, "list", Resource{Type: "user"}, true},
+		{"client manages report types", Subject{UserID: "client", Role: "CLIENT"}, "manage", Resource{Type: "reporttype"}, true},
+
+		// No rule matches
+		{"admin deletes a user", Subject{UserID: "admin", Role: "ADMIN"}, "delete", Resource{Type: "user"}, false},
+		{"admin manages the system", Subject{UserID: "admin", Role: "ADMIN"}, "manage", Resource{Type: "system"}, false},
+		{"client lists users", Subject{UserID: "client", Role: "CLIENT"}, "list", Resource{Type: "user"}, false},
+		{"client manages webhooks", Subject{UserID: "client", Role: "CLIENT"}, "manage", Resource{Type: "webhook"}, false},
+		{"unknown role", Subject{UserID: "guest", Role: "GUEST"}, "update", report("guest", "company-a"), false},
+		{"no role", Subject{UserID: "guest"}, "list", Resource{Type: "user"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Allowed(context.Background(), tt.sub, tt.action, tt.res)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Expected allowed to be %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEngine_Allowed_MembershipError(t *testing.T) {
+	failure := errors.New("users unavailable")
+	engine := New([]Rule{{Role: "ADMIN", Action: "update", Resource: "report", Condition: Company}}, func(ctx context.Context, userID string) ([]string, error) {
+		return nil, failure
+	})
+
+	allowed, err := engine.Allowed(context.Background(), Subject{UserID: "admin", Role: "ADMIN"}, "update", Resource{Type: "report", Companies: []string{"company-a"}})
+	if allowed || !errors.Is(err, failure) {
+		t.Fatalf("Expected a denial with the membership error, got %v, %v", allowed, err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Rule
+		wantErr string
+	}{
+		{
+			name:  "rules, comments and blank lines",
+			input: "# comment\n\nSUPER_ADMIN, *, *\n  ADMIN ,update,  report , company\nCLIENT, delete, report, owner\n",
+			want: []Rule{
+				{Role: "SUPER_ADMIN", Action: "*", Resource: "*"},
+				{Role: "ADMIN", Action: "update", Resource: "report", Condition: Company},
+				{Role: "CLIENT", Action: "delete", Resource: "report", Condition: Owner},
+			},
+		},
+		{name: "too few fields", input: "ADMIN, update\n", wantErr: "line 1: want role, action, resource"},
+		{name: "too many fields", input: "# rules\nADMIN, update, report, company, owner\n", wantErr: "line 2: want role, action, resource"},
+		{name: "empty role", input: ", update, report\n", wantErr: "line 1: empty role, action or resource"},
+		{name: "empty resource", input: "ADMIN, update, \n", wantErr: "line 1: empty role, action or resource"},
+		{name: "unknown condition", input: "ADMIN, update, report, team\n", wantErr: `line 1: unknown condition "team"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Parse(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(rules) != len(tt.want) {
+				t.Fatalf("Expected %d rules, got %d: %+v", len(tt.want), len(rules), rules)
+			}
+			for i := range rules {
+				if rules[i] != tt.want[i] {
+					t.Fatalf("Expected rule %d to be %+v, got %+v", i, tt.want[i], rules[i])
+				}
+			}
+		})
+	}
+}
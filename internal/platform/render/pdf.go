@@ -0,0 +1,262 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// Page layout of PDF listings: landscape A4 in points, with one line per row.
+const (
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 36
+	pdfFontSize   = 8
+	pdfTitleSize  = 12
+	pdfLineHeight = 11
+	// pdfCharWidth is the average Helvetica character width at pdfFontSize, for truncating cells
+	pdfCharWidth = 4.2
+	// Logos are drawn this high, and narrowed to at most pdfLogoMaxWidth
+	pdfLogoHeight   = 24
+	pdfLogoMaxWidth = 120
+)
+
+// The objects written before the pages; the catalog and page tree follow them at the end,
+// once every page is known.
+const (
+	pdfCatalogID  = 1
+	pdfPagesID    = 2
+	pdfFontID     = 3
+	pdfBoldFontID = 4
+	pdfFirstID    = 5
+)
+
+type pdfWriter struct {
+	w       *countingWriter
+	title   string
+	columns []string
+	style   Style
+	offsets map[int]int64
+	nextID  int
+	pages   []int
+
+	logoID                int // 0 without a logo
+	logoWidth, logoHeight float64
+
+	page *bytes.Buffer // content of the page being filled
+	y    float64
+}
+
+func newPDFWriter(w io.Writer, title string, columns []string, style Style) (*pdfWriter, error) {
+	p := &pdfWriter{
+		w:       &countingWriter{w: w},
+		title:   title,
+		columns: columns,
+		style:   style,
+		offsets: make(map[int]int64),
+		nextID:  pdfFirstID,
+	}
+	if _, err := io.WriteString(p.w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, err
+	}
+	if err := p.object(pdfFontID, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"); err != nil {
+		return nil, err
+	}
+	if err := p.object(pdfBoldFontID, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"); err != nil {
+		return nil, err
+	}
+	if style.Logo != nil {
+		if err := p.writeLogo(style.Logo); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// writeLogo writes the logo once as an image every page draws.
+func (p *pdfWriter) writeLogo(logo image.Image) error {
+	bounds := logo.Bounds()
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return nil
+	}
+	data, err := jpegLogo(logo)
+	if err != nil {
+		return err
+	}
+
+	p.logoHeight = pdfLogoHeight
+	p.logoWidth = pdfLogoHeight * float64(bounds.Dx()) / float64(bounds.Dy())
+	if p.logoWidth > pdfLogoMaxWidth {
+		p.logoHeight *= pdfLogoMaxWidth / p.logoWidth
+		p.logoWidth = pdfLogoMaxWidth
+	}
+
+	p.logoID = p.nextID
+	p.nextID++
+	return p.object(p.logoID, fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream",
+		bounds.Dx(), bounds.Dy(), len(data), data))
+}
+
+func (p *pdfWriter) WriteRow(cells []string) error {
+	if p.page == nil || p.y < pdfMargin+pdfLineHeight {
+		if err := p.finishPage(); err != nil {
+			return err
+		}
+		p.startPage()
+	}
+	p.line("/F1", cells)
+	return nil
+}
+
+func (p *pdfWriter) Close() error {
+	if p.page == nil {
+		p.startPage()
+	}
+	if err := p.finishPage(); err != nil {
+		return err
+	}
+
+	kids := make([]string, len(p.pages))
+	for i, id := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	pages := fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages))
+	if err := p.object(pdfPagesID, pages); err != nil {
+		return err
+	}
+	if err := p.object(pdfCatalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesID)); err != nil {
+		return err
+	}
+
+	// Cross-reference table: objects are numbered 1..nextID-1, entries must be 20 bytes
+	xref := p.w.n
+	var b strings.Builder
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", p.nextID)
+	for id := 1; id < p.nextID; id++ {
+		fmt.Fprintf(&b, "%010d 00000 n \n", p.offsets[id])
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", p.nextID, pdfCatalogID, xref)
+	_, err := io.WriteString(p.w, b.String())
+	return err
+}
+
+// startPage begins a page with the logo, title, footer and the header row.
+func (p *pdfWriter) startPage() {
+	p.page = &bytes.Buffer{}
+	top := float64(pdfPageHeight - pdfMargin)
+	titleX := float64(pdfMargin)
+	p.y = top - pdfTitleSize
+	if p.logoID != 0 {
+		x := float64(pdfMargin)
+		if p.style.LogoPlacement == LogoRight {
+			x = pdfPageWidth - pdfMargin - p.logoWidth
+		} else {
+			titleX += p.logoWidth + 8
+		}
+		fmt.Fprintf(p.page, "q %.1f 0 0 %.1f %.1f %.1f cm /Im1 Do Q\n", p.logoWidth, p.logoHeight, x, top-p.logoHeight)
+		// The title is centered on the logo
+		p.y = top - p.logoHeight/2 - pdfTitleSize/3
+	}
+
+	fmt.Fprintf(p.page, "q %s BT /F2 %d Tf %.1f %.1f Td (%s) Tj ET Q\n", pdfColor(p.style.PrimaryColor, "rg"), pdfTitleSize, titleX, p.y, pdfText(p.title))
+	fmt.Fprintf(p.page, "BT /F1 %d Tf %d %d Td (Page %d) Tj ET\n", pdfFontSize, pdfPageWidth-pdfMargin-40, pdfMargin/2, len(p.pages)+1)
+	if p.style.FooterText != "" {
+		fmt.Fprintf(p.page, "q %s BT /F1 %d Tf %d %d Td (%s) Tj ET Q\n", pdfColor(p.style.AccentColor, "rg"), pdfFontSize, pdfMargin, pdfMargin/2, pdfText(p.style.FooterText))
+	}
+
+	if p.logoID != 0 {
+		p.y = min(p.y-pdfLineHeight*2, top-p.logoHeight-pdfLineHeight)
+	} else {
+		p.y -= pdfLineHeight * 2
+	}
+	fmt.Fprintf(p.page, "q %s\n", pdfColor(p.style.PrimaryColor, "rg"))
+	p.line("/F2", p.columns)
+	fmt.Fprintf(p.page, "Q q %s %d %.1f m %d %.1f l S Q\n", pdfColor(p.style.AccentColor, "RG"), pdfMargin, p.y+pdfLineHeight-2, pdfPageWidth-pdfMargin, p.y+pdfLineHeight-2)
+}
+
+// line writes cells in equal columns at the current position and moves down a line.
+func (p *pdfWriter) line(font string, cells []string) {
+	width := float64(pdfPageWidth-2*pdfMargin) / float64(max(len(p.columns), 1))
+	maxChars := int(width/pdfCharWidth) - 1
+	for i, cell := range cells {
+		if i >= len(p.columns) {
+			break
+		}
+		if runes := []rune(cell); len(runes) > maxChars && maxChars > 1 {
+			cell = string(runes[:maxChars-1]) + "…"
+		}
+		x := float64(pdfMargin) + float64(i)*width
+		fmt.Fprintf(p.page, "BT %s %d Tf %.1f %.1f Td (%s) Tj ET\n", font, pdfFontSize, x, p.y, pdfText(cell))
+	}
+	p.y -= pdfLineHeight
+}
+
+// finishPage writes the page being filled, if any, as a content stream and a page object.
+func (p *pdfWriter) finishPage() error {
+	if p.page == nil {
+		return nil
+	}
+	contentID, pageID := p.nextID, p.nextID+1
+	p.nextID += 2
+
+	content := fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.page.Len(), p.page.String())
+	if err := p.object(contentID, content); err != nil {
+		return err
+	}
+	var images string
+	if p.logoID != 0 {
+		images = fmt.Sprintf(" /XObject << /Im1 %d 0 R >>", p.logoID)
+	}
+	page := fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >>%s >> /Contents %d 0 R >>",
+		pdfPagesID, pdfPageWidth, pdfPageHeight, pdfFontID, pdfBoldFontID, images, contentID)
+	if err := p.object(pageID, page); err != nil {
+		return err
+	}
+	p.pages = append(p.pages, pageID)
+	p.page = nil
+	return nil
+}
+
+func (p *pdfWriter) object(id int, body string) error {
+	p.offsets[id] = p.w.n
+	_, err := fmt.Fprintf(p.w, "%d 0 obj\n%s\nendobj\n", id, body)
+	return err
+}
+
+// pdfText encodes s as the body of a PDF string in WinAnsiEncoding. Latin-1 characters map to
+// themselves there; the ellipsis has its own code and anything else becomes "?".
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r == '…':
+			b.WriteString(`\205`)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '\t' || r == '\n' || r == '\r':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// countingWriter tracks the offset of each object for the cross-reference table.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,45 @@
+// Package render writes tables as documents for downloads: XLSX workbooks and PDF listings.
+// Rows are written as they come, so large tables stream into the object store.
+package render
+
+import (
+	"io"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Formats and the content types of their files
+const (
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	ContentTypePDF  = "application/pdf"
+)
+
+// TableWriter writes the rows of one table. Close finishes the document; it does not close
+// the underlying writer.
+type TableWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// NewTableWriter starts a document in format with a header row of columns, in style. title
+// names the sheet of a workbook and heads every page of a PDF.
+func NewTableWriter(w io.Writer, format, title string, columns []string, style Style) (TableWriter, error) {
+	switch format {
+	case FormatXLSX:
+		return newXLSXWriter(w, title, columns, style)
+	case FormatPDF:
+		return newPDFWriter(w, title, columns, style)
+	}
+	return nil, errors.New("UNSUPPORTED_FORMAT", "Unsupported document format", 400, nil, map[string]interface{}{"format": format})
+}
+
+// ContentType returns the content type of documents in format.
+func ContentType(format string) string {
+	if format == FormatPDF {
+		return ContentTypePDF
+	}
+	return ContentTypeXLSX
+}
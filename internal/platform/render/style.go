@@ -0,0 +1,64 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"strconv"
+)
+
+// Logo placements
+const (
+	LogoLeft  = "left"
+	LogoRight = "right"
+)
+
+// Style brands a document. The zero Style renders the default look.
+type Style struct {
+	// Logo is drawn next to the title of every PDF page; workbooks have no room for one
+	Logo          image.Image
+	LogoPlacement string // left (default) or right of the page
+	// PrimaryColor colors the title and header row, AccentColor the header rule and footer;
+	// both "#RRGGBB", black when empty
+	PrimaryColor string
+	AccentColor  string
+	FooterText   string
+}
+
+// rgb returns the red, green and blue components of a "#RRGGBB" color, from 0 to 1.
+func rgb(hex string) (r, g, b float64, ok bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return 0, 0, 0, false
+	}
+	value, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255, true
+}
+
+// pdfColor is the PDF operator setting the fill (rg) or stroke (RG) color to hex, or black.
+func pdfColor(hex, operator string) string {
+	r, g, b, ok := rgb(hex)
+	if !ok {
+		return "0 0 0 " + operator
+	}
+	return fmt.Sprintf("%.3f %.3f %.3f %s", r, g, b, operator)
+}
+
+// jpegLogo flattens a logo onto white, since PDF JPEGs have no transparency, and encodes it.
+func jpegLogo(logo image.Image) ([]byte, error) {
+	bounds := logo.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), logo, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The parts of a workbook with a single sheet of inline strings. Inline strings need no
+// shared string table, so rows are written straight into the sheet.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+
+	// Styled workbooks add a stylesheet whose cell format 1 is the header row: bold, in white
+	// on the primary color
+	xlsxStylesType = `<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+	xlsxStylesRel  = `<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`
+	xlsxStyles     = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><color rgb="FFFFFFFF"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="FF%s"/></patternFill></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/></cellXfs>` +
+		`</styleSheet>`
+)
+
+// xlsxSheetNameMax is the longest sheet name Excel accepts.
+const xlsxSheetNameMax = 31
+
+type xlsxWriter struct {
+	zip    *zip.Writer
+	sheet  io.Writer
+	row    int
+	footer string
+}
+
+// newXLSXWriter starts a workbook. Of the style, the header row takes the primary color and
+// the printed pages the footer text.
+func newXLSXWriter(w io.Writer, title string, columns []string, style Style) (*xlsxWriter, error) {
+	x := &xlsxWriter{zip: zip.NewWriter(w), footer: style.FooterText}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "%s", escapeXML(sheetName(title)), 1)},
+	}
+	headerStyle := ""
+	if _, _, _, ok := rgb(style.PrimaryColor); ok {
+		parts[0].content = strings.Replace(xlsxContentTypes, "</Types>", xlsxStylesType+"</Types>", 1)
+		parts[2].content = strings.Replace(xlsxWorkbookRels, "</Relationships>", xlsxStylesRel+"</Relationships>", 1)
+		parts = append(parts, struct{ name, content string }{"xl/styles.xml", strings.Replace(xlsxStyles, "%s", strings.ToUpper(style.PrimaryColor[1:]), 1)})
+		headerStyle = ` s="1"`
+	}
+	for _, part := range parts {
+		f, err := x.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	x.sheet = sheet
+
+	return x, x.writeRow(columns, headerStyle)
+}
+
+func (x *xlsxWriter) WriteRow(cells []string) error {
+	return x.writeRow(cells, "")
+}
+
+// writeRow writes cells with the given style attribute.
+func (x *xlsxWriter) writeRow(cells []string, style string) error {
+	x.row++
+	var b strings.Builder
+	b.WriteString(`<row r="` + strconv.Itoa(x.row) + `">`)
+	for _, cell := range cells {
+		b.WriteString(`<c t="inlineStr"` + style + `><is><t xml:space="preserve">`)
+		b.WriteString(escapeXML(cell))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	end := xlsxSheetEnd
+	if x.footer != "" {
+		// "&" starts a header code, so a literal one is doubled; &P is the page number
+		footer := "&L" + strings.ReplaceAll(x.footer, "&", "&&") + "&RPage &P"
+		end = `</sheetData><headerFooter><oddFooter>` + escapeXML(footer) + `</oddFooter></headerFooter></worksheet>`
+	}
+	if _, err := io.WriteString(x.sheet, end); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// sheetName strips the characters Excel rejects in sheet names and shortens the name to fit.
+func sheetName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, title)
+	if runes := []rune(name); len(runes) > xlsxSheetNameMax {
+		name = string(runes[:xlsxSheetNameMax])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,379 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+var esHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// esMappings are the fields of the index. IDs are keywords so filters and facets match them
+// exactly; names and emails are analyzed text for typo-tolerant matching.
+var esMappings = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"kind":         map[string]string{"type": "keyword"},
+		"title":        map[string]string{"type": "text"},
+		"detail":       map[string]string{"type": "text"},
+		"organization": map[string]string{"type": "keyword"},
+		"company":      map[string]string{"type": "keyword"},
+		"companies":    map[string]string{"type": "keyword"},
+		"reportType":   map[string]string{"type": "keyword"},
+		"year":         map[string]string{"type": "integer"},
+		"createdBy":    map[string]string{"type": "keyword"},
+		"users":        map[string]string{"type": "keyword"},
+	},
+}
+
+// esDocument is a domain.SearchDocument as stored in Elasticsearch.
+type esDocument struct {
+	Kind         string   `json:"kind"`
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Detail       string   `json:"detail,omitempty"`
+	Organization string   `json:"organization,omitempty"`
+	Company      string   `json:"company,omitempty"`
+	Companies    []string `json:"companies,omitempty"`
+	ReportType   string   `json:"reportType,omitempty"`
+	Year         int      `json:"year,omitempty"`
+	CreatedBy    string   `json:"createdBy,omitempty"`
+	Users        []string `json:"users,omitempty"`
+}
+
+type elasticsearch struct {
+	url    string
+	index  string
+	apiKey string
+
+	mu      sync.Mutex
+	created bool
+}
+
+// NewElasticsearch searches an Elasticsearch index, created with its mappings on first use.
+func NewElasticsearch(url, index, apiKey string) (domain.SearchIndex, error) {
+	if url == "" {
+		return nil, errors.New("SEARCH_CONFIG_MISSING", "Search backend configuration not found", 500, nil,
+			map[string]interface{}{"backend": BackendElasticsearch})
+	}
+	if index == "" {
+		index = DefaultElasticsearchIndex
+	}
+	return &elasticsearch{url: strings.TrimRight(url, "/"), index: index, apiKey: apiKey}, nil
+}
+
+func (e *elasticsearch) Backend() string { return BackendElasticsearch }
+
+func (e *elasticsearch) Index(ctx context.Context, documents []domain.SearchDocument) error {
+	if err := e.ensureIndex(ctx); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, document := range documents {
+		action := map[string]interface{}{"index": map[string]string{"_id": documentID(document.Kind, document.ID)}}
+		if err := encoder.Encode(action); err != nil {
+			return errors.New("SEARCH_ERROR", "Failed to encode search documents", 500, err, nil)
+		}
+		if err := encoder.Encode(toESDocument(document)); err != nil {
+			return errors.New("SEARCH_ERROR", "Failed to encode search documents", 500, err, nil)
+		}
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/"+e.index+"/_bulk", "application/x-ndjson", &body, &result); err != nil {
+		return err
+	}
+	if result.Errors {
+		for _, item := range result.Items {
+			for _, outcome := range item {
+				if len(outcome.Error) > 0 {
+					return errors.New("SEARCH_ERROR", "Failed to index search documents", 502, fmt.Errorf("%s", outcome.Error), nil)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e *elasticsearch) Remove(ctx context.Context, kind string, id primitive.ObjectID) error {
+	err := e.do(ctx, http.MethodDelete, "/"+e.index+"/_doc/"+documentID(kind, id), "", nil, nil)
+	if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (e *elasticsearch) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResults, error) {
+	filters := []interface{}{accessFilter(ctx)}
+	if len(query.Kinds) > 0 {
+		filters = append(filters, terms("kind", query.Kinds))
+	}
+	if query.Company != nil {
+		filters = append(filters, anyOf(term("company", query.Company.Hex()), term("companies", query.Company.Hex())))
+	}
+	if query.ReportType != nil {
+		filters = append(filters, term("reportType", query.ReportType.Hex()))
+	}
+	if query.Year != nil {
+		filters = append(filters, term("year", *query.Year))
+	}
+
+	request := map[string]interface{}{
+		"size":             query.Limit,
+		"track_total_hits": true,
+		"query": map[string]interface{}{"bool": map[string]interface{}{
+			"must": map[string]interface{}{"multi_match": map[string]interface{}{
+				"query":     query.Text,
+				"fields":    []string{"title^2", "detail"},
+				"fuzziness": "AUTO",
+			}},
+			"filter": filters,
+		}},
+		"aggs": map[string]interface{}{
+			domain.SearchFacetKind:       facet("kind"),
+			domain.SearchFacetCompany:    facet("company"),
+			domain.SearchFacetReportType: facet("reportType"),
+			domain.SearchFacetYear:       facet("year"),
+		},
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.New("SEARCH_ERROR", "Failed to encode search", 500, err, nil)
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Score  float64    `json:"_score"`
+				Source esDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]struct {
+			Buckets []struct {
+				Key      interface{} `json:"key"`
+				DocCount int64       `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"aggregations"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/"+e.index+"/_search", "application/json", bytes.NewReader(body), &response); err != nil {
+		// Nothing was indexed yet
+		if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusNotFound {
+			return &domain.SearchResults{Hits: []domain.SearchHit{}, Facets: map[string][]domain.SearchFacetValue{}}, nil
+		}
+		return nil, err
+	}
+
+	results := &domain.SearchResults{
+		Total:  response.Hits.Total.Value,
+		Hits:   make([]domain.SearchHit, 0, len(response.Hits.Hits)),
+		Facets: map[string][]domain.SearchFacetValue{},
+	}
+	for _, hit := range response.Hits.Hits {
+		id, err := primitive.ObjectIDFromHex(hit.Source.ID)
+		if err != nil {
+			continue
+		}
+		results.Hits = append(results.Hits, domain.SearchHit{
+			Kind:       hit.Source.Kind,
+			ID:         id,
+			Title:      hit.Source.Title,
+			Detail:     hit.Source.Detail,
+			Company:    objectID(hit.Source.Company),
+			ReportType: objectID(hit.Source.ReportType),
+			Year:       hit.Source.Year,
+			Score:      hit.Score,
+		})
+	}
+	for name, aggregation := range response.Aggregations {
+		values := make([]domain.SearchFacetValue, len(aggregation.Buckets))
+		for i, bucket := range aggregation.Buckets {
+			// Numbers come back as float64
+			if number, ok := bucket.Key.(float64); ok {
+				bucket.Key = int64(number)
+			}
+			values[i] = domain.SearchFacetValue{Value: fmt.Sprint(bucket.Key), Count: bucket.DocCount}
+		}
+		if len(values) > 0 {
+			results.Facets[name] = values
+		}
+	}
+
+	return results, nil
+}
+
+// ensureIndex creates the index with its mappings unless it exists.
+func (e *elasticsearch) ensureIndex(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.created {
+		return nil
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"mappings": esMappings})
+	err := e.do(ctx, http.MethodPut, "/"+e.index, "application/json", bytes.NewReader(body), nil)
+	if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusBadRequest &&
+		strings.Contains(appErr.Error(), "resource_already_exists_exception") {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	e.created = true
+	return nil
+}
+
+// do sends a request to the cluster and decodes the response into out, if given. Statuses
+// from the cluster are kept on the errors so callers can tell missing documents apart.
+func (e *elasticsearch) do(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, e.url+path, body)
+	if err != nil {
+		return errors.New("SEARCH_ERROR", "Failed to build search request", 500, err, nil)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	}
+
+	resp, err := esHTTPClient.Do(req)
+	if err != nil {
+		return errors.New("SEARCH_ERROR", "Search backend is unavailable", 502, err, map[string]interface{}{"backend": BackendElasticsearch})
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		cause := fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(reason)))
+		details := map[string]interface{}{"backend": BackendElasticsearch}
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return errors.New("SEARCH_ERROR", "Search index or document not found", 404, cause, details)
+		case http.StatusBadRequest:
+			return errors.New("SEARCH_ERROR", "Search request was rejected", 400, cause, details)
+		}
+		return errors.New("SEARCH_ERROR", "Search backend failed", 502, cause, details)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.New("SEARCH_ERROR", "Search response could not be read", 502, err, map[string]interface{}{"backend": BackendElasticsearch})
+	}
+	return nil
+}
+
+// accessFilter limits a search to the documents the context may read, as the repositories do:
+// reports of the tenant's companies and the user's scope, companies of the tenant and scope, and
+// users of the tenant.
+func accessFilter(ctx context.Context) interface{} {
+	tenant, scope := domain.TenantOf(ctx), domain.AccessScopeOf(ctx)
+
+	reports := []interface{}{term("kind", domain.SearchKindReport)}
+	companies := []interface{}{term("kind", domain.SearchKindCompany)}
+	users := []interface{}{term("kind", domain.SearchKindUser)}
+	if tenant != nil {
+		reports = append(reports, terms("company", hexes(tenant.Companies)))
+		var organization interface{} = term("organization", tenant.Organization.Hex())
+		if tenant.IsInstance() {
+			organization = map[string]interface{}{"bool": map[string]interface{}{
+				"must_not": map[string]interface{}{"exists": map[string]string{"field": "organization"}},
+			}}
+		}
+		companies = append(companies, organization)
+		users = append(users, organization)
+	}
+	if scope != nil {
+		user := scope.UserID.Hex()
+		reports = append(reports, anyOf(terms("company", hexes(scope.Companies)), term("createdBy", user), term("users", user)))
+		companies = append(companies, anyOf(terms("company", hexes(scope.Companies)), term("users", user)))
+	}
+
+	return anyOf(allOf(reports...), allOf(companies...), allOf(users...))
+}
+
+func toESDocument(document domain.SearchDocument) esDocument {
+	converted := esDocument{
+		Kind:      document.Kind,
+		ID:        document.ID.Hex(),
+		Title:     document.Title,
+		Detail:    document.Detail,
+		Companies: hexes(document.Companies),
+		Year:      document.Year,
+		Users:     hexes(document.Users),
+	}
+	if document.Organization != nil {
+		converted.Organization = document.Organization.Hex()
+	}
+	if document.Company != nil {
+		converted.Company = document.Company.Hex()
+	}
+	if document.ReportType != nil {
+		converted.ReportType = document.ReportType.Hex()
+	}
+	if document.CreatedBy != nil {
+		converted.CreatedBy = document.CreatedBy.Hex()
+	}
+	return converted
+}
+
+func documentID(kind string, id primitive.ObjectID) string {
+	return kind + ":" + id.Hex()
+}
+
+func term(field string, value interface{}) interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{field: value}}
+}
+
+func terms(field string, values []string) interface{} {
+	return map[string]interface{}{"terms": map[string]interface{}{field: values}}
+}
+
+func anyOf(clauses ...interface{}) interface{} {
+	return map[string]interface{}{"bool": map[string]interface{}{"should": clauses, "minimum_should_match": 1}}
+}
+
+func allOf(clauses ...interface{}) interface{} {
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": clauses}}
+}
+
+func facet(field string) interface{} {
+	return map[string]interface{}{"terms": map[string]interface{}{"field": field, "size": 20}}
+}
+
+func hexes(ids []primitive.ObjectID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.Hex()
+	}
+	return values
+}
+
+func objectID(hex string) *primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
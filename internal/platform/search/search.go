@@ -0,0 +1,40 @@
+package search
+
+import (
+	"strings"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const (
+	BackendAtlas         = "atlas"
+	BackendElasticsearch = "elasticsearch"
+
+	DefaultAtlasIndex         = "default"
+	DefaultElasticsearchIndex = "finsolvz"
+)
+
+// Config selects and configures the search backend.
+type Config struct {
+	Backend string // atlas or elasticsearch; MongoDB regular expressions when empty
+
+	AtlasIndex string // the Atlas Search index of reports, companies and users
+
+	ElasticsearchURL    string
+	ElasticsearchIndex  string
+	ElasticsearchAPIKey string // none when empty, e.g. for a local cluster
+}
+
+// New builds the Elasticsearch index when it is the configured backend. It returns nil for the
+// other backends, which search MongoDB itself and need no index kept in sync.
+func New(cfg Config) (domain.SearchIndex, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", BackendAtlas:
+		return nil, nil
+	case BackendElasticsearch:
+		return NewElasticsearch(cfg.ElasticsearchURL, cfg.ElasticsearchIndex, cfg.ElasticsearchAPIKey)
+	}
+
+	return nil, errors.New("SEARCH_CONFIG_INVALID", "Unknown SEARCH_BACKEND", 500, nil, map[string]interface{}{"backend": cfg.Backend})
+}
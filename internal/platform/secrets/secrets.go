@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Provider fetches secret values by name from an external secret manager.
+type Provider interface {
+	Name() string
+	// Get returns the latest version of the secret, or an error with code SECRET_NOT_FOUND
+	// when it does not exist so callers can fall back to the environment.
+	Get(ctx context.Context, name string) (string, error)
+}
+
+func notFound(name string) error {
+	return errors.New("SECRET_NOT_FOUND", "Secret not found", 404, nil, map[string]interface{}{"secret": name})
+}
+
+// IsNotFound reports whether err means the secret does not exist.
+func IsNotFound(err error) bool {
+	appErr, ok := err.(errors.AppError)
+	return ok && appErr.Code() == "SECRET_NOT_FOUND"
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewProviderFromEnv selects the provider named by SECRETS_PROVIDER (gcp or aws). It returns
+// nil when unset, in which case every setting comes from the environment. These bootstrap
+// settings are always read from the environment since they are needed to reach the manager.
+func NewProviderFromEnv() (Provider, error) {
+	prefix := os.Getenv("SECRETS_PREFIX")
+
+	switch strings.ToLower(os.Getenv("SECRETS_PROVIDER")) {
+	case "", "env":
+		return nil, nil
+	case "gcp":
+		return NewGCPProvider(os.Getenv("GCP_PROJECT_ID"), prefix)
+	case "aws":
+		return NewAWSProvider(os.Getenv("AWS_REGION"), os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), prefix)
+	}
+
+	return nil, errors.New("SECRETS_CONFIG_INVALID", "Unknown SECRETS_PROVIDER", 500, nil, map[string]interface{}{"provider": os.Getenv("SECRETS_PROVIDER")})
+}
+
+// GCP Secret Manager (REST API, authenticated with the instance service account)
+
+type gcpProvider struct {
+	project     string
+	prefix      string
+	baseURL     string
+	metadataURL string
+}
+
+// NewGCPProvider reads secrets named prefix+name from project. Access tokens come from
+// GCP_ACCESS_TOKEN when set (local development) and from the metadata server otherwise.
+func NewGCPProvider(project, prefix string) (Provider, error) {
+	if project == "" {
+		return nil, errors.New("SECRETS_CONFIG_MISSING", "GCP_PROJECT_ID is required for the gcp secrets provider", 500, nil, nil)
+	}
+	return &gcpProvider{
+		project:     project,
+		prefix:      prefix,
+		baseURL:     "https://secretmanager.googleapis.com",
+		metadataURL: "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token",
+	}, nil
+}
+
+func (p *gcpProvider) Name() string { return "gcp" }
+
+func (p *gcpProvider) Get(ctx context.Context, name string) (string, error) {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/latest:access",
+		p.baseURL, url.PathEscape(p.project), url.PathEscape(p.prefix+name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", errors.New("SECRETS_ERROR", "Failed to build secret request", 500, err, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	status, err := doJSON(req, &result)
+	if status == http.StatusNotFound {
+		return "", notFound(name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	value, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return "", errors.New("SECRETS_ERROR", "Failed to decode secret", 500, err, map[string]interface{}{"secret": name})
+	}
+	return string(value), nil
+}
+
+func (p *gcpProvider) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GCP_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metadataURL, nil)
+	if err != nil {
+		return "", errors.New("SECRETS_ERROR", "Failed to build token request", 500, err, nil)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if _, err := doJSON(req, &result); err != nil {
+		return "", err
+	}
+	return result.AccessToken, nil
+}
+
+// AWS Secrets Manager (JSON API, signed with AWS Signature Version 4)
+
+type awsProvider struct {
+	region    string
+	accessKey string
+	secretKey string
+	prefix    string
+	endpoint  string
+}
+
+// NewAWSProvider reads secrets named prefix+name. Only string secrets are supported.
+func NewAWSProvider(region, accessKey, secretKey, prefix string) (Provider, error) {
+	if region == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("SECRETS_CONFIG_MISSING", "AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the aws secrets provider", 500, nil, nil)
+	}
+	return &awsProvider{
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		prefix:    prefix,
+		endpoint:  fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region),
+	}, nil
+}
+
+func (p *awsProvider) Name() string { return "aws" }
+
+func (p *awsProvider) Get(ctx context.Context, name string) (string, error) {
+	payload, _ := json.Marshal(map[string]string{"SecretId": p.prefix + name})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, strings.NewReader(string(payload)))
+	if err != nil {
+		return "", errors.New("SECRETS_ERROR", "Failed to build secret request", 500, err, nil)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	utils.SignAWSRequest(req, payload, p.region, "secretsmanager", p.accessKey, p.secretKey, time.Now())
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	status, err := doJSON(req, &result)
+	if err != nil {
+		if status == http.StatusBadRequest && strings.Contains(err.Error(), "ResourceNotFoundException") {
+			return "", notFound(name)
+		}
+		return "", err
+	}
+	return result.SecretString, nil
+}
+
+// doJSON performs req and decodes a successful JSON response into out. The status code is
+// returned alongside errors so providers can recognise missing secrets.
+func doJSON(req *http.Request, out interface{}) (int, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, errors.New("SECRETS_ERROR", "Failed to reach secret manager", 500, err, nil)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, errors.New("SECRETS_ERROR", "Secret manager request failed", 500,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), nil)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, errors.New("SECRETS_ERROR", "Failed to decode secret manager response", 500, err, nil)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// DefaultLinkExpiry is how long download links stay valid when STORAGE_URL_TTL is unset.
+const DefaultLinkExpiry = 15 * time.Minute
+
+// Link is a signed download URL and the time it stops working.
+type Link struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Downloads hands out short-lived signed URLs for generated files such as backups and
+// exports, which are never reachable through a public path. Every link is recorded in the
+// audit trail as a file.download_issued event.
+type Downloads struct {
+	store      ObjectStore
+	outboxRepo domain.OutboxRepository
+	expiry     time.Duration
+}
+
+func NewDownloads(store ObjectStore, outboxRepo domain.OutboxRepository, expiry time.Duration) *Downloads {
+	if expiry <= 0 {
+		expiry = DefaultLinkExpiry
+	}
+	return &Downloads{
+		store:      store,
+		outboxRepo: outboxRepo,
+		expiry:     expiry,
+	}
+}
+
+type downloadIssuedEvent struct {
+	Key         string    `json:"key"`
+	RequestedBy string    `json:"requestedBy,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Link signs key for requestedBy, the zero ID for links issued without a user. The link is
+// only returned once its issue is recorded, so every download can be traced to a request.
+func (d *Downloads) Link(ctx context.Context, key string, requestedBy primitive.ObjectID) (*Link, error) {
+	url, err := d.store.SignedURL(ctx, key, d.expiry)
+	if err != nil {
+		return nil, err
+	}
+	link := &Link{URL: url, ExpiresAt: time.Now().Add(d.expiry).UTC()}
+
+	payload := downloadIssuedEvent{Key: key, ExpiresAt: link.ExpiresAt}
+	if !requestedBy.IsZero() {
+		payload.RequestedBy = requestedBy.Hex()
+	}
+	event, err := domain.NewEvent(domain.EventFileDownloadIssued, requestedBy, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.outboxRepo.Append(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
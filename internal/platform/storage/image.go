@@ -0,0 +1,298 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Longest side in pixels of the variants produced for logos and avatars.
+const (
+	FullSize  = 1024
+	ThumbSize = 128
+
+	// maxImagePixels rejects images that are small on disk but huge once decoded
+	maxImagePixels = 40_000_000
+)
+
+// ImagePathPrefix is where NewImageHandler serves stored images.
+const ImagePathPrefix = "/api/images/"
+
+// Image is a processed upload stored as a full-size and a thumbnail variant.
+type Image struct {
+	Full  Object `json:"full"`
+	Thumb Object `json:"thumb"`
+}
+
+// UploadImage validates an image against policy, re-encodes it without metadata (EXIF, GPS,
+// comments) after applying its EXIF orientation, and stores it under keyPrefix as
+// "-full" and "-thumb" variants. JPEGs stay JPEG; other formats become PNG to keep transparency.
+func UploadImage(ctx context.Context, store ObjectStore, keyPrefix string, r io.Reader, declaredType string, policy Policy) (*Image, error) {
+	if policy.MaxSize > 0 {
+		r = &limitedReader{r: r, remaining: policy.MaxSize, policy: policy}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		if appErr, ok := err.(errors.AppError); ok {
+			return nil, appErr
+		}
+		return nil, errors.New("UPLOAD_READ_ERROR", "Failed to read upload", 400, err, nil)
+	}
+
+	if _, err := policy.contentType(data[:min(len(data), 512)], declaredType); err != nil {
+		return nil, err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errInvalidImage(err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, errors.New("IMAGE_TOO_LARGE", "Image dimensions are too large", http.StatusRequestEntityTooLarge, nil,
+			map[string]interface{}{"width": cfg.Width, "height": cfg.Height, "maxPixels": maxImagePixels})
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errInvalidImage(err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	full, err := storeVariant(ctx, store, keyPrefix+"-full", orient(fit(src, FullSize), orientation), format)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := storeVariant(ctx, store, keyPrefix+"-thumb", orient(fit(src, ThumbSize), orientation), format)
+	if err != nil {
+		store.Delete(ctx, full.Key)
+		return nil, err
+	}
+
+	return &Image{Full: *full, Thumb: *thumb}, nil
+}
+
+func errInvalidImage(err error) error {
+	return errors.New("INVALID_IMAGE", "File is not a valid image", http.StatusUnsupportedMediaType, err, nil)
+}
+
+func storeVariant(ctx context.Context, store ObjectStore, key string, img image.Image, format string) (*Object, error) {
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if format == "jpeg" {
+		contentType = "image/jpeg"
+		key += ".jpg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, errors.New("IMAGE_ENCODING_ERROR", "Failed to encode image", 500, err, nil)
+		}
+	} else {
+		key += ".png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, errors.New("IMAGE_ENCODING_ERROR", "Failed to encode image", 500, err, nil)
+		}
+	}
+
+	size, err := store.Put(ctx, key, &buf, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Key: key, ContentType: contentType, Size: size}, nil
+}
+
+// fit scales img down so its longest side is at most size, averaging the source pixels
+// covered by each destination pixel. Smaller images are copied unscaled.
+func fit(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= size && sh <= size {
+		return src
+	}
+
+	dw, dh := size, max(1, sh*size/sw)
+	if sh > sw {
+		dw, dh = max(1, sw*size/sh), size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			p := dst.Pix[y*dst.Stride+x*4:]
+			for c := 0; c < 4; c++ {
+				p[c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// orient rotates and flips img so it displays upright for the given EXIF orientation (1-8).
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	// at maps a destination pixel to its source pixel
+	var at func(x, y int) (int, int)
+	dw, dh := w, h
+	switch orientation {
+	case 2:
+		at = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3:
+		at = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4:
+		at = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5:
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return y, x }
+	case 6:
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return y, h - 1 - x }
+	case 7:
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case 8:
+		dw, dh = h, w
+		at = func(x, y int) (int, int) { return w - 1 - y, x }
+	default:
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := at(x, y)
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], img.Pix[sy*img.Stride+sx*4:sy*img.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, returning 1 (upright) when absent.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			break // image data starts; EXIF always precedes it
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// ImagePath is the public path of a stored image variant.
+func ImagePath(key string) string {
+	return ImagePathPrefix + strings.TrimPrefix(key, "images/")
+}
+
+// ImageKey reverses ImagePath, reporting false for paths that do not point at a stored image.
+func ImageKey(p string) (string, bool) {
+	if !strings.HasPrefix(p, ImagePathPrefix) {
+		return "", false
+	}
+	key := "images/" + strings.TrimPrefix(p, ImagePathPrefix)
+	return key, validKey(key) == nil
+}
+
+// NewImageHandler serves processed images below ImagePathPrefix without authentication,
+// since logos and avatars are shown on public pages and in emails. Keys are unique per
+// upload, so responses are cached indefinitely.
+func NewImageHandler(store ObjectStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := ImageKey(r.URL.Path)
+		ext := path.Ext(key)
+		if !ok || (ext != ".jpg" && ext != ".png") {
+			http.NotFound(w, r)
+			return
+		}
+
+		obj, err := store.Get(r.Context(), key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer obj.Close()
+
+		contentType := "image/png"
+		if ext == ".jpg" {
+			contentType = "image/jpeg"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		io.Copy(w, obj)
+	})
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// localStore keeps objects on the local filesystem under a root directory.
+type localStore struct {
+	root    string
+	baseURL string
+	secret  string
+}
+
+// NewLocalStore stores objects under root. Signed URLs are only available when both
+// baseURL and secret are set, and are served by NewSignedURLHandler.
+func NewLocalStore(root, baseURL, secret string) ObjectStore {
+	return &localStore{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+	}
+}
+
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, errors.New("STORAGE_ERROR", "Failed to create storage directory", 500, err, nil)
+	}
+
+	// Write to a temp file first so readers never see a partial object
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return 0, errors.New("STORAGE_ERROR", "Failed to create object", 500, err, nil)
+	}
+
+	n, err := io.Copy(f, r)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		if appErr, ok := err.(errors.AppError); ok {
+			return 0, appErr
+		}
+		return 0, errors.New("STORAGE_ERROR", "Failed to write object", 500, err, nil)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, errors.New("STORAGE_ERROR", "Failed to store object", 500, err, nil)
+	}
+
+	return n, nil
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("OBJECT_NOT_FOUND", "Object not found", 404, err, nil)
+		}
+		return nil, errors.New("STORAGE_ERROR", "Failed to open object", 500, err, nil)
+	}
+	return f, nil
+}
+
+func (s *localStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.New("STORAGE_ERROR", "Failed to delete object", 500, err, nil)
+	}
+	return nil
+}
+
+func (s *localStore) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	if s.baseURL == "" || s.secret == "" {
+		return "", errors.New("SIGNED_URLS_UNAVAILABLE", "Signed URLs require STORAGE_PUBLIC_URL", 500, nil, nil)
+	}
+
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expiresAt)
+	query.Set("signature", signLocal(s.secret, key, expiresAt))
+
+	return s.baseURL + "/files/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// path resolves key inside root, rejecting keys that escape it.
+func (s *localStore) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.Clean("/"+key)), nil
+}
+
+func signLocal(secret, key, expiresAt string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(key + "\n" + expiresAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// NewSignedURLHandler serves local-store objects at /files/{key} when the request carries
+// a valid, unexpired signature from SignedURL.
+func NewSignedURLHandler(store ObjectStore, secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/files/")
+		expiresAt := r.URL.Query().Get("expires")
+
+		expiry, err := strconv.ParseInt(expiresAt, 10, 64)
+		valid := err == nil && time.Now().Unix() <= expiry &&
+			hmac.Equal([]byte(r.URL.Query().Get("signature")), []byte(signLocal(secret, key, expiresAt)))
+		if !valid {
+			http.Error(w, "invalid or expired link", http.StatusForbidden)
+			return
+		}
+
+		obj, err := store.Get(r.Context(), key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer obj.Close()
+
+		// Issuing the link was recorded with the user who asked for it; this records its use
+		log.Infof(r.Context(), "Storage: %s downloaded through a signed link", key)
+
+		contentType := mime.TypeByExtension(path.Ext(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(max(expiry-time.Now().Unix(), 0), 10))
+		io.Copy(w, obj)
+	})
+}
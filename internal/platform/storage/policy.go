@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Policy limits what may be uploaded for a feature. ContentTypes maps each accepted type to
+// the type http.DetectContentType must report for its content; formats without a signature
+// of their own map to their container (CSV is text/plain, XLSX is a zip archive).
+type Policy struct {
+	Name         string
+	MaxSize      int64 // bytes; 0 means unlimited
+	ContentTypes map[string]string
+}
+
+// imageTypes are the formats UploadImage can decode with the standard library.
+var imageTypes = map[string]string{
+	"image/jpeg": "image/jpeg",
+	"image/png":  "image/png",
+	"image/gif":  "image/gif",
+}
+
+var (
+	AvatarPolicy = Policy{Name: "avatar", MaxSize: 2 << 20, ContentTypes: imageTypes}
+	LogoPolicy   = Policy{Name: "logo", MaxSize: 2 << 20, ContentTypes: imageTypes}
+
+	AttachmentPolicy = Policy{Name: "attachment", MaxSize: 20 << 20, ContentTypes: map[string]string{
+		"application/pdf": "application/pdf",
+		"image/jpeg":      "image/jpeg",
+		"image/png":       "image/png",
+		"text/csv":        "text/plain",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": "application/zip",
+	}}
+
+	// ExportPolicy covers files the server generates itself, so any type and size is accepted.
+	ExportPolicy = Policy{Name: "export"}
+)
+
+// Object describes a stored upload.
+type Object struct {
+	Key         string `json:"key"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// Upload stores r under key after checking it against policy. The content type is detected
+// from the data; declaredType (e.g. the multipart Content-Type) only picks between types that
+// share a signature, so a client cannot label arbitrary content as an image.
+func Upload(ctx context.Context, store ObjectStore, key string, r io.Reader, declaredType string, policy Policy) (*Object, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, errors.New("UPLOAD_READ_ERROR", "Failed to read upload", 400, err, nil)
+	}
+	head = head[:n]
+
+	contentType, err := policy.contentType(head, declaredType)
+	if err != nil {
+		return nil, err
+	}
+
+	body := io.MultiReader(bytes.NewReader(head), r)
+	if policy.MaxSize > 0 {
+		body = &limitedReader{r: body, remaining: policy.MaxSize, policy: policy}
+	}
+
+	size, err := store.Put(ctx, key, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{Key: key, ContentType: contentType, Size: size}, nil
+}
+
+func (p Policy) contentType(head []byte, declaredType string) (string, error) {
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if len(p.ContentTypes) == 0 {
+		return detected, nil
+	}
+
+	if declared, _, err := mime.ParseMediaType(declaredType); err == nil && p.ContentTypes[declared] == detected {
+		return declared, nil
+	}
+	if p.ContentTypes[detected] == detected {
+		return detected, nil
+	}
+
+	allowed := make([]string, 0, len(p.ContentTypes))
+	for t := range p.ContentTypes {
+		allowed = append(allowed, t)
+	}
+	sort.Strings(allowed)
+
+	return "", errors.New("UNSUPPORTED_MEDIA_TYPE", "File type is not allowed", http.StatusUnsupportedMediaType, nil,
+		map[string]interface{}{"detected": detected, "allowed": allowed, "policy": p.Name})
+}
+
+// limitedReader fails the upload once more than the policy's MaxSize bytes have been read.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	policy    Policy
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, errors.New("FILE_TOO_LARGE", "File exceeds the maximum size", http.StatusRequestEntityTooLarge, nil,
+			map[string]interface{}{"maxSize": l.policy.MaxSize, "policy": l.policy.Name})
+	}
+	return n, err
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// No overall timeout since backups can take a while to stream; callers bound requests with ctx.
+var storageHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+// s3Store talks to S3 or an S3-compatible API using path-style URLs signed with SigV4.
+type s3Store struct {
+	name      string
+	bucket    string
+	region    string
+	endpoint  string
+	accessKey string
+	secretKey string
+}
+
+// NewS3Store stores objects in an S3 bucket. endpoint overrides the regional AWS endpoint
+// for S3-compatible stores such as MinIO.
+func NewS3Store(bucket, region, endpoint, accessKey, secretKey string) (ObjectStore, error) {
+	if bucket == "" || region == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("STORAGE_CONFIG_MISSING", "Storage configuration not found", 500, nil, map[string]interface{}{"driver": DriverS3})
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	return &s3Store{
+		name:      DriverS3,
+		bucket:    bucket,
+		region:    region,
+		endpoint:  strings.TrimRight(endpoint, "/"),
+		accessKey: accessKey,
+		secretKey: secretKey,
+	}, nil
+}
+
+// NewGCSStore stores objects in a Google Cloud Storage bucket through its S3-compatible
+// XML API, authenticated with an HMAC key of a service account.
+func NewGCSStore(bucket, accessKey, secretKey string) (ObjectStore, error) {
+	if bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("STORAGE_CONFIG_MISSING", "Storage configuration not found", 500, nil, map[string]interface{}{"driver": DriverGCS})
+	}
+	return &s3Store{
+		name:      DriverGCS,
+		bucket:    bucket,
+		region:    "auto",
+		endpoint:  "https://storage.googleapis.com",
+		accessKey: accessKey,
+		secretKey: secretKey,
+	}, nil
+}
+
+func (s *s3Store) objectURL(key string) string {
+	return s.endpoint + "/" + s.bucket + "/" + escapeKey(key)
+}
+
+// Put spools r to a temporary file first since the API needs the length up front.
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error) {
+	if err := validKey(key); err != nil {
+		return 0, err
+	}
+
+	spool, err := os.CreateTemp("", "finsolvz-upload-*")
+	if err != nil {
+		return 0, errors.New("STORAGE_ERROR", "Failed to buffer object", 500, err, nil)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	n, err := io.Copy(spool, r)
+	if err != nil {
+		if appErr, ok := err.(errors.AppError); ok {
+			return 0, appErr
+		}
+		return 0, errors.New("STORAGE_ERROR", "Failed to buffer object", 500, err, nil)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return 0, errors.New("STORAGE_ERROR", "Failed to buffer object", 500, err, nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), spool)
+	if err != nil {
+		return 0, errors.New("STORAGE_ERROR", "Failed to build upload request", 500, err, nil)
+	}
+	req.ContentLength = n
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return n, nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, errors.New("STORAGE_ERROR", "Failed to build download request", 500, err, nil)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return errors.New("STORAGE_ERROR", "Failed to build delete request", 500, err, nil)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		if appErr, ok := err.(errors.AppError); ok && appErr.Status() == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Store) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+
+	signed, err := utils.PresignAWSURL(http.MethodGet, s.objectURL(key), s.region, "s3", s.accessKey, s.secretKey, expires, time.Now())
+	if err != nil {
+		return "", errors.New("STORAGE_ERROR", "Failed to sign URL", 500, err, nil)
+	}
+	return signed, nil
+}
+
+// do signs and sends req, turning non-2xx responses into errors. The caller closes the body.
+func (s *s3Store) do(req *http.Request) (*http.Response, error) {
+	utils.SignAWSRequestUnsignedPayload(req, s.region, "s3", s.accessKey, s.secretKey, time.Now())
+
+	resp, err := storageHTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.New("STORAGE_ERROR", "Failed to reach object store", 500, err, map[string]interface{}{"driver": s.name})
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errors.New("OBJECT_NOT_FOUND", "Object not found", 404, nil, nil)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, errors.New("STORAGE_ERROR", "Object store request failed", 500,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), map[string]interface{}{"driver": s.name})
+	}
+
+	return resp, nil
+}
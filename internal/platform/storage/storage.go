@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// ObjectStore stores opaque blobs by key.
+type ObjectStore interface {
+	// Put stores r under key, replacing any existing object, and returns the bytes written.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads key without credentials until it expires.
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+	DriverGCS   = "gcs"
+)
+
+// Config selects and configures the object store backend.
+type Config struct {
+	Driver string // local (default), s3 or gcs
+
+	// Local disk. Signed URLs point at BaseURL + "/files/..." and are verified with SigningSecret.
+	Dir           string
+	BaseURL       string
+	SigningSecret string
+
+	// LinkExpiry is how long download links handed out by Downloads stay valid
+	LinkExpiry time.Duration
+
+	// S3 and GCS. GCS is used through its S3-compatible API with HMAC keys.
+	Bucket          string
+	Region          string
+	Endpoint        string // optional, for S3-compatible stores
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// New builds the configured object store.
+func New(cfg Config) (ObjectStore, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DriverLocal:
+		return NewLocalStore(cfg.Dir, cfg.BaseURL, cfg.SigningSecret), nil
+	case DriverS3:
+		return NewS3Store(cfg.Bucket, cfg.Region, cfg.Endpoint, cfg.AccessKeyID, cfg.SecretAccessKey)
+	case DriverGCS:
+		return NewGCSStore(cfg.Bucket, cfg.AccessKeyID, cfg.SecretAccessKey)
+	}
+
+	return nil, errors.New("STORAGE_CONFIG_INVALID", "Unknown STORAGE_DRIVER", 500, nil, map[string]interface{}{"driver": cfg.Driver})
+}
+
+// Probe writes, reads back and deletes a small object to confirm the store is reachable
+// with the configured credentials.
+func Probe(ctx context.Context, store ObjectStore) error {
+	key := fmt.Sprintf("diagnostics/probe-%d", time.Now().UnixNano())
+	payload := []byte("finsolvz storage probe")
+
+	if _, err := store.Put(ctx, key, bytes.NewReader(payload), "text/plain"); err != nil {
+		return err
+	}
+	defer store.Delete(context.WithoutCancel(ctx), key)
+
+	obj, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	read, err := io.ReadAll(obj)
+	if err != nil {
+		return errors.New("STORAGE_ERROR", "Failed to read probe object", 500, err, nil)
+	}
+	if !bytes.Equal(read, payload) {
+		return errors.New("STORAGE_ERROR", "Probe object was read back corrupted", 500, nil, nil)
+	}
+	return nil
+}
+
+// validKey rejects empty keys and keys that could escape a directory or bucket prefix.
+func validKey(key string) error {
+	if strings.Trim(key, "/") == "" || strings.Contains(key, "..") || strings.HasPrefix(key, "/") {
+		return errors.New("INVALID_OBJECT_KEY", "Invalid object key", 400, nil, nil)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const (
+	maxAttempts  = 3
+	lease        = 5 * time.Minute
+	retryBackoff = 30 * time.Second
+)
+
+// Handler runs one task. report records progress in percent; the returned value is stored
+// as the task's JSON result. Handlers must tolerate being run again after a crash.
+type Handler func(ctx context.Context, task *domain.Task, report func(percent int)) (interface{}, error)
+
+// Enqueuer schedules tasks. Services depend on this rather than on the Queue.
+type Enqueuer interface {
+	// Enqueue stores a PENDING task with payload marshalled to JSON and returns it immediately.
+	Enqueue(ctx context.Context, taskType domain.TaskType, payload interface{}, createdBy primitive.ObjectID) (*domain.Task, error)
+}
+
+// Queue runs persisted tasks on a pool of workers polling the task repository.
+type Queue struct {
+	repo        domain.TaskRepository
+	interval    time.Duration
+	concurrency int
+
+	mu       sync.RWMutex
+	handlers map[domain.TaskType]Handler
+}
+
+func NewQueue(repo domain.TaskRepository, interval time.Duration, concurrency int) *Queue {
+	return &Queue{
+		repo:        repo,
+		interval:    interval,
+		concurrency: concurrency,
+		handlers:    make(map[domain.TaskType]Handler),
+	}
+}
+
+// Register sets the handler for a task type. Call before Run.
+func (q *Queue) Register(taskType domain.TaskType, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[taskType] = handler
+}
+
+func (q *Queue) Enqueue(ctx context.Context, taskType domain.TaskType, payload interface{}, createdBy primitive.ObjectID) (*domain.Task, error) {
+	q.mu.RLock()
+	_, ok := q.handlers[taskType]
+	q.mu.RUnlock()
+	if !ok {
+		return nil, errors.New("UNKNOWN_TASK_TYPE", "No handler registered for task type", 500, nil, map[string]interface{}{"type": taskType})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.New("TASK_ENCODING_ERROR", "Failed to encode task payload", 500, err, nil)
+	}
+
+	task := &domain.Task{
+		Type:      taskType,
+		Payload:   body,
+		CreatedBy: createdBy,
+	}
+	if err := q.repo.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every running task has returned.
+// Cancelling ctx stops claiming tasks; running ones keep going until drain is cancelled too,
+// which interrupts them and puts them back to PENDING without counting an attempt.
+func (q *Queue) Run(ctx, drain context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, drain)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx, drain context.Context) {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain due tasks before waiting for the next tick
+		for ctx.Err() == nil && q.runNext(ctx, drain) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one task, reporting whether there was one.
+func (q *Queue) runNext(ctx, drain context.Context) bool {
+	q.mu.RLock()
+	types := make([]domain.TaskType, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	q.mu.RUnlock()
+
+	task, err := q.repo.Claim(ctx, types, time.Now().Add(lease))
+	if err != nil {
+		log.Errorf(ctx, "Tasks: failed to claim task: %v", err)
+		return false
+	}
+	if task == nil {
+		return false
+	}
+
+	q.execute(drain, task)
+	return true
+}
+
+func (q *Queue) execute(ctx context.Context, task *domain.Task) {
+	q.mu.RLock()
+	handler := q.handlers[task.Type]
+	q.mu.RUnlock()
+
+	// Keep the lease alive while the handler runs, so slow tasks are not picked up twice
+	var progressMu sync.Mutex
+	progress := task.Progress
+	heartbeat := func() {
+		progressMu.Lock()
+		percent := progress
+		progressMu.Unlock()
+		if err := q.repo.UpdateProgress(ctx, task.ID, percent, time.Now().Add(lease)); err != nil {
+			log.Warnf(ctx, "Tasks: failed to update task %s: %v", task.ID.Hex(), err)
+		}
+	}
+	report := func(percent int) {
+		if percent < 0 {
+			percent = 0
+		} else if percent > 99 {
+			percent = 99 // 100 is reserved for completion
+		}
+		progressMu.Lock()
+		changed := percent != progress
+		progress = percent
+		progressMu.Unlock()
+		if changed {
+			heartbeat()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lease / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				heartbeat()
+			}
+		}
+	}()
+
+	result, err := runHandler(ctx, handler, task, report)
+	close(done)
+
+	// Record the outcome even when shutting down, so the task is not re-run needlessly
+	recordCtx := context.WithoutCancel(ctx)
+
+	if err != nil && ctx.Err() != nil {
+		log.Warnf(ctx, "Tasks: %s task %s interrupted by shutdown, releasing it", task.Type, task.ID.Hex())
+		if releaseErr := q.repo.Release(recordCtx, task.ID); releaseErr != nil {
+			log.Errorf(ctx, "Tasks: failed to release task %s: %v", task.ID.Hex(), releaseErr)
+		}
+		return
+	}
+
+	if err == nil {
+		var body []byte
+		if body, err = json.Marshal(result); err == nil {
+			if err := q.repo.Complete(recordCtx, task.ID, body); err != nil {
+				log.Errorf(ctx, "Tasks: failed to complete task %s: %v", task.ID.Hex(), err)
+			}
+			return
+		}
+	}
+
+	attempts := task.Attempts + 1
+	final := attempts >= maxAttempts
+	if final {
+		log.Errorf(ctx, "Tasks: %s task %s failed after %d attempts: %v", task.Type, task.ID.Hex(), attempts, err)
+	} else {
+		log.Warnf(ctx, "Tasks: %s task %s failed (attempt %d): %v", task.Type, task.ID.Hex(), attempts, err)
+	}
+
+	if markErr := q.repo.Fail(recordCtx, task.ID, errorMessage(err), time.Now().Add(time.Duration(attempts)*retryBackoff), final); markErr != nil {
+		log.Errorf(ctx, "Tasks: failed to record failure for task %s: %v", task.ID.Hex(), markErr)
+	}
+}
+
+// runHandler turns a handler panic into a task failure instead of crashing the worker.
+func runHandler(ctx context.Context, handler Handler, task *domain.Task, report func(int)) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, task, report)
+}
+
+// errorMessage is what task owners see, so AppErrors expose only their public message.
+func errorMessage(err error) string {
+	if appErr, ok := err.(errors.AppError); ok {
+		return appErr.Message()
+	}
+	return err.Error()
+}
@@ -0,0 +1,239 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const (
+	bigQueryBaseURL  = "https://bigquery.googleapis.com/bigquery/v2"
+	bigQueryScope    = "https://www.googleapis.com/auth/bigquery"
+	googleTokenURL   = "https://oauth2.googleapis.com/token"
+	bigQueryMaxBatch = 500 // rows per insertAll request, as BigQuery recommends
+)
+
+var warehouseHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+type bigQuery struct {
+	project  string
+	dataset  string
+	email    string
+	key      *rsa.PrivateKey
+	tokenURL string
+
+	mu      sync.Mutex
+	token   string
+	expiry  time.Time
+	created map[string]bool
+}
+
+// NewBigQuery streams rows into tables of a BigQuery dataset, creating missing tables with
+// their schema. It authenticates as a service account with its JSON key; the project defaults
+// to the key's.
+func NewBigQuery(project, dataset, credentials string) (Sink, error) {
+	missing := errors.New("WAREHOUSE_CONFIG_MISSING", "Warehouse configuration not found", 500, nil, map[string]interface{}{"sink": SinkBigQuery})
+	if dataset == "" || credentials == "" {
+		return nil, missing
+	}
+
+	var account struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal([]byte(credentials), &account); err != nil || account.ClientEmail == "" {
+		return nil, errors.New("WAREHOUSE_CONFIG_INVALID", "BIGQUERY_CREDENTIALS is not a service account key", 500, err, nil)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, errors.New("WAREHOUSE_CONFIG_INVALID", "BIGQUERY_CREDENTIALS is not a service account key", 500, err, nil)
+	}
+	if project == "" {
+		project = account.ProjectID
+	}
+	if project == "" {
+		return nil, missing
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = googleTokenURL
+	}
+
+	return &bigQuery{
+		project:  project,
+		dataset:  dataset,
+		email:    account.ClientEmail,
+		key:      key,
+		tokenURL: account.TokenURI,
+		created:  map[string]bool{},
+	}, nil
+}
+
+func (b *bigQuery) Name() string { return SinkBigQuery }
+
+func (b *bigQuery) Write(ctx context.Context, table Table, rows []Row) error {
+	if err := b.ensureTable(ctx, table); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(rows); start += bigQueryMaxBatch {
+		end := min(start+bigQueryMaxBatch, len(rows))
+		batch := make([]map[string]interface{}, 0, end-start)
+		for _, row := range rows[start:end] {
+			batch = append(batch, map[string]interface{}{"json": row})
+		}
+		request := map[string]interface{}{"rows": batch}
+
+		var response struct {
+			InsertErrors []struct {
+				Index  int               `json:"index"`
+				Errors []json.RawMessage `json:"errors"`
+			} `json:"insertErrors"`
+		}
+		path := fmt.Sprintf("/projects/%s/datasets/%s/tables/%s/insertAll", b.project, b.dataset, table.Name)
+		if _, err := b.do(ctx, http.MethodPost, path, request, &response); err != nil {
+			return err
+		}
+		if len(response.InsertErrors) > 0 {
+			first := response.InsertErrors[0]
+			return errors.New("WAREHOUSE_ERROR", "BigQuery rejected warehouse rows", 502,
+				fmt.Errorf("row %d: %s", start+first.Index, first.Errors), map[string]interface{}{"table": table.Name, "rejected": len(response.InsertErrors)})
+		}
+	}
+	return nil
+}
+
+// ensureTable creates the table unless it exists. Columns added to a table later have to be
+// added to BigQuery by hand.
+func (b *bigQuery) ensureTable(ctx context.Context, table Table) error {
+	b.mu.Lock()
+	created := b.created[table.Name]
+	b.mu.Unlock()
+	if created {
+		return nil
+	}
+
+	fields := make([]map[string]string, len(table.Columns))
+	for i, column := range table.Columns {
+		fields[i] = map[string]string{"name": column.Name, "type": column.Type, "mode": "NULLABLE"}
+	}
+	request := map[string]interface{}{
+		"tableReference": map[string]string{"projectId": b.project, "datasetId": b.dataset, "tableId": table.Name},
+		"schema":         map[string]interface{}{"fields": fields},
+	}
+	status, err := b.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/datasets/%s/tables", b.project, b.dataset), request, nil)
+	if err != nil && status != http.StatusConflict {
+		return err
+	}
+
+	b.mu.Lock()
+	b.created[table.Name] = true
+	b.mu.Unlock()
+	return nil
+}
+
+// do calls the BigQuery API and decodes the response into out, if given. The status is
+// returned with errors so callers can accept some.
+func (b *bigQuery) do(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	token, err := b.accessToken(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, errors.New("WAREHOUSE_ERROR", "Failed to encode BigQuery request", 500, err, nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, bigQueryBaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, errors.New("WAREHOUSE_ERROR", "Failed to build BigQuery request", 500, err, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := warehouseHTTPClient.Do(req)
+	if err != nil {
+		return 0, errors.New("WAREHOUSE_ERROR", "BigQuery is unavailable", 502, err, nil)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, errors.New("WAREHOUSE_ERROR", "BigQuery request failed", 502,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(reason))), nil)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, errors.New("WAREHOUSE_ERROR", "BigQuery response could not be read", 502, err, nil)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// accessToken returns an OAuth access token of the service account, exchanging a signed
+// assertion for a new one shortly before the last expires.
+func (b *bigQuery) accessToken(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token != "" && time.Until(b.expiry) > time.Minute {
+		return b.token, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   b.email,
+		"scope": bigQueryScope,
+		"aud":   b.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(b.key)
+	if err != nil {
+		return "", errors.New("WAREHOUSE_ERROR", "Failed to sign BigQuery credentials", 500, err, nil)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.New("WAREHOUSE_ERROR", "Failed to build token request", 500, err, nil)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := warehouseHTTPClient.Do(req)
+	if err != nil {
+		return "", errors.New("WAREHOUSE_ERROR", "Google authentication is unavailable", 502, err, nil)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", errors.New("WAREHOUSE_ERROR", "Google rejected the BigQuery credentials", 502,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(reason))), nil)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", errors.New("WAREHOUSE_ERROR", "Google token response could not be read", 502, err, nil)
+	}
+
+	b.token = token.AccessToken
+	b.expiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return b.token, nil
+}
@@ -0,0 +1,50 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type jsonl struct {
+	store  storage.ObjectStore
+	prefix string
+}
+
+// NewJSONL writes each batch of rows as a newline-delimited JSON file under
+// <prefix><table>/dt=<date>/, a layout BigQuery, Athena and Snowflake load or query as
+// external tables partitioned by date.
+func NewJSONL(store storage.ObjectStore, prefix string) Sink {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &jsonl{store: store, prefix: prefix}
+}
+
+func (s *jsonl) Name() string { return SinkJSONL }
+
+func (s *jsonl) Write(ctx context.Context, table Table, rows []Row) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return errors.New("WAREHOUSE_ERROR", "Failed to encode warehouse rows", 500, err, map[string]interface{}{"table": table.Name})
+		}
+	}
+
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%s%s/dt=%s/%s.jsonl", s.prefix, table.Name, now.Format("2006-01-02"), now.Format("20060102T150405.000000000Z"))
+	if _, err := s.store.Put(ctx, key, &body, "application/x-ndjson"); err != nil {
+		return err
+	}
+	return nil
+}
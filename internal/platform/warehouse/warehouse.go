@@ -0,0 +1,75 @@
+package warehouse
+
+import (
+	"context"
+	"strings"
+
+	"finsolvz-backend/internal/platform/storage"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Column types, as BigQuery names them.
+const (
+	TypeString    = "STRING"
+	TypeInteger   = "INTEGER"
+	TypeFloat     = "FLOAT"
+	TypeBoolean   = "BOOLEAN"
+	TypeTimestamp = "TIMESTAMP"
+)
+
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is a flat warehouse table. Tables are append-only: every export of a document adds a
+// row, and the latest row of an ID is its current state.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// Row holds the values of a table row by column name. Timestamps are RFC 3339 strings.
+type Row map[string]interface{}
+
+// Sink loads rows into warehouse tables.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, table Table, rows []Row) error
+}
+
+const (
+	SinkJSONL    = "jsonl"
+	SinkBigQuery = "bigquery"
+
+	DefaultPrefix = "warehouse/"
+)
+
+// Config selects and configures the warehouse sink.
+type Config struct {
+	Sink string // jsonl or bigquery; nothing is exported when empty
+
+	// Prefix is where jsonl files go in the object store
+	Prefix string
+
+	BigQueryProject string
+	BigQueryDataset string
+	// BigQueryCredentials is the JSON key of a service account allowed to create tables in
+	// and insert into the dataset
+	BigQueryCredentials string
+}
+
+// New builds the configured sink, or returns nil when none is configured. The jsonl sink
+// writes to store.
+func New(cfg Config, store storage.ObjectStore) (Sink, error) {
+	switch strings.ToLower(cfg.Sink) {
+	case "":
+		return nil, nil
+	case SinkJSONL:
+		return NewJSONL(store, cfg.Prefix), nil
+	case SinkBigQuery:
+		return NewBigQuery(cfg.BigQueryProject, cfg.BigQueryDataset, cfg.BigQueryCredentials)
+	}
+
+	return nil, errors.New("WAREHOUSE_CONFIG_INVALID", "Unknown WAREHOUSE_SINK", 500, nil, map[string]interface{}{"sink": cfg.Sink})
+}
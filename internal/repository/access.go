@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// reportReadFilter adds the soft-delete condition, the tenant and the access scope of ctx to a
+// report read filter.
+func reportReadFilter(ctx context.Context, filter bson.M) bson.M {
+	filter = reportTenantFilter(ctx, scopeFilter(ctx, filter))
+	scope := domain.AccessScopeOf(ctx)
+	if scope == nil {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, {"$or": []bson.M{
+		{"company": bson.M{"$in": scope.Companies}},
+		{"createdBy": scope.UserID},
+		{"userAccess": scope.UserID},
+	}}}}
+}
+
+// companyReadFilter adds the soft-delete condition, the tenant and the access scope of ctx to
+// a company read filter.
+func companyReadFilter(ctx context.Context, filter bson.M) bson.M {
+	filter = tenantFilter(ctx, scopeFilter(ctx, filter))
+	scope := domain.AccessScopeOf(ctx)
+	if scope == nil {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, {"$or": []bson.M{
+		{"_id": bson.M{"$in": scope.Companies}},
+		{"user": scope.UserID},
+	}}}}
+}
+
+// userReadFilter adds the soft-delete condition and the tenant of ctx to a user read filter.
+func userReadFilter(ctx context.Context, filter bson.M) bson.M {
+	return tenantFilter(ctx, scopeFilter(ctx, filter))
+}
+
+// tenantFilter limits a filter on users or companies, which are stamped with their
+// organization, to the tenant of ctx. It serves writes as well as reads.
+func tenantFilter(ctx context.Context, filter bson.M) bson.M {
+	tenant := domain.TenantOf(ctx)
+	if tenant == nil {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, organizationFilter(tenant.Organization)}}
+}
+
+// organizationFilter matches the users or companies of an organization, or of the instance
+// for primitive.NilObjectID. A null condition also matches documents without the field.
+func organizationFilter(organization primitive.ObjectID) bson.M {
+	if organization.IsZero() {
+		return bson.M{"organization": nil}
+	}
+	return bson.M{"organization": organization}
+}
+
+// reportTenantFilter limits a report filter to the companies of the tenant of ctx. Reports
+// follow their company between organizations, so they aren't stamped themselves.
+func reportTenantFilter(ctx context.Context, filter bson.M) bson.M {
+	tenant := domain.TenantOf(ctx)
+	if tenant == nil {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, {"company": bson.M{"$in": tenant.Companies}}}}
+}
+
+// companyDataFilter limits a filter on documents belonging to a company, such as report
+// deadlines, to the companies of the tenant and access scope of ctx. It serves writes as well
+// as reads.
+func companyDataFilter(ctx context.Context, filter bson.M) bson.M {
+	conditions := []bson.M{filter}
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		conditions = append(conditions, bson.M{"company": bson.M{"$in": tenant.Companies}})
+	}
+	if scope := domain.AccessScopeOf(ctx); scope != nil {
+		conditions = append(conditions, bson.M{"company": bson.M{"$in": scope.Companies}})
+	}
+	if len(conditions) == 1 {
+		return filter
+	}
+	return bson.M{"$and": conditions}
+}
+
+// readPipeline prepends a $match stage with a read filter to an aggregation pipeline.
+func readPipeline(filter bson.M, pipeline []bson.M) []bson.M {
+	if len(filter) == 0 {
+		return pipeline
+	}
+	return append([]bson.M{{"$match": filter}}, pipeline...)
+}
+
+// pgReportAccess returns the tenant and access scope of ctx as a condition on reports aliased
+// r. The IDs are ObjectID hex strings, so they are inlined rather than bound.
+func pgReportAccess(ctx context.Context) string {
+	access := "TRUE"
+	if scope := domain.AccessScopeOf(ctx); scope != nil {
+		userID := "'" + scope.UserID.Hex() + "'"
+		access = "(r.company = ANY(" + pgIDArray(scope.Companies) + ") OR r.created_by = " + userID +
+			" OR r.user_access @> jsonb_build_array(" + userID + "::text))"
+	}
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		access += " AND r.company = ANY(" + pgIDArray(tenant.Companies) + ")"
+	}
+	return access
+}
+
+// pgCompanyAccess returns the tenant and access scope of ctx as a condition on the companies table.
+func pgCompanyAccess(ctx context.Context) string {
+	access := "TRUE"
+	if scope := domain.AccessScopeOf(ctx); scope != nil {
+		access = "(id = ANY(" + pgIDArray(scope.Companies) + ") OR users @> jsonb_build_array('" + scope.UserID.Hex() + "'::text))"
+	}
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		access += " AND id = ANY(" + pgIDArray(tenant.Companies) + ")"
+	}
+	return access
+}
+
+func pgIDArray(objectIDs []primitive.ObjectID) string {
+	ids := make([]string, len(objectIDs))
+	for i, id := range objectIDs {
+		ids[i] = "'" + id.Hex() + "'"
+	}
+	return "ARRAY[" + strings.Join(ids, ", ") + "]::text[]"
+}
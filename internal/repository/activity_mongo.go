@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// reportEditedAfter is how much later than its creation a report must change to count as
+// updated; both timestamps are set separately when it is created.
+const reportEditedAfter = 1000 // milliseconds
+
+type activityMongoRepository struct {
+	reports   *mongo.Collection
+	companies *mongo.Collection
+	users     *mongo.Collection
+}
+
+func NewActivityMongoRepository(db *mongo.Database) domain.ActivityRepository {
+	return &activityMongoRepository{
+		reports:   db.Collection(config.CollectionName("reports")),
+		companies: db.Collection(config.CollectionName("companies")),
+		users:     db.Collection(config.CollectionName("users")),
+	}
+}
+
+// Recent merges the newest entries of each collection with $unionWith. Each collection only
+// contributes the skip+limit entries a page can reach, read through its timestamp index.
+func (r *activityMongoRepository) Recent(ctx context.Context, includeUsers bool, skip, limit int) ([]*domain.Activity, int, error) {
+	depth := int64(skip + limit)
+
+	reportFilter := reportReadFilter(ctx, bson.M{})
+	companyFilter := companyReadFilter(ctx, bson.M{})
+	userFilter := userReadFilter(ctx, bson.M{})
+
+	pipeline := []bson.M{
+		{"$match": reportFilter},
+		{"$sort": bson.M{"updatedAt": -1}},
+		{"$limit": depth},
+		{"$project": bson.M{
+			"_id": 0,
+			"kind": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$subtract": bson.A{"$updatedAt", "$createdAt"}}, reportEditedAfter}},
+				domain.ActivityReportUpdated,
+				domain.ActivityReportCreated,
+			}},
+			"at":      "$updatedAt",
+			"subject": "$_id",
+			"name":    "$reportName",
+			"company": "$company",
+			"actor":   "$createdBy",
+		}},
+		{"$unionWith": bson.M{
+			"coll":     r.companies.Name(),
+			"pipeline": createdActivity(companyFilter, depth, domain.ActivityCompanyCreated),
+		}},
+	}
+	if includeUsers {
+		pipeline = append(pipeline, bson.M{"$unionWith": bson.M{
+			"coll":     r.users.Name(),
+			"pipeline": createdActivity(userFilter, depth, domain.ActivityUserJoined),
+		}})
+	}
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.D{{Key: "at", Value: -1}, {Key: "subject", Value: -1}}},
+		bson.M{"$skip": int64(skip)},
+		bson.M{"$limit": int64(limit)},
+	)
+
+	cursor, err := r.reports.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to get activity", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	activity := []*domain.Activity{}
+	if err = cursor.All(ctx, &activity); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to decode activity", 500, err, nil)
+	}
+
+	type source struct {
+		collection *mongo.Collection
+		filter     bson.M
+	}
+	sources := []source{{r.reports, reportFilter}, {r.companies, companyFilter}}
+	if includeUsers {
+		sources = append(sources, source{r.users, userFilter})
+	}
+
+	total := 0
+	for _, source := range sources {
+		count, err := source.collection.CountDocuments(ctx, source.filter)
+		if err != nil {
+			return nil, 0, errors.New("DATABASE_ERROR", "Failed to count activity", 500, err, nil)
+		}
+		total += int(count)
+	}
+
+	return activity, total, nil
+}
+
+// createdActivity is the $unionWith pipeline turning the newest documents of a collection
+// into entries of kind at their creation.
+func createdActivity(filter bson.M, depth int64, kind domain.ActivityKind) []bson.M {
+	return []bson.M{
+		{"$match": filter},
+		{"$sort": bson.M{"createdAt": -1}},
+		{"$limit": depth},
+		{"$project": bson.M{
+			"_id":     0,
+			"kind":    bson.M{"$literal": kind},
+			"at":      "$createdAt",
+			"subject": "$_id",
+			"name":    "$name",
+		}},
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type apiKeyMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAPIKeyMongoRepository(db *mongo.Database) domain.APIKeyRepository {
+	return &apiKeyMongoRepository{
+		collection: db.Collection(config.CollectionName("apikeys")),
+	}
+}
+
+func (r *apiKeyMongoRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = key.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, key)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create API key", 500, err, nil)
+	}
+
+	key.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *apiKeyMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.APIKey, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *apiKeyMongoRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return r.findOne(ctx, bson.M{"hash": hash})
+}
+
+func (r *apiKeyMongoRepository) findOne(ctx context.Context, filter bson.M) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.collection.FindOne(ctx, filter).Decode(&key); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("API_KEY_NOT_FOUND", "API key not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get API key", 500, err, nil)
+	}
+	return &key, nil
+}
+
+func (r *apiKeyMongoRepository) GetAll(ctx context.Context, user *primitive.ObjectID) ([]*domain.APIKey, error) {
+	filter := bson.M{}
+	if user != nil {
+		filter["user"] = *user
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get API keys", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	keys := []*domain.APIKey{}
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode API keys", 500, err, nil)
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyMongoRepository) Update(ctx context.Context, key *domain.APIKey) error {
+	key.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":               key.Name,
+			"rateLimitPerMinute": key.RateLimitPerMinute,
+			"dailyQuota":         key.DailyQuota,
+			"updatedAt":          key.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": key.ID}, update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update API key", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("API_KEY_NOT_FOUND", "API key not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *apiKeyMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete API key", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("API_KEY_NOT_FOUND", "API key not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *apiKeyMongoRepository) Touch(ctx context.Context, id primitive.ObjectID, t time.Time) error {
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"lastUsedAt": t}}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update API key", 500, err, nil)
+	}
+	return nil
+}
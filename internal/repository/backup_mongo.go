@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const restoreBatchSize = 500
+
+// backupLine is one document in a dump. Documents use canonical Extended JSON so
+// ObjectIDs and dates survive the round trip.
+type backupLine struct {
+	Collection string          `json:"collection"`
+	Document   json.RawMessage `json:"document"`
+}
+
+type backupMongoRepository struct {
+	db      *mongo.Database
+	records *mongo.Collection
+}
+
+func NewBackupMongoRepository(db *mongo.Database) domain.BackupRepository {
+	return &backupMongoRepository{
+		db:      db,
+		records: db.Collection(config.CollectionName("backups")),
+	}
+}
+
+// Export writes every document of the given collections (including soft-deleted ones) to w.
+func (r *backupMongoRepository) Export(ctx context.Context, collections []string, w io.Writer) (map[string]int, error) {
+	counts := make(map[string]int, len(collections))
+	encoder := json.NewEncoder(w)
+
+	for _, name := range collections {
+		cursor, err := r.db.Collection(config.CollectionName(name)).Find(ctx, bson.M{})
+		if err != nil {
+			return counts, errors.New("DATABASE_ERROR", "Failed to read collection "+name, 500, err, nil)
+		}
+
+		for cursor.Next(ctx) {
+			doc, err := bson.MarshalExtJSON(cursor.Current, true, false)
+			if err != nil {
+				cursor.Close(ctx)
+				return counts, errors.New("BACKUP_ENCODING_ERROR", "Failed to encode document", 500, err, nil)
+			}
+
+			if err := encoder.Encode(backupLine{Collection: name, Document: doc}); err != nil {
+				cursor.Close(ctx)
+				return counts, errors.New("BACKUP_WRITE_ERROR", "Failed to write backup", 500, err, nil)
+			}
+			counts[name]++
+		}
+
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return counts, errors.New("DATABASE_ERROR", "Failed to read collection "+name, 500, err, nil)
+		}
+	}
+
+	return counts, nil
+}
+
+// Import upserts every document in the dump by _id, so restoring is idempotent.
+func (r *backupMongoRepository) Import(ctx context.Context, rd io.Reader) (map[string]int, error) {
+	counts := make(map[string]int)
+	pending := make(map[string][]mongo.WriteModel)
+
+	flush := func(name string) error {
+		models := pending[name]
+		if len(models) == 0 {
+			return nil
+		}
+		_, err := r.db.Collection(config.CollectionName(name)).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to restore collection "+name, 500, err, nil)
+		}
+		counts[name] += len(models)
+		pending[name] = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20) // reportData documents can be large
+
+	for scanner.Scan() {
+		var line backupLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return counts, errors.New("INVALID_BACKUP", "Malformed backup line", 400, err, nil)
+		}
+		if !isBackupCollection(line.Collection) {
+			return counts, errors.New("INVALID_BACKUP", "Unknown collection in backup: "+line.Collection, 400, nil, nil)
+		}
+
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(line.Document, true, &doc); err != nil {
+			return counts, errors.New("INVALID_BACKUP", "Malformed backup document", 400, err, nil)
+		}
+
+		id, ok := documentID(doc)
+		if !ok {
+			return counts, errors.New("INVALID_BACKUP", "Backup document without _id", 400, nil, nil)
+		}
+
+		pending[line.Collection] = append(pending[line.Collection],
+			mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true))
+
+		if len(pending[line.Collection]) >= restoreBatchSize {
+			if err := flush(line.Collection); err != nil {
+				return counts, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return counts, errors.New("INVALID_BACKUP", "Failed to read backup", 400, err, nil)
+	}
+
+	for name := range pending {
+		if err := flush(name); err != nil {
+			return counts, err
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *backupMongoRepository) CreateRecord(ctx context.Context, backup *domain.Backup) error {
+	result, err := r.records.InsertOne(ctx, backup)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to record backup", 500, err, nil)
+	}
+
+	backup.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *backupMongoRepository) GetRecords(ctx context.Context, scheduled bool) ([]*domain.Backup, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := r.records.Find(ctx, bson.M{"scheduled": scheduled}, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get backups", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	backups := []*domain.Backup{}
+	if err := cursor.All(ctx, &backups); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode backups", 500, err, nil)
+	}
+	return backups, nil
+}
+
+func (r *backupMongoRepository) DeleteRecord(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.records.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete backup record", 500, err, nil)
+	}
+	return nil
+}
+
+func documentID(doc bson.D) (interface{}, bool) {
+	for _, elem := range doc {
+		if elem.Key == "_id" {
+			return elem.Value, true
+		}
+	}
+	return nil, false
+}
+
+func isBackupCollection(name string) bool {
+	for _, c := range domain.BackupCollections {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
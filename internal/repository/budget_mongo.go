@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type budgetMongoRepository struct {
+	budgets  *mongo.Collection
+	versions *mongo.Collection
+}
+
+func NewBudgetMongoRepository(db *mongo.Database) domain.BudgetRepository {
+	return &budgetMongoRepository{
+		budgets:  db.Collection(config.CollectionName("budgets")),
+		versions: db.Collection(config.CollectionName("budget_versions")),
+	}
+}
+
+func (r *budgetMongoRepository) Create(ctx context.Context, budget *domain.Budget, note string) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(budget.Company) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	budget.Version = 1
+	budget.CreatedAt = time.Now()
+	budget.UpdatedAt = budget.CreatedAt
+
+	result, err := r.budgets.InsertOne(ctx, budget)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("BUDGET_ALREADY_EXISTS", "The company already has a budget with this name for the year", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to create budget", 500, err, nil)
+	}
+
+	budget.ID = result.InsertedID.(primitive.ObjectID)
+	return r.saveVersion(ctx, budget, note)
+}
+
+func (r *budgetMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Budget, error) {
+	var budget domain.Budget
+	if err := r.budgets.FindOne(ctx, companyDataFilter(ctx, bson.M{"_id": id})).Decode(&budget); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("BUDGET_NOT_FOUND", "Budget not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get budget", 500, err, nil)
+	}
+	return &budget, nil
+}
+
+func (r *budgetMongoRepository) GetAll(ctx context.Context, company *primitive.ObjectID, year int) ([]*domain.Budget, error) {
+	filter := bson.M{}
+	if company != nil {
+		filter["company"] = *company
+	}
+	if year != 0 {
+		filter["year"] = year
+	}
+	// Lines are left out of lists; fetch one budget for them
+	opts := options.Find().
+		SetSort(bson.D{{Key: "company", Value: 1}, {Key: "year", Value: -1}, {Key: "name", Value: 1}}).
+		SetProjection(bson.M{"lines": 0})
+
+	cursor, err := r.budgets.Find(ctx, companyDataFilter(ctx, filter), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get budgets", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	budgets := []*domain.Budget{}
+	if err = cursor.All(ctx, &budgets); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode budgets", 500, err, nil)
+	}
+
+	return budgets, nil
+}
+
+// Update matches the budget on the version it was read at, so of two concurrent changes the
+// second fails instead of overwriting the first.
+func (r *budgetMongoRepository) Update(ctx context.Context, budget *domain.Budget, note string) error {
+	budget.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       budget.Name,
+			"reportType": budget.ReportType,
+			"currency":   budget.Currency,
+			"lines":      budget.Lines,
+			"updatedBy":  budget.UpdatedBy,
+			"updatedAt":  budget.UpdatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := r.budgets.UpdateOne(ctx, companyDataFilter(ctx, bson.M{"_id": budget.ID, "version": budget.Version}), update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("BUDGET_ALREADY_EXISTS", "The company already has a budget with this name for the year", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to update budget", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.GetByID(ctx, budget.ID); err != nil {
+			return err
+		}
+		return errors.New("BUDGET_VERSION_CONFLICT", "The budget changed since this version was read", 409, nil, nil)
+	}
+
+	budget.Version++
+	return r.saveVersion(ctx, budget, note)
+}
+
+func (r *budgetMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.budgets.DeleteOne(ctx, companyDataFilter(ctx, bson.M{"_id": id}))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete budget", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("BUDGET_NOT_FOUND", "Budget not found", 404, nil, nil)
+	}
+
+	if _, err := r.versions.DeleteMany(ctx, bson.M{"budget": id}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete budget versions", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *budgetMongoRepository) GetVersions(ctx context.Context, id primitive.ObjectID) ([]*domain.BudgetVersion, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"lines": 0})
+
+	cursor, err := r.versions.Find(ctx, companyDataFilter(ctx, bson.M{"budget": id}), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get budget versions", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	versions := []*domain.BudgetVersion{}
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode budget versions", 500, err, nil)
+	}
+
+	return versions, nil
+}
+
+func (r *budgetMongoRepository) GetVersion(ctx context.Context, id primitive.ObjectID, version int) (*domain.BudgetVersion, error) {
+	var budgetVersion domain.BudgetVersion
+	filter := companyDataFilter(ctx, bson.M{"budget": id, "version": version})
+	if err := r.versions.FindOne(ctx, filter).Decode(&budgetVersion); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("BUDGET_VERSION_NOT_FOUND", "Budget version not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get budget version", 500, err, nil)
+	}
+	return &budgetVersion, nil
+}
+
+func (r *budgetMongoRepository) saveVersion(ctx context.Context, budget *domain.Budget, note string) error {
+	version := &domain.BudgetVersion{
+		Budget:    budget.ID,
+		Company:   budget.Company,
+		Version:   budget.Version,
+		Lines:     budget.Lines,
+		Note:      note,
+		CreatedBy: budget.UpdatedBy,
+		CreatedAt: budget.UpdatedAt,
+	}
+
+	if _, err := r.versions.InsertOne(ctx, version); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save budget version", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Cache key prefixes for repository-level lookups
+const (
+	userCacheKeyPrefix       = "repo:user:"
+	companyCacheKeyPrefix    = "repo:company:"
+	reportTypeCacheKeyPrefix = "repo:reporttype:"
+	reportTypeAllCacheKey    = "repo:reporttypes:all"
+
+	organizationCacheKeyPrefix          = "repo:organization:"
+	organizationCompaniesCacheKeyPrefix = "repo:organization:companies:"
+)
+
+// Cached values are copied on the way out so callers can't mutate what's stored.
+
+// cachedUserRepository caches user lookups by ID and invalidates them on writes.
+type cachedUserRepository struct {
+	domain.UserRepository
+	cache utils.Cache
+	ttl   time.Duration
+}
+
+func NewCachedUserRepository(next domain.UserRepository, cache utils.Cache, ttl time.Duration) domain.UserRepository {
+	return &cachedUserRepository{UserRepository: next, cache: cache, ttl: ttl}
+}
+
+func (r *cachedUserRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	if domain.IncludesDeleted(ctx) {
+		return r.UserRepository.GetByID(ctx, id)
+	}
+
+	key := userCacheKeyPrefix + id.Hex()
+	var cached *domain.User
+	if r.cache.Get(key, &cached) {
+		user := *cached
+		// Entries are shared by every caller, so the tenant is checked on the way out
+		if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.Owns(user.Organization) {
+			return nil, errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil)
+		}
+		return &user, nil
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *user
+	r.cache.Set(key, &stored, r.ttl)
+	return user, nil
+}
+
+func (r *cachedUserRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
+	defer r.cache.Delete(userCacheKeyPrefix + id.Hex())
+	return r.UserRepository.Update(ctx, id, user)
+}
+
+func (r *cachedUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cache.Delete(userCacheKeyPrefix + id.Hex())
+	return r.UserRepository.Delete(ctx, id)
+}
+
+// cachedCompanyRepository caches company lookups by ID and invalidates them on writes.
+type cachedCompanyRepository struct {
+	domain.CompanyRepository
+	cache utils.Cache
+	ttl   time.Duration
+}
+
+func NewCachedCompanyRepository(next domain.CompanyRepository, cache utils.Cache, ttl time.Duration) domain.CompanyRepository {
+	return &cachedCompanyRepository{CompanyRepository: next, cache: cache, ttl: ttl}
+}
+
+func (r *cachedCompanyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Company, error) {
+	if domain.IncludesDeleted(ctx) {
+		return r.CompanyRepository.GetByID(ctx, id)
+	}
+
+	key := companyCacheKeyPrefix + id.Hex()
+	var cached *domain.Company
+	if r.cache.Get(key, &cached) {
+		company := *cached
+		// Entries are shared by every caller, so the tenant and access scope are checked on the way out
+		if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.Owns(company.Organization) {
+			return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+		}
+		if scope := domain.AccessScopeOf(ctx); scope != nil && !scope.AllowsCompany(&company) {
+			return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+		}
+		return &company, nil
+	}
+
+	company, err := r.CompanyRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *company
+	r.cache.Set(key, &stored, r.ttl)
+	return company, nil
+}
+
+// Create drops the company list of the organization, or the instance, the company joins. The
+// tenant of every later request in it needs the new company.
+func (r *cachedCompanyRepository) Create(ctx context.Context, company *domain.Company) error {
+	if err := r.CompanyRepository.Create(ctx, company); err != nil {
+		return err
+	}
+	organization := primitive.NilObjectID
+	if company.Organization != nil {
+		organization = *company.Organization
+	}
+	r.cache.Delete(organizationCompaniesCacheKeyPrefix + organization.Hex())
+	return nil
+}
+
+func (r *cachedCompanyRepository) Update(ctx context.Context, id primitive.ObjectID, company *domain.Company) error {
+	defer r.cache.Delete(companyCacheKeyPrefix + id.Hex())
+	return r.CompanyRepository.Update(ctx, id, company)
+}
+
+func (r *cachedCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cache.Delete(companyCacheKeyPrefix + id.Hex())
+	return r.CompanyRepository.Delete(ctx, id)
+}
+
+// cachedReportTypeRepository caches report type lookups by ID and the full list.
+type cachedReportTypeRepository struct {
+	domain.ReportTypeRepository
+	cache utils.Cache
+	ttl   time.Duration
+}
+
+func NewCachedReportTypeRepository(next domain.ReportTypeRepository, cache utils.Cache, ttl time.Duration) domain.ReportTypeRepository {
+	return &cachedReportTypeRepository{ReportTypeRepository: next, cache: cache, ttl: ttl}
+}
+
+func (r *cachedReportTypeRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ReportType, error) {
+	if domain.IncludesDeleted(ctx) {
+		return r.ReportTypeRepository.GetByID(ctx, id)
+	}
+
+	key := reportTypeCacheKeyPrefix + id.Hex()
+	var cached *domain.ReportType
+	if r.cache.Get(key, &cached) {
+		reportType := *cached
+		return &reportType, nil
+	}
+
+	reportType, err := r.ReportTypeRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *reportType
+	r.cache.Set(key, &stored, r.ttl)
+	return reportType, nil
+}
+
+func (r *cachedReportTypeRepository) GetAll(ctx context.Context) ([]*domain.ReportType, error) {
+	if domain.IncludesDeleted(ctx) {
+		return r.ReportTypeRepository.GetAll(ctx)
+	}
+
+	var cached []*domain.ReportType
+	if r.cache.Get(reportTypeAllCacheKey, &cached) {
+		return copyReportTypes(cached), nil
+	}
+
+	reportTypes, err := r.ReportTypeRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(reportTypeAllCacheKey, copyReportTypes(reportTypes), r.ttl)
+	return reportTypes, nil
+}
+
+func (r *cachedReportTypeRepository) Create(ctx context.Context, reportType *domain.ReportType) error {
+	defer r.cache.Delete(reportTypeAllCacheKey)
+	return r.ReportTypeRepository.Create(ctx, reportType)
+}
+
+func (r *cachedReportTypeRepository) Update(ctx context.Context, id primitive.ObjectID, reportType *domain.ReportType) error {
+	defer r.invalidate(id)
+	return r.ReportTypeRepository.Update(ctx, id, reportType)
+}
+
+func (r *cachedReportTypeRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.invalidate(id)
+	return r.ReportTypeRepository.Delete(ctx, id)
+}
+
+func (r *cachedReportTypeRepository) invalidate(id primitive.ObjectID) {
+	r.cache.Delete(reportTypeCacheKeyPrefix + id.Hex())
+	r.cache.Delete(reportTypeAllCacheKey)
+}
+
+// cachedIntegrityRepository drops cached users and companies whose reference lists are repaired.
+type cachedIntegrityRepository struct {
+	domain.IntegrityRepository
+	cache utils.Cache
+}
+
+func NewCachedIntegrityRepository(next domain.IntegrityRepository, cache utils.Cache) domain.IntegrityRepository {
+	return &cachedIntegrityRepository{IntegrityRepository: next, cache: cache}
+}
+
+func (r *cachedIntegrityRepository) RemoveReference(ctx context.Context, orphan domain.OrphanReference) error {
+	switch orphan.Check {
+	case domain.CheckUserCompany:
+		defer r.cache.Delete(userCacheKeyPrefix + orphan.DocumentID.Hex())
+	case domain.CheckCompanyUser:
+		defer r.cache.Delete(companyCacheKeyPrefix + orphan.DocumentID.Hex())
+	}
+	return r.IntegrityRepository.RemoveReference(ctx, orphan)
+}
+
+// cachedOrganizationRepository caches organizations and their company IDs, which the access
+// scope of every request by a member needs, and drops the cached users and companies it moves.
+type cachedOrganizationRepository struct {
+	domain.OrganizationRepository
+	cache utils.Cache
+	ttl   time.Duration
+}
+
+func NewCachedOrganizationRepository(next domain.OrganizationRepository, cache utils.Cache, ttl time.Duration) domain.OrganizationRepository {
+	return &cachedOrganizationRepository{OrganizationRepository: next, cache: cache, ttl: ttl}
+}
+
+func (r *cachedOrganizationRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Organization, error) {
+	if domain.IncludesDeleted(ctx) {
+		return r.OrganizationRepository.GetByID(ctx, id)
+	}
+
+	key := organizationCacheKeyPrefix + id.Hex()
+	var cached *domain.Organization
+	if r.cache.Get(key, &cached) {
+		organization := *cached
+		if tenant := domain.TenantOf(ctx); tenant != nil && tenant.Organization != organization.ID {
+			return nil, errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", 404, nil, nil)
+		}
+		return &organization, nil
+	}
+
+	organization, err := r.OrganizationRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *organization
+	r.cache.Set(key, &stored, r.ttl)
+	return organization, nil
+}
+
+func (r *cachedOrganizationRepository) Update(ctx context.Context, id primitive.ObjectID, organization *domain.Organization) error {
+	defer r.cache.Delete(organizationCacheKeyPrefix + id.Hex())
+	return r.OrganizationRepository.Update(ctx, id, organization)
+}
+
+func (r *cachedOrganizationRepository) Companies(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if domain.IncludesDeleted(ctx) {
+		return r.OrganizationRepository.Companies(ctx, id)
+	}
+
+	key := organizationCompaniesCacheKeyPrefix + id.Hex()
+	var cached []primitive.ObjectID
+	if r.cache.Get(key, &cached) {
+		return append([]primitive.ObjectID{}, cached...), nil
+	}
+
+	companies, err := r.OrganizationRepository.Companies(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, append([]primitive.ObjectID{}, companies...), r.ttl)
+	return companies, nil
+}
+
+func (r *cachedOrganizationRepository) SetCompanyOrganization(ctx context.Context, companyID primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	// The previous organization isn't known here, so every organization's list is dropped
+	defer r.cache.DeletePrefix(organizationCompaniesCacheKeyPrefix)
+	defer r.cache.Delete(companyCacheKeyPrefix + companyID.Hex())
+	return r.OrganizationRepository.SetCompanyOrganization(ctx, companyID, organizationID)
+}
+
+func (r *cachedOrganizationRepository) SetUserOrganization(ctx context.Context, userID primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	defer r.cache.Delete(userCacheKeyPrefix + userID.Hex())
+	return r.OrganizationRepository.SetUserOrganization(ctx, userID, organizationID)
+}
+
+func (r *cachedOrganizationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cache.Delete(organizationCompaniesCacheKeyPrefix + id.Hex())
+	defer r.cache.Delete(organizationCacheKeyPrefix + id.Hex())
+	return r.OrganizationRepository.Delete(ctx, id)
+}
+
+func copyReportTypes(reportTypes []*domain.ReportType) []*domain.ReportType {
+	copied := make([]*domain.ReportType, len(reportTypes))
+	for i, reportType := range reportTypes {
+		rt := *reportType
+		copied[i] = &rt
+	}
+	return copied
+}
@@ -9,6 +9,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"finsolvz-backend/internal/config"
 	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/utils/errors"
 )
@@ -19,13 +20,16 @@ type companyMongoRepository struct {
 
 func NewCompanyMongoRepository(db *mongo.Database) domain.CompanyRepository {
 	return &companyMongoRepository{
-		collection: db.Collection("companies"),
+		collection: db.Collection(config.CollectionName("companies")),
 	}
 }
 
 func (r *companyMongoRepository) Create(ctx context.Context, company *domain.Company) error {
 	company.CreatedAt = time.Now()
 	company.UpdatedAt = time.Now()
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		company.Organization = tenant.Stamp()
+	}
 
 	result, err := r.collection.InsertOne(ctx, company)
 	if err != nil {
@@ -41,7 +45,7 @@ func (r *companyMongoRepository) Create(ctx context.Context, company *domain.Com
 
 func (r *companyMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Company, error) {
 	var company domain.Company
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&company)
+	err := r.collection.FindOne(ctx, companyReadFilter(ctx, bson.M{"_id": id})).Decode(&company)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, err, nil)
@@ -51,12 +55,70 @@ func (r *companyMongoRepository) GetByID(ctx context.Context, id primitive.Objec
 	return &company, nil
 }
 
+func (r *companyMongoRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.Company, error) {
+	cursor, err := r.collection.Find(ctx, companyReadFilter(ctx, bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var companies []*domain.Company
+	if err = cursor.All(ctx, &companies); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode companies", 500, err, nil)
+	}
+
+	return companies, nil
+}
+
 func (r *companyMongoRepository) GetAll(ctx context.Context) ([]*domain.Company, error) {
+	pipeline := append(r.listPipeline(), bson.M{
+		"$limit": 100, // Prevent massive data loads
+	})
+
+	cursor, err := r.collection.Aggregate(ctx, readPipeline(companyReadFilter(ctx, bson.M{}), pipeline))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var companies []*domain.Company
+	if err = cursor.All(ctx, &companies); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode companies", 500, err, nil)
+	}
+
+	return companies, nil
+}
+
+func (r *companyMongoRepository) Each(ctx context.Context, fn func(*domain.Company) error) error {
+	cursor, err := r.collection.Aggregate(ctx, readPipeline(companyReadFilter(ctx, bson.M{}), r.listPipeline()))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var company domain.Company
+		if err := cursor.Decode(&company); err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to decode companies", 500, err, nil)
+		}
+		if err := fn(&company); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
+	}
+
+	return nil
+}
+
+// listPipeline lists companies newest first, with their first user's name.
+func (r *companyMongoRepository) listPipeline() []bson.M {
 	// Optimized pipeline with sub-query for better performance
-	pipeline := []bson.M{
+	return []bson.M{
 		{
 			"$lookup": bson.M{
-				"from":         "users",
+				"from":         config.CollectionName("users"),
 				"localField":   "user",
 				"foreignField": "_id",
 				"as":           "userDetails",
@@ -76,6 +138,7 @@ func (r *companyMongoRepository) GetAll(ctx context.Context) ([]*domain.Company,
 				"name":           1,
 				"profilePicture": 1,
 				"user":           1,
+				"organization":   1,
 				"createdAt":      1,
 				"updatedAt":      1,
 				"userDetails": bson.M{
@@ -86,27 +149,11 @@ func (r *companyMongoRepository) GetAll(ctx context.Context) ([]*domain.Company,
 		{
 			"$sort": bson.M{"createdAt": -1},
 		},
-		{
-			"$limit": 100, // Prevent massive data loads
-		},
-	}
-
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
-	}
-	defer cursor.Close(ctx)
-
-	var companies []*domain.Company
-	if err = cursor.All(ctx, &companies); err != nil {
-		return nil, errors.New("DATABASE_ERROR", "Failed to decode companies", 500, err, nil)
 	}
-
-	return companies, nil
 }
 
 func (r *companyMongoRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*domain.Company, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"user": userID})
+	cursor, err := r.collection.Find(ctx, companyReadFilter(ctx, bson.M{"user": userID}))
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get user companies", 500, err, nil)
 	}
@@ -125,14 +172,18 @@ func (r *companyMongoRepository) Update(ctx context.Context, id primitive.Object
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":           company.Name,
-			"profilePicture": company.ProfilePicture,
-			"user":           company.User,
-			"updatedAt":      company.UpdatedAt,
+			"name":                company.Name,
+			"profilePicture":      company.ProfilePicture,
+			"profilePictureThumb": company.ProfilePictureThumb,
+			"user":                company.User,
+			"fiscalCalendar":      company.FiscalCalendar,
+			"jurisdiction":        company.Jurisdiction,
+			"branding":            company.Branding,
+			"updatedAt":           company.UpdatedAt,
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, tenantFilter(ctx, scopeFilter(ctx, bson.M{"_id": id})), update)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return errors.New("COMPANY_ALREADY_EXISTS", "Company name already exists", 409, err, nil)
@@ -148,12 +199,12 @@ func (r *companyMongoRepository) Update(ctx context.Context, id primitive.Object
 }
 
 func (r *companyMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	result, err := softDelete(ctx, r.collection, tenantFilter(ctx, bson.M{"_id": id}))
 	if err != nil {
 		return errors.New("DATABASE_ERROR", "Failed to delete company", 500, err, nil)
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
 	}
 
@@ -164,16 +215,16 @@ func (r *companyMongoRepository) GetByName(ctx context.Context, name string) (*d
 	var company domain.Company
 
 	// Try exact match first (fastest, uses index)
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&company)
+	err := r.collection.FindOne(ctx, companyReadFilter(ctx, bson.M{"name": name})).Decode(&company)
 	if err == nil {
 		return &company, nil
 	}
 
 	// If not found, try case insensitive exact match
 	if err == mongo.ErrNoDocuments {
-		err = r.collection.FindOne(ctx, bson.M{
+		err = r.collection.FindOne(ctx, companyReadFilter(ctx, bson.M{
 			"name": bson.M{"$regex": "^" + name + "$", "$options": "i"},
-		}).Decode(&company)
+		})).Decode(&company)
 		if err == nil {
 			return &company, nil
 		}
@@ -194,7 +245,7 @@ func (r *companyMongoRepository) SearchByName(ctx context.Context, name string)
 
 	// Add limit to prevent large result sets
 	limit := int64(50)
-	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
+	cursor, err := r.collection.Find(ctx, companyReadFilter(ctx, filter), &options.FindOptions{
 		Limit: &limit,                          // Limit search results
 		Sort:  bson.D{{Key: "name", Value: 1}}, // Sort by name for consistency
 	})
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const companyColumns = `id, name, profile_picture, profile_picture_thumb, users, created_at, updated_at, deleted_at, fiscal_calendar, jurisdiction, branding`
+
+type companyPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewCompanyPostgresRepository(db *sql.DB) domain.CompanyRepository {
+	return &companyPostgresRepository{db: db}
+}
+
+func scanCompany(row interface{ Scan(...interface{}) error }) (*domain.Company, error) {
+	var (
+		company  domain.Company
+		id       string
+		users    []byte
+		fiscal   []byte
+		branding []byte
+	)
+	if err := row.Scan(&id, &company.Name, &company.ProfilePicture, &company.ProfilePictureThumb, &users,
+		&company.CreatedAt, &company.UpdatedAt, &company.DeletedAt, &fiscal, &company.Jurisdiction, &branding); err != nil {
+		return nil, err
+	}
+	company.ID = parseID(id)
+	company.User = decodeIDs(users)
+	if fiscal != nil {
+		if err := json.Unmarshal(fiscal, &company.FiscalCalendar); err != nil {
+			return nil, err
+		}
+	}
+	if branding != nil {
+		if err := json.Unmarshal(branding, &company.Branding); err != nil {
+			return nil, err
+		}
+	}
+	return &company, nil
+}
+
+// encodeFiscalCalendar stores a fiscal calendar as JSON, or NULL for calendar years.
+func encodeFiscalCalendar(calendar *domain.FiscalCalendar) (interface{}, error) {
+	if calendar == nil {
+		return nil, nil
+	}
+	return json.Marshal(calendar)
+}
+
+// encodeBranding stores a branding as JSON, or NULL for the default look.
+func encodeBranding(branding *domain.Branding) (interface{}, error) {
+	if branding == nil {
+		return nil, nil
+	}
+	return json.Marshal(branding)
+}
+
+func (r *companyPostgresRepository) queryCompanies(ctx context.Context, query string, args ...interface{}) ([]*domain.Company, error) {
+	var companies []*domain.Company
+	err := r.eachCompany(ctx, func(company *domain.Company) error {
+		companies = append(companies, company)
+		return nil
+	}, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+func (r *companyPostgresRepository) eachCompany(ctx context.Context, fn func(*domain.Company) error, query string, args ...interface{}) error {
+	rows, err := pgConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		company, err := scanCompany(rows)
+		if err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to decode companies", 500, err, nil)
+		}
+		if err := fn(company); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get companies", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *companyPostgresRepository) Create(ctx context.Context, company *domain.Company) error {
+	fiscal, err := encodeFiscalCalendar(company.FiscalCalendar)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode fiscal calendar", 500, err, nil)
+	}
+	branding, err := encodeBranding(company.Branding)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode branding", 500, err, nil)
+	}
+
+	company.ID = primitive.NewObjectID()
+	company.CreatedAt = time.Now()
+	company.UpdatedAt = time.Now()
+
+	_, err = pgConn(ctx, r.db).ExecContext(ctx, `INSERT INTO companies (`+companyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		company.ID.Hex(), company.Name, company.ProfilePicture, company.ProfilePictureThumb, encodeIDs(company.User),
+		company.CreatedAt, company.UpdatedAt, company.DeletedAt, fiscal, company.Jurisdiction, branding)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("COMPANY_ALREADY_EXISTS", "Company name already exists", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to create company", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *companyPostgresRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Company, error) {
+	row := pgConn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+companyColumns+` FROM companies WHERE id = $1 AND `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx), id.Hex())
+
+	company, err := scanCompany(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get company", 500, err, nil)
+	}
+	return company, nil
+}
+
+// GetByName tries an exact match first, then a case-insensitive one.
+func (r *companyPostgresRepository) GetByName(ctx context.Context, name string) (*domain.Company, error) {
+	companies, err := r.queryCompanies(ctx, `SELECT `+companyColumns+` FROM companies
+		WHERE lower(name) = lower($1) AND `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx)+`
+		ORDER BY (name = $1) DESC LIMIT 1`, name)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to search company", 500, err, nil)
+	}
+
+	if len(companies) == 0 {
+		return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	return companies[0], nil
+}
+
+func (r *companyPostgresRepository) SearchByName(ctx context.Context, name string) ([]*domain.Company, error) {
+	companies, err := r.queryCompanies(ctx, `SELECT `+companyColumns+` FROM companies
+		WHERE name ~* $1 AND `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx)+`
+		ORDER BY name LIMIT 50`, name)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to search companies", 500, err, nil)
+	}
+
+	if len(companies) == 0 {
+		return nil, errors.New("COMPANY_NOT_FOUND", "No companies found matching the criteria", 404, nil, nil)
+	}
+
+	return companies, nil
+}
+
+func (r *companyPostgresRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.Company, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := idPlaceholders(ids)
+	return r.queryCompanies(ctx, `SELECT `+companyColumns+` FROM companies
+		WHERE id IN (`+placeholders+`) AND `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx), args...)
+}
+
+func (r *companyPostgresRepository) GetAll(ctx context.Context) ([]*domain.Company, error) {
+	return r.queryCompanies(ctx, `SELECT `+companyColumns+` FROM companies
+		WHERE `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx)+` ORDER BY created_at DESC LIMIT 100`)
+}
+
+func (r *companyPostgresRepository) Each(ctx context.Context, fn func(*domain.Company) error) error {
+	return r.eachCompany(ctx, fn, `SELECT `+companyColumns+` FROM companies
+		WHERE `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx)+` ORDER BY created_at DESC`)
+}
+
+func (r *companyPostgresRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*domain.Company, error) {
+	return r.queryCompanies(ctx, `SELECT `+companyColumns+` FROM companies
+		WHERE users @> jsonb_build_array($1::text) AND `+pgNotDeleted(ctx, "")+` AND `+pgCompanyAccess(ctx), userID.Hex())
+}
+
+func (r *companyPostgresRepository) Update(ctx context.Context, id primitive.ObjectID, company *domain.Company) error {
+	fiscal, err := encodeFiscalCalendar(company.FiscalCalendar)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode fiscal calendar", 500, err, nil)
+	}
+	branding, err := encodeBranding(company.Branding)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode branding", 500, err, nil)
+	}
+
+	company.UpdatedAt = time.Now()
+
+	result, err := pgConn(ctx, r.db).ExecContext(ctx, `UPDATE companies SET
+			name = $2, profile_picture = $3, users = $4, updated_at = $5, profile_picture_thumb = $6, fiscal_calendar = $7,
+			jurisdiction = $8, branding = $9
+		WHERE id = $1 AND `+pgNotDeleted(ctx, ""),
+		id.Hex(), company.Name, company.ProfilePicture, encodeIDs(company.User), company.UpdatedAt, company.ProfilePictureThumb, fiscal,
+		company.Jurisdiction, branding)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("COMPANY_ALREADY_EXISTS", "Company name already exists", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to update company", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *companyPostgresRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE companies SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`, id.Hex())
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete company", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	return nil
+}
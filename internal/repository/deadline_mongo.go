@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type deadlineMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDeadlineMongoRepository(db *mongo.Database) domain.DeadlineRepository {
+	return &deadlineMongoRepository{
+		collection: db.Collection(config.CollectionName("report_deadlines")),
+	}
+}
+
+func (r *deadlineMongoRepository) Create(ctx context.Context, deadline *domain.ReportDeadline) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(deadline.Company) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	deadline.CreatedAt = time.Now()
+	deadline.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, deadline)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create deadline", 500, err, nil)
+	}
+
+	deadline.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *deadlineMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ReportDeadline, error) {
+	var deadline domain.ReportDeadline
+	if err := r.collection.FindOne(ctx, companyDataFilter(ctx, bson.M{"_id": id})).Decode(&deadline); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("DEADLINE_NOT_FOUND", "Deadline not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get deadline", 500, err, nil)
+	}
+	return &deadline, nil
+}
+
+func (r *deadlineMongoRepository) GetAll(ctx context.Context, company *primitive.ObjectID) ([]*domain.ReportDeadline, error) {
+	filter := bson.M{}
+	if company != nil {
+		filter["company"] = *company
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "company", Value: 1}, {Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, companyDataFilter(ctx, filter), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get deadlines", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	deadlines := []*domain.ReportDeadline{}
+	if err = cursor.All(ctx, &deadlines); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode deadlines", 500, err, nil)
+	}
+
+	return deadlines, nil
+}
+
+func (r *deadlineMongoRepository) Update(ctx context.Context, id primitive.ObjectID, deadline *domain.ReportDeadline) error {
+	deadline.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"reportType": deadline.ReportType,
+			"frequency":  deadline.Frequency,
+			"dueDays":    deadline.DueDays,
+			"remindDays": deadline.RemindDays,
+			"updatedAt":  deadline.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, companyDataFilter(ctx, bson.M{"_id": id}), update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update deadline", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("DEADLINE_NOT_FOUND", "Deadline not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *deadlineMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, companyDataFilter(ctx, bson.M{"_id": id}))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete deadline", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("DEADLINE_NOT_FOUND", "Deadline not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *deadlineMongoRepository) SetReminder(ctx context.Context, id primitive.ObjectID, reminder domain.DeadlineReminder) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"lastReminder": reminder}})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update deadline", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// digestRunID is the ID of the single document holding the digest schedule.
+const digestRunID = "digest"
+
+type digestMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDigestMongoRepository(db *mongo.Database) domain.DigestRepository {
+	return &digestMongoRepository{
+		collection: db.Collection(config.CollectionName("digest_runs")),
+	}
+}
+
+// Claim upserts the schedule, so the first run creates it. When the document exists but the
+// digest is not due or is leased, the upsert collides with it on _id and nothing is claimed.
+func (r *digestMongoRepository) Claim(ctx context.Context, due, lockedUntil time.Time) (*domain.DigestRun, error) {
+	filter := bson.M{"_id": digestRunID, "$and": []bson.M{
+		{"$or": []bson.M{{"lastRunAt": bson.M{"$exists": false}}, {"lastRunAt": bson.M{"$lte": due}}}},
+		{"$or": []bson.M{{"lockedUntil": bson.M{"$exists": false}}, {"lockedUntil": bson.M{"$lt": time.Now()}}}},
+	}}
+	update := bson.M{"$set": bson.M{"lockedUntil": lockedUntil}}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.Before)
+
+	var run domain.DigestRun
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&run); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &domain.DigestRun{}, nil
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, nil
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to claim digest run", 500, err, nil)
+	}
+	return &run, nil
+}
+
+func (r *digestMongoRepository) Complete(ctx context.Context, startedAt time.Time) error {
+	update := bson.M{
+		"$set":   bson.M{"lastRunAt": startedAt},
+		"$unset": bson.M{"lockedUntil": ""},
+	}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": digestRunID}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to record digest run", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *digestMongoRepository) Release(ctx context.Context) error {
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": digestRunID}, bson.M{"$unset": bson.M{"lockedUntil": ""}}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to release digest run", 500, err, nil)
+	}
+	return nil
+}
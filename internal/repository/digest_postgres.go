@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type digestPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewDigestPostgresRepository(db *sql.DB) domain.DigestRepository {
+	return &digestPostgresRepository{db: db}
+}
+
+// Claim inserts the schedule on the first run. Afterwards the insert conflicts with it and
+// takes the lease only when the conditions hold; otherwise no row is returned.
+func (r *digestPostgresRepository) Claim(ctx context.Context, due, lockedUntil time.Time) (*domain.DigestRun, error) {
+	var run domain.DigestRun
+	err := pgConn(ctx, r.db).QueryRowContext(ctx, `INSERT INTO digest_runs (id, locked_until) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET locked_until = EXCLUDED.locked_until
+		WHERE (digest_runs.last_run_at IS NULL OR digest_runs.last_run_at <= $3)
+			AND (digest_runs.locked_until IS NULL OR digest_runs.locked_until < now())
+		RETURNING last_run_at`,
+		digestRunID, lockedUntil, due).Scan(&run.LastRunAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to claim digest run", 500, err, nil)
+	}
+	return &run, nil
+}
+
+func (r *digestPostgresRepository) Complete(ctx context.Context, startedAt time.Time) error {
+	_, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE digest_runs SET last_run_at = $2, locked_until = NULL WHERE id = $1`, digestRunID, startedAt)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to record digest run", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *digestPostgresRepository) Release(ctx context.Context) error {
+	_, err := pgConn(ctx, r.db).ExecContext(ctx, `UPDATE digest_runs SET locked_until = NULL WHERE id = $1`, digestRunID)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to release digest run", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type exportMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewExportMongoRepository(db *mongo.Database) domain.ExportRepository {
+	return &exportMongoRepository{
+		collection: db.Collection(config.CollectionName("exports")),
+	}
+}
+
+func (r *exportMongoRepository) Create(ctx context.Context, export *domain.Export) error {
+	export.CreatedAt = time.Now()
+	export.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, export)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create export", 500, err, nil)
+	}
+
+	export.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *exportMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Export, error) {
+	var export domain.Export
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&export); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("EXPORT_NOT_FOUND", "Export not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get export", 500, err, nil)
+	}
+	return &export, nil
+}
+
+func (r *exportMongoRepository) SetTask(ctx context.Context, id, taskID primitive.ObjectID) error {
+	return r.update(ctx, id, bson.M{"$set": bson.M{"taskId": taskID, "updatedAt": time.Now()}})
+}
+
+func (r *exportMongoRepository) SetRendered(ctx context.Context, id primitive.ObjectID, key string, rows int, size int64, expiresAt time.Time) error {
+	return r.update(ctx, id, bson.M{"$set": bson.M{
+		"key":       key,
+		"rows":      rows,
+		"size":      size,
+		"expiresAt": expiresAt,
+		"updatedAt": time.Now(),
+	}})
+}
+
+func (r *exportMongoRepository) Expired(ctx context.Context, before time.Time, limit int) ([]*domain.Export, error) {
+	filter := bson.M{
+		"key":       bson.M{"$exists": true},
+		"expiresAt": bson.M{"$lt": before},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get expired exports", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var exports []*domain.Export
+	if err = cursor.All(ctx, &exports); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode exports", 500, err, nil)
+	}
+
+	return exports, nil
+}
+
+func (r *exportMongoRepository) MarkExpired(ctx context.Context, id primitive.ObjectID) error {
+	return r.update(ctx, id, bson.M{
+		"$unset": bson.M{"key": ""},
+		"$set":   bson.M{"expiredAt": time.Now(), "updatedAt": time.Now()},
+	})
+}
+
+func (r *exportMongoRepository) update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update export", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("EXPORT_NOT_FOUND", "Export not found", 404, nil, nil)
+	}
+
+	return nil
+}
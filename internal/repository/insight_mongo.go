@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type insightMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInsightMongoRepository(db *mongo.Database) domain.InsightRepository {
+	return &insightMongoRepository{
+		collection: db.Collection(config.CollectionName("report_insights")),
+	}
+}
+
+func (r *insightMongoRepository) Get(ctx context.Context, report primitive.ObjectID, version string) (*domain.ReportInsight, error) {
+	var insight domain.ReportInsight
+	filter := companyDataFilter(ctx, bson.M{"report": report, "version": version})
+	if err := r.collection.FindOne(ctx, filter).Decode(&insight); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("INSIGHT_NOT_FOUND", "Insight not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get insight", 500, err, nil)
+	}
+	return &insight, nil
+}
+
+func (r *insightMongoRepository) Save(ctx context.Context, insight *domain.ReportInsight) error {
+	update := bson.M{
+		"$set": bson.M{
+			"company":     insight.Company,
+			"version":     insight.Version,
+			"model":       insight.Model,
+			"summary":     insight.Summary,
+			"anomalies":   insight.Anomalies,
+			"forecasts":   insight.Forecasts,
+			"generatedBy": insight.GeneratedBy,
+			"generatedAt": insight.GeneratedAt,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved domain.ReportInsight
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"report": insight.Report}, update, opts).Decode(&saved); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save insight", 500, err, nil)
+	}
+
+	insight.ID = saved.ID
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type mongoReference struct {
+	collection string
+	field      string
+	target     string
+	list       bool
+}
+
+var mongoReferences = map[domain.ReferenceCheck]mongoReference{
+	domain.CheckReportCompany:    {collection: "reports", field: "company", target: "companies"},
+	domain.CheckReportType:       {collection: "reports", field: "reportType", target: "reporttypes"},
+	domain.CheckReportCreatedBy:  {collection: "reports", field: "createdBy", target: "users"},
+	domain.CheckReportUserAccess: {collection: "reports", field: "userAccess", target: "users", list: true},
+	domain.CheckUserCompany:      {collection: "users", field: "company", target: "companies", list: true},
+	domain.CheckCompanyUser:      {collection: "companies", field: "user", target: "users", list: true},
+}
+
+type integrityMongoRepository struct {
+	db *mongo.Database
+}
+
+func NewIntegrityMongoRepository(db *mongo.Database) domain.IntegrityRepository {
+	return &integrityMongoRepository{db: db}
+}
+
+func (r *integrityMongoRepository) FindOrphans(ctx context.Context, check domain.ReferenceCheck) ([]domain.OrphanReference, error) {
+	ref, ok := mongoReferences[check]
+	if !ok {
+		return nil, errors.New("INVALID_REFERENCE_CHECK", "Unknown reference check", 400, nil, map[string]interface{}{"check": check})
+	}
+
+	// Soft-deleted sources are ignored; soft-deleted targets count as orphans.
+	pipeline := []bson.M{
+		{"$match": bson.M{"deletedAt": nil, ref.field: bson.M{"$ne": nil}}},
+		{"$project": bson.M{ref.field: 1}},
+	}
+	if ref.list {
+		pipeline = append(pipeline, bson.M{"$unwind": "$" + ref.field})
+	}
+	pipeline = append(pipeline,
+		bson.M{"$lookup": bson.M{
+			"from":         config.CollectionName(ref.target),
+			"localField":   ref.field,
+			"foreignField": "_id",
+			"as":           "target",
+			"pipeline":     []bson.M{{"$project": bson.M{"deletedAt": 1}}},
+		}},
+		bson.M{"$project": bson.M{
+			"referenceId": "$" + ref.field,
+			"target":      bson.M{"$arrayElemAt": []interface{}{"$target", 0}},
+		}},
+		bson.M{"$match": bson.M{"$or": []bson.M{
+			{"target": bson.M{"$exists": false}},
+			{"target.deletedAt": bson.M{"$ne": nil}},
+		}}},
+	)
+
+	cursor, err := r.db.Collection(config.CollectionName(ref.collection)).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to scan references", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID          primitive.ObjectID `bson:"_id"`
+		ReferenceID primitive.ObjectID `bson:"referenceId"`
+		Target      *bson.M            `bson:"target"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode references", 500, err, nil)
+	}
+
+	orphans := make([]domain.OrphanReference, 0, len(rows))
+	for _, row := range rows {
+		orphans = append(orphans, domain.OrphanReference{
+			Check:       check,
+			DocumentID:  row.ID,
+			ReferenceID: row.ReferenceID,
+			Deleted:     row.Target != nil,
+		})
+	}
+
+	return orphans, nil
+}
+
+func (r *integrityMongoRepository) RemoveReference(ctx context.Context, orphan domain.OrphanReference) error {
+	ref, ok := mongoReferences[orphan.Check]
+	if !ok || !ref.list {
+		return errors.New("NOT_REPAIRABLE", "Reference cannot be repaired automatically", 400, nil, map[string]interface{}{"check": orphan.Check})
+	}
+
+	update := bson.M{"$pull": bson.M{ref.field: orphan.ReferenceID}}
+	if _, err := r.db.Collection(config.CollectionName(ref.collection)).UpdateOne(ctx, bson.M{"_id": orphan.DocumentID}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to remove reference", 500, err, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type pgReference struct {
+	table  string
+	column string
+	target string
+	list   bool
+}
+
+var pgReferences = map[domain.ReferenceCheck]pgReference{
+	domain.CheckReportCompany:    {table: "reports", column: "company", target: "companies"},
+	domain.CheckReportType:       {table: "reports", column: "report_type", target: "report_types"},
+	domain.CheckReportCreatedBy:  {table: "reports", column: "created_by", target: "users"},
+	domain.CheckReportUserAccess: {table: "reports", column: "user_access", target: "users", list: true},
+	domain.CheckUserCompany:      {table: "users", column: "company", target: "companies", list: true},
+	domain.CheckCompanyUser:      {table: "companies", column: "users", target: "users", list: true},
+}
+
+type integrityPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewIntegrityPostgresRepository(db *sql.DB) domain.IntegrityRepository {
+	return &integrityPostgresRepository{db: db}
+}
+
+func (r *integrityPostgresRepository) FindOrphans(ctx context.Context, check domain.ReferenceCheck) ([]domain.OrphanReference, error) {
+	ref, ok := pgReferences[check]
+	if !ok {
+		return nil, errors.New("INVALID_REFERENCE_CHECK", "Unknown reference check", 400, nil, map[string]interface{}{"check": check})
+	}
+
+	// Soft-deleted sources are ignored; soft-deleted targets count as orphans.
+	refExpr := "s." + ref.column
+	from := ref.table + " s"
+	if ref.list {
+		refExpr = "e.value"
+		from += " CROSS JOIN LATERAL jsonb_array_elements_text(s." + ref.column + ") e"
+	}
+
+	rows, err := pgConn(ctx, r.db).QueryContext(ctx, `SELECT s.id, `+refExpr+`, t.id IS NOT NULL
+		FROM `+from+`
+		LEFT JOIN `+ref.target+` t ON t.id = `+refExpr+`
+		WHERE s.deleted_at IS NULL AND (t.id IS NULL OR t.deleted_at IS NOT NULL)`)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to scan references", 500, err, nil)
+	}
+	defer rows.Close()
+
+	var orphans []domain.OrphanReference
+	for rows.Next() {
+		var (
+			id, referenceID string
+			deleted         bool
+		)
+		if err := rows.Scan(&id, &referenceID, &deleted); err != nil {
+			return nil, errors.New("DATABASE_ERROR", "Failed to decode references", 500, err, nil)
+		}
+		orphans = append(orphans, domain.OrphanReference{
+			Check:       check,
+			DocumentID:  parseID(id),
+			ReferenceID: parseID(referenceID),
+			Deleted:     deleted,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to scan references", 500, err, nil)
+	}
+
+	return orphans, nil
+}
+
+func (r *integrityPostgresRepository) RemoveReference(ctx context.Context, orphan domain.OrphanReference) error {
+	ref, ok := pgReferences[orphan.Check]
+	if !ok || !ref.list {
+		return errors.New("NOT_REPAIRABLE", "Reference cannot be repaired automatically", 400, nil, map[string]interface{}{"check": orphan.Check})
+	}
+
+	_, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE `+ref.table+` SET `+ref.column+` = `+ref.column+` - $2::text WHERE id = $1`,
+		orphan.DocumentID.Hex(), orphan.ReferenceID.Hex())
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to remove reference", 500, err, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type invitationMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInvitationMongoRepository(db *mongo.Database) domain.InvitationRepository {
+	return &invitationMongoRepository{
+		collection: db.Collection(config.CollectionName("invitations")),
+	}
+}
+
+func (r *invitationMongoRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	invitation.CreatedAt = time.Now()
+	invitation.UpdatedAt = invitation.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, invitation)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create invitation", 500, err, nil)
+	}
+
+	invitation.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *invitationMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Invitation, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *invitationMongoRepository) GetByUser(ctx context.Context, user primitive.ObjectID) (*domain.Invitation, error) {
+	return r.findOne(ctx, bson.M{"user": user})
+}
+
+func (r *invitationMongoRepository) findOne(ctx context.Context, filter bson.M) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	if err := r.collection.FindOne(ctx, filter).Decode(&invitation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("INVITATION_NOT_FOUND", "Invitation not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get invitation", 500, err, nil)
+	}
+	return &invitation, nil
+}
+
+func (r *invitationMongoRepository) GetPage(ctx context.Context, pending bool, skip, limit int) ([]*domain.Invitation, int, error) {
+	filter := bson.M{}
+	if pending {
+		filter["acceptedAt"] = bson.M{"$exists": false}
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count invitations", 500, err, nil)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to get invitations", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	invitations := []*domain.Invitation{}
+	if err = cursor.All(ctx, &invitations); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to decode invitations", 500, err, nil)
+	}
+
+	return invitations, int(total), nil
+}
+
+func (r *invitationMongoRepository) Update(ctx context.Context, invitation *domain.Invitation) error {
+	invitation.UpdatedAt = time.Now()
+
+	set := bson.M{
+		"sends":     invitation.Sends,
+		"expiresAt": invitation.ExpiresAt,
+		"updatedAt": invitation.UpdatedAt,
+	}
+	if invitation.AcceptedAt != nil {
+		set["acceptedAt"] = invitation.AcceptedAt
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": invitation.ID}, bson.M{"$set": set})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update invitation", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("INVITATION_NOT_FOUND", "Invitation not found", 404, nil, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type kpiMongoRepository struct {
+	kpis   *mongo.Collection
+	values *mongo.Collection
+}
+
+func NewKPIMongoRepository(db *mongo.Database) domain.KPIRepository {
+	return &kpiMongoRepository{
+		kpis:   db.Collection(config.CollectionName("kpis")),
+		values: db.Collection(config.CollectionName("kpi_values")),
+	}
+}
+
+// Create stores the KPI in the organization it is stamped with, which must be the tenant's.
+func (r *kpiMongoRepository) Create(ctx context.Context, kpi *domain.KPI) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.Owns(kpi.Organization) {
+		return errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", 404, nil, nil)
+	}
+
+	kpi.CreatedAt = time.Now()
+	kpi.UpdatedAt = time.Now()
+
+	result, err := r.kpis.InsertOne(ctx, kpi)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create KPI", 500, err, nil)
+	}
+
+	kpi.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *kpiMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.KPI, error) {
+	var kpi domain.KPI
+	if err := r.kpis.FindOne(ctx, tenantFilter(ctx, bson.M{"_id": id})).Decode(&kpi); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("KPI_NOT_FOUND", "KPI not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get KPI", 500, err, nil)
+	}
+	return &kpi, nil
+}
+
+func (r *kpiMongoRepository) GetAll(ctx context.Context) ([]*domain.KPI, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.kpis.Find(ctx, tenantFilter(ctx, bson.M{}), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get KPIs", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	kpis := []*domain.KPI{}
+	if err = cursor.All(ctx, &kpis); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode KPIs", 500, err, nil)
+	}
+
+	return kpis, nil
+}
+
+func (r *kpiMongoRepository) Update(ctx context.Context, id primitive.ObjectID, kpi *domain.KPI) error {
+	kpi.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":        kpi.Name,
+			"description": kpi.Description,
+			"formula":     kpi.Formula,
+			"unit":        kpi.Unit,
+			"reportType":  kpi.ReportType,
+			"updatedAt":   kpi.UpdatedAt,
+		},
+	}
+
+	result, err := r.kpis.UpdateOne(ctx, tenantFilter(ctx, bson.M{"_id": id}), update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update KPI", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("KPI_NOT_FOUND", "KPI not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *kpiMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.kpis.DeleteOne(ctx, tenantFilter(ctx, bson.M{"_id": id}))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete KPI", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("KPI_NOT_FOUND", "KPI not found", 404, nil, nil)
+	}
+
+	if _, err := r.values.DeleteMany(ctx, bson.M{"kpi": id}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete KPI values", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *kpiMongoRepository) SaveValue(ctx context.Context, value *domain.KPIValue) error {
+	filter := bson.M{"kpi": value.KPI, "report": value.Report}
+	update := bson.M{
+		"$set": bson.M{
+			"company":    value.Company,
+			"year":       value.Year,
+			"value":      value.Value,
+			"computedAt": value.ComputedAt,
+		},
+	}
+
+	if _, err := r.values.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save KPI value", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *kpiMongoRepository) DeleteValues(ctx context.Context, report primitive.ObjectID, kpi *primitive.ObjectID) error {
+	filter := bson.M{"report": report}
+	if kpi != nil {
+		filter["kpi"] = *kpi
+	}
+
+	if _, err := r.values.DeleteMany(ctx, filter); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete KPI values", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *kpiMongoRepository) GetValues(ctx context.Context, company primitive.ObjectID, kpi *primitive.ObjectID) ([]*domain.KPIValue, error) {
+	filter := bson.M{"company": company}
+	if kpi != nil {
+		filter["kpi"] = *kpi
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "year", Value: 1}, {Key: "computedAt", Value: 1}})
+
+	cursor, err := r.values.Find(ctx, companyDataFilter(ctx, filter), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get KPI values", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	values := []*domain.KPIValue{}
+	if err = cursor.All(ctx, &values); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode KPI values", 500, err, nil)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type ledgerMongoRepository struct {
+	charts        *mongo.Collection
+	trialBalances *mongo.Collection
+}
+
+func NewLedgerMongoRepository(db *mongo.Database) domain.LedgerRepository {
+	return &ledgerMongoRepository{
+		charts:        db.Collection(config.CollectionName("charts_of_accounts")),
+		trialBalances: db.Collection(config.CollectionName("trial_balances")),
+	}
+}
+
+func (r *ledgerMongoRepository) GetChart(ctx context.Context, company primitive.ObjectID) (*domain.ChartOfAccounts, error) {
+	var chart domain.ChartOfAccounts
+	if err := r.charts.FindOne(ctx, companyDataFilter(ctx, bson.M{"company": company})).Decode(&chart); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("CHART_OF_ACCOUNTS_NOT_FOUND", "Chart of accounts not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get chart of accounts", 500, err, nil)
+	}
+	return &chart, nil
+}
+
+// SaveChart replaces the company's chart of accounts, creating it on first save. The template
+// it was copied from is only replaced by another.
+func (r *ledgerMongoRepository) SaveChart(ctx context.Context, chart *domain.ChartOfAccounts) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(chart.Company) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	chart.UpdatedAt = time.Now()
+
+	set := bson.M{
+		"accounts":  chart.Accounts,
+		"updatedBy": chart.UpdatedBy,
+		"updatedAt": chart.UpdatedAt,
+	}
+	if chart.Template != nil {
+		set["template"] = chart.Template
+	}
+	update := bson.M{"$set": set}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved domain.ChartOfAccounts
+	if err := r.charts.FindOneAndUpdate(ctx, bson.M{"company": chart.Company}, update, opts).Decode(&saved); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save chart of accounts", 500, err, nil)
+	}
+
+	chart.ID = saved.ID
+	chart.Template = saved.Template
+	return nil
+}
+
+func (r *ledgerMongoRepository) CreateTrialBalance(ctx context.Context, trialBalance *domain.TrialBalance) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(trialBalance.Company) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
+	trialBalance.CreatedAt = time.Now()
+	if trialBalance.Reports == nil {
+		trialBalance.Reports = []primitive.ObjectID{}
+	}
+
+	result, err := r.trialBalances.InsertOne(ctx, trialBalance)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create trial balance", 500, err, nil)
+	}
+
+	trialBalance.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ledgerMongoRepository) GetTrialBalance(ctx context.Context, id primitive.ObjectID) (*domain.TrialBalance, error) {
+	var trialBalance domain.TrialBalance
+	if err := r.trialBalances.FindOne(ctx, companyDataFilter(ctx, bson.M{"_id": id})).Decode(&trialBalance); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("TRIAL_BALANCE_NOT_FOUND", "Trial balance not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get trial balance", 500, err, nil)
+	}
+	return &trialBalance, nil
+}
+
+func (r *ledgerMongoRepository) GetTrialBalances(ctx context.Context, company *primitive.ObjectID) ([]*domain.TrialBalance, error) {
+	filter := bson.M{}
+	if company != nil {
+		filter["company"] = *company
+	}
+	// Lines are left out of lists; fetch one trial balance for them
+	opts := options.Find().
+		SetSort(bson.D{{Key: "company", Value: 1}, {Key: "createdAt", Value: -1}}).
+		SetProjection(bson.M{"lines": 0})
+
+	cursor, err := r.trialBalances.Find(ctx, companyDataFilter(ctx, filter), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get trial balances", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	trialBalances := []*domain.TrialBalance{}
+	if err = cursor.All(ctx, &trialBalances); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode trial balances", 500, err, nil)
+	}
+
+	return trialBalances, nil
+}
+
+func (r *ledgerMongoRepository) SetReports(ctx context.Context, id primitive.ObjectID, reports []primitive.ObjectID) error {
+	_, err := r.trialBalances.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"reports": reports}})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update trial balance", 500, err, nil)
+	}
+	return nil
+}
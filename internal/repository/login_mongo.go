@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type loginMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLoginMongoRepository(db *mongo.Database) domain.LoginRepository {
+	return &loginMongoRepository{
+		collection: db.Collection(config.CollectionName("logins")),
+	}
+}
+
+func (r *loginMongoRepository) Create(ctx context.Context, login *domain.Login) error {
+	login.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, login)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to record login", 500, err, nil)
+	}
+
+	login.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *loginMongoRepository) GetRecent(ctx context.Context, userID primitive.ObjectID, limit int) ([]*domain.Login, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(int64(limit))
+	filter := bson.M{"userId": userID, "outcome": bson.M{"$ne": domain.LoginFailed}}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get logins", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var logins []*domain.Login
+	if err := cursor.All(ctx, &logins); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode logins", 500, err, nil)
+	}
+
+	return logins, nil
+}
+
+func (r *loginMongoRepository) GetByUser(ctx context.Context, userID primitive.ObjectID, skip, limit int) ([]*domain.Login, int, error) {
+	filter := bson.M{"userId": userID}
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count logins", 500, err, nil)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetSkip(int64(skip)).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to get logins", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	logins := []*domain.Login{}
+	if err := cursor.All(ctx, &logins); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to decode logins", 500, err, nil)
+	}
+
+	return logins, int(total), nil
+}
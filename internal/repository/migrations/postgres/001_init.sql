@@ -0,0 +1,75 @@
+-- Initial schema mirroring the MongoDB collections.
+-- IDs are 24-char hex ObjectIDs so the domain layer works unchanged across drivers.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                     CHAR(24) PRIMARY KEY,
+    name                   TEXT NOT NULL,
+    email                  TEXT NOT NULL UNIQUE,
+    password               TEXT NOT NULL,
+    role                   TEXT NOT NULL,
+    company                JSONB NOT NULL DEFAULT '[]',
+    reset_password_token   TEXT,
+    reset_password_expires TIMESTAMPTZ,
+    created_at             TIMESTAMPTZ NOT NULL,
+    updated_at             TIMESTAMPTZ NOT NULL,
+    deleted_at             TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS users_reset_password_token_idx ON users (reset_password_token) WHERE reset_password_token IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_company_idx ON users USING GIN (company);
+
+CREATE TABLE IF NOT EXISTS report_types (
+    id         CHAR(24) PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS companies (
+    id              CHAR(24) PRIMARY KEY,
+    name            TEXT NOT NULL,
+    profile_picture TEXT,
+    users           JSONB NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    deleted_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS companies_name_idx ON companies (name);
+CREATE INDEX IF NOT EXISTS companies_users_idx ON companies USING GIN (users);
+
+CREATE TABLE IF NOT EXISTS reports (
+    id          CHAR(24) PRIMARY KEY,
+    report_name TEXT NOT NULL,
+    report_type CHAR(24) NOT NULL,
+    year        INTEGER NOT NULL,
+    company     CHAR(24) NOT NULL,
+    currency    TEXT,
+    created_by  CHAR(24) NOT NULL,
+    user_access JSONB NOT NULL DEFAULT '[]',
+    report_data JSONB NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL,
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS reports_company_idx ON reports (company);
+CREATE INDEX IF NOT EXISTS reports_report_type_idx ON reports (report_type);
+CREATE INDEX IF NOT EXISTS reports_created_by_idx ON reports (created_by);
+CREATE INDEX IF NOT EXISTS reports_report_name_idx ON reports (report_name);
+CREATE INDEX IF NOT EXISTS reports_created_at_idx ON reports (created_at DESC);
+CREATE INDEX IF NOT EXISTS reports_user_access_idx ON reports USING GIN (user_access);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id              CHAR(24) PRIMARY KEY,
+    type            TEXT NOT NULL,
+    aggregate_id    CHAR(24) NOT NULL,
+    payload         BYTEA NOT NULL,
+    status          TEXT NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    dispatched_at   TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (status, next_attempt_at);
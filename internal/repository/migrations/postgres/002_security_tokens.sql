@@ -0,0 +1,19 @@
+-- Expiring security tokens (password resets, invitations, share links, sessions)
+-- move out of the users table. Expired rows are purged by the application.
+
+CREATE TABLE IF NOT EXISTS security_tokens (
+    id         CHAR(24) PRIMARY KEY,
+    kind       TEXT NOT NULL,
+    token      TEXT NOT NULL,
+    user_id    CHAR(24) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS security_tokens_kind_token_idx ON security_tokens (kind, token);
+CREATE INDEX IF NOT EXISTS security_tokens_user_idx ON security_tokens (kind, user_id);
+CREATE INDEX IF NOT EXISTS security_tokens_expires_at_idx ON security_tokens (expires_at);
+
+DROP INDEX IF EXISTS users_reset_password_token_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS reset_password_token;
+ALTER TABLE users DROP COLUMN IF EXISTS reset_password_expires;
@@ -0,0 +1,3 @@
+-- Preferred language for user-facing emails; empty means the default locale.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Per-user settings sub-document (notification opt-outs, ...); empty means all defaults.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferences JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+-- E.164 phone number for SMS/WhatsApp notifications; empty means email only.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone TEXT NOT NULL DEFAULT '';
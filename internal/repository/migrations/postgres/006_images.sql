@@ -0,0 +1,5 @@
+-- Processed logo and avatar variants; the full-size company logo stays in profile_picture.
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS profile_picture_thumb TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_thumb TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Tokens issued before sessions_revoked_at are rejected, e.g. after a "this wasn't me" login alert.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Terms of service and privacy policy versions each user accepted, with when and from where.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS consents JSONB NOT NULL DEFAULT '[]';
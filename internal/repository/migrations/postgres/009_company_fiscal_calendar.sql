@@ -0,0 +1,3 @@
+-- Fiscal calendar of a company (start month, period pattern); NULL means calendar years.
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS fiscal_calendar JSONB;
@@ -0,0 +1,3 @@
+-- Tax jurisdiction of a company, e.g. ID or US-CA; NULL when not set.
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS jurisdiction TEXT;
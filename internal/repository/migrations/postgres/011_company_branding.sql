@@ -0,0 +1,4 @@
+-- Branding of the documents rendered for a company (logo placement, colors, footer, locale);
+-- NULL for the default look.
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS branding JSONB;
@@ -0,0 +1,4 @@
+-- Where the data of each report came from (manual entry, API key, trial balance, import or AI
+-- task); NULL for reports written before it was recorded.
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS lineage JSONB;
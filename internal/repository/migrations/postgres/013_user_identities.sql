@@ -0,0 +1,3 @@
+-- Accounts of identity providers, such as Google, that users sign in with.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS identities JSONB NOT NULL DEFAULT '[]';
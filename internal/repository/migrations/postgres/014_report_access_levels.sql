@@ -0,0 +1,4 @@
+-- Levels (VIEW, EDIT, ADMIN) of the users in user_access, as [{"user": id, "level": level}];
+-- users without an entry can view.
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS access_levels JSONB NOT NULL DEFAULT '[]';
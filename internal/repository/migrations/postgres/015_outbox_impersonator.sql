@@ -0,0 +1,3 @@
+-- The super admin whose impersonated request caused the event, for the audit log.
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS impersonator CHAR(24);
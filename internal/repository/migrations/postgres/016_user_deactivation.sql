@@ -0,0 +1,3 @@
+-- Deactivated users keep their row, and the reports they created, but can no longer log in.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+-- Emails and report type names only need to be unique among rows that aren't soft-deleted,
+-- so a deleted user or report type can be created again.
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_live_idx ON users (email) WHERE deleted_at IS NULL;
+
+ALTER TABLE report_types DROP CONSTRAINT IF EXISTS report_types_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS report_types_name_live_idx ON report_types (name) WHERE deleted_at IS NULL;
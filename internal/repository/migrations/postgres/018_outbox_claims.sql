@@ -0,0 +1,7 @@
+-- Dispatchers lock the outbox events they claim, so that several instances don't deliver the
+-- same event, and remember which webhook URLs an event reached so retries skip them.
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS delivered_to JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS outbox_locked_idx ON outbox (status, locked_until);
@@ -0,0 +1,3 @@
+-- Reports record who approved their figures and when, as JSON; NULL until approved.
+
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS approval JSONB;
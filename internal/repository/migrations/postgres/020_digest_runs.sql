@@ -0,0 +1,11 @@
+-- The digest schedule is shared by every instance: when the last digest started, and until
+-- when an instance holds the lease of the one being sent. Each digest reads the reports of
+-- companies changed since the last one.
+
+CREATE TABLE IF NOT EXISTS digest_runs (
+    id           TEXT PRIMARY KEY,
+    last_run_at  TIMESTAMPTZ,
+    locked_until TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS reports_company_updated_at_idx ON reports (company, updated_at);
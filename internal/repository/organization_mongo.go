@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type organizationMongoRepository struct {
+	collection *mongo.Collection
+	companies  *mongo.Collection
+	users      *mongo.Collection
+}
+
+func NewOrganizationMongoRepository(db *mongo.Database) domain.OrganizationRepository {
+	return &organizationMongoRepository{
+		collection: db.Collection(config.CollectionName("organizations")),
+		companies:  db.Collection(config.CollectionName("companies")),
+		users:      db.Collection(config.CollectionName("users")),
+	}
+}
+
+func (r *organizationMongoRepository) Create(ctx context.Context, organization *domain.Organization) error {
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = time.Now()
+	if organization.Admins == nil {
+		organization.Admins = []primitive.ObjectID{}
+	}
+
+	result, err := r.collection.InsertOne(ctx, organization)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create organization", 500, err, nil)
+	}
+
+	organization.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *organizationMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Organization, error) {
+	var organization domain.Organization
+	if err := r.collection.FindOne(ctx, r.readFilter(ctx, bson.M{"_id": id})).Decode(&organization); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get organization", 500, err, nil)
+	}
+	return &organization, nil
+}
+
+func (r *organizationMongoRepository) GetAll(ctx context.Context) ([]*domain.Organization, error) {
+	cursor, err := r.collection.Find(ctx, r.readFilter(ctx, bson.M{}), options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get organizations", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	organizations := []*domain.Organization{}
+	if err = cursor.All(ctx, &organizations); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode organizations", 500, err, nil)
+	}
+
+	return organizations, nil
+}
+
+// readFilter limits requests with a tenant to its organization.
+func (r *organizationMongoRepository) readFilter(ctx context.Context, filter bson.M) bson.M {
+	filter = scopeFilter(ctx, filter)
+	tenant := domain.TenantOf(ctx)
+	if tenant == nil {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, {"_id": tenant.Organization}}}
+}
+
+func (r *organizationMongoRepository) Update(ctx context.Context, id primitive.ObjectID, organization *domain.Organization) error {
+	organization.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":      organization.Name,
+			"admins":    organization.Admins,
+			"settings":  organization.Settings,
+			"updatedAt": organization.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.readFilter(ctx, bson.M{"_id": id}), update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update organization", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *organizationMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := softDelete(ctx, r.collection, bson.M{"_id": id})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete organization", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *organizationMongoRepository) Companies(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return r.memberIDs(ctx, r.companies, id)
+}
+
+func (r *organizationMongoRepository) Users(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return r.memberIDs(ctx, r.users, id)
+}
+
+func (r *organizationMongoRepository) memberIDs(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := collection.Find(ctx, scopeFilter(ctx, organizationFilter(id)), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get organization members", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var members []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &members); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode organization members", 500, err, nil)
+	}
+
+	ids := make([]primitive.ObjectID, len(members))
+	for i, member := range members {
+		ids[i] = member.ID
+	}
+	return ids, nil
+}
+
+func (r *organizationMongoRepository) SetCompanyOrganization(ctx context.Context, companyID primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	return r.setOrganization(ctx, r.companies, companyID, organizationID,
+		errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil))
+}
+
+func (r *organizationMongoRepository) SetUserOrganization(ctx context.Context, userID primitive.ObjectID, organizationID *primitive.ObjectID) error {
+	return r.setOrganization(ctx, r.users, userID, organizationID,
+		errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil))
+}
+
+func (r *organizationMongoRepository) setOrganization(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, organizationID *primitive.ObjectID, notFound error) error {
+	update := bson.M{"$unset": bson.M{"organization": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if organizationID != nil {
+		update = bson.M{"$set": bson.M{"organization": *organizationID, "updatedAt": time.Now()}}
+	}
+
+	result, err := collection.UpdateOne(ctx, scopeFilter(ctx, bson.M{"_id": id}), update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to change organization", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+type outboxMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOutboxMongoRepository(db *mongo.Database) domain.OutboxRepository {
+	return &outboxMongoRepository{
+		collection: db.Collection(config.CollectionName("outbox")),
+	}
+}
+
+func (r *outboxMongoRepository) Append(ctx context.Context, event *domain.Event) error {
+	if event.Impersonator == nil {
+		event.Impersonator = domain.ImpersonatorOf(ctx)
+	}
+	result, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to append outbox event", 500, err, nil)
+	}
+
+	event.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Claim claims the oldest due event with a single findAndModify, which one instance at most
+// wins.
+func (r *outboxMongoRepository) Claim(ctx context.Context, lockedUntil time.Time) (*domain.Event, error) {
+	now := time.Now()
+	filter := bson.M{"$or": []bson.M{
+		{"status": domain.EventStatusPending, "nextAttemptAt": bson.M{"$lte": now}},
+		{"status": domain.EventStatusProcessing, "lockedUntil": bson.M{"$lt": now}},
+	}}
+	update := bson.M{"$set": bson.M{
+		"status":      domain.EventStatusProcessing,
+		"lockedUntil": lockedUntil,
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var event domain.Event
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&event); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to claim outbox event", 500, err, nil)
+	}
+	return &event, nil
+}
+
+func (r *outboxMongoRepository) CountPending(ctx context.Context) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": bson.M{"$in": []domain.EventStatus{domain.EventStatusPending, domain.EventStatusProcessing}}})
+	if err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to count pending outbox events", 500, err, nil)
+	}
+	return count, nil
+}
+
+func (r *outboxMongoRepository) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       domain.EventStatusDispatched,
+			"dispatchedAt": time.Now(),
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"lastError": "", "lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark outbox event dispatched", 500, err, nil)
+	}
+	return nil
+}
+
+// MarkFailed records a delivery failure. Non-final failures stay pending and are retried at nextAttemptAt.
+func (r *outboxMongoRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, deliveredTo []string, nextAttemptAt time.Time, final bool) error {
+	status := domain.EventStatusPending
+	if final {
+		status = domain.EventStatusFailed
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":        status,
+			"lastError":     reason,
+			"deliveredTo":   deliveredTo,
+			"nextAttemptAt": nextAttemptAt,
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark outbox event failed", 500, err, nil)
+	}
+	return nil
+}
+
+// OutboxListener receives the events watched by WatchOutbox.
+type OutboxListener interface {
+	// SetStreaming is called with true once the change stream is open and with false when it stops
+	SetStreaming(on bool)
+	Broadcast(event *domain.Event)
+}
+
+// WatchOutbox hands every event appended to the outbox, by any instance, to the listener as
+// soon as its transaction commits. It returns when ctx is cancelled or change streams are
+// unavailable.
+func WatchOutbox(ctx context.Context, db *mongo.Database, listener OutboxListener) {
+	defer listener.SetStreaming(false)
+
+	collection := db.Collection(config.CollectionName("outbox"))
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+	}
+
+	for ctx.Err() == nil {
+		stream, err := collection.Watch(ctx, pipeline, options.ChangeStream().SetMaxAwaitTime(time.Second))
+		if err != nil {
+			log.Warnf(ctx, "Outbox watcher: change streams unavailable, relying on the dispatcher: %v", err)
+			return
+		}
+		listener.SetStreaming(true)
+
+		for stream.Next(ctx) {
+			var change struct {
+				FullDocument domain.Event `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err != nil {
+				log.Warnf(ctx, "Outbox watcher: skipping undecodable event: %v", err)
+				continue
+			}
+			listener.Broadcast(&change.FullDocument)
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Warnf(ctx, "Outbox watcher: change stream interrupted, restarting: %v", err)
+			listener.SetStreaming(false)
+			time.Sleep(time.Second)
+		}
+		stream.Close(context.Background())
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type outboxPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxPostgresRepository(db *sql.DB) domain.OutboxRepository {
+	return &outboxPostgresRepository{db: db}
+}
+
+func (r *outboxPostgresRepository) Append(ctx context.Context, event *domain.Event) error {
+	event.ID = primitive.NewObjectID()
+	if event.Impersonator == nil {
+		event.Impersonator = domain.ImpersonatorOf(ctx)
+	}
+	var impersonator *string
+	if event.Impersonator != nil {
+		hex := event.Impersonator.Hex()
+		impersonator = &hex
+	}
+
+	_, err := pgConn(ctx, r.db).ExecContext(ctx, `INSERT INTO outbox
+			(id, type, aggregate_id, payload, status, attempts, last_error, next_attempt_at, dispatched_at, impersonator, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		event.ID.Hex(), string(event.Type), event.AggregateID.Hex(), event.Payload, string(event.Status),
+		event.Attempts, event.LastError, event.NextAttemptAt, event.DispatchedAt, impersonator, event.CreatedAt)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to append outbox event", 500, err, nil)
+	}
+
+	return nil
+}
+
+// Claim claims the oldest due event; SKIP LOCKED leaves events another instance is claiming
+// to the next call.
+func (r *outboxPostgresRepository) Claim(ctx context.Context, lockedUntil time.Time) (*domain.Event, error) {
+	var (
+		event             domain.Event
+		id, aggregateID   string
+		eventType, status string
+		deliveredTo       []byte
+	)
+	err := pgConn(ctx, r.db).QueryRowContext(ctx, `UPDATE outbox SET status = $1, locked_until = $2
+		WHERE id = (
+			SELECT id FROM outbox
+			WHERE (status = $3 AND next_attempt_at <= now()) OR (status = $1 AND locked_until < now())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, type, aggregate_id, payload, status, attempts, last_error, next_attempt_at, dispatched_at,
+			locked_until, delivered_to, created_at`,
+		string(domain.EventStatusProcessing), lockedUntil, string(domain.EventStatusPending)).
+		Scan(&id, &eventType, &aggregateID, &event.Payload, &status, &event.Attempts, &event.LastError,
+			&event.NextAttemptAt, &event.DispatchedAt, &event.LockedUntil, &deliveredTo, &event.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to claim outbox event", 500, err, nil)
+	}
+
+	event.ID = parseID(id)
+	event.AggregateID = parseID(aggregateID)
+	event.Type = domain.EventType(eventType)
+	event.Status = domain.EventStatus(status)
+	if err := json.Unmarshal(deliveredTo, &event.DeliveredTo); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode outbox event", 500, err, nil)
+	}
+	return &event, nil
+}
+
+func (r *outboxPostgresRepository) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	if err := pgConn(ctx, r.db).QueryRowContext(ctx, `SELECT count(*) FROM outbox WHERE status IN ($1, $2)`,
+		string(domain.EventStatusPending), string(domain.EventStatusProcessing)).Scan(&count); err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to count pending outbox events", 500, err, nil)
+	}
+	return count, nil
+}
+
+func (r *outboxPostgresRepository) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	_, err := pgConn(ctx, r.db).ExecContext(ctx, `UPDATE outbox SET
+			status = $2, dispatched_at = now(), attempts = attempts + 1, last_error = NULL, locked_until = NULL
+		WHERE id = $1`, id.Hex(), string(domain.EventStatusDispatched))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark outbox event dispatched", 500, err, nil)
+	}
+	return nil
+}
+
+// MarkFailed records a delivery failure. Non-final failures stay pending and are retried at nextAttemptAt.
+func (r *outboxPostgresRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, deliveredTo []string, nextAttemptAt time.Time, final bool) error {
+	status := domain.EventStatusPending
+	if final {
+		status = domain.EventStatusFailed
+	}
+	if deliveredTo == nil {
+		deliveredTo = []string{}
+	}
+	delivered, err := json.Marshal(deliveredTo)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark outbox event failed", 500, err, nil)
+	}
+
+	_, err = pgConn(ctx, r.db).ExecContext(ctx, `UPDATE outbox SET
+			status = $2, last_error = $3, delivered_to = $4, next_attempt_at = $5, attempts = attempts + 1, locked_until = NULL
+		WHERE id = $1`, id.Hex(), string(status), reason, delivered, nextAttemptAt)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark outbox event failed", 500, err, nil)
+	}
+	return nil
+}
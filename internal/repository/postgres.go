@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// MigratePostgres applies pending SQL migrations in filename order.
+func MigratePostgres(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return errors.New("MIGRATION_ERROR", "Failed to create schema_migrations table", 500, err, nil)
+	}
+
+	entries, err := postgresMigrations.ReadDir("migrations/postgres")
+	if err != nil {
+		return errors.New("MIGRATION_ERROR", "Failed to read migrations", 500, err, nil)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		version := strings.TrimSuffix(entry.Name(), ".sql")
+
+		var exists bool
+		if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&exists); err != nil {
+			return errors.New("MIGRATION_ERROR", "Failed to check migration "+version, 500, err, nil)
+		}
+		if exists {
+			continue
+		}
+
+		script, err := postgresMigrations.ReadFile("migrations/postgres/" + entry.Name())
+		if err != nil {
+			return errors.New("MIGRATION_ERROR", "Failed to read migration "+version, 500, err, nil)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return errors.New("MIGRATION_ERROR", "Failed to start migration "+version, 500, err, nil)
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return errors.New("MIGRATION_ERROR", "Failed to apply migration "+version, 500, err, nil)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback()
+			return errors.New("MIGRATION_ERROR", "Failed to record migration "+version, 500, err, nil)
+		}
+		if err := tx.Commit(); err != nil {
+			return errors.New("MIGRATION_ERROR", "Failed to commit migration "+version, 500, err, nil)
+		}
+
+		log.Infof(ctx, "Applied Postgres migration %s", version)
+	}
+
+	return nil
+}
+
+type pgTxKey struct{}
+
+// pgQuerier is the subset of *sql.DB and *sql.Tx the repositories use.
+type pgQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// pgConn returns the transaction bound to ctx by the Postgres transactor, or db otherwise.
+func pgConn(ctx context.Context, db *sql.DB) pgQuerier {
+	if tx, ok := ctx.Value(pgTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// pgNotDeleted returns the soft-delete condition for a table alias unless the context asks for deleted rows.
+func pgNotDeleted(ctx context.Context, alias string) string {
+	if domain.IncludesDeleted(ctx) {
+		return "TRUE"
+	}
+	if alias != "" {
+		return alias + ".deleted_at IS NULL"
+	}
+	return "deleted_at IS NULL"
+}
+
+// isUniqueViolation detects Postgres unique constraint errors without depending on a specific driver.
+func isUniqueViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "23505") || strings.Contains(msg, "duplicate key value")
+}
+
+// rowsAffected reports whether a write touched any row.
+func rowsAffected(result sql.Result) bool {
+	n, err := result.RowsAffected()
+	return err == nil && n > 0
+}
+
+// encodeIDs stores an ObjectID slice as a JSON array of hex strings.
+func encodeIDs(ids []primitive.ObjectID) []byte {
+	hexIDs := make([]string, len(ids))
+	for i, id := range ids {
+		hexIDs[i] = id.Hex()
+	}
+	data, _ := json.Marshal(hexIDs)
+	return data
+}
+
+// idPlaceholders returns "$1, $2, ..." and the hex IDs to bind to them.
+func idPlaceholders(ids []primitive.ObjectID) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id.Hex()
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+func decodeIDs(data []byte) []primitive.ObjectID {
+	var hexIDs []string
+	if err := json.Unmarshal(data, &hexIDs); err != nil {
+		return []primitive.ObjectID{}
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		if id, err := primitive.ObjectIDFromHex(hexID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func parseID(hexID string) primitive.ObjectID {
+	id, _ := primitive.ObjectIDFromHex(strings.TrimSpace(hexID))
+	return id
+}
+
+type postgresTransactor struct {
+	db *sql.DB
+}
+
+func NewPostgresTransactor(db *sql.DB) domain.Transactor {
+	return &postgresTransactor{db: db}
+}
+
+func (t *postgresTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pgTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to start transaction", 500, err, nil)
+	}
+
+	if err := fn(context.WithValue(ctx, pgTxKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New("DATABASE_ERROR", "Database transaction failed", 500, err, nil)
+	}
+	return nil
+}
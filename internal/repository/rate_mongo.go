@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// duplicateKeyCode is the server error of a write that breaks a unique index
+const duplicateKeyCode = 11000
+
+type rateMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRateMongoRepository(db *mongo.Database) domain.RateRepository {
+	return &rateMongoRepository{
+		collection: db.Collection(config.CollectionName("rates")),
+	}
+}
+
+func (r *rateMongoRepository) Create(ctx context.Context, rate *domain.ExchangeRate) error {
+	rate.CreatedAt = time.Now()
+	rate.UpdatedAt = rate.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, rate)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("RATE_ALREADY_EXISTS", "A rate of this pair is already effective on this date", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to create rate", 500, err, nil)
+	}
+
+	rate.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *rateMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ExchangeRate, error) {
+	var rate domain.ExchangeRate
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rate); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("RATE_NOT_FOUND", "Rate not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get rate", 500, err, nil)
+	}
+	return &rate, nil
+}
+
+func (r *rateMongoRepository) GetAll(ctx context.Context, filter domain.RateFilter, skip, limit int) ([]*domain.ExchangeRate, int, error) {
+	query := bson.M{}
+	if filter.Base != "" {
+		query["base"] = filter.Base
+	}
+	if filter.Quote != "" {
+		query["quote"] = filter.Quote
+	}
+	if filter.From != nil || filter.To != nil {
+		dates := bson.M{}
+		if filter.From != nil {
+			dates["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			dates["$lte"] = *filter.To
+		}
+		query["effectiveDate"] = dates
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count rates", 500, err, nil)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "base", Value: 1}, {Key: "quote", Value: 1}, {Key: "effectiveDate", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to get rates", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	rates := []*domain.ExchangeRate{}
+	if err = cursor.All(ctx, &rates); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to decode rates", 500, err, nil)
+	}
+
+	return rates, int(total), nil
+}
+
+func (r *rateMongoRepository) Update(ctx context.Context, id primitive.ObjectID, rate *domain.ExchangeRate) error {
+	rate.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"rate":          rate.Rate,
+			"effectiveDate": rate.EffectiveDate,
+			"source":        rate.Source,
+			"updatedBy":     rate.UpdatedBy,
+			"updatedAt":     rate.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("RATE_ALREADY_EXISTS", "A rate of this pair is already effective on this date", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to update rate", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("RATE_NOT_FOUND", "Rate not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *rateMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete rate", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("RATE_NOT_FOUND", "Rate not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+// SaveSynced upserts on pair and day among rates that aren't manual. Where a manual rate holds
+// the day, the insert breaks the unique index and is skipped.
+func (r *rateMongoRepository) SaveSynced(ctx context.Context, rates []*domain.ExchangeRate) (int, error) {
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, len(rates))
+	for i, rate := range rates {
+		filter := bson.M{
+			"base":          rate.Base,
+			"quote":         rate.Quote,
+			"effectiveDate": rate.EffectiveDate,
+			"source":        bson.M{"$ne": domain.RateSourceManual},
+		}
+		update := bson.M{
+			"$set":         bson.M{"rate": rate.Rate, "source": rate.Source, "updatedAt": now},
+			"$setOnInsert": bson.M{"createdAt": now},
+		}
+		models[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true)
+	}
+
+	result, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		bulkErr, ok := err.(mongo.BulkWriteException)
+		if !ok || bulkErr.WriteConcernError != nil {
+			return 0, errors.New("DATABASE_ERROR", "Failed to save rates", 500, err, nil)
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Code != duplicateKeyCode {
+				return 0, errors.New("DATABASE_ERROR", "Failed to save rates", 500, err, nil)
+			}
+		}
+	}
+
+	if result == nil {
+		return 0, nil
+	}
+	return int(result.MatchedCount + result.UpsertedCount), nil
+}
+
+func (r *rateMongoRepository) Effective(ctx context.Context, base, quote string, date time.Time) (*domain.ExchangeRate, error) {
+	filter := bson.M{"base": base, "quote": quote, "effectiveDate": bson.M{"$lte": date}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "effectiveDate", Value: -1}})
+
+	var rate domain.ExchangeRate
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&rate); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("RATE_NOT_FOUND", "Rate not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get rate", 500, err, nil)
+	}
+	return &rate, nil
+}
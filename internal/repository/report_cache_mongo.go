@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const reportListCacheKeyPrefix = "repo:reports:"
+
+// cachedReportRepository caches populated report lists. Entries are keyed by the list filter plus
+// a version stamp (matching count and latest updatedAt), so report writes from any instance miss
+// the cache on their own. Changes to the populated users, companies and report types are picked up
+// by WatchReportListCache.
+type cachedReportRepository struct {
+	domain.ReportRepository
+	collection *mongo.Collection
+	cache      utils.Cache
+	ttl        time.Duration
+}
+
+func NewCachedReportRepository(next domain.ReportRepository, db *mongo.Database, cache utils.Cache, ttl time.Duration) domain.ReportRepository {
+	return &cachedReportRepository{
+		ReportRepository: next,
+		collection:       db.Collection(config.CollectionName("reports")),
+		cache:            cache,
+		ttl:              ttl,
+	}
+}
+
+func (r *cachedReportRepository) GetAll(ctx context.Context) ([]*domain.PopulatedReport, error) {
+	return r.cachedList(ctx, "all", bson.M{}, func() ([]*domain.PopulatedReport, error) {
+		return r.ReportRepository.GetAll(ctx)
+	})
+}
+
+func (r *cachedReportRepository) GetByCompany(ctx context.Context, companyID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return r.cachedList(ctx, "company:"+companyID.Hex(), bson.M{"company": companyID}, func() ([]*domain.PopulatedReport, error) {
+		return r.ReportRepository.GetByCompany(ctx, companyID)
+	})
+}
+
+func (r *cachedReportRepository) Create(ctx context.Context, report *domain.Report) error {
+	defer r.cache.DeletePrefix(reportListCacheKeyPrefix)
+	return r.ReportRepository.Create(ctx, report)
+}
+
+func (r *cachedReportRepository) Update(ctx context.Context, id primitive.ObjectID, report *domain.Report) (*domain.PopulatedReport, error) {
+	defer r.cache.DeletePrefix(reportListCacheKeyPrefix)
+	return r.ReportRepository.Update(ctx, id, report)
+}
+
+func (r *cachedReportRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cache.DeletePrefix(reportListCacheKeyPrefix)
+	return r.ReportRepository.Delete(ctx, id)
+}
+
+func (r *cachedReportRepository) cachedList(ctx context.Context, name string, filter bson.M, load func() ([]*domain.PopulatedReport, error)) ([]*domain.PopulatedReport, error) {
+	// Lists limited to a user's or tenant's reports differ per caller, so only full lists are cached
+	if domain.IncludesDeleted(ctx) || !domain.ReadsEverything(ctx) {
+		return load()
+	}
+
+	// Lists with and without reportData are cached apart
+	if domain.IncludesReportData(ctx) {
+		name = "data:" + name
+	}
+
+	version, err := r.version(ctx, filter)
+	if err != nil {
+		// The stamp is only an optimisation; fall back to the uncached query
+		log.Warnf(ctx, "Report list cache: failed to read version for %s: %v", name, err)
+		return load()
+	}
+
+	key := reportListCacheKeyPrefix + name + ":" + version
+	var cached []*domain.PopulatedReport
+	if r.cache.Get(key, &cached) {
+		return copyPopulatedReports(cached), nil
+	}
+
+	reports, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Older versions of this list can never be hit again
+	r.cache.DeletePrefix(reportListCacheKeyPrefix + name + ":")
+	r.cache.Set(key, copyPopulatedReports(reports), r.ttl)
+	return reports, nil
+}
+
+// version summarises the matching reports cheaply, without the population lookups.
+func (r *cachedReportRepository) version(ctx context.Context, filter bson.M) (string, error) {
+	pipeline := []bson.M{
+		{"$match": scopeFilter(ctx, filter)},
+		{"$group": bson.M{
+			"_id":       nil,
+			"count":     bson.M{"$sum": 1},
+			"updatedAt": bson.M{"$max": "$updatedAt"},
+		}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close(ctx)
+
+	var stamp struct {
+		Count     int       `bson:"count"`
+		UpdatedAt time.Time `bson:"updatedAt"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&stamp); err != nil {
+			return "", err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%d", stamp.Count, stamp.UpdatedAt.UnixNano()), nil
+}
+
+// WatchReportListCache drops cached report lists whenever a report or a document it populates
+// changes, using a change stream. It returns when ctx is cancelled or the deployment does not
+// support change streams (standalone servers), in which case entries simply expire by TTL.
+func WatchReportListCache(ctx context.Context, db *mongo.Database, cache utils.Cache) {
+	collections := bson.A{}
+	for _, name := range []string{"reports", "companies", "users", "reporttypes"} {
+		collections = append(collections, config.CollectionName(name))
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"ns.coll": bson.M{"$in": collections}}}},
+		{{Key: "$project", Value: bson.M{"ns": 1}}},
+	}
+
+	for ctx.Err() == nil {
+		stream, err := db.Watch(ctx, pipeline, options.ChangeStream().SetMaxAwaitTime(time.Second))
+		if err != nil {
+			log.Warnf(ctx, "Report list cache: change streams unavailable, relying on TTL: %v", err)
+			return
+		}
+
+		for stream.Next(ctx) {
+			cache.DeletePrefix(reportListCacheKeyPrefix)
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Warnf(ctx, "Report list cache: change stream interrupted, restarting: %v", err)
+			// Anything may have changed while the stream was down
+			cache.DeletePrefix(reportListCacheKeyPrefix)
+			time.Sleep(time.Second)
+		}
+		stream.Close(context.Background())
+	}
+}
+
+func copyPopulatedReports(reports []*domain.PopulatedReport) []*domain.PopulatedReport {
+	copied := make([]*domain.PopulatedReport, len(reports))
+	for i, report := range reports {
+		r := *report
+		copied[i] = &r
+	}
+	return copied
+}
@@ -7,22 +7,38 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 
+	"finsolvz-backend/internal/config"
 	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/utils/errors"
 )
 
 type reportMongoRepository struct {
 	collection *mongo.Collection
+	// listCollection serves list and multi-document aggregations; it may be routed to secondaries.
+	listCollection *mongo.Collection
 }
 
-func NewReportMongoRepository(db *mongo.Database) domain.ReportRepository {
+// NewReportMongoRepository creates a report repository. listReadPref applies only to list queries,
+// single-document reads stay on the primary so callers can read their own writes. nil means primary.
+func NewReportMongoRepository(db *mongo.Database, listReadPref *readpref.ReadPref) domain.ReportRepository {
+	if listReadPref == nil {
+		listReadPref = readpref.Primary()
+	}
+
 	return &reportMongoRepository{
-		collection: db.Collection("reports"),
+		collection:     db.Collection(config.CollectionName("reports")),
+		listCollection: db.Collection(config.CollectionName("reports"), options.Collection().SetReadPreference(listReadPref)),
 	}
 }
 
 func (r *reportMongoRepository) Create(ctx context.Context, report *domain.Report) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(report.Company) {
+		return errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
 	report.CreatedAt = time.Now()
 	report.UpdatedAt = time.Now()
 
@@ -36,114 +52,101 @@ func (r *reportMongoRepository) Create(ctx context.Context, report *domain.Repor
 }
 
 // getPopulationPipeline creates an optimized aggregation pipeline for populating report references.
-func (r *reportMongoRepository) getPopulationPipeline() []bson.M {
+func (r *reportMongoRepository) getPopulationPipeline(ctx context.Context) []bson.M {
+	userFields := bson.M{
+		"_id":       1,
+		"name":      1,
+		"email":     1,
+		"role":      1,
+		"createdAt": 1,
+		"updatedAt": 1,
+	}
 	return []bson.M{
 		// Single lookup with pipeline for company (more efficient)
-		{
-			"$lookup": bson.M{
-				"from":         "companies",
-				"localField":   "company",
-				"foreignField": "_id",
-				"as":           "company",
-				"pipeline": []bson.M{
-					{
-						"$project": bson.M{
-							"_id":            1,
-							"name":           1,
-							"profilePicture": 1,
-							"createdAt":      1,
-							"updatedAt":      1,
-						},
-					},
-				},
-			},
-		},
+		populate(ctx, "companies", "company", bson.M{
+			"_id":            1,
+			"name":           1,
+			"profilePicture": 1,
+			"fiscalCalendar": 1,
+			"branding":       1,
+			"createdAt":      1,
+			"updatedAt":      1,
+		}),
 		// Single lookup with pipeline for reportType
-		{
-			"$lookup": bson.M{
-				"from":         "reporttypes",
-				"localField":   "reportType",
-				"foreignField": "_id",
-				"as":           "reportType",
-				"pipeline": []bson.M{
-					{
-						"$project": bson.M{
-							"_id":  1,
-							"name": 1,
-						},
-					},
-				},
-			},
-		},
+		populate(ctx, "reporttypes", "reportType", bson.M{
+			"_id":  1,
+			"name": 1,
+		}),
 		// Single lookup with pipeline for createdBy
+		populate(ctx, "users", "createdBy", userFields),
+		// Single lookup with pipeline for userAccess; users deleted since lose their access
+		populate(ctx, "users", "userAccess", userFields),
+		// Single project stage to flatten single-item arrays. A reference to a deleted
+		// document keeps its ID alone, so that updates still store it.
 		{
-			"$lookup": bson.M{
-				"from":         "users",
-				"localField":   "createdBy",
-				"foreignField": "_id",
-				"as":           "createdBy",
-				"pipeline": []bson.M{
-					{
-						"$project": bson.M{
-							"_id":       1,
-							"name":      1,
-							"email":     1,
-							"role":      1,
-							"createdAt": 1,
-							"updatedAt": 1,
-						},
-					},
-				},
+			"$project": bson.M{
+				"_id":          1,
+				"reportName":   1,
+				"year":         1,
+				"currency":     1,
+				"reportData":   1,
+				"lineage":      1,
+				"approval":     1,
+				"createdAt":    1,
+				"accessLevels": 1,
+				"updatedAt":    1,
+				"company":      populated("company"),
+				"reportType":   populated("reportType"),
+				"createdBy":    populated("createdBy"),
+				"userAccess":   "$populated.userAccess", // Keep as array
 			},
 		},
-		// Single lookup with pipeline for userAccess
-		{
-			"$lookup": bson.M{
-				"from":         "users",
-				"localField":   "userAccess",
-				"foreignField": "_id",
-				"as":           "userAccess",
-				"pipeline": []bson.M{
-					{
-						"$project": bson.M{
-							"_id":       1,
-							"name":      1,
-							"email":     1,
-							"role":      1,
-							"createdAt": 1,
-							"updatedAt": 1,
-						},
-					},
-				},
+	}
+}
+
+// populate looks up the documents of collection a report field references into
+// populated.<field>, leaving out soft-deleted ones unless ctx asks for them.
+func populate(ctx context.Context, collection, field string, fields bson.M) bson.M {
+	return bson.M{
+		"$lookup": bson.M{
+			"from":         config.CollectionName(collection),
+			"localField":   field,
+			"foreignField": "_id",
+			"as":           "populated." + field,
+			"pipeline": []bson.M{
+				{"$match": scopeFilter(ctx, bson.M{})},
+				{"$project": fields},
 			},
 		},
-		// Single project stage to flatten single-item arrays
-		{
-			"$project": bson.M{
-				"_id":        1,
-				"reportName": 1,
-				"year":       1,
-				"currency":   1,
-				"reportData": 1,
-				"createdAt":  1,
-				"updatedAt":  1,
-				"company": bson.M{
-					"$arrayElemAt": []interface{}{"$company", 0},
-				},
-				"reportType": bson.M{
-					"$arrayElemAt": []interface{}{"$reportType", 0},
-				},
-				"createdBy": bson.M{
-					"$arrayElemAt": []interface{}{"$createdBy", 0},
-				},
-				"userAccess": 1, // Keep as array
-			},
+	}
+}
+
+// populated is the document populate found for a single reference, only its ID when there
+// is none, and missing without a reference
+func populated(field string) bson.M {
+	return bson.M{
+		"$ifNull": []interface{}{
+			bson.M{"$arrayElemAt": []interface{}{"$populated." + field, 0}},
+			bson.M{"$cond": []interface{}{
+				bson.M{"$eq": []interface{}{bson.M{"$type": "$" + field}, "objectId"}},
+				bson.M{"_id": "$" + field},
+				"$$REMOVE",
+			}},
 		},
 	}
 }
 
+// listPopulationPipeline is the population pipeline of list reads, which drop reportData
+// before the lookups unless ctx asks for it.
+func (r *reportMongoRepository) listPopulationPipeline(ctx context.Context) []bson.M {
+	if domain.IncludesReportData(ctx) {
+		return r.getPopulationPipeline(ctx)
+	}
+	return append([]bson.M{{"$project": bson.M{"reportData": 0}}}, r.getPopulationPipeline(ctx)...)
+}
+
 func (r *reportMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"_id": id}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"_id": id})}}, r.getPopulationPipeline(ctx)...)
 
 	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -164,7 +167,7 @@ func (r *reportMongoRepository) GetByID(ctx context.Context, id primitive.Object
 }
 
 func (r *reportMongoRepository) GetByName(ctx context.Context, name string) (*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"reportName": name}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"reportName": name})}}, r.getPopulationPipeline(ctx)...)
 
 	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -185,7 +188,7 @@ func (r *reportMongoRepository) GetByName(ctx context.Context, name string) (*do
 }
 
 func (r *reportMongoRepository) GetAll(ctx context.Context) ([]*domain.PopulatedReport, error) {
-	cursor, err := r.collection.Aggregate(ctx, r.getPopulationPipeline())
+	cursor, err := r.listCollection.Aggregate(ctx, readPipeline(reportReadFilter(ctx, bson.M{}), r.listPopulationPipeline(ctx)))
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get reports", 500, err, nil)
 	}
@@ -199,20 +202,43 @@ func (r *reportMongoRepository) GetAll(ctx context.Context) ([]*domain.Populated
 	return reports, nil
 }
 
+func (r *reportMongoRepository) Each(ctx context.Context, fn func(*domain.PopulatedReport) error) error {
+	cursor, err := r.listCollection.Aggregate(ctx, readPipeline(reportReadFilter(ctx, bson.M{}), r.listPopulationPipeline(ctx)))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get reports", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var report domain.PopulatedReport
+		if err := cursor.Decode(&report); err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to decode reports", 500, err, nil)
+		}
+		if err := fn(&report); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get reports", 500, err, nil)
+	}
+
+	return nil
+}
+
 // GetAllPaginated retrieves reports with pagination
 func (r *reportMongoRepository) GetAllPaginated(ctx context.Context, skip, limit int) ([]*domain.PopulatedReport, int, error) {
 	// Get total count
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.listCollection.CountDocuments(ctx, reportReadFilter(ctx, bson.M{}))
 	if err != nil {
 		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count reports", 500, err, nil)
 	}
 
 	// Add pagination to pipeline
-	pipeline := r.getPopulationPipeline()
+	pipeline := readPipeline(reportReadFilter(ctx, bson.M{}), r.listPopulationPipeline(ctx))
 	pipeline = append(pipeline, bson.M{"$skip": skip})
 	pipeline = append(pipeline, bson.M{"$limit": limit})
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, 0, errors.New("DATABASE_ERROR", "Failed to get reports", 500, err, nil)
 	}
@@ -227,9 +253,9 @@ func (r *reportMongoRepository) GetAllPaginated(ctx context.Context, skip, limit
 }
 
 func (r *reportMongoRepository) GetByCompany(ctx context.Context, companyID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"company": companyID}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"company": companyID})}}, r.listPopulationPipeline(ctx)...)
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get reports by company", 500, err, nil)
 	}
@@ -244,9 +270,9 @@ func (r *reportMongoRepository) GetByCompany(ctx context.Context, companyID prim
 }
 
 func (r *reportMongoRepository) GetByCompanies(ctx context.Context, companyIDs []primitive.ObjectID) ([]*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"company": bson.M{"$in": companyIDs}}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"company": bson.M{"$in": companyIDs}})}}, r.listPopulationPipeline(ctx)...)
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get reports by companies", 500, err, nil)
 	}
@@ -260,10 +286,28 @@ func (r *reportMongoRepository) GetByCompanies(ctx context.Context, companyIDs [
 	return reports, nil
 }
 
+func (r *reportMongoRepository) GetUpdatedSince(ctx context.Context, companyIDs []primitive.ObjectID, since time.Time) ([]*domain.PopulatedReport, error) {
+	filter := bson.M{"company": bson.M{"$in": companyIDs}, "updatedAt": bson.M{"$gte": since}}
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, filter)}}, r.listPopulationPipeline(ctx)...)
+
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get updated reports", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var reports []*domain.PopulatedReport
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode reports", 500, err, nil)
+	}
+
+	return reports, nil
+}
+
 func (r *reportMongoRepository) GetByReportType(ctx context.Context, reportTypeID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"reportType": reportTypeID}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"reportType": reportTypeID})}}, r.listPopulationPipeline(ctx)...)
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get reports by report type", 500, err, nil)
 	}
@@ -278,9 +322,9 @@ func (r *reportMongoRepository) GetByReportType(ctx context.Context, reportTypeI
 }
 
 func (r *reportMongoRepository) GetByUserAccess(ctx context.Context, userID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"userAccess": userID}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"userAccess": userID})}}, r.listPopulationPipeline(ctx)...)
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get reports by user access", 500, err, nil)
 	}
@@ -295,9 +339,9 @@ func (r *reportMongoRepository) GetByUserAccess(ctx context.Context, userID prim
 }
 
 func (r *reportMongoRepository) GetByCreatedBy(ctx context.Context, userID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
-	pipeline := append([]bson.M{{"$match": bson.M{"createdBy": userID}}}, r.getPopulationPipeline()...)
+	pipeline := append([]bson.M{{"$match": reportReadFilter(ctx, bson.M{"createdBy": userID})}}, r.listPopulationPipeline(ctx)...)
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.listCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get reports by created by", 500, err, nil)
 	}
@@ -312,23 +356,30 @@ func (r *reportMongoRepository) GetByCreatedBy(ctx context.Context, userID primi
 }
 
 func (r *reportMongoRepository) Update(ctx context.Context, id primitive.ObjectID, report *domain.Report) (*domain.PopulatedReport, error) {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.OwnsCompany(report.Company) {
+		return nil, errors.New("COMPANY_NOT_FOUND", "Company not found", 404, nil, nil)
+	}
+
 	report.UpdatedAt = time.Now()
 
 	update := bson.M{
 		"$set": bson.M{
-			"reportName": report.ReportName,
-			"reportType": report.ReportType,
-			"year":       report.Year,
-			"company":    report.Company,
-			"currency":   report.Currency,
-			"createdBy":  report.CreatedBy,
-			"userAccess": report.UserAccess,
-			"reportData": report.ReportData,
-			"updatedAt":  report.UpdatedAt,
+			"reportName":   report.ReportName,
+			"reportType":   report.ReportType,
+			"year":         report.Year,
+			"company":      report.Company,
+			"currency":     report.Currency,
+			"createdBy":    report.CreatedBy,
+			"userAccess":   report.UserAccess,
+			"accessLevels": report.AccessLevels,
+			"reportData":   report.ReportData,
+			"lineage":      report.Lineage,
+			"approval":     report.Approval,
+			"updatedAt":    report.UpdatedAt,
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, reportTenantFilter(ctx, scopeFilter(ctx, bson.M{"_id": id})), update)
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to update report", 500, err, nil)
 	}
@@ -341,12 +392,12 @@ func (r *reportMongoRepository) Update(ctx context.Context, id primitive.ObjectI
 }
 
 func (r *reportMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	result, err := softDelete(ctx, r.collection, reportTenantFilter(ctx, bson.M{"_id": id}))
 	if err != nil {
 		return errors.New("DATABASE_ERROR", "Failed to delete report", 500, err, nil)
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return errors.New("REPORT_NOT_FOUND", "Report not found", 404, nil, nil)
 	}
 
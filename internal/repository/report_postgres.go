@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// populatedReportSelect mirrors the Mongo population pipeline: referenced documents are
+// embedded as JSON objects with the same projected fields, and deleted ones by their ID
+// alone. %[1]s is the report data column, or NULL for lists that leave it out; %[2]s, %[3]s
+// and %[4]s are the soft-delete conditions of companies, report types and users.
+const populatedReportSelect = `SELECT r.id, r.report_name, r.year, r.currency, %[1]s, r.lineage, r.approval, r.access_levels, r.created_at, r.updated_at,
+	(SELECT CASE WHEN %[2]s THEN jsonb_build_object('id', c.id, 'name', c.name, 'profilePicture', c.profile_picture,
+			'fiscalCalendar', c.fiscal_calendar, 'branding', c.branding, 'createdAt', c.created_at, 'updatedAt', c.updated_at)
+			ELSE jsonb_build_object('id', c.id) END
+		FROM companies c WHERE c.id = r.company),
+	(SELECT CASE WHEN %[3]s THEN jsonb_build_object('id', rt.id, 'name', rt.name) ELSE jsonb_build_object('id', rt.id) END
+		FROM report_types rt WHERE rt.id = r.report_type),
+	(SELECT CASE WHEN %[4]s THEN jsonb_build_object('id', u.id, 'name', u.name, 'email', u.email, 'role', u.role,
+			'createdAt', u.created_at, 'updatedAt', u.updated_at) ELSE jsonb_build_object('id', u.id) END
+		FROM users u WHERE u.id = r.created_by),
+	(SELECT COALESCE(jsonb_agg(jsonb_build_object('id', u.id, 'name', u.name, 'email', u.email, 'role', u.role,
+			'createdAt', u.created_at, 'updatedAt', u.updated_at)), '[]')
+		FROM users u WHERE r.user_access @> jsonb_build_array(u.id::text) AND %[4]s)
+FROM reports r`
+
+// populatedReportQuery is populatedReportSelect for ctx with dataColumn.
+func populatedReportQuery(ctx context.Context, dataColumn string) string {
+	return fmt.Sprintf(populatedReportSelect, dataColumn, pgNotDeleted(ctx, "c"), pgNotDeleted(ctx, "rt"), pgNotDeleted(ctx, "u"))
+}
+
+type reportPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewReportPostgresRepository(db *sql.DB) domain.ReportRepository {
+	return &reportPostgresRepository{db: db}
+}
+
+func scanPopulatedReport(row interface{ Scan(...interface{}) error }) (*domain.PopulatedReport, error) {
+	var (
+		report                                                                        domain.PopulatedReport
+		id                                                                            string
+		reportData, lineage, approval, levels, company, reportType, createdBy, access []byte
+	)
+	if err := row.Scan(&id, &report.ReportName, &report.Year, &report.Currency, &reportData, &lineage, &approval, &levels,
+		&report.CreatedAt, &report.UpdatedAt, &company, &reportType, &createdBy, &access); err != nil {
+		return nil, err
+	}
+	report.ID = parseID(id)
+
+	if reportData != nil {
+		if err := json.Unmarshal(reportData, &report.ReportData); err != nil {
+			return nil, err
+		}
+	}
+	if lineage != nil {
+		if err := json.Unmarshal(lineage, &report.Lineage); err != nil {
+			return nil, err
+		}
+	}
+	for _, ref := range []struct {
+		data []byte
+		dst  interface{}
+	}{
+		{company, &report.Company},
+		{reportType, &report.ReportType},
+		{createdBy, &report.CreatedBy},
+		{access, &report.UserAccess},
+		{approval, &report.Approval},
+		{levels, &report.AccessLevels},
+	} {
+		if len(ref.data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(ref.data, ref.dst); err != nil {
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
+
+// encodeAccessLevels stores access levels as a JSON array, never NULL.
+func encodeAccessLevels(levels []domain.ReportAccess) ([]byte, error) {
+	if levels == nil {
+		levels = []domain.ReportAccess{}
+	}
+	return json.Marshal(levels)
+}
+
+// encodeLineage stores a lineage as JSON, or NULL when none was recorded.
+func encodeLineage(lineage *domain.ReportLineage) (interface{}, error) {
+	if lineage == nil {
+		return nil, nil
+	}
+	return json.Marshal(lineage)
+}
+
+// encodeApproval stores an approval as JSON, or NULL for reports not approved.
+func encodeApproval(approval *domain.ReportApproval) (interface{}, error) {
+	if approval == nil {
+		return nil, nil
+	}
+	return json.Marshal(approval)
+}
+
+func (r *reportPostgresRepository) queryReports(ctx context.Context, where, suffix string, args ...interface{}) ([]*domain.PopulatedReport, error) {
+	var reports []*domain.PopulatedReport
+	err := r.eachReport(ctx, where, suffix, func(report *domain.PopulatedReport) error {
+		reports = append(reports, report)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (r *reportPostgresRepository) eachReport(ctx context.Context, where, suffix string, fn func(*domain.PopulatedReport) error, args ...interface{}) error {
+	dataColumn := "NULL"
+	if domain.IncludesReportData(ctx) {
+		dataColumn = "r.report_data"
+	}
+	query := populatedReportQuery(ctx, dataColumn) + ` WHERE ` + pgNotDeleted(ctx, "r") + ` AND ` + pgReportAccess(ctx)
+	if where != "" {
+		query += ` AND ` + where
+	}
+	query += ` ` + suffix
+
+	rows, err := pgConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get reports", 500, err, nil)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		report, err := scanPopulatedReport(rows)
+		if err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to decode reports", 500, err, nil)
+		}
+		if err := fn(report); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get reports", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *reportPostgresRepository) getOne(ctx context.Context, where string, arg interface{}) (*domain.PopulatedReport, error) {
+	reports, err := r.queryReports(domain.WithReportData(ctx), where, `LIMIT 1`, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reports) == 0 {
+		return nil, errors.New("REPORT_NOT_FOUND", "Report not found", 404, nil, nil)
+	}
+
+	return reports[0], nil
+}
+
+func (r *reportPostgresRepository) Create(ctx context.Context, report *domain.Report) error {
+	report.ID = primitive.NewObjectID()
+	report.CreatedAt = time.Now()
+	report.UpdatedAt = time.Now()
+
+	reportData, err := json.Marshal(report.ReportData)
+	if err != nil {
+		return errors.New("INVALID_REPORT_DATA", "Report data must be JSON encodable", 400, err, nil)
+	}
+	lineage, err := encodeLineage(report.Lineage)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode report lineage", 500, err, nil)
+	}
+	levels, err := encodeAccessLevels(report.AccessLevels)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode report access levels", 500, err, nil)
+	}
+
+	_, err = pgConn(ctx, r.db).ExecContext(ctx, `INSERT INTO reports
+			(id, report_name, report_type, year, company, currency, created_by, user_access, access_levels, report_data, lineage, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		report.ID.Hex(), report.ReportName, report.ReportType.Hex(), report.Year, report.Company.Hex(), report.Currency,
+		report.CreatedBy.Hex(), encodeIDs(report.UserAccess), levels, reportData, lineage, report.CreatedAt, report.UpdatedAt)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create report", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *reportPostgresRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.PopulatedReport, error) {
+	return r.getOne(ctx, "r.id = $1", id.Hex())
+}
+
+func (r *reportPostgresRepository) GetByName(ctx context.Context, name string) (*domain.PopulatedReport, error) {
+	return r.getOne(ctx, "r.report_name = $1", name)
+}
+
+func (r *reportPostgresRepository) GetAll(ctx context.Context) ([]*domain.PopulatedReport, error) {
+	return r.queryReports(ctx, "", `ORDER BY r.created_at DESC`)
+}
+
+func (r *reportPostgresRepository) Each(ctx context.Context, fn func(*domain.PopulatedReport) error) error {
+	return r.eachReport(ctx, "", `ORDER BY r.created_at DESC`, fn)
+}
+
+func (r *reportPostgresRepository) GetAllPaginated(ctx context.Context, skip, limit int) ([]*domain.PopulatedReport, int, error) {
+	var total int
+	if err := pgConn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT count(*) FROM reports r WHERE `+pgNotDeleted(ctx, "r")+` AND `+pgReportAccess(ctx)).Scan(&total); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count reports", 500, err, nil)
+	}
+
+	reports, err := r.queryReports(ctx, "", `ORDER BY r.created_at DESC OFFSET $1 LIMIT $2`, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+func (r *reportPostgresRepository) GetByCompany(ctx context.Context, companyID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return r.queryReports(ctx, "r.company = $1", `ORDER BY r.created_at DESC`, companyID.Hex())
+}
+
+func (r *reportPostgresRepository) GetByCompanies(ctx context.Context, companyIDs []primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	if len(companyIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := idPlaceholders(companyIDs)
+	return r.queryReports(ctx, "r.company IN ("+placeholders+")", `ORDER BY r.created_at DESC`, args...)
+}
+
+func (r *reportPostgresRepository) GetUpdatedSince(ctx context.Context, companyIDs []primitive.ObjectID, since time.Time) ([]*domain.PopulatedReport, error) {
+	if len(companyIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := idPlaceholders(companyIDs)
+	args = append(args, since)
+	where := fmt.Sprintf("r.company IN (%s) AND r.updated_at >= $%d", placeholders, len(args))
+	return r.queryReports(ctx, where, `ORDER BY r.created_at DESC`, args...)
+}
+
+func (r *reportPostgresRepository) GetByReportType(ctx context.Context, reportTypeID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return r.queryReports(ctx, "r.report_type = $1", `ORDER BY r.created_at DESC`, reportTypeID.Hex())
+}
+
+func (r *reportPostgresRepository) GetByUserAccess(ctx context.Context, userID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return r.queryReports(ctx, "r.user_access @> jsonb_build_array($1::text)", `ORDER BY r.created_at DESC`, userID.Hex())
+}
+
+func (r *reportPostgresRepository) GetByCreatedBy(ctx context.Context, userID primitive.ObjectID) ([]*domain.PopulatedReport, error) {
+	return r.queryReports(ctx, "r.created_by = $1", `ORDER BY r.created_at DESC`, userID.Hex())
+}
+
+func (r *reportPostgresRepository) Update(ctx context.Context, id primitive.ObjectID, report *domain.Report) (*domain.PopulatedReport, error) {
+	report.UpdatedAt = time.Now()
+
+	reportData, err := json.Marshal(report.ReportData)
+	if err != nil {
+		return nil, errors.New("INVALID_REPORT_DATA", "Report data must be JSON encodable", 400, err, nil)
+	}
+	lineage, err := encodeLineage(report.Lineage)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to encode report lineage", 500, err, nil)
+	}
+	levels, err := encodeAccessLevels(report.AccessLevels)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to encode report access levels", 500, err, nil)
+	}
+	approval, err := encodeApproval(report.Approval)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to encode report approval", 500, err, nil)
+	}
+
+	result, err := pgConn(ctx, r.db).ExecContext(ctx, `UPDATE reports SET
+			report_name = $2, report_type = $3, year = $4, company = $5, currency = $6,
+			created_by = $7, user_access = $8, access_levels = $9, report_data = $10, lineage = $11, approval = $12, updated_at = $13
+		WHERE id = $1 AND `+pgNotDeleted(ctx, ""),
+		id.Hex(), report.ReportName, report.ReportType.Hex(), report.Year, report.Company.Hex(), report.Currency,
+		report.CreatedBy.Hex(), encodeIDs(report.UserAccess), levels, reportData, lineage, approval, report.UpdatedAt)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to update report", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return nil, errors.New("REPORT_NOT_FOUND", "Report not found", 404, nil, nil)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *reportPostgresRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE reports SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`, id.Hex())
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete report", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("REPORT_NOT_FOUND", "Report not found", 404, nil, nil)
+	}
+
+	return nil
+}
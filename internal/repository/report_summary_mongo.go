@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+type reportSummaryMongoRepository struct {
+	collection *mongo.Collection
+	reports    *mongo.Collection
+}
+
+func NewReportSummaryMongoRepository(db *mongo.Database) domain.ReportSummaryRepository {
+	return &reportSummaryMongoRepository{
+		collection: db.Collection(config.CollectionName("report_summaries")),
+		reports:    db.Collection(config.CollectionName("reports")),
+	}
+}
+
+type summaryKey struct {
+	company primitive.ObjectID
+	year    int
+}
+
+func (r *reportSummaryMongoRepository) Refresh(ctx context.Context, companyID primitive.ObjectID, year int) error {
+	summaries, err := r.summarize(ctx, bson.M{"company": companyID, "year": year})
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"company": companyID, "year": year}
+	summary, ok := summaries[summaryKey{companyID, year}]
+	if !ok {
+		_, err = r.collection.DeleteOne(ctx, filter)
+	} else {
+		_, err = r.collection.ReplaceOne(ctx, filter, summary, options.Replace().SetUpsert(true))
+	}
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save report summary", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *reportSummaryMongoRepository) Rebuild(ctx context.Context) (int, error) {
+	started := time.Now()
+	summaries, err := r.summarize(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(summaries))
+	for key, summary := range summaries {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"company": key.company, "year": key.year}).
+			SetReplacement(summary).
+			SetUpsert(true))
+	}
+	if len(models) > 0 {
+		if _, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return 0, errors.New("DATABASE_ERROR", "Failed to save report summaries", 500, err, nil)
+		}
+	}
+
+	// Summaries of companies and years without reports anymore
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"refreshedAt": bson.M{"$lt": started}}); err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to remove stale report summaries", 500, err, nil)
+	}
+	return len(summaries), nil
+}
+
+func (r *reportSummaryMongoRepository) List(ctx context.Context, companyIDs []primitive.ObjectID, year int) ([]*domain.ReportSummary, error) {
+	var conditions []bson.M
+	if len(companyIDs) > 0 {
+		conditions = append(conditions, bson.M{"company": bson.M{"$in": companyIDs}})
+	}
+	if scope := domain.AccessScopeOf(ctx); scope != nil {
+		conditions = append(conditions, bson.M{"company": bson.M{"$in": scope.Companies}})
+	}
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		conditions = append(conditions, bson.M{"company": bson.M{"$in": tenant.Companies}})
+	}
+	if year != 0 {
+		conditions = append(conditions, bson.M{"year": year})
+	}
+	filter := bson.M{}
+	if len(conditions) > 0 {
+		filter = bson.M{"$and": conditions}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "year", Value: -1}, {Key: "company", Value: 1}}))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get report summaries", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	summaries := []*domain.ReportSummary{}
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode report summaries", 500, err, nil)
+	}
+	return summaries, nil
+}
+
+// summarize computes the summaries of the live reports matching filter by company and year.
+func (r *reportSummaryMongoRepository) summarize(ctx context.Context, filter bson.M) (map[summaryKey]*domain.ReportSummary, error) {
+	pipeline := []bson.M{
+		{"$match": scopeFilter(context.Background(), filter)},
+		{"$group": bson.M{
+			"_id":           bson.M{"company": "$company", "year": "$year", "reportType": "$reportType"},
+			"count":         bson.M{"$sum": 1},
+			"currencies":    bson.M{"$addToSet": "$currency"},
+			"lastUpdatedAt": bson.M{"$max": "$updatedAt"},
+		}},
+	}
+	cursor, err := r.reports.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to summarize reports", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			Company    primitive.ObjectID `bson:"company"`
+			Year       int                `bson:"year"`
+			ReportType primitive.ObjectID `bson:"reportType"`
+		} `bson:"_id"`
+		Count         int       `bson:"count"`
+		Currencies    []*string `bson:"currencies"`
+		LastUpdatedAt time.Time `bson:"lastUpdatedAt"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode report summaries", 500, err, nil)
+	}
+
+	now := time.Now()
+	summaries := make(map[summaryKey]*domain.ReportSummary)
+	for _, group := range groups {
+		key := summaryKey{group.ID.Company, group.ID.Year}
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &domain.ReportSummary{
+				Company:     key.company,
+				Year:        key.year,
+				ReportTypes: []domain.ReportTypeCount{},
+				Currencies:  []string{},
+				RefreshedAt: now,
+			}
+			summaries[key] = summary
+		}
+
+		summary.ReportCount += group.Count
+		summary.ReportTypes = append(summary.ReportTypes, domain.ReportTypeCount{ReportType: group.ID.ReportType, Count: group.Count})
+		for _, currency := range group.Currencies {
+			if currency != nil && *currency != "" && !containsString(summary.Currencies, *currency) {
+				summary.Currencies = append(summary.Currencies, *currency)
+			}
+		}
+		if group.LastUpdatedAt.After(summary.LastUpdatedAt) {
+			summary.LastUpdatedAt = group.LastUpdatedAt
+		}
+	}
+
+	for _, summary := range summaries {
+		types := summary.ReportTypes
+		sort.Slice(types, func(i, j int) bool {
+			if types[i].Count != types[j].Count {
+				return types[i].Count > types[j].Count
+			}
+			return types[i].ReportType.Hex() < types[j].ReportType.Hex()
+		})
+		sort.Strings(summary.Currencies)
+	}
+	return summaries, nil
+}
+
+// summarizingReportRepository refreshes the report summaries of the companies and years a
+// report write touches. Summaries are derived data, so a failed refresh is only logged; the
+// next write to the same company and year, or a rebuild, corrects it.
+type summarizingReportRepository struct {
+	domain.ReportRepository
+	reports   *mongo.Collection
+	summaries domain.ReportSummaryRepository
+}
+
+func NewSummarizingReportRepository(next domain.ReportRepository, db *mongo.Database, summaries domain.ReportSummaryRepository) domain.ReportRepository {
+	return &summarizingReportRepository{
+		ReportRepository: next,
+		reports:          db.Collection(config.CollectionName("reports")),
+		summaries:        summaries,
+	}
+}
+
+func (r *summarizingReportRepository) Create(ctx context.Context, report *domain.Report) error {
+	if err := r.ReportRepository.Create(ctx, report); err != nil {
+		return err
+	}
+	r.refresh(ctx, summaryKey{report.Company, report.Year})
+	return nil
+}
+
+func (r *summarizingReportRepository) Update(ctx context.Context, id primitive.ObjectID, report *domain.Report) (*domain.PopulatedReport, error) {
+	// The update may move the report to another company or year
+	previous, found := r.key(ctx, id)
+	updated, err := r.ReportRepository.Update(ctx, id, report)
+	if err != nil {
+		return nil, err
+	}
+
+	current := summaryKey{report.Company, report.Year}
+	r.refresh(ctx, current)
+	if found && previous != current {
+		r.refresh(ctx, previous)
+	}
+	return updated, nil
+}
+
+func (r *summarizingReportRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	previous, found := r.key(ctx, id)
+	if err := r.ReportRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if found {
+		r.refresh(ctx, previous)
+	}
+	return nil
+}
+
+// key reads the company and year of a live report
+func (r *summarizingReportRepository) key(ctx context.Context, id primitive.ObjectID) (summaryKey, bool) {
+	var report struct {
+		Company primitive.ObjectID `bson:"company"`
+		Year    int                `bson:"year"`
+	}
+	err := r.reports.FindOne(ctx, bson.M{"_id": id, "deletedAt": nil},
+		options.FindOne().SetProjection(bson.M{"company": 1, "year": 1})).Decode(&report)
+	if err != nil {
+		return summaryKey{}, false
+	}
+	return summaryKey{report.Company, report.Year}, true
+}
+
+func (r *summarizingReportRepository) refresh(ctx context.Context, key summaryKey) {
+	if err := r.summaries.Refresh(ctx, key.company, key.year); err != nil {
+		log.Errorf(ctx, "Report summaries: failed to refresh company %s, year %d: %v", key.company.Hex(), key.year, err)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
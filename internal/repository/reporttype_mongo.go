@@ -7,6 +7,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"finsolvz-backend/internal/config"
 	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/utils/errors"
 )
@@ -17,7 +18,7 @@ type reportTypeMongoRepository struct {
 
 func NewReportTypeMongoRepository(db *mongo.Database) domain.ReportTypeRepository {
 	return &reportTypeMongoRepository{
-		collection: db.Collection("reporttypes"),
+		collection: db.Collection(config.CollectionName("reporttypes")),
 	}
 }
 
@@ -36,7 +37,7 @@ func (r *reportTypeMongoRepository) Create(ctx context.Context, reportType *doma
 
 func (r *reportTypeMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ReportType, error) {
 	var reportType domain.ReportType
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&reportType)
+	err := r.collection.FindOne(ctx, scopeFilter(ctx, bson.M{"_id": id})).Decode(&reportType)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("REPORT_TYPE_NOT_FOUND", "Report type not found", 404, err, nil)
@@ -48,7 +49,7 @@ func (r *reportTypeMongoRepository) GetByID(ctx context.Context, id primitive.Ob
 
 func (r *reportTypeMongoRepository) GetByName(ctx context.Context, name string) (*domain.ReportType, error) {
 	var reportType domain.ReportType
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&reportType)
+	err := r.collection.FindOne(ctx, scopeFilter(ctx, bson.M{"name": name})).Decode(&reportType)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("REPORT_TYPE_NOT_FOUND", "Report type not found", 404, err, nil)
@@ -59,7 +60,7 @@ func (r *reportTypeMongoRepository) GetByName(ctx context.Context, name string)
 }
 
 func (r *reportTypeMongoRepository) GetAll(ctx context.Context) ([]*domain.ReportType, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	cursor, err := r.collection.Find(ctx, scopeFilter(ctx, bson.M{}))
 	if err != nil {
 		return nil, errors.New("DATABASE_ERROR", "Failed to get report types", 500, err, nil)
 	}
@@ -80,7 +81,7 @@ func (r *reportTypeMongoRepository) Update(ctx context.Context, id primitive.Obj
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, scopeFilter(ctx, bson.M{"_id": id}), update)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return errors.New("REPORT_TYPE_ALREADY_EXISTS", "Report type name already exists", 409, err, nil)
@@ -96,12 +97,12 @@ func (r *reportTypeMongoRepository) Update(ctx context.Context, id primitive.Obj
 }
 
 func (r *reportTypeMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	result, err := softDelete(ctx, r.collection, bson.M{"_id": id})
 	if err != nil {
 		return errors.New("DATABASE_ERROR", "Failed to delete report type", 500, err, nil)
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return errors.New("REPORT_TYPE_NOT_FOUND", "Report type not found", 404, nil, nil)
 	}
 
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type reportTypePostgresRepository struct {
+	db *sql.DB
+}
+
+func NewReportTypePostgresRepository(db *sql.DB) domain.ReportTypeRepository {
+	return &reportTypePostgresRepository{db: db}
+}
+
+func scanReportType(row interface{ Scan(...interface{}) error }) (*domain.ReportType, error) {
+	var (
+		reportType domain.ReportType
+		id         string
+	)
+	if err := row.Scan(&id, &reportType.Name, &reportType.DeletedAt); err != nil {
+		return nil, err
+	}
+	reportType.ID = parseID(id)
+	return &reportType, nil
+}
+
+func (r *reportTypePostgresRepository) Create(ctx context.Context, reportType *domain.ReportType) error {
+	reportType.ID = primitive.NewObjectID()
+
+	_, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO report_types (id, name) VALUES ($1, $2)`, reportType.ID.Hex(), reportType.Name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("REPORT_TYPE_ALREADY_EXISTS", "Report type name already exists", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to create report type", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *reportTypePostgresRepository) getOne(ctx context.Context, where string, arg interface{}) (*domain.ReportType, error) {
+	row := pgConn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id, name, deleted_at FROM report_types WHERE `+where+` AND `+pgNotDeleted(ctx, ""), arg)
+
+	reportType, err := scanReportType(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("REPORT_TYPE_NOT_FOUND", "Report type not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get report type", 500, err, nil)
+	}
+	return reportType, nil
+}
+
+func (r *reportTypePostgresRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ReportType, error) {
+	return r.getOne(ctx, "id = $1", id.Hex())
+}
+
+func (r *reportTypePostgresRepository) GetByName(ctx context.Context, name string) (*domain.ReportType, error) {
+	return r.getOne(ctx, "name = $1", name)
+}
+
+func (r *reportTypePostgresRepository) GetAll(ctx context.Context) ([]*domain.ReportType, error) {
+	rows, err := pgConn(ctx, r.db).QueryContext(ctx,
+		`SELECT id, name, deleted_at FROM report_types WHERE `+pgNotDeleted(ctx, "")+` ORDER BY name`)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get report types", 500, err, nil)
+	}
+	defer rows.Close()
+
+	var reportTypes []*domain.ReportType
+	for rows.Next() {
+		reportType, err := scanReportType(rows)
+		if err != nil {
+			return nil, errors.New("DATABASE_ERROR", "Failed to decode report types", 500, err, nil)
+		}
+		reportTypes = append(reportTypes, reportType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get report types", 500, err, nil)
+	}
+
+	return reportTypes, nil
+}
+
+func (r *reportTypePostgresRepository) Update(ctx context.Context, id primitive.ObjectID, reportType *domain.ReportType) error {
+	result, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE report_types SET name = $2 WHERE id = $1 AND `+pgNotDeleted(ctx, ""), id.Hex(), reportType.Name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("REPORT_TYPE_ALREADY_EXISTS", "Report type name already exists", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to update report type", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("REPORT_TYPE_NOT_FOUND", "Report type not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *reportTypePostgresRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE report_types SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`, id.Hex())
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete report type", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("REPORT_TYPE_NOT_FOUND", "Report type not found", 404, nil, nil)
+	}
+
+	return nil
+}
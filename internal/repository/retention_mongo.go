@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type retentionMongoRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewRetentionMongoRepository(db *mongo.Database) domain.RetentionRepository {
+	return &retentionMongoRepository{
+		db:         db,
+		collection: db.Collection(config.CollectionName("retention")),
+	}
+}
+
+func (r *retentionMongoRepository) GetCompanyRetentions(ctx context.Context) ([]*domain.CompanyRetention, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get retention policies", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	retentions := []*domain.CompanyRetention{}
+	if err := cursor.All(ctx, &retentions); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode retention policies", 500, err, nil)
+	}
+	return retentions, nil
+}
+
+func (r *retentionMongoRepository) SaveCompanyRetention(ctx context.Context, retention *domain.CompanyRetention) error {
+	retention.UpdatedAt = time.Now()
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": retention.CompanyID}, retention, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save retention policy", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *retentionMongoRepository) DeleteCompanyRetention(ctx context.Context, companyID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": companyID})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete retention policy", 500, err, nil)
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("RETENTION_NOT_FOUND", "Company has no retention policy", 404, nil, nil)
+	}
+	return nil
+}
+
+func (r *retentionMongoRepository) PurgeEvents(ctx context.Context, before time.Time, scope domain.PurgeScope, dryRun bool) (int64, error) {
+	filter := purgeScopeFilter("company", scope)
+	filter["status"] = bson.M{"$nin": []domain.EventStatus{domain.EventStatusPending, domain.EventStatusProcessing}}
+	filter["createdAt"] = bson.M{"$lt": before}
+	return r.purge(ctx, "outbox", filter, dryRun)
+}
+
+func (r *retentionMongoRepository) PurgeTrashedReports(ctx context.Context, deletedBefore time.Time, scope domain.PurgeScope, dryRun bool) (int64, error) {
+	filter := purgeScopeFilter("company", scope)
+	filter["deletedAt"] = bson.M{"$lt": deletedBefore}
+	return r.purge(ctx, "reports", filter, dryRun)
+}
+
+func (r *retentionMongoRepository) PurgeLogins(ctx context.Context, before time.Time, scope domain.PurgeScope, dryRun bool) (int64, error) {
+	filter := purgeScopeFilter("userId", scope)
+	filter["createdAt"] = bson.M{"$lt": before}
+	return r.purge(ctx, "logins", filter, dryRun)
+}
+
+func (r *retentionMongoRepository) purge(ctx context.Context, collection string, filter bson.M, dryRun bool) (int64, error) {
+	coll := r.db.Collection(config.CollectionName(collection))
+	if dryRun {
+		count, err := coll.CountDocuments(ctx, filter)
+		if err != nil {
+			return 0, errors.New("DATABASE_ERROR", "Failed to count expired "+collection, 500, err, nil)
+		}
+		return count, nil
+	}
+
+	result, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to purge "+collection, 500, err, nil)
+	}
+	return result.DeletedCount, nil
+}
+
+func purgeScopeFilter(field string, scope domain.PurgeScope) bson.M {
+	if scope.Only != nil {
+		return bson.M{field: bson.M{"$in": scope.Only}}
+	}
+	if len(scope.Except) > 0 {
+		return bson.M{field: bson.M{"$nin": scope.Except}}
+	}
+	return bson.M{}
+}
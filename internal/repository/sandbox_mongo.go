@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// sandboxStateID is the document of the state collection recording the last reset. The
+// collection survives resets, so instances agree on when the next one is due.
+const sandboxStateID = "reset"
+
+type sandboxMongoRepository struct {
+	live  *mongo.Database
+	db    *mongo.Database
+	state *mongo.Collection
+}
+
+// NewSandboxMongoRepository manages the sandbox database db, seeding it with the report
+// types of the real database live.
+func NewSandboxMongoRepository(live, db *mongo.Database) domain.SandboxRepository {
+	return &sandboxMongoRepository{
+		live:  live,
+		db:    db,
+		state: db.Collection(config.CollectionName("sandbox")),
+	}
+}
+
+func (r *sandboxMongoRepository) Reset(ctx context.Context, seed *domain.SandboxSeed) (*domain.SandboxReset, error) {
+	names, err := r.db.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to list sandbox collections", 500, err, nil)
+	}
+	for _, name := range names {
+		if name == r.state.Name() {
+			continue
+		}
+		if err := r.db.Collection(name).Drop(ctx); err != nil {
+			return nil, errors.New("DATABASE_ERROR", "Failed to wipe sandbox collection "+name, 500, err, nil)
+		}
+	}
+	if err := config.CreateIndexes(r.db); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to index the sandbox", 500, err, nil)
+	}
+
+	now := time.Now()
+	reset := &domain.SandboxReset{ResetAt: now}
+
+	// Real report types keep their IDs; the seed's other types are added
+	var reportTypes []domain.ReportType
+	cursor, err := r.live.Collection(config.CollectionName("reporttypes")).Find(ctx, bson.M{"deletedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to read report types", 500, err, nil)
+	}
+	if err := cursor.All(ctx, &reportTypes); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to read report types", 500, err, nil)
+	}
+	typeIDs := make(map[string]primitive.ObjectID, len(reportTypes))
+	for _, reportType := range reportTypes {
+		typeIDs[reportType.Name] = reportType.ID
+	}
+	for _, name := range seed.ReportTypes {
+		if _, ok := typeIDs[name]; !ok {
+			typeIDs[name] = primitive.NewObjectID()
+			reportTypes = append(reportTypes, domain.ReportType{ID: typeIDs[name], Name: name})
+		}
+	}
+	if err := insertAll(ctx, r.db.Collection(config.CollectionName("reporttypes")), reportTypes); err != nil {
+		return nil, err
+	}
+	reset.ReportTypes = len(reportTypes)
+
+	var companies []domain.Company
+	var reports []domain.Report
+	for _, seedCompany := range seed.Companies {
+		company := domain.Company{
+			ID:        primitive.NewObjectID(),
+			Name:      seedCompany.Name,
+			User:      []primitive.ObjectID{},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		companies = append(companies, company)
+
+		for _, seedReport := range seedCompany.Reports {
+			typeID, ok := typeIDs[seedReport.ReportType]
+			if !ok {
+				return nil, errors.New("SANDBOX_SEED_INVALID", "Seed report names an unknown report type", 500, nil, map[string]interface{}{"reportType": seedReport.ReportType})
+			}
+			report := domain.Report{
+				ID:         primitive.NewObjectID(),
+				ReportName: seedReport.ReportName,
+				ReportType: typeID,
+				Year:       seedReport.Year,
+				Company:    company.ID,
+				UserAccess: []primitive.ObjectID{},
+				ReportData: seedReport.ReportData,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			if seedReport.Currency != "" {
+				currency := seedReport.Currency
+				report.Currency = &currency
+			}
+			reports = append(reports, report)
+		}
+	}
+	if err := insertAll(ctx, r.db.Collection(config.CollectionName("companies")), companies); err != nil {
+		return nil, err
+	}
+	if err := insertAll(ctx, r.db.Collection(config.CollectionName("reports")), reports); err != nil {
+		return nil, err
+	}
+	reset.Companies, reset.Reports = len(companies), len(reports)
+
+	_, err = r.state.UpdateByID(ctx, sandboxStateID, bson.M{"$set": reset}, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to record the sandbox reset", 500, err, nil)
+	}
+	return reset, nil
+}
+
+func insertAll[T any](ctx context.Context, collection *mongo.Collection, docs []T) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(docs))
+	for i := range docs {
+		values[i] = docs[i]
+	}
+	if _, err := collection.InsertMany(ctx, values); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to seed "+collection.Name(), 500, err, nil)
+	}
+	return nil
+}
+
+func (r *sandboxMongoRepository) ClaimReset(ctx context.Context, since time.Time) (bool, error) {
+	// The upsert only inserts when no state exists yet; a recent reset makes it collide
+	// with the existing document instead
+	_, err := r.state.UpdateOne(ctx,
+		bson.M{"_id": sandboxStateID, "resetAt": bson.M{"$lte": since}},
+		bson.M{"$set": bson.M{"resetAt": time.Now()}},
+		options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.New("DATABASE_ERROR", "Failed to claim the sandbox reset", 500, err, nil)
+	}
+	return true, nil
+}
+
+func (r *sandboxMongoRepository) LastReset(ctx context.Context) (*domain.SandboxReset, error) {
+	var reset domain.SandboxReset
+	err := r.state.FindOne(ctx, bson.M{"_id": sandboxStateID}).Decode(&reset)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to read the sandbox state", 500, err, nil)
+	}
+	return &reset, nil
+}
+
+func (r *sandboxMongoRepository) Companies(ctx context.Context) ([]primitive.ObjectID, error) {
+	ids, err := r.db.Collection(config.CollectionName("companies")).Distinct(ctx, "_id", bson.M{"deletedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to list sandbox companies", 500, err, nil)
+	}
+	companies := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			companies = append(companies, oid)
+		}
+	}
+	return companies, nil
+}
+
+func (r *sandboxMongoRepository) EnsureUser(ctx context.Context, user *domain.User) error {
+	_, err := r.db.Collection(config.CollectionName("users")).UpdateByID(ctx, user.ID, bson.M{
+		"$set": bson.M{
+			"name":      user.Name,
+			"email":     user.Email,
+			"role":      user.Role,
+			"updatedAt": time.Now(),
+		},
+		"$setOnInsert": bson.M{
+			"password":  "",
+			"company":   []primitive.ObjectID{},
+			"createdAt": time.Now(),
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to copy the user into the sandbox", 500, err, nil)
+	}
+	return nil
+}
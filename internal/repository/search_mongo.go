@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// searchCollection is how a kind of document is searched in its collection.
+type searchCollection struct {
+	kind       string
+	collection string
+	fields     []string
+	// filter limits the kind's documents to the query's filters and the reads of ctx; false
+	// when no document of the kind can match the query
+	filter  func(ctx context.Context, query domain.SearchQuery) (bson.M, bool)
+	project bson.M
+	facets  map[string]string // facet to field, from project
+}
+
+var searchCollections = []searchCollection{
+	{
+		kind:       domain.SearchKindReport,
+		collection: "reports",
+		fields:     []string{"reportName"},
+		filter: func(ctx context.Context, query domain.SearchQuery) (bson.M, bool) {
+			filter := bson.M{}
+			if query.Company != nil {
+				filter["company"] = *query.Company
+			}
+			if query.ReportType != nil {
+				filter["reportType"] = *query.ReportType
+			}
+			if query.Year != nil {
+				filter["year"] = *query.Year
+			}
+			return reportReadFilter(ctx, filter), true
+		},
+		project: bson.M{"title": "$reportName", "company": "$company", "reportType": "$reportType", "year": "$year"},
+		facets: map[string]string{
+			domain.SearchFacetCompany:    "$company",
+			domain.SearchFacetReportType: "$reportType",
+			domain.SearchFacetYear:       "$year",
+		},
+	},
+	{
+		kind:       domain.SearchKindCompany,
+		collection: "companies",
+		fields:     []string{"name"},
+		filter: func(ctx context.Context, query domain.SearchQuery) (bson.M, bool) {
+			if query.ReportType != nil || query.Year != nil {
+				return nil, false
+			}
+			filter := bson.M{}
+			if query.Company != nil {
+				filter["_id"] = *query.Company
+			}
+			return companyReadFilter(ctx, filter), true
+		},
+		project: bson.M{"title": "$name", "company": "$_id"},
+		facets:  map[string]string{domain.SearchFacetCompany: "$company"},
+	},
+	{
+		kind:       domain.SearchKindUser,
+		collection: "users",
+		fields:     []string{"name", "email"},
+		filter: func(ctx context.Context, query domain.SearchQuery) (bson.M, bool) {
+			if query.ReportType != nil || query.Year != nil {
+				return nil, false
+			}
+			filter := bson.M{}
+			if query.Company != nil {
+				filter["company"] = *query.Company
+			}
+			return userReadFilter(ctx, filter), true
+		},
+		project: bson.M{"title": "$name", "detail": "$email"},
+	},
+}
+
+type searchMongoRepository struct {
+	db         *mongo.Database
+	atlasIndex string
+}
+
+// NewSearchMongoRepository searches with Atlas Search when atlasIndex names the Atlas Search
+// index of the reports, companies and users collections, and with case-insensitive regular
+// expressions otherwise. Regular expressions neither rank matches nor tolerate typos.
+func NewSearchMongoRepository(db *mongo.Database, atlasIndex string) domain.SearchRepository {
+	return &searchMongoRepository{db: db, atlasIndex: atlasIndex}
+}
+
+func (r *searchMongoRepository) Backend() string {
+	if r.atlasIndex != "" {
+		return "atlas"
+	}
+	return "mongo"
+}
+
+func (r *searchMongoRepository) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResults, error) {
+	results := &domain.SearchResults{Hits: []domain.SearchHit{}, Facets: map[string][]domain.SearchFacetValue{}}
+	counts := map[string]map[string]int64{}
+
+	for _, c := range searchCollections {
+		if !searchesKind(query, c.kind) {
+			continue
+		}
+		filter, ok := c.filter(ctx, query)
+		if !ok {
+			continue
+		}
+
+		found, err := r.searchCollection(ctx, c, filter, query)
+		if err != nil {
+			return nil, err
+		}
+
+		if found.total > 0 {
+			results.Total += found.total
+			addFacetCount(counts, domain.SearchFacetKind, c.kind, found.total)
+		}
+		results.Hits = append(results.Hits, found.hits...)
+		for facet, values := range found.facets {
+			for value, count := range values {
+				addFacetCount(counts, facet, value, count)
+			}
+		}
+	}
+
+	// Scores of one backend are comparable across collections; unranked hits go by title
+	sort.SliceStable(results.Hits, func(i, j int) bool {
+		if results.Hits[i].Score != results.Hits[j].Score {
+			return results.Hits[i].Score > results.Hits[j].Score
+		}
+		return results.Hits[i].Title < results.Hits[j].Title
+	})
+	if len(results.Hits) > query.Limit {
+		results.Hits = results.Hits[:query.Limit]
+	}
+	for facet, values := range counts {
+		results.Facets[facet] = sortedFacet(values)
+	}
+
+	return results, nil
+}
+
+type collectionMatches struct {
+	total  int64
+	hits   []domain.SearchHit
+	facets map[string]map[string]int64
+}
+
+func (r *searchMongoRepository) searchCollection(ctx context.Context, c searchCollection, filter bson.M, query domain.SearchQuery) (*collectionMatches, error) {
+	project := bson.M{"score": bson.M{"$literal": 0}}
+	for field, value := range c.project {
+		project[field] = value
+	}
+
+	var pipeline []bson.M
+	if r.atlasIndex != "" {
+		pipeline = append(pipeline, bson.M{"$search": bson.M{
+			"index": r.atlasIndex,
+			"text": bson.M{
+				"query": query.Text,
+				"path":  c.fields,
+				"fuzzy": bson.M{"maxEdits": 1},
+			},
+		}})
+		project["score"] = bson.M{"$meta": "searchScore"}
+	} else {
+		pattern := regexp.QuoteMeta(query.Text)
+		conditions := make([]bson.M, len(c.fields))
+		for i, field := range c.fields {
+			conditions[i] = bson.M{field: bson.M{"$regex": pattern, "$options": "i"}}
+		}
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"$or": conditions}})
+	}
+
+	facets := bson.M{
+		"hits":  []bson.M{{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "title", Value: 1}}}, {"$limit": query.Limit}},
+		"total": []bson.M{{"$count": "n"}},
+	}
+	for facet, field := range c.facets {
+		facets[facet] = []bson.M{
+			{"$match": bson.M{field[1:]: bson.M{"$ne": nil}}},
+			{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+		}
+	}
+	pipeline = append(pipeline,
+		bson.M{"$match": filter},
+		bson.M{"$project": project},
+		bson.M{"$facet": facets},
+	)
+
+	cursor, err := r.db.Collection(config.CollectionName(c.collection)).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to search "+c.collection, 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var out []bson.Raw
+	if err := cursor.All(ctx, &out); err != nil || len(out) == 0 {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode search results", 500, err, nil)
+	}
+
+	var page struct {
+		Hits []struct {
+			ID         primitive.ObjectID  `bson:"_id"`
+			Title      string              `bson:"title"`
+			Detail     string              `bson:"detail"`
+			Company    *primitive.ObjectID `bson:"company"`
+			ReportType *primitive.ObjectID `bson:"reportType"`
+			Year       int                 `bson:"year"`
+			Score      float64             `bson:"score"`
+		} `bson:"hits"`
+		Total []struct {
+			N int64 `bson:"n"`
+		} `bson:"total"`
+	}
+	if err := bson.Unmarshal(out[0], &page); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode search results", 500, err, nil)
+	}
+
+	matches := &collectionMatches{hits: make([]domain.SearchHit, len(page.Hits)), facets: map[string]map[string]int64{}}
+	if len(page.Total) > 0 {
+		matches.total = page.Total[0].N
+	}
+	for i, hit := range page.Hits {
+		matches.hits[i] = domain.SearchHit{
+			Kind:       c.kind,
+			ID:         hit.ID,
+			Title:      hit.Title,
+			Detail:     hit.Detail,
+			Company:    hit.Company,
+			ReportType: hit.ReportType,
+			Year:       hit.Year,
+			Score:      hit.Score,
+		}
+	}
+	for facet := range c.facets {
+		var groups []struct {
+			Value interface{} `bson:"_id"`
+			Count int64       `bson:"count"`
+		}
+		if err := out[0].Lookup(facet).Unmarshal(&groups); err != nil {
+			return nil, errors.New("DATABASE_ERROR", "Failed to decode search results", 500, err, nil)
+		}
+		matches.facets[facet] = map[string]int64{}
+		for _, group := range groups {
+			matches.facets[facet][facetValue(group.Value)] += group.Count
+		}
+	}
+
+	return matches, nil
+}
+
+func searchesKind(query domain.SearchQuery, kind string) bool {
+	if len(query.Kinds) == 0 {
+		return true
+	}
+	for _, k := range query.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func addFacetCount(counts map[string]map[string]int64, facet, value string, count int64) {
+	if counts[facet] == nil {
+		counts[facet] = map[string]int64{}
+	}
+	counts[facet][value] += count
+}
+
+func sortedFacet(counts map[string]int64) []domain.SearchFacetValue {
+	values := make([]domain.SearchFacetValue, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, domain.SearchFacetValue{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	return values
+}
+
+func facetValue(value interface{}) string {
+	if id, ok := value.(primitive.ObjectID); ok {
+		return id.Hex()
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/log"
+)
+
+const searchIndexBatchSize = 500
+
+// searchSources maps the collections a search index follows to the kind of their documents.
+var searchSources = map[string]string{
+	"reports":   domain.SearchKindReport,
+	"companies": domain.SearchKindCompany,
+	"users":     domain.SearchKindUser,
+}
+
+// SyncSearchIndex fills index with the live reports, companies and users, then keeps it in
+// sync through a change stream until ctx is cancelled. Deployments without change streams
+// (standalone servers) are indexed once at startup only.
+func SyncSearchIndex(ctx context.Context, db *mongo.Database, index domain.SearchIndex) {
+	collections := bson.A{}
+	kinds := map[string]string{}
+	for name, kind := range searchSources {
+		collections = append(collections, config.CollectionName(name))
+		kinds[config.CollectionName(name)] = kind
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ns.coll":       bson.M{"$in": collections},
+			"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+		}}},
+	}
+
+	for ctx.Err() == nil {
+		// The stream is opened before the backfill so nothing written during it is missed
+		stream, err := db.Watch(ctx, pipeline, options.ChangeStream().
+			SetFullDocument(options.UpdateLookup).
+			SetMaxAwaitTime(time.Second))
+		if err != nil {
+			log.Warnf(ctx, "Search index: change streams unavailable, indexing once: %v", err)
+			backfillSearchIndex(ctx, db, index)
+			return
+		}
+		backfillSearchIndex(ctx, db, index)
+
+		for stream.Next(ctx) {
+			var event struct {
+				OperationType string `bson:"operationType"`
+				Ns            struct {
+					Coll string `bson:"coll"`
+				} `bson:"ns"`
+				DocumentKey struct {
+					ID primitive.ObjectID `bson:"_id"`
+				} `bson:"documentKey"`
+				FullDocument bson.Raw `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&event); err != nil {
+				log.Warnf(ctx, "Search index: failed to decode change: %v", err)
+				continue
+			}
+
+			kind := kinds[event.Ns.Coll]
+			if document, ok := searchDocument(kind, event.FullDocument); ok {
+				err = index.Index(ctx, []domain.SearchDocument{document})
+			} else {
+				// Deleted, soft deleted, or gone by the time the update was looked up
+				err = index.Remove(ctx, kind, event.DocumentKey.ID)
+			}
+			if err != nil {
+				log.Warnf(ctx, "Search index: failed to apply %s of %s %s: %v", event.OperationType, kind, event.DocumentKey.ID.Hex(), err)
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Warnf(ctx, "Search index: change stream interrupted, restarting: %v", err)
+			time.Sleep(time.Second)
+		}
+		stream.Close(context.Background())
+	}
+}
+
+// backfillSearchIndex indexes every live document. Documents removed while the index wasn't
+// followed stay until they change again.
+func backfillSearchIndex(ctx context.Context, db *mongo.Database, index domain.SearchIndex) {
+	for name, kind := range searchSources {
+		cursor, err := db.Collection(config.CollectionName(name)).Find(ctx, bson.M{"deletedAt": nil})
+		if err != nil {
+			log.Warnf(ctx, "Search index: failed to read %s: %v", name, err)
+			continue
+		}
+
+		indexed := 0
+		batch := make([]domain.SearchDocument, 0, searchIndexBatchSize)
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			if err := index.Index(ctx, batch); err != nil {
+				log.Warnf(ctx, "Search index: failed to index %s: %v", name, err)
+			} else {
+				indexed += len(batch)
+			}
+			batch = batch[:0]
+		}
+		for cursor.Next(ctx) {
+			if document, ok := searchDocument(kind, cursor.Current); ok {
+				batch = append(batch, document)
+			}
+			if len(batch) == searchIndexBatchSize {
+				flush()
+			}
+		}
+		flush()
+		if err := cursor.Err(); err != nil {
+			log.Warnf(ctx, "Search index: failed to read %s: %v", name, err)
+		}
+		cursor.Close(ctx)
+
+		log.Infof(ctx, "Search index: indexed %d %s", indexed, name)
+	}
+}
+
+// searchDocument converts a stored report, company or user to what the search index holds,
+// or returns false when it is missing or soft deleted.
+func searchDocument(kind string, raw bson.Raw) (domain.SearchDocument, bool) {
+	if len(raw) == 0 {
+		return domain.SearchDocument{}, false
+	}
+
+	switch kind {
+	case domain.SearchKindReport:
+		var report domain.Report
+		if err := bson.Unmarshal(raw, &report); err != nil || report.DeletedAt != nil {
+			return domain.SearchDocument{}, false
+		}
+		return domain.SearchDocument{
+			Kind:       kind,
+			ID:         report.ID,
+			Title:      report.ReportName,
+			Company:    &report.Company,
+			ReportType: &report.ReportType,
+			Year:       report.Year,
+			CreatedBy:  &report.CreatedBy,
+			Users:      report.UserAccess,
+		}, true
+	case domain.SearchKindCompany:
+		var company domain.Company
+		if err := bson.Unmarshal(raw, &company); err != nil || company.DeletedAt != nil {
+			return domain.SearchDocument{}, false
+		}
+		return domain.SearchDocument{
+			Kind:         kind,
+			ID:           company.ID,
+			Title:        company.Name,
+			Organization: company.Organization,
+			Company:      &company.ID,
+			Users:        company.User,
+		}, true
+	case domain.SearchKindUser:
+		var user domain.User
+		if err := bson.Unmarshal(raw, &user); err != nil || user.DeletedAt != nil {
+			return domain.SearchDocument{}, false
+		}
+		return domain.SearchDocument{
+			Kind:         kind,
+			ID:           user.ID,
+			Title:        user.Name,
+			Detail:       user.Email,
+			Organization: user.Organization,
+			Companies:    user.Company,
+		}, true
+	}
+	return domain.SearchDocument{}, false
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type sessionMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSessionMongoRepository(db *mongo.Database) domain.SessionRepository {
+	return &sessionMongoRepository{
+		collection: db.Collection(config.CollectionName("sessions")),
+	}
+}
+
+func (r *sessionMongoRepository) Create(ctx context.Context, session *domain.Session) error {
+	session.CreatedAt = time.Now()
+	if session.LastSeenAt.IsZero() {
+		session.LastSeenAt = session.CreatedAt
+	}
+
+	result, err := r.collection.InsertOne(ctx, session)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to record session", 500, err, nil)
+	}
+
+	session.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *sessionMongoRepository) GetByUser(ctx context.Context, userID primitive.ObjectID) ([]*domain.Session, error) {
+	filter := bson.M{"userId": userID, "expiresAt": bson.M{"$gt": time.Now()}}
+	opts := options.Find().SetSort(bson.D{{Key: "lastSeenAt", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get sessions", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []*domain.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode sessions", 500, err, nil)
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionMongoRepository) GetByID(ctx context.Context, userID, id primitive.ObjectID) (*domain.Session, error) {
+	var session domain.Session
+	filter := bson.M{"_id": id, "userId": userID, "expiresAt": bson.M{"$gt": time.Now()}}
+	if err := r.collection.FindOne(ctx, filter).Decode(&session); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("SESSION_NOT_FOUND", "Session not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get session", 500, err, nil)
+	}
+	return &session, nil
+}
+
+func (r *sessionMongoRepository) Touch(ctx context.Context, tokenID string, seenAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"tokenId": tokenID, "lastSeenAt": bson.M{"$lt": seenAt}},
+		bson.M{"$set": bson.M{"lastSeenAt": seenAt}})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update session", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *sessionMongoRepository) Delete(ctx context.Context, tokenID string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"tokenId": tokenID}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete session", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *sessionMongoRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"userId": userID}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete sessions", 500, err, nil)
+	}
+	return nil
+}
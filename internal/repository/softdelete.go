@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/domain"
+)
+
+// scopeFilter adds the soft-delete condition to a read filter unless the context asks for deleted documents.
+func scopeFilter(ctx context.Context, filter bson.M) bson.M {
+	if domain.IncludesDeleted(ctx) {
+		return filter
+	}
+
+	scoped := bson.M{}
+	for k, v := range filter {
+		scoped[k] = v
+	}
+	scoped["deletedAt"] = nil
+	return scoped
+}
+
+// softDelete marks a single live document as deleted instead of removing it.
+func softDelete(ctx context.Context, collection *mongo.Collection, filter bson.M) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{"deletedAt": time.Now()}}
+	return collection.UpdateOne(ctx, scopeFilter(context.Background(), filter), update)
+}
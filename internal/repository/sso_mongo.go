@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type ssoMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSSOConnectionMongoRepository(db *mongo.Database) domain.SSOConnectionRepository {
+	return &ssoMongoRepository{
+		collection: db.Collection(config.CollectionName("sso_connections")),
+	}
+}
+
+func (r *ssoMongoRepository) Get(ctx context.Context, companyID primitive.ObjectID) (*domain.SSOConnection, error) {
+	return r.findOne(ctx, bson.M{"_id": companyID})
+}
+
+func (r *ssoMongoRepository) GetByDomain(ctx context.Context, domain string) (*domain.SSOConnection, error) {
+	return r.findOne(ctx, bson.M{"domains": domain})
+}
+
+func (r *ssoMongoRepository) findOne(ctx context.Context, filter bson.M) (*domain.SSOConnection, error) {
+	var connection domain.SSOConnection
+	if err := r.collection.FindOne(ctx, filter).Decode(&connection); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("SSO_CONNECTION_NOT_FOUND", "Single sign-on is not set up", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get SSO connection", 500, err, nil)
+	}
+	return &connection, nil
+}
+
+func (r *ssoMongoRepository) Save(ctx context.Context, connection *domain.SSOConnection) error {
+	now := time.Now()
+	if connection.CreatedAt.IsZero() {
+		connection.CreatedAt = now
+	}
+	connection.UpdatedAt = now
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": connection.CompanyID}, connection, options.Replace().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("SSO_DOMAIN_TAKEN", "Another company signs in users of this domain", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to save SSO connection", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *ssoMongoRepository) Delete(ctx context.Context, companyID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": companyID})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete SSO connection", 500, err, nil)
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("SSO_CONNECTION_NOT_FOUND", "Single sign-on is not set up", 404, nil, nil)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type taskMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTaskMongoRepository(db *mongo.Database) domain.TaskRepository {
+	return &taskMongoRepository{
+		collection: db.Collection(config.CollectionName("tasks")),
+	}
+}
+
+func (r *taskMongoRepository) Create(ctx context.Context, task *domain.Task) error {
+	now := time.Now()
+	task.Status = domain.TaskStatusPending
+	task.RunAt = now
+	task.CreatedAt = now
+	task.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, task)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create task", 500, err, nil)
+	}
+
+	task.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *taskMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	var task domain.Task
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&task); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("TASK_NOT_FOUND", "Task not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get task", 500, err, nil)
+	}
+	return &task, nil
+}
+
+// ListByUser returns the user's most recent tasks first.
+func (r *taskMongoRepository) ListByUser(ctx context.Context, userID primitive.ObjectID, limit int) ([]*domain.Task, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"createdBy": userID}, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get tasks", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []*domain.Task
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode tasks", 500, err, nil)
+	}
+
+	return tasks, nil
+}
+
+func (r *taskMongoRepository) CountByStatus(ctx context.Context, status domain.TaskStatus) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": status})
+	if err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to count tasks", 500, err, nil)
+	}
+	return count, nil
+}
+
+func (r *taskMongoRepository) Claim(ctx context.Context, types []domain.TaskType, lockedUntil time.Time) (*domain.Task, error) {
+	now := time.Now()
+	filter := bson.M{
+		"type": bson.M{"$in": types},
+		"$or": bson.A{
+			bson.M{"status": domain.TaskStatusPending, "runAt": bson.M{"$lte": now}},
+			bson.M{"status": domain.TaskStatusRunning, "lockedUntil": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      domain.TaskStatusRunning,
+			"lockedUntil": lockedUntil,
+			"startedAt":   now,
+			"updatedAt":   now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "runAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var task domain.Task
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&task); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to claim task", 500, err, nil)
+	}
+	return &task, nil
+}
+
+func (r *taskMongoRepository) UpdateProgress(ctx context.Context, id primitive.ObjectID, progress int, lockedUntil time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"progress":    progress,
+			"lockedUntil": lockedUntil,
+			"updatedAt":   time.Now(),
+		},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": domain.TaskStatusRunning}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update task progress", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *taskMongoRepository) Complete(ctx context.Context, id primitive.ObjectID, result []byte) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":     domain.TaskStatusSucceeded,
+			"progress":   100,
+			"result":     result,
+			"finishedAt": now,
+			"updatedAt":  now,
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"error": "", "lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to complete task", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *taskMongoRepository) Fail(ctx context.Context, id primitive.ObjectID, reason string, runAt time.Time, final bool) error {
+	now := time.Now()
+	set := bson.M{
+		"status":    domain.TaskStatusPending,
+		"error":     reason,
+		"runAt":     runAt,
+		"updatedAt": now,
+	}
+	if final {
+		set["status"] = domain.TaskStatusFailed
+		set["finishedAt"] = now
+	}
+
+	update := bson.M{
+		"$set":   set,
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to record task failure", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *taskMongoRepository) Release(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	update := bson.M{
+		"$set":   bson.M{"status": domain.TaskStatusPending, "runAt": now, "updatedAt": now},
+		"$unset": bson.M{"lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": domain.TaskStatusRunning}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to release task", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type taxRateMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTaxRateMongoRepository(db *mongo.Database) domain.TaxRateRepository {
+	return &taxRateMongoRepository{
+		collection: db.Collection(config.CollectionName("tax_rates")),
+	}
+}
+
+func (r *taxRateMongoRepository) Create(ctx context.Context, rate *domain.TaxRate) error {
+	rate.CreatedAt = time.Now()
+	rate.UpdatedAt = rate.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, rate)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create tax rate", 500, err, nil)
+	}
+
+	rate.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *taxRateMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.TaxRate, error) {
+	var rate domain.TaxRate
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rate); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("TAX_RATE_NOT_FOUND", "Tax rate not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get tax rate", 500, err, nil)
+	}
+	return &rate, nil
+}
+
+func (r *taxRateMongoRepository) GetAll(ctx context.Context, jurisdictions []string) ([]*domain.TaxRate, error) {
+	filter := bson.M{}
+	if len(jurisdictions) > 0 {
+		filter["jurisdiction"] = bson.M{"$in": jurisdictions}
+	}
+	opts := options.Find().SetSort(bson.D{
+		{Key: "jurisdiction", Value: 1}, {Key: "name", Value: 1}, {Key: "effectiveFrom", Value: -1},
+	})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get tax rates", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	rates := []*domain.TaxRate{}
+	if err = cursor.All(ctx, &rates); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode tax rates", 500, err, nil)
+	}
+
+	return rates, nil
+}
+
+func (r *taxRateMongoRepository) Update(ctx context.Context, id primitive.ObjectID, rate *domain.TaxRate) error {
+	rate.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":          rate.Name,
+			"rate":          rate.Rate,
+			"effectiveFrom": rate.EffectiveFrom,
+			"effectiveTo":   rate.EffectiveTo,
+			"check":         rate.Check,
+			"tolerance":     rate.Tolerance,
+			"reportType":    rate.ReportType,
+			"updatedBy":     rate.UpdatedBy,
+			"updatedAt":     rate.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update tax rate", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("TAX_RATE_NOT_FOUND", "Tax rate not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *taxRateMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete tax rate", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("TAX_RATE_NOT_FOUND", "Tax rate not found", 404, nil, nil)
+	}
+
+	return nil
+}
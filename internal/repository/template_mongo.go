@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type templateMongoRepository struct {
+	templates *mongo.Collection
+	versions  *mongo.Collection
+}
+
+func NewReportTemplateMongoRepository(db *mongo.Database) domain.ReportTemplateRepository {
+	return &templateMongoRepository{
+		templates: db.Collection(config.CollectionName("report_templates")),
+		versions:  db.Collection(config.CollectionName("report_template_versions")),
+	}
+}
+
+// Create stores the template in the organization it is stamped with, which must be the tenant's.
+func (r *templateMongoRepository) Create(ctx context.Context, template *domain.ReportTemplate, note string) error {
+	if tenant := domain.TenantOf(ctx); tenant != nil && !tenant.Owns(template.Organization) {
+		return errors.New("ORGANIZATION_NOT_FOUND", "Organization not found", 404, nil, nil)
+	}
+
+	template.Version = 1
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+
+	result, err := r.templates.InsertOne(ctx, template)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("TEMPLATE_ALREADY_EXISTS", "The library already has a template with this name", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to create template", 500, err, nil)
+	}
+
+	template.ID = result.InsertedID.(primitive.ObjectID)
+	return r.saveVersion(ctx, template, note)
+}
+
+func (r *templateMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ReportTemplate, error) {
+	var template domain.ReportTemplate
+	if err := r.templates.FindOne(ctx, tenantFilter(ctx, bson.M{"_id": id})).Decode(&template); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("TEMPLATE_NOT_FOUND", "Template not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get template", 500, err, nil)
+	}
+	return &template, nil
+}
+
+func (r *templateMongoRepository) GetAll(ctx context.Context) ([]*domain.ReportTemplate, error) {
+	// Accounts are left out of lists; fetch one template for them
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetProjection(bson.M{"accounts": 0})
+
+	cursor, err := r.templates.Find(ctx, tenantFilter(ctx, bson.M{}), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get templates", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	templates := []*domain.ReportTemplate{}
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode templates", 500, err, nil)
+	}
+
+	return templates, nil
+}
+
+func (r *templateMongoRepository) Update(ctx context.Context, template *domain.ReportTemplate) error {
+	template.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":        template.Name,
+			"description": template.Description,
+			"reportType":  template.ReportType,
+			"updatedAt":   template.UpdatedAt,
+		},
+	}
+
+	result, err := r.templates.UpdateOne(ctx, tenantFilter(ctx, bson.M{"_id": template.ID}), update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("TEMPLATE_ALREADY_EXISTS", "The library already has a template with this name", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to update template", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("TEMPLATE_NOT_FOUND", "Template not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+// Publish matches the template on the version it was read at, so of two concurrent
+// publications the second fails instead of overwriting the first.
+func (r *templateMongoRepository) Publish(ctx context.Context, template *domain.ReportTemplate, note string) error {
+	template.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"accounts":    template.Accounts,
+			"publishedBy": template.PublishedBy,
+			"updatedAt":   template.UpdatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := r.templates.UpdateOne(ctx, tenantFilter(ctx, bson.M{"_id": template.ID, "version": template.Version}), update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to publish template", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.GetByID(ctx, template.ID); err != nil {
+			return err
+		}
+		return errors.New("TEMPLATE_VERSION_CONFLICT", "The template was published since this version was read", 409, nil, nil)
+	}
+
+	template.Version++
+	return r.saveVersion(ctx, template, note)
+}
+
+func (r *templateMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.templates.DeleteOne(ctx, tenantFilter(ctx, bson.M{"_id": id}))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete template", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("TEMPLATE_NOT_FOUND", "Template not found", 404, nil, nil)
+	}
+
+	if _, err := r.versions.DeleteMany(ctx, bson.M{"template": id}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete template versions", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *templateMongoRepository) GetVersions(ctx context.Context, id primitive.ObjectID) ([]*domain.ReportTemplateVersion, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"accounts": 0})
+
+	cursor, err := r.versions.Find(ctx, tenantFilter(ctx, bson.M{"template": id}), opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get template versions", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	versions := []*domain.ReportTemplateVersion{}
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode template versions", 500, err, nil)
+	}
+
+	return versions, nil
+}
+
+func (r *templateMongoRepository) GetVersion(ctx context.Context, id primitive.ObjectID, version int) (*domain.ReportTemplateVersion, error) {
+	var templateVersion domain.ReportTemplateVersion
+	filter := tenantFilter(ctx, bson.M{"template": id, "version": version})
+	if err := r.versions.FindOne(ctx, filter).Decode(&templateVersion); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("TEMPLATE_VERSION_NOT_FOUND", "Template version not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get template version", 500, err, nil)
+	}
+	return &templateVersion, nil
+}
+
+func (r *templateMongoRepository) CountCopy(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.templates.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"copies": 1}}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update template", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *templateMongoRepository) saveVersion(ctx context.Context, template *domain.ReportTemplate, note string) error {
+	version := &domain.ReportTemplateVersion{
+		Template:     template.ID,
+		Organization: template.Organization,
+		Version:      template.Version,
+		Accounts:     template.Accounts,
+		Note:         note,
+		PublishedBy:  template.PublishedBy,
+		PublishedAt:  template.UpdatedAt,
+	}
+
+	if _, err := r.versions.InsertOne(ctx, version); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save template version", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type tokenMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSecurityTokenMongoRepository(db *mongo.Database) domain.SecurityTokenRepository {
+	return &tokenMongoRepository{
+		collection: db.Collection(config.CollectionName("securitytokens")),
+	}
+}
+
+func (r *tokenMongoRepository) Create(ctx context.Context, token *domain.SecurityToken) error {
+	token.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create token", 500, err, nil)
+	}
+
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetValid also checks expiresAt itself because the TTL monitor only runs about once a minute.
+func (r *tokenMongoRepository) GetValid(ctx context.Context, kind domain.TokenKind, token string) (*domain.SecurityToken, error) {
+	filter := bson.M{
+		"kind":      kind,
+		"token":     token,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	var found domain.SecurityToken
+	if err := r.collection.FindOne(ctx, filter).Decode(&found); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("INVALID_TOKEN", "Invalid or expired token", 400, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get token", 500, err, nil)
+	}
+
+	return &found, nil
+}
+
+func (r *tokenMongoRepository) Delete(ctx context.Context, kind domain.TokenKind, token string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"kind": kind, "token": token}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete token", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *tokenMongoRepository) DeleteByUser(ctx context.Context, kind domain.TokenKind, userID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"kind": kind, "userId": userID}); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete tokens", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type tokenPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewSecurityTokenPostgresRepository(db *sql.DB) domain.SecurityTokenRepository {
+	return &tokenPostgresRepository{db: db}
+}
+
+// Create also purges expired tokens, since Postgres has no TTL index.
+func (r *tokenPostgresRepository) Create(ctx context.Context, token *domain.SecurityToken) error {
+	token.ID = primitive.NewObjectID()
+	token.CreatedAt = time.Now()
+
+	conn := pgConn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM security_tokens WHERE expires_at <= now()`); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to purge expired tokens", 500, err, nil)
+	}
+
+	_, err := conn.ExecContext(ctx, `INSERT INTO security_tokens (id, kind, token, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		token.ID.Hex(), string(token.Kind), token.Token, token.UserID.Hex(), token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create token", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *tokenPostgresRepository) GetValid(ctx context.Context, kind domain.TokenKind, token string) (*domain.SecurityToken, error) {
+	var (
+		found      domain.SecurityToken
+		id, userID string
+	)
+	err := pgConn(ctx, r.db).QueryRowContext(ctx, `SELECT id, token, user_id, expires_at, created_at
+		FROM security_tokens WHERE kind = $1 AND token = $2 AND expires_at > now()`, string(kind), token).
+		Scan(&id, &found.Token, &userID, &found.ExpiresAt, &found.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("INVALID_TOKEN", "Invalid or expired token", 400, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get token", 500, err, nil)
+	}
+
+	found.ID = parseID(id)
+	found.Kind = kind
+	found.UserID = parseID(userID)
+	return &found, nil
+}
+
+func (r *tokenPostgresRepository) Delete(ctx context.Context, kind domain.TokenKind, token string) error {
+	_, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM security_tokens WHERE kind = $1 AND token = $2`, string(kind), token)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete token", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *tokenPostgresRepository) DeleteByUser(ctx context.Context, kind domain.TokenKind, userID primitive.ObjectID) error {
+	_, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM security_tokens WHERE kind = $1 AND user_id = $2`, string(kind), userID.Hex())
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete tokens", 500, err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type mongoTransactor struct {
+	client  *mongo.Client
+	enabled bool
+}
+
+// NewMongoTransactor creates a Transactor backed by Mongo sessions. Transactions need a replica set;
+// with enabled=false (standalone servers) fn runs without a transaction.
+func NewMongoTransactor(client *mongo.Client, enabled bool) domain.Transactor {
+	return &mongoTransactor{
+		client:  client,
+		enabled: enabled,
+	}
+}
+
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !t.enabled {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to start database session", 500, err, nil)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if err != nil {
+		if _, ok := err.(errors.AppError); ok {
+			return err
+		}
+		return errors.New("DATABASE_ERROR", "Database transaction failed", 500, err, nil)
+	}
+
+	return nil
+}
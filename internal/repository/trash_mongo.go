@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type trashMongoRepository struct {
+	db *mongo.Database
+}
+
+func NewTrashMongoRepository(db *mongo.Database) domain.TrashRepository {
+	return &trashMongoRepository{db: db}
+}
+
+func (r *trashMongoRepository) Purge(ctx context.Context, collection string, deletedBefore time.Time) (int64, error) {
+	result, err := r.db.Collection(config.CollectionName(collection)).
+		DeleteMany(ctx, bson.M{"deletedAt": bson.M{"$lt": deletedBefore}})
+	if err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to purge "+collection, 500, err, nil)
+	}
+	return result.DeletedCount, nil
+}
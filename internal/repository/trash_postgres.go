@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// pgTrashTables maps the logical collection names used across drivers to Postgres tables.
+var pgTrashTables = map[string]string{
+	"users":       "users",
+	"companies":   "companies",
+	"reports":     "reports",
+	"reporttypes": "report_types",
+}
+
+type trashPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewTrashPostgresRepository(db *sql.DB) domain.TrashRepository {
+	return &trashPostgresRepository{db: db}
+}
+
+func (r *trashPostgresRepository) Purge(ctx context.Context, collection string, deletedBefore time.Time) (int64, error) {
+	table, ok := pgTrashTables[collection]
+	if !ok {
+		return 0, errors.New("INVALID_COLLECTION", "Collection does not support soft delete", 400, nil, map[string]interface{}{"collection": collection})
+	}
+
+	result, err := pgConn(ctx, r.db).ExecContext(ctx, `DELETE FROM `+table+` WHERE deleted_at < $1`, deletedBefore)
+	if err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to purge "+collection, 500, err, nil)
+	}
+
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
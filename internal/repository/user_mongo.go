@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"finsolvz-backend/internal/config"
 	"finsolvz-backend/internal/domain"
 	"finsolvz-backend/internal/utils/errors"
 )
@@ -18,13 +20,16 @@ type userMongoRepository struct {
 
 func NewUserMongoRepository(db *mongo.Database) domain.UserRepository {
 	return &userMongoRepository{
-		collection: db.Collection("users"),
+		collection: db.Collection(config.CollectionName("users")),
 	}
 }
 
 func (r *userMongoRepository) Create(ctx context.Context, user *domain.User) error {
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	if tenant := domain.TenantOf(ctx); tenant != nil {
+		user.Organization = tenant.Stamp()
+	}
 
 	result, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
@@ -40,7 +45,7 @@ func (r *userMongoRepository) Create(ctx context.Context, user *domain.User) err
 
 func (r *userMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
 	var user domain.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	err := r.collection.FindOne(ctx, userReadFilter(ctx, bson.M{"_id": id})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("USER_NOT_FOUND", "User not found", 404, err, nil)
@@ -52,7 +57,7 @@ func (r *userMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID
 
 func (r *userMongoRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, scopeFilter(ctx, bson.M{"email": email})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("USER_NOT_FOUND", "User not found", 404, err, nil)
@@ -62,18 +67,124 @@ func (r *userMongoRepository) GetByEmail(ctx context.Context, email string) (*do
 	return &user, nil
 }
 
+func (r *userMongoRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.User, error) {
+	cursor, err := r.collection.Find(ctx, userReadFilter(ctx, bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode users", 500, err, nil)
+	}
+
+	return users, nil
+}
+
 // GetAll retrieves all users with normalized company field handling for legacy data compatibility.
 func (r *userMongoRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
-	// Aggregation pipeline to normalize company field formats
+	cursor, err := r.collection.Aggregate(ctx, readPipeline(userReadFilter(ctx, bson.M{}), r.listPipeline()))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode users", 500, err, nil)
+	}
+
+	return users, nil
+}
+
+func (r *userMongoRepository) GetPage(ctx context.Context, query domain.UserQuery) ([]*domain.User, int, error) {
+	match := bson.M{}
+	if query.Search != "" {
+		pattern := regexp.QuoteMeta(query.Search)
+		match["$or"] = []bson.M{
+			{"name": bson.M{"$regex": pattern, "$options": "i"}},
+			{"email": bson.M{"$regex": pattern, "$options": "i"}},
+		}
+	}
+	if query.Role != "" {
+		match["role"] = query.Role
+	}
+	filter := userReadFilter(ctx, match)
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count users", 500, err, nil)
+	}
+
+	sortField, order := query.Sort, 1
+	if sortField == "" {
+		sortField = "createdAt"
+	}
+	if query.Desc {
+		order = -1
+	}
+	// Sorting and paging before normalizing the companies only normalizes the page
 	pipeline := []bson.M{
+		{"$match": filter},
+		{"$sort": bson.D{{Key: sortField, Value: order}, {Key: "_id", Value: order}}},
+		{"$skip": query.Skip},
+	}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": query.Limit})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, append(pipeline, r.listPipeline()...))
+	if err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	users := []*domain.User{}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to decode users", 500, err, nil)
+	}
+
+	return users, int(total), nil
+}
+
+func (r *userMongoRepository) Each(ctx context.Context, fn func(*domain.User) error) error {
+	cursor, err := r.collection.Aggregate(ctx, readPipeline(userReadFilter(ctx, bson.M{}), r.listPipeline()))
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user domain.User
+		if err := cursor.Decode(&user); err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to decode users", 500, err, nil)
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+
+	return nil
+}
+
+// listPipeline normalizes the legacy formats of the company field.
+func (r *userMongoRepository) listPipeline() []bson.M {
+	return []bson.M{
 		{
 			"$project": bson.M{
-				"_id":       1,
-				"name":      1,
-				"email":     1,
-				"role":      1,
-				"createdAt": 1,
-				"updatedAt": 1,
+				"_id":           1,
+				"name":          1,
+				"email":         1,
+				"role":          1,
+				"organization":  1,
+				"deactivatedAt": 1,
+				"invitedAt":     1,
+				"createdAt":     1,
+				"updatedAt":     1,
 				"company": bson.M{
 					"$switch": bson.M{
 						"branches": []bson.M{
@@ -104,19 +215,6 @@ func (r *userMongoRepository) GetAll(ctx context.Context) ([]*domain.User, error
 			},
 		},
 	}
-
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
-	}
-	defer cursor.Close(ctx)
-
-	var users []*domain.User
-	if err = cursor.All(ctx, &users); err != nil {
-		return nil, errors.New("DATABASE_ERROR", "Failed to decode users", 500, err, nil)
-	}
-
-	return users, nil
 }
 
 func (r *userMongoRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
@@ -124,19 +222,50 @@ func (r *userMongoRepository) Update(ctx context.Context, id primitive.ObjectID,
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":      user.Name,
-			"email":     user.Email,
-			"role":      user.Role,
-			"company":   user.Company,
-			"updatedAt": user.UpdatedAt,
+			"name":        user.Name,
+			"email":       user.Email,
+			"role":        user.Role,
+			"company":     user.Company,
+			"locale":      user.Locale,
+			"phone":       user.Phone,
+			"preferences": user.Preferences,
+			"avatar":      user.Avatar,
+			"avatarThumb": user.AvatarThumb,
+			"updatedAt":   user.UpdatedAt,
 		},
 	}
 
+	if user.SessionsRevokedAt != nil {
+		update["$set"].(bson.M)["sessionsRevokedAt"] = user.SessionsRevokedAt
+	}
+
+	unset := bson.M{}
+	if user.DeactivatedAt != nil {
+		update["$set"].(bson.M)["deactivatedAt"] = user.DeactivatedAt
+	} else {
+		unset["deactivatedAt"] = ""
+	}
+
+	if user.InvitedAt != nil {
+		update["$set"].(bson.M)["invitedAt"] = user.InvitedAt
+	} else {
+		unset["invitedAt"] = ""
+	}
+	update["$unset"] = unset
+
+	if user.Consents != nil {
+		update["$set"].(bson.M)["consents"] = user.Consents
+	}
+
+	if user.Identities != nil {
+		update["$set"].(bson.M)["identities"] = user.Identities
+	}
+
 	if user.Password != "" {
 		update["$set"].(bson.M)["password"] = user.Password
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, userReadFilter(ctx, bson.M{"_id": id}), update)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return errors.New("EMAIL_ALREADY_EXISTS", "Email already used by another user", 409, err, nil)
@@ -152,53 +281,14 @@ func (r *userMongoRepository) Update(ctx context.Context, id primitive.ObjectID,
 }
 
 func (r *userMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	result, err := softDelete(ctx, r.collection, tenantFilter(ctx, bson.M{"_id": id}))
 	if err != nil {
 		return errors.New("DATABASE_ERROR", "Failed to delete user", 500, err, nil)
 	}
 
-	if result.DeletedCount == 0 {
-		return errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil)
-	}
-
-	return nil
-}
-
-func (r *userMongoRepository) SetResetToken(ctx context.Context, email, token string, expires time.Time) error {
-	update := bson.M{
-		"$set": bson.M{
-			"resetPasswordToken":   token,
-			"resetPasswordExpires": expires,
-			"updatedAt":            time.Now(),
-		},
-	}
-
-	result, err := r.collection.UpdateOne(ctx, bson.M{"email": email}, update)
-	if err != nil {
-		return errors.New("DATABASE_ERROR", "Failed to set reset token", 500, err, nil)
-	}
-
 	if result.MatchedCount == 0 {
 		return errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil)
 	}
 
 	return nil
 }
-
-func (r *userMongoRepository) GetByResetToken(ctx context.Context, token string) (*domain.User, error) {
-	var user domain.User
-	filter := bson.M{
-		"resetPasswordToken":   token,
-		"resetPasswordExpires": bson.M{"$gt": time.Now()},
-	}
-
-	err := r.collection.FindOne(ctx, filter).Decode(&user)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("INVALID_TOKEN", "Invalid or expired token", 400, err, nil)
-		}
-		return nil, errors.New("DATABASE_ERROR", "Failed to get user by reset token", 500, err, nil)
-	}
-
-	return &user, nil
-}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const userColumns = `id, name, email, password, role, company, locale, phone, preferences, avatar, avatar_thumb, sessions_revoked_at, consents, identities, created_at, updated_at, deleted_at, deactivated_at`
+
+type userPostgresRepository struct {
+	db *sql.DB
+}
+
+func NewUserPostgresRepository(db *sql.DB) domain.UserRepository {
+	return &userPostgresRepository{db: db}
+}
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*domain.User, error) {
+	var (
+		user                 domain.User
+		id                   string
+		company, preferences []byte
+		consents, identities []byte
+	)
+	if err := row.Scan(&id, &user.Name, &user.Email, &user.Password, &user.Role, &company, &user.Locale, &user.Phone, &preferences, &user.Avatar, &user.AvatarThumb,
+		&user.SessionsRevokedAt, &consents, &identities, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt, &user.DeactivatedAt); err != nil {
+		return nil, err
+	}
+	user.ID = parseID(id)
+	user.Company = decodeIDs(company)
+	if err := json.Unmarshal(preferences, &user.Preferences); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(consents, &user.Consents); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(identities, &user.Identities); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userPostgresRepository) Create(ctx context.Context, user *domain.User) error {
+	user.ID = primitive.NewObjectID()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	preferences, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode user preferences", 500, err, nil)
+	}
+	consents, err := encodeConsents(user.Consents)
+	if err != nil {
+		return err
+	}
+	identities, err := encodeIdentities(user.Identities)
+	if err != nil {
+		return err
+	}
+
+	_, err = pgConn(ctx, r.db).ExecContext(ctx, `INSERT INTO users (`+userColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		user.ID.Hex(), user.Name, user.Email, user.Password, user.Role, encodeIDs(user.Company), user.Locale, user.Phone, preferences,
+		user.Avatar, user.AvatarThumb, user.SessionsRevokedAt, consents, identities,
+		user.CreatedAt, user.UpdatedAt, user.DeletedAt, user.DeactivatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("USER_ALREADY_EXISTS", "Email already registered", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to create user", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *userPostgresRepository) getOne(ctx context.Context, where string, arg interface{}) (*domain.User, error) {
+	row := pgConn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE `+where+` AND `+pgNotDeleted(ctx, ""), arg)
+
+	user, err := scanUser(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("USER_NOT_FOUND", "User not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get user", 500, err, nil)
+	}
+	return user, nil
+}
+
+func (r *userPostgresRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	return r.getOne(ctx, "id = $1", id.Hex())
+}
+
+func (r *userPostgresRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.getOne(ctx, "email = $1", email)
+}
+
+func (r *userPostgresRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*domain.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := idPlaceholders(ids)
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM users
+		WHERE id IN (`+placeholders+`) AND `+pgNotDeleted(ctx, ""), args...)
+}
+
+func (r *userPostgresRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
+	return r.queryUsers(ctx,
+		`SELECT `+userColumns+` FROM users WHERE `+pgNotDeleted(ctx, "")+` ORDER BY created_at`)
+}
+
+// userSortColumns are the columns of domain.UserSortFields.
+var userSortColumns = map[string]string{
+	"name":      "name",
+	"email":     "email",
+	"role":      "role",
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+}
+
+func (r *userPostgresRepository) GetPage(ctx context.Context, query domain.UserQuery) ([]*domain.User, int, error) {
+	where := pgNotDeleted(ctx, "")
+	var args []interface{}
+	if query.Search != "" {
+		args = append(args, "%"+strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query.Search)+"%")
+		where += fmt.Sprintf(" AND (name ILIKE $%d OR email ILIKE $%d)", len(args), len(args))
+	}
+	if query.Role != "" {
+		args = append(args, query.Role)
+		where += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+
+	var total int
+	if err := pgConn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.New("DATABASE_ERROR", "Failed to count users", 500, err, nil)
+	}
+
+	column, ok := userSortColumns[query.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	order := "ASC"
+	if query.Desc {
+		order = "DESC"
+	}
+	page := fmt.Sprintf(" ORDER BY %s %s, id %s OFFSET %d", column, order, order, query.Skip)
+	if query.Limit > 0 {
+		page += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+
+	users, err := r.queryUsers(ctx, `SELECT `+userColumns+` FROM users WHERE `+where+page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *userPostgresRepository) Each(ctx context.Context, fn func(*domain.User) error) error {
+	return r.eachUser(ctx, fn,
+		`SELECT `+userColumns+` FROM users WHERE `+pgNotDeleted(ctx, "")+` ORDER BY created_at`)
+}
+
+func (r *userPostgresRepository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.eachUser(ctx, func(user *domain.User) error {
+		users = append(users, user)
+		return nil
+	}, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *userPostgresRepository) eachUser(ctx context.Context, fn func(*domain.User) error, query string, args ...interface{}) error {
+	rows, err := pgConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return errors.New("DATABASE_ERROR", "Failed to decode users", 500, err, nil)
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to get users", 500, err, nil)
+	}
+
+	return nil
+}
+
+func (r *userPostgresRepository) Update(ctx context.Context, id primitive.ObjectID, user *domain.User) error {
+	user.UpdatedAt = time.Now()
+
+	preferences, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to encode user preferences", 500, err, nil)
+	}
+	// As on Mongo, a user without consents keeps the stored ones
+	var consents []byte
+	if user.Consents != nil {
+		if consents, err = encodeConsents(user.Consents); err != nil {
+			return err
+		}
+	}
+	var identities []byte
+	if user.Identities != nil {
+		if identities, err = encodeIdentities(user.Identities); err != nil {
+			return err
+		}
+	}
+
+	result, err := pgConn(ctx, r.db).ExecContext(ctx, `UPDATE users SET
+			name = $2, email = $3, role = $4, company = $5, updated_at = $6,
+			password = COALESCE(NULLIF($7, ''), password), locale = $8, preferences = $9, phone = $10,
+			avatar = $11, avatar_thumb = $12, sessions_revoked_at = $13, consents = COALESCE($14, consents),
+			identities = COALESCE($15, identities), deactivated_at = $16
+		WHERE id = $1 AND `+pgNotDeleted(ctx, ""),
+		id.Hex(), user.Name, user.Email, user.Role, encodeIDs(user.Company), user.UpdatedAt, user.Password, user.Locale, preferences, user.Phone,
+		user.Avatar, user.AvatarThumb, user.SessionsRevokedAt, consents, identities, user.DeactivatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.New("EMAIL_ALREADY_EXISTS", "Email already used by another user", 409, err, nil)
+		}
+		return errors.New("DATABASE_ERROR", "Failed to update user", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *userPostgresRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := pgConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`, id.Hex())
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete user", 500, err, nil)
+	}
+
+	if !rowsAffected(result) {
+		return errors.New("USER_NOT_FOUND", "User not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func encodeConsents(consents []domain.Consent) ([]byte, error) {
+	if consents == nil {
+		consents = []domain.Consent{}
+	}
+	data, err := json.Marshal(consents)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to encode user consents", 500, err, nil)
+	}
+	return data, nil
+}
+
+func encodeIdentities(identities []domain.ExternalIdentity) ([]byte, error) {
+	if identities == nil {
+		identities = []domain.ExternalIdentity{}
+	}
+	data, err := json.Marshal(identities)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to encode user identities", 500, err, nil)
+	}
+	return data, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type warehouseMongoRepository struct {
+	states    *mongo.Collection
+	reports   *mongo.Collection
+	companies *mongo.Collection
+}
+
+func NewWarehouseMongoRepository(db *mongo.Database) domain.WarehouseRepository {
+	return &warehouseMongoRepository{
+		states:    db.Collection(config.CollectionName("warehouse_states")),
+		reports:   db.Collection(config.CollectionName("reports")),
+		companies: db.Collection(config.CollectionName("companies")),
+	}
+}
+
+func (r *warehouseMongoRepository) GetStates(ctx context.Context) ([]*domain.WarehouseState, error) {
+	cursor, err := r.states.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get warehouse export states", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	states := []*domain.WarehouseState{}
+	if err = cursor.All(ctx, &states); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode warehouse export states", 500, err, nil)
+	}
+
+	return states, nil
+}
+
+func (r *warehouseMongoRepository) GetState(ctx context.Context, source string) (*domain.WarehouseState, error) {
+	var state domain.WarehouseState
+	if err := r.states.FindOne(ctx, bson.M{"_id": source}).Decode(&state); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &domain.WarehouseState{Source: source}, nil
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get warehouse export state", 500, err, nil)
+	}
+	return &state, nil
+}
+
+func (r *warehouseMongoRepository) SaveState(ctx context.Context, state *domain.WarehouseState) error {
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.states.ReplaceOne(ctx, bson.M{"_id": state.Source}, state, opts); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to save warehouse export state", 500, err, nil)
+	}
+	return nil
+}
+
+func (r *warehouseMongoRepository) ReportsChangedAfter(ctx context.Context, after domain.WarehouseWatermark, limit int) ([]*domain.Report, error) {
+	reports := []*domain.Report{}
+	if err := r.changedAfter(ctx, r.reports, after, limit, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (r *warehouseMongoRepository) CompaniesChangedAfter(ctx context.Context, after domain.WarehouseWatermark, limit int) ([]*domain.Company, error) {
+	companies := []*domain.Company{}
+	if err := r.changedAfter(ctx, r.companies, after, limit, &companies); err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+// changedAfter reads documents in domain.ChangedAt order. Soft deletes don't touch updatedAt,
+// so the change time is computed per document rather than read from an index.
+func (r *warehouseMongoRepository) changedAfter(ctx context.Context, collection *mongo.Collection, after domain.WarehouseWatermark, limit int, out interface{}) error {
+	pipeline := []bson.M{
+		{"$addFields": bson.M{"_changedAt": bson.M{"$max": bson.A{"$updatedAt", bson.M{"$ifNull": bson.A{"$deletedAt", "$updatedAt"}}}}}},
+		{"$match": bson.M{"$or": []bson.M{
+			{"_changedAt": bson.M{"$gt": after.ChangedAt}},
+			{"_changedAt": after.ChangedAt, "_id": bson.M{"$gt": after.ID}},
+		}}},
+		{"$sort": bson.D{{Key: "_changedAt", Value: 1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+		{"$project": bson.M{"_changedAt": 0}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to read changes for the warehouse", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, out); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to decode changes for the warehouse", 500, err, nil)
+	}
+	return nil
+}
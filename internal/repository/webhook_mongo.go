@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"finsolvz-backend/internal/config"
+	"finsolvz-backend/internal/domain"
+	"finsolvz-backend/internal/utils/errors"
+)
+
+type webhookMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookMongoRepository(db *mongo.Database) domain.WebhookRepository {
+	return &webhookMongoRepository{
+		collection: db.Collection(config.CollectionName("webhooks")),
+	}
+}
+
+func (r *webhookMongoRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, subscription)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to create webhook", 500, err, nil)
+	}
+
+	subscription.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *webhookMongoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&subscription); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("WEBHOOK_NOT_FOUND", "Webhook not found", 404, err, nil)
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to get webhook", 500, err, nil)
+	}
+	return &subscription, nil
+}
+
+func (r *webhookMongoRepository) find(ctx context.Context, filter bson.M) ([]*domain.WebhookSubscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get webhooks", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []*domain.WebhookSubscription
+	if err = cursor.All(ctx, &subscriptions); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode webhooks", 500, err, nil)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *webhookMongoRepository) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// GetActiveForEvent matches subscriptions listing the event type as well as those with no filter.
+func (r *webhookMongoRepository) GetActiveForEvent(ctx context.Context, eventType domain.EventType) ([]*domain.WebhookSubscription, error) {
+	return r.find(ctx, bson.M{
+		"active": true,
+		"$or": bson.A{
+			bson.M{"events": eventType},
+			bson.M{"events": bson.M{"$size": 0}},
+			bson.M{"events": nil},
+		},
+	})
+}
+
+func (r *webhookMongoRepository) Update(ctx context.Context, id primitive.ObjectID, subscription *domain.WebhookSubscription) error {
+	subscription.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"url":       subscription.URL,
+			"secret":    subscription.Secret,
+			"events":    subscription.Events,
+			"active":    subscription.Active,
+			"updatedAt": subscription.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to update webhook", 500, err, nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("WEBHOOK_NOT_FOUND", "Webhook not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+func (r *webhookMongoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to delete webhook", 500, err, nil)
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("WEBHOOK_NOT_FOUND", "Webhook not found", 404, nil, nil)
+	}
+
+	return nil
+}
+
+type webhookDeliveryMongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookDeliveryMongoRepository(db *mongo.Database) domain.WebhookDeliveryRepository {
+	return &webhookDeliveryMongoRepository{
+		collection: db.Collection(config.CollectionName("webhookdeliveries")),
+	}
+}
+
+func (r *webhookDeliveryMongoRepository) Enqueue(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	result, err := r.collection.InsertOne(ctx, delivery)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return errors.New("DATABASE_ERROR", "Failed to enqueue webhook delivery", 500, err, nil)
+	}
+
+	delivery.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Claim claims the oldest due delivery with a single findAndModify, which one instance at most
+// wins.
+func (r *webhookDeliveryMongoRepository) Claim(ctx context.Context, lockedUntil time.Time) (*domain.WebhookDelivery, error) {
+	now := time.Now()
+	filter := bson.M{"$or": []bson.M{
+		{"status": domain.DeliveryStatusPending, "nextAttemptAt": bson.M{"$lte": now}},
+		{"status": domain.DeliveryStatusProcessing, "lockedUntil": bson.M{"$lt": now}},
+	}}
+	update := bson.M{"$set": bson.M{
+		"status":      domain.DeliveryStatusProcessing,
+		"lockedUntil": lockedUntil,
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery domain.WebhookDelivery
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.New("DATABASE_ERROR", "Failed to claim webhook delivery", 500, err, nil)
+	}
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryMongoRepository) CountPending(ctx context.Context) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": bson.M{"$in": []domain.DeliveryStatus{domain.DeliveryStatusPending, domain.DeliveryStatusProcessing}}})
+	if err != nil {
+		return 0, errors.New("DATABASE_ERROR", "Failed to count pending webhook deliveries", 500, err, nil)
+	}
+	return count, nil
+}
+
+func (r *webhookDeliveryMongoRepository) MarkDelivered(ctx context.Context, id primitive.ObjectID, responseStatus int) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":         domain.DeliveryStatusDelivered,
+			"responseStatus": responseStatus,
+			"deliveredAt":    time.Now(),
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"lastError": "", "lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark webhook delivery delivered", 500, err, nil)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. Non-final failures stay pending and are retried at nextAttemptAt.
+func (r *webhookDeliveryMongoRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string, responseStatus int, nextAttemptAt time.Time, final bool) error {
+	status := domain.DeliveryStatusPending
+	if final {
+		status = domain.DeliveryStatusFailed
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":         status,
+			"lastError":      reason,
+			"responseStatus": responseStatus,
+			"nextAttemptAt":  nextAttemptAt,
+		},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"lockedUntil": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return errors.New("DATABASE_ERROR", "Failed to mark webhook delivery failed", 500, err, nil)
+	}
+	return nil
+}
+
+// ListBySubscription returns the most recent deliveries first.
+func (r *webhookDeliveryMongoRepository) ListBySubscription(ctx context.Context, subscriptionID primitive.ObjectID, limit int) ([]*domain.WebhookDelivery, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(int64(limit))
+
+	return r.find(ctx, bson.M{"subscriptionId": subscriptionID}, opts)
+}
+
+func (r *webhookDeliveryMongoRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.WebhookDelivery, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to get webhook deliveries", 500, err, nil)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*domain.WebhookDelivery
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, errors.New("DATABASE_ERROR", "Failed to decode webhook deliveries", 500, err, nil)
+	}
+
+	return deliveries, nil
+}
@@ -1,10 +1,33 @@
 package utils
 
 import (
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Cache holds values shared by the code paths that read them. MemoryCache keeps them per
+// instance; RedisCache shares them between every instance of the server.
+type Cache interface {
+	// Get copies the value stored under key into dest, a pointer to a variable of the
+	// stored type, and reports whether it was found
+	Get(key string, dest interface{}) bool
+	Set(key string, value interface{}, ttl time.Duration)
+	Delete(key string)
+	// DeletePrefix removes all items whose key starts with prefix
+	DeletePrefix(prefix string)
+	Clear()
+	// Increment adds one to the counter under key and returns it. A missing counter starts
+	// at one and expires after ttl; later increments keep that expiry.
+	Increment(key string, ttl time.Duration) int64
+	// Counters returns the unexpired counters whose key starts with prefix, by the rest of
+	// their key
+	Counters(prefix string) map[string]int64
+	Stats() CacheStats
+}
+
 // CacheItem represents a cached item with expiration
 type CacheItem struct {
 	Value      interface{}
@@ -16,16 +39,30 @@ func (item CacheItem) IsExpired() bool {
 	return time.Now().After(item.Expiration)
 }
 
-// Cache is a simple in-memory cache with expiration
-type Cache struct {
-	items map[string]CacheItem
-	mutex sync.RWMutex
+// MemoryCache is a simple in-memory cache with expiration
+type MemoryCache struct {
+	items  map[string]CacheItem
+	mutex  sync.RWMutex
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// CacheStats is a point-in-time snapshot of cache usage
+type CacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+	Items   int     `json:"items"`
 }
 
-// NewCache creates a new cache instance
-func NewCache() *Cache {
-	c := &Cache{
-		items: make(map[string]CacheItem),
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache() *MemoryCache {
+	c := &MemoryCache{
+		items:  make(map[string]CacheItem),
+		closed: make(chan struct{}),
 	}
 
 	// Start cleanup goroutine
@@ -35,7 +72,7 @@ func NewCache() *Cache {
 }
 
 // Set adds an item to the cache with TTL
-func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -45,27 +82,93 @@ func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
 	}
 }
 
-// Get retrieves an item from the cache
-func (c *Cache) Get(key string) (interface{}, bool) {
+// Get retrieves an item from the cache. The stored value itself is copied into dest, so
+// callers must copy what it points to before changing it.
+func (c *MemoryCache) Get(key string, dest interface{}) bool {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
 	item, exists := c.items[key]
-	if !exists {
-		return nil, false
+	if !exists || item.IsExpired() {
+		// Expired items are removed by the cleanup goroutine
+		c.misses.Add(1)
+		return false
+	}
+
+	target := reflect.ValueOf(dest).Elem()
+	value := reflect.ValueOf(item.Value)
+	if !value.IsValid() || !value.Type().AssignableTo(target.Type()) {
+		c.misses.Add(1)
+		return false
+	}
+	target.Set(value)
+
+	c.hits.Add(1)
+	return true
+}
+
+// Increment adds one to the counter under key
+func (c *MemoryCache) Increment(key string, ttl time.Duration) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	item, exists := c.items[key]
+	count, isCounter := item.Value.(int64)
+	if !exists || item.IsExpired() || !isCounter {
+		c.items[key] = CacheItem{Value: int64(1), Expiration: time.Now().Add(ttl)}
+		return 1
+	}
+
+	item.Value = count + 1
+	c.items[key] = item
+	return count + 1
+}
+
+// Counters returns the counters under prefix
+func (c *MemoryCache) Counters(prefix string) map[string]int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	counters := map[string]int64{}
+	for key, item := range c.items {
+		if count, isCounter := item.Value.(int64); isCounter && !item.IsExpired() && strings.HasPrefix(key, prefix) {
+			counters[strings.TrimPrefix(key, prefix)] = count
+		}
 	}
+	return counters
+}
+
+// DeletePrefix removes all items whose key starts with prefix
+func (c *MemoryCache) DeletePrefix(prefix string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	if item.IsExpired() {
-		// Remove expired item
-		delete(c.items, key)
-		return nil, false
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+		}
 	}
+}
 
-	return item.Value, true
+// Stats returns hit/miss counters and the current number of items
+func (c *MemoryCache) Stats() CacheStats {
+	c.mutex.RLock()
+	items := len(c.items)
+	c.mutex.RUnlock()
+
+	stats := CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Items:  items,
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
 }
 
 // Delete removes an item from the cache
-func (c *Cache) Delete(key string) {
+func (c *MemoryCache) Delete(key string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -73,20 +176,28 @@ func (c *Cache) Delete(key string) {
 }
 
 // Clear removes all items from the cache
-func (c *Cache) Clear() {
+func (c *MemoryCache) Clear() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	c.items = make(map[string]CacheItem)
 }
 
-// cleanup removes expired items every minute
-func (c *Cache) cleanup() {
+// Close stops the cleanup goroutine. The cache keeps working, but expired items are only
+// dropped as they are overwritten.
+func (c *MemoryCache) Close() {
+	c.closeOnce.Do(func() { close(c.closed) })
+}
+
+// cleanup removes expired items every minute until the cache is closed
+func (c *MemoryCache) cleanup() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-c.closed:
+			return
 		case <-ticker.C:
 			c.mutex.Lock()
 			for key, item := range c.items {
@@ -99,14 +210,19 @@ func (c *Cache) cleanup() {
 	}
 }
 
-// Global cache instance
-var globalCache *Cache
+// Global cache instance, shared through Redis once SetCache is called with a RedisCache
+var globalCache Cache = NewMemoryCache()
 
-func init() {
-	globalCache = NewCache()
+// SetCache replaces the global cache; it must be called at startup, before any request. A
+// replaced MemoryCache is closed.
+func SetCache(cache Cache) {
+	if memory, ok := globalCache.(*MemoryCache); ok && memory != cache {
+		memory.Close()
+	}
+	globalCache = cache
 }
 
 // GetCache returns the global cache instance
-func GetCache() *Cache {
+func GetCache() Cache {
 	return globalCache
 }
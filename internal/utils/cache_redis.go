@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+func init() {
+	// Concrete types that can appear in interface{} fields such as report data, whether
+	// decoded from MongoDB or from JSON
+	for _, value := range []interface{}{
+		map[string]interface{}{}, []interface{}{},
+		primitive.D{}, primitive.A{}, primitive.M{},
+		primitive.ObjectID{}, primitive.DateTime(0), time.Time{},
+	} {
+		gob.Register(value)
+	}
+}
+
+// RedisCache is a Cache shared by every instance through Redis. Values are gob-encoded,
+// which keeps fields hidden from JSON, so Get needs a pointer to the type Set was given.
+// Redis failures are logged and behave as misses: the cache never fails a request.
+type RedisCache struct {
+	client    *RedisClient
+	namespace string
+	hits      atomic.Int64
+	misses    atomic.Int64
+}
+
+// NewRedisCache creates a cache whose keys are prefixed with namespace, so caches sharing a
+// server can be cleared independently.
+func NewRedisCache(client *RedisClient, namespace string) *RedisCache {
+	return &RedisCache{client: client, namespace: namespace}
+}
+
+func (c *RedisCache) Get(key string, dest interface{}) bool {
+	reply, err := c.client.Do(context.Background(), "GET", c.namespace+key)
+	value, isString := reply.(string)
+	if err != nil || !isString {
+		c.fail("GET", key, err)
+		c.misses.Add(1)
+		return false
+	}
+
+	if err := gob.NewDecoder(strings.NewReader(value)).Decode(dest); err != nil {
+		// Written by a version of the server with a different type
+		c.fail("decode", key, err)
+		c.misses.Add(1)
+		return false
+	}
+
+	c.hits.Add(1)
+	return true
+}
+
+func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		c.fail("encode", key, err)
+		return
+	}
+	_, err := c.client.Do(context.Background(), "SET", c.namespace+key, buf.String(), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	c.fail("SET", key, err)
+}
+
+func (c *RedisCache) Delete(key string) {
+	_, err := c.client.Do(context.Background(), "DEL", c.namespace+key)
+	c.fail("DEL", key, err)
+}
+
+func (c *RedisCache) DeletePrefix(prefix string) {
+	err := c.scan(prefix, func(keys []string) error {
+		_, err := c.client.Do(context.Background(), append([]string{"DEL"}, keys...)...)
+		return err
+	})
+	c.fail("DEL", prefix+"*", err)
+}
+
+func (c *RedisCache) Clear() {
+	c.DeletePrefix("")
+}
+
+func (c *RedisCache) Increment(key string, ttl time.Duration) int64 {
+	ctx := context.Background()
+	// Creating the counter with its expiry first keeps a crash between the two commands
+	// from leaving a counter that never expires
+	_, err := c.client.Do(ctx, "SET", c.namespace+key, "0", "PX", strconv.FormatInt(ttl.Milliseconds(), 10), "NX")
+	if err == nil {
+		var reply interface{}
+		reply, err = c.client.Do(ctx, "INCR", c.namespace+key)
+		if count, ok := reply.(int64); ok {
+			return count
+		}
+	}
+	c.fail("INCR", key, err)
+	return 0
+}
+
+// Counters finds the counters with SCAN and reads them with MGET; keys that expire between
+// the two are left out
+func (c *RedisCache) Counters(prefix string) map[string]int64 {
+	counters := map[string]int64{}
+	err := c.scan(prefix, func(keys []string) error {
+		reply, err := c.client.Do(context.Background(), append([]string{"MGET"}, keys...)...)
+		if err != nil {
+			return err
+		}
+		values, _ := reply.([]interface{})
+		for i, value := range values {
+			text, ok := value.(string)
+			if !ok || i >= len(keys) {
+				continue
+			}
+			if count, err := strconv.ParseInt(text, 10, 64); err == nil {
+				counters[strings.TrimPrefix(keys[i], c.namespace+prefix)] = count
+			}
+		}
+		return nil
+	})
+	c.fail("MGET", prefix+"*", err)
+	return counters
+}
+
+// Stats counts the keys of the namespace with SCAN, which reads every key of the namespace
+func (c *RedisCache) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	err := c.scan("", func(keys []string) error {
+		stats.Items += len(keys)
+		return nil
+	})
+	c.fail("SCAN", "*", err)
+	return stats
+}
+
+// scan calls fn with batches of the namespaced keys starting with prefix
+func (c *RedisCache) scan(prefix string, fn func(keys []string) error) error {
+	cursor := "0"
+	for {
+		reply, err := c.client.Do(context.Background(), "SCAN", cursor, "MATCH", globEscape(c.namespace+prefix)+"*", "COUNT", "500")
+		if err != nil {
+			return err
+		}
+
+		parts, _ := reply.([]interface{})
+		if len(parts) != 2 {
+			return RedisError("unexpected SCAN reply")
+		}
+		cursor, _ = parts[0].(string)
+		items, _ := parts[1].([]interface{})
+
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			if key, ok := item.(string); ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// fail logs a failed cache operation; err is nil for misses
+func (c *RedisCache) fail(op, key string, err error) {
+	if err != nil {
+		log.Warnf(context.Background(), "Redis cache: %s %s%s failed: %v", op, c.namespace, key, err)
+	}
+}
+
+// globEscape escapes the characters SCAN MATCH patterns treat specially
+func globEscape(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
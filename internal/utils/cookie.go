@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// Cookie auth lets the web dashboard keep its session in an HTTP-only cookie instead of
+// localStorage. Requests authenticated by the cookie must echo the session's CSRF token in
+// the X-CSRF-Token header; the token is also set in a cookie the dashboard can read.
+const (
+	SessionCookie = "finsolvz_session"
+	CSRFCookie    = "finsolvz_csrf"
+	CSRFHeader    = "X-CSRF-Token"
+)
+
+var (
+	ErrCookieAuthDisabled = errors.New("COOKIE_AUTH_DISABLED", "Cookie sessions are not enabled on this server", 400, nil, nil)
+	ErrCSRFTokenInvalid   = errors.New("CSRF_TOKEN_INVALID", "Missing or invalid CSRF token", 403, nil, nil)
+)
+
+// CookieConfig configures the session and CSRF cookies.
+type CookieConfig struct {
+	Enabled  bool
+	Domain   string // optional, e.g. ".finsolvz.com" to share the cookies with the dashboard's subdomain
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// cookieConfig is set once at startup from the loaded configuration.
+var cookieConfig CookieConfig
+
+// SetCookieConfig configures the cookies written by SetSessionCookies.
+func SetCookieConfig(cfg CookieConfig) {
+	cookieConfig = cfg
+}
+
+// CookieAuthEnabled reports whether clients may keep their session in a cookie.
+func CookieAuthEnabled() bool {
+	return cookieConfig.Enabled
+}
+
+// SetSessionCookies stores a session token in an HTTP-only cookie and its CSRF token in a
+// readable one, both expiring with the token, and returns the CSRF token.
+func SetSessionCookies(w http.ResponseWriter, token string, expires time.Time) string {
+	csrf := CSRFToken(token)
+	http.SetCookie(w, sessionCookie(SessionCookie, token, expires, true))
+	http.SetCookie(w, sessionCookie(CSRFCookie, csrf, expires, false))
+	return csrf
+}
+
+// ClearSessionCookies removes the session and CSRF cookies.
+func ClearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{SessionCookie, CSRFCookie} {
+		cookie := sessionCookie(name, "", time.Unix(0, 0), name == SessionCookie)
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+	}
+}
+
+func sessionCookie(name, value string, expires time.Time, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cookieConfig.Domain,
+		Expires:  expires,
+		HttpOnly: httpOnly,
+		Secure:   cookieConfig.Secure,
+		SameSite: cookieConfig.SameSite,
+	}
+}
+
+// CSRFToken derives the CSRF token of a session token. It needs no storage and stops
+// working with the session, or when the JWT secret rotates.
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(currentJWTSecret()))
+	mac.Write([]byte("csrf\n" + sessionToken))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCSRFToken reports whether token is the CSRF token of sessionToken.
+func ValidCSRFToken(sessionToken, token string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(CSRFToken(sessionToken)))
+}
+
+// SessionCookieToken returns the session token of the request's cookie, or "" when cookie
+// auth is disabled or the request has none.
+func SessionCookieToken(r *http.Request) string {
+	if !cookieConfig.Enabled {
+		return ""
+	}
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// ExtractToken returns the bearer token of the request, falling back to the session cookie
+// when the request has no Authorization header.
+func ExtractToken(r *http.Request) (string, error) {
+	if r.Header.Get("Authorization") == "" {
+		if token := SessionCookieToken(r); token != "" {
+			return token, nil
+		}
+	}
+	return ExtractBearerToken(r)
+}
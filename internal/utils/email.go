@@ -1,99 +1,203 @@
 package utils
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"html/template"
-	"net/smtp"
-	"os"
-
-	"finsolvz-backend/internal/utils/errors"
+	"sync"
+	"time"
 )
 
 type EmailService interface {
-	SendForgotPasswordEmail(to, name, newPassword string) error
+	// SendForgotPasswordEmail sends the new password in the recipient's locale (DefaultLocale when empty).
+	SendForgotPasswordEmail(to, name, locale, newPassword string) error
+	// SendReportAccessEmail tells the recipient they can now view the given reports.
+	SendReportAccessEmail(to, name, locale string, reports []ReportLink) error
+	// SendWeeklyDigestEmail summarises report activity in the recipient's companies.
+	SendWeeklyDigestEmail(to, name, locale string, digest Digest) error
+	// SendDeadlineReminderEmail reminds the recipient of reports their companies still owe.
+	SendDeadlineReminderEmail(to, name, locale string, reports []DueReport) error
+	// SendAlertEmail delivers a critical security or account alert, with a button for action
+	// when it is set.
+	SendAlertEmail(to, name, locale, subject, message string, action *EmailAction) error
+	// SendMagicLinkEmail sends a one-time link that logs the recipient in, valid for minutes.
+	SendMagicLinkEmail(to, name, locale, link string, minutes int) error
+	// SendInvitationEmail invites the recipient to set a password through link, valid for days.
+	SendInvitationEmail(to, name, locale, inviter, link string, days int) error
+	// Reconfigure switches to a provider built from cfg, e.g. after credentials are rotated.
+	// Templates are kept.
+	Reconfigure(cfg EmailConfig)
+	// Verify checks the provider configuration without sending, and its credentials where the
+	// transport allows it (SMTP AUTH). It returns a short description of what was checked.
+	Verify(ctx context.Context) (string, error)
+}
+
+// ReportLink is a report referenced from an email, with a deep link into the app.
+type ReportLink struct {
+	Name string
+	URL  string
+	// Total is the figure the report ends in, written in its company's number format; empty
+	// when the email does not show it or the report has none
+	Total string
+}
+
+// EmailAction is a button in an email, e.g. the "This wasn't me" link of a login alert.
+type EmailAction struct {
+	Label string
+	URL   string
+}
+
+// Digest is the report activity covered by a digest email.
+type Digest struct {
+	Since   time.Time
+	Created []ReportLink
+	Updated []ReportLink
+}
+
+// DueReport is a report a company owes, referenced from a reminder email.
+type DueReport struct {
+	Company    string
+	ReportType string
+	Period     string
+	DueAt      time.Time
+	Overdue    bool
+}
+
+// Template data, one type per email template
+
+type ForgotPasswordEmail struct {
+	Name        string
+	NewPassword string
+}
+
+type ReportAccessEmail struct {
+	Name    string
+	Reports []ReportLink
+}
+
+type WeeklyDigestEmail struct {
+	Name string
+	Digest
+}
+
+type DeadlineReminderEmail struct {
+	Name    string
+	Reports []DueReport
+}
+
+type AlertEmail struct {
+	Name    string
+	Subject string
+	Message string
+	Action  *EmailAction
+}
+
+type MagicLinkEmail struct {
+	Name    string
+	Link    string
+	Minutes int
+}
+
+type InvitationEmail struct {
+	Name    string
+	Inviter string
+	Link    string
+	Days    int
 }
 
 type emailService struct {
-	smtpHost string
-	smtpPort string
-	email    string
-	password string
-}
-
-func NewEmailService() EmailService {
-	return &emailService{
-		smtpHost: "smtp.gmail.com",
-		smtpPort: "587",
-		email:    os.Getenv("NODEMAILER_EMAIL"),
-		password: os.Getenv("NODEMAILER_PASS"),
-	}
+	templates *EmailTemplates
+
+	mu       sync.RWMutex
+	provider EmailProvider
+	from     string
+	err      error // provider configuration error, reported on send
 }
 
-func (e *emailService) SendForgotPasswordEmail(to, name, newPassword string) error {
-	if e.email == "" || e.password == "" {
-		return errors.New("EMAIL_CONFIG_MISSING", "Email configuration not found", 500, nil, nil)
+// NewEmailService builds the service from cfg. A misconfigured provider does not panic;
+// sends fail with the configuration error instead.
+func NewEmailService(cfg EmailConfig) EmailService {
+	e := &emailService{templates: NewEmailTemplates(cfg.TemplateDir)}
+	e.Reconfigure(cfg)
+	return e
+}
+
+// NewEmailServiceWithProvider sends through the given provider using the embedded templates.
+func NewEmailServiceWithProvider(provider EmailProvider, from string) EmailService {
+	return &emailService{provider: provider, templates: NewEmailTemplates(""), from: from}
+}
+
+func (e *emailService) Reconfigure(cfg EmailConfig) {
+	provider, err := NewEmailProvider(cfg)
+
+	from := cfg.From
+	if from == "" {
+		from = fmt.Sprintf("Finsolvz <%s>", cfg.SMTPUsername)
 	}
 
-	// Email template
-	emailTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Password Reset - Finsolvz</title>
-</head>
-<body style="font-family: sans-serif; line-height: 1.6; margin: 0; padding: 20px;">
-    <div style="max-width: 600px; margin: 0 auto;">
-        <h2>Password Reset - Finsolvz</h2>
-        <p>Dear <strong>{{.Name}}</strong>,</p>
-        <p>We have received a request to reset your password for your <strong>Finsolvz</strong> account.</p>
-        <p>Here is your new password:</p>
-        <div style="background-color: #f5f5f5; padding: 15px; border-radius: 5px; margin: 20px 0;">
-            <p style="font-size: 18px; font-weight: bold; margin: 0; font-family: monospace;">{{.NewPassword}}</p>
-        </div>
-        <p>Please use this password to log in to your account. For security reasons, we strongly recommend changing your password after logging in.</p>
-        <p>If you did not request this change, please contact our support team immediately.</p>
-        <p style="margin-top: 30px;">Best regards,<br/>Finsolvz Team</p>
-    </div>
-</body>
-</html>`
-
-	// Parse template
-	tmpl, err := template.New("forgotPassword").Parse(emailTemplate)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.provider, e.from, e.err = provider, from, err
+}
+
+func (e *emailService) SendForgotPasswordEmail(to, name, locale, newPassword string) error {
+	return e.send(to, EmailTemplateForgotPassword, locale, ForgotPasswordEmail{Name: name, NewPassword: newPassword})
+}
+
+func (e *emailService) SendReportAccessEmail(to, name, locale string, reports []ReportLink) error {
+	return e.send(to, EmailTemplateReportAccess, locale, ReportAccessEmail{Name: name, Reports: reports})
+}
+
+func (e *emailService) SendWeeklyDigestEmail(to, name, locale string, digest Digest) error {
+	return e.send(to, EmailTemplateWeeklyDigest, locale, WeeklyDigestEmail{Name: name, Digest: digest})
+}
+
+func (e *emailService) SendDeadlineReminderEmail(to, name, locale string, reports []DueReport) error {
+	return e.send(to, EmailTemplateDeadlineReminder, locale, DeadlineReminderEmail{Name: name, Reports: reports})
+}
+
+func (e *emailService) SendAlertEmail(to, name, locale, subject, message string, action *EmailAction) error {
+	return e.send(to, EmailTemplateAlert, locale, AlertEmail{Name: name, Subject: subject, Message: message, Action: action})
+}
+
+func (e *emailService) SendMagicLinkEmail(to, name, locale, link string, minutes int) error {
+	return e.send(to, EmailTemplateMagicLink, locale, MagicLinkEmail{Name: name, Link: link, Minutes: minutes})
+}
+
+func (e *emailService) SendInvitationEmail(to, name, locale, inviter, link string, days int) error {
+	return e.send(to, EmailTemplateInvitation, locale, InvitationEmail{Name: name, Inviter: inviter, Link: link, Days: days})
+}
+
+func (e *emailService) Verify(ctx context.Context) (string, error) {
+	e.mu.RLock()
+	provider, err := e.provider, e.err
+	e.mu.RUnlock()
 	if err != nil {
-		return errors.New("EMAIL_TEMPLATE_ERROR", "Failed to parse email template", 500, err, nil)
+		return "", err
 	}
 
-	// Execute template
-	var body bytes.Buffer
-	err = tmpl.Execute(&body, struct {
-		Name        string
-		NewPassword string
-	}{
-		Name:        name,
-		NewPassword: newPassword,
-	})
+	if v, ok := provider.(emailVerifier); ok {
+		return v.verify(ctx)
+	}
+	return provider.Name() + " configured (credentials are checked on first send)", nil
+}
+
+func (e *emailService) send(to, template, locale string, data interface{}) error {
+	e.mu.RLock()
+	provider, from, err := e.provider, e.from, e.err
+	e.mu.RUnlock()
 	if err != nil {
-		return errors.New("EMAIL_TEMPLATE_ERROR", "Failed to execute email template", 500, err, nil)
+		return err
 	}
 
-	// Compose email
-	subject := "Your New Finsolvz Account Password"
-	message := fmt.Sprintf("From: Finsolvz <%s>\r\n", e.email)
-	message += fmt.Sprintf("To: %s\r\n", to)
-	message += fmt.Sprintf("Subject: %s\r\n", subject)
-	message += "MIME-Version: 1.0\r\n"
-	message += "Content-Type: text/html; charset=UTF-8\r\n"
-	message += "\r\n"
-	message += body.String()
-
-	// Send email
-	auth := smtp.PlainAuth("", e.email, e.password, e.smtpHost)
-	err = smtp.SendMail(e.smtpHost+":"+e.smtpPort, auth, e.email, []string{to}, []byte(message))
+	subject, body, err := e.templates.Render(template, locale, data)
 	if err != nil {
-		return errors.New("EMAIL_SEND_ERROR", "Failed to send email", 500, err, nil)
+		return err
 	}
 
-	return nil
+	return provider.Send(context.Background(), EmailMessage{
+		From:    from,
+		To:      []string{to},
+		Subject: subject,
+		HTML:    body,
+	})
 }
@@ -0,0 +1,318 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// EmailMessage is a rendered email ready for delivery.
+type EmailMessage struct {
+	From    string
+	To      []string
+	Subject string
+	HTML    string
+}
+
+// EmailProvider delivers rendered messages through a specific transport.
+type EmailProvider interface {
+	Name() string
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+// EmailConfig selects and configures the email provider. Only the settings of the selected
+// provider are used.
+type EmailConfig struct {
+	Provider    string // smtp (default), sendgrid, mailgun, ses or log
+	DryRun      bool   // only log messages
+	From        string
+	TemplateDir string // optional override of the embedded templates
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	SendGridAPIKey string
+
+	MailgunDomain  string
+	MailgunAPIKey  string
+	MailgunBaseURL string
+
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+}
+
+// NewEmailProvider builds the configured provider, or a logging provider in dry-run mode.
+func NewEmailProvider(cfg EmailConfig) (EmailProvider, error) {
+	if cfg.DryRun {
+		return NewLogEmailProvider(), nil
+	}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "", "smtp":
+		host := cfg.SMTPHost
+		if host == "" {
+			host = "smtp.gmail.com"
+		}
+		port := cfg.SMTPPort
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTPEmailProvider(host, port, cfg.SMTPUsername, cfg.SMTPPassword)
+	case "sendgrid":
+		return NewSendGridEmailProvider(cfg.SendGridAPIKey)
+	case "mailgun":
+		return NewMailgunEmailProvider(cfg.MailgunDomain, cfg.MailgunAPIKey, cfg.MailgunBaseURL)
+	case "ses":
+		return NewSESEmailProvider(cfg.AWSRegion, cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey)
+	case "log":
+		return NewLogEmailProvider(), nil
+	}
+
+	return nil, errors.New("EMAIL_CONFIG_INVALID", "Unknown EMAIL_PROVIDER", 500, nil, map[string]interface{}{"provider": cfg.Provider})
+}
+
+var emailHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+func configMissing(provider string) error {
+	return errors.New("EMAIL_CONFIG_MISSING", "Email configuration not found", 500, nil, map[string]interface{}{"provider": provider})
+}
+
+// postEmailAPI sends an HTTP request to a provider API and treats any non-2xx status as a send error.
+func postEmailAPI(req *http.Request) error {
+	resp, err := emailHTTPClient.Do(req)
+	if err != nil {
+		return errors.New("EMAIL_SEND_ERROR", "Failed to send email", 500, err, nil)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.New("EMAIL_SEND_ERROR", "Failed to send email", 500,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), nil)
+	}
+	return nil
+}
+
+// emailVerifier is implemented by providers that can check their credentials without sending.
+type emailVerifier interface {
+	verify(ctx context.Context) (string, error)
+}
+
+// SMTP
+
+type smtpEmailProvider struct {
+	host     string
+	port     string
+	username string
+	password string
+}
+
+func NewSMTPEmailProvider(host, port, username, password string) (EmailProvider, error) {
+	if username == "" || password == "" {
+		return nil, configMissing("smtp")
+	}
+	return &smtpEmailProvider{host: host, port: port, username: username, password: password}, nil
+}
+
+func (p *smtpEmailProvider) Name() string { return "smtp" }
+
+func (p *smtpEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	message := fmt.Sprintf("From: %s\r\n", msg.From)
+	message += fmt.Sprintf("To: %s\r\n", strings.Join(msg.To, ", "))
+	message += fmt.Sprintf("Subject: %s\r\n", msg.Subject)
+	message += "MIME-Version: 1.0\r\n"
+	message += "Content-Type: text/html; charset=UTF-8\r\n"
+	message += "\r\n"
+	message += msg.HTML
+
+	auth := smtp.PlainAuth("", p.username, p.password, p.host)
+	if err := smtp.SendMail(p.host+":"+p.port, auth, p.username, msg.To, []byte(message)); err != nil {
+		return errors.New("EMAIL_SEND_ERROR", "Failed to send email", 500, err, nil)
+	}
+	return nil
+}
+
+// verify connects and authenticates, upgrading to TLS first like smtp.SendMail does.
+func (p *smtpEmailProvider) verify(ctx context.Context) (string, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.host+":"+p.port)
+	if err != nil {
+		return "", errors.New("EMAIL_VERIFY_ERROR", "Failed to connect to SMTP server", 500, err, nil)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return "", errors.New("EMAIL_VERIFY_ERROR", "Failed to start SMTP session", 500, err, nil)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: p.host}); err != nil {
+			return "", errors.New("EMAIL_VERIFY_ERROR", "Failed to start TLS with SMTP server", 500, err, nil)
+		}
+	}
+	if err := client.Auth(smtp.PlainAuth("", p.username, p.password, p.host)); err != nil {
+		return "", errors.New("EMAIL_VERIFY_ERROR", "SMTP authentication failed", 500, err, nil)
+	}
+	client.Quit()
+
+	return fmt.Sprintf("smtp authenticated as %s at %s:%s", p.username, p.host, p.port), nil
+}
+
+// SendGrid
+
+type sendGridEmailProvider struct {
+	apiKey string
+}
+
+func NewSendGridEmailProvider(apiKey string) (EmailProvider, error) {
+	if apiKey == "" {
+		return nil, configMissing("sendgrid")
+	}
+	return &sendGridEmailProvider{apiKey: apiKey}, nil
+}
+
+func (p *sendGridEmailProvider) Name() string { return "sendgrid" }
+
+func (p *sendGridEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	to := make([]map[string]string, len(msg.To))
+	for i, addr := range msg.To {
+		to[i] = map[string]string{"email": addr}
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": to}},
+		"from":             map[string]string{"email": msg.From},
+		"subject":          msg.Subject,
+		"content":          []map[string]string{{"type": "text/html", "value": msg.HTML}},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return errors.New("EMAIL_SEND_ERROR", "Failed to build email request", 500, err, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return postEmailAPI(req)
+}
+
+// Mailgun
+
+type mailgunEmailProvider struct {
+	domain  string
+	apiKey  string
+	baseURL string
+}
+
+// NewMailgunEmailProvider uses the US API unless baseURL is set, e.g. https://api.eu.mailgun.net.
+func NewMailgunEmailProvider(domain, apiKey, baseURL string) (EmailProvider, error) {
+	if domain == "" || apiKey == "" {
+		return nil, configMissing("mailgun")
+	}
+	if baseURL == "" {
+		baseURL = "https://api.mailgun.net"
+	}
+	return &mailgunEmailProvider{domain: domain, apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (p *mailgunEmailProvider) Name() string { return "mailgun" }
+
+func (p *mailgunEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	form := url.Values{}
+	form.Set("from", msg.From)
+	for _, addr := range msg.To {
+		form.Add("to", addr)
+	}
+	form.Set("subject", msg.Subject)
+	form.Set("html", msg.HTML)
+
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", p.baseURL, p.domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.New("EMAIL_SEND_ERROR", "Failed to build email request", 500, err, nil)
+	}
+	req.SetBasicAuth("api", p.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return postEmailAPI(req)
+}
+
+// Amazon SES (v2 HTTP API, signed with AWS Signature Version 4)
+
+type sesEmailProvider struct {
+	region    string
+	accessKey string
+	secretKey string
+}
+
+func NewSESEmailProvider(region, accessKey, secretKey string) (EmailProvider, error) {
+	if region == "" || accessKey == "" || secretKey == "" {
+		return nil, configMissing("ses")
+	}
+	return &sesEmailProvider{region: region, accessKey: accessKey, secretKey: secretKey}, nil
+}
+
+func (p *sesEmailProvider) Name() string { return "ses" }
+
+func (p *sesEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": msg.From,
+		"Destination":      map[string]interface{}{"ToAddresses": msg.To},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": map[string]string{"Data": msg.Subject, "Charset": "UTF-8"},
+				"Body":    map[string]interface{}{"Html": map[string]string{"Data": msg.HTML, "Charset": "UTF-8"}},
+			},
+		},
+	})
+
+	host := fmt.Sprintf("email.%s.amazonaws.com", p.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+host+"/v2/email/outbound-emails", bytes.NewReader(payload))
+	if err != nil {
+		return errors.New("EMAIL_SEND_ERROR", "Failed to build email request", 500, err, nil)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SignAWSRequest(req, payload, p.region, "ses", p.accessKey, p.secretKey, time.Now())
+
+	return postEmailAPI(req)
+}
+
+// Dry run
+
+type logEmailProvider struct{}
+
+// NewLogEmailProvider logs messages instead of sending them. Bodies are not logged since they may carry credentials.
+func NewLogEmailProvider() EmailProvider {
+	return logEmailProvider{}
+}
+
+func (logEmailProvider) Name() string { return "log" }
+
+func (logEmailProvider) verify(ctx context.Context) (string, error) {
+	return "dry run, emails are only logged", nil
+}
+
+func (logEmailProvider) Send(ctx context.Context, msg EmailMessage) error {
+	log.Infof(ctx, "Email (dry run): to=%s subject=%q bytes=%d", strings.Join(msg.To, ","), msg.Subject, len(msg.HTML))
+	return nil
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"html"
+	"html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+//go:embed templates/email
+var embeddedEmailTemplates embed.FS
+
+// DefaultLocale is used when a user has no locale or no template exists for theirs.
+const DefaultLocale = "en"
+
+// Email template names
+const (
+	EmailTemplateForgotPassword   = "forgot_password"
+	EmailTemplateReportAccess     = "report_access"
+	EmailTemplateWeeklyDigest     = "weekly_digest"
+	EmailTemplateDeadlineReminder = "deadline_reminder"
+	EmailTemplateAlert            = "alert"
+	EmailTemplateMagicLink        = "magic_link"
+	EmailTemplateInvitation       = "invitation"
+)
+
+// EmailTemplates resolves templates by name and locale. Each file defines a "subject" and a
+// "body" template and lives at <locale>/<name>.html.
+type EmailTemplates struct {
+	files  fs.FS
+	reload bool // re-parse on every render so edits on disk apply immediately
+
+	mu    sync.RWMutex
+	cache map[string]*template.Template
+}
+
+// NewEmailTemplates loads templates from dir when set, so copy can be changed
+// without a rebuild, and from the templates embedded in the binary otherwise.
+func NewEmailTemplates(dir string) *EmailTemplates {
+	if dir != "" {
+		t := NewEmailTemplatesFS(os.DirFS(dir))
+		t.reload = true
+		return t
+	}
+
+	files, _ := fs.Sub(embeddedEmailTemplates, "templates/email")
+	return NewEmailTemplatesFS(files)
+}
+
+func NewEmailTemplatesFS(files fs.FS) *EmailTemplates {
+	return &EmailTemplates{
+		files: files,
+		cache: make(map[string]*template.Template),
+	}
+}
+
+// Render executes the named template for locale, falling back to DefaultLocale.
+func (t *EmailTemplates) Render(name, locale string, data interface{}) (subject, body string, err error) {
+	tmpl, err := t.lookup(name, NormalizeLocale(locale))
+	if err != nil {
+		return "", "", err
+	}
+
+	var subjectBuf, bodyBuf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subjectBuf, "subject", data); err != nil {
+		return "", "", errors.New("EMAIL_TEMPLATE_ERROR", "Failed to execute email template", 500, err, nil)
+	}
+	if err := tmpl.ExecuteTemplate(&bodyBuf, "body", data); err != nil {
+		return "", "", errors.New("EMAIL_TEMPLATE_ERROR", "Failed to execute email template", 500, err, nil)
+	}
+
+	// The subject is a plain-text header, so undo the HTML escaping applied to interpolated values
+	return html.UnescapeString(strings.TrimSpace(subjectBuf.String())), bodyBuf.String(), nil
+}
+
+// Locales lists the locales that have at least one template.
+func (t *EmailTemplates) Locales() []string {
+	entries, err := fs.ReadDir(t.files, ".")
+	if err != nil {
+		return []string{DefaultLocale}
+	}
+
+	var locales []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			locales = append(locales, entry.Name())
+		}
+	}
+	return locales
+}
+
+func (t *EmailTemplates) lookup(name, locale string) (*template.Template, error) {
+	for _, candidate := range []string{locale, DefaultLocale} {
+		key := candidate + "/" + name + ".html"
+
+		t.mu.RLock()
+		tmpl, ok := t.cache[key]
+		t.mu.RUnlock()
+		if ok {
+			return tmpl, nil
+		}
+
+		tmpl, err := template.ParseFS(t.files, key)
+		if err != nil {
+			continue
+		}
+
+		if !t.reload {
+			t.mu.Lock()
+			t.cache[key] = tmpl
+			t.mu.Unlock()
+		}
+		return tmpl, nil
+	}
+
+	return nil, errors.New("EMAIL_TEMPLATE_NOT_FOUND", "Email template not found", 500, nil, map[string]interface{}{"template": name, "locale": locale})
+}
+
+// NormalizeLocale reduces tags like "id-ID" or "EN_us" to their lowercase language code.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locale = locale[:i]
+	}
+	if locale == "" {
+		return DefaultLocale
+	}
+	return locale
+}
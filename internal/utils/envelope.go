@@ -0,0 +1,53 @@
+package utils
+
+import "net/http"
+
+// Envelope is the standardized body of RespondJSON for clients that opt in: data holds the
+// payload of successful responses, error the error of failed ones, and meta anything about
+// the response itself, such as pagination.
+type Envelope struct {
+	Data  interface{}            `json:"data"`
+	Meta  map[string]interface{} `json:"meta"`
+	Error *ErrorResponse         `json:"error"`
+}
+
+// envelopeWriter marks a response whose JSON body is wrapped in an Envelope.
+type envelopeWriter struct {
+	http.ResponseWriter
+	apiPrefix string
+}
+
+func (w envelopeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithEnvelope makes RespondJSON wrap the bodies written to w, and to writers wrapping it,
+// in an Envelope, and add the _links of Linker DTOs. apiPrefix is the path prefix the client
+// called, /api/ or /api/v2/, which the links keep.
+func WithEnvelope(w http.ResponseWriter, apiPrefix string) http.ResponseWriter {
+	return envelopeWriter{ResponseWriter: w, apiPrefix: apiPrefix}
+}
+
+// findEnvelope looks for WithEnvelope through the writers middleware wrapped around it
+func findEnvelope(w http.ResponseWriter) (envelopeWriter, bool) {
+	for {
+		if ew, ok := w.(envelopeWriter); ok {
+			return ew, true
+		}
+		wrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return envelopeWriter{}, false
+		}
+		w = wrapper.Unwrap()
+	}
+}
+
+func envelope(data interface{}) Envelope {
+	switch body := data.(type) {
+	case ErrorResponse:
+		return Envelope{Meta: map[string]interface{}{}, Error: &body}
+	case PaginatedResponse:
+		return Envelope{Data: body.Data, Meta: map[string]interface{}{"pagination": body.Pagination}}
+	}
+	return Envelope{Data: data, Meta: map[string]interface{}{}}
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"strings"
 
@@ -22,6 +24,28 @@ func DecodeJSON(r *http.Request, dst interface{}) error {
 	return nil
 }
 
+// MultipartFile streams the named file field of a multipart/form-data request without buffering
+// it, returning the part and its declared Content-Type. It must be read before the handler returns.
+func MultipartFile(r *http.Request, field string) (io.Reader, string, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", errors.New("INVALID_MULTIPART", "Request must be multipart/form-data", 400, err, nil)
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", errors.New("FILE_REQUIRED", "Missing file field", 400, nil, map[string]interface{}{"field": field})
+		}
+		if err != nil {
+			return nil, "", errors.New("INVALID_MULTIPART", "Failed to read multipart body", 400, err, nil)
+		}
+		if part.FormName() == field && part.FileName() != "" {
+			return part, part.Header.Get("Content-Type"), nil
+		}
+	}
+}
+
 // HandleValidationError handles validation errors from go-playground/validator
 func HandleValidationError(w http.ResponseWriter, err error, r *http.Request) {
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
@@ -71,3 +95,15 @@ func ExtractBearerToken(r *http.Request) (string, error) {
 
 	return token, nil
 }
+
+// ClientIP is the address of the client making r: the first X-Forwarded-For entry when a
+// proxy set one, otherwise the remote address.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -1,59 +1,176 @@
 package utils
 
 import (
-	"os"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ErrSessionRevoked rejects a valid token issued before the user revoked their sessions.
+var ErrSessionRevoked = errors.New("SESSION_REVOKED", "Session has been revoked, please log in again", 401, nil, nil)
+
+// ErrTokenRevoked rejects a valid token that was revoked, e.g. by logging out with it.
+var ErrTokenRevoked = errors.New("TOKEN_REVOKED", "Token has been revoked, please log in again", 401, nil, nil)
+
+// ErrAuthUnavailable rejects a valid token that can't be checked for revocation, e.g. while the
+// database is down, rather than let revoked tokens and deactivated users through.
+var ErrAuthUnavailable = errors.New("AUTH_UNAVAILABLE", "Sign-in can't be verified right now, please try again shortly", 503, nil, nil)
+
+// TokenDenylist holds the tokens revoked before they expire, by their ID (the jti claim).
+type TokenDenylist interface {
+	Revoke(ctx context.Context, claims *Claims) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// tokenDenylist is set once at startup; nil accepts every token until it expires.
+var tokenDenylist TokenDenylist
+
+// SetTokenDenylist configures the denylist ValidateJWT checks and RevokeJWT adds to.
+func SetTokenDenylist(denylist TokenDenylist) {
+	tokenDenylist = denylist
+}
+
+// RevokeJWT rejects the token of claims from now on, along with the tokens renewed or refreshed
+// from it, until they expire. Tokens issued without an ID, before revocation existed, cannot
+// be revoked one by one.
+func RevokeJWT(ctx context.Context, claims *Claims) error {
+	if tokenDenylist == nil || claims.ID == "" {
+		return nil
+	}
+	return tokenDenylist.Revoke(ctx, claims)
+}
+
 type Claims struct {
 	UserID string `json:"_id"`
 	Role   string `json:"role"`
+	// Organization is the ID of the user's organization when the token was issued, empty
+	// for users of the instance
+	Organization string `json:"org,omitempty"`
+	// AuthTime is when the user logged in, kept by the tokens renewed or refreshed since
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// TokenUse is "refresh" for refresh tokens, which are only accepted by ValidateRefreshJWT
+	TokenUse string `json:"token_use,omitempty"`
+	// Impersonator is the ID of the super admin acting as the user with the token, empty
+	// unless it was issued with IssueImpersonationJWT
+	Impersonator string `json:"impersonator,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID, role string) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+func GenerateJWT(userID, role, organization string) (string, error) {
+	token, _, err := IssueJWT(userID, role, organization)
+	return token, err
+}
+
+// IssueJWT is GenerateJWT also returning the claims of the token, such as its ID and expiry.
+func IssueJWT(userID, role, organization string) (string, *Claims, error) {
+	claims, err := newClaims(userID, role, organization, tokenLifetimes.Access)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tokenString, err := signJWT(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return tokenString, claims, nil
+}
+
+// IssueImpersonationJWT issues a token acting as the user for impersonator, valid for the
+// impersonation lifetime. It is never renewed, and no refresh token goes with it.
+func IssueImpersonationJWT(userID, role, organization, impersonator string) (string, *Claims, error) {
+	claims, err := newClaims(userID, role, organization, tokenLifetimes.Impersonation)
+	if err != nil {
+		return "", nil, err
+	}
+	claims.Impersonator = impersonator
+
+	tokenString, err := signJWT(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return tokenString, claims, nil
+}
+
+// newClaims returns the claims of a token issued now with a new ID, valid for lifetime.
+func newClaims(userID, role, organization string, lifetime time.Duration) (*Claims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.New("JWT_GENERATION_ERROR", "Failed to generate JWT token", 500, err, nil)
+	}
+
+	now := time.Now()
+	return &Claims{
+		UserID:       userID,
+		Role:         role,
+		Organization: organization,
+		AuthTime:     jwt.NewNumericDate(now),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        hex.EncodeToString(id),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
-	}
+	}, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
+// signJWT signs claims with the current signing key.
+func signJWT(claims *Claims) (string, error) {
+	keys := jwtKeys.Load()
+	if keys == nil || keys.signing == nil {
 		return "", errors.New("JWT_SECRET_MISSING", "JWT secret not configured", 500, nil, nil)
 	}
 
-	tokenString, err := token.SignedString([]byte(secret))
+	token := jwt.NewWithClaims(keys.signing.method, claims)
+	token.Header["kid"] = keys.signing.id
+	tokenString, err := token.SignedString(keys.signing.sign)
 	if err != nil {
 		return "", errors.New("JWT_GENERATION_ERROR", "Failed to generate JWT token", 500, err, nil)
 	}
-
 	return tokenString, nil
 }
 
-func ValidateJWT(tokenString string) (*Claims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
+// ValidateJWT returns the claims of a valid token that was not revoked. Denylist lookup
+// failures reject the token with ErrAuthUnavailable.
+func ValidateJWT(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := parseJWT(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse == tokenUseRefresh {
+		return nil, errors.New("JWT_INVALID", "Refresh tokens cannot authenticate requests", 401, nil, nil)
+	}
+	return claims, nil
+}
+
+func parseJWT(ctx context.Context, tokenString string) (*Claims, error) {
+	keys := jwtKeys.Load()
+	if keys == nil || len(keys.keys) == 0 {
 		return nil, errors.New("JWT_SECRET_MISSING", "JWT secret not configured", 500, nil, nil)
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.verificationKey,
+		jwt.WithValidMethods([]string{JWTAlgorithmHS256, JWTAlgorithmRS256}))
 
 	if err != nil {
 		return nil, errors.New("JWT_INVALID", "Invalid JWT token", 401, err, nil)
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if tokenDenylist != nil && claims.ID != "" {
+			revoked, err := tokenDenylist.IsRevoked(ctx, claims.ID)
+			if err != nil {
+				log.Errorf(ctx, "Token denylist lookup failed: %v", err)
+				return nil, ErrAuthUnavailable
+			}
+			if revoked {
+				return nil, ErrTokenRevoked
+			}
+		}
 		return claims, nil
 	}
 
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms new tokens can be signed with
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+)
+
+// JWTConfig holds the keys tokens are signed and verified with. Keys are named by a key ID,
+// the kid header of the tokens they sign, so rotated keys keep verifying the tokens they
+// signed until those expire.
+type JWTConfig struct {
+	// Secret signs HS256 tokens, and the CSRF tokens of cookie sessions
+	Secret string
+	// PreviousSecrets verify the HS256 tokens signed before Secret was rotated
+	PreviousSecrets []string
+	// Algorithm signs new tokens: HS256 (the default) with Secret, or RS256 with PrivateKey
+	Algorithm string
+	// PrivateKey is the PEM encoded RSA key signing RS256 tokens
+	PrivateKey string
+	// PreviousPublicKeys are PEM encoded RSA public keys verifying the RS256 tokens signed
+	// before PrivateKey was rotated
+	PreviousPublicKeys string
+}
+
+// jwtKey is a key tokens are verified with, and signed with when it is the signing key.
+type jwtKey struct {
+	id     string
+	method jwt.SigningMethod
+	sign   interface{} // []byte or *rsa.PrivateKey; nil for previous keys
+	verify interface{} // []byte or *rsa.PublicKey
+}
+
+type jwtKeySet struct {
+	signing *jwtKey
+	keys    []*jwtKey
+	secret  string
+}
+
+// jwtKeys are the keys of the current configuration. Set at startup, and again when secrets
+// are refreshed.
+var jwtKeys atomic.Pointer[jwtKeySet]
+
+// SetJWTSecret configures HS256 tokens signed and verified with secret alone.
+func SetJWTSecret(secret string) {
+	keys, _ := parseJWTConfig(JWTConfig{Secret: secret})
+	jwtKeys.Store(keys)
+}
+
+// SetJWTConfig configures the keys GenerateJWT signs with and ValidateJWT accepts.
+func SetJWTConfig(cfg JWTConfig) error {
+	keys, err := parseJWTConfig(cfg)
+	if err != nil {
+		return err
+	}
+	jwtKeys.Store(keys)
+	return nil
+}
+
+// CheckJWTConfig returns why cfg is unusable, or nil.
+func CheckJWTConfig(cfg JWTConfig) error {
+	_, err := parseJWTConfig(cfg)
+	return err
+}
+
+func currentJWTSecret() string {
+	if keys := jwtKeys.Load(); keys != nil {
+		return keys.secret
+	}
+	return ""
+}
+
+func parseJWTConfig(cfg JWTConfig) (*jwtKeySet, error) {
+	set := &jwtKeySet{secret: cfg.Secret}
+	for _, secret := range append([]string{cfg.Secret}, cfg.PreviousSecrets...) {
+		if secret == "" {
+			continue
+		}
+		set.keys = append(set.keys, &jwtKey{id: secretKeyID(secret), method: jwt.SigningMethodHS256, verify: []byte(secret)})
+	}
+
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", JWTAlgorithmHS256:
+		if cfg.Secret != "" {
+			set.signing = set.keys[0]
+			set.signing.sign = []byte(cfg.Secret)
+		}
+	case JWTAlgorithmRS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(unescapePEM(cfg.PrivateKey)))
+		if err != nil {
+			return nil, fmt.Errorf("RS256 needs a PEM encoded RSA private key: %w", err)
+		}
+		set.signing = &jwtKey{id: rsaKeyID(&private.PublicKey), method: jwt.SigningMethodRS256, sign: private, verify: &private.PublicKey}
+		set.keys = append(set.keys, set.signing)
+	default:
+		return nil, fmt.Errorf("algorithm must be %s or %s, got %q", JWTAlgorithmHS256, JWTAlgorithmRS256, cfg.Algorithm)
+	}
+
+	rest := []byte(unescapePEM(cfg.PreviousPublicKeys))
+	for {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, fmt.Errorf("previous public keys must be PEM encoded RSA public keys: %w", err)
+		}
+		set.keys = append(set.keys, &jwtKey{id: rsaKeyID(public), method: jwt.SigningMethodRS256, verify: public})
+	}
+	return set, nil
+}
+
+// unescapePEM restores the line breaks of PEM text written on one line with \n, as
+// environment variables often are.
+func unescapePEM(value string) string {
+	return strings.ReplaceAll(value, `\n`, "\n")
+}
+
+// secretKeyID names a secret without revealing it.
+func secretKeyID(secret string) string {
+	sum := sha256.Sum256([]byte("kid\n" + secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// rsaKeyID is the RFC 7638 thumbprint of an RSA public key.
+func rsaKeyID(key *rsa.PublicKey) string {
+	jwk := rsaJWK(key, "")
+	sum := sha256.Sum256([]byte(fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// verificationKey returns the key verifying token: the one named by its kid header, or for
+// tokens issued before key IDs existed, every secret.
+func (s *jwtKeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		set := jwt.VerificationKeySet{}
+		for _, key := range s.keys {
+			if key.method == jwt.SigningMethodHS256 && token.Method == jwt.SigningMethodHS256 {
+				set.Keys = append(set.Keys, key.verify)
+			}
+		}
+		if len(set.Keys) == 0 {
+			return nil, fmt.Errorf("token has no key ID")
+		}
+		return set, nil
+	}
+
+	for _, key := range s.keys {
+		// A key only verifies tokens of its own algorithm, so public keys are never used as secrets
+		if key.id == kid && key.method == token.Method {
+			return key.verify, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// JWK is a public key of the JSON Web Key Set tokens can be verified with.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func rsaJWK(key *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: JWTAlgorithmRS256,
+		KeyID:     kid,
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// PublicJWKS returns the public keys RS256 tokens are verified with, the signing key first.
+// It is empty with HS256, whose secrets cannot be published.
+func PublicJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	keys := jwtKeys.Load()
+	if keys == nil {
+		return jwks
+	}
+	for _, key := range keys.keys {
+		if public, ok := key.verify.(*rsa.PublicKey); ok {
+			jwk := rsaJWK(public, key.id)
+			if key == keys.signing {
+				jwks.Keys = append([]JWK{jwk}, jwks.Keys...)
+			} else {
+				jwks.Keys = append(jwks.Keys, jwk)
+			}
+		}
+	}
+	return jwks
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// tokenUseRefresh marks refresh tokens in the token_use claim.
+const tokenUseRefresh = "refresh"
+
+// RenewedTokenHeader carries the renewed token of a request whose token was close to expiring,
+// when sliding expiration is on. Cookie sessions get a renewed cookie instead.
+const RenewedTokenHeader = "X-Renewed-Token"
+
+// ErrSessionExpired rejects refreshing a login past its maximum age.
+var ErrSessionExpired = errors.New("SESSION_EXPIRED", "Session has reached its maximum age, please log in again", 401, nil, nil)
+
+// TokenLifetimes configures how long tokens stay valid, and whether activity extends them.
+type TokenLifetimes struct {
+	// Access is how long the tokens requests authenticate with stay valid
+	Access time.Duration
+	// Refresh is how long refresh tokens stay valid; none are issued when zero
+	Refresh time.Duration
+	// Sliding renews the tokens of requests made in the second half of their lifetime
+	Sliding bool
+	// MaxAge bounds how long renewed and refreshed tokens keep a login going, from when the
+	// user logged in
+	MaxAge time.Duration
+	// Impersonation is how long the tokens super admins act as other users with stay valid
+	Impersonation time.Duration
+}
+
+// DefaultTokenLifetimes keep tokens valid for a week, without refresh tokens or renewal, and
+// impersonation tokens for 15 minutes.
+var DefaultTokenLifetimes = TokenLifetimes{Access: 7 * 24 * time.Hour, MaxAge: 30 * 24 * time.Hour, Impersonation: 15 * time.Minute}
+
+// tokenLifetimes is set once at startup from the loaded configuration.
+var tokenLifetimes = DefaultTokenLifetimes
+
+// SetTokenLifetimes configures the lifetimes of the tokens issued from now on.
+func SetTokenLifetimes(lifetimes TokenLifetimes) {
+	tokenLifetimes = lifetimes
+}
+
+// RefreshTokensEnabled reports whether logins also return a refresh token.
+func RefreshTokensEnabled() bool {
+	return tokenLifetimes.Refresh > 0
+}
+
+// expiry returns when a token of the login of claims issued now with lifetime expires, no
+// later than the login's maximum age.
+func expiry(claims *Claims, lifetime time.Duration) time.Time {
+	expiresAt := time.Now().Add(lifetime)
+	if claims.AuthTime != nil {
+		if limit := claims.AuthTime.Add(tokenLifetimes.MaxAge); limit.Before(expiresAt) {
+			return limit
+		}
+	}
+	return expiresAt
+}
+
+// SessionExpiry returns when the last token of the login of claims can expire: the token's
+// own expiry, or the login's maximum age when tokens are renewed or refreshed.
+func SessionExpiry(claims *Claims) time.Time {
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if (tokenLifetimes.Sliding || RefreshTokensEnabled()) && claims.AuthTime != nil {
+		if limit := claims.AuthTime.Add(tokenLifetimes.MaxAge); limit.After(expiresAt) {
+			return limit
+		}
+	}
+	return expiresAt
+}
+
+// IssueRefreshJWT returns a refresh token for the login of the access token of claims.
+func IssueRefreshJWT(claims *Claims) (string, error) {
+	refresh := &Claims{
+		UserID:       claims.UserID,
+		Role:         claims.Role,
+		Organization: claims.Organization,
+		AuthTime:     claims.AuthTime,
+		TokenUse:     tokenUseRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        claims.ID,
+			ExpiresAt: jwt.NewNumericDate(expiry(claims, tokenLifetimes.Refresh)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return signJWT(refresh)
+}
+
+// ValidateRefreshJWT returns the claims of a valid refresh token that was not revoked.
+func ValidateRefreshJWT(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := parseJWT(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != tokenUseRefresh {
+		return nil, errors.New("JWT_INVALID", "Token is not a refresh token", 401, nil, nil)
+	}
+	return claims, nil
+}
+
+// RenewJWT returns a new access token for the login of claims, with the user's current role
+// and organization. It keeps the ID of the login's tokens, so revoking any of them revokes
+// them all, and fails with SESSION_EXPIRED once the login reaches its maximum age.
+func RenewJWT(claims *Claims, role, organization string) (string, *Claims, error) {
+	now := time.Now()
+	renewed := &Claims{
+		UserID:       claims.UserID,
+		Role:         role,
+		Organization: organization,
+		AuthTime:     claims.AuthTime,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        claims.ID,
+			ExpiresAt: jwt.NewNumericDate(expiry(claims, tokenLifetimes.Access)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	if !renewed.ExpiresAt.After(now) {
+		return "", nil, ErrSessionExpired
+	}
+
+	token, err := signJWT(renewed)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, renewed, nil
+}
+
+// SlideJWT renews the access token of claims when sliding expiration is on and the token is in
+// the second half of its lifetime. It returns an empty token when the token is not due, is an
+// impersonation token, or renewing would not extend it.
+func SlideJWT(claims *Claims) (string, *Claims) {
+	if !tokenLifetimes.Sliding || claims.ID == "" || claims.AuthTime == nil || claims.ExpiresAt == nil || claims.Impersonator != "" {
+		return "", nil
+	}
+	if time.Until(claims.ExpiresAt.Time) > tokenLifetimes.Access/2 {
+		return "", nil
+	}
+
+	token, renewed, err := RenewJWT(claims, claims.Role, claims.Organization)
+	if err != nil || !renewed.ExpiresAt.After(claims.ExpiresAt.Time) {
+		return "", nil
+	}
+	return token, renewed
+}
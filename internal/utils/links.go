@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Link points at a related resource, HAL style.
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links are the _links of a resource by relation, with hrefs under /api/.
+type Links map[string]Link
+
+// Linker is implemented by response DTOs that link to related resources. Enveloped
+// responses add the links to the DTO's JSON as _links, so clients can navigate without
+// URL templates; bare responses keep their legacy format.
+type Linker interface {
+	Links() Links
+}
+
+// addLinks adds _links to the Linkers in data: data itself, the items of a list or of a
+// PaginatedResponse, and the values of a map such as {message, company}. Hrefs are moved
+// from /api/ to apiPrefix so they keep the version the client called.
+func addLinks(data interface{}, apiPrefix string) (interface{}, error) {
+	if page, ok := data.(PaginatedResponse); ok {
+		linked, err := addLinks(page.Data, apiPrefix)
+		page.Data = linked
+		return page, err
+	}
+	if linker, ok := data.(Linker); ok {
+		if v := reflect.ValueOf(data); v.Kind() == reflect.Pointer && v.IsNil() {
+			return data, nil
+		}
+		return withLinks(linker, apiPrefix)
+	}
+
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !v.Type().Elem().Implements(reflect.TypeOf((*Linker)(nil)).Elem()) {
+			return data, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			linked, err := addLinks(v.Index(i).Interface(), apiPrefix)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = linked
+		}
+		return items, nil
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return data, nil
+		}
+		values := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			linked, err := addLinks(iter.Value().Interface(), apiPrefix)
+			if err != nil {
+				return nil, err
+			}
+			values[iter.Key().String()] = linked
+		}
+		return values, nil
+	}
+	return data, nil
+}
+
+// withLinks appends the _links member to the JSON object of linker.
+func withLinks(linker Linker, apiPrefix string) (json.RawMessage, error) {
+	raw, err := json.Marshal(linker)
+	if err != nil {
+		return nil, err
+	}
+
+	links := linker.Links()
+	for rel, link := range links {
+		if rest, ok := strings.CutPrefix(link.Href, "/api/"); ok {
+			links[rel] = Link{Href: apiPrefix + rest}
+		}
+	}
+	encoded, err := json.Marshal(links)
+	if err != nil {
+		return nil, err
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < 2 || raw[0] != '{' {
+		return raw, nil
+	}
+	var out bytes.Buffer
+	out.Write(raw[:len(raw)-1])
+	if len(raw) > 2 {
+		out.WriteByte(',')
+	}
+	out.WriteString(`"_links":`)
+	out.Write(encoded)
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
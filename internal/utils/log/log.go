@@ -2,8 +2,11 @@ package log
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
 type LogLevel int
@@ -15,6 +18,54 @@ const (
 	ERROR
 )
 
+var levelNames = map[LogLevel]string{DEBUG: "debug", INFO: "info", WARN: "warn", ERROR: "error"}
+
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// ParseLevel accepts debug, info, warn (or warning) and error, in any case.
+func ParseLevel(s string) (LogLevel, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warning" {
+		name = "warn"
+	}
+	for level, n := range levelNames {
+		if n == name {
+			return level, nil
+		}
+	}
+	return INFO, fmt.Errorf("unknown log level %q", s)
+}
+
+// level is the minimum level written; it can change while requests are being served.
+var level atomic.Int32
+
+func init() {
+	level.Store(int32(INFO))
+}
+
+// SetLevel changes the minimum level written. Errors and fatal messages are always written.
+func SetLevel(l LogLevel) {
+	if l > ERROR {
+		l = ERROR
+	}
+	level.Store(int32(l))
+}
+
+// Level returns the current minimum level.
+func Level() LogLevel {
+	return LogLevel(level.Load())
+}
+
+func enabled(l LogLevel) bool {
+	return l >= Level()
+}
+
+// The loggers write through Redact, so log lines carry no personal data or secrets.
 var (
 	debugLogger = log.New(os.Stdout, "DEBUG: ", log.LstdFlags|log.Lshortfile)
 	infoLogger  = log.New(os.Stdout, "INFO: ", log.LstdFlags|log.Lshortfile)
@@ -23,41 +74,55 @@ var (
 )
 
 func Debugf(ctx context.Context, format string, v ...interface{}) {
-	debugLogger.Printf(format, v...)
+	if enabled(DEBUG) {
+		debugLogger.Output(2, Redact(fmt.Sprintf(format, v...)))
+	}
 }
 
 func Infof(ctx context.Context, format string, v ...interface{}) {
-	infoLogger.Printf(format, v...)
+	if enabled(INFO) {
+		infoLogger.Output(2, Redact(fmt.Sprintf(format, v...)))
+	}
 }
 
 func Warnf(ctx context.Context, format string, v ...interface{}) {
-	warnLogger.Printf(format, v...)
+	if enabled(WARN) {
+		warnLogger.Output(2, Redact(fmt.Sprintf(format, v...)))
+	}
 }
 
 func Errorf(ctx context.Context, format string, v ...interface{}) {
-	errorLogger.Printf(format, v...)
+	errorLogger.Output(2, Redact(fmt.Sprintf(format, v...)))
 }
 
 func Debug(ctx context.Context, msg string) {
-	debugLogger.Println(msg)
+	if enabled(DEBUG) {
+		debugLogger.Output(2, Redact(msg))
+	}
 }
 
 func Info(ctx context.Context, msg string) {
-	infoLogger.Println(msg)
+	if enabled(INFO) {
+		infoLogger.Output(2, Redact(msg))
+	}
 }
 
 func Warn(ctx context.Context, msg string) {
-	warnLogger.Println(msg)
+	if enabled(WARN) {
+		warnLogger.Output(2, Redact(msg))
+	}
 }
 
 func Error(ctx context.Context, msg string) {
-	errorLogger.Println(msg)
+	errorLogger.Output(2, Redact(msg))
 }
 
 func Fatal(ctx context.Context, msg string) {
-	errorLogger.Fatalln(msg)
+	errorLogger.Output(2, Redact(msg))
+	os.Exit(1)
 }
 
 func Fatalf(ctx context.Context, format string, v ...interface{}) {
-	errorLogger.Fatalf(format, v...)
+	errorLogger.Output(2, Redact(fmt.Sprintf(format, v...)))
+	os.Exit(1)
 }
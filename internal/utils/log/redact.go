@@ -0,0 +1,102 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces values that must not be logged.
+const Redacted = "[REDACTED]"
+
+var (
+	// JSON members holding credentials or report contents: "password", "newPassword",
+	// "access_token", "resetToken", "secret", "apiKey", "authorization", "reportData"...
+	sensitiveJSONKey = regexp.MustCompile(`"(?:\w*(?i:password|token|secret|api_?key|authorization)|reportData)"\s*:\s*`)
+
+	// Query parameters and %+v struct fields holding credentials: token=..., Password:...
+	sensitiveField = regexp.MustCompile(`\b(\w*(?i:password|token|secret|api_?key))([=:])[^&\s",}]+`)
+
+	bearerToken = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+	jwt         = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*`)
+	apiKey      = regexp.MustCompile(`\bfsk_[0-9a-f]{8,}`)
+
+	// Duplicate key errors of MongoDB quote the conflicting values: dup key: { email: "..." }
+	mongoDupKey = regexp.MustCompile(`(dup key: )\{[^}]*\}`)
+
+	email = regexp.MustCompile(`\b([A-Za-z0-9])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})\b`)
+)
+
+// Redact removes personal data and secrets from s: emails keep their first letter and
+// domain (j***@example.com), while tokens, passwords, secrets and reportData payloads are
+// replaced by [REDACTED]. Every log line is redacted; use it as well for error text sent to
+// clients.
+func Redact(s string) string {
+	s = redactJSON(s)
+	s = sensitiveField.ReplaceAllString(s, "$1$2"+Redacted)
+	s = bearerToken.ReplaceAllString(s, "$1"+Redacted)
+	s = jwt.ReplaceAllString(s, Redacted)
+	s = apiKey.ReplaceAllString(s, Redacted)
+	s = mongoDupKey.ReplaceAllString(s, "$1{ "+Redacted+" }")
+	return email.ReplaceAllString(s, "$1***@$2")
+}
+
+// redactJSON replaces the values of sensitive JSON members, including nested objects and
+// arrays such as reportData.
+func redactJSON(s string) string {
+	matches := sensitiveJSONKey.FindAllStringIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[0] < last {
+			continue // inside a value already redacted
+		}
+		b.WriteString(s[last:m[1]])
+		b.WriteString(`"` + Redacted + `"`)
+		last = jsonValueEnd(s, m[1])
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// jsonValueEnd returns the index just past the JSON value starting at start; values cut
+// short, as in truncated logs, end with s.
+func jsonValueEnd(s string, start int) int {
+	depth := 0
+	inString := false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return i
+			}
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		case ',', ' ', '\n', '\t':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
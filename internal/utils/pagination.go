@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
 // PaginationParams holds pagination parameters
@@ -52,3 +55,36 @@ func CreatePaginatedResponse(data interface{}, pagination PaginationParams) Pagi
 		Pagination: pagination,
 	}
 }
+
+// SetPaginationHeaders adds X-Total-Count and an RFC 5988 Link header with first, prev,
+// next and last pages, for clients that read pagination from headers instead of the body.
+// pagination.Total must be set. Links keep the other query parameters of the request.
+func SetPaginationHeaders(w http.ResponseWriter, r *http.Request, pagination PaginationParams) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(pagination.Total))
+
+	// The original URI, so /api/v2/... links stay on /api/v2
+	u, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil {
+		u = r.URL
+	}
+	pageURL := func(page int) string {
+		query := u.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("limit", strconv.Itoa(pagination.Limit))
+		return u.Path + "?" + query.Encode()
+	}
+
+	last := (pagination.Total + pagination.Limit - 1) / pagination.Limit
+	if last < 1 {
+		last = 1
+	}
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(1))}
+	if pagination.Page > 1 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(min(pagination.Page-1, last))))
+	}
+	if pagination.Page < last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(pagination.Page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(last)))
+	w.Header().Add("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// maxPasswordBytes is what bcrypt hashes; longer passwords would be silently truncated.
+const maxPasswordBytes = 72
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords are the most used passwords of public breach lists, lowercased.
+var commonPasswords = passwordSet(commonPasswordList)
+
+// PasswordPolicy is what new passwords must satisfy. Passwords set before a stricter policy
+// keep working until they are changed.
+type PasswordPolicy struct {
+	MinLength     int  `json:"minLength"` // in characters
+	RequireUpper  bool `json:"requireUppercase"`
+	RequireLower  bool `json:"requireLowercase"`
+	RequireDigit  bool `json:"requireDigit"`
+	RequireSymbol bool `json:"requireSymbol"`
+	RejectCommon  bool `json:"rejectCommon"` // reject the built-in list of common passwords
+	// Banned are further passwords to reject, e.g. the company name; compared ignoring case
+	Banned []string `json:"-"`
+}
+
+// DefaultPasswordPolicy applies until SetPasswordPolicy is called.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    10,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+	RejectCommon: true,
+}
+
+var passwordPolicy = DefaultPasswordPolicy
+
+// SetPasswordPolicy configures the policy ValidatePassword enforces.
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicy = policy
+}
+
+// CurrentPasswordPolicy returns the policy ValidatePassword enforces.
+func CurrentPasswordPolicy() PasswordPolicy {
+	return passwordPolicy
+}
+
+// ValidatePassword checks a new password against the configured policy. It returns
+// PASSWORD_TOO_WEAK listing every rule the password breaks.
+func ValidatePassword(password string) error {
+	return passwordPolicy.Validate(password)
+}
+
+// ValidatePasswordChange checks a password replacing the one hashed in current: it must
+// follow the configured policy and differ from the current password.
+func ValidatePasswordChange(password, current string) error {
+	problems := passwordPolicy.problems(password)
+	if current != "" && ComparePassword(current, password) == nil {
+		problems = append(problems, "must differ from the current password")
+	}
+	return weakPassword(problems)
+}
+
+// Validate checks password against the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	return weakPassword(p.problems(password))
+}
+
+// problems lists the rules of the policy password breaks.
+func (p PasswordPolicy) problems(password string) []string {
+	var problems []string
+	if n := len([]rune(password)); n < p.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes", maxPasswordBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		problems = append(problems, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		problems = append(problems, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if p.RejectCommon && commonPasswords[lowered] {
+		problems = append(problems, "is too common")
+	} else {
+		for _, banned := range p.Banned {
+			if strings.EqualFold(password, banned) {
+				problems = append(problems, "is not allowed")
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// weakPassword returns PASSWORD_TOO_WEAK listing problems, or nil when there are none.
+func weakPassword(problems []string) error {
+	if len(problems) > 0 {
+		return errors.New("PASSWORD_TOO_WEAK", "Password does not meet the password policy", 400, nil,
+			map[string]interface{}{"problems": problems})
+	}
+	return nil
+}
+
+// passwordSet reads a list of passwords, one per line, ignoring blank lines and # comments.
+func passwordSet(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = true
+		}
+	}
+	return set
+}
+
+// ParsePasswordList reads a list of banned passwords, one per line, as in PASSWORD_BANNED_FILE.
+func ParsePasswordList(list string) []string {
+	var passwords []string
+	for password := range passwordSet(list) {
+		passwords = append(passwords, password)
+	}
+	return passwords
+}
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// fieldTree holds the fields kept by a projection; a nil subtree keeps the whole value.
+type fieldTree map[string]fieldTree
+
+// projection shapes the JSON bodies of successful responses, see WithProjection.
+type projection struct {
+	fields   fieldTree
+	collapse map[string]bool
+}
+
+// projectionWriter carries the projection of a response to RespondJSON.
+type projectionWriter struct {
+	http.ResponseWriter
+	projection projection
+}
+
+func (w projectionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithProjection applies the fields and expand query parameters of r to the successful
+// JSON bodies RespondJSON writes to the returned writer, or to each item of list bodies.
+//
+// fields lists the fields to keep, with dot paths reaching into nested objects
+// (fields=reportName,company.name); _id and _links are always kept. relations names the fields holding
+// populated objects that are reduced to their _id unless listed in expand.
+func WithProjection(w http.ResponseWriter, r *http.Request, relations ...string) http.ResponseWriter {
+	p := projection{collapse: make(map[string]bool, len(relations))}
+	for _, relation := range relations {
+		p.collapse[relation] = true
+	}
+	for _, relation := range splitList(r.URL.Query().Get("expand")) {
+		delete(p.collapse, relation)
+	}
+
+	for _, path := range splitList(r.URL.Query().Get("fields")) {
+		if p.fields == nil {
+			p.fields = fieldTree{}
+		}
+		p.fields.add(strings.Split(path, "."))
+	}
+
+	if p.fields == nil && len(p.collapse) == 0 {
+		return w
+	}
+	return projectionWriter{ResponseWriter: w, projection: p}
+}
+
+func (t fieldTree) add(path []string) {
+	sub, seen := t[path[0]]
+	if seen && sub == nil {
+		return // already kept whole
+	}
+	if len(path) == 1 {
+		t[path[0]] = nil
+		return
+	}
+	if sub == nil {
+		sub = fieldTree{}
+		t[path[0]] = sub
+	}
+	sub.add(path[1:])
+}
+
+// findProjection looks for WithProjection through the writers wrapped around it
+func findProjection(w http.ResponseWriter) (projection, bool) {
+	for {
+		if pw, ok := w.(projectionWriter); ok {
+			return pw.projection, true
+		}
+		wrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return projection{}, false
+		}
+		w = wrapper.Unwrap()
+	}
+}
+
+// apply projects data, keeping the items of a PaginatedResponse in place.
+func (p projection) apply(data interface{}) (interface{}, error) {
+	if page, ok := data.(PaginatedResponse); ok {
+		projected, err := p.apply(page.Data)
+		page.Data = projected
+		return page, err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return projectJSON(raw, p.fields, p.collapse)
+}
+
+// projectJSON rewrites objects member by member, so field order and number literals
+// are preserved.
+func projectJSON(raw json.RawMessage, fields fieldTree, collapse map[string]bool) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			projected, err := projectJSON(item, fields, collapse)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = projected
+		}
+		return json.Marshal(items)
+
+	case '{':
+		var out bytes.Buffer
+		out.WriteByte('{')
+		err := eachMember(raw, func(key string, value json.RawMessage) error {
+			if _, keep := fields[key]; fields != nil && !keep && key != "_id" && key != "_links" {
+				return nil
+			}
+			if collapse[key] {
+				value = collapseToID(value)
+			}
+			if sub := fields[key]; sub != nil {
+				var err error
+				if value, err = projectJSON(value, sub, nil); err != nil {
+					return err
+				}
+			}
+
+			if out.Len() > 1 {
+				out.WriteByte(',')
+			}
+			name, _ := json.Marshal(key)
+			out.Write(name)
+			out.WriteByte(':')
+			out.Write(value)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		out.WriteByte('}')
+		return out.Bytes(), nil
+	}
+	return raw, nil
+}
+
+// collapseToID reduces a populated object, or each object of a list, to {"_id": ...}.
+func collapseToID(raw json.RawMessage) json.RawMessage {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw
+	}
+
+	switch raw[0] {
+	case '[':
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return raw
+		}
+		for i, item := range items {
+			items[i] = collapseToID(item)
+		}
+		collapsed, _ := json.Marshal(items)
+		return collapsed
+
+	case '{':
+		var object struct {
+			ID json.RawMessage `json:"_id"`
+		}
+		if json.Unmarshal(raw, &object) != nil || object.ID == nil {
+			return raw
+		}
+		collapsed, _ := json.Marshal(object)
+		return collapsed
+	}
+	return raw
+}
+
+// eachMember calls fn with the members of a JSON object in order.
+func eachMember(raw json.RawMessage, fn func(key string, value json.RawMessage) error) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil { // {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if err := fn(token.(string), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Included reports whether the ?include= list of r names field, a field lists leave out by default.
+func Included(r *http.Request, field string) bool {
+	for _, item := range splitList(r.URL.Query().Get("include")) {
+		if item == field {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
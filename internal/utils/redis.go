@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+const (
+	redisPoolSize    = 10
+	redisDialTimeout = 5 * time.Second
+	// redisTimeout bounds commands whose context has no deadline
+	redisTimeout = 2 * time.Second
+)
+
+// RedisClient is a minimal Redis client speaking RESP over a small pool of connections,
+// enough for the shared cache without another dependency.
+type RedisClient struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      *tls.Config
+	pool     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// RedisError is an error reply from the server. The connection stays usable after one.
+type RedisError string
+
+func (e RedisError) Error() string { return "redis: " + string(e) }
+
+// NewRedisClient connects to a redis:// or rediss:// (TLS) URL such as
+// redis://:password@host:6379/0 and checks the server answers.
+func NewRedisClient(ctx context.Context, rawURL string) (*RedisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, errors.New("REDIS_CONFIG_INVALID", "REDIS_URL must be a redis:// or rediss:// URL", 500, err, nil)
+	}
+
+	c := &RedisClient{
+		addr: u.Host,
+		pool: make(chan *redisConn, redisPoolSize),
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, errors.New("REDIS_CONFIG_INVALID", "REDIS_URL database must be a number", 500, err, nil)
+		}
+	}
+	if u.Scheme == "rediss" {
+		c.tls = &tls.Config{ServerName: u.Hostname()}
+	}
+
+	if err := c.Ping(ctx); err != nil {
+		return nil, errors.New("REDIS_CONNECTION_ERROR", "Failed to connect to Redis", 500, err, map[string]interface{}{"addr": c.addr})
+	}
+	return c, nil
+}
+
+// Ping checks the server answers.
+func (c *RedisClient) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Addr is the host and port of the server.
+func (c *RedisClient) Addr() string {
+	return c.addr
+}
+
+// Do runs a command and returns its reply: a string, an int64, nil, a []interface{} of
+// replies, or a RedisError.
+func (c *RedisClient) Do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	conn.conn.SetDeadline(deadline)
+
+	reply, err := conn.do(args)
+	if _, isReply := err.(RedisError); err != nil && !isReply {
+		// The connection may hold half a reply; never reuse it
+		conn.conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// Close closes the idle connections.
+func (c *RedisClient) Close() {
+	for {
+		select {
+		case conn := <-c.pool:
+			conn.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (c *RedisClient) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.pool:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: redisDialTimeout}
+	var conn net.Conn
+	var err error
+	if c.tls != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: c.tls}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(redisDialTimeout))
+	if c.password != "" {
+		auth := []string{"AUTH", c.password}
+		if c.username != "" {
+			auth = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := rc.do(auth); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := rc.do([]string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+func (c *RedisClient) put(conn *redisConn) {
+	select {
+	case c.pool <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+func (rc *redisConn) do(args []string) (interface{}, error) {
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, cmd.String()); err != nil {
+		return nil, err
+	}
+	return rc.read()
+}
+
+func (rc *redisConn) read() (interface{}, error) {
+	line, err := rc.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, RedisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(rc.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = rc.read(); err != nil {
+				// An error element leaves the rest of the array unread
+				return nil, fmt.Errorf("redis: error in array reply: %w", err)
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"os"
 
 	"finsolvz-backend/internal/utils/errors"
 	"finsolvz-backend/internal/utils/log"
 )
 
-// ErrorResponse struct untuk respons error yang konsisten ke klien.
+// exposeErrorDetails menentukan apakah detail error internal dikirim ke klien (hanya untuk development).
+var exposeErrorDetails bool
+
+// ExposeErrorDetails mengaktifkan atau menonaktifkan detail error internal pada respons 5xx.
+func ExposeErrorDetails(expose bool) {
+	exposeErrorDetails = expose
+}
+
+// ErrorResponse struct untuk respons error yang konsisten ke klien. Details sudah melalui
+// log.Redact, sehingga tidak memuat email, token maupun isi reportData.
 type ErrorResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
@@ -18,7 +26,29 @@ type ErrorResponse struct {
 }
 
 // RespondJSON menulis respons JSON ke klien dengan status code dan data yang diberikan.
+// Data dipangkas sesuai WithProjection bila ada, lalu dibungkus dalam Envelope beserta
+// _links untuk klien yang memilihnya (lihat WithEnvelope).
 func RespondJSON(w http.ResponseWriter, status int, data interface{}) {
+	env, enveloped := findEnvelope(w)
+	if enveloped && data != nil && status < http.StatusBadRequest {
+		linked, err := addLinks(data, env.apiPrefix)
+		if err != nil {
+			log.Errorf(context.Background(), "Failed to add links to JSON response: %v", err)
+		} else {
+			data = linked
+		}
+	}
+	if projection, ok := findProjection(w); ok && data != nil && status < http.StatusBadRequest {
+		projected, err := projection.apply(data)
+		if err != nil {
+			log.Errorf(context.Background(), "Failed to project JSON response: %v", err)
+		} else {
+			data = projected
+		}
+	}
+	if data != nil && enveloped {
+		data = envelope(data)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if data != nil {
@@ -34,18 +64,21 @@ func HandleHTTPError(w http.ResponseWriter, err error, r *http.Request) {
 	appErr, ok := err.(errors.AppError)
 	if !ok {
 		log.Errorf(r.Context(), "Unhandled error: %v", err)
-		RespondJSON(w, http.StatusInternalServerError, ErrorResponse{
+		response := ErrorResponse{
 			Code:    errors.ErrInternalServer.Code(),
 			Message: errors.ErrInternalServer.Message(),
-			Details: err.Error(),
-		})
+		}
+		if exposeErrorDetails {
+			response.Details = log.Redact(err.Error())
+		}
+		RespondJSON(w, http.StatusInternalServerError, response)
 		return
 	}
 
 	if appErr.Status() >= http.StatusInternalServerError {
 		log.Errorf(r.Context(), "Server error occurred: %v", appErr)
 		detailsMessage := appErr.Message()
-		if os.Getenv("APP_ENV") == "development" {
+		if exposeErrorDetails {
 			if unwrappedErr := appErr.Unwrap(); unwrappedErr != nil {
 				detailsMessage = unwrappedErr.Error()
 			} else {
@@ -55,10 +88,13 @@ func HandleHTTPError(w http.ResponseWriter, err error, r *http.Request) {
 		RespondJSON(w, appErr.Status(), ErrorResponse{
 			Code:    appErr.Code(),
 			Message: appErr.Message(),
-			Details: detailsMessage,
+			Details: log.Redact(detailsMessage),
 		})
 	} else {
 		log.Warnf(r.Context(), "Client-side error: %v", appErr)
+		if cause := appErr.Unwrap(); cause != nil {
+			log.Debugf(r.Context(), "Client-side error cause: %v", cause)
+		}
 		RespondJSON(w, appErr.Status(), ErrorResponse{
 			Code:    appErr.Code(),
 			Message: appErr.Message(),
@@ -76,5 +112,5 @@ func formatErrorDetails(details map[string]interface{}) string {
 	if err != nil {
 		return ""
 	}
-	return string(detailsJSON)
+	return log.Redact(string(detailsJSON))
 }
@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"finsolvz-backend/internal/utils/log"
+)
+
+// Row formats list endpoints stream instead of a JSON array, see RowFormat.
+const (
+	FormatCSV    = "text/csv"
+	FormatNDJSON = "application/x-ndjson"
+)
+
+const (
+	// rowFlushInterval is how many rows are buffered before they are sent to the client
+	rowFlushInterval = 100
+	// rowWriteTimeout is how long the client may take to receive each batch of rows
+	rowWriteTimeout = 30 * time.Second
+)
+
+// RowFormat returns the row format the Accept header of r prefers, FormatCSV or
+// FormatNDJSON, or "" when JSON is acceptable at least as much.
+func RowFormat(r *http.Request) string {
+	format, best := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case FormatCSV, FormatNDJSON:
+			if q > best {
+				format, best = mediaType, q
+			}
+		case "application/json", "application/*", "*/*":
+			if q > best || (q == best && format != "") {
+				format, best = "", q
+			}
+		}
+	}
+	return format
+}
+
+// RowWriter streams the items of a list one per row, as CSV or NDJSON, so large lists
+// reach the client while they are still being read from the database.
+//
+// NDJSON rows are the items' JSON, shaped by WithProjection when w carries it. CSV rows hold
+// the given columns, dot paths into the item's JSON (company.name), or the paths of the
+// fields query parameter when set. Values inside lists are joined with "; ".
+type RowWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	format     string
+	columns    []string
+	csv        *csv.Writer
+	started    bool
+	rows       int
+}
+
+// NewRowWriter prepares a row stream in format; nothing is written before the first row,
+// so errors up to then can still be answered with HandleHTTPError.
+func NewRowWriter(w http.ResponseWriter, r *http.Request, format string, columns ...string) *RowWriter {
+	if fields := splitList(r.URL.Query().Get("fields")); len(fields) > 0 {
+		columns = fields
+	}
+	return &RowWriter{
+		w:          w,
+		controller: http.NewResponseController(w),
+		format:     format,
+		columns:    columns,
+	}
+}
+
+// Write sends item as the next row.
+func (rw *RowWriter) Write(item interface{}) error {
+	if err := rw.start(); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	if rw.format == FormatCSV {
+		values, err := csvValues(raw, rw.columns)
+		if err != nil {
+			return err
+		}
+		if err := rw.csv.Write(values); err != nil {
+			return err
+		}
+	} else {
+		if p, ok := findProjection(rw.w); ok {
+			if raw, err = projectJSON(raw, p.fields, p.collapse); err != nil {
+				return err
+			}
+		}
+		if _, err := rw.w.Write(append(raw, '\n')); err != nil {
+			return err
+		}
+	}
+
+	rw.rows++
+	if rw.rows%rowFlushInterval == 0 {
+		return rw.flush()
+	}
+	return nil
+}
+
+// Close sends the rows still buffered; an empty list becomes an empty body, or a CSV
+// header row.
+func (rw *RowWriter) Close() error {
+	if err := rw.start(); err != nil {
+		return err
+	}
+	return rw.flush()
+}
+
+// Fail reports err, the reason the list could not be read to the end. Before the first row
+// it is answered like any other error. After that the response is aborted, so clients can't
+// take a truncated list for a complete one.
+func (rw *RowWriter) Fail(err error, r *http.Request) {
+	if !rw.started {
+		HandleHTTPError(rw.w, err, r)
+		return
+	}
+	log.Errorf(r.Context(), "List stream failed after %d rows: %v", rw.rows, err)
+	panic(http.ErrAbortHandler)
+}
+
+func (rw *RowWriter) start() error {
+	if rw.started {
+		return nil
+	}
+	rw.started = true
+
+	if rw.format == FormatCSV {
+		rw.w.Header().Set("Content-Type", FormatCSV+"; charset=utf-8")
+	} else {
+		rw.w.Header().Set("Content-Type", FormatNDJSON)
+	}
+	rw.w.Header().Set("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+	rw.w.WriteHeader(http.StatusOK)
+
+	if rw.format == FormatCSV {
+		rw.csv = csv.NewWriter(rw.w)
+		return rw.csv.Write(rw.columns)
+	}
+	return nil
+}
+
+func (rw *RowWriter) flush() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		if err := rw.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := rw.controller.SetWriteDeadline(time.Now().Add(rowWriteTimeout)); err != nil && err != http.ErrNotSupported {
+		return err
+	}
+	if err := rw.controller.Flush(); err != nil && err != http.ErrNotSupported {
+		return err
+	}
+	return nil
+}
+
+// csvValues looks the columns up in the JSON object raw.
+func csvValues(raw json.RawMessage, columns []string) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var item interface{}
+	if err := dec.Decode(&item); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = csvCell(lookupPath(item, strings.Split(column, ".")))
+	}
+	return values, nil
+}
+
+// lookupPath follows path through objects, and through every item of the lists on the way.
+func lookupPath(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return lookupPath(v[path[0]], path[1:])
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = lookupPath(item, path)
+		}
+		return items
+	}
+	return nil
+}
+
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		// Spreadsheets run cells starting with these as formulas
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		cells := make([]string, 0, len(v))
+		for _, item := range v {
+			if cell := csvCell(item); cell != "" {
+				cells = append(cells, cell)
+			}
+		}
+		return strings.Join(cells, "; ")
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"finsolvz-backend/internal/utils/errors"
+	"finsolvz-backend/internal/utils/log"
+)
+
+// TextMessage is a plain-text message for a phone number. WhatsApp selects the WhatsApp
+// channel of providers that support both.
+type TextMessage struct {
+	To       string
+	Body     string
+	WhatsApp bool
+}
+
+// MessageSender delivers text messages through a specific SMS/WhatsApp provider.
+type MessageSender interface {
+	Name() string
+	Send(ctx context.Context, msg TextMessage) error
+}
+
+// SMSConfig selects and configures the SMS/WhatsApp provider.
+type SMSConfig struct {
+	Provider string // log (default) or twilio
+	DryRun   bool   // only log messages
+
+	TwilioAccountSID   string
+	TwilioAuthToken    string
+	TwilioSMSFrom      string
+	TwilioWhatsAppFrom string
+}
+
+// NewMessageSender builds the configured provider. In dry-run mode, or when no provider
+// is configured, messages are only logged.
+func NewMessageSender(cfg SMSConfig) (MessageSender, error) {
+	if cfg.DryRun {
+		return NewLogMessageSender(), nil
+	}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "", "log":
+		return NewLogMessageSender(), nil
+	case "twilio":
+		return NewTwilioMessageSender(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioSMSFrom, cfg.TwilioWhatsAppFrom)
+	}
+
+	return nil, errors.New("SMS_CONFIG_INVALID", "Unknown SMS_PROVIDER", 500, nil, map[string]interface{}{"provider": cfg.Provider})
+}
+
+// Twilio
+
+type twilioMessageSender struct {
+	accountSID   string
+	authToken    string
+	smsFrom      string
+	whatsAppFrom string
+	baseURL      string
+}
+
+// NewTwilioMessageSender sends SMS from smsFrom and WhatsApp messages from whatsAppFrom.
+// Either sender number may be empty, which disables that channel.
+func NewTwilioMessageSender(accountSID, authToken, smsFrom, whatsAppFrom string) (MessageSender, error) {
+	if accountSID == "" || authToken == "" || (smsFrom == "" && whatsAppFrom == "") {
+		return nil, errors.New("SMS_CONFIG_MISSING", "SMS configuration not found", 500, nil, map[string]interface{}{"provider": "twilio"})
+	}
+	return &twilioMessageSender{
+		accountSID:   accountSID,
+		authToken:    authToken,
+		smsFrom:      smsFrom,
+		whatsAppFrom: whatsAppFrom,
+		baseURL:      "https://api.twilio.com",
+	}, nil
+}
+
+func (p *twilioMessageSender) Name() string { return "twilio" }
+
+func (p *twilioMessageSender) Send(ctx context.Context, msg TextMessage) error {
+	from, to := p.smsFrom, msg.To
+	if msg.WhatsApp {
+		from, to = p.whatsAppFrom, "whatsapp:"+msg.To
+		if from != "" {
+			from = "whatsapp:" + from
+		}
+	}
+	if from == "" {
+		return errors.New("SMS_CHANNEL_UNAVAILABLE", "Messaging channel is not configured", 500, nil, map[string]interface{}{"whatsapp": msg.WhatsApp})
+	}
+
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", msg.Body)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.baseURL, p.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.New("SMS_SEND_ERROR", "Failed to build message request", 500, err, nil)
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := emailHTTPClient.Do(req)
+	if err != nil {
+		return errors.New("SMS_SEND_ERROR", "Failed to send message", 500, err, nil)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.New("SMS_SEND_ERROR", "Failed to send message", 500,
+			fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))), nil)
+	}
+	return nil
+}
+
+// Log (dry run)
+
+type logMessageSender struct{}
+
+// NewLogMessageSender logs messages instead of sending them. Bodies are not logged since they may carry credentials.
+func NewLogMessageSender() MessageSender {
+	return logMessageSender{}
+}
+
+func (logMessageSender) Name() string { return "log" }
+
+func (logMessageSender) Send(ctx context.Context, msg TextMessage) error {
+	log.Infof(ctx, "Text message (dry run): to=%s whatsapp=%t chars=%d", msg.To, msg.WhatsApp, len(msg.Body))
+	return nil
+}
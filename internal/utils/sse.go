@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"finsolvz-backend/internal/utils/errors"
+)
+
+// EventStream writes Server-Sent Events. Send and the keepalive comments may be called
+// from different goroutines.
+type EventStream struct {
+	mu         sync.Mutex
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// NewEventStream starts a text/event-stream response. It lifts the server's write timeout,
+// which would otherwise cut long streams; the request context decides when the stream ends.
+func NewEventStream(w http.ResponseWriter) (*EventStream, error) {
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		return nil, errors.New("STREAMING_UNSUPPORTED", "Streaming responses are not supported", http.StatusInternalServerError, err, nil)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	stream := &EventStream{w: w, controller: controller}
+	if err := controller.Flush(); err != nil {
+		return nil, errors.New("STREAMING_UNSUPPORTED", "Streaming responses are not supported", http.StatusInternalServerError, err, nil)
+	}
+	return stream, nil
+}
+
+// Send writes one event with data encoded as JSON.
+func (s *EventStream) Send(event string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write([]byte("event: " + event + "\ndata: " + string(body) + "\n\n")); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// KeepAlive writes a comment every interval until ctx is done, so idle streams are not
+// dropped by proxies.
+func (s *EventStream) KeepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			_, err := s.w.Write([]byte(": keepalive\n\n"))
+			if err == nil {
+				err = s.controller.Flush()
+			}
+			s.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -96,85 +96,6 @@ func TestE2E_HealthCheck(t *testing.T) {
 	t.Logf("✅ Health check passed: %v", response["message"])
 }
 
-// Test production performance
-func TestE2E_Performance(t *testing.T) {
-	cfg := setupE2E(t)
-
-	// Define performance thresholds based on region
-	thresholds := map[string]time.Duration{
-		"health":    200 * time.Millisecond, // Health check should be very fast
-		"auth":      500 * time.Millisecond, // Authentication should be reasonable
-		"protected": 1 * time.Second,        // Protected endpoints can be slower
-	}
-
-	tests := []struct {
-		name      string
-		method    string
-		path      string
-		body      interface{}
-		headers   map[string]string
-		threshold time.Duration
-		setup     func() map[string]string // Returns headers for subsequent requests
-	}{
-		{
-			name:      "Health Check Performance",
-			method:    "GET",
-			path:      "/",
-			threshold: thresholds["health"],
-		},
-		{
-			name:   "Login Performance",
-			method: "POST",
-			path:   "/api/login",
-			body: map[string]interface{}{
-				"email":    "admin@finsolvz.com", // Assuming test admin exists
-				"password": "admin123",
-			},
-			threshold: thresholds["auth"],
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Setup if needed
-			var headers map[string]string
-			if tt.setup != nil {
-				headers = tt.setup()
-			}
-			if tt.headers != nil {
-				if headers == nil {
-					headers = make(map[string]string)
-				}
-				for k, v := range tt.headers {
-					headers[k] = v
-				}
-			}
-
-			// Make request and measure time
-			start := time.Now()
-			resp, err := cfg.makeRequest(tt.method, tt.path, tt.body, headers)
-			duration := time.Since(start)
-
-			if err != nil {
-				t.Fatalf("Request failed: %v", err)
-			}
-			defer resp.Body.Close()
-
-			// Check performance
-			if duration > tt.threshold {
-				t.Errorf("Request too slow: %v (threshold: %v)", duration, tt.threshold)
-			} else {
-				t.Logf("✅ %s: %v (threshold: %v)", tt.name, duration, tt.threshold)
-			}
-
-			// Basic status check
-			if resp.StatusCode >= 500 {
-				t.Errorf("Server error: %d", resp.StatusCode)
-			}
-		})
-	}
-}
-
 // Test complete user journey
 func TestE2E_UserJourney(t *testing.T) {
 	cfg := setupE2E(t)
@@ -188,7 +109,7 @@ func TestE2E_UserJourney(t *testing.T) {
 	t.Run("Login with non-existent user", func(t *testing.T) {
 		loginReq := map[string]interface{}{
 			"email":    testEmail,
-			"password": "password123",
+			"password": "Ledger2024Balance",
 		}
 
 		resp, err := cfg.makeRequest("POST", "/api/login", loginReq, nil)
@@ -211,7 +132,7 @@ func TestE2E_UserJourney(t *testing.T) {
 		registerReq := map[string]interface{}{
 			"name":     "E2E Test User",
 			"email":    testEmail,
-			"password": "password123",
+			"password": "Ledger2024Balance",
 			"role":     "CLIENT",
 		}
 
@@ -228,7 +149,7 @@ func TestE2E_UserJourney(t *testing.T) {
 
 				loginReq := map[string]interface{}{
 					"email":    testEmail,
-					"password": "password123",
+					"password": "Ledger2024Balance",
 				}
 
 				loginResp, err := cfg.makeRequest("POST", "/api/login", loginReq, nil)
@@ -296,54 +217,6 @@ func TestE2E_UserJourney(t *testing.T) {
 		t.Logf("✅ Protected endpoint access successful")
 	})
 
-	// Step 4: Test performance of authenticated requests
-	t.Run("Authenticated request performance", func(t *testing.T) {
-		headers := map[string]string{
-			"Authorization": "Bearer " + authToken,
-		}
-
-		// Test multiple requests to check caching
-		var durations []time.Duration
-		for i := 0; i < 3; i++ {
-			start := time.Now()
-			resp, err := cfg.makeRequest("GET", "/api/loginUser", nil, headers)
-			duration := time.Since(start)
-			durations = append(durations, duration)
-
-			if err != nil {
-				t.Fatalf("Performance test request %d failed: %v", i+1, err)
-			}
-			resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				t.Errorf("Performance test request %d failed with status %d", i+1, resp.StatusCode)
-			}
-		}
-
-		// Calculate average
-		var total time.Duration
-		for _, d := range durations {
-			total += d
-		}
-		average := total / time.Duration(len(durations))
-
-		t.Logf("✅ Authenticated request performance:")
-		t.Logf("   Request 1: %v", durations[0])
-		t.Logf("   Request 2: %v", durations[1])
-		t.Logf("   Request 3: %v", durations[2])
-		t.Logf("   Average: %v", average)
-
-		// Check if requests are getting faster (caching effect)
-		if len(durations) >= 2 && durations[1] < durations[0] {
-			t.Logf("✅ Caching appears to be working (request 2 faster than request 1)")
-		}
-
-		// Performance threshold for authenticated requests
-		if average > 2*time.Second {
-			t.Errorf("Authenticated requests too slow: %v average", average)
-		}
-	})
-
 	t.Logf("🎉 E2E user journey completed successfully")
 }
 
@@ -20,6 +20,8 @@ import (
 	"finsolvz-backend/internal/app/user"
 	"finsolvz-backend/internal/config"
 	"finsolvz-backend/internal/platform/http/middleware"
+	"finsolvz-backend/internal/platform/notify"
+	"finsolvz-backend/internal/platform/storage"
 	"finsolvz-backend/internal/repository"
 	"finsolvz-backend/internal/utils"
 )
@@ -75,15 +77,19 @@ func setupTestServer(t *testing.T) *TestServer {
 	// Setup repositories
 	userRepo := repository.NewUserMongoRepository(db)
 	companyRepo := repository.NewCompanyMongoRepository(db)
+	outboxRepo := repository.NewOutboxMongoRepository(db)
+	transactor := repository.NewMongoTransactor(client, false)
 
 	// Setup services
-	emailService := utils.NewEmailService()
-	authService := auth.NewService(userRepo, emailService)
-	userService := user.NewService(userRepo)
-	companyService := company.NewService(companyRepo, userRepo)
+	utils.SetJWTSecret("integration-test-jwt-secret")
+	emailService := utils.NewEmailService(utils.EmailConfig{DryRun: true})
+	authService := auth.NewService(userRepo, repository.NewSecurityTokenMongoRepository(db), notify.NewNotifier(emailService, utils.NewLogMessageSender()), nil, nil, "")
+	store := storage.NewLocalStore(t.TempDir(), "", "")
+	userService := user.NewService(userRepo, outboxRepo, transactor, store)
+	companyService := company.NewService(companyRepo, userRepo, outboxRepo, transactor, store)
 
 	// Setup handlers
-	authHandler := auth.NewHandler(authService)
+	authHandler := auth.NewHandler(authService, nil, nil, nil, nil)
 	userHandler := user.NewHandler(userService, authService)
 	companyHandler := company.NewHandler(companyService)
 
@@ -93,7 +99,7 @@ func setupTestServer(t *testing.T) *TestServer {
 	router.Use(middleware.RecoveryMiddleware)
 
 	// Register routes
-	authHandler.RegisterRoutes(router)
+	authHandler.RegisterRoutes(router, middleware.AuthMiddleware)
 	userHandler.RegisterRoutes(router, middleware.AuthMiddleware)
 	companyHandler.RegisterRoutes(router, middleware.AuthMiddleware)
 
@@ -184,7 +190,7 @@ func TestIntegration_AuthFlow(t *testing.T) {
 	registerReq := map[string]interface{}{
 		"name":     "Test User",
 		"email":    "test@example.com",
-		"password": "password123",
+		"password": "Ledger2024Balance",
 		"role":     "CLIENT",
 	}
 
@@ -214,7 +220,7 @@ func TestIntegration_AuthFlow(t *testing.T) {
 	// Test login
 	loginReq := map[string]interface{}{
 		"email":    "test@example.com",
-		"password": "password123",
+		"password": "Ledger2024Balance",
 	}
 
 	resp, err = ts.makeRequest("POST", "/api/login", loginReq, nil)
@@ -250,7 +256,7 @@ func TestIntegration_ProtectedEndpoints(t *testing.T) {
 	registerReq := map[string]interface{}{
 		"name":     "Admin User",
 		"email":    "admin@example.com",
-		"password": "password123",
+		"password": "Ledger2024Balance",
 		"role":     "SUPER_ADMIN",
 	}
 
@@ -301,7 +307,7 @@ func TestIntegration_CompanyFlow(t *testing.T) {
 	registerReq := map[string]interface{}{
 		"name":     "Company Admin",
 		"email":    "admin@company.com",
-		"password": "password123",
+		"password": "Ledger2024Balance",
 		"role":     "ADMIN",
 	}
 
@@ -421,80 +427,3 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 		t.Errorf("Expected status 404, got %d", resp.StatusCode)
 	}
 }
-
-// Performance test
-func TestIntegration_Performance(t *testing.T) {
-	ts := setupTestServer(t)
-	defer ts.Cleanup(t)
-
-	// Setup: Create admin user
-	registerReq := map[string]interface{}{
-		"name":     "Perf Admin",
-		"email":    "perf@example.com",
-		"password": "password123",
-		"role":     "ADMIN",
-	}
-
-	resp, err := ts.makeRequest("POST", "/api/register", registerReq, nil)
-	if err != nil {
-		t.Fatalf("Registration failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	var authResponse auth.AuthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&authResponse); err != nil {
-		t.Fatalf("Failed to decode auth response: %v", err)
-	}
-
-	authHeaders := map[string]string{
-		"Authorization": "Bearer " + authResponse.Token,
-	}
-
-	// Performance test: Multiple health checks
-	start := time.Now()
-	for i := 0; i < 10; i++ {
-		resp, err := ts.makeRequest("GET", "/", nil, nil)
-		if err != nil {
-			t.Fatalf("Health check %d failed: %v", i, err)
-		}
-		resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("Health check %d failed with status %d", i, resp.StatusCode)
-		}
-	}
-	healthCheckDuration := time.Since(start)
-
-	// Performance test: Multiple authenticated requests
-	start = time.Now()
-	for i := 0; i < 10; i++ {
-		resp, err := ts.makeRequest("GET", "/api/users", nil, authHeaders)
-		if err != nil {
-			t.Fatalf("Authenticated request %d failed: %v", i, err)
-		}
-		resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("Authenticated request %d failed with status %d", i, resp.StatusCode)
-		}
-	}
-	authRequestDuration := time.Since(start)
-
-	// Performance assertions
-	avgHealthCheck := healthCheckDuration / 10
-	avgAuthRequest := authRequestDuration / 10
-
-	t.Logf("Performance results:")
-	t.Logf("  Average health check: %v", avgHealthCheck)
-	t.Logf("  Average auth request: %v", avgAuthRequest)
-
-	// Health checks should be very fast
-	if avgHealthCheck > 50*time.Millisecond {
-		t.Errorf("Health check too slow: %v (expected < 50ms)", avgHealthCheck)
-	}
-
-	// Authenticated requests should be reasonably fast
-	if avgAuthRequest > 200*time.Millisecond {
-		t.Errorf("Authenticated request too slow: %v (expected < 200ms)", avgAuthRequest)
-	}
-}
@@ -0,0 +1,109 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client calls the API as one logged-in user.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   string
+	userID  string
+}
+
+// NewClient creates a client for the server at baseURL, sharing connections between workers
+// like a load balancer would.
+func NewClient(baseURL string) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 100
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}
+}
+
+// StatusError is a response with an unexpected status.
+type StatusError struct {
+	Method string
+	Path   string
+	Status int
+	Code   string // the error code of the API, if the body had one
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.Status, e.Code)
+}
+
+// Login signs in and makes the client send the token with every later request.
+func (c *Client) Login(ctx context.Context, email, password string) error {
+	var response struct {
+		Token string `json:"access_token"`
+		User  struct {
+			ID string `json:"_id"`
+		} `json:"user"`
+	}
+	if err := c.Do(ctx, http.MethodPost, "/api/login", map[string]string{"email": email, "password": password}, &response); err != nil {
+		return err
+	}
+	c.token = response.Token
+	c.userID = response.User.ID
+	return nil
+}
+
+// UserID is the ID of the logged-in user.
+func (c *Client) UserID() string {
+	return c.userID
+}
+
+// Do sends a JSON request and decodes the response into out unless it is nil. Responses
+// outside 2xx are returned as a *StatusError.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Code string `json:"code"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return &StatusError{Method: method, Path: path, Status: resp.StatusCode, Code: body.Code}
+	}
+	if out == nil {
+		// Read the body anyway, so the latency covers the whole response and the connection is reused
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
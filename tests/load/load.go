@@ -0,0 +1,172 @@
+// Package load drives scripted scenarios against a running server and checks their latency,
+// error rate and throughput against thresholds. The scenarios live in load_test.go:
+//
+//	FINSOLVZ_LOAD_URL=http://localhost:8787 go test -v -timeout=10m ./tests/load
+package load
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scenario is one kind of request a load test repeats.
+type Scenario struct {
+	Name string
+	// Request sends one request; an error counts as a failure
+	Request func(ctx context.Context, c *Client) error
+}
+
+// Options shape the load of a run.
+type Options struct {
+	Concurrency int           // workers sending requests in parallel
+	Duration    time.Duration // how long to keep sending
+	Rate        int           // requests per second across all workers; 0 sends as fast as the server answers
+}
+
+// Result is what a run measured.
+type Result struct {
+	Scenario  string
+	Requests  int
+	Failures  int
+	Errors    map[string]int // failures by message
+	Latencies []time.Duration
+	Elapsed   time.Duration
+}
+
+// Run repeats the scenario with the given load until the duration is over or ctx is cancelled.
+func Run(ctx context.Context, c *Client, scenario Scenario, opts Options) *Result {
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	// With a rate, workers take a ticket before every request
+	var tickets <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.Rate))
+		defer ticker.Stop()
+		tickets = ticker.C
+	}
+
+	result := &Result{Scenario: scenario.Name, Errors: make(map[string]int)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	started := time.Now()
+	for i := 0; i < max(opts.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if tickets != nil {
+					select {
+					case <-tickets:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+
+				start := time.Now()
+				err := scenario.Request(ctx, c)
+				latency := time.Since(start)
+				if err != nil && ctx.Err() != nil {
+					// Cut off by the end of the run rather than failed
+					return
+				}
+
+				mu.Lock()
+				result.Requests++
+				result.Latencies = append(result.Latencies, latency)
+				if err != nil {
+					result.Failures++
+					result.Errors[err.Error()]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(started)
+
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+	return result
+}
+
+// Percentile returns the latency p percent of the requests stayed under, e.g. 95.
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(r.Latencies))*p/100+0.5) - 1
+	return r.Latencies[min(max(i, 0), len(r.Latencies)-1)]
+}
+
+// Throughput is the requests per second of the run.
+func (r *Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// ErrorRate is the share of requests that failed, from 0 to 1.
+func (r *Result) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Requests)
+}
+
+func (r *Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d requests in %s (%.1f/s), %d failed (%.1f%%), p50 %s, p95 %s, p99 %s",
+		r.Scenario, r.Requests, r.Elapsed.Round(time.Millisecond), r.Throughput(), r.Failures, r.ErrorRate()*100,
+		r.Percentile(50).Round(time.Millisecond), r.Percentile(95).Round(time.Millisecond), r.Percentile(99).Round(time.Millisecond))
+
+	messages := make([]string, 0, len(r.Errors))
+	for message := range r.Errors {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool { return r.Errors[messages[i]] > r.Errors[messages[j]] })
+	for _, message := range messages[:min(len(messages), 5)] {
+		fmt.Fprintf(&b, "\n  %dx %s", r.Errors[message], message)
+	}
+	return b.String()
+}
+
+// Thresholds are the limits a run must stay within; zero fields aren't checked.
+type Thresholds struct {
+	P50           time.Duration
+	P95           time.Duration
+	P99           time.Duration
+	MaxErrorRate  float64 // from 0 to 1
+	MinThroughput float64 // requests per second
+}
+
+// Check lists the thresholds the result exceeds.
+func (t Thresholds) Check(r *Result) []string {
+	var violations []string
+	if r.Requests == 0 {
+		return []string{"no requests completed"}
+	}
+	for _, limit := range []struct {
+		percentile float64
+		max        time.Duration
+	}{{50, t.P50}, {95, t.P95}, {99, t.P99}} {
+		if got := r.Percentile(limit.percentile); limit.max > 0 && got > limit.max {
+			violations = append(violations, fmt.Sprintf("p%g latency %s exceeds %s", limit.percentile, got.Round(time.Millisecond), limit.max))
+		}
+	}
+	if r.ErrorRate() > t.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", r.ErrorRate()*100, t.MaxErrorRate*100))
+	}
+	if t.MinThroughput > 0 && r.Throughput() < t.MinThroughput {
+		violations = append(violations, fmt.Sprintf("throughput %.1f/s is below %.1f/s", r.Throughput(), t.MinThroughput))
+	}
+	return violations
+}
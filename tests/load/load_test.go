@@ -0,0 +1,166 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Load tests run against a live server, local or staging. The server must allow the load
+// from one IP (RATE_LIMIT_PER_MINUTE) and the user must be able to create reports:
+//
+//	FINSOLVZ_LOAD_URL=http://localhost:8787 FINSOLVZ_LOAD_EMAIL=admin@finsolvz.com \
+//	FINSOLVZ_LOAD_PASSWORD=admin123 go test -v -timeout=10m ./tests/load
+//
+// FINSOLVZ_LOAD_DURATION and FINSOLVZ_LOAD_CONCURRENCY change the load of every scenario.
+
+func setupLoad(t *testing.T) (*Client, Options) {
+	baseURL := os.Getenv("FINSOLVZ_LOAD_URL")
+	if baseURL == "" {
+		t.Skip("Skipping load tests: FINSOLVZ_LOAD_URL not set")
+	}
+
+	opts := Options{Concurrency: 10, Duration: 30 * time.Second}
+	if value := os.Getenv("FINSOLVZ_LOAD_DURATION"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			t.Fatalf("FINSOLVZ_LOAD_DURATION: %v", err)
+		}
+		opts.Duration = duration
+	}
+	if value := os.Getenv("FINSOLVZ_LOAD_CONCURRENCY"); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil || concurrency <= 0 {
+			t.Fatalf("FINSOLVZ_LOAD_CONCURRENCY must be a positive number, got %q", value)
+		}
+		opts.Concurrency = concurrency
+	}
+
+	c := NewClient(baseURL)
+	email, password := loadCredentials()
+	if err := c.Login(context.Background(), email, password); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return c, opts
+}
+
+func loadCredentials() (string, string) {
+	email, password := os.Getenv("FINSOLVZ_LOAD_EMAIL"), os.Getenv("FINSOLVZ_LOAD_PASSWORD")
+	if email == "" {
+		email, password = "admin@finsolvz.com", "admin123"
+	}
+	return email, password
+}
+
+func runScenario(t *testing.T, c *Client, scenario Scenario, opts Options, thresholds Thresholds) {
+	result := Run(context.Background(), c, scenario, opts)
+	t.Log(result)
+	for _, violation := range thresholds.Check(result) {
+		t.Errorf("%s: %s", scenario.Name, violation)
+	}
+}
+
+func TestLoad_Login(t *testing.T) {
+	c, opts := setupLoad(t)
+	email, password := loadCredentials()
+
+	// Password hashing makes logins the slowest requests by design
+	runScenario(t, c, Scenario{
+		Name: "login",
+		Request: func(ctx context.Context, c *Client) error {
+			return c.Do(ctx, http.MethodPost, "/api/login", map[string]string{"email": email, "password": password}, nil)
+		},
+	}, opts, Thresholds{P95: 800 * time.Millisecond, P99: 1500 * time.Millisecond, MaxErrorRate: 0.01})
+}
+
+func TestLoad_ReportList(t *testing.T) {
+	c, opts := setupLoad(t)
+
+	runScenario(t, c, Scenario{
+		Name: "report list",
+		Request: func(ctx context.Context, c *Client) error {
+			return c.Do(ctx, http.MethodGet, "/api/reports", nil, nil)
+		},
+	}, opts, Thresholds{P95: 500 * time.Millisecond, P99: time.Second, MaxErrorRate: 0.01})
+
+	runScenario(t, c, Scenario{
+		Name: "report page",
+		Request: func(ctx context.Context, c *Client) error {
+			return c.Do(ctx, http.MethodGet, "/api/reports/paginated?page=1&limit=20", nil, nil)
+		},
+	}, opts, Thresholds{P95: 300 * time.Millisecond, P99: 800 * time.Millisecond, MaxErrorRate: 0.01})
+}
+
+// Creates reports named "load-test ..." in the first company with the first report type, and
+// deletes them afterwards. The deletions trip the mass deletion alert unless
+// ANOMALY_MASS_DELETE_THRESHOLD=0.
+func TestLoad_ReportCreate(t *testing.T) {
+	c, opts := setupLoad(t)
+	ctx := context.Background()
+
+	var companies []struct {
+		ID string `json:"_id"`
+	}
+	var reportTypes []struct {
+		ID string `json:"id"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/api/company", nil, &companies); err != nil {
+		t.Fatalf("Failed to list companies: %v", err)
+	}
+	if err := c.Do(ctx, http.MethodGet, "/api/reportTypes", nil, &reportTypes); err != nil {
+		t.Fatalf("Failed to list report types: %v", err)
+	}
+	if len(companies) == 0 || len(reportTypes) == 0 {
+		t.Skip("Skipping report creation: the server needs a company and a report type")
+	}
+
+	// Creating reports as fast as possible would mostly measure the database's write capacity
+	opts.Rate = 10
+
+	var mu sync.Mutex
+	var created []string
+	t.Cleanup(func() {
+		for _, id := range created {
+			if err := c.Do(context.Background(), http.MethodDelete, "/api/reports/"+id, nil, nil); err != nil {
+				t.Logf("Failed to delete load test report %s: %v", id, err)
+			}
+		}
+	})
+
+	started := time.Now().Unix()
+	var sequence int
+	runScenario(t, c, Scenario{
+		Name: "report create",
+		Request: func(ctx context.Context, c *Client) error {
+			mu.Lock()
+			sequence++
+			name := fmt.Sprintf("load-test %d-%d", started, sequence)
+			mu.Unlock()
+
+			var report struct {
+				ID string `json:"_id"`
+			}
+			err := c.Do(ctx, http.MethodPost, "/api/reports", map[string]interface{}{
+				"reportName": name,
+				"reportType": reportTypes[0].ID,
+				"year":       strconv.Itoa(time.Now().Year()),
+				"company":    companies[0].ID,
+				"createBy":   c.UserID(),
+				"reportData": []map[string]interface{}{{"account": "Revenue", "amount": 1000}},
+			}, &report)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			created = append(created, report.ID)
+			mu.Unlock()
+			return nil
+		},
+	}, opts, Thresholds{P95: time.Second, P99: 2 * time.Second, MaxErrorRate: 0.01})
+}